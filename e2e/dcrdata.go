@@ -0,0 +1,229 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3/ecdsa"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/wire"
+	types "github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// defaultBestBlock is the initial best block height of the dcrdata
+	// stand-in. It must be greater than the ticket maturity of the
+	// active network so that votes can be started.
+	defaultBestBlock uint32 = 1000
+
+	// ticketPoolSize is the number of tickets in the ticket pool of the
+	// dcrdata stand-in.
+	ticketPoolSize = 100
+
+	// ticketCommitAmt is the commitment amount, in DCR, of the tickets
+	// in the ticket pool of the dcrdata stand-in.
+	ticketCommitAmt = 100.0
+)
+
+// Ticket is a ticket in the ticket pool of the dcrdata stand-in. The harness
+// controls the private key of the ticket commitment address so that tests are
+// able to cast votes using the ticket.
+type Ticket struct {
+	Hash    string // Ticket hash
+	Address string // Commitment address
+	key     *secp256k1.PrivateKey
+}
+
+// newTicket returns a new ticket that has a random hash and a P2PKH
+// commitment address on the testnet.
+func newTicket() (*Ticket, error) {
+	h, err := util.Random(chainhash.HashSize)
+	if err != nil {
+		return nil, err
+	}
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	pkh := dcrutil.Hash160(key.PubKey().SerializeCompressed())
+	addr, err := dcrutil.NewAddressPubKeyHash(pkh, chaincfg.TestNet3Params(),
+		dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	return &Ticket{
+		Hash:    hex.EncodeToString(h),
+		Address: addr.Address(),
+		key:     key,
+	}, nil
+}
+
+// Sign returns the hex encoded signature of the provided message. The message
+// is signed using the private key of the ticket commitment address, the same
+// as a wallet signs a vote.
+func (t *Ticket) Sign(msg string) string {
+	var b bytes.Buffer
+	wire.WriteVarString(&b, 0, "Decred Signed Message:\n")
+	wire.WriteVarString(&b, 0, msg)
+	sig := ecdsa.SignCompact(t.key, chainhash.HashB(b.Bytes()), true)
+	return hex.EncodeToString(sig)
+}
+
+// dcrdata is an in-process stand-in for the dcrdata HTTP API. It serves the
+// routes that the politeiad dcrdata plugin uses to start a vote and to look up
+// the ticket commitment addresses of cast votes. The chain is fake: the block
+// hashes are derived from the block heights and the ticket pool is the same
+// for every block.
+type dcrdata struct {
+	sync.Mutex
+	bestBlock uint32
+	tickets   []Ticket
+	server    *httptest.Server
+}
+
+// newDcrdata starts a new dcrdata stand-in. A closure that stops the server
+// is returned.
+func newDcrdata(t *testing.T) (*dcrdata, func()) {
+	t.Helper()
+
+	tickets := make([]Ticket, 0, ticketPoolSize)
+	for i := 0; i < ticketPoolSize; i++ {
+		ticket, err := newTicket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		tickets = append(tickets, *ticket)
+	}
+	d := dcrdata{
+		bestBlock: defaultBestBlock,
+		tickets:   tickets,
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/block/best", d.handleBestBlock).
+		Methods(http.MethodGet)
+	router.HandleFunc("/api/block/{height:[0-9]+}", d.handleBlockDetails).
+		Methods(http.MethodGet)
+	router.HandleFunc("/api/stake/pool/b/{hash}/full", d.handleTicketPool).
+		Methods(http.MethodGet)
+	router.HandleFunc("/api/txs/trimmed", d.handleTxsTrimmed).
+		Methods(http.MethodPost)
+	d.server = httptest.NewServer(router)
+
+	return &d, d.server.Close
+}
+
+// host returns the host of the dcrdata stand-in.
+func (d *dcrdata) host() string {
+	return d.server.URL
+}
+
+// setBestBlock sets the best block height.
+func (d *dcrdata) setBestBlock(height uint32) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.bestBlock = height
+}
+
+// eligibleTickets returns the tickets of the ticket pool.
+func (d *dcrdata) eligibleTickets() []Ticket {
+	tickets := make([]Ticket, len(d.tickets))
+	copy(tickets, d.tickets)
+	return tickets
+}
+
+// blockData returns the block data for the provided height. A pointer is
+// returned so that the block time is encoded using its JSON marshaler.
+func blockData(height uint32) *types.BlockDataBasic {
+	h := util.Digest([]byte(strconv.FormatUint(uint64(height), 10)))
+	return &types.BlockDataBasic{
+		Height: height,
+		Hash:   hex.EncodeToString(h),
+		Time:   types.NewTimeAPIFromUNIX(time.Now().Unix()),
+	}
+}
+
+func (d *dcrdata) handleBestBlock(w http.ResponseWriter, r *http.Request) {
+	d.Lock()
+	height := d.bestBlock
+	d.Unlock()
+
+	util.RespondWithJSON(w, http.StatusOK, blockData(height))
+}
+
+func (d *dcrdata) handleBlockDetails(w http.ResponseWriter, r *http.Request) {
+	height, err := strconv.ParseUint(mux.Vars(r)["height"], 10, 32)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, blockData(uint32(height)))
+}
+
+func (d *dcrdata) handleTicketPool(w http.ResponseWriter, r *http.Request) {
+	hashes := make([]string, 0, len(d.tickets))
+	for _, v := range d.tickets {
+		hashes = append(hashes, v.Hash)
+	}
+	util.RespondWithJSON(w, http.StatusOK, hashes)
+}
+
+// handleTxsTrimmed returns the ticket purchase transactions of the requested
+// tickets. Only the commitment output, which is the output that the ticketvote
+// plugin uses to verify the vote signatures, is included. Transactions that
+// are not in the ticket pool are omitted.
+func (d *dcrdata) handleTxsTrimmed(w http.ResponseWriter, r *http.Request) {
+	var t types.Txns
+	err := json.NewDecoder(r.Body).Decode(&t)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	addrs := make(map[string]string, len(d.tickets))
+	for _, v := range d.tickets {
+		addrs[v.Hash] = v.Address
+	}
+	txs := make([]types.TrimmedTx, 0, len(t.Transactions))
+	for _, v := range t.Transactions {
+		addr, ok := addrs[v]
+		if !ok {
+			continue
+		}
+		commitAmt := ticketCommitAmt
+		txs = append(txs, types.TrimmedTx{
+			TxID: v,
+			Vout: []types.Vout{
+				{
+					N: 1,
+					ScriptPubKeyDecoded: types.ScriptPubKey{
+						Type:      "sstxcommitment",
+						Addresses: []string{addr},
+						CommitAmt: &commitAmt,
+					},
+				},
+			},
+		})
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, txs)
+}
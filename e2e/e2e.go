@@ -0,0 +1,153 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package e2e provides a harness for end-to-end tests. The harness runs
+// politeiad and politeiawww in-process on random localhost ports, along with
+// a dcrdata stand-in, so that full proposal lifecycle tests can be written as
+// regular go tests without any external services.
+//
+// politeiad uses a tstore backend that is setup for testing, i.e. an
+// in-memory tlog and a leveldb key-value store, and has the pi, comments,
+// ticketvote, usermd, and dcrdata plugins registered. politeiawww serves the
+// legacy pi API with emails disabled and the user paywall turned off.
+//
+// The dcrdata stand-in serves the routes that are required to start a vote
+// and to cast votes. The harness controls the private keys of the ticket
+// commitment addresses so that tests are able to sign votes using the
+// eligible tickets.
+package e2e
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pdclient "github.com/decred/politeia/politeiad/client"
+	pclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// Harness contains an end-to-end test environment.
+type Harness struct {
+	dir     string
+	dcrdata *dcrdata
+	pd      *politeiad
+	www     *politeiawww
+
+	// Politeiad is a politeiad client that uses the RPC credentials.
+	Politeiad *pdclient.Client
+
+	// Client is a politeiawww client that is not logged in.
+	Client *pclient.Client
+}
+
+// New starts a new end-to-end test environment. The returned closure stops
+// all servers and removes all test data when invoked.
+func New(t *testing.T) (*Harness, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "e2e.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"politeiad", "politeiawww"} {
+		err = os.MkdirAll(filepath.Join(dir, v), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Start the servers. politeiawww verifies the politeiad plugins
+	// on startup so politeiad must be started first.
+	d, cleanupDcrdata := newDcrdata(t)
+	pd, cleanupPoliteiad := newPoliteiad(t,
+		filepath.Join(dir, "politeiad"), d.host())
+	www, cleanupPoliteiawww := newPoliteiawww(t,
+		filepath.Join(dir, "politeiawww"), pd)
+
+	// Setup the clients
+	pdc, err := pdclient.New(pd.host, pd.cert, rpcUser, rpcPass,
+		&pd.identity.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc, err := pclient.New(www.host, pclient.Opts{
+		HTTPSCert: www.cert,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := Harness{
+		dir:       dir,
+		dcrdata:   d,
+		pd:        pd,
+		www:       www,
+		Politeiad: pdc,
+		Client:    pc,
+	}
+	return &h, func() {
+		t.Helper()
+
+		cleanupPoliteiawww()
+		cleanupPoliteiad()
+		cleanupDcrdata()
+
+		err := os.RemoveAll(dir)
+		if err != nil {
+			t.Fatalf("remove tmp dir: %v", err)
+		}
+	}
+}
+
+// PoliteiawwwHost returns the politeiawww host in the format
+// https://<host>:<port>.
+func (h *Harness) PoliteiawwwHost() string {
+	return h.www.host
+}
+
+// PoliteiawwwCert returns the path to the politeiawww https cert.
+func (h *Harness) PoliteiawwwCert() string {
+	return h.www.cert
+}
+
+// PoliteiadHost returns the politeiad host in the format
+// https://<host>:<port>.
+func (h *Harness) PoliteiadHost() string {
+	return h.pd.host
+}
+
+// PoliteiadCert returns the path to the politeiad https cert.
+func (h *Harness) PoliteiadCert() string {
+	return h.pd.cert
+}
+
+// PoliteiadPublicKey returns the hex encoded public key of the politeiad
+// identity. It is used to verify the server signatures of records.
+func (h *Harness) PoliteiadPublicKey() string {
+	return h.pd.identity.Public.String()
+}
+
+// SetBestBlock sets the best block height that is reported by the dcrdata
+// stand-in.
+func (h *Harness) SetBestBlock(height uint32) {
+	h.dcrdata.setBestBlock(height)
+}
+
+// Fsck runs the politeiad backend filesystem check, which verifies the plugin
+// caches.
+func (h *Harness) Fsck(t *testing.T) {
+	t.Helper()
+
+	err := h.pd.backend.Fsck()
+	if err != nil {
+		t.Fatalf("Fsck: %v", err)
+	}
+}
+
+// EligibleTickets returns the tickets that are eligible to vote in votes that
+// are started by the harness.
+func (h *Harness) EligibleTickets() []Ticket {
+	return h.dcrdata.eligibleTickets()
+}
@@ -0,0 +1,424 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package e2e
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/util"
+)
+
+func TestProposalLifecycle(t *testing.T) {
+	h, cleanup := New(t)
+	defer cleanup()
+
+	var (
+		admin  = h.NewUser(t, true)
+		author = h.NewUser(t, false)
+	)
+
+	// Verify politeiad has the required plugins registered
+	plugins, err := h.Politeiad.PluginInventory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plugins) != 5 {
+		t.Fatalf("got %v politeiad plugins, want 5", len(plugins))
+	}
+
+	// Submit a proposal
+	pr, err := h.Client.PiPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := proposalFiles(t, "Original proposal text", pr.Domains[0])
+	nr, err := author.Client.RecordNew(rcv1.New{
+		Files:     files,
+		PublicKey: author.Identity.Public.String(),
+		Signature: signedMerkleRoot(t, author, files),
+	})
+	if err != nil {
+		t.Fatalf("RecordNew: %v", err)
+	}
+	err = pclient.RecordVerify(nr.Record, h.PoliteiadPublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := nr.Record.CensorshipRecord.Token
+	assertProposalStatus(t, h, token, piplugin.PropStatusUnvetted)
+
+	// Edit the proposal
+	files = proposalFiles(t, "Edited proposal text", pr.Domains[0])
+	er, err := author.Client.RecordEdit(rcv1.Edit{
+		Token:     token,
+		Files:     files,
+		PublicKey: author.Identity.Public.String(),
+		Signature: signedMerkleRoot(t, author, files),
+	})
+	if err != nil {
+		t.Fatalf("RecordEdit: %v", err)
+	}
+	if er.Record.Version != 2 {
+		t.Fatalf("got version %v, want 2", er.Record.Version)
+	}
+
+	// Make the proposal public
+	status := rcv1.RecordStatusPublic
+	msg := token + strconv.FormatUint(uint64(er.Record.Version), 10) +
+		strconv.Itoa(int(status))
+	sig := admin.Identity.SignMessage([]byte(msg))
+	ssr, err := admin.Client.RecordSetStatus(rcv1.SetStatus{
+		Token:     token,
+		Version:   er.Record.Version,
+		Status:    status,
+		PublicKey: admin.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("RecordSetStatus: %v", err)
+	}
+	assertProposalStatus(t, h, token, piplugin.PropStatusUnderReview)
+	version := ssr.Record.Version
+
+	// Comment on the proposal and upvote the comment
+	state := cmv1.RecordStateVetted
+	comment := "This is a comment"
+	msg = strconv.FormatUint(uint64(state), 10) + token + "0" + comment
+	sig = author.Identity.SignMessage([]byte(msg))
+	cnr, err := author.Client.CommentNew(cmv1.New{
+		State:     state,
+		Token:     token,
+		Comment:   comment,
+		PublicKey: author.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("CommentNew: %v", err)
+	}
+	commentID := cnr.Comment.CommentID
+	msg = strconv.FormatUint(uint64(state), 10) + token +
+		strconv.FormatUint(uint64(commentID), 10) +
+		strconv.FormatInt(int64(cmv1.VoteUpvote), 10)
+	sig = admin.Identity.SignMessage([]byte(msg))
+	cvr, err := admin.Client.CommentVote(cmv1.Vote{
+		State:     state,
+		Token:     token,
+		CommentID: commentID,
+		Vote:      cmv1.VoteUpvote,
+		PublicKey: admin.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("CommentVote: %v", err)
+	}
+	if cvr.Upvotes != 1 {
+		t.Fatalf("got %v upvotes, want 1", cvr.Upvotes)
+	}
+	cr, err := h.Client.Comments(cmv1.Comments{
+		Token: token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cr.Comments) != 1 || cr.Comments[0].Comment != comment {
+		t.Fatalf("unexpected comments: %+v", cr.Comments)
+	}
+
+	// Authorize the vote
+	action := tkv1.AuthActionAuthorize
+	msg = token + strconv.FormatUint(uint64(version), 10) + string(action)
+	sig = author.Identity.SignMessage([]byte(msg))
+	_, err = author.Client.TicketVoteAuthorize(tkv1.Authorize{
+		Token:     token,
+		Version:   version,
+		Action:    action,
+		PublicKey: author.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("TicketVoteAuthorize: %v", err)
+	}
+	assertProposalStatus(t, h, token, piplugin.PropStatusVoteAuthorized)
+
+	// Start the vote
+	vp := voteParams(token, version, 10)
+	vpb, err := json.Marshal(vp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig = admin.Identity.SignMessage([]byte(hex.EncodeToString(util.Digest(vpb))))
+	_, err = admin.Client.TicketVoteStart(tkv1.Start{
+		Starts: []tkv1.StartDetails{
+			{
+				Params:    vp,
+				PublicKey: admin.Identity.Public.String(),
+				Signature: hex.EncodeToString(sig[:]),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("TicketVoteStart: %v", err)
+	}
+	assertProposalStatus(t, h, token, piplugin.PropStatusVoteStarted)
+
+	sr, err := h.Client.TicketVoteSummaries(tkv1.Summaries{
+		Tokens: []string{token},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sr.Summaries[token]
+	switch {
+	case s.Status != tkv1.VoteStatusStarted:
+		t.Fatalf("got vote status %v, want %v",
+			tkv1.VoteStatuses[s.Status],
+			tkv1.VoteStatuses[tkv1.VoteStatusStarted])
+	case int(s.EligibleTickets) != len(h.EligibleTickets()):
+		t.Fatalf("got %v eligible tickets, want %v",
+			s.EligibleTickets, len(h.EligibleTickets()))
+	}
+
+	// Cast votes using the eligible tickets. A ticket is not allowed
+	// to vote twice.
+	tickets := h.EligibleTickets()[:3]
+	for _, v := range h.CastVotes(t, token, "1", tickets) {
+		if v.ErrorCode != nil {
+			t.Fatalf("vote %v: %v %v", v.Ticket, *v.ErrorCode,
+				v.ErrorContext)
+		}
+	}
+	for _, v := range h.CastVotes(t, token, "1", tickets[:1]) {
+		if v.ErrorCode == nil ||
+			*v.ErrorCode != tkv1.VoteErrorTicketAlreadyVoted {
+			t.Fatalf("got vote error %v, want %v", v.ErrorCode,
+				tkv1.VoteErrorTicketAlreadyVoted)
+		}
+	}
+
+	// Votes that are not signed by the ticket commitment address
+	// are rejected.
+	unsigned := h.EligibleTickets()[3]
+	cbr, err := h.Client.TicketVoteCastBallot(tkv1.CastBallot{
+		Votes: []tkv1.CastVote{
+			{
+				Token:     token,
+				Ticket:    unsigned.Hash,
+				VoteBit:   "1",
+				Signature: tickets[0].Sign(token + unsigned.Hash + "1"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := cbr.Receipts[0]
+	if r.ErrorCode == nil || *r.ErrorCode != tkv1.VoteErrorSignatureInvalid {
+		t.Fatalf("got vote error %v, want %v", r.ErrorCode,
+			tkv1.VoteErrorSignatureInvalid)
+	}
+
+	rr, err := h.Client.TicketVoteResults(tkv1.Results{
+		Token: token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rr.Votes) != len(tickets) {
+		t.Fatalf("got %v cast votes, want %v", len(rr.Votes), len(tickets))
+	}
+}
+
+func TestUserActivityFsck(t *testing.T) {
+	h, cleanup := New(t)
+	defer cleanup()
+
+	var (
+		admin  = h.NewUser(t, true)
+		author = h.NewUser(t, false)
+		state  = cmv1.RecordStateVetted
+	)
+
+	// Create a proposal with a comment and a comment vote
+	token := h.NewProposal(t, author, admin)
+	commentID := h.NewComment(t, author, token, "An author comment")
+	msg := strconv.FormatUint(uint64(state), 10) + token +
+		strconv.FormatUint(uint64(commentID), 10) +
+		strconv.FormatInt(int64(cmv1.VoteUpvote), 10)
+	sig := admin.Identity.SignMessage([]byte(msg))
+	_, err := admin.Client.CommentVote(cmv1.Vote{
+		State:     state,
+		Token:     token,
+		CommentID: commentID,
+		Vote:      cmv1.VoteUpvote,
+		PublicKey: admin.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("CommentVote: %v", err)
+	}
+
+	// userActivity returns the full activity timeline of a user
+	userActivity := func(u *User) []usermd.Activity {
+		t.Helper()
+
+		activity, err := h.Politeiad.UserActivity(context.Background(),
+			usermd.UserActivity{
+				UserID:   u.ID,
+				Unvetted: true,
+			})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return activity
+	}
+	authorActivity := userActivity(author)
+	adminActivity := userActivity(admin)
+	if len(authorActivity) != 3 || len(adminActivity) != 1 {
+		t.Fatalf("got %v author and %v admin activity entries, want 3 "+
+			"and 1", len(authorActivity), len(adminActivity))
+	}
+
+	// The fsck rebuilds the activity of every user from the records
+	// and the comments. The activity that was added by the plugin
+	// hooks must be matched by the rebuilt activity so that no
+	// entries are duplicated.
+	h.Fsck(t)
+	if !reflect.DeepEqual(userActivity(author), authorActivity) ||
+		!reflect.DeepEqual(userActivity(admin), adminActivity) {
+		t.Fatalf("activity changed by fsck")
+	}
+}
+
+// assertProposalStatus verifies that the proposal has the provided status.
+func assertProposalStatus(t *testing.T, h *Harness, token string, want piplugin.PropStatusT) {
+	t.Helper()
+
+	sr, err := h.Client.PiSummaries(piv1.Summaries{
+		Tokens: []string{token},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := sr.Summaries[token].Status
+	if got != string(want) {
+		t.Fatalf("got proposal status %v, want %v", got, want)
+	}
+}
+
+func TestTransferOwnership(t *testing.T) {
+	h, cleanup := New(t)
+	defer cleanup()
+
+	var (
+		admin    = h.NewUser(t, true)
+		author   = h.NewUser(t, false)
+		newOwner = h.NewUser(t, false)
+	)
+	token := h.NewProposal(t, author, admin)
+
+	// transferOwnership signs and submits an ownership transfer
+	transferOwnership := func(from, to *User) (*rcv1.TransferOwnershipReply, error) {
+		tr := rcv1.TransferOwnership{
+			Token:      token,
+			Version:    1,
+			FromUserID: from.ID,
+			ToUserID:   to.ID,
+			Reason:     "author is no longer active",
+		}
+		msg := tr.Token + strconv.FormatUint(uint64(tr.Version), 10) +
+			tr.FromUserID + tr.ToUserID + tr.Reason
+		sig := from.Identity.SignMessage([]byte(msg))
+		tr.AuthorPublicKey = from.Identity.Public.String()
+		tr.AuthorSignature = hex.EncodeToString(sig[:])
+		sig = admin.Identity.SignMessage([]byte(msg + tr.AuthorSignature))
+		tr.PublicKey = admin.Identity.Public.String()
+		tr.Signature = hex.EncodeToString(sig[:])
+		return admin.Client.RecordTransferOwnership(tr)
+	}
+
+	// Transfer the ownership of the proposal
+	tor, err := transferOwnership(author, newOwner)
+	if err != nil {
+		t.Fatalf("RecordTransferOwnership: %v", err)
+	}
+	scs, err := pclient.StatusChangesDecode(tor.Record.Metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pclient.StatusChangesVerify(scs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ot := scs[len(scs)-1].OwnershipTransfer
+	switch {
+	case tor.Record.Status != rcv1.RecordStatusPublic:
+		t.Fatalf("got status %v, want public", tor.Record.Status)
+	case ot == nil || ot.FromUserID != author.ID || ot.ToUserID != newOwner.ID:
+		t.Fatalf("ownership transfer not found in the status changes")
+	}
+
+	// Verify the new owner is the record author
+	authors, err := h.Politeiad.Authors(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(authors) != 1 || authors[0] != newOwner.ID {
+		t.Fatalf("got authors %v, want %v", authors, newOwner.ID)
+	}
+	urr, err := h.Politeiad.UserRecords(context.Background(), newOwner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urr.Vetted) != 1 || urr.Vetted[0] != token {
+		t.Fatalf("got new owner records %v, want %v", urr.Vetted, token)
+	}
+
+	// The transfer is added to the activity timeline of both users.
+	// The fsck must rebuild the same activity.
+	ua := usermd.UserActivity{
+		UserID:   newOwner.ID,
+		Unvetted: true,
+	}
+	activity, err := h.Politeiad.UserActivity(context.Background(), ua)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(activity) != 1 ||
+		activity[0].Type != usermd.ActivityTypeOwnershipTransfer {
+		t.Fatalf("got new owner activity %+v, want a transfer", activity)
+	}
+	h.Fsck(t)
+	fsckActivity, err := h.Politeiad.UserActivity(context.Background(), ua)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fsckActivity, activity) {
+		t.Fatalf("activity changed by fsck")
+	}
+
+	// The previous owner is no longer a record author
+	_, err = transferOwnership(author, admin)
+	var re pclient.RespErr
+	switch {
+	case !errors.As(err, &re):
+		t.Fatalf("got error %v, want a response error", err)
+	case re.ErrorReply.PluginID != usermd.PluginID ||
+		re.ErrorReply.ErrorCode != int(usermd.ErrorCodeOwnershipTransferInvalid):
+		t.Fatalf("got error %v, want ownership transfer invalid", err)
+	}
+}
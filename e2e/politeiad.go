@@ -0,0 +1,127 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package e2e
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe"
+	cmplugin "github.com/decred/politeia/politeiad/plugins/comments"
+	ddplugin "github.com/decred/politeia/politeiad/plugins/dcrdata"
+	piplugin "github.com/decred/politeia/politeiad/plugins/pi"
+	tkplugin "github.com/decred/politeia/politeiad/plugins/ticketvote"
+	umplugin "github.com/decred/politeia/politeiad/plugins/usermd"
+	"github.com/decred/politeia/politeiad/server"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// politeiad RPC credentials
+	rpcUser = "user"
+	rpcPass = "pass"
+)
+
+// politeiad contains an in-process politeiad instance that uses a tstore
+// backend. The backend is setup for testing, i.e. it uses an in-memory tlog
+// client and a leveldb key-value store.
+type politeiad struct {
+	host     string // https://<host>:<port>
+	cert     string // Path to the https cert
+	identity *identity.FullIdentity
+	backend  backendv2.Backend
+	server   *http.Server
+}
+
+// newPoliteiad starts a new politeiad instance that writes its data to the
+// provided directory and that uses the provided dcrdata host for the dcrdata
+// plugin. A closure that stops politeiad is returned.
+func newPoliteiad(t *testing.T, dir, dcrdataHost string) (*politeiad, func()) {
+	t.Helper()
+
+	// Setup the identity and the TLS cert
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		certFile = filepath.Join(dir, "https.cert")
+		keyFile  = filepath.Join(dir, "https.key")
+	)
+	err = util.GenCertPair(elliptic.P521(), "politeiad", certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Setup the backend and register the plugins that are required by
+	// the politeiawww pi API.
+	b, cleanupBackend := tstorebe.NewTestTstoreBackend(t)
+	plugins := []backendv2.Plugin{
+		{
+			ID: ddplugin.PluginID,
+			Settings: []backendv2.PluginSetting{
+				{
+					Key:   ddplugin.SettingKeyHostHTTP,
+					Value: dcrdataHost,
+				},
+				{
+					Key:   ddplugin.SettingKeyHostWS,
+					Value: "ws" + dcrdataHost[len("http"):] + "/ps",
+				},
+			},
+		},
+		{ID: cmplugin.PluginID},
+		{ID: piplugin.PluginID},
+		{ID: tkplugin.PluginID},
+		{ID: umplugin.PluginID},
+	}
+	for _, v := range plugins {
+		v.Identity = id
+		err = b.PluginRegister(v)
+		if err != nil {
+			t.Fatalf("PluginRegister %v: %v", v.ID, err)
+		}
+	}
+	for _, v := range b.PluginInventory() {
+		err = b.PluginSetup(v.ID)
+		if err != nil {
+			t.Fatalf("PluginSetup %v: %v", v.ID, err)
+		}
+	}
+
+	// Setup the router and start the server
+	router := mux.NewRouter()
+	server.New(b, id).RegisterRoutes(router)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &http.Server{
+		Handler: router,
+	}
+	go func() {
+		_ = s.ServeTLS(l, certFile, keyFile)
+	}()
+
+	pd := politeiad{
+		host:     fmt.Sprintf("https://%v", l.Addr()),
+		cert:     certFile,
+		identity: id,
+		backend:  b,
+		server:   s,
+	}
+	return &pd, func() {
+		s.Close()
+		b.Close()
+		cleanupBackend()
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package e2e
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	pdclient "github.com/decred/politeia/politeiad/client"
+	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/politeiawww/legacy"
+	"github.com/decred/politeia/politeiawww/logger"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
+)
+
+const (
+	// csrfKeyLength is the length of the CSRF key in bytes.
+	csrfKeyLength = 32
+
+	// websocketReadLimit is the maximum number of bytes allowed for a
+	// message read from a websocket client.
+	websocketReadLimit = 4 * 1024 * 1024
+)
+
+// politeiawww contains an in-process politeiawww instance that serves the
+// legacy pi API.
+type politeiawww struct {
+	host    string // https://<host>:<port>
+	cert    string // Path to the https cert
+	key     string // Path to the https key
+	cfg     *config.Config
+	pd      *politeiad
+	csrfKey []byte
+	legacy  *legacy.Politeiawww
+	server  *http.Server
+}
+
+// newPoliteiawww starts a new politeiawww instance that writes its data to the
+// provided directory and that uses the provided politeiad instance. User
+// emails are disabled and the user paywall is turned off. A closure that
+// stops politeiawww is returned.
+func newPoliteiawww(t *testing.T, dir string, pd *politeiad) (*politeiawww, func()) {
+	t.Helper()
+
+	// Turn logging off. The politeiawww loggers write to stdout by
+	// default.
+	logger.SetLogLevels("off")
+
+	// Setup the TLS cert
+	var (
+		certFile = filepath.Join(dir, "https.cert")
+		keyFile  = filepath.Join(dir, "https.key")
+		dataDir  = filepath.Join(dir, "data")
+	)
+	err := util.GenCertPair(elliptic.P256(), "politeiawww", certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.MkdirAll(dataDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Setup config
+	cfg := &config.Config{
+		HomeDir:            dir,
+		DataDir:            dataDir,
+		TestNet:            true,
+		HTTPSCert:          certFile,
+		HTTPSKey:           keyFile,
+		CookieKeyFile:      filepath.Join(dir, "cookie.key"),
+		WebsocketReadLimit: websocketReadLimit,
+		RPCHost:            pd.host,
+		RPCCert:            pd.cert,
+		RPCUser:            rpcUser,
+		RPCPass:            rpcPass,
+		UserDB:             config.LevelDB,
+		LegacyConfig: config.LegacyConfig{
+			Mode: config.PiWWWMode,
+		},
+		Identity: &pd.identity.Public,
+	}
+
+	// The CSRF key is kept for the lifetime of the harness so that the
+	// CSRF tokens of the clients remain valid when politeiawww is
+	// restarted.
+	csrfKey, err := util.Random(csrfKeyLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	www := politeiawww{
+		host:    "https://127.0.0.1:0",
+		cert:    certFile,
+		key:     keyFile,
+		cfg:     cfg,
+		pd:      pd,
+		csrfKey: csrfKey,
+	}
+	www.start(t)
+
+	return &www, func() {
+		www.stop()
+	}
+}
+
+// start starts politeiawww. The server listens on the host of the previous
+// run when politeiawww is restarted.
+func (w *politeiawww) start(t *testing.T) {
+	t.Helper()
+
+	// Setup the router. Authenticated routes are registered onto a
+	// subrouter that is CSRF protected, the same as politeiawww does.
+	router := mux.NewRouter()
+	router.StrictSlash(true)
+	protected := router.NewRoute().Subrouter()
+	protected.Use(csrf.Protect(w.csrfKey, csrf.Path("/")))
+
+	// Setup politeiawww
+	pdc, err := pdclient.New(w.pd.host, w.pd.cert, rpcUser, rpcPass,
+		&w.pd.identity.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := legacy.NewPoliteiawww(w.cfg, router, protected,
+		chaincfg.TestNet3Params(), pdc)
+	if err != nil {
+		t.Fatalf("NewPoliteiawww: %v", err)
+	}
+
+	// Start the server
+	l, err := net.Listen("tcp", strings.TrimPrefix(w.host, "https://"))
+	if err != nil {
+		p.Close()
+		t.Fatal(err)
+	}
+	s := &http.Server{
+		Handler: router,
+	}
+	go func() {
+		_ = s.ServeTLS(l, w.cert, w.key)
+	}()
+
+	w.host = fmt.Sprintf("https://%v", l.Addr())
+	w.legacy = p
+	w.server = s
+}
+
+// stop stops politeiawww and closes its user database.
+func (w *politeiawww) stop() {
+	w.server.Close()
+	w.legacy.Close()
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package e2e
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/mime"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/util"
+)
+
+// NewProposal submits a new proposal on behalf of the author, makes it public
+// on behalf of the admin, and returns its token.
+func (h *Harness) NewProposal(t *testing.T, author, admin *User) string {
+	t.Helper()
+
+	pr, err := h.Client.PiPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := util.Random(8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := proposalFiles(t, "Proposal "+hex.EncodeToString(r),
+		pr.Domains[0])
+	nr, err := author.Client.RecordNew(rcv1.New{
+		Files:     files,
+		PublicKey: author.Identity.Public.String(),
+		Signature: signedMerkleRoot(t, author, files),
+	})
+	if err != nil {
+		t.Fatalf("RecordNew: %v", err)
+	}
+
+	var (
+		token   = nr.Record.CensorshipRecord.Token
+		version = nr.Record.Version
+		status  = rcv1.RecordStatusPublic
+	)
+	msg := token + strconv.FormatUint(uint64(version), 10) +
+		strconv.Itoa(int(status))
+	sig := admin.Identity.SignMessage([]byte(msg))
+	_, err = admin.Client.RecordSetStatus(rcv1.SetStatus{
+		Token:     token,
+		Version:   version,
+		Status:    status,
+		PublicKey: admin.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("RecordSetStatus: %v", err)
+	}
+
+	return token
+}
+
+// NewComment submits a new top level comment on a public record on behalf of
+// the user and returns the comment ID.
+func (h *Harness) NewComment(t *testing.T, u *User, token, comment string) uint32 {
+	t.Helper()
+
+	state := cmv1.RecordStateVetted
+	msg := strconv.FormatUint(uint64(state), 10) + token + "0" + comment
+	sig := u.Identity.SignMessage([]byte(msg))
+	nr, err := u.Client.CommentNew(cmv1.New{
+		State:     state,
+		Token:     token,
+		Comment:   comment,
+		PublicKey: u.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("CommentNew: %v", err)
+	}
+
+	return nr.Comment.CommentID
+}
+
+// StartVote authorizes the vote of a public proposal on behalf of the author
+// and starts it on behalf of the admin. The vote is a standard approve/reject
+// vote that lasts for the minimum vote duration.
+func (h *Harness) StartVote(t *testing.T, author, admin *User, token string) {
+	t.Helper()
+
+	r, err := h.Client.RecordDetails(rcv1.Details{
+		Token: token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	version := r.Version
+
+	// Authorize the vote
+	action := tkv1.AuthActionAuthorize
+	msg := token + strconv.FormatUint(uint64(version), 10) + string(action)
+	sig := author.Identity.SignMessage([]byte(msg))
+	_, err = author.Client.TicketVoteAuthorize(tkv1.Authorize{
+		Token:     token,
+		Version:   version,
+		Action:    action,
+		PublicKey: author.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("TicketVoteAuthorize: %v", err)
+	}
+
+	// Start the vote
+	pr, err := h.Client.TicketVotePolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp := voteParams(token, version, pr.VoteDurationMin)
+	vpb, err := json.Marshal(vp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig = admin.Identity.SignMessage([]byte(hex.EncodeToString(util.Digest(vpb))))
+	_, err = admin.Client.TicketVoteStart(tkv1.Start{
+		Starts: []tkv1.StartDetails{
+			{
+				Params:    vp,
+				PublicKey: admin.Identity.Public.String(),
+				Signature: hex.EncodeToString(sig[:]),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("TicketVoteStart: %v", err)
+	}
+}
+
+// CastVotes casts a vote on a proposal using each of the provided tickets and
+// returns the ballot receipts.
+func (h *Harness) CastVotes(t *testing.T, token, voteBit string, tickets []Ticket) []tkv1.CastVoteReply {
+	t.Helper()
+
+	votes := make([]tkv1.CastVote, 0, len(tickets))
+	for _, v := range tickets {
+		votes = append(votes, tkv1.CastVote{
+			Token:     token,
+			Ticket:    v.Hash,
+			VoteBit:   voteBit,
+			Signature: v.Sign(token + v.Hash + voteBit),
+		})
+	}
+	cbr, err := h.Client.TicketVoteCastBallot(tkv1.CastBallot{
+		Votes: votes,
+	})
+	if err != nil {
+		t.Fatalf("TicketVoteCastBallot: %v", err)
+	}
+
+	return cbr.Receipts
+}
+
+// voteParams returns the parameters of a standard approve/reject vote.
+func voteParams(token string, version, duration uint32) tkv1.VoteParams {
+	return tkv1.VoteParams{
+		Token:            token,
+		Version:          version,
+		Type:             tkv1.VoteTypeStandard,
+		Mask:             0x03,
+		Duration:         duration,
+		QuorumPercentage: 20,
+		PassPercentage:   60,
+		Options: []tkv1.VoteOption{
+			{
+				ID:          tkv1.VoteOptionIDApprove,
+				Description: "Approve the proposal",
+				Bit:         0x01,
+			},
+			{
+				ID:          tkv1.VoteOptionIDReject,
+				Description: "Reject the proposal",
+				Bit:         0x02,
+			},
+		},
+	}
+}
+
+// proposalFiles returns the files of a proposal that has the provided index
+// file text and domain.
+func proposalFiles(t *testing.T, text, domain string) []rcv1.File {
+	t.Helper()
+
+	pm := piv1.ProposalMetadata{
+		Name:      "Test proposal",
+		Amount:    2000000, // $20k in cents
+		StartDate: time.Now().Add(30 * 24 * time.Hour).Unix(),
+		EndDate:   time.Now().Add(120 * 24 * time.Hour).Unix(),
+		Domain:    domain,
+	}
+	pmb, err := json.Marshal(pm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []rcv1.File{
+		newFile(piv1.FileNameIndexFile, []byte(text+"\n")),
+		newFile(piv1.FileNameProposalMetadata, pmb),
+	}
+}
+
+// newFile returns a record file for the provided payload.
+func newFile(name string, payload []byte) rcv1.File {
+	return rcv1.File{
+		Name:    name,
+		MIME:    mime.DetectMimeType(payload),
+		Digest:  hex.EncodeToString(util.Digest(payload)),
+		Payload: base64.StdEncoding.EncodeToString(payload),
+	}
+}
+
+// signedMerkleRoot returns the user signature of the merkle root of the
+// provided files.
+func signedMerkleRoot(t *testing.T, u *User, files []rcv1.File) string {
+	t.Helper()
+
+	digests := make([]string, 0, len(files))
+	for _, v := range files {
+		digests = append(digests, v.Digest)
+	}
+	m, err := util.MerkleRoot(digests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := u.Identity.SignMessage([]byte(hex.EncodeToString(m[:])))
+	return hex.EncodeToString(sig[:])
+}
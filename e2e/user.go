@@ -0,0 +1,249 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package e2e
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/politeiawww/legacy/user/localdb"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/schema"
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/net/publicsuffix"
+)
+
+// User is a politeiawww user that has been registered, verified, and logged
+// in.
+type User struct {
+	ID       string
+	Username string
+	Email    string
+	Password string
+	Admin    bool
+	Identity *identity.FullIdentity
+
+	// Cookies and CSRF are the session cookies and the header CSRF
+	// token of the user.
+	Cookies []*http.Cookie
+	CSRF    string
+
+	// Client is a politeiawww client that uses the session of the
+	// user. It can be used to send requests to authenticated routes.
+	Client *pclient.Client
+}
+
+// NewUser registers and verifies a new user with random credentials, logs
+// the user in, and returns it. The user is made an admin if admin is true.
+func (h *Harness) NewUser(t *testing.T, admin bool) *User {
+	t.Helper()
+
+	// Setup the user credentials
+	r, err := util.Random(int(www.PolicyMinPasswordLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := User{
+		Username: hex.EncodeToString(r),
+		Email:    hex.EncodeToString(r) + "@example.com",
+		Password: hex.EncodeToString(r),
+		Admin:    admin,
+		Identity: id,
+	}
+
+	// Register and verify the user. The verification token is
+	// returned in the reply since emails are disabled.
+	c, err := newWWWClient(h.www.host, h.www.cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nu := www.NewUser{
+		Email:     u.Email,
+		Username:  u.Username,
+		Password:  digestSHA3(u.Password),
+		PublicKey: id.Public.String(),
+	}
+	var nur www.NewUserReply
+	err = c.do(http.MethodPost, www.RouteNewUser, nu, &nur)
+	if err != nil {
+		t.Fatalf("NewUser: %v", err)
+	}
+	sig := id.SignMessage([]byte(nur.VerificationToken))
+	vnu := www.VerifyNewUser{
+		Email:             u.Email,
+		VerificationToken: nur.VerificationToken,
+		Signature:         hex.EncodeToString(sig[:]),
+	}
+	err = c.do(http.MethodGet, www.RouteVerifyNewUser, &vnu,
+		&www.VerifyNewUserReply{})
+	if err != nil {
+		t.Fatalf("VerifyNewUser: %v", err)
+	}
+
+	// Make the user an admin. There is no API route for this.
+	if admin {
+		h.setAdmin(t, u.Username)
+	}
+
+	// Login and setup the user's client
+	l := www.Login{
+		Email:    u.Email,
+		Password: digestSHA3(u.Password),
+	}
+	var lr www.LoginReply
+	err = c.do(http.MethodPost, www.RouteLogin, l, &lr)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if lr.IsAdmin != admin {
+		t.Fatalf("got admin %v, want %v", lr.IsAdmin, admin)
+	}
+	u.ID = lr.UserID
+	u.Cookies = c.http.Jar.Cookies(c.url)
+	u.CSRF = c.csrf
+	u.Client, err = pclient.New(h.www.host, pclient.Opts{
+		HTTPSCert:  h.www.cert,
+		Cookies:    u.Cookies,
+		HeaderCSRF: u.CSRF,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &u
+}
+
+// setAdmin makes the user with the provided username an admin by updating the
+// user record in the user database, the same as politeiawww_dbutil does.
+// politeiawww holds the lock on the leveldb user database so it is stopped
+// while the database is updated and is then restarted.
+func (h *Harness) setAdmin(t *testing.T, username string) {
+	t.Helper()
+
+	h.www.stop()
+	defer h.www.start(t)
+
+	db, err := localdb.New(h.www.cfg.DataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	u, err := db.UserGetByUsername(username)
+	if err != nil {
+		t.Fatalf("UserGetByUsername: %v", err)
+	}
+	u.Admin = true
+	err = db.UserUpdate(*u)
+	if err != nil {
+		t.Fatalf("UserUpdate: %v", err)
+	}
+}
+
+// wwwClient is a minimal client for the politeiawww www v1 user routes. The
+// typed politeiawww client does not support these routes.
+type wwwClient struct {
+	url  *url.URL
+	csrf string // Header CSRF token
+	http *http.Client
+}
+
+// newWWWClient returns a new wwwClient that has fetched the CSRF tokens.
+func newWWWClient(host, cert string) (*wwwClient, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	h, err := util.NewHTTPClient(false, cert)
+	if err != nil {
+		return nil, err
+	}
+	h.Jar, err = cookiejar.New(&cookiejar.Options{
+		PublicSuffixList: publicsuffix.List,
+	})
+	if err != nil {
+		return nil, err
+	}
+	c := wwwClient{
+		url:  u,
+		http: h,
+	}
+
+	// The version route sets the CSRF cookie and returns the
+	// header CSRF token.
+	r, err := c.http.Get(host + www.PoliteiaWWWAPIRoute + www.RouteVersion)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("version: %v %s", r.StatusCode, util.RespBody(r))
+	}
+	c.csrf = r.Header.Get(www.CsrfToken)
+
+	return &c, nil
+}
+
+// do sends a request to the provided www v1 route and decodes the reply into
+// the provided reply object. GET requests encode the request object as query
+// params.
+func (c *wwwClient) do(method, route string, v, reply interface{}) error {
+	fullRoute := c.url.String() + www.PoliteiaWWWAPIRoute + route
+	var body []byte
+	switch method {
+	case http.MethodGet:
+		form := url.Values{}
+		err := schema.NewEncoder().Encode(v, form)
+		if err != nil {
+			return err
+		}
+		fullRoute += "?" + form.Encode()
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		body = b
+	}
+
+	req, err := http.NewRequest(method, fullRoute, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(www.CsrfToken, c.csrf)
+	r, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	respBody := util.RespBody(r)
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v %v: %v %s", method, route, r.StatusCode,
+			respBody)
+	}
+	return json.Unmarshal(respBody, reply)
+}
+
+// digestSHA3 returns the hex encoded SHA3-256 digest of a string. Passwords
+// are hashed client side before being sent to politeiawww.
+func digestSHA3(s string) string {
+	h := sha3.New256()
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -8,6 +8,7 @@ require (
 	github.com/dajohi/goemail v1.0.0
 	github.com/davecgh/go-spew v1.1.1
 	github.com/decred/dcrd/blockchain/stake/v3 v3.0.0
+	github.com/decred/dcrd/blockchain/standalone/v2 v2.0.0
 	github.com/decred/dcrd/certgen v1.1.1
 	github.com/decred/dcrd/chaincfg/chainhash v1.0.3-0.20200921185235-6d75c7ec1199
 	github.com/decred/dcrd/chaincfg/v3 v3.0.0
@@ -39,6 +40,7 @@ require (
 	github.com/jessevdk/go-flags v1.4.1-0.20200711081900-c17162fe8fd7
 	github.com/jinzhu/gorm v1.9.12
 	github.com/jrick/logrotate v1.0.0
+	github.com/klauspost/cpuid/v2 v2.0.11 // indirect
 	github.com/lib/pq v1.9.0 // indirect
 	github.com/marcopeereboom/sbox v1.1.0
 	github.com/otiai10/copy v1.2.0
@@ -54,4 +56,5 @@ require (
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c
 	google.golang.org/genproto v0.0.0-20220422154200-b37d22cd5731
 	google.golang.org/grpc v1.46.0
+	lukechampine.com/blake3 v1.1.7
 )
@@ -242,6 +242,39 @@ func (p *piPlugin) cmdBillingStatusChanges(token []byte) (string, error) {
 	return string(reply), nil
 }
 
+// cmdBillingStatusTimestamps returns the timestamps of the billing status
+// changes of a proposal.
+func (p *piPlugin) cmdBillingStatusTimestamps(token []byte) (string, error) {
+	// Get the billing status change digests. The digests are ordered
+	// from oldest to newest.
+	digests, err := p.tstore.DigestsByDataDesc(token,
+		[]string{dataDescriptorBillingStatus})
+	if err != nil {
+		return "", fmt.Errorf("DigestsByDataDesc %x %v: %v",
+			token, dataDescriptorBillingStatus, err)
+	}
+
+	// Get the timestamps
+	timestamps := make([]pi.Timestamp, 0, len(digests))
+	for _, v := range digests {
+		ts, err := p.timestamp(token, v)
+		if err != nil {
+			return "", err
+		}
+		timestamps = append(timestamps, *ts)
+	}
+
+	// Prepare reply
+	reply, err := json.Marshal(pi.BillingStatusTimestampsReply{
+		Timestamps: timestamps,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(reply), nil
+}
+
 // cmdSummary returns the pi summary of a proposal.
 func (p *piPlugin) cmdSummary(token []byte) (string, error) {
 	// Get the proposal status
@@ -405,6 +438,34 @@ func (p *piPlugin) billingStatusChanges(token []byte) ([]pi.BillingStatusChange,
 	return statusChanges, nil
 }
 
+// timestamp returns the timestamp for a specific piece of data.
+func (p *piPlugin) timestamp(token []byte, digest []byte) (*pi.Timestamp, error) {
+	t, err := p.tstore.Timestamp(token, digest)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp %x %x: %v",
+			token, digest, err)
+	}
+
+	// Convert response
+	proofs := make([]pi.Proof, 0, len(t.Proofs))
+	for _, v := range t.Proofs {
+		proofs = append(proofs, pi.Proof{
+			Type:       v.Type,
+			Digest:     v.Digest,
+			MerkleRoot: v.MerkleRoot,
+			MerklePath: v.MerklePath,
+			ExtraData:  v.ExtraData,
+		})
+	}
+	return &pi.Timestamp{
+		Data:       t.Data,
+		Digest:     t.Digest,
+		TxID:       t.TxID,
+		MerkleRoot: t.MerkleRoot,
+		Proofs:     proofs,
+	}, nil
+}
+
 // billingStatusEncode encodes a BillingStatusChange into a BlobEntry.
 func billingStatusEncode(bsc pi.BillingStatusChange) (*store.BlobEntry, error) {
 	data, err := json.Marshal(bsc)
@@ -336,6 +336,13 @@ func (p *piPlugin) proposalFilesVerify(files []backend.File) error {
 		}
 	}
 
+	// Verify the index file contains the sections that are required
+	// for the proposal domain.
+	err = p.proposalSectionsVerify(files, pm.Domain)
+	if err != nil {
+		return err
+	}
+
 	// Ensure legacy token is not set during normal proposal submissions
 	if pm.LegacyToken != "" {
 		return backend.PluginError{
@@ -454,20 +461,38 @@ func latestAuthorUpdate(token []byte, cs []comments.Comment) *comments.Comment {
 	return &latestAuthorUpdate
 }
 
-// recordAuthor returns the author's userID of the record associated with
-// the provided token.
-func (p *piPlugin) recordAuthor(token []byte) (string, error) {
+// recordAuthors returns the user IDs of all of the authors of the record
+// associated with the provided token.
+func (p *piPlugin) recordAuthors(token []byte) ([]string, error) {
 	reply, err := p.backend.PluginRead(token, usermd.PluginID,
 		usermd.CmdAuthor, "")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	var ar usermd.AuthorReply
 	err = json.Unmarshal([]byte(reply), &ar)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	authors := make([]string, 0, len(ar.CoAuthors)+1)
+	authors = append(authors, ar.UserID)
+	authors = append(authors, ar.CoAuthors...)
+	return authors, nil
+}
+
+// isRecordAuthor returns whether the provided user ID is one of the authors
+// of the record associated with the provided token.
+func (p *piPlugin) isRecordAuthor(token []byte, userID string) (bool, error) {
+	authors, err := p.recordAuthors(token)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range authors {
+		if v == userID {
+			return true, nil
+		}
 	}
-	return ar.UserID, nil
+	return false, nil
 }
 
 // commentVoteAllowedOnApprovedProposal verifies that the given comment
@@ -497,16 +522,15 @@ func (p *piPlugin) commentVoteAllowedOnApprovedProposal(token []byte, payload st
 // update.
 //
 // The comment must include proper proposal update metadata and the comment
-// must be submitted by the proposal author for it to be considered a valid
-// author update.
+// must be submitted by one of the proposal authors for it to be considered a
+// valid author update.
 func (p *piPlugin) isValidAuthorUpdate(token []byte, n comments.New) error {
-	// Get the proposal author. The proposal author
-	// and the comment author must be the same user.
-	recordAuthorID, err := p.recordAuthor(token)
+	// The comment author must be one of the proposal authors.
+	isAuthor, err := p.isRecordAuthor(token, n.UserID)
 	if err != nil {
 		return err
 	}
-	if recordAuthorID != n.UserID {
+	if !isAuthor {
 		return backend.PluginError{
 			PluginID:     pi.PluginID,
 			ErrorCode:    uint32(pi.ErrorCodeCommentWriteNotAllowed),
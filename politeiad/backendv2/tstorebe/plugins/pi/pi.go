@@ -66,6 +66,10 @@ type piPlugin struct {
 	billingStatusChangesMax      uint32
 	summariesPageSize            uint32
 	billingStatusChangesPageSize uint32
+	proposalSectionsEncoded      string // JSON encoded []string
+	proposalSections             []string
+	proposalTemplatesEncoded     string // JSON encoded []pi.ProposalTemplate
+	proposalTemplates            map[string]pi.ProposalTemplate // [domain]template
 }
 
 // Setup performs any plugin setup that is required.
@@ -90,6 +94,8 @@ func (p *piPlugin) Cmd(token []byte, cmd, payload string) (string, error) {
 		return p.cmdSummary(token)
 	case pi.CmdBillingStatusChanges:
 		return p.cmdBillingStatusChanges(token)
+	case pi.CmdBillingStatusTimestamps:
+		return p.cmdBillingStatusTimestamps(token)
 	}
 
 	return "", backend.ErrPluginCmdInvalid
@@ -190,6 +196,14 @@ func (p *piPlugin) Settings() []backend.PluginSetting {
 			Key:   pi.SettingKeyBillingStatusChangesPageSize,
 			Value: strconv.FormatUint(uint64(p.billingStatusChangesPageSize), 10),
 		},
+		{
+			Key:   pi.SettingKeyProposalSections,
+			Value: p.proposalSectionsEncoded,
+		},
+		{
+			Key:   pi.SettingKeyProposalTemplates,
+			Value: p.proposalTemplatesEncoded,
+		},
 	}
 }
 
@@ -218,6 +232,8 @@ func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backen
 		billingStatusChangesMax      = pi.SettingBillingStatusChangesMax
 		summariesPageSize            = pi.SettingSummariesPageSize
 		billingStatusChangesPageSize = pi.SettingBillingStatusChangesPageSize
+		sections                     = pi.SettingProposalSections
+		templates                    = pi.SettingProposalTemplates
 	)
 
 	// Override defaults with any passed in settings
@@ -338,6 +354,20 @@ func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backen
 			}
 			billingStatusChangesPageSize = uint32(u)
 
+		case pi.SettingKeyProposalSections:
+			err := json.Unmarshal([]byte(v.Value), &sections)
+			if err != nil {
+				return nil, errors.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+
+		case pi.SettingKeyProposalTemplates:
+			err := json.Unmarshal([]byte(v.Value), &templates)
+			if err != nil {
+				return nil, errors.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+
 		default:
 			return nil, errors.Errorf("invalid plugin setting: %v", v.Key)
 		}
@@ -372,6 +402,29 @@ func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backen
 		domainsMap[d] = struct{}{}
 	}
 
+	// Verify the proposal sections and templates and encode them so
+	// that they can be returned as plugin setting strings.
+	for _, v := range sections {
+		if util.MarkdownHeadingNormalize(v) == "" {
+			return nil, errors.Errorf("proposal section cannot be empty")
+		}
+	}
+	b, err = json.Marshal(sections)
+	if err != nil {
+		return nil, err
+	}
+	sectionsString := string(b)
+
+	templatesMap, err := proposalTemplatesMap(templates, domainsMap)
+	if err != nil {
+		return nil, err
+	}
+	b, err = json.Marshal(templates)
+	if err != nil {
+		return nil, err
+	}
+	templatesString := string(b)
+
 	return &piPlugin{
 		dataDir:                      dataDir,
 		identity:                     id,
@@ -393,6 +446,10 @@ func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backen
 		billingStatusChangesMax:      billingStatusChangesMax,
 		summariesPageSize:            summariesPageSize,
 		billingStatusChangesPageSize: billingStatusChangesPageSize,
+		proposalSectionsEncoded:      sectionsString,
+		proposalSections:             sections,
+		proposalTemplatesEncoded:     templatesString,
+		proposalTemplates:            templatesMap,
 		statuses: proposalStatuses{
 			data:    make(map[string]*statusEntry, statusesCacheLimit),
 			entries: list.New(),
@@ -0,0 +1,96 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/plugins/pi"
+	"github.com/decred/politeia/util"
+	"github.com/pkg/errors"
+)
+
+// proposalTemplatesMap verifies the provided proposal templates and returns
+// them as a map keyed by domain. Each template must reference a supported
+// proposal domain and a domain can only have a single template.
+func proposalTemplatesMap(templates []pi.ProposalTemplate, domains map[string]struct{}) (map[string]pi.ProposalTemplate, error) {
+	m := make(map[string]pi.ProposalTemplate, len(templates))
+	for _, v := range templates {
+		if _, ok := domains[v.Domain]; !ok {
+			return nil, errors.Errorf("proposal template domain '%v' is "+
+				"not a supported proposal domain", v.Domain)
+		}
+		if _, ok := m[v.Domain]; ok {
+			return nil, errors.Errorf("duplicate proposal template for "+
+				"domain '%v'", v.Domain)
+		}
+		for _, s := range v.Sections {
+			if util.MarkdownHeadingNormalize(s) == "" {
+				return nil, errors.Errorf("proposal template for domain "+
+					"'%v' contains an empty section", v.Domain)
+			}
+		}
+		m[v.Domain] = v
+	}
+	return m, nil
+}
+
+// requiredSections returns the markdown sections that the index file of a
+// proposal with the provided domain is required to contain.
+func (p *piPlugin) requiredSections(domain string) []string {
+	sections := make([]string, 0, len(p.proposalSections))
+	sections = append(sections, p.proposalSections...)
+	if t, ok := p.proposalTemplates[domain]; ok {
+		sections = append(sections, t.Sections...)
+	}
+	return sections
+}
+
+// proposalSectionsVerify verifies that the proposal index file contains all
+// of the markdown sections that are required for the provided domain.
+func (p *piPlugin) proposalSectionsVerify(files []backend.File, domain string) error {
+	required := p.requiredSections(domain)
+	if len(required) == 0 {
+		// Nothing to verify
+		return nil
+	}
+
+	// Find the index file
+	var index string
+	for _, v := range files {
+		if v.Name != pi.FileNameIndexFile {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(v.Payload)
+		if err != nil {
+			return errors.Errorf("invalid base64 %v", v.Name)
+		}
+		index = string(b)
+		break
+	}
+
+	// Verify that all required sections are present
+	headings := util.MarkdownHeadings(index)
+	missing := make([]string, 0, len(required))
+	for _, v := range required {
+		if _, ok := headings[util.MarkdownHeadingNormalize(v)]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return backend.PluginError{
+			PluginID:  pi.PluginID,
+			ErrorCode: uint32(pi.ErrorCodeIndexFileSectionMissing),
+			ErrorContext: fmt.Sprintf("%v is missing the following "+
+				"sections: %v", pi.FileNameIndexFile,
+				strings.Join(missing, ", ")),
+		}
+	}
+
+	return nil
+}
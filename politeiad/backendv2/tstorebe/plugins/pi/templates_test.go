@@ -0,0 +1,157 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"errors"
+	"testing"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/plugins/pi"
+)
+
+func TestProposalSectionsVerify(t *testing.T) {
+	// Setup pi plugin
+	p, cleanup := newTestPiPlugin(t)
+	defer cleanup()
+
+	domains := make(map[string]struct{}, len(pi.SettingProposalDomains))
+	for _, v := range pi.SettingProposalDomains {
+		domains[v] = struct{}{}
+	}
+	templates, err := proposalTemplatesMap([]pi.ProposalTemplate{
+		{
+			Domain:   "development",
+			Sections: []string{"Timeline"},
+		},
+	}, domains)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.proposalSections = []string{"Budget"}
+	p.proposalTemplates = templates
+
+	// Setup test files
+	var (
+		indexBudget = file(pi.FileNameIndexFile,
+			[]byte("# Budget\nPay me."))
+		indexBudgetTimeline = file(pi.FileNameIndexFile,
+			[]byte("# Budget\nPay me.\n\n## Timeline\nSoon."))
+	)
+
+	// errSectionMissing is returned when the index file is
+	// missing a required section.
+	errSectionMissing := backend.PluginError{
+		PluginID:  pi.PluginID,
+		ErrorCode: uint32(pi.ErrorCodeIndexFileSectionMissing),
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name   string         // Test name
+		files  []backend.File // Proposal files
+		domain string         // Proposal domain
+		err    error          // Expected error
+	}{
+		{
+			"global section missing",
+			[]backend.File{fileProposalIndex()},
+			"marketing",
+			errSectionMissing,
+		},
+		{
+			"global section present",
+			[]backend.File{indexBudget},
+			"marketing",
+			nil,
+		},
+		{
+			"domain section missing",
+			[]backend.File{indexBudget},
+			"development",
+			errSectionMissing,
+		},
+		{
+			"domain section present",
+			[]backend.File{indexBudgetTimeline},
+			"development",
+			nil,
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := p.proposalSectionsVerify(v.files, v.domain)
+			switch {
+			case v.err == nil && err != nil:
+				t.Errorf("want error nil, got '%v'", err)
+			case v.err != nil && err == nil:
+				t.Errorf("want error '%v', got nil", v.err)
+			case v.err != nil && err != nil:
+				var pe backend.PluginError
+				if !errors.As(err, &pe) {
+					t.Errorf("want plugin error, got '%v'", err)
+					return
+				}
+				if pe.ErrorCode != uint32(pi.ErrorCodeIndexFileSectionMissing) {
+					t.Errorf("want error code %v, got %v",
+						pi.ErrorCodeIndexFileSectionMissing, pe.ErrorCode)
+				}
+			}
+		})
+	}
+}
+
+func TestProposalTemplatesMap(t *testing.T) {
+	domains := map[string]struct{}{
+		"development": {},
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name      string                // Test name
+		templates []pi.ProposalTemplate // Input
+		wantErr   bool                  // Expected error
+	}{
+		{
+			"unsupported domain",
+			[]pi.ProposalTemplate{{Domain: "marketing"}},
+			true,
+		},
+		{
+			"duplicate domain",
+			[]pi.ProposalTemplate{
+				{Domain: "development"},
+				{Domain: "development"},
+			},
+			true,
+		},
+		{
+			"empty section",
+			[]pi.ProposalTemplate{
+				{Domain: "development", Sections: []string{" "}},
+			},
+			true,
+		},
+		{
+			"success",
+			[]pi.ProposalTemplate{
+				{Domain: "development", Sections: []string{"Budget"}},
+			},
+			false,
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			_, err := proposalTemplatesMap(v.templates, domains)
+			if (err != nil) != v.wantErr {
+				t.Errorf("got error '%v', want error %v", err, v.wantErr)
+			}
+		})
+	}
+}
@@ -216,6 +216,14 @@ type TstoreClient interface {
 	// RecordState returns whether the record is unvetted or vetted.
 	RecordState(token []byte) (backend.StateT, error)
 
+	// RecordMetadataAppend appends the provided metadata streams onto
+	// the metadata of the most recent version of a record and saves
+	// the result as a new iteration of the record. Plugins are only
+	// allowed to append onto their own metadata streams. The plugin
+	// hooks are not executed. This method must be called WITH the
+	// record lock held, which is the case for plugin write commands.
+	RecordMetadataAppend(token []byte, mdAppend []backend.MetadataStream) error
+
 	// CachePut saves the provided key-value pairs to the key-value store. It
 	// prefixes the keys with the plugin ID in order to limit the access of the
 	// plugins only to the data they own.
@@ -0,0 +1,616 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package usermd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/backendv2/tstorebe/plugins"
+	"github.com/decred/politeia/politeiad/plugins/comments"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+)
+
+const (
+	// fnActivityCache is the filename for the activity cache of a user
+	// that is saved to the plugin data dir.
+	//
+	// The activity cache contains the activity timeline of the user. The
+	// timeline is built using the plugin hooks as the activity occurs.
+	// The file contains a stream of JSON encoded usermd Activity entries,
+	// one per line, so that new activity can be appended to the file
+	// without rewriting it. The activity is sorted from oldest to newest.
+	fnActivityCache = "{userid}-activity.json"
+)
+
+var (
+	// errActivityCacheCorrupt is returned when an activity cache file
+	// cannot be decoded.
+	errActivityCacheCorrupt = errors.New("activity cache is corrupt")
+)
+
+// activityCachePath returns the filepath to the activity cache for the
+// specified user.
+func (p *usermdPlugin) activityCachePath(userID string) string {
+	fn := strings.Replace(fnActivityCache, "{userid}", userID, 1)
+	return filepath.Join(p.dataDir, fn)
+}
+
+// activityCacheLocked returns the cached activity timeline for the specified
+// user. An errActivityCacheCorrupt is returned if the cache file cannot be
+// decoded.
+//
+// This function must be called WITH the lock held.
+func (p *usermdPlugin) activityCacheLocked(userID string) ([]usermd.Activity, error) {
+	f, err := os.Open(p.activityCachePath(userID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// File doesn't exist. Return an empty activity timeline.
+			return []usermd.Activity{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	activity := make([]usermd.Activity, 0, 64)
+	d := json.NewDecoder(f)
+	for {
+		var a usermd.Activity
+		err := d.Decode(&a)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %v: %v",
+				errActivityCacheCorrupt, userID, err)
+		}
+		activity = append(activity, a)
+	}
+
+	return activity, nil
+}
+
+// activityCache returns the cached activity timeline for the specified user.
+//
+// This function must be called WITHOUT the lock held.
+func (p *usermdPlugin) activityCache(userID string) ([]usermd.Activity, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	return p.activityCacheLocked(userID)
+}
+
+// activityCacheSaveLocked replaces the cached activity timeline of the
+// specified user with the provided activity. The activity is written to a
+// temporary file that is then renamed so that the existing cache is not lost
+// if the write fails.
+//
+// This function must be called WITH the lock held.
+func (p *usermdPlugin) activityCacheSaveLocked(userID string, activity []usermd.Activity) error {
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	for _, v := range activity {
+		err := e.Encode(v)
+		if err != nil {
+			return err
+		}
+	}
+
+	fp := p.activityCachePath(userID)
+	tmp := fp + ".tmp"
+	err := ioutil.WriteFile(tmp, b.Bytes(), 0664)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, fp)
+}
+
+// activityAdd adds an activity entry to the activity timeline of the provided
+// users. The entry is appended to the activity cache of each user.
+//
+// This function must be called WITHOUT the lock held.
+func (p *usermdPlugin) activityAdd(userIDs []string, a usermd.Activity) error {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	p.Lock()
+	defer p.Unlock()
+
+	for _, userID := range userIDs {
+		f, err := os.OpenFile(p.activityCachePath(userID),
+			os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0664)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(b)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		err = f.Close()
+		if err != nil {
+			return err
+		}
+
+		log.Debugf("User activity add %v %v %v",
+			userID, usermd.Activities[a.Type], a.Token)
+	}
+
+	return nil
+}
+
+// activityPage returns the requested page of activity entries, sorted from
+// newest to oldest. Activity that occurred on unvetted records is filtered
+// out unless the unvetted argument is set to true. Page numbering starts at
+// 1. A page number of 0 returns the first page.
+func activityPage(activity []usermd.Activity, pageSize, page uint32, unvetted bool) []usermd.Activity {
+	if page == 0 {
+		page = 1
+	}
+	var (
+		startIdx = int((page - 1) * pageSize)
+		endIdx   = startIdx + int(pageSize)
+		entries  = make([]usermd.Activity, 0, pageSize)
+		idx      int
+	)
+	for i := len(activity) - 1; i >= 0 && idx < endIdx; i-- {
+		a := activity[i]
+		if !unvetted && backend.StateT(a.State) == backend.StateUnvetted {
+			continue
+		}
+		if idx >= startIdx {
+			entries = append(entries, a)
+		}
+		idx++
+	}
+	return entries
+}
+
+// cmdUserActivity returns a page of the activity timeline of a user.
+func (p *usermdPlugin) cmdUserActivity(payload string) (string, error) {
+	// Decode payload
+	var ua usermd.UserActivity
+	err := json.Unmarshal([]byte(payload), &ua)
+	if err != nil {
+		return "", err
+	}
+
+	// Get the requested page of activity
+	ac, err := p.activityCache(ua.UserID)
+	if err != nil {
+		return "", err
+	}
+	activity := activityPage(ac, p.userActivityPageSize,
+		ua.Page, ua.Unvetted)
+
+	// Prepare reply
+	uar := usermd.UserActivityReply{
+		Activity: activity,
+	}
+	reply, err := json.Marshal(uar)
+	if err != nil {
+		return "", err
+	}
+
+	return string(reply), nil
+}
+
+// hookPluginPost adds the comment activity of a user to their activity
+// timeline once the comments plugin command has been executed successfully.
+func (p *usermdPlugin) hookPluginPost(payload string) error {
+	var hpp plugins.HookPluginPost
+	err := json.Unmarshal([]byte(payload), &hpp)
+	if err != nil {
+		return err
+	}
+	if hpp.PluginID != comments.PluginID {
+		// Only comments activity is tracked
+		return nil
+	}
+
+	switch hpp.Cmd {
+	case comments.CmdNew:
+		var nr comments.NewReply
+		err = json.Unmarshal([]byte(hpp.Reply), &nr)
+		if err != nil {
+			return err
+		}
+		return p.commentActivityAdd(usermd.ActivityTypeCommentNew,
+			nr.Comment)
+
+	case comments.CmdEdit:
+		var er comments.EditReply
+		err = json.Unmarshal([]byte(hpp.Reply), &er)
+		if err != nil {
+			return err
+		}
+		return p.commentActivityAdd(usermd.ActivityTypeCommentEdit,
+			er.Comment)
+
+	case comments.CmdVote:
+		var v comments.Vote
+		err = json.Unmarshal([]byte(hpp.Payload), &v)
+		if err != nil {
+			return err
+		}
+		var vr comments.VoteReply
+		err = json.Unmarshal([]byte(hpp.Reply), &vr)
+		if err != nil {
+			return err
+		}
+		return p.activityAdd([]string{v.UserID}, usermd.Activity{
+			Type:      usermd.ActivityTypeCommentVote,
+			Token:     v.Token,
+			State:     uint32(convertStateFromComments(v.State)),
+			CommentID: v.CommentID,
+			Vote:      int32(v.Vote),
+			Timestamp: vr.Timestamp,
+		})
+	}
+
+	return nil
+}
+
+// commentActivityAdd adds a comment activity entry to the activity timeline
+// of the comment author.
+func (p *usermdPlugin) commentActivityAdd(t usermd.ActivityT, c comments.Comment) error {
+	return p.activityAdd([]string{c.UserID}, usermd.Activity{
+		Type:      t,
+		Token:     c.Token,
+		State:     uint32(convertStateFromComments(c.State)),
+		CommentID: c.CommentID,
+		Timestamp: c.Timestamp,
+	})
+}
+
+// recordActivity rebuilds the record activity of a record from the provided
+// record versions. The record versions must be sorted from oldest to newest
+// and the last version must be the most recent version of the record, which
+// contains the full status change history. The activity is returned keyed by
+// user ID and is sorted from oldest to newest.
+//
+// Once a record is made public only the vetted versions of the record can be
+// retrieved, so the edits that were made while the record was unvetted cannot
+// be rebuilt. The record new entry is rebuilt using the authors and the
+// timestamp of the oldest version that was provided.
+//
+// The record metadata timestamp of a version is the timestamp of its most
+// recent status change or metadata update. The timestamp of the first status
+// change of a version is used as the timestamp of the version when it has one.
+func recordActivity(versions []backend.Record) (map[string][]usermd.Activity, error) {
+	if len(versions) == 0 {
+		return map[string][]usermd.Activity{}, nil
+	}
+	latest := versions[len(versions)-1]
+	statusChanges, err := statusChangesDecode(latest.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		activity = make(map[string][]usermd.Activity, 8)
+		token    = latest.RecordMetadata.Token
+		state    = backend.StateUnvetted
+		authors  []string
+		scIdx    int
+	)
+	add := func(userIDs []string, a usermd.Activity) {
+		for _, userID := range userIDs {
+			activity[userID] = append(activity[userID], a)
+		}
+	}
+
+	// versionTimestamp returns the timestamp of the provided version.
+	versionTimestamp := func(r backend.Record) int64 {
+		for _, v := range statusChanges {
+			if v.Version == r.RecordMetadata.Version {
+				return v.Timestamp
+			}
+		}
+		return r.RecordMetadata.Timestamp
+	}
+
+	// addStatusChanges adds the status changes that occurred prior to
+	// the provided version. A version of 0 adds all remaining status
+	// changes.
+	addStatusChanges := func(version uint32) {
+		for ; scIdx < len(statusChanges); scIdx++ {
+			sc := statusChanges[scIdx]
+			if version != 0 && sc.Version >= version {
+				return
+			}
+			if ot := sc.OwnershipTransfer; ot != nil {
+				add([]string{ot.FromUserID, ot.ToUserID}, usermd.Activity{
+					Type:       usermd.ActivityTypeOwnershipTransfer,
+					Token:      token,
+					State:      uint32(state),
+					Version:    sc.Version,
+					FromUserID: ot.FromUserID,
+					ToUserID:   ot.ToUserID,
+					Timestamp:  sc.Timestamp,
+				})
+				authors = ownershipTransfersApply(authors, sc.Version,
+					[]usermd.StatusChangeMetadata{sc})
+				continue
+			}
+			if backend.StatusT(sc.Status) == backend.StatusPublic {
+				state = backend.StateVetted
+			}
+			add(authors, usermd.Activity{
+				Type:      usermd.ActivityTypeRecordStatusChange,
+				Token:     token,
+				State:     uint32(state),
+				Version:   sc.Version,
+				Status:    sc.Status,
+				Timestamp: sc.Timestamp,
+			})
+		}
+	}
+
+	for i, r := range versions {
+		um, err := userMetadataDecode(r.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		if um == nil {
+			return nil, fmt.Errorf("user metadata not found for version %v",
+				r.RecordMetadata.Version)
+		}
+		version := r.RecordMetadata.Version
+
+		if i == 0 {
+			// The record new entry
+			authors = recordAuthors(*um)
+			addStatusChanges(1)
+			add(authors, usermd.Activity{
+				Type:      usermd.ActivityTypeRecordNew,
+				Token:     token,
+				State:     uint32(backend.StateUnvetted),
+				Version:   1,
+				Timestamp: versionTimestamp(r),
+			})
+			if version == 1 {
+				continue
+			}
+		}
+
+		// The record edit entry. The status changes of the prior
+		// versions have already occurred.
+		addStatusChanges(version)
+		authors = recordAuthors(*um)
+		add([]string{um.UserID}, usermd.Activity{
+			Type:      usermd.ActivityTypeRecordEdit,
+			Token:     token,
+			State:     uint32(state),
+			Version:   version,
+			Timestamp: versionTimestamp(r),
+		})
+	}
+	addStatusChanges(0)
+
+	return activity, nil
+}
+
+// commentActivity rebuilds the comment activity of a record using the
+// comments plugin. The activity is returned keyed by user ID and is sorted
+// from oldest to newest. The new and edit entries of deleted comments cannot
+// be rebuilt since the comment content has been deleted.
+func (p *usermdPlugin) commentActivity(token []byte) (map[string][]usermd.Activity, error) {
+	reply, err := p.backend.PluginRead(token, comments.PluginID,
+		comments.CmdGetAll, "")
+	if errors.Is(err, backend.ErrPluginIDInvalid) {
+		// The comments plugin is not registered
+		return map[string][]usermd.Activity{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var gar comments.GetAllReply
+	err = json.Unmarshal([]byte(reply), &gar)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make(map[string][]usermd.Activity, len(gar.Comments))
+	for _, c := range gar.Comments {
+		if c.Deleted {
+			continue
+		}
+		activity[c.UserID] = append(activity[c.UserID], usermd.Activity{
+			Type:      usermd.ActivityTypeCommentNew,
+			Token:     c.Token,
+			State:     uint32(convertStateFromComments(c.State)),
+			CommentID: c.CommentID,
+			Timestamp: c.CreatedAt,
+		})
+		for version := uint32(2); version <= c.Version; version++ {
+			b, err := json.Marshal(comments.GetVersion{
+				CommentID: c.CommentID,
+				Version:   version,
+			})
+			if err != nil {
+				return nil, err
+			}
+			reply, err := p.backend.PluginRead(token, comments.PluginID,
+				comments.CmdGetVersion, string(b))
+			if err != nil {
+				return nil, err
+			}
+			var gvr comments.GetVersionReply
+			err = json.Unmarshal([]byte(reply), &gvr)
+			if err != nil {
+				return nil, err
+			}
+			e := gvr.Comment
+			activity[e.UserID] = append(activity[e.UserID], usermd.Activity{
+				Type:      usermd.ActivityTypeCommentEdit,
+				Token:     e.Token,
+				State:     uint32(convertStateFromComments(e.State)),
+				CommentID: e.CommentID,
+				Timestamp: e.Timestamp,
+			})
+		}
+	}
+
+	// The comment votes are paginated. Request the votes page by page
+	// until an empty page is returned.
+	for page := uint32(1); ; page++ {
+		b, err := json.Marshal(comments.Votes{
+			Page: page,
+		})
+		if err != nil {
+			return nil, err
+		}
+		reply, err := p.backend.PluginRead(token, comments.PluginID,
+			comments.CmdVotes, string(b))
+		if err != nil {
+			return nil, err
+		}
+		var vr comments.VotesReply
+		err = json.Unmarshal([]byte(reply), &vr)
+		if err != nil {
+			return nil, err
+		}
+		if len(vr.Votes) == 0 {
+			break
+		}
+		for _, v := range vr.Votes {
+			activity[v.UserID] = append(activity[v.UserID], usermd.Activity{
+				Type:      usermd.ActivityTypeCommentVote,
+				Token:     v.Token,
+				State:     uint32(convertStateFromComments(v.State)),
+				CommentID: v.CommentID,
+				Vote:      int32(v.Vote),
+				Timestamp: v.Timestamp,
+			})
+		}
+	}
+
+	return activity, nil
+}
+
+// userActivity rebuilds the activity of all users on the provided record. The
+// activity is returned keyed by user ID.
+func (p *usermdPlugin) userActivity(token []byte, latest *backend.Record) (map[string][]usermd.Activity, error) {
+	// Get all record versions that can be retrieved
+	versions := make([]backend.Record, 0, latest.RecordMetadata.Version)
+	for v := uint32(1); v < latest.RecordMetadata.Version; v++ {
+		r, err := p.tstore.RecordPartial(token, v, nil, true)
+		if errors.Is(err, backend.ErrRecordNotFound) {
+			// The version only exists as an unvetted version
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		versions = append(versions, *r)
+	}
+	versions = append(versions, *latest)
+
+	activity, err := recordActivity(versions)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := p.commentActivity(token)
+	if err != nil {
+		return nil, err
+	}
+	for userID, entries := range ca {
+		activity[userID] = append(activity[userID], entries...)
+	}
+
+	return activity, nil
+}
+
+// activityKey returns the key that is used to match a rebuilt activity entry
+// against the entries in the activity cache. The timestamp and the state are
+// not part of the key since they cannot always be rebuilt exactly.
+func activityKey(a usermd.Activity) usermd.Activity {
+	a.State = 0
+	a.Timestamp = 0
+	return a
+}
+
+// activityMissing returns the entries of the rebuilt activity that are not
+// found in the cached activity. An entry can occur more than once, e.g. a
+// user voting on the same comment multiple times, so the number of times that
+// each entry occurs is compared.
+func activityMissing(cached, rebuilt []usermd.Activity) []usermd.Activity {
+	counts := make(map[usermd.Activity]int, len(cached))
+	for _, v := range cached {
+		counts[activityKey(v)]++
+	}
+	missing := make([]usermd.Activity, 0, len(rebuilt))
+	for _, v := range rebuilt {
+		k := activityKey(v)
+		if counts[k] > 0 {
+			counts[k]--
+			continue
+		}
+		missing = append(missing, v)
+	}
+	return missing
+}
+
+// fsckActivityCache verifies that the activity cache of the provided user
+// contains the provided activity. Any missing entries are added to the cache
+// and the cache is re-sorted by timestamp. A corrupt activity cache is
+// replaced. The number of entries that were added is returned.
+//
+// This function must be called WITHOUT the lock held.
+func (p *usermdPlugin) fsckActivityCache(userID string, activity []usermd.Activity) (int, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	cached, err := p.activityCacheLocked(userID)
+	if errors.Is(err, errActivityCacheCorrupt) {
+		log.Errorf("Replacing corrupt activity cache of %v: %v", userID, err)
+		cached = []usermd.Activity{}
+		err = p.activityCacheSaveLocked(userID, cached)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	missing := activityMissing(cached, activity)
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	cached = append(cached, missing...)
+	sort.SliceStable(cached, func(i, j int) bool {
+		return cached[i].Timestamp < cached[j].Timestamp
+	})
+	err = p.activityCacheSaveLocked(userID, cached)
+	if err != nil {
+		return 0, err
+	}
+
+	log.Debugf("%v missing activity entries were added to %v activity "+
+		"cache", len(missing), userID)
+
+	return len(missing), nil
+}
+
+// convertStateFromComments converts a comments plugin record state to a
+// backend record state.
+func convertStateFromComments(s comments.RecordStateT) backend.StateT {
+	switch s {
+	case comments.RecordStateUnvetted:
+		return backend.StateUnvetted
+	case comments.RecordStateVetted:
+		return backend.StateVetted
+	}
+	return backend.StateInvalid
+}
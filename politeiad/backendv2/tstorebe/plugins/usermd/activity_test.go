@@ -0,0 +1,447 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package usermd
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+)
+
+func TestActivityPage(t *testing.T) {
+	// Setup activity from oldest to newest. The timestamp is used
+	// to identify the entries.
+	var (
+		unvetted = uint32(backend.StateUnvetted)
+		vetted   = uint32(backend.StateVetted)
+	)
+	activity := []usermd.Activity{
+		{State: unvetted, Timestamp: 1},
+		{State: vetted, Timestamp: 2},
+		{State: vetted, Timestamp: 3},
+		{State: unvetted, Timestamp: 4},
+		{State: vetted, Timestamp: 5},
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name     string  // Test name
+		pageSize uint32  // Page size
+		page     uint32  // Page number
+		unvetted bool    // Include unvetted activity
+		want     []int64 // Expected timestamps
+	}{
+		{
+			"all activity",
+			10,
+			1,
+			true,
+			[]int64{5, 4, 3, 2, 1},
+		},
+		{
+			"page zero returns first page",
+			2,
+			0,
+			true,
+			[]int64{5, 4},
+		},
+		{
+			"second page",
+			2,
+			2,
+			true,
+			[]int64{3, 2},
+		},
+		{
+			"unvetted filtered",
+			2,
+			1,
+			false,
+			[]int64{5, 3},
+		},
+		{
+			"unvetted filtered second page",
+			2,
+			2,
+			false,
+			[]int64{2},
+		},
+		{
+			"page out of range",
+			2,
+			4,
+			true,
+			[]int64{},
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			page := activityPage(activity, v.pageSize, v.page, v.unvetted)
+			if len(page) != len(v.want) {
+				t.Fatalf("got %v entries, want %v", len(page), len(v.want))
+			}
+			for i, a := range page {
+				if a.Timestamp != v.want[i] {
+					t.Errorf("entry %v: got timestamp %v, want %v",
+						i, a.Timestamp, v.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestActivityAdd(t *testing.T) {
+	dir, err := ioutil.TempDir("", "usermd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &usermdPlugin{
+		dataDir: dir,
+	}
+
+	// Add activity to multiple users
+	a := usermd.Activity{
+		Type:      usermd.ActivityTypeRecordNew,
+		Token:     "45154fb45664714b",
+		State:     uint32(backend.StateUnvetted),
+		Timestamp: 1,
+	}
+	err = p.activityAdd([]string{"user1", "user2"}, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Type = usermd.ActivityTypeRecordEdit
+	a.Timestamp = 2
+	err = p.activityAdd([]string{"user1"}, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the activity caches
+	ac, err := p.activityCache("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ac) != 2 {
+		t.Fatalf("user1: got %v entries, want 2", len(ac))
+	}
+	if ac[1].Type != usermd.ActivityTypeRecordEdit {
+		t.Errorf("user1: got activity %v, want %v",
+			usermd.Activities[ac[1].Type],
+			usermd.Activities[usermd.ActivityTypeRecordEdit])
+	}
+	ac, err = p.activityCache("user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ac) != 1 {
+		t.Fatalf("user2: got %v entries, want 1", len(ac))
+	}
+	ac, err = p.activityCache("user3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ac) != 0 {
+		t.Fatalf("user3: got %v entries, want 0", len(ac))
+	}
+}
+
+func TestRecordActivity(t *testing.T) {
+	var (
+		token = "45154fb45664714b"
+		b     = usermd.CoAuthor{UserID: "b"}
+	)
+
+	// newVersion returns a record version that was submitted by the
+	// provided user and has the provided status change history.
+	newVersion := func(version uint32, userID string, timestamp int64, statusChanges []usermd.StatusChangeMetadata) backend.Record {
+		um, err := json.Marshal(usermd.UserMetadata{
+			UserID:    userID,
+			CoAuthors: []usermd.CoAuthor{b},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := newStatusChangesStream(t, statusChanges)
+		md = append(md, backend.MetadataStream{
+			PluginID: usermd.PluginID,
+			StreamID: usermd.StreamIDUserMetadata,
+			Payload:  string(um),
+		})
+		return backend.Record{
+			RecordMetadata: backend.RecordMetadata{
+				Token:     token,
+				Version:   version,
+				Timestamp: timestamp,
+			},
+			Metadata: md,
+		}
+	}
+
+	// Record history:
+	// 1. Version 1 is submitted by a with co-author b
+	// 2. Version 1 is made public
+	// 3. The ownership is transferred from a to c
+	// 4. Version 2 is submitted by c
+	// 5. Version 2 is archived
+	statusChanges := []usermd.StatusChangeMetadata{
+		{
+			Token:     token,
+			Version:   1,
+			Status:    uint32(backend.StatusPublic),
+			Timestamp: 20,
+		},
+		{
+			Token:     token,
+			Version:   1,
+			Status:    uint32(backend.StatusPublic),
+			Timestamp: 30,
+			OwnershipTransfer: &usermd.OwnershipTransfer{
+				FromUserID: "a",
+				ToUserID:   "c",
+			},
+		},
+		{
+			Token:     token,
+			Version:   2,
+			Status:    uint32(backend.StatusArchived),
+			Timestamp: 50,
+		},
+	}
+	versions := []backend.Record{
+		newVersion(1, "a", 30, statusChanges[:2]),
+		newVersion(2, "c", 50, statusChanges),
+	}
+
+	var (
+		vetted   = uint32(backend.StateVetted)
+		unvetted = uint32(backend.StateUnvetted)
+
+		recordNew = usermd.Activity{
+			Type:      usermd.ActivityTypeRecordNew,
+			Token:     token,
+			State:     unvetted,
+			Version:   1,
+			Timestamp: 20,
+		}
+		public = usermd.Activity{
+			Type:      usermd.ActivityTypeRecordStatusChange,
+			Token:     token,
+			State:     vetted,
+			Version:   1,
+			Status:    uint32(backend.StatusPublic),
+			Timestamp: 20,
+		}
+		transfer = usermd.Activity{
+			Type:       usermd.ActivityTypeOwnershipTransfer,
+			Token:      token,
+			State:      vetted,
+			Version:    1,
+			FromUserID: "a",
+			ToUserID:   "c",
+			Timestamp:  30,
+		}
+		edit = usermd.Activity{
+			Type:      usermd.ActivityTypeRecordEdit,
+			Token:     token,
+			State:     vetted,
+			Version:   2,
+			Timestamp: 50,
+		}
+		archived = usermd.Activity{
+			Type:      usermd.ActivityTypeRecordStatusChange,
+			Token:     token,
+			State:     vetted,
+			Version:   2,
+			Status:    uint32(backend.StatusArchived),
+			Timestamp: 50,
+		}
+	)
+	// When only the latest version can be retrieved, the record new
+	// entry uses the authors and the timestamp of the latest version.
+	latestNew := recordNew
+	latestNew.Timestamp = 50
+
+	var tests = []struct {
+		name     string
+		versions []backend.Record
+		want     map[string][]usermd.Activity
+	}{
+		{
+			"all versions",
+			versions,
+			map[string][]usermd.Activity{
+				"a": {recordNew, public, transfer},
+				"b": {recordNew, public, archived},
+				"c": {transfer, edit, archived},
+			},
+		},
+		{
+			"latest version only",
+			versions[1:],
+			map[string][]usermd.Activity{
+				"a": {transfer},
+				"b": {latestNew, public, archived},
+				"c": {latestNew, public, transfer, edit, archived},
+			},
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			activity, err := recordActivity(v.versions)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(activity) != len(v.want) {
+				t.Fatalf("got activity for %v users, want %v",
+					len(activity), len(v.want))
+			}
+			for userID, want := range v.want {
+				got := activity[userID]
+				if len(got) != len(want) {
+					t.Fatalf("%v: got %v entries, want %v",
+						userID, len(got), len(want))
+				}
+				for i := range want {
+					if got[i] != want[i] {
+						t.Errorf("%v entry %v: got %+v, want %+v",
+							userID, i, got[i], want[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestActivityMissing(t *testing.T) {
+	var (
+		recordNew = usermd.Activity{
+			Type:      usermd.ActivityTypeRecordNew,
+			Token:     "45154fb45664714b",
+			Version:   1,
+			Timestamp: 1,
+		}
+		vote = usermd.Activity{
+			Type:      usermd.ActivityTypeCommentVote,
+			Token:     "45154fb45664714b",
+			CommentID: 1,
+			Vote:      1,
+			Timestamp: 2,
+		}
+	)
+
+	// The rebuilt record new entry has a different timestamp and the
+	// user voted on the comment twice.
+	rebuiltNew := recordNew
+	rebuiltNew.Timestamp = 3
+	cached := []usermd.Activity{recordNew, vote}
+	rebuilt := []usermd.Activity{rebuiltNew, vote, vote}
+
+	missing := activityMissing(cached, rebuilt)
+	if len(missing) != 1 || missing[0] != vote {
+		t.Fatalf("got missing %+v, want %+v", missing, []usermd.Activity{vote})
+	}
+	missing = activityMissing(rebuilt, cached)
+	if len(missing) != 0 {
+		t.Fatalf("got missing %+v, want none", missing)
+	}
+}
+
+func TestFsckActivityCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "usermd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &usermdPlugin{
+		dataDir: dir,
+	}
+
+	// Setup an activity cache with a single entry
+	newActivity := func(timestamp int64) usermd.Activity {
+		return usermd.Activity{
+			Type:      usermd.ActivityTypeCommentNew,
+			Token:     "45154fb45664714b",
+			CommentID: uint32(timestamp),
+			Timestamp: timestamp,
+		}
+	}
+	err = p.activityAdd([]string{"user1"}, newActivity(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify that the missing entries are added in timestamp order
+	rebuilt := []usermd.Activity{newActivity(1), newActivity(2),
+		newActivity(3)}
+	added, err := p.fsckActivityCache("user1", rebuilt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 2 {
+		t.Fatalf("got %v added entries, want 2", added)
+	}
+	ac, err := p.activityCache("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ac) != len(rebuilt) {
+		t.Fatalf("got %v entries, want %v", len(ac), len(rebuilt))
+	}
+	for i := range rebuilt {
+		if ac[i] != rebuilt[i] {
+			t.Errorf("entry %v: got %+v, want %+v", i, ac[i], rebuilt[i])
+		}
+	}
+
+	// Verify that new activity is appended to the rewritten cache
+	err = p.activityAdd([]string{"user1"}, newActivity(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ac, err = p.activityCache("user1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ac) != 4 || ac[3] != newActivity(4) {
+		t.Fatalf("appended entry not found: %+v", ac)
+	}
+
+	// Verify that a corrupt activity cache is replaced
+	fp := filepath.Join(dir, "user2-activity.json")
+	err = ioutil.WriteFile(fp, []byte(`{"type":5,"tok`), 0664)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.activityCache("user2")
+	if !errors.Is(err, errActivityCacheCorrupt) {
+		t.Fatalf("got error %v, want %v", err, errActivityCacheCorrupt)
+	}
+	added, err = p.fsckActivityCache("user2", rebuilt[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 1 {
+		t.Fatalf("got %v added entries, want 1", added)
+	}
+	ac, err = p.activityCache("user2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ac) != 1 || ac[0] != rebuilt[0] {
+		t.Fatalf("got %+v, want %+v", ac, rebuilt[:1])
+	}
+}
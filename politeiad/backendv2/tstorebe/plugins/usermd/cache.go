@@ -147,7 +147,7 @@ func (p *usermdPlugin) userCacheDelToken(userID string, state backend.StateT, to
 
 	switch state {
 	case backend.StateUnvetted:
-		tokens, err := delToken(uc.Vetted, token)
+		tokens, err := delToken(uc.Unvetted, token)
 		if err != nil {
 			return fmt.Errorf("delToken %v %v: %v",
 				userID, state, err)
@@ -207,6 +207,57 @@ func (p *usermdPlugin) userCacheMoveTokenToVetted(userID string, token string) e
 	return nil
 }
 
+// userCacheTransferToken moves a record token from the user cache of the
+// provided from user to the user cache of the provided to user. This is done
+// when the ownership of a record is transferred.
+func (p *usermdPlugin) userCacheTransferToken(fromUserID, toUserID string, state backend.StateT, token string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	// Get current user data
+	from, err := p.userCacheLocked(fromUserID)
+	if err != nil {
+		return err
+	}
+	to, err := p.userCacheLocked(toUserID)
+	if err != nil {
+		return err
+	}
+
+	// Move token
+	switch state {
+	case backend.StateUnvetted:
+		from.Unvetted, err = delToken(from.Unvetted, token)
+		if err != nil {
+			return fmt.Errorf("delToken %v: %v", fromUserID, err)
+		}
+		to.Unvetted = append(to.Unvetted, token)
+	case backend.StateVetted:
+		from.Vetted, err = delToken(from.Vetted, token)
+		if err != nil {
+			return fmt.Errorf("delToken %v: %v", fromUserID, err)
+		}
+		to.Vetted = append(to.Vetted, token)
+	default:
+		return fmt.Errorf("invalid state %v", state)
+	}
+
+	// Save changes
+	err = p.userCacheSaveLocked(fromUserID, *from)
+	if err != nil {
+		return err
+	}
+	err = p.userCacheSaveLocked(toUserID, *to)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("User cache transfer %v %v from %v to %v",
+		backend.States[state], token, fromUserID, toUserID)
+
+	return nil
+}
+
 // delToken deletes the tokenToDel from the tokens list. An error is returned
 // if the token is not found.
 func delToken(tokens []string, tokenToDel string) ([]string, error) {
@@ -0,0 +1,99 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package usermd
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+)
+
+func TestUserCacheDelToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "usermd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &usermdPlugin{
+		dataDir: dir,
+	}
+
+	// Setup a user cache that contains both unvetted and vetted tokens
+	var (
+		userID    = "user1"
+		unvetted1 = "45154fb45664714a"
+		unvetted2 = "45154fb45664714b"
+		vetted    = "45154fb45664714c"
+	)
+	err = p.userCacheSave(userID, userCache{
+		Unvetted: []string{unvetted1, unvetted2},
+		Vetted:   []string{vetted},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name         string
+		state        backend.StateT
+		token        string
+		wantErr      bool
+		wantUnvetted []string
+		wantVetted   []string
+	}{
+		{
+			"token not in state",
+			backend.StateVetted,
+			unvetted1,
+			true,
+			[]string{unvetted1, unvetted2},
+			[]string{vetted},
+		},
+		{
+			"unvetted token",
+			backend.StateUnvetted,
+			unvetted1,
+			false,
+			[]string{unvetted2},
+			[]string{vetted},
+		},
+		{
+			"vetted token",
+			backend.StateVetted,
+			vetted,
+			false,
+			[]string{unvetted2},
+			[]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := p.userCacheDelToken(userID, test.state, test.token)
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case !test.wantErr && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			}
+
+			uc, err := p.userCache(userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(uc.Unvetted, test.wantUnvetted) {
+				t.Errorf("got unvetted %v, want %v",
+					uc.Unvetted, test.wantUnvetted)
+			}
+			if !reflect.DeepEqual(uc.Vetted, test.wantVetted) {
+				t.Errorf("got vetted %v, want %v",
+					uc.Vetted, test.wantVetted)
+			}
+		})
+	}
+}
@@ -5,26 +5,35 @@
 package usermd
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"time"
 
+	backend "github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/politeiad/plugins/usermd"
+	"github.com/decred/politeia/util"
 )
 
-// cmdAuthor returns the user ID of a record's author.
+// cmdAuthor returns the user ID of a record's author. The returned authors
+// include any ownership transfers that have occurred.
 func (p *usermdPlugin) cmdAuthor(token []byte) (string, error) {
-	// Get user metadata
+	// Get the record metadata
 	r, err := p.tstore.RecordPartial(token, 0, nil, true)
 	if err != nil {
 		return "", err
 	}
-	um, err := userMetadataDecode(r.Metadata)
+
+	// Get the current record authors
+	authors, err := currentAuthors(r.RecordMetadata.Version, r.Metadata)
 	if err != nil {
 		return "", err
 	}
 
 	// Prepare reply
 	ar := usermd.AuthorReply{
-		UserID: um.UserID,
+		UserID:    authors[0],
+		CoAuthors: authors[1:],
 	}
 	reply, err := json.Marshal(ar)
 	if err != nil {
@@ -35,7 +44,8 @@ func (p *usermdPlugin) cmdAuthor(token []byte) (string, error) {
 }
 
 // cmdUserRecords retrieves the tokens of all records that were submitted by
-// the provided user ID. The returned tokens are sorted from newest to oldest.
+// the provided user ID, including the records that the user is a co-author
+// of. The returned tokens are sorted from newest to oldest.
 func (p *usermdPlugin) cmdUserRecords(payload string) (string, error) {
 	// Decode payload
 	var ur usermd.UserRecords
@@ -75,3 +85,115 @@ func (p *usermdPlugin) cmdUserRecords(payload string) (string, error) {
 
 	return string(reply), nil
 }
+
+// cmdTransferOwnership transfers the ownership of a record from one of the
+// record authors to a different user. The transfer is appended to the status
+// change history of the record.
+func (p *usermdPlugin) cmdTransferOwnership(token []byte, payload string) (string, error) {
+	// Decode payload
+	var to usermd.TransferOwnership
+	err := json.Unmarshal([]byte(payload), &to)
+	if err != nil {
+		return "", err
+	}
+
+	// Verify token
+	err = tokenMatches(token, to.Token)
+	if err != nil {
+		return "", err
+	}
+
+	// Get the record metadata
+	r, err := p.tstore.RecordPartial(token, 0, nil, true)
+	if err != nil {
+		return "", err
+	}
+	rm := r.RecordMetadata
+
+	// Setup the status change history entry. An ownership transfer
+	// does not change the record status.
+	sc := usermd.StatusChangeMetadata{
+		Token:     to.Token,
+		Version:   to.Version,
+		Status:    uint32(rm.Status),
+		Reason:    to.Reason,
+		PublicKey: to.PublicKey,
+		Signature: to.Signature,
+		Timestamp: time.Now().Unix(),
+		OwnershipTransfer: &usermd.OwnershipTransfer{
+			FromUserID:      to.FromUserID,
+			ToUserID:        to.ToUserID,
+			AuthorPublicKey: to.AuthorPublicKey,
+			AuthorSignature: to.AuthorSignature,
+		},
+	}
+
+	// Verify the ownership transfer against the current record
+	// authors.
+	authors, err := currentAuthors(rm.Version, r.Metadata)
+	if err != nil {
+		return "", err
+	}
+	err = ownershipTransferVerify(rm, authors, sc)
+	if err != nil {
+		return "", err
+	}
+
+	// Append the ownership transfer to the status change history
+	b, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+	err = p.tstore.RecordMetadataAppend(token, []backend.MetadataStream{
+		{
+			PluginID: usermd.PluginID,
+			StreamID: usermd.StreamIDStatusChanges,
+			Payload:  string(b),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Update the user caches and the activity timelines
+	err = p.ownershipTransferCache(rm, sc)
+	if err != nil {
+		return "", err
+	}
+
+	// Prepare reply
+	tor := usermd.TransferOwnershipReply{
+		StatusChange: sc,
+	}
+	reply, err := json.Marshal(tor)
+	if err != nil {
+		return "", err
+	}
+
+	return string(reply), nil
+}
+
+// tokenMatches verifies that the command token (the token for the record that
+// this plugin command is being executed on) matches the payload token (the
+// token that the plugin command payload contains that is typically used in the
+// payload signature). The payload token must be the full length token.
+func tokenMatches(cmdToken []byte, payloadToken string) error {
+	pt, err := util.TokenDecode(util.TokenTypeTstore, payloadToken)
+	if err != nil {
+		return backend.PluginError{
+			PluginID:     usermd.PluginID,
+			ErrorCode:    uint32(usermd.ErrorCodeTokenInvalid),
+			ErrorContext: util.TokenRegexp(),
+		}
+	}
+	if !bytes.Equal(cmdToken, pt) {
+		return backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeTokenInvalid),
+			ErrorContext: fmt.Sprintf("payload token does not "+
+				"match command token: got %x, want %x",
+				pt, cmdToken),
+		}
+	}
+	return nil
+}
@@ -29,7 +29,7 @@ func (p *usermdPlugin) hookNewRecordPre(payload string) error {
 		return err
 	}
 
-	return userMetadataVerify(nr.Metadata, nr.Files)
+	return p.userMetadataVerify(nr.Metadata, nr.Files)
 }
 
 // hookNewRecordPre caches plugin data from the tstore backend RecordNew
@@ -47,14 +47,25 @@ func (p *usermdPlugin) hookNewRecordPost(payload string) error {
 		return err
 	}
 
-	// Add token to the user cache
-	err = p.userCacheAddToken(um.UserID, nr.RecordMetadata.State,
-		nr.RecordMetadata.Token)
-	if err != nil {
-		return err
+	// Add token to the user cache of every record author
+	rm := nr.RecordMetadata
+	authors := recordAuthors(*um)
+	for _, userID := range authors {
+		err = p.userCacheAddToken(userID, rm.State, rm.Token)
+		if err != nil {
+			return err
+		}
 	}
 
-	return nil
+	// Add the new record to the activity timeline of every record
+	// author.
+	return p.activityAdd(authors, usermd.Activity{
+		Type:      usermd.ActivityTypeRecordNew,
+		Token:     rm.Token,
+		State:     uint32(rm.State),
+		Version:   rm.Version,
+		Timestamp: rm.Timestamp,
+	})
 }
 
 // hookEditRecordPre adds plugin specific validation onto the tstore backend
@@ -67,32 +78,70 @@ func (p *usermdPlugin) hookEditRecordPre(payload string) error {
 	}
 
 	// Verify user metadata
-	err = userMetadataVerify(er.Metadata, er.Files)
+	err = p.userMetadataVerify(er.Metadata, er.Files)
 	if err != nil {
 		return err
 	}
 
-	// Verify user ID has not changed
+	// Verify that the user submitting the edit is one of the current
+	// record authors and that the set of record authors has not
+	// changed. Any of the record authors are allowed to submit an
+	// edit. The current record authors include any ownership
+	// transfers that have occurred.
 	um, err := userMetadataDecode(er.Metadata)
 	if err != nil {
 		return err
 	}
-	umCurr, err := userMetadataDecode(er.Record.Metadata)
+	authors, err := currentAuthors(er.Record.RecordMetadata.Version,
+		er.Record.Metadata)
 	if err != nil {
 		return err
 	}
-	if um.UserID != umCurr.UserID {
+	if !isAuthor(authors, um.UserID) {
 		return backend.PluginError{
 			PluginID:  usermd.PluginID,
 			ErrorCode: uint32(usermd.ErrorCodeUserIDInvalid),
-			ErrorContext: fmt.Sprintf("user id cannot change: got %v, want %v",
-				um.UserID, umCurr.UserID),
+			ErrorContext: fmt.Sprintf("user %v is not a record author",
+				um.UserID),
+		}
+	}
+	if !authorSetsAreEqual(recordAuthors(*um), authors) {
+		return backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeCoAuthorsInvalid),
+			ErrorContext: fmt.Sprintf("record authors cannot change: "+
+				"got %v, want %v", recordAuthors(*um), authors),
 		}
 	}
 
 	return nil
 }
 
+// hookEditRecordPost caches plugin data from the tstore backend RecordEdit
+// method.
+func (p *usermdPlugin) hookEditRecordPost(payload string) error {
+	var er plugins.HookEditRecord
+	err := json.Unmarshal([]byte(payload), &er)
+	if err != nil {
+		return err
+	}
+
+	// Add the edit to the activity timeline of the author that
+	// submitted the edit.
+	um, err := userMetadataDecode(er.Metadata)
+	if err != nil {
+		return err
+	}
+	rm := er.RecordMetadata
+	return p.activityAdd([]string{um.UserID}, usermd.Activity{
+		Type:      usermd.ActivityTypeRecordEdit,
+		Token:     rm.Token,
+		State:     uint32(rm.State),
+		Version:   rm.Version,
+		Timestamp: rm.Timestamp,
+	})
+}
+
 // hookEditRecordPre adds plugin specific validation onto the tstore backend
 // RecordEdit method.
 func (p *usermdPlugin) hookEditMetadataPre(payload string) error {
@@ -103,7 +152,64 @@ func (p *usermdPlugin) hookEditMetadataPre(payload string) error {
 	}
 
 	// User metadata should not change on metadata updates
-	return userMetadataPreventUpdates(em.Record.Metadata, em.Metadata)
+	err = userMetadataPreventUpdates(em.Record.Metadata, em.Metadata)
+	if err != nil {
+		return err
+	}
+
+	// Verify any ownership transfers that are being appended to the
+	// status change history. Ownership transfers are added using the
+	// TransferOwnership command. This check prevents a metadata update
+	// from being used to bypass the verification that the command
+	// performs. The transfers are applied in order, so each transfer
+	// is verified against the authors that result from the transfers
+	// that precede it.
+	transfers, err := ownershipTransfersNew(em.Record.Metadata, em.Metadata)
+	if err != nil {
+		return err
+	}
+	rm := em.Record.RecordMetadata
+	authors, err := currentAuthors(rm.Version, em.Record.Metadata)
+	if err != nil {
+		return err
+	}
+	for _, v := range transfers {
+		err = ownershipTransferVerify(rm, authors, v)
+		if err != nil {
+			return err
+		}
+		authors = ownershipTransfersApply(authors, rm.Version,
+			[]usermd.StatusChangeMetadata{v})
+	}
+
+	return nil
+}
+
+// hookEditMetadataPost caches plugin data from the tstore backend
+// RecordEditMetadata method.
+func (p *usermdPlugin) hookEditMetadataPost(payload string) error {
+	var em plugins.HookEditMetadata
+	err := json.Unmarshal([]byte(payload), &em)
+	if err != nil {
+		return err
+	}
+
+	// Move the token of the record from the user cache of the previous
+	// owner to the user cache of the new owner for every ownership
+	// transfer, and add the transfer to the activity timeline of both
+	// users.
+	transfers, err := ownershipTransfersNew(em.Record.Metadata, em.Metadata)
+	if err != nil {
+		return err
+	}
+	for _, v := range transfers {
+		err = p.ownershipTransferCache(em.Record.RecordMetadata, v)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // hookSetStatusRecordPre adds plugin specific validation onto the tstore
@@ -140,20 +246,33 @@ func (p *usermdPlugin) hookSetRecordStatusPost(payload string) error {
 	}
 	rm := srs.RecordMetadata
 
+	// Get the current record authors
+	authors, err := currentAuthors(rm.Version, srs.Metadata)
+	if err != nil {
+		return err
+	}
+
 	// When a record is made public the token must be moved from the
 	// unvetted list to the vetted list in the user cache.
 	if rm.Status == backend.StatusPublic {
-		um, err := userMetadataDecode(srs.Metadata)
-		if err != nil {
-			return err
-		}
-		err = p.userCacheMoveTokenToVetted(um.UserID, rm.Token)
-		if err != nil {
-			return err
+		for _, userID := range authors {
+			err = p.userCacheMoveTokenToVetted(userID, rm.Token)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
-	return nil
+	// Add the status change to the activity timeline of every record
+	// author.
+	return p.activityAdd(authors, usermd.Activity{
+		Type:      usermd.ActivityTypeRecordStatusChange,
+		Token:     rm.Token,
+		State:     uint32(rm.State),
+		Version:   rm.Version,
+		Status:    uint32(rm.Status),
+		Timestamp: rm.Timestamp,
+	})
 }
 
 // userMetadataDecode decodes and returns the UserMetadata from the provided
@@ -177,9 +296,55 @@ func userMetadataDecode(metadata []backend.MetadataStream) (*usermd.UserMetadata
 	return userMD, nil
 }
 
+// recordAuthors returns the user IDs of all of the record authors. The user
+// that submitted the record version is always the first entry.
+func recordAuthors(um usermd.UserMetadata) []string {
+	authors := make([]string, 0, len(um.CoAuthors)+1)
+	authors = append(authors, um.UserID)
+	for _, v := range um.CoAuthors {
+		authors = append(authors, v.UserID)
+	}
+	return authors
+}
+
+// isAuthor returns whether the provided user ID is one of the provided record
+// authors.
+func isAuthor(authors []string, userID string) bool {
+	for _, v := range authors {
+		if v == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// authorsAreEqual returns whether the provided user metadata contain the same
+// set of record authors. The order of the authors does not matter.
+func authorsAreEqual(a, b usermd.UserMetadata) bool {
+	return authorSetsAreEqual(recordAuthors(a), recordAuthors(b))
+}
+
+// authorSetsAreEqual returns whether the provided lists of record authors
+// contain the same set of authors. The order of the authors does not matter.
+func authorSetsAreEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	m := make(map[string]struct{}, len(a))
+	for _, v := range a {
+		m[v] = struct{}{}
+	}
+	for _, v := range b {
+		if _, ok := m[v]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // userMetadataVerify parses a UserMetadata from the metadata streams and
 // verifies its contents are valid.
-func userMetadataVerify(metadata []backend.MetadataStream, files []backend.File) error {
+func (p *usermdPlugin) userMetadataVerify(metadata []backend.MetadataStream, files []backend.File) error {
 	// Decode user metadata
 	um, err := userMetadataDecode(metadata)
 	if err != nil {
@@ -216,6 +381,48 @@ func userMetadataVerify(metadata []backend.MetadataStream, files []backend.File)
 		return convertSignatureError(err)
 	}
 
+	// Verify co-authors
+	if uint32(len(um.CoAuthors)) > p.coAuthorsMax {
+		return backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeCoAuthorsInvalid),
+			ErrorContext: fmt.Sprintf("got %v co-authors, max is %v",
+				len(um.CoAuthors), p.coAuthorsMax),
+		}
+	}
+	authors := map[string]struct{}{
+		um.UserID: {},
+	}
+	for _, v := range um.CoAuthors {
+		_, err = uuid.Parse(v.UserID)
+		if err != nil {
+			return backend.PluginError{
+				PluginID:     usermd.PluginID,
+				ErrorCode:    uint32(usermd.ErrorCodeUserIDInvalid),
+				ErrorContext: fmt.Sprintf("co-author %v", v.UserID),
+			}
+		}
+		if _, ok := authors[v.UserID]; ok {
+			return backend.PluginError{
+				PluginID:  usermd.PluginID,
+				ErrorCode: uint32(usermd.ErrorCodeCoAuthorsInvalid),
+				ErrorContext: fmt.Sprintf("duplicate author %v",
+					v.UserID),
+			}
+		}
+		authors[v.UserID] = struct{}{}
+
+		// Each co-author must sign the merkle root to signify
+		// their acceptance of the record content.
+		err = util.VerifySignature(v.Signature, v.PublicKey, mr)
+		if err != nil {
+			pe := convertSignatureError(err)
+			pe.ErrorContext = fmt.Sprintf("co-author %v: %v",
+				v.UserID, pe.ErrorContext)
+			return pe
+		}
+	}
+
 	return nil
 }
 
@@ -256,6 +463,14 @@ func userMetadataPreventUpdates(current, update []backend.MetadataStream) error
 			ErrorContext: fmt.Sprintf("signature cannot change: got %v, want %v",
 				u.Signature, c.Signature),
 		}
+
+	case !authorsAreEqual(*u, *c):
+		return backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeCoAuthorsInvalid),
+			ErrorContext: fmt.Sprintf("record authors cannot change: "+
+				"got %v, want %v", recordAuthors(*u), recordAuthors(*c)),
+		}
 	}
 
 	return nil
@@ -314,6 +529,16 @@ func statusChangeMetadataVerify(rm backend.RecordMetadata, metadata []backend.Me
 	}
 	scm := statusChanges[len(statusChanges)-1]
 
+	// Verify that the status change is not an ownership transfer.
+	// Ownership transfers are added using the TransferOwnership command.
+	if scm.OwnershipTransfer != nil {
+		return backend.PluginError{
+			PluginID:     usermd.PluginID,
+			ErrorCode:    uint32(usermd.ErrorCodeStatusInvalid),
+			ErrorContext: "ownership transfers cannot change the status",
+		}
+	}
+
 	// Verify token matches
 	if scm.Token != rm.Token {
 		return backend.PluginError{
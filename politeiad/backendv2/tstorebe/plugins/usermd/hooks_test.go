@@ -0,0 +1,187 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package usermd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+func TestUserMetadataVerifyCoAuthors(t *testing.T) {
+	p := &usermdPlugin{
+		coAuthorsMax: 2,
+	}
+
+	// Setup record files and the merkle root that must be signed
+	payload := []byte("Hello, world.")
+	files := []backend.File{
+		{
+			Name:    "index.md",
+			MIME:    "text/plain; charset=utf-8",
+			Digest:  hex.EncodeToString(util.Digest(payload)),
+			Payload: base64.StdEncoding.EncodeToString(payload),
+		},
+	}
+	m, err := util.MerkleRoot([]string{files[0].Digest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr := hex.EncodeToString(m[:])
+
+	// Setup authors
+	var (
+		author    = newTestCoAuthor(t, mr)
+		coAuthor1 = newTestCoAuthor(t, mr)
+		coAuthor2 = newTestCoAuthor(t, mr)
+		coAuthor3 = newTestCoAuthor(t, mr)
+
+		coAuthorBadSig = coAuthor1
+	)
+	coAuthorBadSig.Signature = coAuthor2.Signature
+
+	// Setup tests
+	var tests = []struct {
+		name      string            // Test name
+		coAuthors []usermd.CoAuthor // Co-authors
+		errCode   usermd.ErrorCodeT // Expected error code
+	}{
+		{
+			"no co-authors",
+			nil,
+			usermd.ErrorCodeInvalid,
+		},
+		{
+			"too many co-authors",
+			[]usermd.CoAuthor{coAuthor1, coAuthor2, coAuthor3},
+			usermd.ErrorCodeCoAuthorsInvalid,
+		},
+		{
+			"duplicate co-author",
+			[]usermd.CoAuthor{coAuthor1, coAuthor1},
+			usermd.ErrorCodeCoAuthorsInvalid,
+		},
+		{
+			"author is co-author",
+			[]usermd.CoAuthor{author},
+			usermd.ErrorCodeCoAuthorsInvalid,
+		},
+		{
+			"invalid co-author signature",
+			[]usermd.CoAuthor{coAuthorBadSig},
+			usermd.ErrorCodeSignatureInvalid,
+		},
+		{
+			"success",
+			[]usermd.CoAuthor{coAuthor1, coAuthor2},
+			usermd.ErrorCodeInvalid,
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			um := usermd.UserMetadata{
+				UserID:    author.UserID,
+				PublicKey: author.PublicKey,
+				Signature: author.Signature,
+				CoAuthors: v.coAuthors,
+			}
+			b, err := json.Marshal(um)
+			if err != nil {
+				t.Fatal(err)
+			}
+			metadata := []backend.MetadataStream{
+				{
+					PluginID: usermd.PluginID,
+					StreamID: usermd.StreamIDUserMetadata,
+					Payload:  string(b),
+				},
+			}
+
+			err = p.userMetadataVerify(metadata, files)
+			switch {
+			case v.errCode == usermd.ErrorCodeInvalid && err != nil:
+				t.Errorf("want error nil, got '%v'", err)
+			case v.errCode != usermd.ErrorCodeInvalid:
+				var pe backend.PluginError
+				if !errors.As(err, &pe) {
+					t.Errorf("want plugin error, got '%v'", err)
+					return
+				}
+				if usermd.ErrorCodeT(pe.ErrorCode) != v.errCode {
+					t.Errorf("want error '%v', got '%v'",
+						usermd.ErrorCodes[v.errCode],
+						usermd.ErrorCodes[usermd.ErrorCodeT(pe.ErrorCode)])
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorsAreEqual(t *testing.T) {
+	a := usermd.UserMetadata{
+		UserID: "a",
+		CoAuthors: []usermd.CoAuthor{
+			{UserID: "b"},
+			{UserID: "c"},
+		},
+	}
+
+	// A different submitting author with the same set of authors
+	b := usermd.UserMetadata{
+		UserID: "c",
+		CoAuthors: []usermd.CoAuthor{
+			{UserID: "a"},
+			{UserID: "b"},
+		},
+	}
+	if !authorsAreEqual(a, b) {
+		t.Errorf("want authors equal, got not equal")
+	}
+
+	// A co-author has been removed
+	c := usermd.UserMetadata{
+		UserID: "a",
+		CoAuthors: []usermd.CoAuthor{
+			{UserID: "b"},
+		},
+	}
+	if authorsAreEqual(a, c) {
+		t.Errorf("want authors not equal, got equal")
+	}
+
+	// A co-author has been replaced
+	c.CoAuthors = append(c.CoAuthors, usermd.CoAuthor{UserID: "d"})
+	if authorsAreEqual(a, c) {
+		t.Errorf("want authors not equal, got equal")
+	}
+}
+
+// newTestCoAuthor returns a co-author with a new identity that has signed the
+// provided merkle root.
+func newTestCoAuthor(t *testing.T, merkleRoot string) usermd.CoAuthor {
+	t.Helper()
+
+	id, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := id.SignMessage([]byte(merkleRoot))
+
+	return usermd.CoAuthor{
+		UserID:    uuid.New().String(),
+		PublicKey: id.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	}
+}
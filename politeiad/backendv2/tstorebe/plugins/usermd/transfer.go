@@ -0,0 +1,257 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package usermd
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+	"github.com/decred/politeia/util"
+	"github.com/google/uuid"
+)
+
+var (
+	// transferStatuses contains the record statuses that allow for the
+	// ownership of the record to be transferred.
+	transferStatuses = map[backend.StatusT]struct{}{
+		backend.StatusUnreviewed: {},
+		backend.StatusPublic:     {},
+	}
+)
+
+// currentAuthors returns the user IDs of the current record authors. This is
+// the record authors from the user metadata with the ownership transfers of
+// the provided record version applied. The ownership transfers are parsed from
+// the status change history. The user that submitted the record version, or
+// the user that it was transferred to, is always the first entry.
+func currentAuthors(version uint32, metadata []backend.MetadataStream) ([]string, error) {
+	um, err := userMetadataDecode(metadata)
+	if err != nil {
+		return nil, err
+	}
+	if um == nil {
+		return nil, backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeUserMetadataNotFound),
+		}
+	}
+	statusChanges, err := statusChangesDecode(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return ownershipTransfersApply(recordAuthors(*um), version,
+		statusChanges), nil
+}
+
+// ownershipTransfersApply applies the ownership transfers of the provided
+// record version to the provided record authors. Status changes that are not
+// ownership transfers are ignored. A transferred author is replaced by the new
+// owner in place so that the order of the authors is preserved.
+func ownershipTransfersApply(authors []string, version uint32, statusChanges []usermd.StatusChangeMetadata) []string {
+	a := make([]string, len(authors))
+	copy(a, authors)
+	for _, v := range statusChanges {
+		ot := v.OwnershipTransfer
+		if ot == nil {
+			// Not an ownership transfer
+			continue
+		}
+		if v.Version != version {
+			// The transfers of prior versions are already reflected
+			// in the user metadata of this version.
+			continue
+		}
+		for i, userID := range a {
+			if userID == ot.FromUserID {
+				a[i] = ot.ToUserID
+				break
+			}
+		}
+	}
+	return a
+}
+
+// ownershipTransfersNew returns the ownership transfers that are being
+// appended to the status change history of a record by a metadata update. An
+// error is returned if the existing status change history is being modified
+// or if any of the appended entries are not ownership transfers. Status
+// changes can only be added using a record status change.
+func ownershipTransfersNew(current, update []backend.MetadataStream) ([]usermd.StatusChangeMetadata, error) {
+	c, err := statusChangesDecode(current)
+	if err != nil {
+		return nil, err
+	}
+	u, err := statusChangesDecode(update)
+	if err != nil {
+		return nil, err
+	}
+	if len(u) < len(c) || !reflect.DeepEqual(c, u[:len(c)]) {
+		return nil, backend.PluginError{
+			PluginID:     usermd.PluginID,
+			ErrorCode:    uint32(usermd.ErrorCodeStatusInvalid),
+			ErrorContext: "status change history cannot be modified",
+		}
+	}
+	transfers := u[len(c):]
+	for _, v := range transfers {
+		if v.OwnershipTransfer == nil {
+			return nil, backend.PluginError{
+				PluginID:  usermd.PluginID,
+				ErrorCode: uint32(usermd.ErrorCodeStatusInvalid),
+				ErrorContext: "status changes cannot be added using a " +
+					"metadata update",
+			}
+		}
+	}
+	return transfers, nil
+}
+
+// ownershipTransferVerify verifies that the provided ownership transfer is
+// valid for a record that has the provided record metadata and current record
+// authors.
+func ownershipTransferVerify(rm backend.RecordMetadata, authors []string, sc usermd.StatusChangeMetadata) error {
+	ot := sc.OwnershipTransfer
+
+	// Verify the token, version, and status
+	switch {
+	case sc.Token != rm.Token:
+		return backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeTokenInvalid),
+			ErrorContext: fmt.Sprintf("ownership transfer token does not "+
+				"match record metadata token: got %v, want %v",
+				sc.Token, rm.Token),
+		}
+	case sc.Version != rm.Version:
+		return backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeOwnershipTransferInvalid),
+			ErrorContext: fmt.Sprintf("version is not the latest: "+
+				"got %v, want %v", sc.Version, rm.Version),
+		}
+	case sc.Status != uint32(rm.Status):
+		return backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeStatusInvalid),
+			ErrorContext: fmt.Sprintf("status does not match the record "+
+				"status: got %v, want %v", sc.Status, rm.Status),
+		}
+	}
+	if _, ok := transferStatuses[rm.Status]; !ok {
+		return backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeRecordLocked),
+			ErrorContext: fmt.Sprintf("record status is %v",
+				backend.Statuses[rm.Status]),
+		}
+	}
+
+	// Verify the user IDs and the reason
+	_, err := uuid.Parse(ot.ToUserID)
+	if err != nil {
+		return backend.PluginError{
+			PluginID:     usermd.PluginID,
+			ErrorCode:    uint32(usermd.ErrorCodeUserIDInvalid),
+			ErrorContext: fmt.Sprintf("to user %v", ot.ToUserID),
+		}
+	}
+	if sc.Reason == "" {
+		return backend.PluginError{
+			PluginID:     usermd.PluginID,
+			ErrorCode:    uint32(usermd.ErrorCodeReasonMissing),
+			ErrorContext: "a reason must be given for ownership transfers",
+		}
+	}
+
+	// Verify signatures
+	err = ownershipTransferSignaturesVerify(sc)
+	if err != nil {
+		return err
+	}
+
+	// Verify that the ownership is being transferred from a current
+	// record author to a user that is not a record author.
+	return ownershipTransferAllowed(authors, ot.FromUserID, ot.ToUserID)
+}
+
+// ownershipTransferSignaturesVerify verifies the author signature and the
+// admin signature of an ownership transfer.
+func ownershipTransferSignaturesVerify(sc usermd.StatusChangeMetadata) error {
+	var (
+		ot      = sc.OwnershipTransfer
+		version = strconv.FormatUint(uint64(sc.Version), 10)
+		msg     = sc.Token + version + ot.FromUserID + ot.ToUserID + sc.Reason
+	)
+	err := util.VerifySignature(ot.AuthorSignature, ot.AuthorPublicKey, msg)
+	if err != nil {
+		pe := convertSignatureError(err)
+		pe.ErrorContext = fmt.Sprintf("author: %v", pe.ErrorContext)
+		return pe
+	}
+	err = util.VerifySignature(sc.Signature, sc.PublicKey,
+		msg+ot.AuthorSignature)
+	if err != nil {
+		pe := convertSignatureError(err)
+		pe.ErrorContext = fmt.Sprintf("admin: %v", pe.ErrorContext)
+		return pe
+	}
+	return nil
+}
+
+// ownershipTransferAllowed verifies that the ownership of a record can be
+// transferred from the provided user to the provided user given the current
+// record authors.
+func ownershipTransferAllowed(authors []string, fromUserID, toUserID string) error {
+	var isAuthor bool
+	for _, v := range authors {
+		switch v {
+		case toUserID:
+			return backend.PluginError{
+				PluginID:  usermd.PluginID,
+				ErrorCode: uint32(usermd.ErrorCodeOwnershipTransferInvalid),
+				ErrorContext: fmt.Sprintf("user %v is already a record "+
+					"author", toUserID),
+			}
+		case fromUserID:
+			isAuthor = true
+		}
+	}
+	if !isAuthor {
+		return backend.PluginError{
+			PluginID:  usermd.PluginID,
+			ErrorCode: uint32(usermd.ErrorCodeOwnershipTransferInvalid),
+			ErrorContext: fmt.Sprintf("user %v is not a record author",
+				fromUserID),
+		}
+	}
+	return nil
+}
+
+// ownershipTransferCache updates the plugin caches for an ownership transfer
+// that was appended to the status change history of a record. The token of
+// the record is moved from the user cache of the previous owner to the user
+// cache of the new owner and the transfer is added to the activity timeline of
+// both users.
+func (p *usermdPlugin) ownershipTransferCache(rm backend.RecordMetadata, sc usermd.StatusChangeMetadata) error {
+	ot := sc.OwnershipTransfer
+	err := p.userCacheTransferToken(ot.FromUserID, ot.ToUserID,
+		rm.State, rm.Token)
+	if err != nil {
+		return err
+	}
+	return p.activityAdd([]string{ot.FromUserID, ot.ToUserID},
+		usermd.Activity{
+			Type:       usermd.ActivityTypeOwnershipTransfer,
+			Token:      rm.Token,
+			State:      uint32(rm.State),
+			Version:    rm.Version,
+			FromUserID: ot.FromUserID,
+			ToUserID:   ot.ToUserID,
+			Timestamp:  sc.Timestamp,
+		})
+}
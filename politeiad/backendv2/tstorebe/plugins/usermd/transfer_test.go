@@ -0,0 +1,367 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package usermd
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+	"github.com/google/uuid"
+)
+
+func TestOwnershipTransfersApply(t *testing.T) {
+	authors := []string{"a", "b", "c"}
+
+	// newTransfer returns a status change that transfers the ownership
+	// of the record at the provided version.
+	newTransfer := func(version uint32, from, to string) usermd.StatusChangeMetadata {
+		return usermd.StatusChangeMetadata{
+			Version: version,
+			OwnershipTransfer: &usermd.OwnershipTransfer{
+				FromUserID: from,
+				ToUserID:   to,
+			},
+		}
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name          string                        // Test name
+		version       uint32                        // Record version
+		statusChanges []usermd.StatusChangeMetadata // Status change history
+		want          []string                      // Expected authors
+	}{
+		{
+			"no transfers",
+			1,
+			[]usermd.StatusChangeMetadata{
+				{Version: 1, Status: uint32(backend.StatusPublic)},
+			},
+			[]string{"a", "b", "c"},
+		},
+		{
+			"submitting author transferred",
+			1,
+			[]usermd.StatusChangeMetadata{
+				newTransfer(1, "a", "d"),
+			},
+			[]string{"d", "b", "c"},
+		},
+		{
+			"transfers applied in order",
+			1,
+			[]usermd.StatusChangeMetadata{
+				newTransfer(1, "b", "d"),
+				{Version: 1, Status: uint32(backend.StatusPublic)},
+				newTransfer(1, "d", "e"),
+			},
+			[]string{"a", "e", "c"},
+		},
+		{
+			"prior version transfers ignored",
+			2,
+			[]usermd.StatusChangeMetadata{
+				newTransfer(1, "a", "d"),
+				newTransfer(2, "c", "e"),
+			},
+			[]string{"a", "b", "e"},
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			got := ownershipTransfersApply(authors, v.version,
+				v.statusChanges)
+			if !reflect.DeepEqual(got, v.want) {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+
+	// Verify that the provided authors were not modified
+	if !reflect.DeepEqual(authors, []string{"a", "b", "c"}) {
+		t.Errorf("authors were modified: %v", authors)
+	}
+}
+
+func TestOwnershipTransfersNew(t *testing.T) {
+	var (
+		statusChange = usermd.StatusChangeMetadata{
+			Token:  "45154fb45664714b",
+			Status: uint32(backend.StatusPublic),
+		}
+		transfer = usermd.StatusChangeMetadata{
+			Token:  "45154fb45664714b",
+			Status: uint32(backend.StatusPublic),
+			OwnershipTransfer: &usermd.OwnershipTransfer{
+				FromUserID: "a",
+				ToUserID:   "b",
+			},
+		}
+	)
+
+	// Setup tests
+	var tests = []struct {
+		name    string                        // Test name
+		current []usermd.StatusChangeMetadata // Current history
+		update  []usermd.StatusChangeMetadata // Updated history
+		want    int                           // Expected transfers
+		errCode usermd.ErrorCodeT             // Expected error code
+	}{
+		{
+			"no changes",
+			[]usermd.StatusChangeMetadata{statusChange},
+			[]usermd.StatusChangeMetadata{statusChange},
+			0,
+			usermd.ErrorCodeInvalid,
+		},
+		{
+			"history removed",
+			[]usermd.StatusChangeMetadata{statusChange},
+			[]usermd.StatusChangeMetadata{},
+			0,
+			usermd.ErrorCodeStatusInvalid,
+		},
+		{
+			"history modified",
+			[]usermd.StatusChangeMetadata{statusChange},
+			[]usermd.StatusChangeMetadata{transfer, transfer},
+			0,
+			usermd.ErrorCodeStatusInvalid,
+		},
+		{
+			"status change appended",
+			[]usermd.StatusChangeMetadata{statusChange},
+			[]usermd.StatusChangeMetadata{statusChange, statusChange},
+			0,
+			usermd.ErrorCodeStatusInvalid,
+		},
+		{
+			"success",
+			[]usermd.StatusChangeMetadata{statusChange},
+			[]usermd.StatusChangeMetadata{statusChange, transfer, transfer},
+			2,
+			usermd.ErrorCodeInvalid,
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			transfers, err := ownershipTransfersNew(
+				newStatusChangesStream(t, v.current),
+				newStatusChangesStream(t, v.update))
+			if v.errCode != usermd.ErrorCodeInvalid {
+				assertPluginError(t, err, v.errCode)
+				return
+			}
+			if err != nil {
+				t.Fatalf("want error nil, got '%v'", err)
+			}
+			if len(transfers) != v.want {
+				t.Errorf("got %v transfers, want %v",
+					len(transfers), v.want)
+			}
+		})
+	}
+}
+
+func TestOwnershipTransferVerify(t *testing.T) {
+	author, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		fromUserID = uuid.New().String()
+		toUserID   = uuid.New().String()
+		authors    = []string{fromUserID}
+		rm         = backend.RecordMetadata{
+			Token:   "45154fb45664714b",
+			Version: 2,
+			Status:  backend.StatusPublic,
+		}
+	)
+
+	// newTransfer returns a signed ownership transfer for the provided
+	// record version and status.
+	newTransfer := func(version uint32, status backend.StatusT, to string) usermd.StatusChangeMetadata {
+		sc := usermd.StatusChangeMetadata{
+			Token:   rm.Token,
+			Version: version,
+			Status:  uint32(status),
+			Reason:  "author is no longer active",
+			OwnershipTransfer: &usermd.OwnershipTransfer{
+				FromUserID:      fromUserID,
+				ToUserID:        to,
+				AuthorPublicKey: author.Public.String(),
+			},
+		}
+		msg := sc.Token + strconv.FormatUint(uint64(version), 10) +
+			fromUserID + to + sc.Reason
+		sig := author.SignMessage([]byte(msg))
+		sc.OwnershipTransfer.AuthorSignature = hex.EncodeToString(sig[:])
+		sig = admin.SignMessage([]byte(msg +
+			sc.OwnershipTransfer.AuthorSignature))
+		sc.PublicKey = admin.Public.String()
+		sc.Signature = hex.EncodeToString(sig[:])
+		return sc
+	}
+	transfer := newTransfer(rm.Version, rm.Status, toUserID)
+
+	// The author signature does not cover the admin fields
+	badAuthorSig := newTransfer(rm.Version, rm.Status, toUserID)
+	badAuthorSig.OwnershipTransfer.ToUserID = uuid.New().String()
+
+	// The admin signed using the wrong key
+	badAdminSig := newTransfer(rm.Version, rm.Status, toUserID)
+	badAdminSig.PublicKey = author.Public.String()
+
+	// The reason is missing
+	noReason := newTransfer(rm.Version, rm.Status, toUserID)
+	noReason.Reason = ""
+
+	// Setup tests
+	var tests = []struct {
+		name    string                      // Test name
+		rm      backend.RecordMetadata      // Record metadata
+		sc      usermd.StatusChangeMetadata // Ownership transfer
+		errCode usermd.ErrorCodeT           // Expected error code
+	}{
+		{
+			"version is not the latest",
+			rm,
+			newTransfer(1, rm.Status, toUserID),
+			usermd.ErrorCodeOwnershipTransferInvalid,
+		},
+		{
+			"status does not match",
+			rm,
+			newTransfer(rm.Version, backend.StatusUnreviewed, toUserID),
+			usermd.ErrorCodeStatusInvalid,
+		},
+		{
+			"record locked",
+			backend.RecordMetadata{
+				Token:   rm.Token,
+				Version: rm.Version,
+				Status:  backend.StatusArchived,
+			},
+			newTransfer(rm.Version, backend.StatusArchived, toUserID),
+			usermd.ErrorCodeRecordLocked,
+		},
+		{
+			"reason missing",
+			rm,
+			noReason,
+			usermd.ErrorCodeReasonMissing,
+		},
+		{
+			"invalid author signature",
+			rm,
+			badAuthorSig,
+			usermd.ErrorCodeSignatureInvalid,
+		},
+		{
+			"invalid admin signature",
+			rm,
+			badAdminSig,
+			usermd.ErrorCodeSignatureInvalid,
+		},
+		{
+			"transfer to an author",
+			rm,
+			newTransfer(rm.Version, rm.Status, fromUserID),
+			usermd.ErrorCodeOwnershipTransferInvalid,
+		},
+		{
+			"success",
+			rm,
+			transfer,
+			usermd.ErrorCodeInvalid,
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := ownershipTransferVerify(v.rm, authors, v.sc)
+			if v.errCode != usermd.ErrorCodeInvalid {
+				assertPluginError(t, err, v.errCode)
+				return
+			}
+			if err != nil {
+				t.Errorf("want error nil, got '%v'", err)
+			}
+		})
+	}
+}
+
+func TestOwnershipTransferAllowed(t *testing.T) {
+	authors := []string{"a", "b"}
+
+	err := ownershipTransferAllowed(authors, "c", "d")
+	if err == nil {
+		t.Errorf("want error for non-author transfer, got nil")
+	}
+	err = ownershipTransferAllowed(authors, "a", "b")
+	if err == nil {
+		t.Errorf("want error for transfer to an author, got nil")
+	}
+	err = ownershipTransferAllowed(authors, "a", "c")
+	if err != nil {
+		t.Errorf("want error nil, got '%v'", err)
+	}
+}
+
+// newStatusChangesStream returns the metadata streams for the provided status
+// change history.
+func newStatusChangesStream(t *testing.T, statusChanges []usermd.StatusChangeMetadata) []backend.MetadataStream {
+	t.Helper()
+
+	var payload string
+	for _, v := range statusChanges {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload += string(b)
+	}
+	return []backend.MetadataStream{
+		{
+			PluginID: usermd.PluginID,
+			StreamID: usermd.StreamIDStatusChanges,
+			Payload:  payload,
+		},
+	}
+}
+
+// assertPluginError verifies that the provided error is a usermd plugin error
+// with the provided error code.
+func assertPluginError(t *testing.T, err error, errCode usermd.ErrorCodeT) {
+	t.Helper()
+
+	var pe backend.PluginError
+	if !errors.As(err, &pe) {
+		t.Errorf("want plugin error, got '%v'", err)
+		return
+	}
+	if usermd.ErrorCodeT(pe.ErrorCode) != errCode {
+		t.Errorf("want error '%v', got '%v'",
+			usermd.ErrorCodes[errCode],
+			usermd.ErrorCodes[usermd.ErrorCodeT(pe.ErrorCode)])
+	}
+}
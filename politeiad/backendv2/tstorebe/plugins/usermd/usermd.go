@@ -9,11 +9,13 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"sync"
 
 	backend "github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/plugins"
 	"github.com/decred/politeia/politeiad/plugins/usermd"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -26,12 +28,17 @@ var (
 // usermdPlugin satisfies the plugins PluginClient interface.
 type usermdPlugin struct {
 	sync.Mutex
-	tstore plugins.TstoreClient
+	backend backend.Backend
+	tstore  plugins.TstoreClient
 
 	// dataDir is the pi plugin data directory. The only data that is
 	// stored here is cached data that can be re-created at any time
 	// by walking the trillian trees.
 	dataDir string
+
+	// Plugin settings
+	coAuthorsMax         uint32
+	userActivityPageSize uint32
 }
 
 // Setup performs any plugin setup that is required.
@@ -54,6 +61,10 @@ func (p *usermdPlugin) Cmd(token []byte, cmd, payload string) (string, error) {
 		return p.cmdAuthor(token)
 	case usermd.CmdUserRecords:
 		return p.cmdUserRecords(payload)
+	case usermd.CmdTransferOwnership:
+		return p.cmdTransferOwnership(token, payload)
+	case usermd.CmdUserActivity:
+		return p.cmdUserActivity(payload)
 	}
 
 	return "", backend.ErrPluginCmdInvalid
@@ -72,12 +83,18 @@ func (p *usermdPlugin) Hook(h plugins.HookT, payload string) error {
 		return p.hookNewRecordPost(payload)
 	case plugins.HookTypeEditRecordPre:
 		return p.hookEditRecordPre(payload)
+	case plugins.HookTypeEditRecordPost:
+		return p.hookEditRecordPost(payload)
 	case plugins.HookTypeEditMetadataPre:
 		return p.hookEditMetadataPre(payload)
+	case plugins.HookTypeEditMetadataPost:
+		return p.hookEditMetadataPost(payload)
 	case plugins.HookTypeSetRecordStatusPre:
 		return p.hookSetRecordStatusPre(payload)
 	case plugins.HookTypeSetRecordStatusPost:
 		return p.hookSetRecordStatusPost(payload)
+	case plugins.HookTypePluginPost:
+		return p.hookPluginPost(payload)
 	}
 
 	return nil
@@ -127,19 +144,31 @@ func (p *usermdPlugin) addMissingRecord(tokens []string, missingRecord *backend.
 // It verifies the user cache using the following process:
 //
 // 1. For each record, get the user metadata file from the db.
-// 2. Get the user cache for the record's author.
+// 2. Get the user cache for each of the record's current authors. The
+//    current authors include any ownership transfers.
 // 3. Verify that the record is listed in the user cache under the
 //    correct category.  If the record is not found in the user
 //    cache, add it.  The tokens listed in the user cache are
 //    ordered by the timestamp of their most recent status change
 //    from oldest to newest.
 //
+// It verifies the activity caches using the following process:
+//
+// 1. For each record, rebuild the activity of every user on the record
+//    from the record versions, the status change history, and the
+//    comments plugin data.
+// 2. Verify that the rebuilt activity is listed in the activity cache
+//    of each user. Any missing entries are added to the activity
+//    cache, which remains sorted by timestamp from oldest to newest.
+//    A corrupt activity cache is replaced.
+//
 // This function satisfies the plugins PluginClient interface.
 func (p *usermdPlugin) Fsck(tokens [][]byte) error {
 	log.Tracef("usermd Fsck")
 
-	// Number of records which were added to the user cache.
-	var c int64
+	// Number of records which were added to the user cache and
+	// number of entries which were added to the activity caches.
+	var c, ac int64
 
 	for _, token := range tokens {
 		r, err := p.tstore.RecordPartial(token, 0, nil, true)
@@ -147,68 +176,102 @@ func (p *usermdPlugin) Fsck(tokens [][]byte) error {
 			return err
 		}
 
-		// Decode user metadata
-		um, err := userMetadataDecode(r.Metadata)
+		// Verify the user cache of every record author
+		authors, err := currentAuthors(r.RecordMetadata.Version,
+			r.Metadata)
 		if err != nil {
 			return err
 		}
+		for _, userID := range authors {
+			added, err := p.fsckUserCache(userID, r)
+			if err != nil {
+				return err
+			}
+			if added {
+				c++
+			}
+		}
 
-		// Get the user cache for the record's author
-		uc, err := p.userCache(um.UserID)
+		// Verify the activity cache of every user that has activity
+		// on the record.
+		activity, err := p.userActivity(token, r)
 		if err != nil {
 			return err
 		}
-
-		// Verify that the record is listed in the user cache under the
-		// correct category.
-		var found bool
-		tokenStr := hex.EncodeToString(token)
-		switch r.RecordMetadata.State {
-		case backend.StateUnvetted:
-			for _, t := range uc.Unvetted {
-				if t == tokenStr {
-					found = true
-				}
-			}
-			// Unvetted record is missing, add it
-			if !found {
-				uc.Unvetted, err = p.addMissingRecord(uc.Unvetted, r)
-				if err != nil {
-					return err
-				}
+		for userID, entries := range activity {
+			added, err := p.fsckActivityCache(userID, entries)
+			if err != nil {
+				return err
 			}
+			ac += int64(added)
+		}
+	}
+
+	log.Infof("%v missing records were added to the user records cache", c)
+	log.Infof("%v missing entries were added to the user activity caches", ac)
 
-		case backend.StateVetted:
-			for _, t := range uc.Vetted {
-				if t == tokenStr {
-					found = true
-				}
+	return nil
+}
+
+// fsckUserCache verifies that the provided record is listed in the user cache
+// of the provided user under the correct category. The record is added to the
+// user cache if it is missing. A bool is returned indicating whether the
+// record was added to the user cache.
+func (p *usermdPlugin) fsckUserCache(userID string, r *backend.Record) (bool, error) {
+	// Get the user cache
+	uc, err := p.userCache(userID)
+	if err != nil {
+		return false, err
+	}
+
+	// Verify that the record is listed in the user cache under the
+	// correct category.
+	var found bool
+	tokenStr := r.RecordMetadata.Token
+	switch r.RecordMetadata.State {
+	case backend.StateUnvetted:
+		for _, t := range uc.Unvetted {
+			if t == tokenStr {
+				found = true
 			}
-			// Vetted record is missing, add it
-			if !found {
-				uc.Vetted, err = p.addMissingRecord(uc.Vetted, r)
-				if err != nil {
-					return err
-				}
+		}
+		// Unvetted record is missing, add it
+		if !found {
+			uc.Unvetted, err = p.addMissingRecord(uc.Unvetted, r)
+			if err != nil {
+				return false, err
 			}
 		}
 
-		// If a missing token was added to the user cache, save new user cache
-		// to disk.
+	case backend.StateVetted:
+		for _, t := range uc.Vetted {
+			if t == tokenStr {
+				found = true
+			}
+		}
+		// Vetted record is missing, add it
 		if !found {
-			err = p.userCacheSave(um.UserID, *uc)
+			uc.Vetted, err = p.addMissingRecord(uc.Vetted, r)
 			if err != nil {
-				return err
+				return false, err
 			}
-			c++
-			log.Debugf("Missing %v record %v was added to %v user records cache",
-				backend.States[r.RecordMetadata.State], tokenStr, um.UserID)
 		}
 	}
+	if found {
+		return false, nil
+	}
 
-	log.Infof("%v missing records were added to the user records cache", c)
+	// A missing token was added to the user cache. Save the new user
+	// cache to disk.
+	err = p.userCacheSave(userID, *uc)
+	if err != nil {
+		return false, err
+	}
 
-	return nil
+	log.Debugf("Missing %v record %v was added to %v user records cache",
+		backend.States[r.RecordMetadata.State], tokenStr, userID)
+
+	return true, nil
 }
 
 // Settings returns the plugin's settings.
@@ -217,11 +280,20 @@ func (p *usermdPlugin) Fsck(tokens [][]byte) error {
 func (p *usermdPlugin) Settings() []backend.PluginSetting {
 	log.Tracef("usermd Settings")
 
-	return nil
+	return []backend.PluginSetting{
+		{
+			Key:   usermd.SettingKeyCoAuthorsMax,
+			Value: strconv.FormatUint(uint64(p.coAuthorsMax), 10),
+		},
+		{
+			Key:   usermd.SettingKeyUserActivityPageSize,
+			Value: strconv.FormatUint(uint64(p.userActivityPageSize), 10),
+		},
+	}
 }
 
 // New returns a new usermdPlugin.
-func New(tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir string) (*usermdPlugin, error) {
+func New(backend backend.Backend, tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir string) (*usermdPlugin, error) {
 	// Create plugin data directory
 	dataDir = filepath.Join(dataDir, usermd.PluginID)
 	err := os.MkdirAll(dataDir, 0700)
@@ -229,8 +301,41 @@ func New(tstore plugins.TstoreClient, settings []backend.PluginSetting, dataDir
 		return nil, err
 	}
 
+	// Setup plugin setting default values
+	var (
+		coAuthorsMax         = usermd.SettingCoAuthorsMax
+		userActivityPageSize = usermd.SettingUserActivityPageSize
+	)
+
+	// Override defaults with any passed in settings
+	for _, v := range settings {
+		switch v.Key {
+		case usermd.SettingKeyCoAuthorsMax:
+			u, err := strconv.ParseUint(v.Value, 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+			coAuthorsMax = uint32(u)
+
+		case usermd.SettingKeyUserActivityPageSize:
+			u, err := strconv.ParseUint(v.Value, 10, 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+			userActivityPageSize = uint32(u)
+
+		default:
+			return nil, errors.Errorf("invalid plugin setting: %v", v.Key)
+		}
+	}
+
 	return &usermdPlugin{
-		tstore:  tstore,
-		dataDir: dataDir,
+		backend:              backend,
+		tstore:               tstore,
+		dataDir:              dataDir,
+		coAuthorsMax:         coAuthorsMax,
+		userActivityPageSize: userActivityPageSize,
 	}, nil
 }
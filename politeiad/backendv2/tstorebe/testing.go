@@ -25,6 +25,10 @@ func NewTestTstoreBackend(t *testing.T) (*tstoreBackend, func()) {
 		t.Fatal(err)
 	}
 	dataDir := filepath.Join(appDir, "data")
+	err = os.MkdirAll(dataDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	tstoreBackend := tstoreBackend{
 		appDir:     appDir,
@@ -105,7 +105,7 @@ func (t *testClient) TreesAll() ([]*trillian.Tree, error) {
 	t.Lock()
 	defer t.Unlock()
 
-	trees := make([]*trillian.Tree, len(t.trees))
+	trees := make([]*trillian.Tree, 0, len(t.trees))
 	for _, v := range t.trees {
 		trees = append(trees, &trillian.Tree{
 			TreeId:      v.TreeId,
@@ -131,18 +131,15 @@ func (t *testClient) LeavesAppend(treeID int64, leavesAppend []*trillian.LogLeaf
 		leaves = make([]*trillian.LogLeaf, 0, len(leavesAppend))
 	}
 
-	// Get last leaf index
-	var index int64
-	if len(leaves) > 0 {
-		index = int64(len(leaves)) - 1
-	}
+	// Get the index of the next leaf
+	index := int64(len(leaves))
 
 	// Append leaves
 	queued := make([]QueuedLeafProof, 0, len(leavesAppend))
 	for _, v := range leavesAppend {
 		// Append to leaves
 		v.MerkleLeafHash = MerkleLeafHash(v.LeafValue)
-		v.LeafIndex = index + 1
+		v.LeafIndex = index
 		leaves = append(leaves, v)
 		index++
 
@@ -186,8 +183,8 @@ func (t *testClient) LeavesAll(treeID int64) ([]*trillian.LogLeaf, error) {
 	leavesCopy := make([]*trillian.LogLeaf, 0, len(leaves))
 	for _, v := range leaves {
 		var (
-			leafValue []byte
-			extraData []byte
+			leafValue = make([]byte, len(v.LeafValue))
+			extraData = make([]byte, len(v.ExtraData))
 		)
 		copy(leafValue, v.LeafValue)
 		copy(extraData, v.ExtraData)
@@ -105,7 +105,7 @@ func (t *Tstore) PluginRegister(b backend.Backend, p backend.Plugin) error {
 		}
 	case umplugin.PluginID:
 		tstoreClient := NewTstoreClient(t, umplugin.PluginID)
-		pluginClient, err = usermd.New(tstoreClient, p.Settings, dataDir)
+		pluginClient, err = usermd.New(b, tstoreClient, p.Settings, dataDir)
 		if err != nil {
 			return err
 		}
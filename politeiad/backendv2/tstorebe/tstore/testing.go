@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"testing"
 
+	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store/localdb"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tlog"
 )
@@ -33,7 +34,11 @@ func NewTestTstore(t *testing.T, dataDir string) *Tstore {
 	}
 
 	return &Tstore{
-		tlog:  tlog.NewTestClient(t),
-		store: store,
+		dataDir:         dataDir,
+		activeNetParams: chaincfg.TestNet3Params(),
+		tlog:            tlog.NewTestClient(t),
+		store:           store,
+		plugins:         make(map[string]plugin),
+		tokens:          make(map[string][]byte),
 	}
 }
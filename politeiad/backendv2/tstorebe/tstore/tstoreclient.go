@@ -10,7 +10,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	backend "github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/plugins"
@@ -483,6 +485,68 @@ func (t *tstoreClient) RecordState(token []byte) (backend.StateT, error) {
 	return t.tstore.RecordState(token)
 }
 
+// RecordMetadataAppend appends the provided metadata streams onto the
+// metadata of the most recent version of a record and saves the result as a
+// new iteration of the record. The version of the record is not incremented.
+// Plugins are only allowed to append onto their own metadata streams. The
+// plugin hooks are not executed.
+//
+// This function must be called WITH the record lock held.
+//
+// This function satisfies the plugins TstoreClient interface.
+func (t *tstoreClient) RecordMetadataAppend(token []byte, mdAppend []backend.MetadataStream) error {
+	log.Tracef("RecordMetadataAppend: %x", token)
+
+	// Verify that the plugin is only appending to its own metadata
+	// streams.
+	if len(mdAppend) == 0 {
+		return backend.ErrNoRecordChanges
+	}
+	for _, v := range mdAppend {
+		if v.PluginID != t.pluginID {
+			return fmt.Errorf("plugin %v cannot append to %v metadata "+
+				"stream %v", t.pluginID, v.PluginID, v.StreamID)
+		}
+	}
+
+	// Get the existing record
+	r, err := t.tstore.RecordLatest(token)
+	if err != nil {
+		return err
+	}
+
+	// Apply the appends. The version is not incremented for metadata
+	// only updates. The iteration is incremented.
+	// Its ok if there is no existing metadata for a metadata stream.
+	// In this case the append data becomes the full metadata stream.
+	appends := make(map[string]backend.MetadataStream, len(mdAppend))
+	for _, v := range mdAppend {
+		k := v.PluginID + strconv.FormatUint(uint64(v.StreamID), 10)
+		if md, ok := appends[k]; ok {
+			v.Payload = md.Payload + v.Payload
+		}
+		appends[k] = v
+	}
+	metadata := make([]backend.MetadataStream, 0, len(r.Metadata)+len(appends))
+	for _, v := range r.Metadata {
+		k := v.PluginID + strconv.FormatUint(uint64(v.StreamID), 10)
+		if md, ok := appends[k]; ok {
+			v.Payload += md.Payload
+			delete(appends, k)
+		}
+		metadata = append(metadata, v)
+	}
+	for _, v := range appends {
+		metadata = append(metadata, v)
+	}
+	rm := r.RecordMetadata
+	rm.Iteration++
+	rm.Timestamp = time.Now().Unix()
+
+	// Save the record
+	return t.tstore.RecordSave(token, rm, metadata, r.Files)
+}
+
 // leavesForDescriptor returns all leaves that have and extra data descriptor
 // that matches the provided descriptor. If a record is vetted, only vetted
 // leaves will be returned.
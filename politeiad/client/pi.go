@@ -7,6 +7,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	"github.com/decred/politeia/politeiad/plugins/pi"
@@ -123,3 +124,40 @@ func (c *Client) PiBillingStatusChanges(ctx context.Context, tokens []string) (m
 	return bscsr, nil
 
 }
+
+// PiBillingStatusTimestamps sends the pi plugin BillingStatusTimestamps
+// command to the politeiad v2 API.
+func (c *Client) PiBillingStatusTimestamps(ctx context.Context, token string) (*pi.BillingStatusTimestampsReply, error) {
+	// Setup request
+	cmds := []pdv2.PluginCmd{
+		{
+			Token:   token,
+			ID:      pi.PluginID,
+			Command: pi.CmdBillingStatusTimestamps,
+			Payload: "",
+		},
+	}
+
+	// Send request
+	replies, err := c.PluginReads(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	if len(replies) == 0 {
+		return nil, fmt.Errorf("no replies found")
+	}
+	pcr := replies[0]
+	err = extractPluginCmdError(pcr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var bstr pi.BillingStatusTimestampsReply
+	err = json.Unmarshal([]byte(pcr.Payload), &bstr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bstr, nil
+}
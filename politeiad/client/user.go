@@ -49,6 +49,47 @@ func (c *Client) Author(ctx context.Context, token string) (string, error) {
 	return ar.UserID, nil
 }
 
+// Authors sends the user plugin Author command to the politeiad v2 API and
+// returns the user IDs of all of the record authors. The author that
+// submitted the most recent version of the record is the first entry.
+func (c *Client) Authors(ctx context.Context, token string) ([]string, error) {
+	// Setup request
+	cmds := []pdv2.PluginCmd{
+		{
+			Token:   token,
+			ID:      usermd.PluginID,
+			Command: usermd.CmdAuthor,
+			Payload: "",
+		},
+	}
+
+	// Send request
+	replies, err := c.PluginReads(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	if len(replies) == 0 {
+		return nil, fmt.Errorf("no replies found")
+	}
+	pcr := replies[0]
+	err = extractPluginCmdError(pcr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var ar usermd.AuthorReply
+	err = json.Unmarshal([]byte(pcr.Payload), &ar)
+	if err != nil {
+		return nil, err
+	}
+	authors := make([]string, 0, len(ar.CoAuthors)+1)
+	authors = append(authors, ar.UserID)
+	authors = append(authors, ar.CoAuthors...)
+
+	return authors, nil
+}
+
 // UserRecords sends the user plugin UserRecords command to the politeiad v2
 // API.
 func (c *Client) UserRecords(ctx context.Context, userID string) (*usermd.UserRecordsReply, error) {
@@ -91,3 +132,74 @@ func (c *Client) UserRecords(ctx context.Context, userID string) (*usermd.UserRe
 
 	return &urr, nil
 }
+
+// TransferOwnership sends the user plugin TransferOwnership command to the
+// politeiad v2 API.
+func (c *Client) TransferOwnership(ctx context.Context, to usermd.TransferOwnership) (*usermd.TransferOwnershipReply, error) {
+	// Setup request
+	b, err := json.Marshal(to)
+	if err != nil {
+		return nil, err
+	}
+	cmd := pdv2.PluginCmd{
+		Token:   to.Token,
+		ID:      usermd.PluginID,
+		Command: usermd.CmdTransferOwnership,
+		Payload: string(b),
+	}
+
+	// Send request
+	reply, err := c.PluginWrite(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var tor usermd.TransferOwnershipReply
+	err = json.Unmarshal([]byte(reply), &tor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tor, nil
+}
+
+// UserActivity sends the user plugin UserActivity command to the politeiad v2
+// API.
+func (c *Client) UserActivity(ctx context.Context, ua usermd.UserActivity) ([]usermd.Activity, error) {
+	// Setup request
+	b, err := json.Marshal(ua)
+	if err != nil {
+		return nil, err
+	}
+	cmds := []pdv2.PluginCmd{
+		{
+			ID:      usermd.PluginID,
+			Command: usermd.CmdUserActivity,
+			Payload: string(b),
+		},
+	}
+
+	// Send request
+	replies, err := c.PluginReads(ctx, cmds)
+	if err != nil {
+		return nil, err
+	}
+	if len(replies) == 0 {
+		return nil, fmt.Errorf("no replies found")
+	}
+	pcr := replies[0]
+	err = extractPluginCmdError(pcr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode reply
+	var uar usermd.UserActivityReply
+	err = json.Unmarshal([]byte(pcr.Payload), &uar)
+	if err != nil {
+		return nil, err
+	}
+
+	return uar.Activity, nil
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/store/mysql"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tlog"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe/tstore"
+	"github.com/decred/politeia/politeiad/server"
 	"github.com/decred/politeia/wsdcrdata"
 	"github.com/decred/slog"
 	"github.com/jrick/logrotate/rotator"
@@ -64,6 +65,9 @@ var (
 
 // Initialize package-global logger variables.
 func init() {
+	// Server loggers
+	server.UseLogger(log)
+
 	// Git backend loggers
 	gitbe.UseLogger(gitbeLog)
 
@@ -17,6 +17,10 @@ const (
 	// of a proposal.
 	CmdBillingStatusChanges = "billingstatuschanges"
 
+	// CmdBillingStatusTimestamps command returns the timestamps of the
+	// billing status changes of a proposal.
+	CmdBillingStatusTimestamps = "billingstatustimestamps"
+
 	// CmdSummary command returns a summary for a proposal.
 	CmdSummary = "summary"
 )
@@ -80,6 +84,14 @@ const (
 	// SettingKeyBillingStatusChangesPageSize is the plugin key for
 	// the SettingBillingStatusChangesPageSize plugin setting.
 	SettingKeyBillingStatusChangesPageSize = "billingstatuschangespagesize"
+
+	// SettingKeyProposalSections is the plugin setting key for the
+	// SettingProposalSections plugin setting.
+	SettingKeyProposalSections = "proposalsections"
+
+	// SettingKeyProposalTemplates is the plugin setting key for the
+	// SettingProposalTemplates plugin setting.
+	SettingKeyProposalTemplates = "proposaltemplates"
 )
 
 // Plugin setting default values. These can be overridden by providing a plugin
@@ -149,8 +161,33 @@ var (
 		"research",
 		"design",
 	}
+
+	// SettingProposalSections contains the markdown headings that the
+	// index file of every proposal is required to contain, regardless of
+	// the proposal domain. No sections are required by default.
+	SettingProposalSections = []string{}
+
+	// SettingProposalTemplates contains the default per-domain proposal
+	// templates. No templates are defined by default.
+	SettingProposalTemplates = []ProposalTemplate{}
 )
 
+// ProposalTemplate describes the structure of the index file of a proposal
+// that is submitted under a specific domain.
+//
+// Sections contains the markdown headings that the index file of a proposal
+// with the given domain is required to contain. These are in addition to the
+// sections required by the SettingProposalSections plugin setting. Headings
+// are matched case insensitively and can be of any heading level.
+//
+// Template contains an optional markdown document that clients can use as
+// the starting point when drafting a proposal for the domain.
+type ProposalTemplate struct {
+	Domain   string   `json:"domain"`
+	Sections []string `json:"sections"`
+	Template string   `json:"template,omitempty"`
+}
+
 // ErrorCodeT represents a plugin error that was caused by the user.
 type ErrorCodeT uint32
 
@@ -246,10 +283,15 @@ const (
 	// during a normal proposal submission.
 	ErrorCodeLegacyTokenNotAllowed = 20
 
+	// ErrorCodeIndexFileSectionMissing is returned when the proposal index
+	// file does not contain one or more of the markdown sections that are
+	// required by the proposal sections and templates plugin settings.
+	ErrorCodeIndexFileSectionMissing = 21
+
 	// ErrorCodeLast is used by unit tests to verify that all error codes have
 	// a human readable entry in the ErrorCodes map. This error will never be
 	// returned.
-	ErrorCodeLast ErrorCodeT = 21
+	ErrorCodeLast ErrorCodeT = 22
 )
 
 var (
@@ -276,6 +318,7 @@ var (
 		ErrorCodeExtraDataHintInvalid:          "extra data hint invalid",
 		ErrorCodeLegacyTokenNotAllowed:         "setting legacy token is not allowed",
 		ErrorCodeExtraDataInvalid:              "extra data payload invalid",
+		ErrorCodeIndexFileSectionMissing:       "index file section missing",
 	}
 )
 
@@ -545,3 +588,44 @@ type BillingStatusChanges struct {
 type BillingStatusChangesReply struct {
 	BillingStatusChanges []BillingStatusChange `json:"billingstatuschanges"`
 }
+
+// Proof contains an inclusion proof for the digest in the merkle root. The
+// ExtraData field is used by certain types of proofs to include additional
+// data that is required to validate the proof.
+type Proof struct {
+	Type       string   `json:"type"`
+	Digest     string   `json:"digest"`
+	MerkleRoot string   `json:"merkleroot"`
+	MerklePath []string `json:"merklepath"`
+	ExtraData  string   `json:"extradata"` // JSON encoded
+}
+
+// Timestamp contains all of the data required to verify that a piece of data
+// was timestamped onto the decred blockchain.
+//
+// All digests are hex encoded SHA256 digests. The merkle root can be found in
+// the OP_RETURN of the specified DCR transaction.
+//
+// TxID, MerkleRoot, and Proofs will only be populated once the merkle root has
+// been included in a DCR tx and the tx has 6 confirmations. The Data field
+// will not be populated if the data has been censored.
+type Timestamp struct {
+	Data       string  `json:"data"` // JSON encoded
+	Digest     string  `json:"digest"`
+	TxID       string  `json:"txid"`
+	MerkleRoot string  `json:"merkleroot"`
+	Proofs     []Proof `json:"proofs"`
+}
+
+// BillingStatusTimestamps requests the timestamps of the billing status
+// changes for the provided proposal token.
+type BillingStatusTimestamps struct {
+	Token string `json:"token"`
+}
+
+// BillingStatusTimestampsReply is the reply to the BillingStatusTimestamps
+// command. The data payloads of the timestamps contain BillingStatusChange
+// structures. The timestamps are ordered from oldest to newest.
+type BillingStatusTimestampsReply struct {
+	Timestamps []Timestamp `json:"timestamps"`
+}
@@ -15,6 +15,39 @@ const (
 
 	// CmdUserRecords command returns all records submitted by the given user.
 	CmdUserRecords = "userrecords"
+
+	// CmdTransferOwnership command transfers the ownership of a record from
+	// one of the record authors to a different user.
+	CmdTransferOwnership = "transferownership"
+
+	// CmdUserActivity command returns a page of the activity timeline of a
+	// user.
+	CmdUserActivity = "useractivity"
+)
+
+// Plugin setting keys can be used to specify custom plugin settings. Default
+// plugin setting values can be overridden by providing a plugin setting key
+// and value to the plugin on startup.
+const (
+	// SettingKeyCoAuthorsMax is the plugin setting key for the
+	// SettingCoAuthorsMax plugin setting.
+	SettingKeyCoAuthorsMax = "coauthorsmax"
+
+	// SettingKeyUserActivityPageSize is the plugin setting key for the
+	// SettingUserActivityPageSize plugin setting.
+	SettingKeyUserActivityPageSize = "useractivitypagesize"
+)
+
+// Plugin setting default values. These can be overridden by providing a plugin
+// setting key and value to the plugin on startup.
+const (
+	// SettingCoAuthorsMax is the default maximum number of co-authors that
+	// can be added to a record.
+	SettingCoAuthorsMax uint32 = 5
+
+	// SettingUserActivityPageSize is the default number of activity
+	// entries that are returned per page by the UserActivity command.
+	SettingUserActivityPageSize uint32 = 50
 )
 
 // Stream IDs are the metadata stream IDs for metadata defined in this package.
@@ -70,8 +103,24 @@ const (
 	// is required but is not included.
 	ErrorCodeReasonMissing ErrorCodeT = 8
 
+	// ErrorCodeCoAuthorsInvalid is returned when the co-authors of a record
+	// are invalid. This includes duplicate co-authors, exceeding the
+	// maximum number of co-authors, and changing the set of record authors
+	// during an edit.
+	ErrorCodeCoAuthorsInvalid ErrorCodeT = 9
+
+	// ErrorCodeOwnershipTransferInvalid is returned when an ownership
+	// transfer is invalid. This includes transferring ownership from a
+	// user that is not a record author and transferring ownership to a
+	// user that is already a record author.
+	ErrorCodeOwnershipTransferInvalid ErrorCodeT = 10
+
+	// ErrorCodeRecordLocked is returned when the ownership of a record is
+	// being transferred but the record status does not allow for changes.
+	ErrorCodeRecordLocked ErrorCodeT = 11
+
 	// ErrorCodeLast unit test only.
-	ErrorCodeLast ErrorCodeT = 9
+	ErrorCodeLast ErrorCodeT = 12
 )
 
 var (
@@ -86,6 +135,9 @@ var (
 		ErrorCodeTokenInvalid:                 "token invalid",
 		ErrorCodeStatusInvalid:                "status invalid",
 		ErrorCodeReasonMissing:                "status change reason is missing",
+		ErrorCodeCoAuthorsInvalid:             "co-authors invalid",
+		ErrorCodeOwnershipTransferInvalid:     "ownership transfer invalid",
+		ErrorCodeRecordLocked:                 "record is locked",
 	}
 )
 
@@ -96,8 +148,26 @@ var (
 // merkle root is the ordered merkle root of all user submitted politeiad
 // files. The merkle root is hex encoded before being signed so that the
 // signature is consistent with how politeiad signs the merkle root.
+//
+// CoAuthors contains the additional authors of the record. A record can be
+// jointly owned by multiple users. The user that submits a version of the
+// record can be any of the record authors. The UserID field contains the
+// submitting author and the CoAuthors field contains the remaining authors.
+// Every author must sign the merkle root of every version of the record,
+// which signifies their acceptance of the record content. The set of record
+// authors cannot change between versions of a record.
 type UserMetadata struct {
-	UserID    string `json:"userid"`    // Author user ID
+	UserID    string     `json:"userid"`    // Author user ID
+	PublicKey string     `json:"publickey"` // Key used for signature
+	Signature string     `json:"signature"` // Signature of merkle root
+	CoAuthors []CoAuthor `json:"coauthors,omitempty"`
+}
+
+// CoAuthor contains the user metadata of a record co-author.
+//
+// Signature is the co-author signature of the hex encoded record merkle root.
+type CoAuthor struct {
+	UserID    string `json:"userid"`    // Co-author user ID
 	PublicKey string `json:"publickey"` // Key used for signature
 	Signature string `json:"signature"` // Signature of merkle root
 }
@@ -105,6 +175,13 @@ type UserMetadata struct {
 // StatusChangeMetadata contains the user signature for a record status change.
 //
 // Signature is the client signature of the Token+Version+Status+Reason.
+//
+// The status change history of a record also contains the ownership transfers
+// of the record. An ownership transfer does not change the record status. The
+// OwnershipTransfer field is only populated for ownership transfers. When it
+// is populated, Status is the record status at the time of the transfer and
+// Signature is the admin signature of the Token+Version+FromUserID+ToUserID+
+// Reason+AuthorSignature.
 type StatusChangeMetadata struct {
 	Token     string `json:"token"`
 	Version   uint32 `json:"version"`
@@ -113,18 +190,49 @@ type StatusChangeMetadata struct {
 	PublicKey string `json:"publickey"`
 	Signature string `json:"signature"`
 	Timestamp int64  `json:"timestamp"`
+
+	OwnershipTransfer *OwnershipTransfer `json:"ownershiptransfer,omitempty"`
+}
+
+// OwnershipTransfer contains the details of a record ownership transfer. The
+// ownership of a record can be transferred from one of the record authors to
+// a different user. This allows a record to be maintained when an author is no
+// longer able or willing to do so. The transfer must be signed by the author
+// that is giving up ownership and must be confirmed by an admin.
+//
+// Ownership transfers are appended to the status change history of a record
+// by the TransferOwnership command. The record authors listed in the UserMetadata of a
+// record version are the authors of that version. The ownership transfers of
+// the same version are applied on top of them, in order, to determine the
+// current record authors. A record edit must include the current record
+// authors, so the transfers of prior versions are already reflected in the
+// UserMetadata of the most recent version.
+//
+// AuthorSignature is the signature of the Token+Version+FromUserID+ToUserID+
+// Reason using the FromUserID author's key.
+type OwnershipTransfer struct {
+	FromUserID      string `json:"fromuserid"`
+	ToUserID        string `json:"touserid"`
+	AuthorPublicKey string `json:"authorpublickey"`
+	AuthorSignature string `json:"authorsignature"`
 }
 
 // Author returns the user ID of a record's author.
 type Author struct{}
 
 // AuthorReply is the reply to the Author command.
+//
+// UserID contains the author that submitted the most recent version of the
+// record. CoAuthors contains the user IDs of the remaining record authors.
+// All record authors share ownership of the record.
 type AuthorReply struct {
-	UserID string `json:"userid"`
+	UserID    string   `json:"userid"`
+	CoAuthors []string `json:"coauthors,omitempty"`
 }
 
 // UserRecords retrieves the tokens of all records that were submitted by the
-// provided user ID. The returned tokens are sorted from newest to oldest.
+// provided user ID, including the records that the user is a co-author of.
+// The returned tokens are sorted from newest to oldest.
 type UserRecords struct {
 	UserID string `json:"userid"`
 }
@@ -134,3 +242,127 @@ type UserRecordsReply struct {
 	Unvetted []string `json:"unvetted"`
 	Vetted   []string `json:"vetted"`
 }
+
+// TransferOwnership transfers the ownership of a record from one of the record
+// authors to a different user. The transfer is appended to the status change
+// history of the record and does not change the record status. Version must
+// be the most recent version of the record.
+//
+// AuthorSignature is the signature of the Token+Version+FromUserID+ToUserID+
+// Reason using the FromUserID author's key.
+//
+// Signature is the admin signature of the Token+Version+FromUserID+ToUserID+
+// Reason+AuthorSignature.
+type TransferOwnership struct {
+	Token           string `json:"token"`
+	Version         uint32 `json:"version"`
+	FromUserID      string `json:"fromuserid"`
+	ToUserID        string `json:"touserid"`
+	Reason          string `json:"reason"`
+	AuthorPublicKey string `json:"authorpublickey"`
+	AuthorSignature string `json:"authorsignature"`
+	PublicKey       string `json:"publickey"` // Admin public key
+	Signature       string `json:"signature"` // Admin signature
+}
+
+// TransferOwnershipReply is the reply to the TransferOwnership command.
+// StatusChange is the status change history entry that was appended for the
+// ownership transfer.
+type TransferOwnershipReply struct {
+	StatusChange StatusChangeMetadata `json:"statuschange"`
+}
+
+// ActivityT represents a type of user activity.
+type ActivityT uint32
+
+const (
+	// ActivityTypeInvalid is an invalid activity type.
+	ActivityTypeInvalid ActivityT = 0
+
+	// ActivityTypeRecordNew is the activity type for a new record. This
+	// activity is added to the timeline of every record author.
+	ActivityTypeRecordNew ActivityT = 1
+
+	// ActivityTypeRecordEdit is the activity type for a record edit. This
+	// activity is added to the timeline of the author that submitted the
+	// edit.
+	ActivityTypeRecordEdit ActivityT = 2
+
+	// ActivityTypeRecordStatusChange is the activity type for a record
+	// status change. This activity is added to the timeline of every
+	// record author.
+	ActivityTypeRecordStatusChange ActivityT = 3
+
+	// ActivityTypeOwnershipTransfer is the activity type for a record
+	// ownership transfer. This activity is added to the timeline of both
+	// the previous and the new record author.
+	ActivityTypeOwnershipTransfer ActivityT = 4
+
+	// ActivityTypeCommentNew is the activity type for a new comment.
+	ActivityTypeCommentNew ActivityT = 5
+
+	// ActivityTypeCommentEdit is the activity type for a comment edit.
+	ActivityTypeCommentEdit ActivityT = 6
+
+	// ActivityTypeCommentVote is the activity type for a comment vote.
+	ActivityTypeCommentVote ActivityT = 7
+
+	// ActivityTypeLast unit test only.
+	ActivityTypeLast ActivityT = 8
+)
+
+var (
+	// Activities contains the human readable activity types.
+	Activities = map[ActivityT]string{
+		ActivityTypeInvalid:            "invalid",
+		ActivityTypeRecordNew:          "record new",
+		ActivityTypeRecordEdit:         "record edit",
+		ActivityTypeRecordStatusChange: "record status change",
+		ActivityTypeOwnershipTransfer:  "ownership transfer",
+		ActivityTypeCommentNew:         "comment new",
+		ActivityTypeCommentEdit:        "comment edit",
+		ActivityTypeCommentVote:        "comment vote",
+	}
+)
+
+// Activity represents an entry in the activity timeline of a user.
+//
+// State is the state of the record at the time of the activity. Status is
+// only populated for record status changes. CommentID is only populated for
+// comment activity. Vote is only populated for comment votes and contains the
+// comments plugin vote type. FromUserID and ToUserID are only populated for
+// ownership transfers.
+type Activity struct {
+	Type       ActivityT `json:"type"`
+	Token      string    `json:"token"`
+	State      uint32    `json:"state"`
+	Version    uint32    `json:"version,omitempty"`
+	Status     uint32    `json:"status,omitempty"`
+	CommentID  uint32    `json:"commentid,omitempty"`
+	Vote       int32     `json:"vote,omitempty"`
+	FromUserID string    `json:"fromuserid,omitempty"`
+	ToUserID   string    `json:"touserid,omitempty"`
+	Timestamp  int64     `json:"timestamp"`
+}
+
+// UserActivity requests a page of the activity timeline of a user. The
+// activity timeline contains the record, comment, and comment vote activity
+// of the user. The activity is sorted from newest to oldest.
+//
+// Page numbering starts at 1. The first page is returned if no page is
+// provided. The page size is determined by the SettingUserActivityPageSize
+// plugin setting.
+//
+// Activity that occurred on unvetted records is only included when the
+// Unvetted field is set to true. It is the responsibility of the caller to
+// only request unvetted activity for authorized users.
+type UserActivity struct {
+	UserID   string `json:"userid"`
+	Page     uint32 `json:"page,omitempty"`
+	Unvetted bool   `json:"unvetted,omitempty"`
+}
+
+// UserActivityReply is the reply to the UserActivity command.
+type UserActivityReply struct {
+	Activity []Activity `json:"activity"`
+}
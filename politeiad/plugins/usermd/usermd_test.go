@@ -15,4 +15,8 @@ func TestMaps(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ErrorCodes: %v", err)
 	}
+	err = unittest.TestGenericConstMap(Activities, uint64(ActivityTypeLast))
+	if err != nil {
+		t.Fatalf("Activities: %v", err)
+	}
 }
@@ -23,11 +23,11 @@ import (
 	"github.com/decred/dcrd/chaincfg/v3"
 	v1 "github.com/decred/politeia/politeiad/api/v1"
 	"github.com/decred/politeia/politeiad/api/v1/identity"
-	v2 "github.com/decred/politeia/politeiad/api/v2"
 	"github.com/decred/politeia/politeiad/backend"
 	"github.com/decred/politeia/politeiad/backend/gitbe"
 	"github.com/decred/politeia/politeiad/backendv2"
 	"github.com/decred/politeia/politeiad/backendv2/tstorebe"
+	"github.com/decred/politeia/politeiad/server"
 	"github.com/decred/politeia/util"
 	"github.com/decred/politeia/util/version"
 	"github.com/gorilla/mux"
@@ -133,11 +133,6 @@ func (p *politeia) addRoute(method string, route string, handler http.HandlerFun
 	p.router.StrictSlash(true).HandleFunc(route, handler).Methods(method)
 }
 
-func (p *politeia) addRouteV2(method string, route string, handler http.HandlerFunc, perm permission) {
-	route = v2.APIRoute + route
-	p.addRoute(method, route, handler, perm)
-}
-
 func (p *politeia) setupBackendGit(anp *chaincfg.Params) error {
 	if p.router == nil {
 		return errors.Errorf("router must be initialized")
@@ -291,31 +286,7 @@ func (p *politeia) setupBackendTstore(anp *chaincfg.Params) error {
 		p.getIdentity, permissionPublic)
 
 	// Setup v2 routes
-	p.addRouteV2(http.MethodPost, v2.RouteRecordNew,
-		p.handleRecordNew, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RouteRecordEdit,
-		p.handleRecordEdit, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RouteRecordEditMetadata,
-		p.handleRecordEditMetadata, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RouteRecordSetStatus,
-		p.handleRecordSetStatus, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RouteRecords,
-		p.handleRecords, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RouteRecordTimestamps,
-		p.handleRecordTimestamps, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RouteInventory,
-		p.handleInventory, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RouteInventoryOrdered,
-		p.handleInventoryOrdered, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RoutePluginWrite,
-		p.handlePluginWrite, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RoutePluginReads,
-		p.handlePluginReads, permissionPublic)
-	p.addRouteV2(http.MethodPost, v2.RoutePluginInventory,
-		p.handlePluginInventory, permissionPublic)
-
-	p.addRouteV2(http.MethodPost, v2.RoutePluginInventory,
-		p.handlePluginInventory, permissionPublic)
+	server.New(p.backendv2, p.identity).RegisterRoutes(p.router)
 
 	// Setup plugins
 	if len(p.cfg.Plugins) > 0 {
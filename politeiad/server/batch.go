@@ -1,7 +1,7 @@
 // Copyright (c) 2021 The Decred developers
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
-package main
+package server
 
 import (
 	"sync"
@@ -0,0 +1,58 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package server implements the politeiad v2 HTTP API. The API is served by
+// the politeiad binary and can also be served in-process, e.g. by test
+// harnesses, using any backendv2 implementation.
+package server
+
+import (
+	"net/http"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	v2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/backendv2"
+	"github.com/gorilla/mux"
+)
+
+// Server contains the context that is required to serve the politeiad v2 API.
+type Server struct {
+	backend  backendv2.Backend
+	identity *identity.FullIdentity
+}
+
+// New returns a new Server. The identity is used to sign the server responses
+// and records.
+func New(backend backendv2.Backend, id *identity.FullIdentity) *Server {
+	return &Server{
+		backend:  backend,
+		identity: id,
+	}
+}
+
+// RegisterRoutes registers the v2 API routes onto the provided router. All v2
+// routes are public.
+func (s *Server) RegisterRoutes(router *mux.Router) {
+	routes := []struct {
+		route   string
+		handler http.HandlerFunc
+	}{
+		{v2.RouteRecordNew, s.handleRecordNew},
+		{v2.RouteRecordEdit, s.handleRecordEdit},
+		{v2.RouteRecordEditMetadata, s.handleRecordEditMetadata},
+		{v2.RouteRecordSetStatus, s.handleRecordSetStatus},
+		{v2.RouteRecords, s.handleRecords},
+		{v2.RouteRecordTimestamps, s.handleRecordTimestamps},
+		{v2.RouteInventory, s.handleInventory},
+		{v2.RouteInventoryOrdered, s.handleInventoryOrdered},
+		{v2.RoutePluginWrite, s.handlePluginWrite},
+		{v2.RoutePluginReads, s.handlePluginReads},
+		{v2.RoutePluginInventory, s.handlePluginInventory},
+	}
+	for _, v := range routes {
+		router.StrictSlash(true).
+			HandleFunc(v2.APIRoute+v.route, v.handler).
+			Methods(http.MethodPost)
+	}
+}
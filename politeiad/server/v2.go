@@ -1,7 +1,7 @@
 // Copyright (c) 2020-2021 The Decred developers
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
-package main
+package server
 
 import (
 	"encoding/hex"
@@ -17,7 +17,7 @@ import (
 	"github.com/decred/politeia/util"
 )
 
-func (p *politeia) handleRecordNew(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleRecordNew(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleRecordNew")
 
 	// Decode request
@@ -44,7 +44,7 @@ func (p *politeia) handleRecordNew(w http.ResponseWriter, r *http.Request) {
 		metadata = convertMetadataStreamsToBackend(rn.Metadata)
 		files    = convertFilesToBackend(rn.Files)
 	)
-	rc, err := p.backendv2.RecordNew(metadata, files)
+	rc, err := s.backend.RecordNew(metadata, files)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleRecordNew: RecordNew: %v", err)
@@ -52,10 +52,10 @@ func (p *politeia) handleRecordNew(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Prepare reply
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	rnr := v2.RecordNewReply{
 		Response: hex.EncodeToString(response[:]),
-		Record:   p.convertRecordToV2(*rc),
+		Record:   s.convertRecordToV2(*rc),
 	}
 
 	log.Infof("%v Record created %v",
@@ -64,7 +64,7 @@ func (p *politeia) handleRecordNew(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, rnr)
 }
 
-func (p *politeia) handleRecordEdit(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleRecordEdit(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleRecordEdit")
 
 	// Decode request
@@ -101,7 +101,7 @@ func (p *politeia) handleRecordEdit(w http.ResponseWriter, r *http.Request) {
 		mdOverwrite = convertMetadataStreamsToBackend(re.MDOverwrite)
 		filesAdd    = convertFilesToBackend(re.FilesAdd)
 	)
-	rc, err := p.backendv2.RecordEdit(token, mdAppend,
+	rc, err := s.backend.RecordEdit(token, mdAppend,
 		mdOverwrite, filesAdd, re.FilesDel)
 	if err != nil {
 		respondWithErrorV2(w, r,
@@ -110,10 +110,10 @@ func (p *politeia) handleRecordEdit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Prepare reply
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	rer := v2.RecordEditReply{
 		Response: hex.EncodeToString(response[:]),
-		Record:   p.convertRecordToV2(*rc),
+		Record:   s.convertRecordToV2(*rc),
 	}
 
 	log.Infof("%v Record edited %v",
@@ -122,7 +122,7 @@ func (p *politeia) handleRecordEdit(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, rer)
 }
 
-func (p *politeia) handleRecordEditMetadata(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleRecordEditMetadata(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleRecordEditMetadata")
 
 	// Decode request
@@ -158,7 +158,7 @@ func (p *politeia) handleRecordEditMetadata(w http.ResponseWriter, r *http.Reque
 		mdAppend    = convertMetadataStreamsToBackend(re.MDAppend)
 		mdOverwrite = convertMetadataStreamsToBackend(re.MDOverwrite)
 	)
-	rc, err := p.backendv2.RecordEditMetadata(token, mdAppend, mdOverwrite)
+	rc, err := s.backend.RecordEditMetadata(token, mdAppend, mdOverwrite)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleRecordEditMetadata: RecordEditMetadata: %v", err)
@@ -166,16 +166,16 @@ func (p *politeia) handleRecordEditMetadata(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Prepare reply
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	rer := v2.RecordEditMetadataReply{
 		Response: hex.EncodeToString(response[:]),
-		Record:   p.convertRecordToV2(*rc),
+		Record:   s.convertRecordToV2(*rc),
 	}
 
 	util.RespondWithJSON(w, http.StatusOK, rer)
 }
 
-func (p *politeia) handleRecordSetStatus(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleRecordSetStatus(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleRecordSetStatus")
 
 	// Decode request
@@ -212,7 +212,7 @@ func (p *politeia) handleRecordSetStatus(w http.ResponseWriter, r *http.Request)
 		mdOverwrite = convertMetadataStreamsToBackend(rss.MDOverwrite)
 		status      = backendv2.StatusT(rss.Status)
 	)
-	rc, err := p.backendv2.RecordSetStatus(token, status,
+	rc, err := s.backend.RecordSetStatus(token, status,
 		mdAppend, mdOverwrite)
 	if err != nil {
 		respondWithErrorV2(w, r,
@@ -221,10 +221,10 @@ func (p *politeia) handleRecordSetStatus(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Prepare reply
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	rer := v2.RecordSetStatusReply{
 		Response: hex.EncodeToString(response[:]),
-		Record:   p.convertRecordToV2(*rc),
+		Record:   s.convertRecordToV2(*rc),
 	}
 
 	log.Infof("%v Record status set %v %v", util.RemoteAddr(r),
@@ -233,7 +233,7 @@ func (p *politeia) handleRecordSetStatus(w http.ResponseWriter, r *http.Request)
 	util.RespondWithJSON(w, http.StatusOK, rer)
 }
 
-func (p *politeia) handleRecords(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleRecords(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleRecords")
 
 	// Decode request
@@ -266,7 +266,7 @@ func (p *politeia) handleRecords(w http.ResponseWriter, r *http.Request) {
 
 	// Get record batch
 	reqs := convertRecordRequestsToBackend(rgb.Requests)
-	brecords, err := p.backendv2.Records(reqs)
+	brecords, err := s.backend.Records(reqs)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleRecordGet: Records: %v", err)
@@ -276,9 +276,9 @@ func (p *politeia) handleRecords(w http.ResponseWriter, r *http.Request) {
 	// Prepare reply
 	records := make(map[string]v2.Record, len(brecords))
 	for k, v := range brecords {
-		records[k] = p.convertRecordToV2(v)
+		records[k] = s.convertRecordToV2(v)
 	}
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	reply := v2.RecordsReply{
 		Response: hex.EncodeToString(response[:]),
 		Records:  records,
@@ -287,7 +287,7 @@ func (p *politeia) handleRecords(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, reply)
 }
 
-func (p *politeia) handleRecordTimestamps(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleRecordTimestamps(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleRecordTimestamps")
 
 	// Decode request
@@ -319,7 +319,7 @@ func (p *politeia) handleRecordTimestamps(w http.ResponseWriter, r *http.Request
 	}
 
 	// Get record timestamps
-	rt, err := p.backendv2.RecordTimestamps(token, rgt.Version)
+	rt, err := s.backend.RecordTimestamps(token, rgt.Version)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleRecordTimestamps: RecordTimestamps: %v", err)
@@ -327,7 +327,7 @@ func (p *politeia) handleRecordTimestamps(w http.ResponseWriter, r *http.Request
 	}
 
 	// Prepare reply
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	rtr := v2.RecordTimestampsReply{
 		Response:       hex.EncodeToString(response[:]),
 		RecordMetadata: convertTimestampToV2(rt.RecordMetadata),
@@ -338,7 +338,7 @@ func (p *politeia) handleRecordTimestamps(w http.ResponseWriter, r *http.Request
 	util.RespondWithJSON(w, http.StatusOK, rtr)
 }
 
-func (p *politeia) handleInventory(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleInventory(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleInventory")
 
 	// Decode request
@@ -390,7 +390,7 @@ func (p *politeia) handleInventory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get inventory
-	inv, err := p.backendv2.Inventory(state, status, pageSize, pageNumber)
+	inv, err := s.backend.Inventory(state, status, pageSize, pageNumber)
 	if err != nil {
 		respondWithErrorV2(w, r,
 			"handleInventory: Inventory: %v", err)
@@ -408,7 +408,7 @@ func (p *politeia) handleInventory(w http.ResponseWriter, r *http.Request) {
 		key := backendv2.Statuses[k]
 		vetted[key] = v
 	}
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	ir := v2.InventoryReply{
 		Response: hex.EncodeToString(response[:]),
 		Unvetted: unvetted,
@@ -418,7 +418,7 @@ func (p *politeia) handleInventory(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, ir)
 }
 
-func (p *politeia) handleInventoryOrdered(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleInventoryOrdered(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleInventoryOrdered")
 
 	// Decode request
@@ -454,7 +454,7 @@ func (p *politeia) handleInventoryOrdered(w http.ResponseWriter, r *http.Request
 	}
 
 	// Get inventory
-	tokens, err := p.backendv2.InventoryOrdered(state,
+	tokens, err := s.backend.InventoryOrdered(state,
 		v2.InventoryPageSize, i.Page)
 	if err != nil {
 		respondWithErrorV2(w, r,
@@ -462,7 +462,7 @@ func (p *politeia) handleInventoryOrdered(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	ir := v2.InventoryOrderedReply{
 		Response: hex.EncodeToString(response[:]),
 		Tokens:   tokens,
@@ -471,7 +471,7 @@ func (p *politeia) handleInventoryOrdered(w http.ResponseWriter, r *http.Request
 	util.RespondWithJSON(w, http.StatusOK, ir)
 }
 
-func (p *politeia) handlePluginWrite(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handlePluginWrite(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handlePluginWrite")
 
 	// Decode request
@@ -503,7 +503,7 @@ func (p *politeia) handlePluginWrite(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Execute plugin cmd
-	payload, err := p.backendv2.PluginWrite(token, pw.Cmd.ID,
+	payload, err := s.backend.PluginWrite(token, pw.Cmd.ID,
 		pw.Cmd.Command, pw.Cmd.Payload)
 	if err != nil {
 		respondWithErrorV2(w, r,
@@ -512,7 +512,7 @@ func (p *politeia) handlePluginWrite(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Prepare reply
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	pwr := v2.PluginWriteReply{
 		Response: hex.EncodeToString(response[:]),
 		Payload:  payload,
@@ -524,7 +524,7 @@ func (p *politeia) handlePluginWrite(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, pwr)
 }
 
-func (p *politeia) handlePluginReads(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handlePluginReads(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handlePluginReads")
 
 	// Decode request
@@ -548,7 +548,7 @@ func (p *politeia) handlePluginReads(w http.ResponseWriter, r *http.Request) {
 
 	// Execute the batch of read cmds
 	batch := newBatch(pr.Cmds)
-	batch.execConcurrently(p.backendv2.PluginRead)
+	batch.execConcurrently(s.backend.PluginRead)
 
 	// Prepare the replies
 	replies := make([]v2.PluginCmdReply, len(pr.Cmds))
@@ -616,7 +616,7 @@ func (p *politeia) handlePluginReads(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Prepare reply
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	prr := v2.PluginReadsReply{
 		Response: hex.EncodeToString(response[:]),
 		Replies:  replies,
@@ -626,7 +626,7 @@ func (p *politeia) handlePluginReads(w http.ResponseWriter, r *http.Request) {
 
 }
 
-func (p *politeia) handlePluginInventory(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handlePluginInventory(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handlePluginInventory")
 
 	// Decode request
@@ -649,10 +649,10 @@ func (p *politeia) handlePluginInventory(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get plugin inventory
-	plugins := p.backendv2.PluginInventory()
+	plugins := s.backend.PluginInventory()
 
 	// Prepare reply
-	response := p.identity.SignMessage(challenge)
+	response := s.identity.SignMessage(challenge)
 	ir := v2.PluginInventoryReply{
 		Response: hex.EncodeToString(response[:]),
 		Plugins:  convertPluginsToV2(plugins),
@@ -674,12 +674,12 @@ func decodeTokenAnyLength(token string) ([]byte, error) {
 	return util.TokenDecodeAnyLength(util.TokenTypeTstore, token)
 }
 
-func (p *politeia) convertRecordToV2(r backendv2.Record) v2.Record {
+func (s *Server) convertRecordToV2(r backendv2.Record) v2.Record {
 	var (
 		metadata = convertMetadataStreamsToV2(r.Metadata)
 		files    = convertFilesToV2(r.Files)
 		rm       = r.RecordMetadata
-		sig      = p.identity.SignMessage([]byte(rm.Merkle + rm.Token))
+		sig      = s.identity.SignMessage([]byte(rm.Merkle + rm.Token))
 	)
 	return v2.Record{
 		State:     v2.RecordStateT(rm.State),
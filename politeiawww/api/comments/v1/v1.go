@@ -38,6 +38,47 @@ const (
 	RouteTimestamps = "/timestamps"
 )
 
+// The following plugin ID and commands are used to access this API through
+// the politeiawww v3 plugin routes. The command payloads and replies are the
+// same as the request and reply types of the corresponding routes.
+const (
+	// PluginID is the politeiawww plugin ID for this API.
+	PluginID = "comments"
+
+	// Version is the plugin API version.
+	Version uint32 = 1
+)
+
+// Plugin commands
+const (
+	// CmdPolicy command returns the policy for the comments API.
+	CmdPolicy = "policy"
+
+	// CmdNew command adds a new comment.
+	CmdNew = "new"
+
+	// CmdEdit command edits a comment.
+	CmdEdit = "edit"
+
+	// CmdVote command votes on a comment.
+	CmdVote = "vote"
+
+	// CmdDel command deletes a comment.
+	CmdDel = "del"
+
+	// CmdCount command returns the number of comments on a batch of records.
+	CmdCount = "count"
+
+	// CmdComments command returns all comments of a record.
+	CmdComments = "comments"
+
+	// CmdVotes command returns the comment votes of a record.
+	CmdVotes = "votes"
+
+	// CmdTimestamps command returns the timestamps of a batch of comments.
+	CmdTimestamps = "timestamps"
+)
+
 // ErrorCodeT represents a user error code.
 type ErrorCodeT uint32
 
@@ -70,6 +70,37 @@ const (
 	//
 	// This route accepts a Batch and returns a BatchReply.
 	ReadBatchRoute = "/readbatch"
+
+	// RateLimitsRoute is a GET request route that returns the rate limit
+	// policies that are enforced by the server along with their counters.
+	//
+	// This route returns a RateLimitsReply.
+	RateLimitsRoute = "/ratelimits"
+
+	// APIKeyNewRoute is a POST request route that creates a new API key for
+	// the logged in user.
+	//
+	// This route is CSRF protected and requires a session cookie. API keys
+	// cannot be used to manage API keys.
+	//
+	// This route accepts an APIKeyNew and returns an APIKeyNewReply.
+	APIKeyNewRoute = "/apikey/new"
+
+	// APIKeyRevokeRoute is a POST request route that revokes an API key of the
+	// logged in user.
+	//
+	// This route is CSRF protected and requires a session cookie.
+	//
+	// This route accepts an APIKeyRevoke and returns an APIKeyRevokeReply.
+	APIKeyRevokeRoute = "/apikey/revoke"
+
+	// APIKeysRoute is a POST request route that returns the API keys of the
+	// logged in user.
+	//
+	// This route is CSRF protected and requires a session cookie.
+	//
+	// This route accepts an APIKeys and returns an APIKeysReply.
+	APIKeysRoute = "/apikeys"
 )
 
 const (
@@ -79,6 +110,14 @@ const (
 	// SessionCookieName is the cookie name for the session cookie. Clients will
 	// have the session cookie set the first time a plugin command route is hit.
 	SessionCookieName = "session"
+
+	// APIKeyHeader is the header that contains the API key for requests that
+	// are authenticated using an API key instead of a session cookie.
+	//
+	// Requests that contain an API key are not CSRF protected and do not use
+	// the session cookie. API keys are accepted by the WriteRoute, ReadRoute,
+	// and ReadBatchRoute.
+	APIKeyHeader = "X-API-Key"
 )
 
 // Version contains the GET request parameters for the VersionRoute. The
@@ -117,6 +156,31 @@ type PolicyReply struct {
 	ReadBatchLimit uint32 `json:"readbatchlimit"`
 }
 
+// RateLimits contains the GET request parameters for the RateLimitsRoute. The
+// RateLimitsRoute returns a RateLimitsReply.
+type RateLimits struct{}
+
+// RateLimit contains a rate limit policy and its counters.
+//
+// The target is either a route, e.g. "/v3/read", or a plugin command in the
+// format "pluginID.cmd". The key describes how clients are identified and is
+// either "ip" or "user". Each client is allowed a burst of requests that is
+// refilled at a rate of Rate requests per Interval seconds.
+type RateLimit struct {
+	Target   string `json:"target"`
+	Key      string `json:"key"`
+	Rate     uint32 `json:"rate"`
+	Interval uint32 `json:"interval"` // In seconds
+	Burst    uint32 `json:"burst"`
+	Allowed  uint64 `json:"allowed"` // Number of requests allowed
+	Limited  uint64 `json:"limited"` // Number of requests rate limited
+}
+
+// RateLimitsReply is the reply for the RateLimitsRoute.
+type RateLimitsReply struct {
+	RateLimits []RateLimit `json:"ratelimits"`
+}
+
 // Cmd represents a plugin command.
 type Cmd struct {
 	PluginID string `json:"pluginid"`
@@ -200,6 +264,28 @@ const (
 	// ErrorCodeBatchLimitExceeded is return when the number of plugin commands
 	// that are allowed to be executed in a batch request is exceeded.
 	ErrorCodeBatchLimitExceeded ErrorCodeT = 4
+
+	// ErrorCodeRateLimitExceeded is returned when a client has exceeded a rate
+	// limit policy. The HTTP status code will be 429 and the Retry-After header
+	// will contain the number of seconds that the client must wait before
+	// retrying the request.
+	ErrorCodeRateLimitExceeded ErrorCodeT = 5
+
+	// ErrorCodeNotLoggedIn is returned when a route requires a logged in user
+	// and the session cookie does not belong to a logged in user.
+	ErrorCodeNotLoggedIn ErrorCodeT = 6
+
+	// ErrorCodeAPIKeyInvalid is returned when the API key that was provided in
+	// the APIKeyHeader is malformed, does not exist, or has expired.
+	ErrorCodeAPIKeyInvalid ErrorCodeT = 7
+
+	// ErrorCodeAPIKeyScope is returned when the API key that was provided has
+	// not been granted a scope that allows the plugin command to be executed.
+	ErrorCodeAPIKeyScope ErrorCodeT = 8
+
+	// ErrorCodeAPIKeyNotFound is returned when an API key could not be found
+	// for the logged in user.
+	ErrorCodeAPIKeyNotFound ErrorCodeT = 9
 )
 
 var (
@@ -210,6 +296,11 @@ var (
 		ErrorCodePluginNotFound:      "plugin not found",
 		ErrorCodePluginNotAuthorized: "plugin not authorized",
 		ErrorCodeBatchLimitExceeded:  "batch limit exceeded",
+		ErrorCodeRateLimitExceeded:   "rate limit exceeded",
+		ErrorCodeNotLoggedIn:         "not logged in",
+		ErrorCodeAPIKeyInvalid:       "api key invalid",
+		ErrorCodeAPIKeyScope:         "api key scope not allowed",
+		ErrorCodeAPIKeyNotFound:      "api key not found",
 	}
 )
 
@@ -225,3 +316,78 @@ type InternalError struct {
 func (e InternalError) Error() string {
 	return fmt.Sprintf("internal server error: %v", e.ErrorCode)
 }
+
+const (
+	// APIKeyScopeRead allows an API key to execute read-only plugin commands.
+	// All API keys are allowed to execute read-only plugin commands.
+	APIKeyScopeRead = "read"
+
+	// APIKeyScopeComment allows an API key to execute the comments plugin
+	// write commands.
+	APIKeyScopeComment = "comment"
+
+	// APIKeyScopeVoteAuthorize allows an API key to execute the ticketvote
+	// plugin authorize command.
+	APIKeyScopeVoteAuthorize = "voteauthorize"
+
+	// APIKeyScopeAdmin allows an API key to execute all plugin commands.
+	APIKeyScopeAdmin = "admin"
+)
+
+// APIKeyScopes contains the supported API key scopes.
+var APIKeyScopes = map[string]struct{}{
+	APIKeyScopeRead:          {},
+	APIKeyScopeComment:       {},
+	APIKeyScopeVoteAuthorize: {},
+	APIKeyScopeAdmin:         {},
+}
+
+// APIKey contains the details of an API key. The API key secret is not
+// included.
+//
+// API key scopes only restrict the plugin commands that can be executed using
+// the key. The user that the API key belongs to must still be authorized to
+// execute the plugin command.
+type APIKey struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  int64    `json:"createdat"`  // Unix timestamp
+	Expiry     int64    `json:"expiry"`     // Unix timestamp
+	LastUsedAt int64    `json:"lastusedat"` // Unix timestamp, 0 if unused
+}
+
+// APIKeyNew creates a new API key for the logged in user.
+//
+// The Expiry is a Unix timestamp. A default expiry is used if the Expiry is
+// not provided.
+type APIKeyNew struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	Expiry int64    `json:"expiry,omitempty"`
+}
+
+// APIKeyNewReply is the reply to the APIKeyNew command. The Key is the
+// plaintext API key that must be provided in the APIKeyHeader. It is only
+// returned once and cannot be recovered.
+type APIKeyNewReply struct {
+	APIKey APIKey `json:"apikey"`
+	Key    string `json:"key"`
+}
+
+// APIKeyRevoke revokes an API key of the logged in user. A revoked API key
+// is deleted and cannot be used again.
+type APIKeyRevoke struct {
+	ID string `json:"id"`
+}
+
+// APIKeyRevokeReply is the reply to the APIKeyRevoke command.
+type APIKeyRevokeReply struct{}
+
+// APIKeys requests the API keys of the logged in user.
+type APIKeys struct{}
+
+// APIKeysReply is the reply to the APIKeys command.
+type APIKeysReply struct {
+	APIKeys []APIKey `json:"apikeys"`
+}
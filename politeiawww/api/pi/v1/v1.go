@@ -13,17 +13,59 @@ const (
 	// RoutePolicy returns the policy for the pi API.
 	RoutePolicy = "/policy"
 
+	// RouteTemplates returns the proposal templates.
+	RouteTemplates = "/templates"
+
 	// RouteSetBillingStatus sets the proposal's billing status.
 	RouteSetBillingStatus = "/setbillingstatus"
 
 	// RouteBillingStatusChanges returns the proposal's billing status changes.
 	RouteBillingStatusChanges = "/billingstatuschanges"
 
+	// RouteBillingStatusTimestamps returns the timestamps of the proposal's
+	// billing status changes.
+	RouteBillingStatusTimestamps = "/billingstatustimestamps"
+
 	// RouteSummaries returns the proposal summary for a page of
 	// records.
 	RouteSummaries = "/summaries"
 )
 
+// The following plugin ID and commands are used to access this API through
+// the politeiawww v3 plugin routes. The command payloads and replies are the
+// same as the request and reply types of the corresponding routes.
+const (
+	// PluginID is the politeiawww plugin ID for this API.
+	PluginID = "pi"
+
+	// Version is the plugin API version.
+	Version uint32 = 1
+)
+
+// Plugin commands
+const (
+	// CmdPolicy command returns the policy for the pi API.
+	CmdPolicy = "policy"
+
+	// CmdTemplates command returns the proposal templates.
+	CmdTemplates = "templates"
+
+	// CmdSetBillingStatus command sets the billing status of a proposal.
+	CmdSetBillingStatus = "setbillingstatus"
+
+	// CmdBillingStatusChanges command returns the billing status changes of a
+	// batch of proposals.
+	CmdBillingStatusChanges = "billingstatuschanges"
+
+	// CmdBillingStatusTimestamps command returns the timestamps of the
+	// billing status changes of a proposal.
+	CmdBillingStatusTimestamps = "billingstatustimestamps"
+
+	// CmdSummaries command returns the proposal summaries of a batch of
+	// proposals.
+	CmdSummaries = "summaries"
+)
+
 // ErrorCodeT represents a user error code.
 type ErrorCodeT uint32
 
@@ -134,6 +176,44 @@ type PolicyReply struct {
 	SummariesPageSize            uint32   `json:"summariespagesize"`
 	BillingStatusChangesPageSize uint32   `json:"billingstatuschangespagesize"`
 	BillingStatusChangesMax      uint32   `json:"billingstatuschangesmax"`
+
+	// Sections contains the markdown headings that the index file of
+	// every proposal is required to contain. Additional sections may be
+	// required for specific domains. See the Templates route.
+	Sections []string `json:"sections"`
+}
+
+// Templates requests the proposal templates. Proposal templates describe the
+// required structure of the index file of proposals that are submitted under
+// a specific domain.
+type Templates struct{}
+
+// TemplatesReply is the reply to the Templates command.
+//
+// Sections contains the markdown headings that are required for all
+// proposals, regardless of domain.
+//
+// Templates contains the templates for the domains that have one. A domain
+// that does not have a template only requires the sections that are listed
+// in the Sections field.
+type TemplatesReply struct {
+	Sections  []string           `json:"sections"`
+	Templates []ProposalTemplate `json:"templates"`
+}
+
+// ProposalTemplate describes the structure of the index file of a proposal
+// that is submitted under a specific domain.
+//
+// Sections contains the markdown headings that the index file is required to
+// contain, in addition to the sections that are required for all proposals.
+// Headings are matched case insensitively and can be of any heading level.
+//
+// Template contains an optional markdown document that can be used as the
+// starting point when drafting a proposal for the domain.
+type ProposalTemplate struct {
+	Domain   string   `json:"domain"`
+	Sections []string `json:"sections"`
+	Template string   `json:"template,omitempty"`
 }
 
 const (
@@ -304,6 +384,49 @@ type BillingStatusChangesReply struct {
 	BillingStatusChanges map[string][]BillingStatusChange `json:"billingstatuschanges"`
 }
 
+// Proof contains an inclusion proof for the digest in the merkle root. All
+// digests are hex encoded SHA256 digests.
+//
+// The ExtraData field is used by certain types of proofs to include
+// additional data that is required to validate the proof.
+type Proof struct {
+	Type       string   `json:"type"`
+	Digest     string   `json:"digest"`
+	MerkleRoot string   `json:"merkleroot"`
+	MerklePath []string `json:"merklepath"`
+	ExtraData  string   `json:"extradata"` // JSON encoded
+}
+
+// Timestamp contains all of the data required to verify that a piece of
+// data was timestamped onto the decred blockchain.
+//
+// All digests are hex encoded SHA256 digests. The merkle root can be found
+// in the OP_RETURN of the specified DCR transaction.
+//
+// TxID, MerkleRoot, and Proofs will only be populated once the merkle root
+// has been included in a DCR tx and the tx has 6 confirmations. The Data
+// field will not be populated if the data has been censored.
+type Timestamp struct {
+	Data       string  `json:"data"` // JSON encoded
+	Digest     string  `json:"digest"`
+	TxID       string  `json:"txid"`
+	MerkleRoot string  `json:"merkleroot"`
+	Proofs     []Proof `json:"proofs"`
+}
+
+// BillingStatusTimestamps requests the timestamps of the billing status
+// changes of a proposal.
+type BillingStatusTimestamps struct {
+	Token string `json:"token"`
+}
+
+// BillingStatusTimestampsReply is the reply to the BillingStatusTimestamps
+// command. The data payloads of the timestamps contain BillingStatusChange
+// structures. The timestamps are ordered from oldest to newest.
+type BillingStatusTimestampsReply struct {
+	Timestamps []Timestamp `json:"timestamps"`
+}
+
 const (
 	// ProposalUpdateHint is the hint that is included in a comment's
 	// ExtraDataHint field to indicate that the comment is an update
@@ -41,6 +41,64 @@ const (
 
 	// RouteUserRecords returnes the tokens of all records submitted by a user.
 	RouteUserRecords = "/userrecords"
+
+	// RouteTransferOwnership transfers the ownership of a record from one of
+	// the record authors to a different user.
+	RouteTransferOwnership = "/transferownership"
+
+	// RouteUserActivity returns a page of the activity timeline of a user.
+	RouteUserActivity = "/useractivity"
+)
+
+// The following plugin ID and commands are used to access this API through
+// the politeiawww v3 plugin routes. The command payloads and replies are the
+// same as the request and reply types of the corresponding routes.
+const (
+	// PluginID is the politeiawww plugin ID for this API.
+	PluginID = "records"
+
+	// Version is the plugin API version.
+	Version uint32 = 1
+)
+
+// Plugin commands
+const (
+	// CmdPolicy command returns the policy for the records API.
+	CmdPolicy = "policy"
+
+	// CmdNew command adds a new record.
+	CmdNew = "new"
+
+	// CmdEdit command edits a record.
+	CmdEdit = "edit"
+
+	// CmdSetStatus command sets the status of a record.
+	CmdSetStatus = "setstatus"
+
+	// CmdDetails command returns the details of a record.
+	CmdDetails = "details"
+
+	// CmdTimestamps command returns the timestamps of a record.
+	CmdTimestamps = "timestamps"
+
+	// CmdRecords command returns a batch of records.
+	CmdRecords = "records"
+
+	// CmdInventory command returns the tokens of the records in the inventory.
+	CmdInventory = "inventory"
+
+	// CmdInventoryOrdered command returns a page of record tokens ordered by the
+	// timestamp of their most recent status change.
+	CmdInventoryOrdered = "inventoryordered"
+
+	// CmdUserRecords command returns the tokens of all records submitted by a user.
+	CmdUserRecords = "userrecords"
+
+	// CmdTransferOwnership command transfers the ownership of a record.
+	CmdTransferOwnership = "transferownership"
+
+	// CmdUserActivity command returns a page of the activity timeline of a user.
+	CmdUserActivity = "useractivity"
 )
 
 // ErrorCodeT represents a user error code.
@@ -324,8 +382,23 @@ type Record struct {
 //
 // Signature is the client signature of the record merkle root. The merkle root
 // is the ordered merkle root of all user submitted politeiad files.
+//
+// CoAuthors contains the additional authors of a jointly owned record. The
+// UserID field contains the author that submitted the record version. Every
+// co-author must also sign the record merkle root.
 type UserMetadata struct {
-	UserID    string `json:"userid"`    // Author user ID
+	UserID    string     `json:"userid"`    // Author user ID
+	PublicKey string     `json:"publickey"` // Key used for signature
+	Signature string     `json:"signature"` // Signature of merkle root
+	CoAuthors []CoAuthor `json:"coauthors,omitempty"`
+}
+
+// CoAuthor contains the user ID and signature of a record co-author.
+//
+// Signature is the co-author signature of the record merkle root. It signifies
+// the co-author's acceptance of the record content.
+type CoAuthor struct {
+	UserID    string `json:"userid"`    // Co-author user ID
 	PublicKey string `json:"publickey"` // Key used for signature
 	Signature string `json:"signature"` // Signature of merkle root
 }
@@ -334,6 +407,12 @@ type UserMetadata struct {
 // server and saved to politeiad as a metadata stream.
 //
 // Signature is the client signature of the Token+Version+Status+Reason.
+//
+// The status change history of a record also contains the ownership transfers
+// of the record. The OwnershipTransfer field is only populated for ownership
+// transfers. When it is populated, Status is the unchanged record status and
+// Signature is the admin signature of the Token+Version+FromUserID+ToUserID+
+// Reason+AuthorSignature.
 type StatusChange struct {
 	Token     string        `json:"token"`
 	Version   uint32        `json:"version"`
@@ -342,16 +421,23 @@ type StatusChange struct {
 	PublicKey string        `json:"publickey"`
 	Signature string        `json:"signature"`
 	Timestamp int64         `json:"timestamp"`
+
+	OwnershipTransfer *OwnershipTransfer `json:"ownershiptransfer,omitempty"`
 }
 
 // New submits a new record.
 //
 // Signature is the client signature of the record merkle root. The merkle root
 // is the ordered merkle root of all record Files.
+//
+// CoAuthors is optional and contains the users that will jointly own the
+// record with the submitting user. Each co-author must sign the record merkle
+// root using their active identity.
 type New struct {
-	Files     []File `json:"files"`
-	PublicKey string `json:"publickey"`
-	Signature string `json:"signature"`
+	Files     []File     `json:"files"`
+	PublicKey string     `json:"publickey"`
+	Signature string     `json:"signature"`
+	CoAuthors []CoAuthor `json:"coauthors,omitempty"`
 }
 
 // NewReply is the reply to the New command.
@@ -363,11 +449,16 @@ type NewReply struct {
 //
 // Signature is the client signature of the record merkle root. The merkle root
 // is the ordered merkle root of all record Files.
+//
+// Any of the record authors can submit an edit. CoAuthors must contain the
+// signatures of all of the remaining record authors. The set of record authors
+// cannot be changed by an edit.
 type Edit struct {
-	Token     string `json:"token"`
-	Files     []File `json:"files"`
-	PublicKey string `json:"publickey"`
-	Signature string `json:"signature"`
+	Token     string     `json:"token"`
+	Files     []File     `json:"files"`
+	PublicKey string     `json:"publickey"`
+	Signature string     `json:"signature"`
+	CoAuthors []CoAuthor `json:"coauthors,omitempty"`
 }
 
 // EditReply is the reply to the Edit command.
@@ -544,3 +635,134 @@ type UserRecordsReply struct {
 	Unvetted []string `json:"unvetted"`
 	Vetted   []string `json:"vetted"`
 }
+
+// TransferOwnership transfers the ownership of a record from one of the record
+// authors to a different user. This allows a record to be maintained when an
+// author is no longer able or willing to do so. The transfer must be signed by
+// the author that is giving up ownership using their active identity and must
+// be submitted by an admin. The transfer is added to the status change history
+// of the record. It does not change the record status.
+//
+// AuthorSignature is the author signature of the Token+Version+FromUserID+
+// ToUserID+Reason.
+//
+// Signature is the admin signature of the Token+Version+FromUserID+ToUserID+
+// Reason+AuthorSignature.
+type TransferOwnership struct {
+	Token           string `json:"token"`
+	Version         uint32 `json:"version"`
+	FromUserID      string `json:"fromuserid"`
+	ToUserID        string `json:"touserid"`
+	Reason          string `json:"reason"`
+	AuthorPublicKey string `json:"authorpublickey"`
+	AuthorSignature string `json:"authorsignature"`
+	PublicKey       string `json:"publickey"`
+	Signature       string `json:"signature"`
+}
+
+// TransferOwnershipReply is the reply to the TransferOwnership command.
+type TransferOwnershipReply struct {
+	Record Record `json:"record"`
+}
+
+// OwnershipTransfer contains the details of a record ownership transfer. It is
+// included in the status change history of a record.
+//
+// AuthorSignature is the author signature of the Token+Version+FromUserID+
+// ToUserID+Reason.
+type OwnershipTransfer struct {
+	FromUserID      string `json:"fromuserid"`
+	ToUserID        string `json:"touserid"`
+	AuthorPublicKey string `json:"authorpublickey"`
+	AuthorSignature string `json:"authorsignature"`
+}
+
+// ActivityT represents a type of user activity.
+type ActivityT uint32
+
+const (
+	// ActivityTypeInvalid is an invalid activity type.
+	ActivityTypeInvalid ActivityT = 0
+
+	// ActivityTypeRecordNew is the activity type for a new record. This
+	// activity is added to the timeline of every record author.
+	ActivityTypeRecordNew ActivityT = 1
+
+	// ActivityTypeRecordEdit is the activity type for a record edit. This
+	// activity is added to the timeline of the author that submitted the
+	// edit.
+	ActivityTypeRecordEdit ActivityT = 2
+
+	// ActivityTypeRecordStatusChange is the activity type for a record
+	// status change. This activity is added to the timeline of every
+	// record author.
+	ActivityTypeRecordStatusChange ActivityT = 3
+
+	// ActivityTypeOwnershipTransfer is the activity type for a record
+	// ownership transfer. This activity is added to the timeline of both
+	// the previous and the new record author.
+	ActivityTypeOwnershipTransfer ActivityT = 4
+
+	// ActivityTypeCommentNew is the activity type for a new comment.
+	ActivityTypeCommentNew ActivityT = 5
+
+	// ActivityTypeCommentEdit is the activity type for a comment edit.
+	ActivityTypeCommentEdit ActivityT = 6
+
+	// ActivityTypeCommentVote is the activity type for a comment vote.
+	ActivityTypeCommentVote ActivityT = 7
+
+	// ActivityTypeLast unit test only.
+	ActivityTypeLast ActivityT = 8
+)
+
+var (
+	// Activities contains the human readable activity types.
+	Activities = map[ActivityT]string{
+		ActivityTypeInvalid:            "invalid",
+		ActivityTypeRecordNew:          "record new",
+		ActivityTypeRecordEdit:         "record edit",
+		ActivityTypeRecordStatusChange: "record status change",
+		ActivityTypeOwnershipTransfer:  "ownership transfer",
+		ActivityTypeCommentNew:         "comment new",
+		ActivityTypeCommentEdit:        "comment edit",
+		ActivityTypeCommentVote:        "comment vote",
+	}
+)
+
+// Activity represents an entry in the activity timeline of a user.
+//
+// State is the state of the record at the time of the activity. Status is
+// only populated for record status changes. CommentID is only populated for
+// comment activity. Vote is only populated for comment votes and contains the
+// comments API vote type. FromUserID and ToUserID are only populated for
+// ownership transfers.
+type Activity struct {
+	Type       ActivityT     `json:"type"`
+	Token      string        `json:"token"`
+	State      RecordStateT  `json:"state"`
+	Version    uint32        `json:"version,omitempty"`
+	Status     RecordStatusT `json:"status,omitempty"`
+	CommentID  uint32        `json:"commentid,omitempty"`
+	Vote       int32         `json:"vote,omitempty"`
+	FromUserID string        `json:"fromuserid,omitempty"`
+	ToUserID   string        `json:"touserid,omitempty"`
+	Timestamp  int64         `json:"timestamp"`
+}
+
+// UserActivity requests a page of the activity timeline of a user. The
+// activity timeline contains the record, comment, and comment vote activity
+// of the user, sorted from newest to oldest.
+//
+// Page numbering starts at 1. The first page is returned if no page is
+// provided. Activity that occurred on unvetted records is only returned to
+// admins and the user.
+type UserActivity struct {
+	UserID string `json:"userid"`
+	Page   uint32 `json:"page,omitempty"`
+}
+
+// UserActivityReply is the reply to the UserActivity command.
+type UserActivityReply struct {
+	Activity []Activity `json:"activity"`
+}
@@ -43,6 +43,52 @@ const (
 	RouteTimestamps = "/timestamps"
 )
 
+// The following plugin ID and commands are used to access this API through
+// the politeiawww v3 plugin routes. The command payloads and replies are the
+// same as the request and reply types of the corresponding routes.
+const (
+	// PluginID is the politeiawww plugin ID for this API.
+	PluginID = "ticketvote"
+
+	// Version is the plugin API version.
+	Version uint32 = 1
+)
+
+// Plugin commands
+const (
+	// CmdPolicy command returns the policy for the ticketvote API.
+	CmdPolicy = "policy"
+
+	// CmdAuthorize command authorizes a ticket vote.
+	CmdAuthorize = "authorize"
+
+	// CmdStart command starts a ticket vote.
+	CmdStart = "start"
+
+	// CmdCastBallot command casts a ballot of votes.
+	CmdCastBallot = "castballot"
+
+	// CmdDetails command returns the vote details of a record.
+	CmdDetails = "details"
+
+	// CmdResults command returns the cast votes of a record.
+	CmdResults = "results"
+
+	// CmdSummaries command returns the vote summaries of a batch of records.
+	CmdSummaries = "summaries"
+
+	// CmdSubmissions command returns the submissions of a runoff vote.
+	CmdSubmissions = "submissions"
+
+	// CmdInventory command returns the tokens of the records in the vote
+	// inventory.
+	CmdInventory = "inventory"
+
+	// CmdTimestamps command returns the timestamps of the vote data of a
+	// record.
+	CmdTimestamps = "timestamps"
+)
+
 // ErrorCodeT represents a user error code.
 type ErrorCodeT uint32
 
@@ -16,6 +16,7 @@ type PropStatusT int
 type PropVoteStatusT int
 type UserManageActionT int
 type EmailNotificationT int
+type EmailDigestT int
 type VoteT int
 type TOTPMethodT int
 
@@ -49,8 +50,14 @@ const (
 	RouteManageUser               = "/user/manage"
 	RouteSetTOTP                  = "/user/totp"
 	RouteVerifyTOTP               = "/user/verifytotp"
+	RouteSetWebAuthn              = "/user/webauthn"
+	RouteVerifyWebAuthn           = "/user/verifywebauthn"
+	RouteWebAuthnCredentials      = "/user/webauthn/credentials"
+	RouteRemoveWebAuthn           = "/user/webauthn/remove"
+	RouteWebAuthnBackupCodes      = "/user/webauthn/backupcodes"
 	RouteUserDetails              = "/user/{userid:[0-9a-zA-Z-]{36}}"
 	RouteUsers                    = "/users"
+	RouteMailQueue                = "/mailqueue"
 	RouteUnauthenticatedWebSocket = "/ws"
 	RouteAuthenticatedWebSocket   = "/aws"
 
@@ -141,6 +148,10 @@ const (
 	// for the routes that return lists of users
 	UserListPageSize = 20
 
+	// MailQueuePageSize is the maximum number of emails returned by
+	// the mail queue route
+	MailQueuePageSize = 100
+
 	// Error status codes
 	ErrorStatusInvalid                     ErrorStatusT = 0
 	ErrorStatusInvalidPassword             ErrorStatusT = 1
@@ -223,7 +234,12 @@ const (
 	ErrorStatusTOTPInvalidType             ErrorStatusT = 78
 	ErrorStatusRequiresTOTPCode            ErrorStatusT = 79
 	ErrorStatusTOTPWaitForNewCode          ErrorStatusT = 80
-	ErrorStatusLast                        ErrorStatusT = 81
+	ErrorStatusWebAuthnFailedValidation    ErrorStatusT = 81
+	ErrorStatusRequiresWebAuthn            ErrorStatusT = 82
+	ErrorStatusWebAuthnCredentialNotFound  ErrorStatusT = 83
+	ErrorStatusInvalidLanguage             ErrorStatusT = 84
+	ErrorStatusInvalidEmailDigest          ErrorStatusT = 85
+	ErrorStatusLast                        ErrorStatusT = 86
 
 	// Proposal state codes
 	//
@@ -302,6 +318,14 @@ const (
 	NotificationEmailCommentOnMyProposal         EmailNotificationT = 1 << 7
 	NotificationEmailCommentOnMyComment          EmailNotificationT = 1 << 8
 
+	// Email digest frequencies. Users that have enabled an email digest
+	// receive the notifications that are enabled in their email
+	// notifications bitmask as a single periodic email instead of as
+	// individual emails.
+	EmailDigestNone   EmailDigestT = 0 // Send notifications individually
+	EmailDigestDaily  EmailDigestT = 1 // Send a daily digest
+	EmailDigestWeekly EmailDigestT = 2 // Send a weekly digest
+
 	// Time-base one time password types
 	TOTPTypeInvalid TOTPMethodT = 0 // Invalid TOTP type
 	TOTPTypeBasic   TOTPMethodT = 1
@@ -409,6 +433,19 @@ var (
 		ErrorStatusTOTPInvalidType:             "invalid totp type",
 		ErrorStatusRequiresTOTPCode:            "login requires totp code",
 		ErrorStatusTOTPWaitForNewCode:          "must wait until next totp code window",
+		ErrorStatusWebAuthnFailedValidation:    "webauthn or backup code validation failed",
+		ErrorStatusRequiresWebAuthn:            "webauthn assertion or backup code required",
+		ErrorStatusWebAuthnCredentialNotFound:  "webauthn credential not found",
+		ErrorStatusInvalidLanguage:             "invalid language",
+		ErrorStatusInvalidEmailDigest:          "invalid email digest",
+	}
+
+	// EmailDigest converts email digest frequencies to human readable
+	// text
+	EmailDigest = map[EmailDigestT]string{
+		EmailDigestNone:   "none",
+		EmailDigestDaily:  "daily",
+		EmailDigestWeekly: "weekly",
 	}
 
 	// PropStatus converts propsal status codes to human readable text
@@ -755,6 +792,12 @@ type Login struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Code     string `json:"code,omitempty"` // TOTP code based on user's TOTP secret (if verified)
+
+	// WebAuthn second factor. Either a WebAuthn assertion or an unused
+	// backup code is required when the user has registered a WebAuthn
+	// credential and has not provided a valid TOTP code.
+	WebAuthn   *WebAuthnAssertion `json:"webauthn,omitempty"`
+	BackupCode string             `json:"backupcode,omitempty"`
 }
 
 // LoginReply is used to reply to the Login command.
@@ -772,6 +815,7 @@ type LoginReply struct {
 	LastLoginTime      int64  `json:"lastlogintime"`      // Unix timestamp of last login date
 	SessionMaxAge      int64  `json:"sessionmaxage"`      // Unix timestamp of session max age
 	TOTPVerified       bool   `json:"totpverified"`       // Whether current totp secret has been verified with
+	WebAuthnEnabled    bool   `json:"webauthnenabled"`    // Whether the user has registered a webauthn credential
 }
 
 //Logout attempts to log the user out.
@@ -854,6 +898,52 @@ type UserPaymentsRescanReply struct {
 	NewCredits []ProposalCredit `json:"newcredits"`
 }
 
+// MailStatusT represents the status of an email in the outbound mail queue.
+type MailStatusT int
+
+const (
+	// MailStatusInvalid is an invalid mail status.
+	MailStatusInvalid MailStatusT = 0
+
+	// MailStatusQueued indicates that the email is waiting to be sent or
+	// to be retried.
+	MailStatusQueued MailStatusT = 1
+
+	// MailStatusSent indicates that the email was sent.
+	MailStatusSent MailStatusT = 2
+
+	// MailStatusFailed indicates that the email could not be sent after
+	// the maximum number of attempts and will not be retried.
+	MailStatusFailed MailStatusT = 3
+)
+
+// MailQueue is used by an admin to inspect the outbound mail queue. It
+// returns up to MailQueuePageSize emails with the provided status, oldest
+// first.
+type MailQueue struct {
+	Status MailStatusT `json:"status"`
+}
+
+// MailQueueReply is the reply to the MailQueue command.
+type MailQueueReply struct {
+	Mail []QueuedMail `json:"mail"`
+}
+
+// QueuedMail is an email in the outbound mail queue. The ID is the
+// idempotency key of the notification event that queued the email, or a
+// random ID for emails that are not tied to an event.
+type QueuedMail struct {
+	ID            string      `json:"id"`
+	Subject       string      `json:"subject"`
+	Recipients    []string    `json:"recipients"`
+	Status        MailStatusT `json:"status"`
+	Attempts      int         `json:"attempts"`      // Send attempts
+	LastError     string      `json:"lasterror"`     // Last send error
+	CreatedAt     int64       `json:"createdat"`     // UNIX timestamp
+	NextAttemptAt int64       `json:"nextattemptat"` // UNIX timestamp
+	SentAt        int64       `json:"sentat"`        // UNIX timestamp
+}
+
 // NewProposal attempts to submit a new proposal.
 //
 // Metadata is required to include a ProposalMetadata for all proposal
@@ -1011,6 +1101,7 @@ type PolicyReply struct {
 	MinVoteDuration            uint32   `json:"minvoteduration"`
 	MaxVoteDuration            uint32   `json:"maxvoteduration"`
 	PaywallConfirmations       uint64   `json:"paywallconfirmations"`
+	EmailLanguages             []string `json:"emaillanguages"`
 }
 
 // VoteOption describes a single vote option.
@@ -1337,7 +1428,9 @@ type ManageUserReply struct{}
 
 // EditUser edits a user's preferences.
 type EditUser struct {
-	EmailNotifications *uint64 `json:"emailnotifications"` // Notify the user via emails
+	EmailNotifications *uint64       `json:"emailnotifications"`    // Notify the user via emails
+	EmailDigest        *EmailDigestT `json:"emaildigest,omitempty"` // Email digest frequency
+	Language           *string       `json:"language,omitempty"`    // Notification email language
 }
 
 // EditUserReply is the reply for the EditUser command.
@@ -1367,6 +1460,8 @@ type User struct {
 	Identities                      []UserIdentity `json:"identities"`
 	ProposalCredits                 uint64         `json:"proposalcredits"`
 	EmailNotifications              uint64         `json:"emailnotifications"` // Notify the user via emails
+	EmailDigest                     EmailDigestT   `json:"emaildigest"`        // Email digest frequency
+	Language                        string         `json:"language,omitempty"` // Notification email language
 }
 
 // UserIdentity represents a user's unique identity.
@@ -1495,3 +1590,114 @@ type VerifyTOTP struct {
 // with no errors.
 type VerifyTOTPReply struct {
 }
+
+// SetWebAuthn begins the registration of a new WebAuthn credential for the
+// logged in user. The reply contains the options that must be passed to the
+// WebAuthn API of the client (navigator.credentials.create). The registration
+// must be completed using VerifyWebAuthn before the challenge expires.
+type SetWebAuthn struct{}
+
+// SetWebAuthnReply contains the public key credential creation options. All
+// binary values are base64url encoded. Clients should request the none
+// attestation conveyance preference.
+type SetWebAuthnReply struct {
+	Challenge          string   `json:"challenge"`
+	RPID               string   `json:"rpid"`
+	RPName             string   `json:"rpname"`
+	UserID             string   `json:"userid"` // User handle
+	Username           string   `json:"username"`
+	Algorithms         []int64  `json:"algorithms"`         // Supported COSE algorithms
+	ExcludeCredentials []string `json:"excludecredentials"` // Registered credential IDs
+	Timeout            int64    `json:"timeout"`            // In milliseconds
+}
+
+// VerifyWebAuthn completes the registration of a WebAuthn credential using
+// the response of the authenticator. The client data JSON and the
+// attestation object are base64url encoded.
+//
+// Backup codes are generated when the first credential is registered. They
+// are only returned once and can each be used once in place of a WebAuthn
+// assertion during login.
+type VerifyWebAuthn struct {
+	Name              string `json:"name"` // User chosen credential name
+	ClientDataJSON    string `json:"clientdatajson"`
+	AttestationObject string `json:"attestationobject"`
+}
+
+// VerifyWebAuthnReply is the reply to the VerifyWebAuthn command.
+type VerifyWebAuthnReply struct {
+	CredentialID string   `json:"credentialid"`
+	BackupCodes  []string `json:"backupcodes,omitempty"`
+}
+
+// WebAuthnCredential contains the details of a registered WebAuthn
+// credential.
+type WebAuthnCredential struct {
+	ID         string `json:"id"` // base64url encoded
+	Name       string `json:"name"`
+	CreatedAt  int64  `json:"createdat"`
+	LastUsedAt int64  `json:"lastusedat"`
+}
+
+// WebAuthnCredentials returns the WebAuthn credentials of the logged in user.
+type WebAuthnCredentials struct{}
+
+// WebAuthnCredentialsReply is the reply to the WebAuthnCredentials command.
+type WebAuthnCredentialsReply struct {
+	Credentials []WebAuthnCredential `json:"credentials"`
+	BackupCodes int                  `json:"backupcodes"` // Unused backup codes
+}
+
+// RemoveWebAuthn removes a WebAuthn credential from the logged in user. The
+// backup codes are removed along with the last credential.
+//
+// The request must be confirmed using either a WebAuthn assertion or an
+// unused backup code. An ErrorStatusRequiresWebAuthn error that contains the
+// challenge to sign is returned when neither have been provided.
+type RemoveWebAuthn struct {
+	CredentialID string             `json:"credentialid"`
+	WebAuthn     *WebAuthnAssertion `json:"webauthn,omitempty"`
+	BackupCode   string             `json:"backupcode,omitempty"`
+}
+
+// RemoveWebAuthnReply is the reply to the RemoveWebAuthn command.
+type RemoveWebAuthnReply struct{}
+
+// WebAuthnBackupCodes replaces the backup codes of the logged in user with a
+// new set of backup codes. The user must have a registered WebAuthn
+// credential.
+//
+// The request must be confirmed using either a WebAuthn assertion or an
+// unused backup code. An ErrorStatusRequiresWebAuthn error that contains the
+// challenge to sign is returned when neither have been provided.
+type WebAuthnBackupCodes struct {
+	WebAuthn   *WebAuthnAssertion `json:"webauthn,omitempty"`
+	BackupCode string             `json:"backupcode,omitempty"`
+}
+
+// WebAuthnBackupCodesReply is the reply to the WebAuthnBackupCodes command.
+type WebAuthnBackupCodesReply struct {
+	BackupCodes []string `json:"backupcodes"`
+}
+
+// WebAuthnAssertion contains the response of an authenticator to a WebAuthn
+// login or confirmation challenge. All values are base64url encoded.
+type WebAuthnAssertion struct {
+	CredentialID      string `json:"credentialid"`
+	ClientDataJSON    string `json:"clientdatajson"`
+	AuthenticatorData string `json:"authenticatordata"`
+	Signature         string `json:"signature"`
+}
+
+// WebAuthnLoginChallenge contains the public key credential request options
+// that must be passed to the WebAuthn API of the client
+// (navigator.credentials.get). It is returned as a JSON encoded error context
+// of the ErrorStatusRequiresWebAuthn error once the password of the user has
+// been verified, and by the requests that change the WebAuthn settings of
+// the user. All binary values are base64url encoded.
+type WebAuthnLoginChallenge struct {
+	Challenge        string   `json:"challenge"`
+	RPID             string   `json:"rpid"`
+	AllowCredentials []string `json:"allowcredentials"`
+	Timeout          int64    `json:"timeout"` // In milliseconds
+}
@@ -0,0 +1,407 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	v3 "github.com/decred/politeia/politeiawww/api/http/v3"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/apikeys"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/decred/politeia/util"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/sessions"
+	"github.com/pkg/errors"
+)
+
+const (
+	// apiKeysMax is the maximum number of API keys that a user can have.
+	apiKeysMax = 20
+
+	// apiKeyNameMaxLength is the maximum length of an API key name.
+	apiKeyNameMaxLength = 64
+
+	// apiKeyExpiryDefault is the expiry that is used when the user does
+	// not provide one.
+	apiKeyExpiryDefault = 90 * 24 * time.Hour
+
+	// apiKeyExpiryMax is the maximum amount of time that an API key can
+	// be valid for.
+	apiKeyExpiryMax = 365 * 24 * time.Hour
+
+	// apiKeyLastUsedInterval is the minimum amount of time between updates
+	// to the last used timestamp of an API key. This prevents every request
+	// from resulting in a database write.
+	apiKeyLastUsedInterval = time.Minute
+)
+
+// handleAPIKeyNew is the request handler for the http v3 APIKeyNewRoute.
+func (p *politeiawww) handleAPIKeyNew(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleAPIKeyNew")
+
+	var akn v3.APIKeyNew
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&akn); err != nil {
+		respondWithError(w, r, "handleAPIKeyNew: %v",
+			v3.UserError{
+				ErrorCode: v3.ErrorCodeInvalidInput,
+			})
+		return
+	}
+
+	userID, err := p.apiKeyUser(r)
+	if err != nil {
+		respondWithError(w, r, "handleAPIKeyNew: apiKeyUser: %v", err)
+		return
+	}
+
+	reply, err := p.apiKeyNew(userID, akn, time.Now())
+	if err != nil {
+		respondWithError(w, r, "handleAPIKeyNew: apiKeyNew: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// handleAPIKeyRevoke is the request handler for the http v3
+// APIKeyRevokeRoute.
+func (p *politeiawww) handleAPIKeyRevoke(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleAPIKeyRevoke")
+
+	var akr v3.APIKeyRevoke
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&akr); err != nil {
+		respondWithError(w, r, "handleAPIKeyRevoke: %v",
+			v3.UserError{
+				ErrorCode: v3.ErrorCodeInvalidInput,
+			})
+		return
+	}
+
+	userID, err := p.apiKeyUser(r)
+	if err != nil {
+		respondWithError(w, r, "handleAPIKeyRevoke: apiKeyUser: %v", err)
+		return
+	}
+
+	// Verify that the API key belongs to the user. The same error
+	// is returned whether the key does not exist or belongs to a
+	// different user.
+	k, err := p.apiKeys.Get(akr.ID)
+	switch {
+	case errors.Is(err, apikeys.ErrNotFound) ||
+		(err == nil && k.UserID != userID):
+		respondWithError(w, r, "handleAPIKeyRevoke: %v",
+			v3.UserError{
+				ErrorCode: v3.ErrorCodeAPIKeyNotFound,
+			})
+		return
+	case err != nil:
+		respondWithError(w, r, "handleAPIKeyRevoke: Get: %v", err)
+		return
+	}
+
+	err = p.apiKeys.Del(k.ID)
+	if err != nil {
+		respondWithError(w, r, "handleAPIKeyRevoke: Del: %v", err)
+		return
+	}
+
+	log.Infof("API key revoked: %v %v %v", userID, k.ID, k.Name)
+
+	util.RespondWithJSON(w, http.StatusOK, v3.APIKeyRevokeReply{})
+}
+
+// handleAPIKeys is the request handler for the http v3 APIKeysRoute.
+func (p *politeiawww) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleAPIKeys")
+
+	userID, err := p.apiKeyUser(r)
+	if err != nil {
+		respondWithError(w, r, "handleAPIKeys: apiKeyUser: %v", err)
+		return
+	}
+
+	keys, err := p.apiKeys.GetByUser(userID)
+	if err != nil {
+		respondWithError(w, r, "handleAPIKeys: GetByUser: %v", err)
+		return
+	}
+
+	reply := v3.APIKeysReply{
+		APIKeys: make([]v3.APIKey, 0, len(keys)),
+	}
+	for _, k := range keys {
+		reply.APIKeys = append(reply.APIKeys, convertAPIKeyToHTTP(k))
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, reply)
+}
+
+// apiKeyUser returns the user ID of the logged in user for the API key
+// management routes. These routes require a session cookie. An API key
+// cannot be used to manage API keys.
+func (p *politeiawww) apiKeyUser(r *http.Request) (string, error) {
+	if r.Header.Get(v3.APIKeyHeader) != "" {
+		return "", v3.UserError{
+			ErrorCode:    v3.ErrorCodeInvalidInput,
+			ErrorContext: "api keys cannot be used to manage api keys",
+		}
+	}
+	s, err := p.extractSession(r)
+	if err != nil {
+		return "", err
+	}
+	userID, _ := sessionValues(s)
+	if userID == "" {
+		return "", v3.UserError{
+			ErrorCode: v3.ErrorCodeNotLoggedIn,
+		}
+	}
+
+	// Verify that the user still exists
+	_, err = p.userDB.Get(userID)
+	switch {
+	case errors.Is(err, user.ErrNotFound):
+		return "", v3.UserError{
+			ErrorCode: v3.ErrorCodeNotLoggedIn,
+		}
+	case err != nil:
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// apiKeyNew creates a new API key for a user.
+func (p *politeiawww) apiKeyNew(userID string, akn v3.APIKeyNew, now time.Time) (*v3.APIKeyNewReply, error) {
+	// Validate the name
+	name := strings.TrimSpace(akn.Name)
+	if name == "" || utf8.RuneCountInString(name) > apiKeyNameMaxLength {
+		return nil, v3.UserError{
+			ErrorCode: v3.ErrorCodeInvalidInput,
+			ErrorContext: fmt.Sprintf("name must be between 1 and %v "+
+				"characters", apiKeyNameMaxLength),
+		}
+	}
+
+	// Validate the scopes
+	if len(akn.Scopes) == 0 {
+		return nil, v3.UserError{
+			ErrorCode:    v3.ErrorCodeInvalidInput,
+			ErrorContext: "no scopes provided",
+		}
+	}
+	scopes := make([]string, 0, len(akn.Scopes))
+	dups := make(map[string]struct{}, len(akn.Scopes))
+	for _, v := range akn.Scopes {
+		if _, ok := v3.APIKeyScopes[v]; !ok {
+			return nil, v3.UserError{
+				ErrorCode:    v3.ErrorCodeInvalidInput,
+				ErrorContext: fmt.Sprintf("invalid scope '%v'", v),
+			}
+		}
+		if _, ok := dups[v]; ok {
+			continue
+		}
+		dups[v] = struct{}{}
+		scopes = append(scopes, v)
+	}
+
+	// Validate the expiry
+	expiry := akn.Expiry
+	if expiry == 0 {
+		expiry = now.Add(apiKeyExpiryDefault).Unix()
+	}
+	if expiry <= now.Unix() || expiry > now.Add(apiKeyExpiryMax).Unix() {
+		return nil, v3.UserError{
+			ErrorCode: v3.ErrorCodeInvalidInput,
+			ErrorContext: fmt.Sprintf("expiry must be in the future and "+
+				"within %v days", int(apiKeyExpiryMax.Hours()/24)),
+		}
+	}
+
+	// Verify that the user has not reached the max number
+	// of API keys.
+	keys, err := p.apiKeys.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) >= apiKeysMax {
+		return nil, v3.UserError{
+			ErrorCode: v3.ErrorCodeInvalidInput,
+			ErrorContext: fmt.Sprintf("max number of api keys (%v) "+
+				"reached", apiKeysMax),
+		}
+	}
+
+	// Create the API key
+	k, key, err := apikeys.New(userID, name, scopes, now.Unix(), expiry)
+	if err != nil {
+		return nil, err
+	}
+	err = p.apiKeys.Insert(*k)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("API key created: %v %v %v %v", userID, k.ID, k.Name,
+		k.Scopes)
+
+	return &v3.APIKeyNewReply{
+		APIKey: convertAPIKeyToHTTP(*k),
+		Key:    key,
+	}, nil
+}
+
+// verifyAPIKey verifies the provided plaintext API key and returns the API
+// key from the database. An ErrorCodeAPIKeyInvalid user error is returned if
+// the API key is malformed, does not exist, or has expired.
+//
+// The last used timestamp of the API key is updated.
+func (p *politeiawww) verifyAPIKey(key string, now time.Time) (*apikeys.APIKey, error) {
+	id, secret, err := apikeys.Parse(key)
+	if err != nil {
+		return nil, v3.UserError{
+			ErrorCode: v3.ErrorCodeAPIKeyInvalid,
+		}
+	}
+	k, err := p.apiKeys.Get(id)
+	switch {
+	case errors.Is(err, apikeys.ErrNotFound):
+		return nil, v3.UserError{
+			ErrorCode: v3.ErrorCodeAPIKeyInvalid,
+		}
+	case err != nil:
+		return nil, err
+	}
+	if !k.VerifySecret(secret) {
+		log.Debugf("API key secret mismatch: %v", k.ID)
+		return nil, v3.UserError{
+			ErrorCode: v3.ErrorCodeAPIKeyInvalid,
+		}
+	}
+	if k.Expired(now.Unix()) {
+		return nil, v3.UserError{
+			ErrorCode:    v3.ErrorCodeAPIKeyInvalid,
+			ErrorContext: "api key expired",
+		}
+	}
+
+	// Update the last used timestamp
+	if now.Unix()-k.LastUsedAt >= int64(apiKeyLastUsedInterval.Seconds()) {
+		k.LastUsedAt = now.Unix()
+		err = p.apiKeys.Update(*k)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// apiKeyAllowed returns whether the provided API key has been granted a scope
+// that allows it to execute the plugin command. All API keys are allowed to
+// execute read-only commands. A nil API key is always allowed since the
+// request was authenticated using the session cookie.
+func apiKeyAllowed(k *apikeys.APIKey, cmd v3.Cmd, write bool) bool {
+	switch {
+	case k == nil:
+		return true
+	case k.HasScope(v3.APIKeyScopeAdmin):
+		return true
+	case !write:
+		return true
+	case cmd.PluginID == cmv1.PluginID:
+		return k.HasScope(v3.APIKeyScopeComment)
+	case cmd.PluginID == tkv1.PluginID && cmd.Cmd == tkv1.CmdAuthorize:
+		return k.HasScope(v3.APIKeyScopeVoteAuthorize)
+	}
+	return false
+}
+
+// reqSession contains the session data for a plugin command request. A
+// request is authenticated using either the session cookie or an API key.
+type reqSession struct {
+	cookie *sessions.Session // Nil for API key requests
+	apiKey *apikeys.APIKey   // Nil for session cookie requests
+	plugin *plugin.Session
+}
+
+// extractReqSession extracts the session data from the request. Requests that
+// contain an API key are authenticated using the API key and do not use the
+// session cookie.
+func (p *politeiawww) extractReqSession(r *http.Request) (*reqSession, error) {
+	key := r.Header.Get(v3.APIKeyHeader)
+	if key == "" {
+		s, err := p.extractSession(r)
+		if err != nil {
+			return nil, err
+		}
+		return &reqSession{
+			cookie: s,
+			plugin: convertSession(s),
+		}, nil
+	}
+
+	now := time.Now()
+	k, err := p.verifyAPIKey(key, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The API key is treated as a session that was created
+	// at the time of the request.
+	return &reqSession{
+		apiKey: k,
+		plugin: &plugin.Session{
+			UserID:    k.UserID,
+			CreatedAt: now.Unix(),
+		},
+	}, nil
+}
+
+// saveReqSession saves any updates that were made to the session of a
+// request. Nothing is saved for API key requests.
+func (p *politeiawww) saveReqSession(r *http.Request, w http.ResponseWriter, s *reqSession) error {
+	if s.cookie == nil {
+		return nil
+	}
+	return p.saveUserSession(r, w, s.cookie, s.plugin)
+}
+
+// apiKeyCSRFMiddleware skips the CSRF check for requests that contain an API
+// key. These requests do not use the session cookie, so they cannot be
+// forged by a third party site. It must be registered before the CSRF
+// middleware.
+func apiKeyCSRFMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(v3.APIKeyHeader) != "" {
+			r = csrf.UnsafeSkipCheck(r)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// convertAPIKeyToHTTP converts an API key to a http v3 APIKey.
+func convertAPIKeyToHTTP(k apikeys.APIKey) v3.APIKey {
+	return v3.APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Scopes:     k.Scopes,
+		CreatedAt:  k.CreatedAt,
+		Expiry:     k.Expiry,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
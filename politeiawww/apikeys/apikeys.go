@@ -0,0 +1,147 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package apikeys provides the API keys that automation clients use to
+// authenticate with the politeiawww plugin API in place of a session cookie.
+//
+// An API key has the format "<id>.<secret>". Only the SHA-256 digest of the
+// secret is saved to the database. The plaintext key is returned to the user
+// once, when the key is created.
+package apikeys
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/decred/politeia/util"
+)
+
+var (
+	// ErrNotFound is returned when an API key is not found in the database.
+	ErrNotFound = errors.New("api key not found")
+
+	// ErrInvalidKey is returned when an API key is not formatted correctly.
+	ErrInvalidKey = errors.New("invalid api key")
+)
+
+const (
+	// idSize is the size in bytes of the ID of an API key.
+	idSize = 8
+
+	// secretSize is the size in bytes of the secret of an API key.
+	secretSize = 32
+
+	// separator separates the ID and the secret of an API key.
+	separator = "."
+)
+
+// APIKey represents an API key that has been created by a user.
+type APIKey struct {
+	ID         string   // Unique ID, hex encoded
+	UserID     string   // User that the key belongs to
+	Name       string   // User chosen name
+	Digest     string   // SHA-256 digest of the secret, hex encoded
+	Scopes     []string // Scopes that have been granted to the key
+	CreatedAt  int64    // Unix timestamp
+	Expiry     int64    // Unix timestamp
+	LastUsedAt int64    // Unix timestamp, 0 if the key has not been used
+}
+
+// HasScope returns whether the API key has been granted the provided scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, v := range k.Scopes {
+		if v == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired returns whether the API key has expired at the provided unix time.
+func (k *APIKey) Expired(now int64) bool {
+	return now >= k.Expiry
+}
+
+// VerifySecret returns whether the provided secret matches the digest of the
+// API key.
+func (k *APIKey) VerifySecret(secret string) bool {
+	d := digest(secret)
+	return subtle.ConstantTimeCompare([]byte(d), []byte(k.Digest)) == 1
+}
+
+// New returns a new API key and its plaintext representation. The plaintext
+// key must be provided to the user. It cannot be recovered once the API key
+// has been saved.
+func New(userID, name string, scopes []string, createdAt, expiry int64) (*APIKey, string, error) {
+	id, err := util.Random(idSize)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := util.Random(secretSize)
+	if err != nil {
+		return nil, "", err
+	}
+	k := APIKey{
+		ID:        hex.EncodeToString(id),
+		UserID:    userID,
+		Name:      name,
+		Digest:    digest(hex.EncodeToString(secret)),
+		Scopes:    scopes,
+		CreatedAt: createdAt,
+		Expiry:    expiry,
+	}
+	return &k, k.ID + separator + hex.EncodeToString(secret), nil
+}
+
+// Parse parses a plaintext API key and returns the ID and the secret. An
+// ErrInvalidKey error is returned if the key is not formatted correctly.
+func Parse(key string) (string, string, error) {
+	s := strings.Split(strings.TrimSpace(key), separator)
+	if len(s) != 2 ||
+		len(s[0]) != hex.EncodedLen(idSize) ||
+		len(s[1]) != hex.EncodedLen(secretSize) {
+		return "", "", ErrInvalidKey
+	}
+	if _, err := hex.DecodeString(s[0]); err != nil {
+		return "", "", ErrInvalidKey
+	}
+	if _, err := hex.DecodeString(s[1]); err != nil {
+		return "", "", ErrInvalidKey
+	}
+	return s[0], s[1], nil
+}
+
+// digest returns the hex encoded SHA-256 digest of an API key secret.
+func digest(secret string) string {
+	d := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(d[:])
+}
+
+// DB represents the database for API keys.
+type DB interface {
+	// Insert inserts a new API key into the database.
+	Insert(APIKey) error
+
+	// Update updates an existing API key in the database.
+	//
+	// An ErrNotFound error is returned if the API key does not exist.
+	Update(APIKey) error
+
+	// Del deletes an API key from the database.
+	//
+	// An ErrNotFound error is returned if the API key does not exist.
+	Del(id string) error
+
+	// Get gets an API key from the database.
+	//
+	// An ErrNotFound error is returned if the API key does not exist.
+	Get(id string) (*APIKey, error)
+
+	// GetByUser returns all of the API keys for a user, ordered by the
+	// time that they were created.
+	GetByUser(userID string) ([]APIKey, error)
+}
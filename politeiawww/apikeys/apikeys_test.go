@@ -0,0 +1,60 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package apikeys
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewAndParse(t *testing.T) {
+	k, key, err := New("user-id", "bot", []string{"read"}, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, secret, err := Parse(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != k.ID {
+		t.Errorf("got id %v, want %v", id, k.ID)
+	}
+	if !k.VerifySecret(secret) {
+		t.Errorf("secret does not match digest")
+	}
+	if k.VerifySecret(strings.Repeat("0", len(secret))) {
+		t.Errorf("wrong secret matches digest")
+	}
+}
+
+func TestParse(t *testing.T) {
+	var (
+		id     = strings.Repeat("a", 16)
+		secret = strings.Repeat("b", 64)
+	)
+	var tests = []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{"valid", id + "." + secret, nil},
+		{"surrounding whitespace", " " + id + "." + secret + "\n", nil},
+		{"empty", "", ErrInvalidKey},
+		{"no separator", id + secret, ErrInvalidKey},
+		{"short id", id[1:] + "." + secret, ErrInvalidKey},
+		{"short secret", id + "." + secret[1:], ErrInvalidKey},
+		{"invalid hex", strings.Repeat("z", 16) + "." + secret, ErrInvalidKey},
+		{"extra part", id + "." + secret + ".", ErrInvalidKey},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := Parse(test.key)
+			if err != test.wantErr {
+				t.Errorf("got err %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
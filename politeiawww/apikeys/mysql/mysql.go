@@ -0,0 +1,294 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/apikeys"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultTableName is the default table name for the API keys table.
+	defaultTableName = "api_keys"
+
+	// defaultUsersTable is the default table name for the users table that
+	// the API keys table references.
+	defaultUsersTable = "users"
+
+	// defaultOpTimeout is the default timeout for a single database operation.
+	defaultOpTimeout = 1 * time.Minute
+
+	// scopesSeparator separates the scopes in the scopes column.
+	scopesSeparator = ","
+)
+
+// tableAPIKeys defines the API keys table.
+//
+// The digest column contains the hex encoded SHA-256 digest of the API key
+// secret. The scopes column contains a comma separated list of scopes.
+const tableAPIKeys = `
+  id           CHAR(16) NOT NULL PRIMARY KEY,
+  user_id      CHAR(36) NOT NULL,
+  name         VARCHAR(64) NOT NULL,
+  digest       CHAR(64) NOT NULL,
+  scopes       VARCHAR(255) NOT NULL,
+  created_at   BIGINT NOT NULL,
+  expiry       BIGINT NOT NULL,
+  last_used_at BIGINT NOT NULL,
+  INDEX (user_id),
+  FOREIGN KEY (user_id) REFERENCES %v(id)
+`
+
+// Opts includes configurable options for the API keys database.
+type Opts struct {
+	// TableName is the table name for the API keys table. Defaults to
+	// "api_keys".
+	TableName string
+
+	// UsersTable is the table name for the users table. Defaults to
+	// "users".
+	UsersTable string
+
+	// OpTimeout is the timeout for a single database operation. Defaults to
+	// 1 minute.
+	OpTimeout time.Duration
+}
+
+var (
+	_ apikeys.DB = (*mysql)(nil)
+)
+
+// mysql implements the apikeys.DB interface.
+type mysql struct {
+	// db is the mysql DB context.
+	db *sql.DB
+
+	// opts includes the API keys database options.
+	opts *Opts
+}
+
+// ctxForOp returns a context and cancel function for a single database
+// operation. It uses the database operation timeout set on the mysql
+// context.
+func (m *mysql) ctxForOp() (context.Context, func()) {
+	return context.WithTimeout(context.Background(), m.opts.OpTimeout)
+}
+
+// Insert inserts a new API key into the database.
+//
+// Insert satisfies the apikeys.DB interface.
+func (m *mysql) Insert(k apikeys.APIKey) error {
+	log.Tracef("Insert: %v %v", k.UserID, k.ID)
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	q := fmt.Sprintf(`INSERT INTO %v
+  (id, user_id, name, digest, scopes, created_at, expiry, last_used_at)
+  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, m.opts.TableName)
+	_, err := m.db.ExecContext(ctx, q, k.ID, k.UserID, k.Name, k.Digest,
+		strings.Join(k.Scopes, scopesSeparator), k.CreatedAt, k.Expiry,
+		k.LastUsedAt)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// Update updates an existing API key in the database. The ID, user ID, and
+// digest of an API key cannot be updated.
+//
+// An ErrNotFound error is returned if the API key does not exist.
+//
+// Update satisfies the apikeys.DB interface.
+func (m *mysql) Update(k apikeys.APIKey) error {
+	log.Tracef("Update: %v", k.ID)
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	q := fmt.Sprintf(`UPDATE %v
+  SET name = ?, scopes = ?, expiry = ?, last_used_at = ?
+  WHERE id = ?`, m.opts.TableName)
+	r, err := m.db.ExecContext(ctx, q, k.Name,
+		strings.Join(k.Scopes, scopesSeparator), k.Expiry, k.LastUsedAt,
+		k.ID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return rowsAffected(r)
+}
+
+// Del deletes an API key from the database.
+//
+// An ErrNotFound error is returned if the API key does not exist.
+//
+// Del satisfies the apikeys.DB interface.
+func (m *mysql) Del(id string) error {
+	log.Tracef("Del: %v", id)
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	r, err := m.db.ExecContext(ctx,
+		"DELETE FROM "+m.opts.TableName+" WHERE id = ?", id)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return rowsAffected(r)
+}
+
+// Get gets an API key from the database.
+//
+// An ErrNotFound error is returned if the API key does not exist.
+//
+// Get satisfies the apikeys.DB interface.
+func (m *mysql) Get(id string) (*apikeys.APIKey, error) {
+	log.Tracef("Get: %v", id)
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	q := fmt.Sprintf(`SELECT %v FROM %v WHERE id = ?`,
+		columns, m.opts.TableName)
+	k, err := scanAPIKey(m.db.QueryRowContext(ctx, q, id))
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, apikeys.ErrNotFound
+	case err != nil:
+		return nil, errors.WithStack(err)
+	}
+
+	return k, nil
+}
+
+// GetByUser returns all of the API keys for a user, ordered by the time that
+// they were created.
+//
+// GetByUser satisfies the apikeys.DB interface.
+func (m *mysql) GetByUser(userID string) ([]apikeys.APIKey, error) {
+	log.Tracef("GetByUser: %v", userID)
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	q := fmt.Sprintf(`SELECT %v FROM %v WHERE user_id = ?
+  ORDER BY created_at ASC`, columns, m.opts.TableName)
+	rows, err := m.db.QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	keys := make([]apikeys.APIKey, 0, 16)
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		keys = append(keys, *k)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return keys, nil
+}
+
+// columns contains the API keys table columns in the order that they are
+// scanned by scanAPIKey.
+const columns = "id, user_id, name, digest, scopes, created_at, expiry, " +
+	"last_used_at"
+
+// scanner is satisfied by both a sql Row and sql Rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAPIKey scans an API key from a row that contains the API key columns.
+func scanAPIKey(s scanner) (*apikeys.APIKey, error) {
+	var (
+		k      apikeys.APIKey
+		scopes string
+	)
+	err := s.Scan(&k.ID, &k.UserID, &k.Name, &k.Digest, &scopes,
+		&k.CreatedAt, &k.Expiry, &k.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	if scopes != "" {
+		k.Scopes = strings.Split(scopes, scopesSeparator)
+	}
+	return &k, nil
+}
+
+// rowsAffected returns an ErrNotFound error if the provided result did not
+// affect any rows.
+func rowsAffected(r sql.Result) error {
+	rows, err := r.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if rows == 0 {
+		return apikeys.ErrNotFound
+	}
+	return nil
+}
+
+// New returns a new mysql context that implements the apikeys DB interface.
+// The opts param can be used to override the default mysql context settings.
+//
+// The API keys table references the users table. The users table must be
+// created before this function is called.
+func New(db *sql.DB, opts *Opts) (*mysql, error) {
+	// Setup database options.
+	tableName := defaultTableName
+	usersTable := defaultUsersTable
+	opTimeout := defaultOpTimeout
+	// Override defaults if options are provided
+	if opts != nil {
+		if opts.TableName != "" {
+			tableName = opts.TableName
+		}
+		if opts.UsersTable != "" {
+			usersTable = opts.UsersTable
+		}
+		if opts.OpTimeout != 0 {
+			opTimeout = opts.OpTimeout
+		}
+	}
+
+	// Create mysql context
+	m := mysql{
+		db: db,
+		opts: &Opts{
+			TableName:  tableName,
+			UsersTable: usersTable,
+			OpTimeout:  opTimeout,
+		},
+	}
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	// Create the API keys table
+	q := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (%v)`,
+		m.opts.TableName, fmt.Sprintf(tableAPIKeys, m.opts.UsersTable))
+	_, err := db.ExecContext(ctx, q)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &m, nil
+}
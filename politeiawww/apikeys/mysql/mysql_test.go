@@ -0,0 +1,190 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/decred/politeia/politeiawww/apikeys"
+)
+
+func setupTestDB(t *testing.T) (*mysql, sqlmock.Sqlmock, func()) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error %s while creating stub db conn", err)
+	}
+
+	m := &mysql{
+		db: db,
+		opts: &Opts{
+			TableName:  defaultTableName,
+			UsersTable: defaultUsersTable,
+			OpTimeout:  defaultOpTimeout,
+		},
+	}
+
+	return m, mock, func() {
+		db.Close()
+	}
+}
+
+// testAPIKey returns an API key that can be used in the tests.
+func testAPIKey() apikeys.APIKey {
+	return apikeys.APIKey{
+		ID:         "0123456789abcdef",
+		UserID:     "c4a9a4a4-0a8e-4b7c-9a5e-5b3f3e0b8f2d",
+		Name:       "digest bot",
+		Digest:     "digest",
+		Scopes:     []string{"read", "comment"},
+		CreatedAt:  100,
+		Expiry:     200,
+		LastUsedAt: 150,
+	}
+}
+
+func TestInsert(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	k := testAPIKey()
+
+	// Query
+	sqlInsert := fmt.Sprintf(`INSERT INTO %v
+  (id, user_id, name, digest, scopes, created_at, expiry, last_used_at)
+  VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, mdb.opts.TableName)
+
+	// The scopes must be saved as a comma separated list
+	mock.ExpectExec(regexp.QuoteMeta(sqlInsert)).
+		WithArgs(k.ID, k.UserID, k.Name, k.Digest, "read,comment",
+			k.CreatedAt, k.Expiry, k.LastUsedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute method
+	err := mdb.Insert(k)
+	if err != nil {
+		t.Errorf("Insert unwanted error: %s", err)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateNotFound(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	k := testAPIKey()
+
+	// Query
+	sqlUpdate := fmt.Sprintf(`UPDATE %v
+  SET name = ?, scopes = ?, expiry = ?, last_used_at = ?
+  WHERE id = ?`, mdb.opts.TableName)
+
+	mock.ExpectExec(regexp.QuoteMeta(sqlUpdate)).
+		WithArgs(k.Name, "read,comment", k.Expiry, k.LastUsedAt, k.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute method
+	err := mdb.Update(k)
+	if !errors.Is(err, apikeys.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, apikeys.ErrNotFound)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	k := testAPIKey()
+
+	// Query
+	sqlSelect := fmt.Sprintf(`SELECT %v FROM %v WHERE id = ?`,
+		columns, mdb.opts.TableName)
+	cols := []string{"id", "user_id", "name", "digest", "scopes",
+		"created_at", "expiry", "last_used_at"}
+
+	// Should return apikeys.ErrNotFound when the key doesn't exist
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).
+		WithArgs(k.ID).
+		WillReturnRows(sqlmock.NewRows(cols))
+
+	r, err := mdb.Get(k.ID)
+	if !errors.Is(err, apikeys.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, apikeys.ErrNotFound)
+	}
+	if r != nil {
+		t.Errorf("not expecting a result but got one")
+	}
+
+	// Should return the API key with the scopes decoded
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).
+		WithArgs(k.ID).
+		WillReturnRows(sqlmock.NewRows(cols).
+			AddRow(k.ID, k.UserID, k.Name, k.Digest, "read,comment",
+				k.CreatedAt, k.Expiry, k.LastUsedAt))
+
+	r, err = mdb.Get(k.ID)
+	if err != nil {
+		t.Fatalf("Get unwanted error: %s", err)
+	}
+	if !reflect.DeepEqual(*r, k) {
+		t.Errorf("got %+v, want %+v", *r, k)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDel(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	id := testAPIKey().ID
+
+	// Query
+	sqlDelete := "DELETE FROM " + mdb.opts.TableName + " WHERE id = ?"
+
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(sqlDelete)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute method
+	err := mdb.Del(id)
+	if err != nil {
+		t.Errorf("Del unwanted error: %s", err)
+	}
+	err = mdb.Del(id)
+	if !errors.Is(err, apikeys.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, apikeys.ErrNotFound)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
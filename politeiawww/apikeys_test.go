@@ -0,0 +1,255 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	v3 "github.com/decred/politeia/politeiawww/api/http/v3"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/apikeys"
+)
+
+// testAPIKeysDB is an in memory apikeys.DB that is used for testing.
+type testAPIKeysDB struct {
+	keys map[string]apikeys.APIKey
+}
+
+var (
+	_ apikeys.DB = (*testAPIKeysDB)(nil)
+)
+
+func newTestAPIKeysDB() *testAPIKeysDB {
+	return &testAPIKeysDB{
+		keys: make(map[string]apikeys.APIKey),
+	}
+}
+
+func (d *testAPIKeysDB) Insert(k apikeys.APIKey) error {
+	d.keys[k.ID] = k
+	return nil
+}
+
+func (d *testAPIKeysDB) Update(k apikeys.APIKey) error {
+	if _, ok := d.keys[k.ID]; !ok {
+		return apikeys.ErrNotFound
+	}
+	d.keys[k.ID] = k
+	return nil
+}
+
+func (d *testAPIKeysDB) Del(id string) error {
+	if _, ok := d.keys[id]; !ok {
+		return apikeys.ErrNotFound
+	}
+	delete(d.keys, id)
+	return nil
+}
+
+func (d *testAPIKeysDB) Get(id string) (*apikeys.APIKey, error) {
+	k, ok := d.keys[id]
+	if !ok {
+		return nil, apikeys.ErrNotFound
+	}
+	return &k, nil
+}
+
+func (d *testAPIKeysDB) GetByUser(userID string) ([]apikeys.APIKey, error) {
+	keys := make([]apikeys.APIKey, 0, len(d.keys))
+	for _, k := range d.keys {
+		if k.UserID == userID {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt < keys[j].CreatedAt
+	})
+	return keys, nil
+}
+
+func TestAPIKeyAllowed(t *testing.T) {
+	var (
+		read = v3.Cmd{
+			PluginID: cmv1.PluginID,
+			Cmd:      cmv1.CmdComments,
+		}
+		comment = v3.Cmd{
+			PluginID: cmv1.PluginID,
+			Cmd:      cmv1.CmdNew,
+		}
+		authorize = v3.Cmd{
+			PluginID: tkv1.PluginID,
+			Cmd:      tkv1.CmdAuthorize,
+		}
+		start = v3.Cmd{
+			PluginID: tkv1.PluginID,
+			Cmd:      tkv1.CmdStart,
+		}
+	)
+	newKey := func(scopes ...string) *apikeys.APIKey {
+		return &apikeys.APIKey{Scopes: scopes}
+	}
+
+	var tests = []struct {
+		name  string
+		key   *apikeys.APIKey
+		cmd   v3.Cmd
+		write bool
+		want  bool
+	}{
+		{"session cookie", nil, start, true, true},
+		{"read scope read", newKey(v3.APIKeyScopeRead), read, false, true},
+		{"read scope write", newKey(v3.APIKeyScopeRead), comment, true, false},
+		{"comment scope comment", newKey(v3.APIKeyScopeComment),
+			comment, true, true},
+		{"comment scope authorize", newKey(v3.APIKeyScopeComment),
+			authorize, true, false},
+		{"comment scope read", newKey(v3.APIKeyScopeComment),
+			read, false, true},
+		{"voteauthorize scope authorize", newKey(v3.APIKeyScopeVoteAuthorize),
+			authorize, true, true},
+		{"voteauthorize scope start", newKey(v3.APIKeyScopeVoteAuthorize),
+			start, true, false},
+		{"admin scope start", newKey(v3.APIKeyScopeAdmin), start, true, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := apiKeyAllowed(test.key, test.cmd, test.write)
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAPIKeyNew(t *testing.T) {
+	p := &politeiawww{
+		apiKeys: newTestAPIKeysDB(),
+	}
+	var (
+		userID = "user-id"
+		now    = time.Now()
+		valid  = v3.APIKeyNew{
+			Name:   "digest bot",
+			Scopes: []string{v3.APIKeyScopeRead},
+		}
+	)
+
+	var tests = []struct {
+		name    string
+		akn     v3.APIKeyNew
+		wantErr bool
+	}{
+		{"success default expiry", valid, false},
+		{"success expiry", v3.APIKeyNew{
+			Name:   "bot",
+			Scopes: []string{v3.APIKeyScopeComment},
+			Expiry: now.Add(time.Hour).Unix(),
+		}, false},
+		{"no name", v3.APIKeyNew{
+			Scopes: []string{v3.APIKeyScopeRead},
+		}, true},
+		{"no scopes", v3.APIKeyNew{
+			Name: "bot",
+		}, true},
+		{"invalid scope", v3.APIKeyNew{
+			Name:   "bot",
+			Scopes: []string{"superuser"},
+		}, true},
+		{"expiry in the past", v3.APIKeyNew{
+			Name:   "bot",
+			Scopes: []string{v3.APIKeyScopeRead},
+			Expiry: now.Add(-time.Hour).Unix(),
+		}, true},
+		{"expiry too far out", v3.APIKeyNew{
+			Name:   "bot",
+			Scopes: []string{v3.APIKeyScopeRead},
+			Expiry: now.Add(apiKeyExpiryMax + time.Hour).Unix(),
+		}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reply, err := p.apiKeyNew(userID, test.akn, now)
+			var ue v3.UserError
+			switch {
+			case test.wantErr && !errors.As(err, &ue):
+				t.Fatalf("got err %v, want user error", err)
+			case test.wantErr:
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+
+			// The returned key must authenticate the user
+			k, err := p.verifyAPIKey(reply.Key, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k.UserID != userID || k.ID != reply.APIKey.ID {
+				t.Errorf("got key %v for user %v, want key %v for user %v",
+					k.ID, k.UserID, reply.APIKey.ID, userID)
+			}
+		})
+	}
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	p := &politeiawww{
+		apiKeys: newTestAPIKeysDB(),
+	}
+	now := time.Now()
+	reply, err := p.apiKeyNew("user-id", v3.APIKeyNew{
+		Name:   "bot",
+		Scopes: []string{v3.APIKeyScopeRead},
+		Expiry: now.Add(time.Hour).Unix(),
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _, err := apikeys.Parse(reply.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name    string
+		key     string
+		now     time.Time
+		wantErr bool
+	}{
+		{"valid", reply.Key, now, false},
+		{"malformed", "key", now, true},
+		{"wrong secret", id + "." + strings.Repeat("0", 64), now, true},
+		{"expired", reply.Key, now.Add(2 * time.Hour), true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := p.verifyAPIKey(test.key, test.now)
+			var ue v3.UserError
+			switch {
+			case test.wantErr && (!errors.As(err, &ue) ||
+				ue.ErrorCode != v3.ErrorCodeAPIKeyInvalid):
+				t.Errorf("got err %v, want %v", err,
+					v3.ErrorCodes[v3.ErrorCodeAPIKeyInvalid])
+			case !test.wantErr && err != nil:
+				t.Errorf("got err %v, want nil", err)
+			}
+		})
+	}
+
+	// The last used timestamp must have been updated
+	k, err := p.apiKeys.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.LastUsedAt != now.Unix() {
+		t.Errorf("got last used %v, want %v", k.LastUsedAt, now.Unix())
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package client
+
+import (
+	"fmt"
+
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+// ProposalArchive contains the full history of a proposal: every record
+// version along with its status changes, the comments and comment votes, the
+// vote authorizations, vote details and cast votes, the billing status
+// changes, and the timestamps of all of the above.
+//
+// This is the format of the [token]-archive.json file that is created by the
+// pictl proposalarchive command and verified by politeiaverify.
+type ProposalArchive struct {
+	Records                 []ProposalArchiveRecord    `json:"records"`
+	Comments                []cmv1.Comment             `json:"comments,omitempty"`
+	CommentVotes            []cmv1.CommentVote         `json:"commentvotes,omitempty"`
+	CommentTimestamps       *cmv1.TimestampsReply      `json:"commenttimestamps,omitempty"`
+	VoteAuths               []tkv1.AuthDetails         `json:"voteauths,omitempty"`
+	VoteDetails             *tkv1.VoteDetails          `json:"votedetails,omitempty"`
+	CastVotes               []tkv1.CastVoteDetails     `json:"castvotes,omitempty"`
+	VoteTimestamps          *tkv1.TimestampsReply      `json:"votetimestamps,omitempty"`
+	BillingStatusChanges    []piv1.BillingStatusChange `json:"billingstatuschanges,omitempty"`
+	BillingStatusTimestamps []piv1.Timestamp           `json:"billingstatustimestamps,omitempty"`
+	ServerPublicKey         string                     `json:"serverpublickey"`
+}
+
+// ProposalArchiveRecord contains a single version of the proposal record and
+// the timestamps of that version.
+type ProposalArchiveRecord struct {
+	Record     rcv1.Record           `json:"record"`
+	Timestamps *rcv1.TimestampsReply `json:"timestamps,omitempty"`
+}
+
+// ProposalArchive fetches the full history of a proposal from politeiawww and
+// returns it as a ProposalArchive. The server public key is included in the
+// archive so that it can be verified offline. The contents of the archive are
+// not verified.
+func (c *Client) ProposalArchive(token, serverPublicKey string) (*ProposalArchive, error) {
+	// Get the most recent version of the record. The token that was
+	// provided may be a short token. The full token is used for all
+	// subsequent requests.
+	latest, err := c.RecordDetails(rcv1.Details{
+		Token: token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	token = latest.CensorshipRecord.Token
+
+	// Get every version of the record and its timestamps
+	pa := ProposalArchive{
+		Records:         make([]ProposalArchiveRecord, 0, latest.Version),
+		ServerPublicKey: serverPublicKey,
+	}
+	for version := uint32(1); version <= latest.Version; version++ {
+		r, err := c.RecordDetails(rcv1.Details{
+			Token:   token,
+			Version: version,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("RecordDetails %v: %v", version, err)
+		}
+		tr, err := c.RecordTimestamps(rcv1.Timestamps{
+			Token:   token,
+			Version: version,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("RecordTimestamps %v: %v", version, err)
+		}
+		pa.Records = append(pa.Records, ProposalArchiveRecord{
+			Record:     *r,
+			Timestamps: tr,
+		})
+	}
+
+	// Get the comments data
+	err = c.proposalArchiveComments(token, &pa)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the ticket vote data
+	err = c.proposalArchiveVote(token, &pa)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the billing status changes and their timestamps
+	bscr, err := c.PiBillingStatusChanges(piv1.BillingStatusChanges{
+		Tokens: []string{token},
+	})
+	if err != nil {
+		return nil, err
+	}
+	pa.BillingStatusChanges = bscr.BillingStatusChanges[token]
+	if len(pa.BillingStatusChanges) > 0 {
+		bstr, err := c.PiBillingStatusTimestamps(
+			piv1.BillingStatusTimestamps{
+				Token: token,
+			})
+		if err != nil {
+			return nil, err
+		}
+		pa.BillingStatusTimestamps = bstr.Timestamps
+	}
+
+	return &pa, nil
+}
+
+// proposalArchiveComments adds the comments, comment votes, and comment
+// timestamps of a proposal to the archive.
+func (c *Client) proposalArchiveComments(token string, pa *ProposalArchive) error {
+	cr, err := c.Comments(cmv1.Comments{
+		Token: token,
+	})
+	if err != nil {
+		return err
+	}
+	if len(cr.Comments) == 0 {
+		return nil
+	}
+	pa.Comments = cr.Comments
+
+	pr, err := c.CommentPolicy()
+	if err != nil {
+		return err
+	}
+
+	// The comment votes route is paginated. Request the votes page by
+	// page until a partial page is returned.
+	for page := uint32(1); ; page++ {
+		vr, err := c.CommentVotes(cmv1.Votes{
+			Token: token,
+			Page:  page,
+		})
+		if err != nil {
+			return err
+		}
+		pa.CommentVotes = append(pa.CommentVotes, vr.Votes...)
+		if len(vr.Votes) < int(pr.VotesPageSize) {
+			break
+		}
+	}
+
+	// The comment timestamps route is paginated by comment ID
+	commentIDs := make([]uint32, 0, len(cr.Comments))
+	for _, v := range cr.Comments {
+		commentIDs = append(commentIDs, v.CommentID)
+	}
+	pa.CommentTimestamps = &cmv1.TimestampsReply{
+		Comments: make(map[uint32]cmv1.CommentTimestamp, len(commentIDs)),
+	}
+	for len(commentIDs) > 0 {
+		page := commentIDs
+		if len(page) > int(pr.TimestampsPageSize) {
+			page = page[:pr.TimestampsPageSize]
+		}
+		commentIDs = commentIDs[len(page):]
+
+		tr, err := c.CommentTimestamps(cmv1.Timestamps{
+			Token:      token,
+			CommentIDs: page,
+		})
+		if err != nil {
+			return err
+		}
+		for cid, v := range tr.Comments {
+			pa.CommentTimestamps.Comments[cid] = v
+		}
+	}
+
+	return nil
+}
+
+// proposalArchiveVote adds the vote authorizations, vote details, cast votes,
+// and vote timestamps of a proposal to the archive.
+func (c *Client) proposalArchiveVote(token string, pa *ProposalArchive) error {
+	dr, err := c.TicketVoteDetails(tkv1.Details{
+		Token: token,
+	})
+	if err != nil {
+		return err
+	}
+	if len(dr.Auths) == 0 && dr.Vote == nil {
+		return nil
+	}
+	pa.VoteAuths = dr.Auths
+	pa.VoteDetails = dr.Vote
+
+	rr, err := c.TicketVoteResults(tkv1.Results{
+		Token: token,
+	})
+	if err != nil {
+		return err
+	}
+	pa.CastVotes = rr.Votes
+
+	// The authorization and vote details timestamps are returned when
+	// no votes page is requested. The cast vote timestamps are then
+	// requested page by page.
+	tr, err := c.TicketVoteTimestamps(tkv1.Timestamps{
+		Token: token,
+	})
+	if err != nil {
+		return err
+	}
+	pa.VoteTimestamps = tr
+	if len(pa.CastVotes) == 0 {
+		return nil
+	}
+	pr, err := c.TicketVotePolicy()
+	if err != nil {
+		return err
+	}
+	for page := uint32(1); ; page++ {
+		tr, err := c.TicketVoteTimestamps(tkv1.Timestamps{
+			Token:     token,
+			VotesPage: page,
+		})
+		if err != nil {
+			return err
+		}
+		pa.VoteTimestamps.Votes = append(pa.VoteTimestamps.Votes,
+			tr.Votes...)
+		if len(tr.Votes) < int(pr.TimestampsPageSize) {
+			break
+		}
+	}
+
+	return nil
+}
@@ -78,17 +78,11 @@ func (c *Client) makeReq(method string, api, route string, v interface{}) ([]byt
 	switch {
 	case method == http.MethodGet && c.verbose:
 		fmt.Printf("Request: %v %v\n", method, fullRoute)
-	case method == http.MethodGet && c.rawJSON:
-		// No JSON to print
 	case c.verbose:
 		fmt.Printf("Request: %v %v\n", method, fullRoute)
 		if len(reqBody) > 0 {
 			fmt.Printf("%s\n", reqBody)
 		}
-	case c.rawJSON:
-		if len(reqBody) > 0 {
-			fmt.Printf("%s\n", reqBody)
-		}
 	}
 
 	// Send request
@@ -157,7 +151,7 @@ type Opts struct {
 	Cookies    []*http.Cookie
 	HeaderCSRF string
 	Verbose    bool // Print verbose output
-	RawJSON    bool // Print raw json
+	RawJSON    bool // Print raw json replies
 }
 
 // New returns a new politeiawww client.
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 
+	backend "github.com/decred/politeia/politeiad/backendv2"
 	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
 	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
 )
@@ -31,6 +32,23 @@ func (c *Client) PiPolicy() (*piv1.PolicyReply, error) {
 	return &pr, nil
 }
 
+// PiTemplates sends a pi v1 Templates request to politeiawww.
+func (c *Client) PiTemplates() (*piv1.TemplatesReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteTemplates, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var tr piv1.TemplatesReply
+	err = json.Unmarshal(resBody, &tr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tr, nil
+}
+
 // PiSetBillingStatus sends a pi v1 SetBillingStatus request
 // to politeiawww.
 func (c *Client) PiSetBillingStatus(sbs piv1.SetBillingStatus) (*piv1.SetBillingStatusReply, error) {
@@ -84,6 +102,29 @@ func (c *Client) PiBillingStatusChanges(bscs piv1.BillingStatusChanges) (*piv1.B
 	return &bscsr, nil
 }
 
+// PiBillingStatusTimestamps sends a pi v1 BillingStatusTimestamps request to
+// politeiawww.
+func (c *Client) PiBillingStatusTimestamps(bst piv1.BillingStatusTimestamps) (*piv1.BillingStatusTimestampsReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		piv1.APIRoute, piv1.RouteBillingStatusTimestamps, bst)
+	if err != nil {
+		return nil, err
+	}
+
+	var bstr piv1.BillingStatusTimestampsReply
+	err = json.Unmarshal(resBody, &bstr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bstr, nil
+}
+
+// PiTimestampVerify verifies that the provided pi v1 Timestamp is valid.
+func PiTimestampVerify(t piv1.Timestamp) error {
+	return backend.VerifyTimestamp(convertPiTimestamp(t))
+}
+
 // ProposalMetadataDecode decodes and returns the ProposalMetadata from the
 // Provided record files. An error returned if a ProposalMetadata is not found.
 func ProposalMetadataDecode(files []rcv1.File) (*piv1.ProposalMetadata, error) {
@@ -132,3 +173,27 @@ func VoteMetadataDecode(files []rcv1.File) (*piv1.VoteMetadata, error) {
 	}
 	return vmp, nil
 }
+
+func convertPiProof(p piv1.Proof) backend.Proof {
+	return backend.Proof{
+		Type:       p.Type,
+		Digest:     p.Digest,
+		MerkleRoot: p.MerkleRoot,
+		MerklePath: p.MerklePath,
+		ExtraData:  p.ExtraData,
+	}
+}
+
+func convertPiTimestamp(t piv1.Timestamp) backend.Timestamp {
+	proofs := make([]backend.Proof, 0, len(t.Proofs))
+	for _, v := range t.Proofs {
+		proofs = append(proofs, convertPiProof(v))
+	}
+	return backend.Timestamp{
+		Data:       t.Data,
+		Digest:     t.Digest,
+		TxID:       t.TxID,
+		MerkleRoot: t.MerkleRoot,
+		Proofs:     proofs,
+	}
+}
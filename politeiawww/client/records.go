@@ -196,6 +196,41 @@ func (c *Client) UserRecords(ur rcv1.UserRecords) (*rcv1.UserRecordsReply, error
 	return &urr, nil
 }
 
+// RecordTransferOwnership sends a records v1 TransferOwnership request to
+// politeiawww.
+func (c *Client) RecordTransferOwnership(to rcv1.TransferOwnership) (*rcv1.TransferOwnershipReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		rcv1.APIRoute, rcv1.RouteTransferOwnership, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var tor rcv1.TransferOwnershipReply
+	err = json.Unmarshal(resBody, &tor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tor, nil
+}
+
+// RecordUserActivity sends a records v1 UserActivity request to politeiawww.
+func (c *Client) RecordUserActivity(ua rcv1.UserActivity) (*rcv1.UserActivityReply, error) {
+	resBody, err := c.makeReq(http.MethodPost,
+		rcv1.APIRoute, rcv1.RouteUserActivity, ua)
+	if err != nil {
+		return nil, err
+	}
+
+	var uar rcv1.UserActivityReply
+	err = json.Unmarshal(resBody, &uar)
+	if err != nil {
+		return nil, err
+	}
+
+	return &uar, nil
+}
+
 // digestsVerify verifies that all file digests match the calculated SHA256
 // digests of the file payloads.
 func digestsVerify(files []rcv1.File) error {
@@ -359,9 +394,48 @@ func UserMetadataDecode(ms []v1.MetadataStream) (*rcv1.UserMetadata, error) {
 	return ump, nil
 }
 
+// RecordAuthors returns the user IDs of all of the current record authors,
+// i.e. the user that submitted the record version followed by the co-authors,
+// with the ownership transfers of the provided record version applied. A
+// transferred author is replaced by the new owner in place. An error is
+// returned if a UserMetadata is not found.
+func RecordAuthors(version uint32, ms []v1.MetadataStream) ([]string, error) {
+	um, err := UserMetadataDecode(ms)
+	if err != nil {
+		return nil, err
+	}
+	authors := make([]string, 0, len(um.CoAuthors)+1)
+	authors = append(authors, um.UserID)
+	for _, v := range um.CoAuthors {
+		authors = append(authors, v.UserID)
+	}
+
+	// Apply the ownership transfers of this record version. The
+	// transfers of prior versions are already reflected in the user
+	// metadata.
+	statuses, err := StatusChangesDecode(ms)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range statuses {
+		ot := v.OwnershipTransfer
+		if ot == nil || v.Version != version {
+			continue
+		}
+		for i, userID := range authors {
+			if userID == ot.FromUserID {
+				authors[i] = ot.ToUserID
+				break
+			}
+		}
+	}
+
+	return authors, nil
+}
+
 // UserMetadataVerify verifies that the UserMetadata contains a valid user ID,
 // a valid public key, and that this signature is a valid signature of the
-// record merkle root.
+// record merkle root. The same is verified for each of the co-authors.
 func UserMetadataVerify(um v1.UserMetadata, merkleRoot string) error {
 	// Verify user ID
 	_, err := uuid.Parse(um.UserID)
@@ -375,6 +449,19 @@ func UserMetadataVerify(um v1.UserMetadata, merkleRoot string) error {
 		return fmt.Errorf("invalid user metadata: %v", err)
 	}
 
+	// Verify co-authors
+	for _, v := range um.CoAuthors {
+		_, err := uuid.Parse(v.UserID)
+		if err != nil {
+			return fmt.Errorf("invalid co-author user id: %v", err)
+		}
+		err = util.VerifySignature(v.Signature, v.PublicKey, merkleRoot)
+		if err != nil {
+			return fmt.Errorf("invalid co-author %v signature: %v",
+				v.UserID, err)
+		}
+	}
+
 	return nil
 }
 
@@ -409,6 +496,13 @@ func StatusChangesDecode(metadata []v1.MetadataStream) ([]v1.StatusChange, error
 func StatusChangesVerify(sc []v1.StatusChange) error {
 	// Verify signatures
 	for _, v := range sc {
+		if v.OwnershipTransfer != nil {
+			err := ownershipTransferVerify(v)
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		var (
 			status  = strconv.FormatUint(uint64(v.Status), 10)
 			version = strconv.FormatUint(uint64(v.Version), 10)
@@ -424,6 +518,28 @@ func StatusChangesVerify(sc []v1.StatusChange) error {
 	return nil
 }
 
+// ownershipTransferVerify verifies the author signature and the admin
+// signature of an ownership transfer status change.
+func ownershipTransferVerify(sc v1.StatusChange) error {
+	var (
+		ot      = sc.OwnershipTransfer
+		version = strconv.FormatUint(uint64(sc.Version), 10)
+		msg     = sc.Token + version + ot.FromUserID + ot.ToUserID + sc.Reason
+	)
+	err := util.VerifySignature(ot.AuthorSignature, ot.AuthorPublicKey, msg)
+	if err != nil {
+		return fmt.Errorf("invalid ownership transfer author signature "+
+			"%v: %v", sc.Token, err)
+	}
+	err = util.VerifySignature(sc.Signature, sc.PublicKey,
+		msg+ot.AuthorSignature)
+	if err != nil {
+		return fmt.Errorf("invalid ownership transfer signature %v: %v",
+			sc.Token, err)
+	}
+	return nil
+}
+
 func convertRecordProof(p rcv1.Proof) backend.Proof {
 	return backend.Proof{
 		Type:       p.Type,
@@ -0,0 +1,50 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// cmdDraftDel deletes a local proposal draft.
+type cmdDraftDel struct {
+	Args struct {
+		Name string `positional-arg-name:"name" required:"true"`
+	} `positional-args:"true"`
+}
+
+// Execute executes the cmdDraftDel command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdDraftDel) Execute(args []string) error {
+	exists, err := draftExists(c.Args.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("draft '%v' not found", c.Args.Name)
+	}
+	dir, err := draftDir(c.Args.Name)
+	if err != nil {
+		return err
+	}
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return err
+	}
+	printf("Draft '%v' deleted\n", c.Args.Name)
+	return nil
+}
+
+// draftDelHelpMsg is printed to stdout by the help command.
+const draftDelHelpMsg = `draftdel "name"
+
+Delete a local proposal draft. This does not affect proposals that have been
+submitted from the draft.
+
+Arguments:
+1. name (string, required) Draft name.
+`
@@ -0,0 +1,157 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+)
+
+// cmdDraftEdit edits a local proposal draft.
+type cmdDraftEdit struct {
+	Args struct {
+		Name        string   `positional-arg-name:"name" required:"true"`
+		IndexFile   string   `positional-arg-name:"indexfile"`
+		Attachments []string `positional-arg-name:"attachments"`
+	} `positional-args:"true" optional:"true"`
+
+	// Remove contains the names of the attachments that should be
+	// removed from the draft.
+	Remove []string `long:"remove" optional:"true"`
+
+	// NoLink removes the vote metadata from the draft.
+	NoLink bool `long:"nolink" optional:"true"`
+
+	draftFlags
+}
+
+// Execute executes the cmdDraftEdit command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdDraftEdit) Execute(args []string) error {
+	name := c.Args.Name
+	exists, err := draftExists(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("draft '%v' not found", name)
+	}
+	dir, err := draftDir(name)
+	if err != nil {
+		return err
+	}
+
+	// Remove attachments
+	for _, v := range c.Remove {
+		switch v {
+		case piv1.FileNameIndexFile, piv1.FileNameProposalMetadata,
+			piv1.FileNameVoteMetadata:
+			return fmt.Errorf("%v cannot be removed", v)
+		}
+		if v != filepath.Base(v) || v == draftInfoFilename {
+			return fmt.Errorf("invalid file name '%v'", v)
+		}
+		err := os.Remove(filepath.Join(dir, v))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Replace the index file and add attachments
+	if c.Args.IndexFile != "" {
+		err := draftFileCopy(name, piv1.FileNameIndexFile, c.Args.IndexFile)
+		if err != nil {
+			return err
+		}
+	}
+	for _, fp := range c.Args.Attachments {
+		err := draftFileCopy(name, filepath.Base(fp), fp)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update the metadata
+	files, err := draftFiles(name)
+	if err != nil {
+		return err
+	}
+	pm, vm, err := draftMetadata(files)
+	if err != nil {
+		return err
+	}
+	if c.NoLink {
+		vm = &piv1.VoteMetadata{}
+	}
+	err = c.apply(pm, vm)
+	if err != nil {
+		return err
+	}
+	if vm.LinkBy != 0 {
+		// RFPs do not request funding
+		pm.Amount = 0
+		pm.StartDate = 0
+		pm.EndDate = 0
+	}
+	err = draftMetadataSave(name, *pm, *vm)
+	if err != nil {
+		return err
+	}
+
+	return draftPrint(name)
+}
+
+// draftEditHelpMsg is printed to stdout by the help command.
+const draftEditHelpMsg = `draftedit [flags] "name" "indexfile" "attachments"
+
+Edit a local proposal draft. The provided index file replaces the index file of
+the draft and the provided attachments are added to the draft. Attachments with
+the same file name as an existing attachment replace the existing attachment.
+
+The proposal metadata fields that are provided using the flags are updated.
+All other metadata fields remain unchanged. The funding fields are cleared
+when the draft is made an RFP.
+
+The draft files can also be edited directly in the draft directory.
+
+Arguments:
+1. name        (string, required) Draft name.
+2. indexfile   (string, optional) Index file.
+3. attachments (string, optional) Attachment files.
+
+Flags:
+ --remove       (string) Name of an attachment to remove. Can be used multiple
+                         times.
+
+ --nolink       (bool)   Remove the vote metadata, i.e. the linkby and linkto
+                         fields, from the draft.
+
+ --name         (string) Name of the proposal.
+
+ --amount       (int)    Funding amount in cents.
+
+ --startdate    (string) Start Date, Format: "01/02/2006"
+
+ --enddate      (string) End Date, Format: "01/02/2006"
+
+ --domain       (string) Proposal domain.
+
+ --linkto       (string) Token of an existing public proposal to link to.
+
+ --linkby       (string) Make the proposal an RFP by setting the linkby
+                         deadline. The provided string should be a duration
+                         that will be added onto the current time.
+
+ --rfp          (bool)   Make the proposal an RFP by setting the linkby to one
+                         month from the current time.
+
+Examples:
+$ pictl draftedit --amount=1500000 myprop index.md
+$ pictl draftedit --remove=chart.png myprop
+`
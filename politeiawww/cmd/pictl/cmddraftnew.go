@@ -0,0 +1,227 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// cmdDraftNew creates a new local proposal draft.
+type cmdDraftNew struct {
+	Args struct {
+		Name        string   `positional-arg-name:"name" required:"true"`
+		IndexFile   string   `positional-arg-name:"indexfile"`
+		Attachments []string `positional-arg-name:"attachments"`
+	} `positional-args:"true" optional:"true"`
+
+	// Token is the token of an existing proposal. The files of the
+	// most recent version of the proposal are used to create the
+	// draft and the draft is submitted as an edit of the proposal.
+	Token string `long:"token" optional:"true"`
+
+	draftFlags
+}
+
+// Execute executes the cmdDraftNew command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdDraftNew) Execute(args []string) error {
+	// Unpack args
+	name := c.Args.Name
+	indexFile := c.Args.IndexFile
+
+	// Verify args and flags
+	switch {
+	case indexFile == "" && c.Token == "":
+		return fmt.Errorf("index file not found; you must either " +
+			"provide an index.md file or use --token")
+	case indexFile != "" && c.Token != "":
+		return fmt.Errorf("you cannot provide an index file and use the " +
+			"--token flag at the same time")
+	}
+	exists, err := draftExists(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("draft '%v' already exists", name)
+	}
+
+	// Get the proposal files of an existing proposal
+	var files []rcv1.File
+	if c.Token != "" {
+		opts := pclient.Opts{
+			HTTPSCert: cfg.HTTPSCert,
+			Cookies:   cfg.Cookies,
+			Verbose:   cfg.Verbose,
+			RawJSON:   cfg.RawJSON,
+		}
+		pc, err := pclient.New(cfg.Host, opts)
+		if err != nil {
+			return err
+		}
+		r, err := pc.RecordDetails(rcv1.Details{
+			Token: c.Token,
+		})
+		if err != nil {
+			return err
+		}
+		files = r.Files
+	}
+
+	// Create the draft
+	dir, err := draftDir(name)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+	err = draftNew(name, c, files)
+	if err != nil {
+		// Cleanup the partially created draft
+		os.RemoveAll(dir)
+		return err
+	}
+
+	return draftPrint(name)
+}
+
+// draftNew writes the files of a new draft to the draft directory.
+func draftNew(name string, c *cmdDraftNew, files []rcv1.File) error {
+	// Write the proposal files
+	for _, v := range files {
+		b, err := base64.StdEncoding.DecodeString(v.Payload)
+		if err != nil {
+			return err
+		}
+		err = draftFileWrite(name, v.Name, b)
+		if err != nil {
+			return err
+		}
+	}
+	if c.Args.IndexFile != "" {
+		err := draftFileCopy(name, piv1.FileNameIndexFile, c.Args.IndexFile)
+		if err != nil {
+			return err
+		}
+	}
+	for _, fp := range c.Args.Attachments {
+		err := draftFileCopy(name, filepath.Base(fp), fp)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Setup the metadata
+	files, err := draftFiles(name)
+	if err != nil {
+		return err
+	}
+	pm, vm, err := draftMetadata(files)
+	if err != nil {
+		return err
+	}
+	err = c.apply(pm, vm)
+	if err != nil {
+		return err
+	}
+	err = draftMetadataSave(name, *pm, *vm)
+	if err != nil {
+		return err
+	}
+
+	return draftInfoSave(name, draftInfo{
+		Token: c.Token,
+	})
+}
+
+// draftPrint prints the files and the expected merkle root of a draft.
+func draftPrint(name string) error {
+	files, err := draftFiles(name)
+	if err != nil {
+		return err
+	}
+	di, err := draftInfoLoad(name)
+	if err != nil {
+		return err
+	}
+	merkle, err := draftMerkleRoot(files)
+	if err != nil {
+		return err
+	}
+	dir, err := draftDir(name)
+	if err != nil {
+		return err
+	}
+	printf("Draft : %v\n", name)
+	printf("Dir   : %v\n", dir)
+	if di.Token != "" {
+		printf("Token : %v\n", di.Token)
+	}
+	printf("Merkle: %v\n", merkle)
+	printf("Files\n")
+	return printProposalFiles(files)
+}
+
+// draftNewHelpMsg is printed to stdout by the help command.
+const draftNewHelpMsg = `draftnew [flags] "name" "indexfile" "attachments"
+
+Create a new local proposal draft. Drafts are stored in the drafts directory of
+the pictl home directory. Each draft is a directory that contains the proposal
+files, which can be edited using any editor. The draft can be validated against
+the proposal policy without a network connection using the draftvalidate
+command and is submitted using the draftsubmit command.
+
+The proposal metadata is set using the flags. The --rfp, --linkby and --linkto
+flags set the vote metadata.
+
+A draft of an existing proposal can be created using the --token flag. The
+files of the most recent version of the proposal are downloaded into the draft
+and the draft is submitted as an edit of the proposal.
+
+Arguments:
+1. name        (string, required) Draft name. May contain letters, numbers,
+                                  '-' and '_'.
+2. indexfile   (string, optional) Index file.
+3. attachments (string, optional) Attachment files.
+
+Flags:
+ --token        (string) Token of an existing proposal to create the draft
+                         from. The indexfile argument is not allowed when
+                         using this flag.
+
+ --name         (string) Name of the proposal.
+
+ --amount       (int)    Funding amount in cents.
+
+ --startdate    (string) Start Date, Format: "01/02/2006"
+
+ --enddate      (string) End Date, Format: "01/02/2006"
+
+ --domain       (string) Proposal domain.
+
+ --linkto       (string) Token of an existing public proposal to link to.
+
+ --linkby       (string) Make the proposal an RFP by setting the linkby
+                         deadline. The provided string should be a duration
+                         that will be added onto the current time.
+
+ --rfp          (bool)   Make the proposal an RFP by setting the linkby to one
+                         month from the current time.
+
+Examples:
+$ pictl draftnew --name="My proposal" --domain=development --amount=2000000 \
+    --startdate=01/02/2022 --enddate=06/02/2022 myprop index.md chart.png
+$ pictl draftnew --token=1f4e4ae2b3e3a5f8 myprop-v2
+`
@@ -0,0 +1,29 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+// cmdDraftPolicy retrieves and caches the proposal policy that drafts are
+// validated against.
+type cmdDraftPolicy struct{}
+
+// Execute executes the cmdDraftPolicy command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdDraftPolicy) Execute(args []string) error {
+	dp, err := draftPolicyFetch()
+	if err != nil {
+		return err
+	}
+	printJSON(dp)
+	return nil
+}
+
+// draftPolicyHelpMsg is printed to stdout by the help command.
+const draftPolicyHelpMsg = `draftpolicy
+
+Fetch the pi API policy, the proposal templates, and the records API policy
+and cache them to disk. Drafts are validated against the cached policy so that
+they can be validated without a network connection. The policy is cached per
+politeiawww host and is refreshed automatically by the draftsubmit command.`
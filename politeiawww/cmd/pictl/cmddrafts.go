@@ -0,0 +1,71 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	pclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// cmdDrafts lists the local proposal drafts.
+type cmdDrafts struct {
+	Args struct {
+		Name string `positional-arg-name:"name"`
+	} `positional-args:"true" optional:"true"`
+}
+
+// Execute executes the cmdDrafts command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdDrafts) Execute(args []string) error {
+	// Print the details of a single draft
+	if c.Args.Name != "" {
+		return draftPrint(c.Args.Name)
+	}
+
+	// Print all drafts
+	names, err := draftNames()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		printf("No drafts found\n")
+		return nil
+	}
+	for _, name := range names {
+		files, err := draftFiles(name)
+		if err != nil {
+			return err
+		}
+		di, err := draftInfoLoad(name)
+		if err != nil {
+			return err
+		}
+		var proposalName string
+		pm, err := pclient.ProposalMetadataDecode(files)
+		if err == nil {
+			proposalName = pm.Name
+		}
+		token := di.Token
+		if token == "" {
+			token = "unsubmitted"
+		}
+		printf("%-20v %-16v %v\n", name, token, proposalName)
+	}
+
+	return nil
+}
+
+// draftsHelpMsg is printed to stdout by the help command.
+const draftsHelpMsg = `drafts "name"
+
+List the local proposal drafts. The draft name, the token of the proposal that
+the draft was submitted as, and the proposal name are printed for each draft.
+
+The files and the expected merkle root of a draft are printed when a draft name
+is provided.
+
+Arguments:
+1. name (string, optional) Draft name.
+`
@@ -0,0 +1,182 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/politeiawww/cmd/shared"
+)
+
+// cmdDraftSubmit submits a local proposal draft. The draft is submitted as a
+// new proposal or, if the draft is linked to a proposal, as an edit of that
+// proposal.
+type cmdDraftSubmit struct {
+	Args struct {
+		Name string `positional-arg-name:"name" required:"true"`
+	} `positional-args:"true"`
+}
+
+// Execute executes the cmdDraftSubmit command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdDraftSubmit) Execute(args []string) error {
+	name := c.Args.Name
+
+	// Check for user identity. A user identity is required to sign
+	// the proposal files.
+	if cfg.Identity == nil {
+		return shared.ErrUserIdentityNotFound
+	}
+
+	// Validate the draft against the most recent policy
+	dp, err := draftPolicyFetch()
+	if err != nil {
+		return err
+	}
+	files, err := draftFiles(name)
+	if err != nil {
+		return err
+	}
+	problems := draftValidate(files, *dp, time.Now())
+	if len(problems) > 0 {
+		return fmt.Errorf("draft '%v' is invalid:\n  %v", name,
+			strings.Join(problems, "\n  "))
+	}
+	merkle, err := draftMerkleRoot(files)
+	if err != nil {
+		return err
+	}
+	di, err := draftInfoLoad(name)
+	if err != nil {
+		return err
+	}
+
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert:  cfg.HTTPSCert,
+		Cookies:    cfg.Cookies,
+		HeaderCSRF: cfg.CSRF,
+		Verbose:    cfg.Verbose,
+		RawJSON:    cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	// Submit the draft
+	sig, err := signedMerkleRoot(files, cfg.Identity)
+	if err != nil {
+		return err
+	}
+	var r rcv1.Record
+	if di.Token == "" {
+		nr, err := pc.RecordNew(rcv1.New{
+			Files:     files,
+			PublicKey: cfg.Identity.Public.String(),
+			Signature: sig,
+		})
+		if err != nil {
+			return err
+		}
+		r = nr.Record
+	} else {
+		er, err := pc.RecordEdit(rcv1.Edit{
+			Token:     di.Token,
+			Files:     files,
+			PublicKey: cfg.Identity.Public.String(),
+			Signature: sig,
+		})
+		if err != nil {
+			return err
+		}
+		r = er.Record
+	}
+
+	// Verify the record
+	vr, err := client.Version()
+	if err != nil {
+		return err
+	}
+	err = pclient.RecordVerify(r, vr.PubKey)
+	if err != nil {
+		return fmt.Errorf("unable to verify record: %v", err)
+	}
+	err = draftFilesVerify(files, r.Files)
+	if err != nil {
+		return err
+	}
+
+	// Link the draft to the proposal so that subsequent submissions
+	// are submitted as edits.
+	err = draftInfoSave(name, draftInfo{
+		Token: r.CensorshipRecord.Token,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Print the censorship record
+	printf("Token          : %v\n", r.CensorshipRecord.Token)
+	printf("Version        : %v\n", r.Version)
+	printf("Expected merkle: %v\n", merkle)
+	printf("Merkle         : %v\n", r.CensorshipRecord.Merkle)
+	printf("Receipt        : %v\n", r.CensorshipRecord.Signature)
+	if merkle != r.CensorshipRecord.Merkle {
+		printf("The server ordered the files differently than the draft. " +
+			"The file digests match the draft.\n")
+	}
+
+	return nil
+}
+
+// draftFilesVerify verifies that the files of a submitted record are the same
+// files as the draft files. The server does not necessarily preserve the order
+// of the files so the files are compared by name.
+func draftFilesVerify(draft, record []rcv1.File) error {
+	if len(draft) != len(record) {
+		return fmt.Errorf("record contains %v files, draft contains %v",
+			len(record), len(draft))
+	}
+	digests := make(map[string]string, len(record)) // [name]digest
+	for _, v := range record {
+		digests[v.Name] = v.Digest
+	}
+	for _, v := range draft {
+		d, ok := digests[v.Name]
+		if !ok {
+			return fmt.Errorf("file %v not found in record", v.Name)
+		}
+		if d != v.Digest {
+			return fmt.Errorf("file %v digest mismatch: got %v, want %v",
+				v.Name, d, v.Digest)
+		}
+	}
+	return nil
+}
+
+// draftSubmitHelpMsg is printed to stdout by the help command.
+const draftSubmitHelpMsg = `draftsubmit "name"
+
+Submit a local proposal draft. The proposal policy is refreshed and the draft
+is validated before it is submitted. The draft is submitted as a new proposal
+unless it was created from an existing proposal or has been submitted before,
+in which case it is submitted as an edit of that proposal.
+
+The returned record is verified against the server public key and the record
+files are verified against the draft files. The expected merkle root and the
+merkle root of the censorship record are printed.
+
+Arguments:
+1. name (string, required) Draft name.
+
+Example:
+$ pictl draftsubmit myprop
+`
@@ -0,0 +1,67 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// cmdDraftValidate validates a local proposal draft against the cached
+// proposal policy.
+type cmdDraftValidate struct {
+	Args struct {
+		Name string `positional-arg-name:"name" required:"true"`
+	} `positional-args:"true"`
+}
+
+// Execute executes the cmdDraftValidate command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdDraftValidate) Execute(args []string) error {
+	dp, err := draftPolicyLoad()
+	if err != nil {
+		return err
+	}
+	if dp == nil {
+		return fmt.Errorf("proposal policy not found; run the draftpolicy " +
+			"command to cache the policy")
+	}
+	err = draftPrint(c.Args.Name)
+	if err != nil {
+		return err
+	}
+	printf("Policy: %v\n", dateAndTimeFromUnix(dp.Timestamp))
+
+	files, err := draftFiles(c.Args.Name)
+	if err != nil {
+		return err
+	}
+	problems := draftValidate(files, *dp, time.Now())
+	if len(problems) > 0 {
+		printf("Problems\n")
+		for _, v := range problems {
+			printf("  %v\n", v)
+		}
+		return fmt.Errorf("draft '%v' is invalid", c.Args.Name)
+	}
+	printf("Draft is valid\n")
+
+	return nil
+}
+
+// draftValidateHelpMsg is printed to stdout by the help command.
+const draftValidateHelpMsg = `draftvalidate "name"
+
+Validate a local proposal draft against the cached proposal policy. This
+command does not require a network connection. The policy is cached using the
+draftpolicy command.
+
+The draft files, the merkle root that the censorship record of the proposal is
+expected to contain, and all policy violations are printed.
+
+Arguments:
+1. name (string, required) Draft name.
+`
@@ -22,6 +22,10 @@ type cmdHelp struct {
 // This function satisfies the go-flags Commander interface.
 func (c *cmdHelp) Execute(args []string) error {
 	switch c.Args.Command {
+	// Help commands
+	case "run":
+		fmt.Printf("%s\n", runHelpMsg)
+
 	// Basic commands
 	case "version":
 		fmt.Printf("%s\n", shared.VersionHelpMsg)
@@ -67,10 +71,14 @@ func (c *cmdHelp) Execute(args []string) error {
 		fmt.Printf("%s\n", shared.UserPasswordResetHelpMsg)
 	case "users":
 		fmt.Printf("%s\n", shared.UsersHelpMsg)
+	case "mailqueue":
+		fmt.Printf("%s\n", mailQueueHelpMsg)
 
 		// Proposal commands
 	case "proposalpolicy":
 		fmt.Printf("%s\n", proposalPolicyHelpMsg)
+	case "proposaltemplates":
+		fmt.Printf("%s\n", proposalTemplatesHelpMsg)
 	case "proposalnew":
 		fmt.Printf("%s\n", proposalNewHelpMsg)
 	case "proposaledit":
@@ -81,10 +89,14 @@ func (c *cmdHelp) Execute(args []string) error {
 		fmt.Printf("%s\n", proposalSetBillingStatusHelpMsg)
 	case "proposalbillingstatuschanges":
 		fmt.Printf("%s\n", proposalBillingStatusChangesHelpMsg)
+	case "proposalbillingtimestamps":
+		fmt.Printf("%s\n", proposalBillingTimestampsHelpMsg)
 	case "proposaldetails":
 		fmt.Printf("%s\n", proposalDetailsHelpMsg)
 	case "proposaltimestamps":
 		fmt.Printf("%s\n", proposalTimestampsHelpMsg)
+	case "proposalarchive":
+		fmt.Printf("%s\n", proposalArchiveHelpMsg)
 	case "proposals":
 		fmt.Printf("%s\n", proposalsHelpMsg)
 	case "proposalsummaries":
@@ -96,9 +108,27 @@ func (c *cmdHelp) Execute(args []string) error {
 	case "userproposals":
 		fmt.Printf("%s\n", userProposalsHelpMsg)
 
+		// Draft commands
+	case "draftpolicy":
+		fmt.Printf("%s\n", draftPolicyHelpMsg)
+	case "draftnew":
+		fmt.Printf("%s\n", draftNewHelpMsg)
+	case "draftedit":
+		fmt.Printf("%s\n", draftEditHelpMsg)
+	case "draftvalidate":
+		fmt.Printf("%s\n", draftValidateHelpMsg)
+	case "draftsubmit":
+		fmt.Printf("%s\n", draftSubmitHelpMsg)
+	case "draftdel":
+		fmt.Printf("%s\n", draftDelHelpMsg)
+	case "drafts":
+		fmt.Printf("%s\n", draftsHelpMsg)
+
 		// Record commands
 	case "recordpolicy":
 		fmt.Printf("%s\n", recordPolicyHelpMsg)
+	case "useractivity":
+		fmt.Printf("%s\n", userActivityHelpMsg)
 
 		// Comment commands
 	case "commentpolicy":
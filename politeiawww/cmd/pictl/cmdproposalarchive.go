@@ -0,0 +1,98 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+
+	pclient "github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/util"
+)
+
+// cmdProposalArchive creates a proposal archive bundle that contains the full
+// history of a proposal. The bundle can be verified using politeiaverify.
+type cmdProposalArchive struct {
+	Args struct {
+		Token string `positional-arg-name:"token" required:"true"`
+	} `positional-args:"true"`
+
+	// Dir is the directory that the archive bundle is saved to. The
+	// current working directory is used if this flag is not provided.
+	Dir string `long:"dir" optional:"true"`
+}
+
+// Execute executes the cmdProposalArchive command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdProposalArchive) Execute(args []string) error {
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert:  cfg.HTTPSCert,
+		Cookies:    cfg.Cookies,
+		HeaderCSRF: cfg.CSRF,
+		Verbose:    cfg.Verbose,
+		RawJSON:    cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	// Get the server public key
+	vr, err := client.Version()
+	if err != nil {
+		return err
+	}
+
+	// Create the archive bundle
+	pa, err := pc.ProposalArchive(c.Args.Token, vr.PubKey)
+	if err != nil {
+		return err
+	}
+
+	// Save the archive bundle to disk
+	b, err := json.MarshalIndent(pa, "", "  ")
+	if err != nil {
+		return err
+	}
+	token := pa.Records[len(pa.Records)-1].Record.CensorshipRecord.Token
+	fp := filepath.Join(util.CleanAndExpandPath(c.Dir),
+		token+"-archive.json")
+	err = ioutil.WriteFile(fp, b, 0600)
+	if err != nil {
+		return err
+	}
+
+	printf("Record versions        : %v\n", len(pa.Records))
+	printf("Comments               : %v\n", len(pa.Comments))
+	printf("Comment votes          : %v\n", len(pa.CommentVotes))
+	printf("Vote authorizations    : %v\n", len(pa.VoteAuths))
+	printf("Cast votes             : %v\n", len(pa.CastVotes))
+	printf("Billing status changes : %v\n", len(pa.BillingStatusChanges))
+	printf("Archive saved to %v\n", fp)
+
+	return nil
+}
+
+// proposalArchiveHelpMsg is printed to stdout by the help command.
+const proposalArchiveHelpMsg = `proposalarchive [flags] "token"
+
+Create a proposal archive bundle. The bundle contains the full history of the
+proposal: every record version along with its status changes, the comments and
+comment votes, the vote authorizations, vote details and cast votes, the
+billing status changes, and the timestamps of all of the above.
+
+The bundle is saved to [token]-archive.json and can be verified offline using
+politeiaverify.
+
+Arguments:
+1. token  (string, required) Proposal censorship token
+
+Flags:
+ --dir    (string, optional) Directory to save the bundle to. Defaults to the
+                             current working directory.
+`
@@ -0,0 +1,77 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// cmdProposalBillingTimestamps retrieves the timestamps of the billing
+// status changes of a proposal.
+type cmdProposalBillingTimestamps struct {
+	Args struct {
+		Token string `positional-arg-name:"token" required:"true"`
+	} `positional-args:"true"`
+}
+
+// Execute executes the cmdProposalBillingTimestamps command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdProposalBillingTimestamps) Execute(args []string) error {
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert:  cfg.HTTPSCert,
+		Cookies:    cfg.Cookies,
+		HeaderCSRF: cfg.CSRF,
+		Verbose:    cfg.Verbose,
+		RawJSON:    cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	// Get timestamps
+	bst := piv1.BillingStatusTimestamps{
+		Token: c.Args.Token,
+	}
+	bstr, err := pc.PiBillingStatusTimestamps(bst)
+	if err != nil {
+		return err
+	}
+
+	// Verify timestamps
+	var notTimestamped int
+	for _, v := range bstr.Timestamps {
+		err := pclient.PiTimestampVerify(v)
+		switch err {
+		case nil:
+			// Timestamp is valid; continue
+		case backend.ErrNotTimestamped:
+			notTimestamped++
+		default:
+			return err
+		}
+	}
+
+	printf("Total number of billing status changes: %v, timestamped: %v, "+
+		"not timestamped: %v\n", len(bstr.Timestamps),
+		len(bstr.Timestamps)-notTimestamped, notTimestamped)
+
+	return nil
+}
+
+// proposalBillingTimestampsHelpMsg is printed to stdout by the help command.
+const proposalBillingTimestampsHelpMsg = `proposalbillingtimestamps "token"
+
+Fetch the timestamps of the billing status changes of a proposal. The
+timestamps contain all necessary data to verify that the billing status
+changes have been timestamped onto the decred blockchain.
+
+Arguments:
+1. token  (string, required) Proposal censorship token
+`
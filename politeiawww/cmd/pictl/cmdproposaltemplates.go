@@ -0,0 +1,45 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	pclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// cmdProposalTemplates retrieves the proposal templates.
+type cmdProposalTemplates struct{}
+
+// Execute executes the cmdProposalTemplates command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdProposalTemplates) Execute(args []string) error {
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert: cfg.HTTPSCert,
+		Verbose:   cfg.Verbose,
+		RawJSON:   cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	// Get templates
+	tr, err := pc.PiTemplates()
+	if err != nil {
+		return err
+	}
+
+	// Print templates
+	printJSON(tr)
+
+	return nil
+}
+
+// proposalTemplatesHelpMsg is the printed to stdout by the help command.
+const proposalTemplatesHelpMsg = `proposaltemplates
+
+Fetch the proposal templates. The templates describe the markdown sections
+that the index file of a proposal is required to contain.`
@@ -0,0 +1,314 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/decred/politeia/util"
+)
+
+// cmdRun executes a script of pictl commands.
+type cmdRun struct {
+	Args struct {
+		Script string   `positional-arg-name:"script" required:"true"`
+		Vars   []string `positional-arg-name:"vars"`
+	} `positional-args:"true"`
+}
+
+// Execute executes the cmdRun command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdRun) Execute(args []string) error {
+	// Setup the script variables
+	vars := make(map[string]string, len(c.Args.Vars))
+	for _, v := range c.Args.Vars {
+		s := strings.SplitN(v, "=", 2)
+		if len(s) != 2 || s[0] == "" {
+			return fmt.Errorf("invalid variable '%v'; must be name=value", v)
+		}
+		vars[s[0]] = s[1]
+	}
+
+	// Read the script
+	f, err := os.Open(util.CleanAndExpandPath(c.Args.Script))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	err = scanner.Err()
+	if err != nil {
+		return err
+	}
+
+	// Execute the script
+	pictl, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	var replies []interface{}
+	for i, line := range lines {
+		lineNum := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			// Skip empty lines and comments
+			continue
+		}
+		fields, err := splitArgs(line)
+		if err != nil {
+			return fmt.Errorf("line %v: %v", lineNum, err)
+		}
+		fields, err = expandVars(fields, vars)
+		if err != nil {
+			return fmt.Errorf("line %v: %v", lineNum, err)
+		}
+
+		// Capture a variable from the replies of the previous command
+		if fields[0] == "set" {
+			if len(fields) != 3 {
+				return fmt.Errorf("line %v: usage: set <variable> <path>",
+					lineNum)
+			}
+			value, err := replyLookup(replies, fields[2])
+			if err != nil {
+				return fmt.Errorf("line %v: %v", lineNum, err)
+			}
+			vars[fields[1]] = value
+			printf("%v = %v\n", fields[1], value)
+			continue
+		}
+
+		// Execute the command
+		printf("> %v\n", strings.Join(fields, " "))
+		stdout, err := runCmd(pictl, fields)
+		if !cfg.Silent {
+			os.Stdout.Write(stdout)
+		}
+		if err != nil {
+			return fmt.Errorf("line %v: %v: %v", lineNum, fields[0], err)
+		}
+		replies = parseReplies(stdout)
+	}
+
+	return nil
+}
+
+// runCmd executes a pictl command in a new process using the global options
+// of this process and returns its stdout. The command is executed with the
+// --json flag so that the API replies can be parsed.
+func runCmd(pictl string, args []string) ([]byte, error) {
+	opts := []string{
+		"--json",
+		"--appdata=" + cfg.HomeDir,
+		"--host=" + cfg.Host,
+	}
+	if cfg.HTTPSCert != "" {
+		opts = append(opts, "--httpscert="+cfg.HTTPSCert)
+	}
+	if cfg.SkipVerify {
+		opts = append(opts, "--skipverify")
+	}
+	if cfg.ClientCert != "" {
+		opts = append(opts, "--clientcert="+cfg.ClientCert)
+	}
+	if cfg.ClientKey != "" {
+		opts = append(opts, "--clientkey="+cfg.ClientKey)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(pictl, append(opts, args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	return stdout.Bytes(), err
+}
+
+// parseReplies returns the JSON replies that were printed by a pictl command
+// that was executed with the --json flag. Each reply is printed on its own
+// line. Lines that are not JSON are ignored.
+func parseReplies(stdout []byte) []interface{} {
+	var replies []interface{}
+	for _, line := range bytes.Split(stdout, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var v interface{}
+		err := json.Unmarshal(line, &v)
+		if err != nil {
+			continue
+		}
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			replies = append(replies, v)
+		}
+	}
+	return replies
+}
+
+// replyLookup returns the value at the provided path of the most recent reply
+// that contains the path. The path is a dot separated list of object keys and
+// array indexes, e.g. "record.censorshiprecord.token" or "votes.0.ticket".
+// Strings are returned as is. All other values are returned JSON encoded.
+func replyLookup(replies []interface{}, path string) (string, error) {
+	for i := len(replies) - 1; i >= 0; i-- {
+		v, ok := jsonLookup(replies[i], path)
+		if !ok {
+			continue
+		}
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+	return "", fmt.Errorf("path '%v' not found in the replies of the "+
+		"previous command", path)
+}
+
+// jsonLookup returns the value at the provided path of a decoded JSON value.
+func jsonLookup(v interface{}, path string) (interface{}, bool) {
+	if path == "" || path == "." {
+		return v, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			v, ok = t[key]
+			if !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, false
+			}
+			v = t[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// expandVars replaces the $name and ${name} variable references in the
+// provided arguments with the variable values. An error is returned if a
+// referenced variable has not been set.
+func expandVars(args []string, vars map[string]string) ([]string, error) {
+	var missing []string
+	expanded := make([]string, 0, len(args))
+	for _, v := range args {
+		expanded = append(expanded, os.Expand(v, func(name string) string {
+			value, ok := vars[name]
+			if !ok {
+				missing = append(missing, name)
+			}
+			return value
+		}))
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("variables not set: %v", missing)
+	}
+	return expanded, nil
+}
+
+// splitArgs splits a script line into arguments. Arguments are separated by
+// whitespace. Single and double quotes can be used to include whitespace in
+// an argument.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		arg     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			arg.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// runHelpMsg is printed to stdout by the help command.
+const runHelpMsg = `run "script" [name=value]...
+
+Execute a script of pictl commands. Each line of the script contains a single
+pictl command without the 'pictl' prefix. Lines starting with '#' are comments.
+Arguments containing whitespace must be quoted.
+
+Every command is executed with the global options of the run command and the
+--json flag. The 'set' statement captures a value from the JSON replies of the
+previous command into a variable. The path is a dot separated list of object
+keys and array indexes. The most recent reply that contains the path is used.
+Variables are referenced using $name or ${name}. Variables can also be set when
+invoking the script.
+
+  set <variable> <path>
+
+The script stops at the first command that fails.
+
+Arguments:
+1. script   (string, required)  Script file path
+2. vars     (string, optional)  Variables to set, formatted as name=value
+
+Example script:
+  # Submit a proposal and authorize its vote
+  proposalnew --random
+  set token record.censorshiprecord.token
+  proposalsetstatus --unvetted $token public
+  voteauthorize $token
+
+Example:
+$ pictl run playbook.txt
+$ pictl run playbook.txt token=1f4e4ae2b3e3a5f8
+`
@@ -0,0 +1,99 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	var tests = []struct {
+		line string
+		args []string
+		err  bool
+	}{
+		{"proposalnew --random", []string{"proposalnew", "--random"}, false},
+		{"  commentnew  abc\t'a comment' ", []string{"commentnew", "abc",
+			"a comment"}, false},
+		{`proposalsetstatus abc censored "spam \"link\""`,
+			[]string{"proposalsetstatus", "abc", "censored", `spam "link"`},
+			false},
+		{`commentnew abc ''`, []string{"commentnew", "abc", ""}, false},
+		{`commentnew abc "unterminated`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			args, err := splitArgs(tt.line)
+			switch {
+			case tt.err && err == nil:
+				t.Fatal("expected error")
+			case !tt.err && err != nil:
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(args, tt.args) {
+				t.Fatalf("got %q, want %q", args, tt.args)
+			}
+		})
+	}
+}
+
+func TestExpandVars(t *testing.T) {
+	vars := map[string]string{
+		"token": "abc",
+	}
+	args, err := expandVars([]string{"voteauthorize", "$token",
+		"${token}-v1"}, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"voteauthorize", "abc", "abc-v1"}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("got %q, want %q", args, want)
+	}
+
+	_, err = expandVars([]string{"voteauthorize", "$missing"}, vars)
+	if err == nil {
+		t.Fatal("expected missing variable error")
+	}
+}
+
+func TestReplyLookup(t *testing.T) {
+	replies := parseReplies([]byte(`{"version":1,"pubkey":"key"}
+not a reply
+{"record":{"version":1,"censorshiprecord":{"token":"abc"}},"votes":[{"ticket":"t1"}]}
+`))
+	if len(replies) != 2 {
+		t.Fatalf("got %v replies, want 2", len(replies))
+	}
+
+	var tests = []struct {
+		path  string
+		value string
+		err   bool
+	}{
+		{"record.censorshiprecord.token", "abc", false},
+		{"votes.0.ticket", "t1", false},
+		{"pubkey", "key", false},
+		{"version", "1", false}, // Found in an earlier reply
+		{"record.censorshiprecord", `{"token":"abc"}`, false},
+		{"votes.1.ticket", "", true},
+		{"record.missing", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, err := replyLookup(replies, tt.path)
+			switch {
+			case tt.err && err == nil:
+				t.Fatal("expected error")
+			case !tt.err && err != nil:
+				t.Fatal(err)
+			}
+			if value != tt.value {
+				t.Fatalf("got %v, want %v", value, tt.value)
+			}
+		})
+	}
+}
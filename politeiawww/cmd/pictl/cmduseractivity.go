@@ -0,0 +1,86 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+)
+
+// cmdUserActivity retrieves a page of the activity timeline of a user.
+type cmdUserActivity struct {
+	Args struct {
+		UserID string `positional-arg-name:"userID" optional:"true"`
+	} `positional-args:"true"`
+
+	// Page is the page number to request.
+	Page uint32 `long:"page" optional:"true"`
+}
+
+// Execute executes the cmdUserActivity command.
+//
+// This function satisfies the go-flags Commander interface.
+func (c *cmdUserActivity) Execute(args []string) error {
+	// Setup client
+	opts := pclient.Opts{
+		HTTPSCert:  cfg.HTTPSCert,
+		Cookies:    cfg.Cookies,
+		HeaderCSRF: cfg.CSRF,
+		Verbose:    cfg.Verbose,
+		RawJSON:    cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return err
+	}
+
+	// Setup user ID
+	userID := c.Args.UserID
+	if userID == "" {
+		// No user ID provided. Use the user ID of the logged in user.
+		lr, err := client.Me()
+		if err != nil {
+			if err.Error() == "401" {
+				return fmt.Errorf("no user ID provided and no logged in user found")
+			}
+			return err
+		}
+		userID = lr.UserID
+	}
+
+	// Get user activity
+	ua := rcv1.UserActivity{
+		UserID: userID,
+		Page:   c.Page,
+	}
+	uar, err := pc.RecordUserActivity(ua)
+	if err != nil {
+		return err
+	}
+
+	// Print user activity to stdout
+	printJSON(uar)
+
+	return nil
+}
+
+// userActivityHelpMsg is printed to stdout by the help command.
+const userActivityHelpMsg = `useractivity "userID"
+
+Retrieve a page of the activity timeline of a user. The activity timeline
+contains the record, comment, and comment vote activity of the user, sorted
+from newest to oldest. If no user ID is given, the ID of the logged in user
+will be used.
+
+Activity that occurred on unvetted records is only returned to admins and the
+user.
+
+Arguments:
+1. userID (string, optional) User ID.
+
+Flags:
+ --page (uint32, optional) Page number to request. Defaults to the first page.`
@@ -0,0 +1,577 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/mime"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// draftsDirname is the name of the directory in the pictl home
+	// directory that contains the proposal drafts.
+	draftsDirname = "drafts"
+
+	// draftInfoFilename is the name of the file in a draft directory
+	// that contains the pictl specific draft information. It is not
+	// part of the proposal.
+	draftInfoFilename = "draft.json"
+
+	// draftPolicyFilename is the name of the host specific file that
+	// caches the proposal policy that drafts are validated against.
+	draftPolicyFilename = "draftpolicy.json"
+
+	// Accepted MIME types
+	mimeTypeText     = "text/plain"
+	mimeTypeTextUTF8 = "text/plain; charset=utf-8"
+	mimeTypePNG      = "image/png"
+)
+
+var (
+	// regexpDraftName matches valid draft names. A draft name is used
+	// as the name of the draft directory.
+	regexpDraftName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+)
+
+// draftInfo contains the pictl specific information of a proposal draft.
+type draftInfo struct {
+	// Token is the censorship token of the proposal that the draft was
+	// submitted as. The draft is submitted as an edit of this proposal
+	// when it is set.
+	Token string `json:"token,omitempty"`
+}
+
+// draftPolicy contains the proposal policy of a politeiawww host. It is
+// cached so that drafts can be validated without a network connection.
+type draftPolicy struct {
+	Policy    piv1.PolicyReply    `json:"policy"`
+	Templates piv1.TemplatesReply `json:"templates"`
+	Records   rcv1.PolicyReply    `json:"records"`
+	Timestamp int64               `json:"timestamp"` // Unix time of retrieval
+}
+
+// draftFlags contains the proposal metadata flags that are used to create
+// and edit drafts.
+type draftFlags struct {
+	Name      string `long:"name" optional:"true"`
+	LinkTo    string `long:"linkto" optional:"true"`
+	LinkBy    string `long:"linkby" optional:"true"`
+	Amount    uint64 `long:"amount" optional:"true"`
+	StartDate string `long:"startdate" optional:"true"`
+	EndDate   string `long:"enddate" optional:"true"`
+	Domain    string `long:"domain" optional:"true"`
+	RFP       bool   `long:"rfp" optional:"true"`
+}
+
+// apply applies the provided flags to the proposal metadata and the vote
+// metadata. Only the flags that have been provided are applied.
+func (f *draftFlags) apply(pm *piv1.ProposalMetadata, vm *piv1.VoteMetadata) error {
+	if f.RFP && f.LinkBy != "" {
+		return fmt.Errorf("you cannot use both the --rfp and --linkby " +
+			"flags at the same time")
+	}
+	if f.Name != "" {
+		pm.Name = f.Name
+	}
+	if f.Amount != 0 {
+		pm.Amount = f.Amount
+	}
+	if f.Domain != "" {
+		pm.Domain = f.Domain
+	}
+	if f.StartDate != "" {
+		d, err := unixFromDate(f.StartDate)
+		if err != nil {
+			return err
+		}
+		pm.StartDate = d
+	}
+	if f.EndDate != "" {
+		d, err := unixFromDate(f.EndDate)
+		if err != nil {
+			return err
+		}
+		pm.EndDate = d
+	}
+	if f.LinkTo != "" {
+		vm.LinkTo = f.LinkTo
+	}
+	switch {
+	case f.RFP:
+		// Set linkby to a month from now
+		vm.LinkBy = time.Now().Add(time.Hour * 24 * 30).Unix()
+	case f.LinkBy != "":
+		d, err := time.ParseDuration(f.LinkBy)
+		if err != nil {
+			return fmt.Errorf("unable to parse linkby: %v", err)
+		}
+		vm.LinkBy = time.Now().Add(d).Unix()
+	}
+	return nil
+}
+
+// draftDir returns the directory of the draft with the provided name.
+func draftDir(name string) (string, error) {
+	if !regexpDraftName.MatchString(name) {
+		return "", fmt.Errorf("invalid draft name '%v'; must match %v",
+			name, regexpDraftName)
+	}
+	return filepath.Join(cfg.HomeDir, draftsDirname, name), nil
+}
+
+// draftExists returns whether a draft with the provided name exists.
+func draftExists(name string) (bool, error) {
+	dir, err := draftDir(name)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(dir)
+	switch {
+	case err == nil:
+		return true, nil
+	case os.IsNotExist(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// draftNames returns the names of all drafts.
+func draftNames() ([]string, error) {
+	fis, err := ioutil.ReadDir(filepath.Join(cfg.HomeDir, draftsDirname))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := make([]string, 0, len(fis))
+	for _, v := range fis {
+		if v.IsDir() && regexpDraftName.MatchString(v.Name()) {
+			names = append(names, v.Name())
+		}
+	}
+	return names, nil
+}
+
+// draftInfoLoad loads the pictl specific information of a draft.
+func draftInfoLoad(name string) (*draftInfo, error) {
+	dir, err := draftDir(name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, draftInfoFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &draftInfo{}, nil
+		}
+		return nil, err
+	}
+	var di draftInfo
+	err = json.Unmarshal(b, &di)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal %v: %v", draftInfoFilename, err)
+	}
+	return &di, nil
+}
+
+// draftInfoSave saves the pictl specific information of a draft.
+func draftInfoSave(name string, di draftInfo) error {
+	dir, err := draftDir(name)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(di)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, draftInfoFilename), b, 0600)
+}
+
+// draftFiles returns the proposal files of a draft. Every file in the draft
+// directory is a proposal file, except for the draft info file. The files are
+// sorted by name so that the merkle root of a draft is deterministic.
+func draftFiles(name string) ([]rcv1.File, error) {
+	dir, err := draftDir(name)
+	if err != nil {
+		return nil, err
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("draft '%v' not found", name)
+		}
+		return nil, err
+	}
+	files := make([]rcv1.File, 0, len(fis))
+	for _, v := range fis {
+		if v.IsDir() || v.Name() == draftInfoFilename ||
+			strings.HasPrefix(v.Name(), ".") {
+			continue
+		}
+		payload, err := ioutil.ReadFile(filepath.Join(dir, v.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rcv1.File{
+			Name:    v.Name(),
+			MIME:    mime.DetectMimeType(payload),
+			Digest:  hex.EncodeToString(util.Digest(payload)),
+			Payload: base64.StdEncoding.EncodeToString(payload),
+		})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// draftFileWrite writes a file to the draft directory. The file name must not
+// contain any path elements.
+func draftFileWrite(name, filename string, payload []byte) error {
+	dir, err := draftDir(name)
+	if err != nil {
+		return err
+	}
+	if filename != filepath.Base(filename) || filename == draftInfoFilename ||
+		strings.HasPrefix(filename, ".") {
+		return fmt.Errorf("invalid file name '%v'", filename)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, filename), payload, 0600)
+}
+
+// draftFileCopy copies the file at the provided path into the draft directory
+// using the provided file name.
+func draftFileCopy(name, filename, fp string) error {
+	payload, err := ioutil.ReadFile(util.CleanAndExpandPath(fp))
+	if err != nil {
+		return err
+	}
+	return draftFileWrite(name, filename, payload)
+}
+
+// draftFileFound returns whether the provided files contain a file with the
+// provided name.
+func draftFileFound(files []rcv1.File, filename string) bool {
+	for _, v := range files {
+		if v.Name == filename {
+			return true
+		}
+	}
+	return false
+}
+
+// draftMetadata returns the proposal metadata and the vote metadata of the
+// provided draft files. Zero values are returned for metadata files that do
+// not exist.
+func draftMetadata(files []rcv1.File) (*piv1.ProposalMetadata, *piv1.VoteMetadata, error) {
+	pm := &piv1.ProposalMetadata{}
+	if draftFileFound(files, piv1.FileNameProposalMetadata) {
+		var err error
+		pm, err = pclient.ProposalMetadataDecode(files)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	vm, err := pclient.VoteMetadataDecode(files)
+	if err != nil {
+		return nil, nil, err
+	}
+	if vm == nil {
+		vm = &piv1.VoteMetadata{}
+	}
+	return pm, vm, nil
+}
+
+// draftMetadataSave writes the proposal metadata and the vote metadata to the
+// draft directory. The vote metadata file is removed if the vote metadata is
+// empty.
+func draftMetadataSave(name string, pm piv1.ProposalMetadata, vm piv1.VoteMetadata) error {
+	b, err := json.Marshal(pm)
+	if err != nil {
+		return err
+	}
+	err = draftFileWrite(name, piv1.FileNameProposalMetadata, b)
+	if err != nil {
+		return err
+	}
+	if vm.LinkTo == "" && vm.LinkBy == 0 {
+		dir, err := draftDir(name)
+		if err != nil {
+			return err
+		}
+		err = os.Remove(filepath.Join(dir, piv1.FileNameVoteMetadata))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	b, err = json.Marshal(vm)
+	if err != nil {
+		return err
+	}
+	return draftFileWrite(name, piv1.FileNameVoteMetadata, b)
+}
+
+// draftMerkleRoot returns the merkle root of the provided draft files. This is
+// the merkle root that the censorship record of the proposal is expected to
+// contain once the draft has been submitted.
+func draftMerkleRoot(files []rcv1.File) (string, error) {
+	if len(files) == 0 {
+		return "", fmt.Errorf("no proposal files found")
+	}
+	digests := make([]string, 0, len(files))
+	for _, v := range files {
+		digests = append(digests, v.Digest)
+	}
+	m, err := util.MerkleRoot(digests)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(m[:]), nil
+}
+
+// draftPolicyPath returns the path of the draft policy cache of the
+// politeiawww host. The file is segmented by host the same way the other
+// pictl data files are.
+func draftPolicyPath() (string, error) {
+	u, err := url.Parse(cfg.Host)
+	if err != nil {
+		return "", fmt.Errorf("parse host: %v", err)
+	}
+	f := fmt.Sprintf("%v_%v", u.Hostname(), draftPolicyFilename)
+	return filepath.Join(cfg.DataDir, f), nil
+}
+
+// draftPolicyFetch retrieves the proposal policy from politeiawww and caches
+// it to disk.
+func draftPolicyFetch() (*draftPolicy, error) {
+	opts := pclient.Opts{
+		HTTPSCert: cfg.HTTPSCert,
+		Verbose:   cfg.Verbose,
+		RawJSON:   cfg.RawJSON,
+	}
+	pc, err := pclient.New(cfg.Host, opts)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := pc.PiPolicy()
+	if err != nil {
+		return nil, err
+	}
+	tr, err := pc.PiTemplates()
+	if err != nil {
+		return nil, err
+	}
+	rr, err := pc.RecordPolicy()
+	if err != nil {
+		return nil, err
+	}
+	dp := draftPolicy{
+		Policy:    *pr,
+		Templates: *tr,
+		Records:   *rr,
+		Timestamp: time.Now().Unix(),
+	}
+
+	fp, err := draftPolicyPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(dp)
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(fp, b, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dp, nil
+}
+
+// draftPolicyLoad loads the cached proposal policy. A nil policy is returned
+// if the policy has not been cached yet.
+func draftPolicyLoad() (*draftPolicy, error) {
+	fp, err := draftPolicyPath()
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(fp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var dp draftPolicy
+	err = json.Unmarshal(b, &dp)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal %v: %v", fp, err)
+	}
+	return &dp, nil
+}
+
+// draftValidate validates the provided draft files against the proposal
+// policy. The validation mirrors the validation that is performed by the
+// server when a proposal is submitted. All of the problems that are found are
+// returned.
+func draftValidate(files []rcv1.File, dp draftPolicy, now time.Time) []string {
+	var (
+		p        = dp.Policy
+		problems []string
+	)
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// Verify file types and sizes
+	var (
+		images int
+		index  string
+		found  bool
+	)
+	for _, v := range files {
+		payload, err := base64.StdEncoding.DecodeString(v.Payload)
+		if err != nil {
+			addProblem("%v: invalid base64", v.Name)
+			continue
+		}
+		switch v.MIME {
+		case mimeTypeText, mimeTypeTextUTF8:
+			switch v.Name {
+			case piv1.FileNameIndexFile:
+				index = string(payload)
+				found = true
+			case piv1.FileNameProposalMetadata, piv1.FileNameVoteMetadata:
+			default:
+				addProblem("%v: invalid text file name; allowed text "+
+					"files are %v, %v, %v", v.Name, piv1.FileNameIndexFile,
+					piv1.FileNameProposalMetadata, piv1.FileNameVoteMetadata)
+			}
+			if len(payload) > int(p.TextFileSizeMax) {
+				addProblem("%v: size %v exceeds max size %v", v.Name,
+					len(payload), p.TextFileSizeMax)
+			}
+		case mimeTypePNG:
+			images++
+			if len(payload) > int(p.ImageFileSizeMax) {
+				addProblem("%v: size %v exceeds max size %v", v.Name,
+					len(payload), p.ImageFileSizeMax)
+			}
+		default:
+			addProblem("%v: invalid mime type %v", v.Name, v.MIME)
+		}
+	}
+	if !found {
+		addProblem("%v not found", piv1.FileNameIndexFile)
+	}
+	if images > int(p.ImageFileCountMax) {
+		addProblem("got %v image files, max is %v", images,
+			p.ImageFileCountMax)
+	}
+
+	// Verify the proposal metadata
+	if !draftFileFound(files, piv1.FileNameProposalMetadata) {
+		addProblem("%v not found", piv1.FileNameProposalMetadata)
+		return problems
+	}
+	pm, err := pclient.ProposalMetadataDecode(files)
+	if err != nil {
+		addProblem("%v: %v", piv1.FileNameProposalMetadata, err)
+		return problems
+	}
+	vm, err := pclient.VoteMetadataDecode(files)
+	if err != nil {
+		addProblem("%v: %v", piv1.FileNameVoteMetadata, err)
+		return problems
+	}
+	rexp, err := util.Regexp(p.NameSupportedChars, uint64(p.NameLengthMin),
+		uint64(p.NameLengthMax))
+	if err != nil {
+		addProblem("invalid name policy: %v", err)
+	} else if !rexp.MatchString(pm.Name) {
+		addProblem("name '%v' must be %v to %v characters and may only "+
+			"contain %v", pm.Name, p.NameLengthMin, p.NameLengthMax,
+			strings.Join(p.NameSupportedChars, " "))
+	}
+	var validDomain bool
+	for _, v := range p.Domains {
+		if v == pm.Domain {
+			validDomain = true
+			break
+		}
+	}
+	if !validDomain {
+		addProblem("domain '%v' must be one of %v", pm.Domain,
+			strings.Join(p.Domains, ", "))
+	}
+
+	if vm != nil && vm.LinkBy != 0 {
+		// The proposal is an RFP. RFPs do not request funding.
+		if pm.Amount != 0 {
+			addProblem("RFP metadata should not include an amount")
+		}
+		if pm.StartDate != 0 {
+			addProblem("RFP metadata should not include a start date")
+		}
+		if pm.EndDate != 0 {
+			addProblem("RFP metadata should not include an end date")
+		}
+	} else {
+		if pm.Amount < p.AmountMin || pm.Amount > p.AmountMax {
+			addProblem("amount %v must be between %v and %v",
+				dollars(int64(pm.Amount)), dollars(int64(p.AmountMin)),
+				dollars(int64(p.AmountMax)))
+		}
+		if pm.StartDate <= now.Unix()+p.StartDateMin {
+			addProblem("start date %v must be after %v",
+				dateFromUnix(pm.StartDate),
+				dateFromUnix(now.Unix()+p.StartDateMin))
+		}
+		if pm.EndDate <= pm.StartDate ||
+			pm.EndDate >= now.Unix()+p.EndDateMax {
+			addProblem("end date %v must be after the start date and "+
+				"before %v", dateFromUnix(pm.EndDate),
+				dateFromUnix(now.Unix()+p.EndDateMax))
+		}
+	}
+
+	// Verify the index file contains the required sections
+	if found {
+		required := append([]string{}, p.Sections...)
+		for _, v := range dp.Templates.Templates {
+			if v.Domain == pm.Domain {
+				required = append(required, v.Sections...)
+			}
+		}
+		headings := util.MarkdownHeadings(index)
+		for _, v := range required {
+			_, ok := headings[util.MarkdownHeadingNormalize(v)]
+			if !ok {
+				addProblem("%v is missing the section '%v'",
+					piv1.FileNameIndexFile, v)
+			}
+		}
+	}
+
+	return problems
+}
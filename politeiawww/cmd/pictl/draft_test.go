@@ -0,0 +1,155 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiad/api/v1/mime"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	"github.com/decred/politeia/util"
+)
+
+func newDraftFile(t *testing.T, name string, payload []byte) rcv1.File {
+	t.Helper()
+
+	return rcv1.File{
+		Name:    name,
+		MIME:    mime.DetectMimeType(payload),
+		Digest:  hex.EncodeToString(util.Digest(payload)),
+		Payload: base64.StdEncoding.EncodeToString(payload),
+	}
+}
+
+func newDraftMetadata(t *testing.T, name string, v interface{}) rcv1.File {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newDraftFile(t, name, b)
+}
+
+func TestDraftValidate(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	dp := draftPolicy{
+		Policy: piv1.PolicyReply{
+			TextFileSizeMax:    1024,
+			ImageFileCountMax:  1,
+			ImageFileSizeMax:   1024,
+			NameLengthMin:      8,
+			NameLengthMax:      80,
+			NameSupportedChars: []string{"A-z", "0-9", " "},
+			AmountMin:          100,
+			AmountMax:          1000,
+			StartDateMin:       100,
+			EndDateMax:         10000,
+			Domains:            []string{"development"},
+			Sections:           []string{"Purpose"},
+		},
+		Templates: piv1.TemplatesReply{
+			Templates: []piv1.ProposalTemplate{
+				{
+					Domain:   "development",
+					Sections: []string{"Plan"},
+				},
+			},
+		},
+	}
+	index := newDraftFile(t, piv1.FileNameIndexFile,
+		[]byte("# Purpose\nA purpose\n\nPlan\n----\nA plan\n"))
+	pm := piv1.ProposalMetadata{
+		Name:      "A proposal name",
+		Amount:    500,
+		StartDate: now.Unix() + 200,
+		EndDate:   now.Unix() + 5000,
+		Domain:    "development",
+	}
+	pmInvalid := piv1.ProposalMetadata{
+		Name:      "Bad",
+		Amount:    5000,
+		StartDate: now.Unix(),
+		EndDate:   now.Unix() + 20000,
+		Domain:    "marketing",
+	}
+	rfp := piv1.ProposalMetadata{
+		Name:   "An RFP name",
+		Domain: "development",
+	}
+	vm := piv1.VoteMetadata{
+		LinkBy: now.Unix() + 1000,
+	}
+
+	var tests = []struct {
+		name     string
+		files    []rcv1.File
+		problems int
+	}{
+		{"valid", []rcv1.File{
+			index,
+			newDraftMetadata(t, piv1.FileNameProposalMetadata, pm),
+		}, 0},
+		{"valid rfp", []rcv1.File{
+			index,
+			newDraftMetadata(t, piv1.FileNameProposalMetadata, rfp),
+			newDraftMetadata(t, piv1.FileNameVoteMetadata, vm),
+		}, 0},
+		{"rfp with funding", []rcv1.File{
+			index,
+			newDraftMetadata(t, piv1.FileNameProposalMetadata, pm),
+			newDraftMetadata(t, piv1.FileNameVoteMetadata, vm),
+		}, 3},
+		{"invalid metadata", []rcv1.File{
+			index,
+			newDraftMetadata(t, piv1.FileNameProposalMetadata, pmInvalid),
+		}, 5},
+		{"invalid files", []rcv1.File{
+			newDraftFile(t, "notes.txt", []byte("notes")),
+			newDraftMetadata(t, piv1.FileNameProposalMetadata, pm),
+		}, 2},
+		{"missing sections", []rcv1.File{
+			newDraftFile(t, piv1.FileNameIndexFile, []byte("# Plan\n")),
+			newDraftMetadata(t, piv1.FileNameProposalMetadata, pm),
+		}, 1},
+		{"missing metadata", []rcv1.File{
+			index,
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := draftValidate(tt.files, dp, now)
+			if len(problems) != tt.problems {
+				t.Fatalf("got %v problems, want %v: %q", len(problems),
+					tt.problems, problems)
+			}
+		})
+	}
+}
+
+func TestDraftFilesVerify(t *testing.T) {
+	a := newDraftFile(t, "a.png", []byte("a"))
+	b := newDraftFile(t, "b.png", []byte("b"))
+	c := newDraftFile(t, "b.png", []byte("c"))
+
+	// The server may order the files differently
+	err := draftFilesVerify([]rcv1.File{a, b}, []rcv1.File{b, a})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = draftFilesVerify([]rcv1.File{a, b}, []rcv1.File{a, c})
+	if err == nil {
+		t.Fatal("expected digest mismatch error")
+	}
+	err = draftFilesVerify([]rcv1.File{a, b}, []rcv1.File{a})
+	if err == nil {
+		t.Fatal("expected file count error")
+	}
+}
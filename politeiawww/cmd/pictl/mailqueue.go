@@ -0,0 +1,61 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+
+	v1 "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/cmd/shared"
+)
+
+// mailQueueCmd retrieves the emails in the outbound mail queue that have the
+// provided status.
+type mailQueueCmd struct {
+	Args struct {
+		Status string `positional-arg-name:"status"`
+	} `positional-args:"true" optional:"true"`
+}
+
+// Execute executes the mailQueueCmd command.
+//
+// This function satisfies the go-flags Commander interface.
+func (cmd *mailQueueCmd) Execute(args []string) error {
+	statuses := map[string]v1.MailStatusT{
+		"":       v1.MailStatusQueued,
+		"queued": v1.MailStatusQueued,
+		"sent":   v1.MailStatusSent,
+		"failed": v1.MailStatusFailed,
+	}
+	s, ok := statuses[cmd.Args.Status]
+	if !ok {
+		return fmt.Errorf("invalid status '%v'", cmd.Args.Status)
+	}
+
+	mqr, err := client.MailQueue(&v1.MailQueue{
+		Status: s,
+	})
+	if err != nil {
+		return err
+	}
+
+	return shared.PrintJSON(mqr)
+}
+
+// mailQueueHelpMsg is the output of the help command when 'mailqueue' is
+// specified.
+var mailQueueHelpMsg = `mailqueue "status"
+
+Get the emails in the outbound mail queue that have the provided status. The
+oldest emails are returned first. Failed emails have been retried the maximum
+number of times and will not be sent. Requires admin privileges.
+
+Arguments:
+1. status      (string, optional)   Mail status (default: queued)
+
+Valid statuses:
+  queued
+  sent
+  failed`
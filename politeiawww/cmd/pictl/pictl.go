@@ -38,6 +38,7 @@ type pictl struct {
 
 	// Basic commands
 	Help cmdHelp `command:"help"`
+	Run  cmdRun  `command:"run"`
 
 	// Server commands
 	Version shared.VersionCmd `command:"version"`
@@ -63,24 +64,38 @@ type pictl struct {
 	UserProposalCredits     userProposalCreditsCmd       `command:"userproposalcredits"`
 	UserDetails             userDetailsCmd               `command:"userdetails"`
 	Users                   shared.UsersCmd              `command:"users"`
+	MailQueue               mailQueueCmd                 `command:"mailqueue"`
 
 	// Proposal commands
 	ProposalPolicy               cmdProposalPolicy               `command:"proposalpolicy"`
+	ProposalTemplates            cmdProposalTemplates            `command:"proposaltemplates"`
 	ProposalNew                  cmdProposalNew                  `command:"proposalnew"`
 	ProposalEdit                 cmdProposalEdit                 `command:"proposaledit"`
 	ProposalSetStatus            cmdProposalSetStatus            `command:"proposalsetstatus"`
 	ProposalSetBillingStatus     cmdProposalSetBillingStatus     `command:"proposalsetbillingstatus"`
 	ProposalBillingStatusChanges cmdProposalBillingStatusChanges `command:"proposalbillingstatuschanges"`
+	ProposalBillingTimestamps    cmdProposalBillingTimestamps    `command:"proposalbillingtimestamps"`
 	ProposalDetails              cmdProposalDetails              `command:"proposaldetails"`
 	ProposalTimestamps           cmdProposalTimestamps           `command:"proposaltimestamps"`
+	ProposalArchive              cmdProposalArchive              `command:"proposalarchive"`
 	Proposals                    cmdProposals                    `command:"proposals"`
 	ProposalSummaries            cmdProposalSummaries            `command:"proposalsummaries"`
 	ProposalInv                  cmdProposalInv                  `command:"proposalinv"`
 	ProposalInvOrdered           cmdProposalInvOrdered           `command:"proposalinvordered"`
 	UserProposals                cmdUserProposals                `command:"userproposals"`
 
+	// Draft commands
+	DraftPolicy   cmdDraftPolicy   `command:"draftpolicy"`
+	DraftNew      cmdDraftNew      `command:"draftnew"`
+	DraftEdit     cmdDraftEdit     `command:"draftedit"`
+	DraftValidate cmdDraftValidate `command:"draftvalidate"`
+	DraftSubmit   cmdDraftSubmit   `command:"draftsubmit"`
+	DraftDel      cmdDraftDel      `command:"draftdel"`
+	Drafts        cmdDrafts        `command:"drafts"`
+
 	// Records commands
 	RecordPolicy cmdRecordPolicy `command:"recordpolicy"`
+	UserActivity cmdUserActivity `command:"useractivity"`
 
 	// Comments commands
 	CommentsPolicy    cmdCommentPolicy     `command:"commentpolicy"`
@@ -128,13 +143,14 @@ const helpMsg = `Application Options:
       --host=       politeiawww host
       --httscert    politeiawww https cert file path
       --skipverify  Skip verifying the server's certificate chain and host name
-  -j, --json        Print raw JSON output
+  -j, --json        Print the raw JSON replies
   -v, --verbose     Print verbose output
       --silent      Suppress all output
       --timer       Print command execution time stats
 
 Help commands
   help                         Print detailed help message for a command
+  run                          Execute a script of pictl commands
 
 Basic commands
   version                      (public) Get politeiawww server version and CSRF
@@ -161,24 +177,38 @@ User commands
   userproposalcredits          (user)   Get user proposal credits
   userdetails                  (public) Get user details
   users                        (public) Get users
+  mailqueue                    (admin)  Get queued or failed emails
 
 Proposal commands
   proposalpolicy               (public) Get the pi api policy
+  proposaltemplates            (public) Get the proposal templates
   proposalnew                  (user)   Submit a new proposal
   proposaledit                 (user)   Edit an existing proposal
   proposalsetstatus            (admin)  Set the status of a proposal
   proposalsetbillingstatus     (admin)  Set the billing status of a proposal
   proposalbillingstatuschanges (public) Get billing status changes
+  proposalbillingtimestamps    (public) Get billing status timestamps
   proposaldetails              (public) Get a full proposal record
   proposaltimestamps           (public) Get timestamps for a proposal
+  proposalarchive              (public) Create a proposal archive bundle
   proposals                    (public) Get proposals without their files
   proposalsummaries            (public) Get proposal summaries
   proposalinv                  (public) Get inventory by proposal status
   proposalinvordered           (public) Get inventory ordered chronologically
   userproposals                (public) Get proposals submitted by a user
 
+Draft commands
+  draftpolicy                  (public) Cache the proposal policy for drafts
+  draftnew                     (local)  Create a local proposal draft
+  draftedit                    (local)  Edit a local proposal draft
+  draftvalidate                (local)  Validate a draft against the policy
+  draftsubmit                  (user)   Submit a draft as a proposal
+  draftdel                     (local)  Delete a local proposal draft
+  drafts                       (local)  List the local proposal drafts
+
 Record commands
   recordpolicy                 (public) Get the records api policy
+  useractivity                 (public) Get the activity timeline of a user
 
 Comment commands
   commentpolicy                (public) Get the comments api policy
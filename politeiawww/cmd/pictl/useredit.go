@@ -18,6 +18,14 @@ type userEditCmd struct {
 	Args struct {
 		NotifType string `long:"emailnotifications"` // Email notification bit field
 	} `positional-args:"true" required:"true"`
+
+	// Language is the language that notification emails are sent in.
+	// The supported languages are returned by the policy route.
+	Language string `long:"language" optional:"true"`
+
+	// EmailDigest is how often the email notifications are sent. The
+	// notifications are sent individually when this is set to none.
+	EmailDigest string `long:"emaildigest" optional:"true"`
 }
 
 // Execute executes the userEditCmd command.
@@ -68,6 +76,16 @@ func (cmd *userEditCmd) Execute(args []string) error {
 	eu := &v1.EditUser{
 		EmailNotifications: &helper,
 	}
+	if cmd.Language != "" {
+		eu.Language = &cmd.Language
+	}
+	if cmd.EmailDigest != "" {
+		d, err := parseEmailDigest(cmd.EmailDigest)
+		if err != nil {
+			return err
+		}
+		eu.EmailDigest = &d
+	}
 
 	// Print request details
 	err = shared.PrintJSON(eu)
@@ -85,6 +103,22 @@ func (cmd *userEditCmd) Execute(args []string) error {
 	return shared.PrintJSON(eur)
 }
 
+// parseEmailDigest parses the provided email digest frequency. Both the
+// numeric and the human readable frequencies are accepted.
+func parseEmailDigest(s string) (v1.EmailDigestT, error) {
+	u, err := strconv.ParseUint(s, 10, 32)
+	if err == nil {
+		return v1.EmailDigestT(u), nil
+	}
+	for k, v := range v1.EmailDigest {
+		if v == s {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid email digest %q; valid options are "+
+		"none, daily and weekly", s)
+}
+
 // userEditHelpMsg is the output of the help command when 'edituser' is
 // specified.
 const userEditHelpMsg = `useredit "emailnotifications"
@@ -94,6 +128,15 @@ Edit user settings for the logged in user.
 Arguments:
 1. emailnotifications       (string, required)   Email notification bit field
 
+Flags:
+ --language                 (string, optional)   Notification email language.
+                                                 The supported languages are
+                                                 listed by the policy command.
+ --emaildigest              (string, optional)   Send the email notifications
+                                                 as a periodic digest. Valid
+                                                 options are none, daily and
+                                                 weekly.
+
 Valid options are:
 
 1.   userproposalchange         Notify when status of my proposal changes
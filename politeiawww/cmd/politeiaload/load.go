@@ -0,0 +1,559 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	pclient "github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// batchSize is the number of tokens that are requested by the batch
+	// operations. It matches the page size of the batched routes.
+	batchSize = 5
+
+	// ballotSize is the number of votes that are included in a ballot.
+	ballotSize = 5
+
+	// discoverComments is the number of records whose comments are
+	// retrieved prior to the run in order to populate the comment IDs
+	// that the comment vote operation votes on.
+	discoverComments = 20
+)
+
+// op is a load operation. Each operation sends a single request to a single
+// politeiawww route.
+type op struct {
+	route string
+	auth  bool // Requires a logged in user
+	exec  func(w *worker) error
+}
+
+// ops contains all load operations.
+var ops = map[string]op{
+	"details": {
+		route: rcv1.APIRoute + rcv1.RouteDetails,
+		exec: func(w *worker) error {
+			_, err := w.client.RecordDetails(rcv1.Details{
+				Token: w.token(),
+			})
+			return err
+		},
+	},
+	"records": {
+		route: rcv1.APIRoute + rcv1.RouteRecords,
+		exec: func(w *worker) error {
+			tokens := w.tokens(batchSize)
+			reqs := make([]rcv1.RecordRequest, 0, len(tokens))
+			for _, v := range tokens {
+				reqs = append(reqs, rcv1.RecordRequest{
+					Token: v,
+					Filenames: []string{
+						piv1.FileNameProposalMetadata,
+						piv1.FileNameVoteMetadata,
+					},
+				})
+			}
+			_, err := w.client.Records(rcv1.Records{
+				Requests: reqs,
+			})
+			return err
+		},
+	},
+	"summaries": {
+		route: piv1.APIRoute + piv1.RouteSummaries,
+		exec: func(w *worker) error {
+			_, err := w.client.PiSummaries(piv1.Summaries{
+				Tokens: w.tokens(batchSize),
+			})
+			return err
+		},
+	},
+	"comments": {
+		route: cmv1.APIRoute + cmv1.RouteComments,
+		exec: func(w *worker) error {
+			_, err := w.client.Comments(cmv1.Comments{
+				Token: w.token(),
+			})
+			return err
+		},
+	},
+	"commentcount": {
+		route: cmv1.APIRoute + cmv1.RouteCount,
+		exec: func(w *worker) error {
+			_, err := w.client.CommentCount(cmv1.Count{
+				Tokens: w.tokens(batchSize),
+			})
+			return err
+		},
+	},
+	"votesummaries": {
+		route: tkv1.APIRoute + tkv1.RouteSummaries,
+		exec: func(w *worker) error {
+			_, err := w.client.TicketVoteSummaries(tkv1.Summaries{
+				Tokens: w.tokens(batchSize),
+			})
+			return err
+		},
+	},
+	"commentnew": {
+		route: cmv1.APIRoute + cmv1.RouteNew,
+		auth:  true,
+		exec:  commentNew,
+	},
+	"commentvote": {
+		route: cmv1.APIRoute + cmv1.RouteVote,
+		auth:  true,
+		exec:  commentVote,
+	},
+	"ballot": {
+		route: tkv1.APIRoute + tkv1.RouteCastBallot,
+		exec:  castBallot,
+	},
+}
+
+// opNames returns the sorted names of all load operations.
+func opNames() []string {
+	names := make([]string, 0, len(ops))
+	for k := range ops {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// weightedOp is a load operation and its relative weight in the mix.
+type weightedOp struct {
+	name   string
+	weight uint
+}
+
+// parseMix parses a mix of operations. The mix is a comma separated list of
+// name=weight pairs, e.g. "details=60,comments=30,ballot=10". The weights are
+// relative to each other and do not need to add up to 100.
+func parseMix(s string) ([]weightedOp, error) {
+	var (
+		mix  []weightedOp
+		seen = make(map[string]struct{})
+	)
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid mix entry '%v'; must be "+
+				"name=weight", v)
+		}
+		name := strings.TrimSpace(kv[0])
+		if _, ok := ops[name]; !ok {
+			return nil, fmt.Errorf("unknown operation '%v'; valid "+
+				"operations are %v", name, strings.Join(opNames(), ", "))
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("duplicate operation '%v'", name)
+		}
+		seen[name] = struct{}{}
+		weight, err := strconv.ParseUint(strings.TrimSpace(kv[1]), 10, 32)
+		if err != nil || weight == 0 {
+			return nil, fmt.Errorf("invalid weight for operation '%v'; "+
+				"must be a positive integer", name)
+		}
+		mix = append(mix, weightedOp{
+			name:   name,
+			weight: uint(weight),
+		})
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("mix does not contain any operations")
+	}
+	return mix, nil
+}
+
+// pickOp returns the operation that corresponds to the provided random number
+// in the range [0, total weight).
+func pickOp(mix []weightedOp, n uint) string {
+	for _, v := range mix {
+		if n < v.weight {
+			return v.name
+		}
+		n -= v.weight
+	}
+	return mix[len(mix)-1].name
+}
+
+// mixWeight returns the total weight of a mix.
+func mixWeight(mix []weightedOp) uint {
+	var total uint
+	for _, v := range mix {
+		total += v.weight
+	}
+	return total
+}
+
+// ticket is a ticket that is eligible to vote on the records of a run. The
+// sign function returns the hex encoded signature of a message that is signed
+// using the private key of the ticket commitment address.
+type ticket struct {
+	hash string
+	sign func(msg string) string
+}
+
+// targets contains the records that the load operations are sent against.
+type targets struct {
+	sync.Mutex
+	tokens    []string
+	commented []string            // Tokens of records with comments
+	comments  map[string][]uint32 // [token]commentIDs
+	voting    []string            // Tokens of records with a vote
+	voteBits  map[string]string   // [token]hex encoded vote bit
+	tickets   []ticket            // Eligible tickets
+	voted     map[string]int      // [token]Number of tickets used
+}
+
+// ballotTickets returns up to n eligible tickets that have not been used to
+// vote on the provided record yet. The returned tickets are marked as used.
+func (t *targets) ballotTickets(token string, n int) []ticket {
+	t.Lock()
+	defer t.Unlock()
+
+	start := t.voted[token]
+	end := start + n
+	if end > len(t.tickets) {
+		end = len(t.tickets)
+	}
+	t.voted[token] = end
+
+	return t.tickets[start:end]
+}
+
+// run contains the settings of a load run.
+type run struct {
+	host      string
+	httpsCert string
+	session   *session
+	mix       []weightedOp
+	rate      float64 // Requests per second
+	duration  time.Duration
+	workers   int
+	tokens    []string
+	tickets   []ticket
+}
+
+// newClient returns a new politeiawww client for the run.
+func (r *run) newClient() (*pclient.Client, error) {
+	opts := pclient.Opts{
+		HTTPSCert: r.httpsCert,
+	}
+	if r.session != nil {
+		opts.Cookies = r.session.cookies
+		opts.HeaderCSRF = r.session.csrf
+	}
+	return pclient.New(r.host, opts)
+}
+
+// discover retrieves the records that the load operations are sent against.
+// The public records of the first inventory page are used when tokens were
+// not provided. The comment IDs and the votes of the records are retrieved
+// for the operations that require them.
+func (r *run) discover(c *pclient.Client) (*targets, error) {
+	t := targets{
+		tokens:   r.tokens,
+		comments: make(map[string][]uint32),
+		voteBits: make(map[string]string),
+		tickets:  r.tickets,
+		voted:    make(map[string]int),
+	}
+	if len(t.tokens) == 0 {
+		ir, err := c.RecordInventory(rcv1.Inventory{
+			State:  rcv1.RecordStateVetted,
+			Status: rcv1.RecordStatusPublic,
+			Page:   1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("record inventory: %v", err)
+		}
+		t.tokens = ir.Vetted[rcv1.RecordStatuses[rcv1.RecordStatusPublic]]
+	}
+	if len(t.tokens) == 0 {
+		return nil, fmt.Errorf("no public records found; provide the " +
+			"record tokens using -tokens")
+	}
+
+	var needComments, needVotes bool
+	for _, v := range r.mix {
+		switch v.name {
+		case "commentvote":
+			needComments = true
+		case "ballot":
+			needVotes = true
+		}
+	}
+	for i, token := range t.tokens {
+		if needComments && i < discoverComments {
+			cr, err := c.Comments(cmv1.Comments{
+				Token: token,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("comments %v: %v", token, err)
+			}
+			for _, v := range cr.Comments {
+				t.comments[token] = append(t.comments[token], v.CommentID)
+			}
+			if len(cr.Comments) > 0 {
+				t.commented = append(t.commented, token)
+			}
+		}
+		if needVotes {
+			t.voteBits[token] = "1"
+			dr, err := c.TicketVoteDetails(tkv1.Details{
+				Token: token,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("vote details %v: %v", token, err)
+			}
+			if dr.Vote != nil && len(dr.Vote.Params.Options) > 0 {
+				bit := dr.Vote.Params.Options[0].Bit
+				t.voteBits[token] = strconv.FormatUint(bit, 16)
+				t.voting = append(t.voting, token)
+			}
+		}
+	}
+	if needComments && len(t.commented) == 0 {
+		return nil, fmt.Errorf("no comments found to vote on")
+	}
+
+	return &t, nil
+}
+
+// execute executes the load run. Requests are dispatched at a fixed rate
+// regardless of how long the previous requests take to complete. A request
+// that cannot be dispatched because all workers are busy is counted as
+// missed. The run stops early when the stop channel is closed.
+func (r *run) execute(stop <-chan struct{}) (*result, error) {
+	for _, v := range r.mix {
+		if ops[v.name].auth && (r.session == nil || r.session.id == nil) {
+			return nil, fmt.Errorf("operation '%v' requires a logged in "+
+				"user; use -pictl", v.name)
+		}
+	}
+
+	// Retrieve the records that the load is sent against
+	c, err := r.newClient()
+	if err != nil {
+		return nil, err
+	}
+	t, err := r.discover(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Start the workers
+	var (
+		s    = newStats()
+		jobs = make(chan string, r.workers)
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < r.workers; i++ {
+		c, err := r.newClient()
+		if err != nil {
+			return nil, err
+		}
+		w := worker{
+			client:  c,
+			targets: t,
+			session: r.session,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				start := time.Now()
+				err := ops[name].exec(&w)
+				s.add(ops[name].route, time.Since(start), err)
+			}
+		}()
+	}
+
+	// Dispatch the requests
+	var (
+		interval = time.Duration(float64(time.Second) / r.rate)
+		total    = mixWeight(r.mix)
+		ticker   = time.NewTicker(interval)
+		timer    = time.NewTimer(r.duration)
+		start    = time.Now()
+		missed   uint64
+	)
+	fmt.Printf("Sending %v req/s for %v using %v workers against %v "+
+		"records\n", r.rate, r.duration, r.workers, len(t.tokens))
+dispatch:
+	for {
+		select {
+		case <-ticker.C:
+			name := pickOp(r.mix, uint(rand.Intn(int(total))))
+			select {
+			case jobs <- name:
+			default:
+				missed++
+			}
+		case <-timer.C:
+			break dispatch
+		case <-stop:
+			break dispatch
+		}
+	}
+	ticker.Stop()
+	timer.Stop()
+	close(jobs)
+	wg.Wait()
+
+	return s.result(time.Since(start), missed), nil
+}
+
+// worker executes load operations.
+type worker struct {
+	client  *pclient.Client
+	targets *targets
+	session *session
+}
+
+// token returns a random record token.
+func (w *worker) token() string {
+	return w.targets.tokens[rand.Intn(len(w.targets.tokens))]
+}
+
+// tokens returns up to n random record tokens.
+func (w *worker) tokens(n int) []string {
+	if n > len(w.targets.tokens) {
+		n = len(w.targets.tokens)
+	}
+	tokens := make([]string, 0, n)
+	for _, i := range rand.Perm(len(w.targets.tokens))[:n] {
+		tokens = append(tokens, w.targets.tokens[i])
+	}
+	return tokens
+}
+
+// commentNew submits a new comment on a random record.
+func commentNew(w *worker) error {
+	var (
+		state    = cmv1.RecordStateVetted
+		token    = w.token()
+		parentID uint32
+		comment  = fmt.Sprintf("Load test comment %v", time.Now().UnixNano())
+	)
+	msg := strconv.FormatUint(uint64(state), 10) + token +
+		strconv.FormatUint(uint64(parentID), 10) + comment
+	sig := w.session.id.SignMessage([]byte(msg))
+	_, err := w.client.CommentNew(cmv1.New{
+		State:     state,
+		Token:     token,
+		ParentID:  parentID,
+		Comment:   comment,
+		PublicKey: w.session.id.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	return err
+}
+
+// commentVote casts a random comment vote on a random comment.
+func commentVote(w *worker) error {
+	var (
+		token     = w.targets.commented[rand.Intn(len(w.targets.commented))]
+		state     = cmv1.RecordStateVetted
+		ids       = w.targets.comments[token]
+		commentID = ids[rand.Intn(len(ids))]
+		vote      = cmv1.VoteUpvote
+	)
+	if rand.Intn(2) == 0 {
+		vote = cmv1.VoteDownvote
+	}
+	msg := strconv.FormatUint(uint64(state), 10) + token +
+		strconv.FormatUint(uint64(commentID), 10) +
+		strconv.FormatInt(int64(vote), 10)
+	sig := w.session.id.SignMessage([]byte(msg))
+	_, err := w.client.CommentVote(cmv1.Vote{
+		State:     state,
+		Token:     token,
+		CommentID: commentID,
+		Vote:      vote,
+		PublicKey: w.session.id.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	return err
+}
+
+// castBallot casts a ballot of votes on a random record. The records that
+// have a vote are preferred when there are any.
+//
+// When the run has eligible tickets, the votes are signed using the tickets
+// and each ticket votes once on each record. These votes go through the full
+// ballot processing path of the ticketvote plugin, i.e. the eligibility
+// check, the commitment address lookup, the signature verification, and the
+// vote write. An error is returned once all tickets have voted on the record.
+//
+// Otherwise the votes use random ticket hashes and signatures. politeiawww
+// rejects them in the eligibility check before their commitment addresses are
+// looked up or their signatures are verified.
+//
+// The individual vote errors are not counted as request errors.
+func castBallot(w *worker) error {
+	token := w.token()
+	if len(w.targets.voting) > 0 {
+		token = w.targets.voting[rand.Intn(len(w.targets.voting))]
+	}
+	voteBit := w.targets.voteBits[token]
+
+	votes := make([]tkv1.CastVote, 0, ballotSize)
+	if len(w.targets.tickets) > 0 {
+		tickets := w.targets.ballotTickets(token, ballotSize)
+		if len(tickets) == 0 {
+			return fmt.Errorf("all tickets have voted on %v", token)
+		}
+		for _, v := range tickets {
+			votes = append(votes, tkv1.CastVote{
+				Token:     token,
+				Ticket:    v.hash,
+				VoteBit:   voteBit,
+				Signature: v.sign(token + v.hash + voteBit),
+			})
+		}
+	} else {
+		for i := 0; i < ballotSize; i++ {
+			ticket, err := util.Random(32)
+			if err != nil {
+				return err
+			}
+			sig, err := util.Random(65)
+			if err != nil {
+				return err
+			}
+			votes = append(votes, tkv1.CastVote{
+				Token:     token,
+				Ticket:    hex.EncodeToString(ticket),
+				VoteBit:   voteBit,
+				Signature: hex.EncodeToString(sig),
+			})
+		}
+	}
+	_, err := w.client.TicketVoteCastBallot(tkv1.CastBallot{
+		Votes: votes,
+	})
+	return err
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/config"
+)
+
+const (
+	defaultHost = "https://127.0.0.1:4443"
+	defaultMix  = "details=30,records=10,summaries=10,comments=25," +
+		"commentcount=5,votesummaries=5,ballot=15"
+)
+
+var (
+	// CLI flags
+	host      = flag.String("host", defaultHost, "politeiawww host")
+	httpsCert = flag.String("httpscert", config.DefaultHTTPSCert,
+		"politeiawww https cert")
+	pictlDir = flag.String("pictl", "", "pictl home directory of a "+
+		"logged in user; required for authenticated routes")
+
+	rate     = flag.Float64("rate", 10, "requests per second")
+	duration = flag.Duration("duration", time.Minute, "duration of the run")
+	workers  = flag.Uint("workers", 10, "number of concurrent requests")
+	mix      = flag.String("mix", defaultMix, "weighted mix of operations")
+	tokens   = flag.String("tokens", "", "comma separated record tokens; "+
+		"defaults to the public records of the first inventory page")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: politeiaload [flags]\n")
+	fmt.Fprintf(os.Stderr, " flags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\n operations:\n")
+	for _, name := range opNames() {
+		o := ops[name]
+		auth := ""
+		if o.auth {
+			auth = " (requires -pictl)"
+		}
+		fmt.Fprintf(os.Stderr, "  %-14v %v%v\n", name, o.route, auth)
+	}
+	fmt.Fprintf(os.Stderr, "\n")
+}
+
+func _main() error {
+	flag.Usage = usage
+	flag.Parse()
+
+	switch {
+	case *rate <= 0:
+		return fmt.Errorf("rate must be positive")
+	case *duration <= 0:
+		return fmt.Errorf("duration must be positive")
+	case *workers == 0:
+		return fmt.Errorf("workers must be positive")
+	}
+	m, err := parseMix(*mix)
+	if err != nil {
+		return err
+	}
+
+	// Setup the run
+	r := run{
+		host:      *host,
+		httpsCert: *httpsCert,
+		mix:       m,
+		rate:      *rate,
+		duration:  *duration,
+		workers:   int(*workers),
+	}
+	if *tokens != "" {
+		r.tokens = strings.Split(*tokens, ",")
+	}
+	if *pictlDir != "" {
+		r.session, err = loadSession(*pictlDir, r.host)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Stop the run early on an interrupt
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		close(stop)
+	}()
+
+	res, err := r.execute(stop)
+	if err != nil {
+		return err
+	}
+	res.print(os.Stdout)
+
+	return nil
+}
+
+func main() {
+	err := _main()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/e2e"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+func TestParseMix(t *testing.T) {
+	var tests = []struct {
+		mix   string
+		total uint
+		err   bool
+	}{
+		{defaultMix, 100, false},
+		{"details=1", 1, false},
+		{" details = 3 , ballot=2,", 5, false},
+		{"details", 0, true},
+		{"details=0", 0, true},
+		{"details=-1", 0, true},
+		{"details=1,details=2", 0, true},
+		{"unknown=1", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mix, func(t *testing.T) {
+			mix, err := parseMix(tt.mix)
+			switch {
+			case tt.err && err == nil:
+				t.Fatal("expected error")
+			case !tt.err && err != nil:
+				t.Fatal(err)
+			}
+			if total := mixWeight(mix); total != tt.total {
+				t.Fatalf("got total weight %v, want %v", total, tt.total)
+			}
+		})
+	}
+}
+
+func TestPickOp(t *testing.T) {
+	mix, err := parseMix("details=2,comments=1,ballot=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"details", "details", "comments", "ballot", "ballot",
+		"ballot"}
+	for n, name := range want {
+		got := pickOp(mix, uint(n))
+		if got != name {
+			t.Fatalf("pickOp(%v): got %v, want %v", n, got, name)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 0, 100)
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*time.Millisecond)
+	}
+	var tests = []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, time.Millisecond},
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got := percentile(sorted, tt.p)
+		if got != tt.want {
+			t.Fatalf("percentile(%v): got %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Fatalf("empty percentile: got %v, want 0", got)
+	}
+}
+
+func TestRunLocal(t *testing.T) {
+	h, cleanup := e2e.New(t)
+	defer cleanup()
+
+	var (
+		admin  = h.NewUser(t, true)
+		author = h.NewUser(t, false)
+	)
+
+	// Seed public proposals that have comments and an active vote. The
+	// comments are made by the admin since the load is sent by the
+	// author and users cannot vote on their own comments.
+	tokens := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		token := h.NewProposal(t, author, admin)
+		for j := 0; j < 2; j++ {
+			h.NewComment(t, admin, token, fmt.Sprintf("Comment %v", j+1))
+		}
+		h.StartVote(t, author, admin, token)
+		tokens = append(tokens, token)
+	}
+
+	// The ballots are signed using the eligible tickets
+	eligible := h.EligibleTickets()
+	tickets := make([]ticket, 0, len(eligible))
+	for _, v := range eligible {
+		v := v
+		tickets = append(tickets, ticket{
+			hash: v.Hash,
+			sign: v.Sign,
+		})
+	}
+
+	// Include every operation in the mix. Ballots are weighted higher
+	// so that the run is certain to cast votes.
+	m := "ballot=5,"
+	for _, name := range opNames() {
+		if name != "ballot" {
+			m += name + "=1,"
+		}
+	}
+	mix, err := parseMix(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := run{
+		host:      h.PoliteiawwwHost(),
+		httpsCert: h.PoliteiawwwCert(),
+		session: &session{
+			cookies: author.Cookies,
+			csrf:    author.CSRF,
+			id:      author.Identity,
+		},
+		mix:      mix,
+		rate:     100,
+		duration: 500 * time.Millisecond,
+		workers:  4,
+		tickets:  tickets,
+	}
+	res, err := r.execute(make(chan struct{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total.Requests == 0 {
+		t.Fatal("no requests were sent")
+	}
+	var ballots int
+	for _, v := range res.Routes {
+		if v.Errors != 0 {
+			t.Errorf("%v: %v errors: %v", v.Route, v.Errors, v.FirstErr)
+		}
+		if v.Route == tkv1.APIRoute+tkv1.RouteCastBallot {
+			ballots = v.Requests
+		}
+	}
+
+	if ballots == 0 {
+		t.Fatal("no ballots were cast")
+	}
+
+	// Verify that every vote of the ballots was counted
+	var votes int
+	for _, token := range tokens {
+		rr, err := h.Client.TicketVoteResults(tkv1.Results{
+			Token: token,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		votes += len(rr.Votes)
+	}
+	if votes != ballots*ballotSize {
+		t.Errorf("got %v votes, want %v", votes, ballots*ballotSize)
+	}
+}
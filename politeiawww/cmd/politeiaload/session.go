@@ -0,0 +1,94 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// The following files are written by pictl to its data directory
+	// when a user logs in. The file names are prefixed with the
+	// hostname of the politeiawww instance.
+	pictlDataDirname = "data"
+	pictlUserFile    = "user.txt"
+	pictlCSRFFile    = "csrf.txt"
+	pictlCookieFile  = "cookies.json"
+	pictlIdentity    = "identity.json"
+)
+
+// session contains the credentials of a logged in user. They are used to
+// send requests to the authenticated routes.
+type session struct {
+	cookies []*http.Cookie
+	csrf    string
+	id      *identity.FullIdentity
+}
+
+// loadSession loads the session of the user that is logged in to the provided
+// host using pictl.
+func loadSession(pictlHomeDir, host string) (*session, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("parse host: %v", err)
+	}
+	dataDir := filepath.Join(util.CleanAndExpandPath(pictlHomeDir),
+		pictlDataDirname)
+	hostFile := func(filename string) string {
+		return filepath.Join(dataDir,
+			fmt.Sprintf("%v_%v", u.Hostname(), filename))
+	}
+
+	// Load the logged in username
+	b, err := ioutil.ReadFile(hostFile(pictlUserFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no pictl user is logged in to %v",
+				u.Hostname())
+		}
+		return nil, err
+	}
+	username := strings.TrimSpace(string(b))
+	if username == "" {
+		return nil, fmt.Errorf("no pictl user is logged in to %v",
+			u.Hostname())
+	}
+
+	// Load the cookies, CSRF token, and identity
+	b, err = ioutil.ReadFile(hostFile(pictlCookieFile))
+	if err != nil {
+		return nil, err
+	}
+	var cookies []*http.Cookie
+	err = json.Unmarshal(b, &cookies)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal cookies: %v", err)
+	}
+	csrf, err := ioutil.ReadFile(hostFile(pictlCSRFFile))
+	if err != nil {
+		return nil, err
+	}
+	fp := hostFile(fmt.Sprintf("%v_%v", username, pictlIdentity))
+	id, err := identity.LoadFullIdentity(fp)
+	if err != nil {
+		return nil, fmt.Errorf("load identity %v: %v", fp, err)
+	}
+
+	return &session{
+		cookies: cookies,
+		csrf:    string(csrf),
+		id:      id,
+	}, nil
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// stats collects the request latencies of a load run.
+type stats struct {
+	sync.Mutex
+	routes map[string]*routeStats // [route]routeStats
+}
+
+// routeStats contains the request latencies and errors of a single route.
+type routeStats struct {
+	latencies []time.Duration
+	errors    uint64
+	firstErr  error
+}
+
+// newStats returns a new stats.
+func newStats() *stats {
+	return &stats{
+		routes: make(map[string]*routeStats),
+	}
+}
+
+// add adds the result of a request to the stats.
+func (s *stats) add(route string, latency time.Duration, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	rs, ok := s.routes[route]
+	if !ok {
+		rs = &routeStats{}
+		s.routes[route] = rs
+	}
+	rs.latencies = append(rs.latencies, latency)
+	if err != nil {
+		rs.errors++
+		if rs.firstErr == nil {
+			rs.firstErr = err
+		}
+	}
+}
+
+// routeResult contains the results of a single route.
+type routeResult struct {
+	Route    string        `json:"route"`
+	Requests int           `json:"requests"`
+	Errors   uint64        `json:"errors"`
+	P50      time.Duration `json:"p50"`
+	P90      time.Duration `json:"p90"`
+	P99      time.Duration `json:"p99"`
+	Max      time.Duration `json:"max"`
+	FirstErr string        `json:"firsterr,omitempty"`
+}
+
+// result contains the results of a load run.
+type result struct {
+	Elapsed time.Duration `json:"elapsed"`
+	Missed  uint64        `json:"missed"` // Requests not sent; workers busy
+	Routes  []routeResult `json:"routes"`
+	Total   routeResult   `json:"total"`
+}
+
+// result returns the results of the load run.
+func (s *stats) result(elapsed time.Duration, missed uint64) *result {
+	s.Lock()
+	defer s.Unlock()
+
+	r := result{
+		Elapsed: elapsed,
+		Missed:  missed,
+		Routes:  make([]routeResult, 0, len(s.routes)),
+	}
+	var all []time.Duration
+	for route, rs := range s.routes {
+		rr := routeSummary(route, rs.latencies)
+		rr.Errors = rs.errors
+		if rs.firstErr != nil {
+			rr.FirstErr = rs.firstErr.Error()
+		}
+		r.Routes = append(r.Routes, rr)
+		r.Total.Errors += rs.errors
+		all = append(all, rs.latencies...)
+	}
+	sort.Slice(r.Routes, func(i, j int) bool {
+		return r.Routes[i].Route < r.Routes[j].Route
+	})
+	errs := r.Total.Errors
+	r.Total = routeSummary("total", all)
+	r.Total.Errors = errs
+
+	return &r
+}
+
+// routeSummary returns the latency percentiles of the provided latencies.
+func routeSummary(route string, latencies []time.Duration) routeResult {
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return routeResult{
+		Route:    route,
+		Requests: len(sorted),
+		P50:      percentile(sorted, 50),
+		P90:      percentile(sorted, 90),
+		P99:      percentile(sorted, 99),
+		Max:      percentile(sorted, 100),
+	}
+}
+
+// percentile returns the pth percentile of the provided sorted latencies
+// using the nearest rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// print prints the results of a load run.
+func (r *result) print(w io.Writer) {
+	format := "%-28v %8v %7v %8v %9v %9v %9v %9v\n"
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, format, "Route", "Requests", "Errors", "Req/s",
+		"p50", "p90", "p99", "Max")
+	printRoute := func(rr routeResult) {
+		perSec := float64(rr.Requests) / r.Elapsed.Seconds()
+		fmt.Fprintf(w, format, rr.Route, rr.Requests, rr.Errors,
+			fmt.Sprintf("%.1f", perSec), roundLatency(rr.P50),
+			roundLatency(rr.P90), roundLatency(rr.P99),
+			roundLatency(rr.Max))
+	}
+	for _, v := range r.Routes {
+		printRoute(v)
+	}
+	printRoute(r.Total)
+	fmt.Fprintf(w, "\nElapsed: %v\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "Missed : %v\n", r.Missed)
+	for _, v := range r.Routes {
+		if v.FirstErr != "" {
+			fmt.Fprintf(w, "First error %v: %v\n", v.Route, v.FirstErr)
+		}
+	}
+}
+
+// roundLatency rounds a latency for display.
+func roundLatency(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
@@ -0,0 +1,355 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+
+	"github.com/decred/dcrd/blockchain/standalone/v2"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/wire"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/util"
+	"lukechampine.com/blake3"
+)
+
+const (
+	// opReturn is the OP_RETURN script opcode.
+	opReturn = 0x6a
+
+	// opData32 is the script opcode that pushes the next 32 bytes onto
+	// the stack.
+	opData32 = 0x20
+)
+
+// powParams contains the proof of work consensus parameters that the chain
+// file headers are verified against.
+type powParams struct {
+	// powLimit is the highest proof of work target that a block header
+	// can commit to.
+	powLimit *big.Int
+
+	// blake3Height is the height at which DCP0011 activated. The proof of
+	// work hash of the headers at and after this height is the BLAKE3
+	// hash of the header instead of the block hash.
+	blake3Height uint32
+}
+
+var (
+	// mainNetPowParams are the mainnet proof of work parameters. DCP0011
+	// activated at the start of the rule change interval that began at
+	// block 794368.
+	mainNetPowParams = powParams{
+		powLimit:     chaincfg.MainNetParams().PowLimit,
+		blake3Height: 794368,
+	}
+)
+
+// chainFile represents the file of DCR blockchain data that is used to verify
+// timestamp anchors without a network connection. The data can be exported
+// from dcrd using dcrctl.
+//
+// Blocks contains the raw blocks that include the anchor transactions. These
+// are returned by 'dcrctl getblock [hash] false'.
+//
+// Headers contains the raw block headers of the chain, starting at or before
+// the first anchor block and ending at the chain tip that the anchors are
+// verified against. These are returned by
+// 'dcrctl getblockheader [hash] false'.
+type chainFile struct {
+	Blocks  []string `json:"blocks"`
+	Headers []string `json:"headers"`
+}
+
+// anchorTx is a transaction that is included in a block of the chain.
+type anchorTx struct {
+	tx     *wire.MsgTx
+	height uint32
+}
+
+// anchorStore contains the DCR blockchain data that has been loaded from a
+// chain file and verified.
+type anchorStore struct {
+	tip     uint32                      // Height of the chain tip
+	tipHash chainhash.Hash              // Hash of the chain tip
+	txs     map[chainhash.Hash]anchorTx // [txid]anchorTx
+}
+
+// anchor is a merkle root that was timestamped onto the DCR blockchain in the
+// OP_RETURN of a transaction.
+type anchor struct {
+	TxID       string
+	MerkleRoot string
+}
+
+// loadChainFile loads the chain file at the provided path and verifies its
+// contents. The block headers must form a chain and each header must satisfy
+// the proof of work target it commits to. The target must not exceed the proof
+// of work limit of the network. Every block must be part of the chain and its
+// transactions must match the merkle root in its header.
+//
+// The difficulty retargeting rules are not validated, so a chain that was
+// mined at the proof of work limit would pass these checks. The hash of the
+// chain tip must match the provided tip hash, which the user must obtain from
+// a trusted source. The tip commits to every header before it, which ties the
+// entire chain file to the main chain.
+func loadChainFile(fp string, params powParams, tipHash string) (*anchorStore, error) {
+	if tipHash == "" {
+		return nil, fmt.Errorf("chain tip hash not provided")
+	}
+	wantTip, err := chainhash.NewHashFromStr(tipHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain tip hash: %v", err)
+	}
+	b, err := ioutil.ReadFile(util.CleanAndExpandPath(fp))
+	if err != nil {
+		return nil, err
+	}
+	var cf chainFile
+	err = json.Unmarshal(b, &cf)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal chain file: %v", err)
+	}
+	if len(cf.Headers) == 0 {
+		return nil, fmt.Errorf("chain file does not contain any headers")
+	}
+
+	// Decode and verify the block headers
+	headers := make([]wire.BlockHeader, 0, len(cf.Headers))
+	for i, v := range cf.Headers {
+		hb, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("header %v: %v", i, err)
+		}
+		var h wire.BlockHeader
+		err = h.Deserialize(bytes.NewReader(hb))
+		if err != nil {
+			return nil, fmt.Errorf("header %v: %v", i, err)
+		}
+		headers = append(headers, h)
+	}
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Height < headers[j].Height
+	})
+	heights := make(map[chainhash.Hash]uint32, len(headers))
+	for i, h := range headers {
+		hash := h.BlockHash()
+		powHash, err := powHash(&h, params)
+		if err != nil {
+			return nil, fmt.Errorf("header %v: %v", h.Height, err)
+		}
+		err = standalone.CheckProofOfWork(&powHash, h.Bits, params.powLimit)
+		if err != nil {
+			return nil, fmt.Errorf("header %v: invalid proof of work: %v",
+				h.Height, err)
+		}
+		if i > 0 {
+			prev := headers[i-1]
+			if h.Height != prev.Height+1 || h.PrevBlock != prev.BlockHash() {
+				return nil, fmt.Errorf("header %v does not connect to "+
+					"header %v", h.Height, prev.Height)
+			}
+		}
+		heights[hash] = h.Height
+	}
+
+	// Verify the chain tip
+	tip := headers[len(headers)-1]
+	if tip.BlockHash() != *wantTip {
+		return nil, fmt.Errorf("chain tip %v at height %v does not match "+
+			"the trusted tip %v", tip.BlockHash(), tip.Height, wantTip)
+	}
+
+	// Decode and verify the blocks
+	as := anchorStore{
+		tip:     tip.Height,
+		tipHash: tip.BlockHash(),
+		txs:     make(map[chainhash.Hash]anchorTx),
+	}
+	for i, v := range cf.Blocks {
+		bb, err := hex.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("block %v: %v", i, err)
+		}
+		var block wire.MsgBlock
+		err = block.Deserialize(bytes.NewReader(bb))
+		if err != nil {
+			return nil, fmt.Errorf("block %v: %v", i, err)
+		}
+		hash := block.BlockHash()
+		height, ok := heights[hash]
+		if !ok {
+			return nil, fmt.Errorf("block %v is not part of the chain", hash)
+		}
+
+		// The header commits to the regular transaction tree prior to
+		// the activation of DCP0005 and to both transaction trees after.
+		mr := block.Header.MerkleRoot
+		if standalone.CalcTxTreeMerkleRoot(block.Transactions) != mr &&
+			standalone.CalcCombinedTxTreeMerkleRoot(block.Transactions,
+				block.STransactions) != mr {
+			return nil, fmt.Errorf("block %v: invalid merkle root", hash)
+		}
+
+		for _, tx := range block.Transactions {
+			as.txs[tx.TxHash()] = anchorTx{
+				tx:     tx,
+				height: height,
+			}
+		}
+	}
+
+	return &as, nil
+}
+
+// powHash returns the hash of the provided header that must satisfy the proof
+// of work target. This is the block hash prior to the activation of DCP0011
+// and the BLAKE3 hash of the serialized header after.
+func powHash(h *wire.BlockHeader, params powParams) (chainhash.Hash, error) {
+	if h.Height < params.blake3Height {
+		return h.BlockHash(), nil
+	}
+	b, err := h.Bytes()
+	if err != nil {
+		return chainhash.Hash{}, err
+	}
+	return chainhash.Hash(blake3.Sum256(b)), nil
+}
+
+// verify verifies that the provided merkle root is included in the OP_RETURN
+// of the provided transaction and that the transaction has at least the
+// provided number of confirmations. The number of confirmations is returned.
+func (s *anchorStore) verify(a anchor, confirmations uint32) (uint32, error) {
+	txid, err := chainhash.NewHashFromStr(a.TxID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid tx id: %v", err)
+	}
+	root, err := hex.DecodeString(a.MerkleRoot)
+	if err != nil || len(root) != 32 {
+		return 0, fmt.Errorf("invalid merkle root %v", a.MerkleRoot)
+	}
+	atx, ok := s.txs[*txid]
+	if !ok {
+		return 0, fmt.Errorf("tx %v not found in chain file", a.TxID)
+	}
+
+	// Look for an OP_RETURN output that contains the merkle root
+	script := append([]byte{opReturn, opData32}, root...)
+	var found bool
+	for _, v := range atx.tx.TxOut {
+		if bytes.Equal(v.PkScript, script) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return 0, fmt.Errorf("merkle root %v not found in tx %v",
+			a.MerkleRoot, a.TxID)
+	}
+
+	depth := s.tip - atx.height + 1
+	if depth < confirmations {
+		return depth, fmt.Errorf("tx %v has %v confirmations, want %v",
+			a.TxID, depth, confirmations)
+	}
+
+	return depth, nil
+}
+
+// anchorsAdd adds an anchor to the provided anchors. Timestamps that have not
+// been anchored yet and duplicate anchors are ignored.
+func anchorsAdd(anchors []anchor, txID, merkleRoot string) []anchor {
+	if txID == "" {
+		return anchors
+	}
+	a := anchor{
+		TxID:       txID,
+		MerkleRoot: merkleRoot,
+	}
+	for _, v := range anchors {
+		if v == a {
+			return anchors
+		}
+	}
+	return append(anchors, a)
+}
+
+// recordAnchors returns the anchors of a records v1 TimestampsReply.
+func recordAnchors(tr rcv1.TimestampsReply) []anchor {
+	anchors := anchorsAdd(nil, tr.RecordMetadata.TxID,
+		tr.RecordMetadata.MerkleRoot)
+	for _, streams := range tr.Metadata {
+		for _, v := range streams {
+			anchors = anchorsAdd(anchors, v.TxID, v.MerkleRoot)
+		}
+	}
+	for _, v := range tr.Files {
+		anchors = anchorsAdd(anchors, v.TxID, v.MerkleRoot)
+	}
+	return anchors
+}
+
+// commentAnchors returns the anchors of a comments v1 TimestampsReply.
+func commentAnchors(tr cmv1.TimestampsReply) []anchor {
+	var anchors []anchor
+	for _, ct := range tr.Comments {
+		for _, v := range ct.Adds {
+			anchors = anchorsAdd(anchors, v.TxID, v.MerkleRoot)
+		}
+		if ct.Del != nil {
+			anchors = anchorsAdd(anchors, ct.Del.TxID, ct.Del.MerkleRoot)
+		}
+	}
+	return anchors
+}
+
+// voteAnchors returns the anchors of a ticketvote v1 TimestampsReply.
+func voteAnchors(tr tkv1.TimestampsReply) []anchor {
+	var anchors []anchor
+	for _, v := range tr.Auths {
+		anchors = anchorsAdd(anchors, v.TxID, v.MerkleRoot)
+	}
+	if tr.Details != nil {
+		anchors = anchorsAdd(anchors, tr.Details.TxID, tr.Details.MerkleRoot)
+	}
+	for _, v := range tr.Votes {
+		anchors = anchorsAdd(anchors, v.TxID, v.MerkleRoot)
+	}
+	return anchors
+}
+
+// verifyAnchors verifies the provided anchors against the chain file that was
+// provided by the user. The chain tip must match the tip hash that was
+// provided by the user. This is a no-op when a chain file was not provided.
+func verifyAnchors(anchors []anchor) error {
+	if *chain == "" {
+		return nil
+	}
+	s, err := loadChainFile(*chain, mainNetPowParams, *tipHash)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Chain tip %v at height %v\n", s.tipHash, s.tip)
+	for _, v := range anchors {
+		depth, err := s.verify(v, uint32(*confirmations))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Anchor %v\n", v.MerkleRoot)
+		fmt.Printf("  DCR tx       : %v\n", v.TxID)
+		fmt.Printf("  Confirmations: %v\n", depth)
+	}
+	fmt.Printf("Anchors verified against %v!\n", *chain)
+	return nil
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/blockchain/standalone/v2"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+)
+
+var (
+	// testPowLimit is the proof of work limit that is used in the tests.
+	// Half of all hashes satisfy it so that the test headers can be mined
+	// quickly.
+	testPowLimit = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255),
+		big.NewInt(1))
+
+	// testPowParams are the proof of work parameters that are used in the
+	// tests. DCP0011 activates at height 2.
+	testPowParams = powParams{
+		powLimit:     testPowLimit,
+		blake3Height: 2,
+	}
+)
+
+// mineHeader increments the nonce of the provided header until the accept
+// function returns true for it.
+func mineHeader(t *testing.T, h *wire.BlockHeader, accept func(*wire.BlockHeader) bool) {
+	t.Helper()
+
+	for i := 0; i < 1000; i++ {
+		if accept(h) {
+			return
+		}
+		h.Nonce++
+	}
+	t.Fatalf("could not mine header %v", h.Height)
+}
+
+// validPow returns whether the header satisfies the proof of work rules of
+// the provided parameters.
+func validPow(params powParams) func(*wire.BlockHeader) bool {
+	return func(h *wire.BlockHeader) bool {
+		hash, err := powHash(h, params)
+		if err != nil {
+			return false
+		}
+		return standalone.CheckProofOfWork(&hash, h.Bits,
+			params.powLimit) == nil
+	}
+}
+
+// newTestHeader returns a header at the provided height that connects to the
+// provided previous header.
+func newTestHeader(prev *wire.BlockHeader, height uint32) wire.BlockHeader {
+	h := wire.BlockHeader{
+		Version:   1,
+		Bits:      standalone.BigToCompact(testPowLimit),
+		Height:    height,
+		Timestamp: time.Unix(int64(height), 0),
+	}
+	if prev != nil {
+		h.PrevBlock = prev.BlockHash()
+	}
+	return h
+}
+
+// writeChainFile writes the provided headers and blocks to a chain file and
+// returns the file path.
+func writeChainFile(t *testing.T, headers []wire.BlockHeader, blocks []wire.MsgBlock) string {
+	t.Helper()
+
+	var cf chainFile
+	for _, h := range headers {
+		b, err := h.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf.Headers = append(cf.Headers, hex.EncodeToString(b))
+	}
+	for _, v := range blocks {
+		b, err := v.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf.Blocks = append(cf.Blocks, hex.EncodeToString(b))
+	}
+	b, err := json.Marshal(cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := filepath.Join(t.TempDir(), "chain.json")
+	err = ioutil.WriteFile(fp, b, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fp
+}
+
+func TestLoadChainFile(t *testing.T) {
+	// Setup an anchor transaction
+	root := chainhash.HashH([]byte("root"))
+	tx := wire.NewMsgTx()
+	tx.AddTxOut(wire.NewTxOut(0, append([]byte{opReturn, opData32},
+		root[:]...)))
+	a := anchor{
+		TxID:       tx.TxHash().String(),
+		MerkleRoot: hex.EncodeToString(root[:]),
+	}
+
+	// Setup a chain that spans the activation of DCP0011. The anchor
+	// is included in the block at height 1.
+	var headers []wire.BlockHeader
+	var block wire.MsgBlock
+	for i := uint32(0); i < 4; i++ {
+		var prev *wire.BlockHeader
+		if i > 0 {
+			prev = &headers[i-1]
+		}
+		h := newTestHeader(prev, i)
+		if i == 1 {
+			h.MerkleRoot = standalone.CalcTxTreeMerkleRoot(
+				[]*wire.MsgTx{tx})
+		}
+		mineHeader(t, &h, validPow(testPowParams))
+		if i == 1 {
+			block = wire.MsgBlock{
+				Header:       h,
+				Transactions: []*wire.MsgTx{tx},
+			}
+		}
+		headers = append(headers, h)
+	}
+	valid := writeChainFile(t, headers, []wire.MsgBlock{block})
+
+	// Setup a chain where the header after the activation of DCP0011
+	// only satisfies the pre-DCP0011 proof of work hash.
+	blake256Only := make([]wire.BlockHeader, 2)
+	copy(blake256Only, headers[:2])
+	h := newTestHeader(&blake256Only[1], 2)
+	mineHeader(t, &h, func(h *wire.BlockHeader) bool {
+		blake256 := testPowParams
+		blake256.blake3Height = 100
+		return validPow(blake256)(h) && !validPow(testPowParams)(h)
+	})
+	blake256Only = append(blake256Only, h)
+	blake256OnlyFile := writeChainFile(t, blake256Only, nil)
+
+	// Setup a chain that does not connect
+	disconnected := make([]wire.BlockHeader, len(headers))
+	copy(disconnected, headers)
+	disconnected[2].PrevBlock = chainhash.Hash{}
+	mineHeader(t, &disconnected[2], validPow(testPowParams))
+	disconnectedFile := writeChainFile(t, disconnected, nil)
+
+	// The headers commit to a target that exceeds this limit
+	strict := testPowParams
+	strict.powLimit = new(big.Int).Rsh(testPowLimit, 1)
+
+	// The chain tip hashes that the chain files are verified against
+	tip := headers[len(headers)-1].BlockHash().String()
+	otherTip := headers[len(headers)-2].BlockHash().String()
+
+	var tests = []struct {
+		name    string
+		fp      string
+		params  powParams
+		tipHash string
+		wantErr string
+	}{
+		{"valid", valid, testPowParams, tip, ""},
+		{"tip hash not provided", valid, testPowParams, "",
+			"tip hash not provided"},
+		{"tip hash mismatch", valid, testPowParams, otherTip,
+			"does not match the trusted tip"},
+		{"target above pow limit", valid, strict, tip,
+			"invalid proof of work"},
+		{"blake256 after dcp0011", blake256OnlyFile, testPowParams, tip,
+			"invalid proof of work"},
+		{"disconnected", disconnectedFile, testPowParams, tip,
+			"does not connect"},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			s, err := loadChainFile(v.fp, v.params, v.tipHash)
+			switch {
+			case v.wantErr == "" && err != nil:
+				t.Fatalf("got error %v, want nil", err)
+			case v.wantErr != "" && (err == nil ||
+				!strings.Contains(err.Error(), v.wantErr)):
+				t.Fatalf("got error %v, want %v", err, v.wantErr)
+			case v.wantErr != "":
+				return
+			}
+
+			// Verify the anchor
+			depth, err := s.verify(a, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if depth != 3 {
+				t.Errorf("got %v confirmations, want 3", depth)
+			}
+			_, err = s.verify(a, 4)
+			if err == nil {
+				t.Errorf("got nil error, want confirmations error")
+			}
+		})
+	}
+}
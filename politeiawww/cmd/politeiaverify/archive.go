@@ -0,0 +1,616 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	backend "github.com/decred/politeia/politeiad/backendv2"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/client"
+	"github.com/decred/politeia/util"
+)
+
+// archiveReport prints the outcome of the archive verification checks and
+// keeps track of the results.
+type archiveReport struct {
+	passed         int
+	failed         int
+	notTimestamped int
+}
+
+// section prints the header of a new report section.
+func (r *archiveReport) section(name string) {
+	fmt.Printf("\n%v\n", name)
+}
+
+// check records the result of a verification check.
+func (r *archiveReport) check(desc string, err error) {
+	if err != nil {
+		r.failed++
+		fmt.Printf("  FAIL  %v: %v\n", desc, err)
+		return
+	}
+	r.passed++
+	fmt.Printf("  ok    %v\n", desc)
+}
+
+// timestamp records the result of a timestamp verification check. Data that
+// has not been anchored onto the DCR blockchain yet is not considered a
+// failure.
+func (r *archiveReport) timestamp(desc string, err error) {
+	if err == backend.ErrNotTimestamped {
+		r.notTimestamped++
+		fmt.Printf("  wait  %v: not timestamped yet\n", desc)
+		return
+	}
+	r.check(desc, err)
+}
+
+// commentVoteVerify verifies the signature and receipt of a comment vote.
+func commentVoteVerify(v cmv1.CommentVote, serverPublicKey string) error {
+	msg := strconv.FormatUint(uint64(v.State), 10) + v.Token +
+		strconv.FormatUint(uint64(v.CommentID), 10) +
+		strconv.FormatInt(int64(v.Vote), 10)
+	err := util.VerifySignature(v.Signature, v.PublicKey, msg)
+	if err != nil {
+		return fmt.Errorf("verify signature: %v", err)
+	}
+	err = util.VerifySignature(v.Receipt, serverPublicKey, v.Signature)
+	if err != nil {
+		return fmt.Errorf("verify receipt: %v", err)
+	}
+	return nil
+}
+
+// billingStatusChangeVerify verifies the signature and receipt of a billing
+// status change.
+func billingStatusChangeVerify(bsc piv1.BillingStatusChange, serverPublicKey string) error {
+	if _, ok := piv1.BillingStatuses[bsc.Status]; !ok ||
+		bsc.Status == piv1.BillingStatusInvalid {
+		return fmt.Errorf("invalid billing status %v", bsc.Status)
+	}
+	msg := bsc.Token + strconv.FormatUint(uint64(bsc.Status), 10) + bsc.Reason
+	err := util.VerifySignature(bsc.Signature, bsc.PublicKey, msg)
+	if err != nil {
+		return fmt.Errorf("verify signature: %v", err)
+	}
+	err = util.VerifySignature(bsc.Receipt, serverPublicKey, bsc.Signature)
+	if err != nil {
+		return fmt.Errorf("verify receipt: %v", err)
+	}
+	return nil
+}
+
+// voteApproved returns whether the provided cast votes meet the quorum and
+// pass thresholds of the vote. The approval is calculated the same way the
+// ticketvote plugin calculates it.
+func voteApproved(vd tkv1.VoteDetails, votes []tkv1.CastVoteDetails) bool {
+	var approveBit uint64
+	for _, v := range vd.Params.Options {
+		if v.ID == tkv1.VoteOptionIDApprove {
+			approveBit = v.Bit
+		}
+	}
+	var total, approved uint64
+	for _, v := range votes {
+		total++
+		bit, err := strconv.ParseUint(v.VoteBit, 16, 64)
+		if err == nil && bit == approveBit {
+			approved++
+		}
+	}
+	var (
+		eligible   = float64(len(vd.EligibleTickets))
+		quorumPerc = float64(vd.Params.QuorumPercentage)
+		passPerc   = float64(vd.Params.PassPercentage)
+		quorum     = uint64(quorumPerc / 100 * eligible)
+		pass       = uint64(passPerc / 100 * float64(total))
+	)
+	return total >= quorum && approved >= pass
+}
+
+// verifyArchiveRecords verifies every record version of the archive and
+// returns the most recent version of the record.
+func verifyArchiveRecords(r *archiveReport, ab client.ProposalArchive) (*rcv1.Record, error) {
+	if len(ab.Records) == 0 {
+		return nil, fmt.Errorf("archive does not contain any records")
+	}
+	sort.Slice(ab.Records, func(i, j int) bool {
+		return ab.Records[i].Record.Version < ab.Records[j].Record.Version
+	})
+	latest := ab.Records[len(ab.Records)-1].Record
+	token := latest.CensorshipRecord.Token
+
+	r.section("Records")
+	for i, v := range ab.Records {
+		rc := v.Record
+		desc := fmt.Sprintf("version %v", rc.Version)
+
+		// Verify the versions are sequential and belong to the same
+		// record.
+		var err error
+		switch {
+		case rc.Version != uint32(i+1):
+			err = fmt.Errorf("got version %v, want %v", rc.Version, i+1)
+		case rc.CensorshipRecord.Token != token:
+			err = fmt.Errorf("token %v does not match %v",
+				rc.CensorshipRecord.Token, token)
+		}
+		r.check(desc+" belongs to proposal", err)
+
+		// Verify the censorship record and user signatures
+		r.check(desc+" censorship record",
+			client.RecordVerify(rc, ab.ServerPublicKey))
+		um, err := client.UserMetadataDecode(rc.Metadata)
+		if err == nil {
+			err = client.UserMetadataVerify(*um, rc.CensorshipRecord.Merkle)
+		}
+		r.check(desc+" author signature", err)
+
+		schanges, err := client.StatusChangesDecode(rc.Metadata)
+		if err == nil {
+			err = client.StatusChangesVerify(schanges)
+		}
+		r.check(desc+" status change signatures", err)
+		for _, sc := range schanges {
+			fmt.Printf("        status %v (version %v)\n",
+				rcv1.RecordStatuses[sc.Status], sc.Version)
+			var err error
+			switch {
+			case sc.Token != token:
+				err = fmt.Errorf("token %v does not match %v", sc.Token, token)
+			case sc.Version > rc.Version:
+				err = fmt.Errorf("status change version %v is newer than "+
+					"the record", sc.Version)
+			}
+			if err != nil {
+				r.check(desc+" status change", err)
+			}
+		}
+
+		// Verify the timestamps of this version
+		if v.Timestamps == nil {
+			r.timestamp(desc+" timestamps", backend.ErrNotTimestamped)
+			continue
+		}
+		if v.Timestamps.RecordMetadata.TxID == "" {
+			r.timestamp(desc+" timestamps", backend.ErrNotTimestamped)
+			continue
+		}
+		var rm backend.RecordMetadata
+		err = json.Unmarshal([]byte(v.Timestamps.RecordMetadata.Data), &rm)
+		if err == nil {
+			switch {
+			case rm.Token != token:
+				err = fmt.Errorf("timestamped token %v does not match %v",
+					rm.Token, token)
+			case rm.Version != rc.Version:
+				err = fmt.Errorf("timestamped version %v does not match %v",
+					rm.Version, rc.Version)
+			case rm.Merkle != rc.CensorshipRecord.Merkle:
+				err = fmt.Errorf("timestamped merkle root %v does not match "+
+					"the censorship record", rm.Merkle)
+			}
+		}
+		r.check(desc+" timestamps match record", err)
+		r.timestamp(desc+" timestamps (DCR tx "+
+			v.Timestamps.RecordMetadata.TxID+")",
+			client.RecordTimestampsVerify(*v.Timestamps))
+	}
+
+	return &latest, nil
+}
+
+// verifyArchiveComments verifies the comments, comment votes, and comment
+// timestamps of the archive.
+func verifyArchiveComments(r *archiveReport, ab client.ProposalArchive, token string) {
+	r.section("Comments")
+	if len(ab.Comments) == 0 {
+		fmt.Printf("  No comments found\n")
+		return
+	}
+
+	comments := make(map[uint32]struct{}, len(ab.Comments))
+	for _, v := range ab.Comments {
+		comments[v.CommentID] = struct{}{}
+	}
+	var dels int
+	for _, v := range ab.Comments {
+		desc := fmt.Sprintf("comment %v", v.CommentID)
+		err := client.CommentVerify(v, ab.ServerPublicKey)
+		if err == nil && v.Token != token {
+			err = fmt.Errorf("token %v does not match %v", v.Token, token)
+		}
+		if err == nil && v.ParentID != 0 {
+			if _, ok := comments[v.ParentID]; !ok {
+				err = fmt.Errorf("parent comment %v not found", v.ParentID)
+			}
+		}
+		r.check(desc, err)
+		if v.Deleted {
+			dels++
+		}
+	}
+	fmt.Printf("        %v comments, %v deleted\n", len(ab.Comments), dels)
+
+	for _, v := range ab.CommentVotes {
+		desc := fmt.Sprintf("comment %v vote by %v", v.CommentID, v.UserID)
+		err := commentVoteVerify(v, ab.ServerPublicKey)
+		if err == nil && v.Token != token {
+			err = fmt.Errorf("token %v does not match %v", v.Token, token)
+		}
+		if err == nil {
+			if _, ok := comments[v.CommentID]; !ok {
+				err = fmt.Errorf("comment not found")
+			}
+		}
+		r.check(desc, err)
+	}
+
+	if ab.CommentTimestamps == nil {
+		r.timestamp("comment timestamps", backend.ErrNotTimestamped)
+		return
+	}
+	var err error
+	for cid := range ab.CommentTimestamps.Comments {
+		if _, ok := comments[cid]; !ok {
+			err = fmt.Errorf("timestamp for unknown comment %v", cid)
+			break
+		}
+	}
+	if err == nil && len(ab.CommentTimestamps.Comments) != len(comments) {
+		err = fmt.Errorf("got %v comment timestamps, want %v",
+			len(ab.CommentTimestamps.Comments), len(comments))
+	}
+	r.check("comment timestamps match comments", err)
+	notTimestamped, err := client.CommentTimestampsVerify(*ab.CommentTimestamps)
+	r.check("comment timestamps", err)
+	for _, cid := range notTimestamped {
+		r.timestamp(fmt.Sprintf("comment %v timestamp", cid),
+			backend.ErrNotTimestamped)
+	}
+}
+
+// verifyArchiveVote verifies the vote authorizations, vote details, cast
+// votes, and vote timestamps of the archive. The vote is checked to have been
+// held on the most recent version of the record.
+func verifyArchiveVote(r *archiveReport, ab client.ProposalArchive, latest rcv1.Record) {
+	token := latest.CensorshipRecord.Token
+
+	r.section("Vote")
+	if len(ab.VoteAuths) == 0 && ab.VoteDetails == nil {
+		fmt.Printf("  Vote has not been authorized or started\n")
+		return
+	}
+
+	for i, v := range ab.VoteAuths {
+		desc := fmt.Sprintf("authorization %v (%v version %v)", i, v.Action,
+			v.Version)
+		err := client.AuthDetailsVerify(v, ab.ServerPublicKey)
+		if err == nil && v.Token != token {
+			err = fmt.Errorf("token %v does not match %v", v.Token, token)
+		}
+		r.check(desc, err)
+	}
+	if len(ab.VoteAuths) > 0 {
+		// Only the most recent authorization is required to be for
+		// the most recent record version. Previous authorizations may
+		// have been revoked prior to the record being edited.
+		a := ab.VoteAuths[len(ab.VoteAuths)-1]
+		var err error
+		if a.Version != latest.Version {
+			err = fmt.Errorf("authorized version %v, record version %v",
+				a.Version, latest.Version)
+		}
+		r.check("authorization matches record version", err)
+	}
+
+	if ab.VoteDetails == nil {
+		fmt.Printf("  Vote has not been started\n")
+		return
+	}
+	vd := *ab.VoteDetails
+	r.check("vote details", client.VoteDetailsVerify(vd, ab.ServerPublicKey))
+
+	var err error
+	switch {
+	case vd.Params.Token != token:
+		err = fmt.Errorf("token %v does not match %v", vd.Params.Token, token)
+	case vd.Params.Version != latest.Version:
+		err = fmt.Errorf("vote version %v, record version %v",
+			vd.Params.Version, latest.Version)
+	}
+	r.check("vote details match record version", err)
+
+	if vd.Params.Type == tkv1.VoteTypeStandard {
+		err = nil
+		switch {
+		case len(ab.VoteAuths) == 0:
+			err = fmt.Errorf("vote authorization not found")
+		case ab.VoteAuths[len(ab.VoteAuths)-1].Action !=
+			string(tkv1.AuthActionAuthorize):
+			err = fmt.Errorf("vote authorization was revoked")
+		}
+		r.check("vote was authorized", err)
+	}
+
+	// Verify cast votes. This includes verifying the cast vote
+	// signature, receipt, verifying that the ticket is eligible to
+	// vote, that the vote bit is a valid vote option, and that the
+	// vote is not a duplicate.
+	var (
+		eligible = make(map[string]struct{}, len(vd.EligibleTickets))
+		bits     = make(map[uint64]struct{}, len(vd.Params.Options))
+		dups     = make(map[string]struct{}, len(ab.CastVotes))
+		invalid  int
+	)
+	for _, v := range vd.EligibleTickets {
+		eligible[v] = struct{}{}
+	}
+	for _, v := range vd.Params.Options {
+		bits[v.Bit] = struct{}{}
+	}
+	for _, v := range ab.CastVotes {
+		err := client.CastVoteDetailsVerify(v, ab.ServerPublicKey)
+		if err == nil && v.Token != token {
+			err = fmt.Errorf("token %v does not match %v", v.Token, token)
+		}
+		if err == nil {
+			if _, ok := eligible[v.Ticket]; !ok {
+				err = fmt.Errorf("ticket not eligible")
+			}
+		}
+		if err == nil {
+			bit, perr := strconv.ParseUint(v.VoteBit, 16, 64)
+			if _, ok := bits[bit]; perr != nil || !ok {
+				err = fmt.Errorf("invalid vote bit %v", v.VoteBit)
+			}
+		}
+		if err == nil {
+			if _, ok := dups[v.Ticket]; ok {
+				err = fmt.Errorf("duplicate vote")
+			}
+		}
+		dups[v.Ticket] = struct{}{}
+		if err != nil {
+			// Only report failed votes individually. An archive can
+			// contain tens of thousands of votes.
+			invalid++
+			r.check("cast vote "+v.Ticket, err)
+		}
+	}
+	err = nil
+	if invalid > 0 {
+		err = fmt.Errorf("%v invalid votes", invalid)
+	}
+	r.check(fmt.Sprintf("cast votes %v/%v", len(ab.CastVotes),
+		len(eligible)), err)
+
+	// Verify vote timestamps
+	if ab.VoteTimestamps == nil {
+		r.timestamp("vote timestamps", backend.ErrNotTimestamped)
+		return
+	}
+	tr := *ab.VoteTimestamps
+	err = nil
+	switch {
+	case len(tr.Auths) != len(ab.VoteAuths):
+		err = fmt.Errorf("got %v authorization timestamps, want %v",
+			len(tr.Auths), len(ab.VoteAuths))
+	case tr.Details == nil:
+		err = fmt.Errorf("vote details timestamp not found")
+	case len(tr.Votes) != len(ab.CastVotes):
+		err = fmt.Errorf("got %v cast vote timestamps, want %v",
+			len(tr.Votes), len(ab.CastVotes))
+	}
+	r.check("vote timestamps match vote", err)
+	for i, v := range tr.Auths {
+		r.timestamp(fmt.Sprintf("authorization %v timestamp", i),
+			client.TicketVoteTimestampVerify(v))
+	}
+	if tr.Details != nil {
+		r.timestamp("vote details timestamp",
+			client.TicketVoteTimestampVerify(*tr.Details))
+	}
+	var notTimestamped int
+	err = nil
+	for i, v := range tr.Votes {
+		verr := client.TicketVoteTimestampVerify(v)
+		switch verr {
+		case nil:
+		case backend.ErrNotTimestamped:
+			notTimestamped++
+		default:
+			err = fmt.Errorf("cast vote timestamp %v: %v", i, verr)
+		}
+		if err != nil {
+			break
+		}
+	}
+	r.check("cast vote timestamps", err)
+	if notTimestamped > 0 {
+		r.timestamp(fmt.Sprintf("%v cast vote timestamps", notTimestamped),
+			backend.ErrNotTimestamped)
+	}
+}
+
+// verifyArchiveBilling verifies the billing status changes and the billing
+// status change timestamps of the archive. Billing status changes are only
+// allowed on proposals that were approved by the ticket vote.
+func verifyArchiveBilling(r *archiveReport, ab client.ProposalArchive, token string) {
+	r.section("Billing")
+	if len(ab.BillingStatusChanges) == 0 {
+		fmt.Printf("  No billing status changes found\n")
+		return
+	}
+
+	for i, v := range ab.BillingStatusChanges {
+		desc := fmt.Sprintf("billing status change %v (%v)", i,
+			piv1.BillingStatuses[v.Status])
+		err := billingStatusChangeVerify(v, ab.ServerPublicKey)
+		if err == nil && v.Token != token {
+			err = fmt.Errorf("token %v does not match %v", v.Token, token)
+		}
+		r.check(desc, err)
+	}
+
+	var err error
+	switch {
+	case ab.VoteDetails == nil:
+		err = fmt.Errorf("vote details not found")
+	case !voteApproved(*ab.VoteDetails, ab.CastVotes):
+		err = fmt.Errorf("cast votes do not approve the proposal")
+	}
+	r.check("billing status requires an approved vote", err)
+
+	// Verify the billing status change timestamps. The timestamps and
+	// the billing status changes are both ordered from oldest to newest.
+	if len(ab.BillingStatusTimestamps) == 0 {
+		r.timestamp("billing status change timestamps",
+			backend.ErrNotTimestamped)
+		return
+	}
+	err = nil
+	if len(ab.BillingStatusTimestamps) != len(ab.BillingStatusChanges) {
+		err = fmt.Errorf("got %v billing status change timestamps, want %v",
+			len(ab.BillingStatusTimestamps), len(ab.BillingStatusChanges))
+	}
+	for i, v := range ab.BillingStatusTimestamps {
+		if err != nil {
+			break
+		}
+		var bsc piv1.BillingStatusChange
+		err = json.Unmarshal([]byte(v.Data), &bsc)
+		if err != nil {
+			err = fmt.Errorf("timestamp %v: %v", i, err)
+			break
+		}
+		if bsc.Signature != ab.BillingStatusChanges[i].Signature {
+			err = fmt.Errorf("timestamp %v does not match billing status "+
+				"change %v", i, i)
+		}
+	}
+	r.check("billing status change timestamps match changes", err)
+	for i, v := range ab.BillingStatusTimestamps {
+		r.timestamp(fmt.Sprintf("billing status change %v timestamp", i),
+			client.PiTimestampVerify(v))
+	}
+}
+
+// verifyArchiveAnchors verifies that the merkle roots of all archive
+// timestamps are included in the DCR blockchain data of the chain file that
+// was provided by the user. This is skipped when a chain file was not
+// provided.
+func verifyArchiveAnchors(r *archiveReport, ab client.ProposalArchive) {
+	if *chain == "" {
+		return
+	}
+
+	var anchors []anchor
+	for _, v := range ab.Records {
+		if v.Timestamps == nil {
+			continue
+		}
+		for _, a := range recordAnchors(*v.Timestamps) {
+			anchors = anchorsAdd(anchors, a.TxID, a.MerkleRoot)
+		}
+	}
+	if ab.CommentTimestamps != nil {
+		for _, a := range commentAnchors(*ab.CommentTimestamps) {
+			anchors = anchorsAdd(anchors, a.TxID, a.MerkleRoot)
+		}
+	}
+	if ab.VoteTimestamps != nil {
+		for _, a := range voteAnchors(*ab.VoteTimestamps) {
+			anchors = anchorsAdd(anchors, a.TxID, a.MerkleRoot)
+		}
+	}
+	for _, v := range ab.BillingStatusTimestamps {
+		anchors = anchorsAdd(anchors, v.TxID, v.MerkleRoot)
+	}
+
+	r.section("Anchors")
+	s, err := loadChainFile(*chain, mainNetPowParams, *tipHash)
+	if err != nil {
+		r.check("chain file "+*chain, err)
+		return
+	}
+	r.check(fmt.Sprintf("chain file %v (tip %v at height %v)", *chain,
+		s.tipHash, s.tip), nil)
+	for _, v := range anchors {
+		depth, err := s.verify(v, uint32(*confirmations))
+		r.check(fmt.Sprintf("DCR tx %v (%v confirmations)", v.TxID, depth),
+			err)
+	}
+}
+
+// verifyArchive runs all of the verification checks on the provided archive
+// and records the results in the report. The token of the proposal is
+// returned. An error is only returned if the archive could not be verified at
+// all.
+func verifyArchive(r *archiveReport, ab client.ProposalArchive) (string, error) {
+	latest, err := verifyArchiveRecords(r, ab)
+	if err != nil {
+		return "", err
+	}
+	token := latest.CensorshipRecord.Token
+	verifyArchiveComments(r, ab, token)
+	verifyArchiveVote(r, ab, *latest)
+	verifyArchiveBilling(r, ab, token)
+	verifyArchiveAnchors(r, ab)
+
+	return token, nil
+}
+
+// verifyArchiveBundle takes the filepath of a proposal archive bundle and
+// verifies the full history of the proposal. All signatures, receipts, and
+// timestamps are verified, and the different parts of the archive are checked
+// for consistency with one another, e.g. the vote must have been held on the
+// most recent version of the record. The timestamp anchors are verified
+// against the chain file when one is provided. A verification report is
+// printed and an error is returned if any of the checks failed.
+func verifyArchiveBundle(fp string) error {
+	// Decode archive bundle
+	b, err := ioutil.ReadFile(fp)
+	if err != nil {
+		return err
+	}
+	var ab client.ProposalArchive
+	err = json.Unmarshal(b, &ab)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal archive bundle: %v", err)
+	}
+
+	fmt.Printf("Server public key: %v\n", ab.ServerPublicKey)
+
+	var r archiveReport
+	token, err := verifyArchive(&r, ab)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n")
+	fmt.Printf("Token             : %v\n", token)
+	fmt.Printf("Record versions   : %v\n", len(ab.Records))
+	fmt.Printf("Checks passed     : %v\n", r.passed)
+	fmt.Printf("Checks failed     : %v\n", r.failed)
+	fmt.Printf("Not timestamped   : %v\n", r.notTimestamped)
+
+	if r.failed > 0 {
+		return fmt.Errorf("%v archive checks failed", r.failed)
+	}
+
+	fmt.Printf("Proposal archive verified!\n")
+
+	return nil
+}
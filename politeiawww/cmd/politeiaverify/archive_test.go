@@ -0,0 +1,301 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/decred/politeia/e2e"
+	cmv1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/client"
+)
+
+// newTestArchive creates a proposal on the e2e servers that has comments, a
+// comment vote, an approved vote, and a billing status change, and returns
+// the archive of the proposal.
+func newTestArchive(t *testing.T) *client.ProposalArchive {
+	t.Helper()
+
+	h, cleanup := e2e.New(t)
+	defer cleanup()
+
+	var (
+		admin  = h.NewUser(t, true)
+		author = h.NewUser(t, false)
+		state  = cmv1.RecordStateVetted
+	)
+
+	// Create a proposal with comments and a comment vote
+	token := h.NewProposal(t, author, admin)
+	commentID := h.NewComment(t, admin, token, "An admin comment")
+	h.NewComment(t, author, token, "An author comment")
+	msg := strconv.FormatUint(uint64(state), 10) + token +
+		strconv.FormatUint(uint64(commentID), 10) +
+		strconv.FormatInt(int64(cmv1.VoteUpvote), 10)
+	sig := author.Identity.SignMessage([]byte(msg))
+	_, err := author.Client.CommentVote(cmv1.Vote{
+		State:     state,
+		Token:     token,
+		CommentID: commentID,
+		Vote:      cmv1.VoteUpvote,
+		PublicKey: author.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("CommentVote: %v", err)
+	}
+
+	// Approve the proposal and end the vote
+	h.StartVote(t, author, admin, token)
+	h.CastVotes(t, token, "1", h.EligibleTickets()[:30])
+	dr, err := h.Client.TicketVoteDetails(tkv1.Details{
+		Token: token,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.SetBestBlock(dr.Vote.EndBlockHeight)
+
+	// Set the billing status
+	status := piv1.BillingStatusCompleted
+	msg = token + strconv.FormatUint(uint64(status), 10)
+	sig = admin.Identity.SignMessage([]byte(msg))
+	_, err = admin.Client.PiSetBillingStatus(piv1.SetBillingStatus{
+		Token:     token,
+		Status:    status,
+		PublicKey: admin.Identity.Public.String(),
+		Signature: hex.EncodeToString(sig[:]),
+	})
+	if err != nil {
+		t.Fatalf("PiSetBillingStatus: %v", err)
+	}
+
+	// Create the archive using the short token
+	pa, err := h.Client.ProposalArchive(token[:7], h.PoliteiadPublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pa
+}
+
+func TestVerifyArchive(t *testing.T) {
+	pa := newTestArchive(t)
+
+	// Sanity check the contents of the archive
+	switch {
+	case len(pa.Records) != 1:
+		t.Fatalf("got %v records, want 1", len(pa.Records))
+	case len(pa.Comments) != 2 || len(pa.CommentVotes) != 1 ||
+		pa.CommentTimestamps == nil:
+		t.Fatalf("archive comments data is incomplete")
+	case len(pa.VoteAuths) != 1 || pa.VoteDetails == nil ||
+		len(pa.CastVotes) != 30 || pa.VoteTimestamps == nil ||
+		len(pa.VoteTimestamps.Votes) != 30:
+		t.Fatalf("archive vote data is incomplete")
+	case len(pa.BillingStatusChanges) != 1 ||
+		len(pa.BillingStatusTimestamps) != 1:
+		t.Fatalf("archive billing data is incomplete")
+	}
+	b, err := json.Marshal(pa)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name   string
+		modify func(*client.ProposalArchive)
+		fail   bool
+	}{
+		{
+			"valid archive",
+			func(ab *client.ProposalArchive) {},
+			false,
+		},
+		{
+			"record version not sequential",
+			func(ab *client.ProposalArchive) {
+				ab.Records[0].Record.Version = 2
+			},
+			true,
+		},
+		{
+			"record censorship record tampered",
+			func(ab *client.ProposalArchive) {
+				ab.Records[0].Record.CensorshipRecord.Merkle = ab.ServerPublicKey
+			},
+			true,
+		},
+		{
+			"comment tampered",
+			func(ab *client.ProposalArchive) {
+				ab.Comments[0].Comment += "tampered"
+			},
+			true,
+		},
+		{
+			"comment parent not found",
+			func(ab *client.ProposalArchive) {
+				ab.Comments[0].ParentID = 99
+			},
+			true,
+		},
+		{
+			"comment vote receipt tampered",
+			func(ab *client.ProposalArchive) {
+				ab.CommentVotes[0].Receipt = ab.Comments[0].Receipt
+			},
+			true,
+		},
+		{
+			"comment timestamp missing",
+			func(ab *client.ProposalArchive) {
+				delete(ab.CommentTimestamps.Comments, ab.Comments[0].CommentID)
+			},
+			true,
+		},
+		{
+			"vote authorization tampered",
+			func(ab *client.ProposalArchive) {
+				ab.VoteAuths[0].Action = string(tkv1.AuthActionRevoke)
+			},
+			true,
+		},
+		{
+			"vote details receipt tampered",
+			func(ab *client.ProposalArchive) {
+				ab.VoteDetails.Receipt = ab.VoteAuths[0].Receipt
+			},
+			true,
+		},
+		{
+			"cast vote ticket not eligible",
+			func(ab *client.ProposalArchive) {
+				eligible := make([]string, 0, len(ab.VoteDetails.EligibleTickets))
+				for _, v := range ab.VoteDetails.EligibleTickets {
+					if v != ab.CastVotes[0].Ticket {
+						eligible = append(eligible, v)
+					}
+				}
+				ab.VoteDetails.EligibleTickets = eligible
+			},
+			true,
+		},
+		{
+			"cast vote bit tampered",
+			func(ab *client.ProposalArchive) {
+				ab.CastVotes[0].VoteBit = "2"
+			},
+			true,
+		},
+		{
+			"duplicate cast vote",
+			func(ab *client.ProposalArchive) {
+				ab.CastVotes = append(ab.CastVotes, ab.CastVotes[0])
+			},
+			true,
+		},
+		{
+			"cast vote timestamp missing",
+			func(ab *client.ProposalArchive) {
+				ab.VoteTimestamps.Votes = ab.VoteTimestamps.Votes[1:]
+			},
+			true,
+		},
+		{
+			"billing status change tampered",
+			func(ab *client.ProposalArchive) {
+				ab.BillingStatusChanges[0].Reason = "tampered"
+			},
+			true,
+		},
+		{
+			"billing status change without an approved vote",
+			func(ab *client.ProposalArchive) {
+				// The eligible tickets are not part of the signed
+				// vote params. Adding tickets raises the quorum
+				// above the number of cast votes.
+				for i := 0; i < 200; i++ {
+					ab.VoteDetails.EligibleTickets = append(
+						ab.VoteDetails.EligibleTickets, strconv.Itoa(i))
+				}
+			},
+			true,
+		},
+		{
+			"billing status timestamps not provided",
+			func(ab *client.ProposalArchive) {
+				ab.BillingStatusTimestamps = nil
+			},
+			false,
+		},
+		{
+			"billing status timestamp missing",
+			func(ab *client.ProposalArchive) {
+				ab.BillingStatusChanges = append(ab.BillingStatusChanges,
+					ab.BillingStatusChanges[0])
+			},
+			true,
+		},
+		{
+			"billing status timestamp data mismatch",
+			func(ab *client.ProposalArchive) {
+				bsc := ab.BillingStatusChanges[0]
+				bsc.Signature = ab.VoteAuths[0].Signature
+				b, err := json.Marshal(bsc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ab.BillingStatusTimestamps[0].Data = string(b)
+			},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Modify a copy of the archive
+			var ab client.ProposalArchive
+			err := json.Unmarshal(b, &ab)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.modify(&ab)
+
+			var r archiveReport
+			_, err = verifyArchive(&r, ab)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case tt.fail && r.failed == 0:
+				t.Errorf("got 0 failed checks, want a failure")
+			case !tt.fail && r.failed != 0:
+				t.Errorf("got %v failed checks, want 0", r.failed)
+			}
+			if r.passed == 0 {
+				t.Errorf("got 0 passed checks")
+			}
+
+			// The test servers do not anchor any data
+			if r.notTimestamped == 0 {
+				t.Errorf("got 0 not timestamped checks")
+			}
+		})
+	}
+
+	// An archive without any records cannot be verified
+	var r archiveReport
+	_, err = verifyArchive(&r, client.ProposalArchive{
+		ServerPublicKey: pa.ServerPublicKey,
+	})
+	if err == nil {
+		t.Errorf("got nil error for an archive without records")
+	}
+}
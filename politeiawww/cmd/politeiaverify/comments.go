@@ -114,6 +114,5 @@ func verifyCommentTimestamps(fp string) error {
 
 	fmt.Printf("All timestamps verified!\n")
 
-	return nil
-
+	return verifyAnchors(commentAnchors(tr))
 }
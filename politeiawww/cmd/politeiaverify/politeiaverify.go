@@ -23,6 +23,10 @@ var (
 	publicKey = flag.String("k", "", "server public key")
 	token     = flag.String("t", "", "record censorship token")
 	signature = flag.String("s", "", "record censorship signature")
+
+	chain         = flag.String("chain", "", "chain file used to verify anchors")
+	confirmations = flag.Uint("confirmations", 6, "required anchor confirmations")
+	tipHash       = flag.String("tiphash", "", "trusted hash of the chain file tip")
 )
 
 // loadFiles loads and returns a politeiawww records v1 File for each provided
@@ -117,6 +121,7 @@ var (
 	expCommentTimestamps = `^[0-9a-f]{7,16}-comments-timestamps.json$`
 	expVotes             = `^[0-9a-f]{7,16}-votes.json$`
 	expVoteTimestamps    = `^[0-9a-f]{7,16}-votes-timestamps.json$`
+	expArchive           = `^[0-9a-f]{7,16}-archive.json$`
 
 	regexpJSONFile          = regexp.MustCompile(expJSONFile)
 	regexpRecord            = regexp.MustCompile(expRecord)
@@ -125,6 +130,7 @@ var (
 	regexpCommentTimestamps = regexp.MustCompile(expCommentTimestamps)
 	regexpVotes             = regexp.MustCompile(expVotes)
 	regexpVoteTimestamps    = regexp.MustCompile(expVoteTimestamps)
+	regexpArchive           = regexp.MustCompile(expArchive)
 )
 
 // verifyFile verifies a data file downloaded from politeiagui. This can be
@@ -138,6 +144,7 @@ var (
 // Comment timestamps: [token]-comments-timestamps.json
 // Votes bundle      : [token]-votes.json
 // Vote timestamps   : [token]-votes-timestamps.json
+// Proposal archive  : [token]-archive.json
 func verifyFile(fp string) error {
 	fp = util.CleanAndExpandPath(fp)
 	filename := filepath.Base(fp)
@@ -156,6 +163,8 @@ func verifyFile(fp string) error {
 		return verifyVotesBundle(fp)
 	case regexpVoteTimestamps.FindString(filename) != "":
 		return verifyVoteTimestamps(fp)
+	case regexpArchive.FindString(filename) != "":
+		return verifyArchiveBundle(fp)
 	}
 
 	return fmt.Errorf("file not recognized")
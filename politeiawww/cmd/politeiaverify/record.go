@@ -81,6 +81,16 @@ func verifyRecordBundle(fp string) error {
 	}
 
 	for _, v := range schanges {
+		if ot := v.OwnershipTransfer; ot != nil {
+			fmt.Printf("Ownership transfer: %v to %v\n",
+				ot.FromUserID, ot.ToUserID)
+			fmt.Printf("  Reason     : %v\n", v.Reason)
+			fmt.Printf("  Author key : %v\n", ot.AuthorPublicKey)
+			fmt.Printf("  Author sig : %v\n", ot.AuthorSignature)
+			fmt.Printf("  Public key : %v\n", v.PublicKey)
+			fmt.Printf("  Signature  : %v\n", v.Signature)
+			continue
+		}
 		fmt.Printf("Status change: %v\n", rcv1.RecordStatuses[v.Status])
 		if v.Reason != "" {
 			fmt.Printf("  Reason     : %v\n", v.Reason)
@@ -147,5 +157,5 @@ func verifyRecordTimestamps(fp string) error {
 	fmt.Printf("Record timestamps verified!\n")
 	fmt.Printf("The merkle root can be found in the OP_RETURN of the DCR tx.\n")
 
-	return nil
+	return verifyAnchors(recordAnchors(tr))
 }
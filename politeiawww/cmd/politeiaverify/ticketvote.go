@@ -203,5 +203,5 @@ func verifyVoteTimestamps(fp string) error {
 	}
 	fmt.Printf("Cast vote timestamps verified!\n")
 
-	return nil
+	return verifyAnchors(voteAnchors(tr))
 }
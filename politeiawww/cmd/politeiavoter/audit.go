@@ -0,0 +1,386 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrdata/v6/api/types"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// dcrdataTxsTrimmed is the dcrdata route that returns the trimmed
+	// transactions for a list of tx IDs.
+	dcrdataTxsTrimmed = "/api/txs/trimmed"
+
+	// dcrdataTxsBatchSize is the maximum number of transactions that are
+	// requested from dcrdata at once.
+	dcrdataTxsBatchSize = 500
+)
+
+// auditTicket contains the vote that is expected to have been cast by a
+// wallet ticket.
+type auditTicket struct {
+	Address string // Ticket commitment address
+	VoteBit string // Intended vote bit
+}
+
+// auditMismatch is a wallet ticket vote that does not match the intended
+// vote.
+type auditMismatch struct {
+	Ticket string
+	Reason string
+}
+
+// auditReport contains the outcome of auditing the cast votes of a proposal.
+type auditReport struct {
+	Votes             int      // Number of cast votes
+	InvalidAddresses  []string // Tickets not signed by the commitment address
+	InvalidSignatures []string // Tickets with an invalid client signature
+	InvalidReceipts   []string // Tickets with an invalid server receipt
+	Duplicates        []string // Tickets that voted more than once
+
+	Tickets    int             // Number of audited wallet tickets
+	Verified   int             // Wallet tickets with a matching vote
+	Missing    []string        // Wallet tickets without a vote
+	Mismatched []auditMismatch // Wallet tickets with a mismatched vote
+}
+
+// failed returns whether the audit found any problems.
+func (r *auditReport) failed() bool {
+	return len(r.InvalidAddresses) != 0 ||
+		len(r.InvalidSignatures) != 0 || len(r.InvalidReceipts) != 0 ||
+		len(r.Duplicates) != 0 || len(r.Missing) != 0 ||
+		len(r.Mismatched) != 0
+}
+
+// auditSignature verifies the client signature of a cast vote against the
+// address that was used to sign it. The caller must verify that the address
+// is the commitment address of the ticket.
+func auditSignature(cvd tkv1.CastVoteDetails) bool {
+	// The signature is hex encoded. The verify message function
+	// expects it to be base64 encoded.
+	b, err := hex.DecodeString(cvd.Signature)
+	if err != nil {
+		return false
+	}
+	msg := cvd.Token + cvd.Ticket + cvd.VoteBit
+	ok, err := verifyMessage(activeNetParams.Params, cvd.Address, msg,
+		base64.StdEncoding.EncodeToString(b))
+	if err != nil {
+		return false
+	}
+	return ok
+}
+
+// auditVotes audits the cast votes of a proposal. Every cast vote is verified
+// to be signed by the commitment address of its ticket, which is looked up in
+// the provided addresses, and the signature and the server receipt of every
+// cast vote are verified. The votes of the provided wallet tickets are
+// verified to be present, to be signed by the commitment address of the
+// ticket, and to vote for the intended vote bit.
+func auditVotes(token string, votes []tkv1.CastVoteDetails, serverPubKey string, addrs map[string]string, tickets map[string]auditTicket) *auditReport {
+	r := auditReport{
+		Votes:   len(votes),
+		Tickets: len(tickets),
+	}
+	cast := make(map[string]tkv1.CastVoteDetails, len(votes))
+	for _, v := range votes {
+		if _, ok := cast[v.Ticket]; ok {
+			r.Duplicates = append(r.Duplicates, v.Ticket)
+		}
+		cast[v.Ticket] = v
+
+		// The cast vote address is provided by the client. The
+		// signature only proves ownership of the ticket if it is
+		// the commitment address of the ticket.
+		addr, ok := addrs[v.Ticket]
+		switch {
+		case !ok || addr != v.Address:
+			r.InvalidAddresses = append(r.InvalidAddresses, v.Ticket)
+		case v.Token != token || !auditSignature(v):
+			r.InvalidSignatures = append(r.InvalidSignatures, v.Ticket)
+		}
+		err := util.VerifySignature(v.Receipt, serverPubKey, v.Signature)
+		if err != nil {
+			r.InvalidReceipts = append(r.InvalidReceipts, v.Ticket)
+		}
+	}
+
+	// Audit the wallet tickets in a deterministic order
+	sorted := make([]string, 0, len(tickets))
+	for k := range tickets {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, ticket := range sorted {
+		t := tickets[ticket]
+		v, ok := cast[ticket]
+		switch {
+		case !ok:
+			r.Missing = append(r.Missing, ticket)
+		case v.VoteBit != t.VoteBit:
+			r.Mismatched = append(r.Mismatched, auditMismatch{
+				Ticket: ticket,
+				Reason: fmt.Sprintf("vote bit %v, want %v", v.VoteBit,
+					t.VoteBit),
+			})
+		case v.Address != t.Address:
+			r.Mismatched = append(r.Mismatched, auditMismatch{
+				Ticket: ticket,
+				Reason: fmt.Sprintf("address %v, want %v", v.Address,
+					t.Address),
+			})
+		default:
+			r.Verified++
+		}
+	}
+
+	return &r
+}
+
+// largestCommitmentAddr returns the largest commitment address of a ticket.
+// This is the address that a vote must be signed with.
+func largestCommitmentAddr(tx types.TrimmedTx) (string, error) {
+	var (
+		bestAddr string  // Addr with largest commitment amount
+		bestAmt  float64 // Largest commitment amount
+	)
+	for _, vout := range tx.Vout {
+		scriptPubKey := vout.ScriptPubKeyDecoded
+		switch {
+		case scriptPubKey.CommitAmt == nil:
+			// No commitment amount; continue
+		case len(scriptPubKey.Addresses) == 0:
+			// No commitment address; continue
+		case *scriptPubKey.CommitAmt > bestAmt:
+			// New largest commitment address found
+			bestAddr = scriptPubKey.Addresses[0]
+			bestAmt = *scriptPubKey.CommitAmt
+		}
+	}
+	if bestAddr == "" || bestAmt == 0.0 {
+		return "", fmt.Errorf("no largest commitment address found")
+	}
+	return bestAddr, nil
+}
+
+// commitmentAddrs looks up the largest commitment address of each of the
+// provided tickets using dcrdata. A map[ticket]address is returned. Tickets
+// that dcrdata does not return are not included in the map.
+func (p *piv) commitmentAddrs(tickets []string) (map[string]string, error) {
+	addrs := make(map[string]string, len(tickets))
+	for len(tickets) > 0 {
+		batch := tickets
+		if len(batch) > dcrdataTxsBatchSize {
+			batch = batch[:dcrdataTxsBatchSize]
+		}
+		tickets = tickets[len(batch):]
+
+		b, err := json.Marshal(types.Txns{
+			Transactions: batch,
+		})
+		if err != nil {
+			return nil, err
+		}
+		url := p.cfg.Dcrdata + dcrdataTxsTrimmed
+		log.Debugf("Request: %v %v", http.MethodPost, url)
+		req, err := http.NewRequestWithContext(p.ctx, http.MethodPost, url,
+			bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", p.userAgent)
+		r, err := p.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body := util.ConvertBodyToByteArray(r.Body, false)
+		r.Body.Close()
+		if r.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%v %v: %v %s", http.MethodPost, url,
+				r.StatusCode, body)
+		}
+		var txs []types.TrimmedTx
+		err = json.Unmarshal(body, &txs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range txs {
+			addr, err := largestCommitmentAddr(tx)
+			if err != nil {
+				log.Debugf("Ticket %v: %v", tx.TxID, err)
+				continue
+			}
+			addrs[tx.TxID] = addr
+		}
+	}
+	return addrs, nil
+}
+
+// journalVoteBits returns the vote bits that were scheduled to be cast on a
+// proposal according to the work journals of the proposal.
+func (p *piv) journalVoteBits(token string) (map[string]string, error) {
+	dir := filepath.Join(p.cfg.voteDir, token)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	work := make(map[string][]workTuple)
+	for _, v := range files {
+		if !strings.HasPrefix(v.Name(), workJournal) {
+			continue
+		}
+		err := decodeWork(filepath.Join(dir, v.Name()), work)
+		if err != nil {
+			return nil, err
+		}
+	}
+	voteBits := make(map[string]string)
+	for _, wts := range work {
+		for _, wt := range wts {
+			for _, va := range wt.Votes {
+				voteBits[va.Vote.Ticket] = va.Vote.VoteBit
+			}
+		}
+	}
+	return voteBits, nil
+}
+
+// audit audits the votes that have been cast on a proposal.
+func (p *piv) audit(args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return fmt.Errorf("audit: invalid number of arguments %v", args)
+	}
+	token := args[0]
+
+	// Get server public key.
+	version, err := p.getVersion()
+	if err != nil {
+		return err
+	}
+	dr, err := p.voteDetails(token, version.PubKey)
+	if err != nil {
+		return err
+	}
+	if dr.Vote == nil {
+		return fmt.Errorf("proposal vote has not started: %v", token)
+	}
+
+	// Lookup the intended vote bits. The provided vote option applies
+	// to all wallet tickets. The work journals are used otherwise.
+	var (
+		voteBitAll string
+		voteBits   map[string]string
+	)
+	if len(args) == 2 {
+		voteBitAll, err = voteBit(dr.Vote.Params, args[1])
+		if err != nil {
+			return err
+		}
+	} else {
+		voteBits, err = p.journalVoteBits(token)
+		if err != nil {
+			return fmt.Errorf("no vote journals found for %v; provide "+
+				"the vote id: %v", token, err)
+		}
+	}
+
+	// Lookup the wallet tickets
+	tix, err := convertTicketHashes(dr.Vote.EligibleTickets)
+	if err != nil {
+		return fmt.Errorf("ticket pool corrupt: %v %v", token, err)
+	}
+	ctres, err := p.signer.CommittedTickets(p.ctx, tix)
+	if err != nil {
+		return fmt.Errorf("ticket pool verification: %v %v", token, err)
+	}
+	tickets := make(map[string]auditTicket, len(ctres.TicketAddresses))
+	for _, v := range ctres.TicketAddresses {
+		h, err := chainhash.NewHash(v.Ticket)
+		if err != nil {
+			return err
+		}
+		vb := voteBitAll
+		if vb == "" {
+			var ok bool
+			vb, ok = voteBits[h.String()]
+			if !ok {
+				// Ticket was not scheduled to vote
+				continue
+			}
+		}
+		tickets[h.String()] = auditTicket{
+			Address: v.Address,
+			VoteBit: vb,
+		}
+	}
+
+	// Lookup the commitment addresses of the tickets that voted
+	rr, err := p._voteResults(token)
+	if err != nil {
+		return err
+	}
+	voted := make([]string, 0, len(rr.Votes))
+	for _, v := range rr.Votes {
+		voted = append(voted, v.Ticket)
+	}
+	addrs, err := p.commitmentAddrs(voted)
+	if err != nil {
+		return fmt.Errorf("commitment addresses: %v", err)
+	}
+
+	// Audit the cast votes
+	r := auditVotes(token, rr.Votes, version.PubKey, addrs, tickets)
+
+	fmt.Printf("Audit: %v\n", token)
+	fmt.Printf("  Cast votes        : %v\n", r.Votes)
+	fmt.Printf("  Invalid addresses : %v\n", len(r.InvalidAddresses))
+	fmt.Printf("  Invalid signatures: %v\n", len(r.InvalidSignatures))
+	fmt.Printf("  Invalid receipts  : %v\n", len(r.InvalidReceipts))
+	fmt.Printf("  Duplicate votes   : %v\n", len(r.Duplicates))
+	fmt.Printf("  Wallet tickets    : %v\n", r.Tickets)
+	fmt.Printf("  Votes verified    : %v\n", r.Verified)
+	fmt.Printf("  Votes missing     : %v\n", len(r.Missing))
+	fmt.Printf("  Votes mismatched  : %v\n", len(r.Mismatched))
+	for _, v := range r.InvalidAddresses {
+		fmt.Printf("Invalid address   : %v\n", v)
+	}
+	for _, v := range r.InvalidSignatures {
+		fmt.Printf("Invalid signature : %v\n", v)
+	}
+	for _, v := range r.InvalidReceipts {
+		fmt.Printf("Invalid receipt   : %v\n", v)
+	}
+	for _, v := range r.Duplicates {
+		fmt.Printf("Duplicate vote    : %v\n", v)
+	}
+	for _, v := range r.Missing {
+		fmt.Printf("Missing vote      : %v\n", v)
+	}
+	for _, v := range r.Mismatched {
+		fmt.Printf("Mismatched vote   : %v %v\n", v.Ticket, v.Reason)
+	}
+
+	if r.failed() {
+		return fmt.Errorf("audit failed: %v", token)
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/decred/dcrdata/v6/api/types"
+	"github.com/decred/politeia/politeiad/api/v1/identity"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+func TestAuditVotes(t *testing.T) {
+	server, err := identity.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverPubKey := server.Public.String()
+	key := newTestKey(t)
+	other := newTestKey(t)
+
+	// castVote returns a cast vote that is signed by the provided key
+	// and that has a valid server receipt.
+	castVote := func(k *testKey, ticket, voteBit string) tkv1.CastVoteDetails {
+		sig := hex.EncodeToString(k.sign("token" + ticket + voteBit))
+		receipt := server.SignMessage([]byte(sig))
+		return tkv1.CastVoteDetails{
+			Token:     "token",
+			Ticket:    ticket,
+			VoteBit:   voteBit,
+			Address:   k.address,
+			Signature: sig,
+			Receipt:   hex.EncodeToString(receipt[:]),
+		}
+	}
+
+	votes := []tkv1.CastVoteDetails{
+		castVote(key, "verified", "2"),
+		castVote(key, "mismatched-bit", "1"),
+		castVote(other, "mismatched-address", "2"),
+		castVote(other, "other", "1"),
+		castVote(key, "bad-signature", "2"),
+		castVote(key, "bad-receipt", "2"),
+		castVote(other, "not-commitment-address", "2"),
+		castVote(key, "unknown-ticket", "2"),
+	}
+	votes[4].VoteBit = "1"
+	votes[5].Receipt = votes[0].Receipt
+
+	// The commitment addresses of the tickets. The vote of the
+	// not-commitment-address ticket has a valid signature, but it
+	// was signed by a different address.
+	addrs := map[string]string{
+		"verified":               key.address,
+		"mismatched-bit":         key.address,
+		"mismatched-address":     other.address,
+		"other":                  other.address,
+		"bad-signature":          key.address,
+		"bad-receipt":            key.address,
+		"not-commitment-address": key.address,
+	}
+	tickets := map[string]auditTicket{
+		"verified":           {Address: key.address, VoteBit: "2"},
+		"mismatched-bit":     {Address: key.address, VoteBit: "2"},
+		"mismatched-address": {Address: key.address, VoteBit: "2"},
+		"missing":            {Address: key.address, VoteBit: "2"},
+	}
+
+	r := auditVotes("token", votes, serverPubKey, addrs, tickets)
+	if r.Votes != len(votes) || r.Tickets != len(tickets) {
+		t.Fatalf("got %v votes %v tickets, want %v %v", r.Votes, r.Tickets,
+			len(votes), len(tickets))
+	}
+	if len(r.InvalidAddresses) != 2 ||
+		r.InvalidAddresses[0] != "not-commitment-address" ||
+		r.InvalidAddresses[1] != "unknown-ticket" {
+		t.Fatalf("invalid addresses: got %v", r.InvalidAddresses)
+	}
+	if len(r.InvalidSignatures) != 1 ||
+		r.InvalidSignatures[0] != "bad-signature" {
+		t.Fatalf("invalid signatures: got %v", r.InvalidSignatures)
+	}
+	if len(r.InvalidReceipts) != 1 || r.InvalidReceipts[0] != "bad-receipt" {
+		t.Fatalf("invalid receipts: got %v", r.InvalidReceipts)
+	}
+	if r.Verified != 1 {
+		t.Fatalf("verified: got %v, want 1", r.Verified)
+	}
+	if len(r.Missing) != 1 || r.Missing[0] != "missing" {
+		t.Fatalf("missing: got %v", r.Missing)
+	}
+	if len(r.Mismatched) != 2 ||
+		r.Mismatched[0].Ticket != "mismatched-address" ||
+		r.Mismatched[1].Ticket != "mismatched-bit" {
+		t.Fatalf("mismatched: got %v", r.Mismatched)
+	}
+	if !r.failed() {
+		t.Fatal("expected audit to fail")
+	}
+
+	// Verify a clean audit
+	r = auditVotes("token", votes[:1], serverPubKey, addrs,
+		map[string]auditTicket{
+			"verified": tickets["verified"],
+		})
+	if r.failed() || r.Verified != 1 {
+		t.Fatalf("unexpected audit failure: %+v", r)
+	}
+}
+
+func TestCommitmentAddrs(t *testing.T) {
+	commitAmt := func(amt float64) *float64 { return &amt }
+	txs := map[string]types.TrimmedTx{
+		"ticket1": {
+			TxID: "ticket1",
+			Vout: []types.Vout{
+				{
+					ScriptPubKeyDecoded: types.ScriptPubKey{
+						Addresses: []string{"small"},
+						CommitAmt: commitAmt(1),
+					},
+				},
+				{
+					ScriptPubKeyDecoded: types.ScriptPubKey{
+						Addresses: []string{"largest"},
+						CommitAmt: commitAmt(2),
+					},
+				},
+			},
+		},
+		"ticket2": {
+			TxID: "ticket2",
+			Vout: []types.Vout{
+				{
+					ScriptPubKeyDecoded: types.ScriptPubKey{
+						Addresses: []string{"nocommitment"},
+					},
+				},
+			},
+		},
+	}
+
+	// Setup a dcrdata stand-in that returns the txs that it knows
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != dcrdataTxsTrimmed {
+			http.NotFound(w, r)
+			return
+		}
+		requests++
+		var req types.Txns
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reply := make([]types.TrimmedTx, 0, len(req.Transactions))
+		for _, v := range req.Transactions {
+			if tx, ok := txs[v]; ok {
+				reply = append(reply, tx)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(reply)
+	}))
+	defer ts.Close()
+
+	p := &piv{
+		cfg: &config{
+			Dcrdata: ts.URL,
+		},
+		client: ts.Client(),
+		ctx:    context.Background(),
+	}
+
+	// Tickets without a commitment address and unknown tickets are
+	// not returned. The tickets are requested in batches.
+	tickets := []string{"ticket1", "ticket2"}
+	for i := 0; i < dcrdataTxsBatchSize; i++ {
+		tickets = append(tickets, "unknown")
+	}
+	addrs, err := p.commitmentAddrs(tickets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"ticket1": "largest",
+	}
+	if !reflect.DeepEqual(addrs, want) {
+		t.Errorf("got %v, want %v", addrs, want)
+	}
+	if requests != 2 {
+		t.Errorf("got %v requests, want 2", requests)
+	}
+}
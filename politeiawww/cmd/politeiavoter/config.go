@@ -35,12 +35,18 @@ const (
 	defaultWalletMainnetPort = "9111"
 	defaultWalletTestnetPort = "19111"
 
+	defaultWalletRPCMainnetPort = "9110"
+	defaultWalletRPCTestnetPort = "19110"
+
 	walletCertFile = "rpc.cert"
 	clientCertFile = "client.pem"
 	clientKeyFile  = "client-key.pem"
 
 	defaultBunches = uint(1)
 
+	defaultRulesFilename = "rules.json"
+	defaultPollInterval  = "10m"
+
 	// Testing stuff
 	testNormal            = 0
 	testFailUnrecoverable = 1
@@ -55,6 +61,7 @@ var (
 	defaultWalletCert = filepath.Join(dcrwalletHomeDir, walletCertFile)
 	defaultClientCert = filepath.Join(defaultHomeDir, clientCertFile)
 	defaultClientKey  = filepath.Join(defaultHomeDir, clientKeyFile)
+	defaultRulesFile  = filepath.Join(defaultHomeDir, defaultRulesFilename)
 
 	// defaultHoursPrior is the default HoursPrior config value. It's required
 	// to be var and not a const since the HoursPrior setting is a pointer.
@@ -77,11 +84,15 @@ type config struct {
 	LogDir           string `long:"logdir" description:"Directory to log output."`
 	TestNet          bool   `long:"testnet" description:"Use the test network"`
 	PoliteiaWWW      string `long:"politeiawww" description:"Politeia WWW host"`
+	Dcrdata          string `long:"dcrdata" description:"dcrdata host used to look up ticket commitment addresses"`
 	Profile          string `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	DebugLevel       string `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
 	WalletHost       string `long:"wallethost" description:"Wallet host"`
 	WalletCert       string `long:"walletgrpccert" description:"Wallet GRPC certificate"`
 	WalletPassphrase string `long:"walletpassphrase" description:"Wallet decryption passphrase"`
+	Signer           string `long:"signer" description:"Wallet API used to sign votes {grpc, jsonrpc}"`
+	WalletRPCUser    string `long:"walletrpcuser" description:"Wallet JSON-RPC username"`
+	WalletRPCPass    string `long:"walletrpcpass" default-mask:"-" description:"Wallet JSON-RPC password"`
 	BypassProxyCheck bool   `long:"bypassproxycheck" description:"Don't use this unless you know what you're doing."`
 	Proxy            string `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyUser        string `long:"proxyuser" description:"Username for proxy server"`
@@ -90,6 +101,8 @@ type config struct {
 	Trickle          bool   `long:"trickle" description:"Enable vote trickling, requires --proxy."`
 	Bunches          uint   `long:"bunches" description:"Number of parallel bunches that start at random times."`
 	SkipVerify       bool   `long:"skipverify" description:"Skip verifying the server's certifcate chain and host name."`
+	RulesFile        string `long:"rulesfile" description:"Path to the vote rules file used by the daemon command"`
+	PollInterval     string `long:"pollinterval" description:"How often the daemon checks for new proposal votes e.g. 10m"`
 
 	// HoursPrior designates the hours to subtract from the end of the
 	// voting period and is set to a default of 12 hours. These extra
@@ -104,6 +117,7 @@ type config struct {
 	dial          func(string, string) (net.Conn, error)
 	voteDuration  time.Duration // Parsed VoteDuration
 	hoursPrior    time.Duration // Converted HoursPrior
+	pollInterval  time.Duration // Parsed PollInterval
 	blocksPerHour uint64
 
 	// Test only
@@ -236,6 +250,10 @@ func loadConfig() (*config, []string, error) {
 		ClientCert: defaultClientCert,
 		ClientKey:  defaultClientKey,
 		Bunches:    defaultBunches,
+		RulesFile:  defaultRulesFile,
+		Signer:     signerGRPC,
+
+		PollInterval: defaultPollInterval,
 		// HoursPrior default is set below
 	}
 
@@ -324,6 +342,12 @@ func loadConfig() (*config, []string, error) {
 		} else {
 			cfg.ClientKey = preCfg.ClientKey
 		}
+		if preCfg.RulesFile == defaultRulesFile {
+			cfg.RulesFile = filepath.Join(cfg.HomeDir,
+				defaultRulesFilename)
+		} else {
+			cfg.RulesFile = preCfg.RulesFile
+		}
 	}
 
 	// Load additional config from file.
@@ -352,6 +376,7 @@ func loadConfig() (*config, []string, error) {
 	if hd != cfg.HomeDir {
 		cfg.LogDir = filepath.Join(cfg.HomeDir, defaultLogDirname)
 		cfg.voteDir = filepath.Join(cfg.HomeDir, defaultVoteDirname)
+		cfg.RulesFile = filepath.Join(cfg.HomeDir, defaultRulesFilename)
 	}
 
 	// Parse command line options again to ensure they take precedence.
@@ -425,14 +450,36 @@ func loadConfig() (*config, []string, error) {
 			cfg.PoliteiaWWW = "https://test-proposals.decred.org/api"
 		}
 	}
+	if cfg.Dcrdata == "" {
+		if activeNetParams.Name == "mainnet" {
+			cfg.Dcrdata = "https://dcrdata.decred.org"
+		} else {
+			cfg.Dcrdata = "https://testnet.dcrdata.org"
+		}
+	}
 
+	switch cfg.Signer {
+	case signerGRPC:
+	case signerJSONRPC:
+		if cfg.WalletRPCUser == "" || cfg.WalletRPCPass == "" {
+			return nil, nil, fmt.Errorf("--walletrpcuser and " +
+				"--walletrpcpass are required when using the " +
+				"jsonrpc signer")
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid --signer %q", cfg.Signer)
+	}
 	if cfg.WalletHost == "" {
+		mainnetPort := defaultWalletMainnetPort
+		testnetPort := defaultWalletTestnetPort
+		if cfg.Signer == signerJSONRPC {
+			mainnetPort = defaultWalletRPCMainnetPort
+			testnetPort = defaultWalletRPCTestnetPort
+		}
 		if activeNetParams.Name == "mainnet" {
-			cfg.WalletHost = defaultWalletHost + ":" +
-				defaultWalletMainnetPort
+			cfg.WalletHost = defaultWalletHost + ":" + mainnetPort
 		} else {
-			cfg.WalletHost = defaultWalletHost + ":" +
-				defaultWalletTestnetPort
+			cfg.WalletHost = defaultWalletHost + ":" + testnetPort
 		}
 	}
 	// Append the network type to the log directory so it is "namespaced"
@@ -528,6 +575,16 @@ func loadConfig() (*config, []string, error) {
 	}
 	cfg.hoursPrior = time.Duration(*cfg.HoursPrior) * time.Hour
 
+	// Daemon settings
+	cfg.RulesFile = util.CleanAndExpandPath(cfg.RulesFile)
+	cfg.pollInterval, err = time.ParseDuration(cfg.PollInterval)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid --pollinterval %v", err)
+	}
+	if cfg.pollInterval < time.Minute {
+		return nil, nil, fmt.Errorf("--pollinterval must be at least 1m")
+	}
+
 	// Number of bunches
 	if cfg.Bunches < 1 || cfg.Bunches > 100 {
 		return nil, nil, fmt.Errorf("invalid number of bunches "+
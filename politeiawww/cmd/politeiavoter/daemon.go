@@ -0,0 +1,415 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"golang.org/x/sync/errgroup"
+)
+
+// daemonStateFile is the file that the daemon persists the state of a
+// proposal vote to. It is saved in the vote directory of the proposal.
+const daemonStateFile = "daemon.json"
+
+// daemonVote is the persisted state of a proposal vote that is being cast by
+// the daemon. The vote alarms contain the signed votes and the time at which
+// they are scheduled to be submitted. The vote alarms are persisted so that
+// the daemon can resume casting the votes after a restart.
+type daemonVote struct {
+	Token   string      `json:"token"`
+	VoteID  string      `json:"voteid"`  // Vote option ID
+	VoteBit string      `json:"votebit"` // Vote option bit
+	Alarms  []voteAlarm `json:"alarms"`
+
+	// Finished is set once all the votes have been cast or once the
+	// proposal vote has ended.
+	Finished bool `json:"finished"`
+}
+
+// daemonStatePath returns the path of the daemon state file of a proposal
+// vote.
+func (p *piv) daemonStatePath(token string) string {
+	return filepath.Join(p.cfg.voteDir, token, daemonStateFile)
+}
+
+// saveDaemonVote persists the daemon state of a proposal vote. The state is
+// written to a temporary file first so that a crash cannot leave a partially
+// written state file behind.
+func (p *piv) saveDaemonVote(dv daemonVote) error {
+	fp := p.daemonStatePath(dv.Token)
+	err := os.MkdirAll(filepath.Dir(fp), 0700)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(dv, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := fp + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, fp)
+}
+
+// loadDaemonVote loads the daemon state of a proposal vote. An os.ErrNotExist
+// error is returned if the daemon has not cast votes on the proposal.
+func (p *piv) loadDaemonVote(token string) (*daemonVote, error) {
+	b, err := ioutil.ReadFile(p.daemonStatePath(token))
+	if err != nil {
+		return nil, err
+	}
+	var dv daemonVote
+	err = json.Unmarshal(b, &dv)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %v", token, err)
+	}
+	return &dv, nil
+}
+
+// pendingAlarms returns the vote alarms of the tickets that have not voted
+// yet. The cast map contains the tickets that have voted. Alarms that were
+// scheduled in the past, e.g. because the daemon was not running, are
+// rescheduled at random times between now and the last alarm of the vote so
+// that they are not all submitted at once.
+func pendingAlarms(alarms []voteAlarm, cast map[string]struct{}, now time.Time) ([]voteAlarm, error) {
+	var (
+		pending = make([]voteAlarm, 0, len(alarms))
+		last    = now
+	)
+	for _, v := range alarms {
+		if _, ok := cast[v.Vote.Ticket]; ok {
+			continue
+		}
+		pending = append(pending, v)
+		if v.At.After(last) {
+			last = v.At
+		}
+	}
+	window := last.Unix() - now.Unix()
+	for k, v := range pending {
+		if !v.At.Before(now) {
+			continue
+		}
+		var offset int64
+		if window > 0 {
+			var err error
+			offset, err = randomInt64(0, window)
+			if err != nil {
+				return nil, err
+			}
+		}
+		pending[k].At = time.Unix(now.Unix()+offset, 0)
+	}
+	return pending, nil
+}
+
+// daemonVoteDuration returns the duration that the votes of a proposal are
+// trickled in over. The votes are trickled in by --hoursprior before the end
+// of the vote, or over --voteduration if it ends earlier. The remaining time
+// is halved when there is less time left in the vote than --hoursprior.
+func (p *piv) daemonVoteDuration(timeLeftInVote time.Duration) time.Duration {
+	d := timeLeftInVote - p.cfg.hoursPrior
+	if p.cfg.voteDuration > 0 && p.cfg.voteDuration < d {
+		d = p.cfg.voteDuration
+	}
+	if d <= 0 {
+		d = timeLeftInVote / 2
+	}
+	return d
+}
+
+// voteDaemon casts votes on the proposal votes that have been started
+// according to the vote rules. The votes are trickled in and their state is
+// persisted so that the daemon can be restarted without losing or
+// duplicating votes.
+type voteDaemon struct {
+	sync.Mutex
+	p          *piv
+	passphrase []byte
+	rules      *voteRules
+	active     map[string]struct{} // Votes that are being cast
+	skipped    map[string]struct{} // Votes that are not voted on
+}
+
+// daemon runs politeiavoter in daemon mode until a shutdown signal is
+// received.
+func (p *piv) daemon(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("daemon: unexpected arguments %v", args)
+	}
+	if !p.cfg.BypassProxyCheck && p.cfg.Proxy == "" {
+		return fmt.Errorf("cannot run the daemon without --proxy")
+	}
+
+	rules, err := loadVoteRules(p.cfg.RulesFile)
+	if err != nil {
+		return fmt.Errorf("load vote rules: %v", err)
+	}
+	passphrase, err := p.walletPassphrase()
+	if err != nil {
+		return err
+	}
+	err = p.unlockWallet(passphrase)
+	if err != nil {
+		return err
+	}
+
+	d := voteDaemon{
+		p:          p,
+		passphrase: passphrase,
+		rules:      rules,
+		active:     make(map[string]struct{}),
+		skipped:    make(map[string]struct{}),
+	}
+
+	log.Infof("Vote daemon started; polling every %v", p.cfg.pollInterval)
+
+	var wg sync.WaitGroup
+	for {
+		d.poll(&wg)
+
+		err := WaitFor(p.ctx, p.cfg.pollInterval)
+		if err != nil {
+			break
+		}
+	}
+
+	// Wait for the votes that are being cast to stop. Their state
+	// has been persisted and they are resumed on the next start.
+	wg.Wait()
+
+	log.Infof("Vote daemon stopped")
+
+	return nil
+}
+
+// poll reloads the vote rules, looks up the proposal votes that have been
+// started, and starts casting the votes of the proposals that the daemon has
+// not voted on yet.
+func (d *voteDaemon) poll(wg *sync.WaitGroup) {
+	// Reload the rules so that they can be edited without restarting
+	// the daemon.
+	rules, err := loadVoteRules(d.p.cfg.RulesFile)
+	if err != nil {
+		log.Errorf("Reload vote rules: %v", err)
+	} else {
+		d.rules = rules
+	}
+
+	tokens, err := d.p.startedVotes()
+	if err != nil {
+		log.Errorf("Started votes: %v", err)
+		return
+	}
+	for _, token := range tokens {
+		d.Lock()
+		_, active := d.active[token]
+		d.Unlock()
+		if active {
+			continue
+		}
+
+		dv, err := d.p.loadDaemonVote(token)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// The daemon has not voted on this proposal yet
+			dv, err = d.schedule(token)
+			if err != nil {
+				log.Errorf("Schedule votes %v: %v", token, err)
+				continue
+			}
+			if dv == nil {
+				// No rule for this proposal
+				continue
+			}
+		case err != nil:
+			log.Errorf("Load vote state %v: %v", token, err)
+			continue
+		}
+		if dv.Finished {
+			continue
+		}
+
+		d.Lock()
+		d.active[token] = struct{}{}
+		d.Unlock()
+
+		wg.Add(1)
+		go func(dv daemonVote) {
+			defer wg.Done()
+			defer func() {
+				d.Lock()
+				delete(d.active, dv.Token)
+				d.Unlock()
+			}()
+
+			err := d.run(dv)
+			if err != nil {
+				log.Errorf("Vote %v: %v", dv.Token, err)
+			}
+		}(*dv)
+	}
+}
+
+// schedule signs the votes of a proposal according to the vote rules and
+// persists the vote alarms. A nil daemonVote is returned if the vote rules
+// do not vote on the proposal.
+func (d *voteDaemon) schedule(token string) (*daemonVote, error) {
+	rps, err := d.p.ruleProposals([]string{token})
+	if err != nil {
+		return nil, err
+	}
+	vd, err := d.rules.decide(rps[0])
+	if err != nil {
+		return nil, err
+	}
+	voteID := vd.VoteID
+	if voteID == "" {
+		if _, ok := d.skipped[token]; !ok {
+			log.Infof("Not voting on %v: %v", token, vd.Reason)
+			d.skipped[token] = struct{}{}
+		}
+		return nil, nil
+	}
+
+	sv, err := d.p.signVotes(token, voteID, d.passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schedule the votes
+	var (
+		blocksLeft = int64(sv.summary.EndBlockHeight) -
+			int64(sv.summary.BestBlock)
+		blockTime      = activeNetParams.TargetTimePerBlock
+		timeLeftInVote = time.Duration(blocksLeft) * blockTime
+		voteDuration   = d.p.daemonVoteDuration(timeLeftInVote)
+	)
+	alarms, err := d.p.generateVoteAlarm(token, sv.voteBit, voteDuration,
+		sv.ctres, sv.smr)
+	if err != nil {
+		return nil, err
+	}
+	dv := daemonVote{
+		Token:   token,
+		VoteID:  voteID,
+		VoteBit: sv.voteBit,
+		Alarms:  make([]voteAlarm, 0, len(alarms)),
+	}
+	for _, v := range alarms {
+		dv.Alarms = append(dv.Alarms, *v)
+	}
+	err = d.p.saveDaemonVote(dv)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Scheduled %v %q votes on %v over %v (%v)", len(dv.Alarms),
+		voteID, token, voteDuration, vd.Reason)
+
+	return &dv, nil
+}
+
+// run casts the votes of a proposal. The votes that have already been cast
+// are looked up before any vote is submitted so that a vote is never cast
+// twice, even when the daemon was restarted after it submitted a vote but
+// before it recorded the result.
+func (d *voteDaemon) run(dv daemonVote) error {
+	p := d.p
+
+	// Filter out the tickets that have already voted
+	v, err := p.getVersion()
+	if err != nil {
+		return err
+	}
+	rr, err := p.voteResults(dv.Token, v.PubKey)
+	if err != nil {
+		return err
+	}
+	cast := make(map[string]struct{}, len(rr.Votes))
+	for _, v := range rr.Votes {
+		cast[v.Ticket] = struct{}{}
+	}
+	pending, err := pendingAlarms(dv.Alarms, cast, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		dv.Finished = true
+		return p.saveDaemonVote(dv)
+	}
+
+	// Persist the rescheduled alarms and log the work so that the votes
+	// can be verified using the verify command.
+	dv.Alarms = pending
+	err = p.saveDaemonVote(dv)
+	if err != nil {
+		return err
+	}
+	err = p.jsonLog(workJournal, dv.Token, pending)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Casting %v votes on %v", len(pending), dv.Token)
+
+	// Launch voting go routines
+	eg, ectx := errgroup.WithContext(p.ctx)
+	bunches := int(p.cfg.Bunches)
+	for k := range pending {
+		voterID := k
+		bunchID := voterID % bunches
+		va := pending[k]
+		eg.Go(func() error {
+			return p.voteTicket(ectx, bunchID, voterID, len(pending), va)
+		})
+	}
+	err = eg.Wait()
+	if err != nil {
+		if p.ctx.Err() != nil {
+			// Shutting down. The vote is resumed on the next start.
+			return nil
+		}
+
+		// Verify if the vote has ended. The remaining votes are retried
+		// on the next poll if it has not.
+		sr, serr := p._summary(dv.Token)
+		if serr != nil {
+			return err
+		}
+		if s, ok := sr.Summaries[dv.Token]; ok &&
+			s.Status == tkv1.VoteStatusStarted {
+			return err
+		}
+		log.Infof("Vote %v has ended: %v", dv.Token, err)
+	}
+
+	dv.Finished = true
+	err = p.saveDaemonVote(dv)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Finished casting votes on %v", dv.Token)
+
+	return nil
+}
+
+// isDaemonStateFile returns whether the provided vote directory file is used
+// by the daemon.
+func isDaemonStateFile(name string) bool {
+	return name == daemonStateFile || name == daemonStateFile+".tmp"
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDaemonVoteSaveLoad(t *testing.T) {
+	c, cleanup := fakePiv(t, time.Minute, 1)
+	defer cleanup()
+
+	// Verify that a missing state returns os.ErrNotExist
+	_, err := c.loadDaemonVote("token")
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("got %v, want %v", err, os.ErrNotExist)
+	}
+
+	ctres, smr := fakeTickets(10)
+	alarms, err := c.generateVoteAlarm("token", "voteBit", time.Minute,
+		ctres, smr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dv := daemonVote{
+		Token:   "token",
+		VoteID:  "yes",
+		VoteBit: "voteBit",
+	}
+	for _, v := range alarms {
+		dv.Alarms = append(dv.Alarms, *v)
+	}
+	err = c.saveDaemonVote(dv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the state round trips
+	got, err := c.loadDaemonVote("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Token != dv.Token || got.VoteID != dv.VoteID ||
+		got.VoteBit != dv.VoteBit || got.Finished {
+		t.Fatalf("got %+v, want %+v", got, dv)
+	}
+	if len(got.Alarms) != len(dv.Alarms) {
+		t.Fatalf("got %v alarms, want %v", len(got.Alarms), len(dv.Alarms))
+	}
+	for k, v := range got.Alarms {
+		if v.Vote != dv.Alarms[k].Vote || !v.At.Equal(dv.Alarms[k].At) {
+			t.Fatalf("alarm %v: got %+v, want %+v", k, v, dv.Alarms[k])
+		}
+	}
+
+	// Verify the state is overwritten
+	dv.Finished = true
+	err = c.saveDaemonVote(dv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = c.loadDaemonVote("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Finished {
+		t.Fatalf("vote not finished")
+	}
+}
+
+func TestPendingAlarms(t *testing.T) {
+	c, cleanup := fakePiv(t, time.Minute, 1)
+	defer cleanup()
+
+	ctres, smr := fakeTickets(10)
+	alarms, err := c.generateVoteAlarm("token", "voteBit", time.Hour,
+		ctres, smr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	va := make([]voteAlarm, 0, len(alarms))
+	for _, v := range alarms {
+		va = append(va, *v)
+	}
+
+	// Mark the first two tickets as voted and move the next two into
+	// the past.
+	now := time.Now()
+	cast := map[string]struct{}{
+		va[0].Vote.Ticket: {},
+		va[1].Vote.Ticket: {},
+	}
+	va[2].At = now.Add(-time.Hour)
+	va[3].At = now.Add(-time.Minute)
+	last := now
+	for _, v := range va {
+		if v.At.After(last) {
+			last = v.At
+		}
+	}
+
+	pending, err := pendingAlarms(va, cast, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(va)-len(cast) {
+		t.Fatalf("got %v pending alarms, want %v", len(pending),
+			len(va)-len(cast))
+	}
+	for _, v := range pending {
+		if _, ok := cast[v.Vote.Ticket]; ok {
+			t.Fatalf("ticket already voted: %v", v.Vote.Ticket)
+		}
+		if v.At.Unix() < now.Unix() || v.At.After(last) {
+			t.Fatalf("alarm %v not between %v and %v", v.At, now, last)
+		}
+	}
+
+	// Verify that no alarms are returned when all tickets have voted
+	for _, v := range va {
+		cast[v.Vote.Ticket] = struct{}{}
+	}
+	pending, err = pendingAlarms(va, cast, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("got %v pending alarms, want 0", len(pending))
+	}
+}
@@ -9,6 +9,12 @@ const listCmdMessage = `Available commands:
   vote      Vote on a proposal
   tally     Tally votes on a proposal
   verify    Verify votes on a proposal
+  daemon    Automatically vote on proposals using a rules file
+  dryrun    Show how the rules file votes on the active proposals
+  export    Export the unsigned votes on a proposal for offline signing
+  sign      Sign exported votes using an offline wallet
+  import    Cast votes that were signed using an offline wallet
+  audit     Audit the votes that were cast on a proposal
   help      Print detailed help message for a command`
 
 const inventoryHelpMsg = `inventory 
@@ -37,3 +43,101 @@ provided then it verifies all votes present in the vote dir.
 
 Arguments:
 1. tokens  ([]string, optional)  Proposal tokens.`
+
+const daemonHelpMsg = `daemon
+
+Run politeiavoter as a long running daemon. The daemon polls politeiawww for
+proposal votes that have been started and trickles in the votes on the
+proposals that the rules file votes on. The signed votes and their
+schedule are saved to the vote directory so that the daemon can be restarted
+without losing or duplicating votes. The rules file is reloaded on every poll.
+
+The wallet passphrase is requested once on startup. The daemon requires
+--proxy unless --bypassproxycheck is set.
+
+Rules file (--rulesfile):
+{
+  "votes": {
+    "<token>": "<voteid>"
+  },
+  "rules": [
+    {"domain": "marketing", "vote": "abstain"},
+    {"amountover": 100000, "vote": "no"}
+  ]
+}
+
+The votes take precedence over the rules. The rules are evaluated in order and
+the first rule that matches a proposal decides the vote. A rule matches when
+all of its criteria match:
+  token         Proposal token
+  domain        Proposal domain (case insensitive)
+  namecontains  Text contained in the proposal name (case insensitive)
+  amountover    Funding amount is greater than this amount in USD
+  amountunder   Funding amount is less than this amount in USD
+  votetype      Vote type (standard or runoff)
+
+The vote of a rule is a vote option ID (e.g. yes) or abstain to not vote on
+the proposal.
+
+Flags:
+ --rulesfile     (string, optional)  Path to the rules file
+                                     (default: <appdata>/rules.json)
+ --pollinterval  (string, optional)  How often to check for new proposal
+                                     votes (default: 10m)`
+
+const dryRunHelpMsg = `dryrun
+
+Show the vote that the daemon casts on each proposal that is being voted on
+according to the rules file. No votes are cast. See 'help daemon' for the
+rules file format.
+
+Flags:
+ --rulesfile  (string, optional)  Path to the rules file
+                                  (default: <appdata>/rules.json)`
+
+const exportHelpMsg = `export "token" "voteid" "filename"
+
+Export the unsigned votes on a proposal to a file so that they can be signed
+by a wallet on an offline machine using the sign command. The file contains
+the eligible tickets of the proposal vote that have not voted yet. A wallet
+is not required.
+
+Arguments:
+1. token     (string, required)  Proposal censorship token
+2. voteid    (string, required)  Vote option ID (e.g. yes)
+3. filename  (string, required)  File to export the votes to`
+
+const signHelpMsg = `sign "in" "out"
+
+Sign the votes that were exported using the export command with the tickets
+of the wallet. This command does not connect to politeiawww so that it can be
+run on an offline machine. The signed votes are cast using the import command.
+
+Arguments:
+1. in   (string, required)  File with the exported votes
+2. out  (string, required)  File to write the signed votes to`
+
+const importHelpMsg = `import "filename"
+
+Cast the votes that were signed on an offline machine using the sign command.
+The signatures are verified and tickets that have already voted are skipped.
+The votes are trickled in when --trickle is set. A wallet is not required.
+
+Arguments:
+1. filename  (string, required)  File with the signed votes`
+
+const auditHelpMsg = `audit "token" "voteid"
+
+Audit the votes that were cast on a proposal. The vote results are downloaded
+from politeiawww and the client signature and the server receipt of every cast
+vote are verified. Every vote must be signed by the commitment address of its
+ticket, which is looked up using dcrdata. The votes of the wallet tickets are
+verified to be present, to be signed by the ticket commitment address, and to
+vote for the intended vote option. Missing and mismatched votes are reported.
+
+The intended vote option is the provided vote ID. If no vote ID is provided,
+the votes that were scheduled in the local vote journals are used.
+
+Arguments:
+1. token   (string, required)  Proposal censorship token
+2. voteid  (string, optional)  Vote option ID (e.g. yes)`
@@ -0,0 +1,323 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	pb "decred.org/dcrwallet/rpc/walletrpc"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+// offlineBallot contains the votes of a proposal that are exported so that
+// they can be signed by a wallet on an offline machine. The tickets are the
+// eligible tickets of the proposal vote that have not voted yet.
+type offlineBallot struct {
+	Token   string   `json:"token"`
+	VoteID  string   `json:"voteid"`  // Vote option ID
+	VoteBit string   `json:"votebit"` // Vote option bit
+	Tickets []string `json:"tickets"` // Ticket hashes
+}
+
+// offlineVote is a vote that was signed on an offline machine.
+type offlineVote struct {
+	Ticket    string `json:"ticket"`    // Ticket hash
+	Address   string `json:"address"`   // Ticket commitment address
+	Signature string `json:"signature"` // Base64 encoded signature
+}
+
+// offlineSignedBallot contains the votes of a proposal that were signed on an
+// offline machine.
+type offlineSignedBallot struct {
+	Token   string        `json:"token"`
+	VoteID  string        `json:"voteid"`  // Vote option ID
+	VoteBit string        `json:"votebit"` // Vote option bit
+	Votes   []offlineVote `json:"votes"`
+}
+
+// writeJSONFile writes the JSON encoding of the provided value to a file.
+func writeJSONFile(filename string, v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0600)
+}
+
+// readJSONFile decodes the JSON encoded file into the provided value.
+func readJSONFile(filename string, v interface{}) error {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(b, v)
+	if err != nil {
+		return fmt.Errorf("decode %v: %v", filename, err)
+	}
+	return nil
+}
+
+// voteBit returns the vote bit of the provided vote option.
+func voteBit(vp tkv1.VoteParams, voteID string) (string, error) {
+	for _, v := range vp.Options {
+		if v.ID == voteID {
+			return strconv.FormatUint(v.Bit, 16), nil
+		}
+	}
+	return "", fmt.Errorf("vote id not found: %v", voteID)
+}
+
+// exportVotes exports the unsigned votes of a proposal to a file so that
+// they can be signed using the sign command on an offline machine.
+func (p *piv) exportVotes(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("export: not enough arguments %v", args)
+	}
+	var (
+		token    = args[0]
+		voteID   = args[1]
+		filename = args[2]
+	)
+
+	// Verify vote is still active
+	sr, err := p._summary(token)
+	if err != nil {
+		return err
+	}
+	vs, ok := sr.Summaries[token]
+	if !ok {
+		return fmt.Errorf("proposal does not exist: %v", token)
+	}
+	if vs.Status != tkv1.VoteStatusStarted {
+		return fmt.Errorf("proposal vote is not active: %v", vs.Status)
+	}
+
+	v, err := p.getVersion()
+	if err != nil {
+		return err
+	}
+	dr, err := p.voteDetails(token, v.PubKey)
+	if err != nil {
+		return err
+	}
+	vb, err := voteBit(dr.Vote.Params, voteID)
+	if err != nil {
+		return err
+	}
+
+	// Filter out the tickets that have already voted
+	rr, err := p.voteResults(token, v.PubKey)
+	if err != nil {
+		return err
+	}
+	cast := make(map[string]struct{}, len(rr.Votes))
+	for _, v := range rr.Votes {
+		cast[v.Ticket] = struct{}{}
+	}
+	tickets := make([]string, 0, len(dr.Vote.EligibleTickets))
+	for _, v := range dr.Vote.EligibleTickets {
+		if _, ok := cast[v]; ok {
+			continue
+		}
+		tickets = append(tickets, v)
+	}
+
+	err = writeJSONFile(filename, offlineBallot{
+		Token:   token,
+		VoteID:  voteID,
+		VoteBit: vb,
+		Tickets: tickets,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported %v tickets to %v\n", len(tickets), filename)
+
+	return nil
+}
+
+// signOffline signs the votes of an exported ballot with the tickets of the
+// wallet. It does not connect to politeiawww.
+func (p *piv) signOffline(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("sign: not enough arguments %v", args)
+	}
+	var (
+		in  = args[0]
+		out = args[1]
+	)
+
+	var ob offlineBallot
+	err := readJSONFile(in, &ob)
+	if err != nil {
+		return err
+	}
+	tix, err := convertTicketHashes(ob.Tickets)
+	if err != nil {
+		return fmt.Errorf("ticket pool corrupt: %v %v", ob.Token, err)
+	}
+
+	passphrase, err := p.walletPassphrase()
+	if err != nil {
+		return err
+	}
+	err = p.unlockWallet(passphrase)
+	if err != nil {
+		return err
+	}
+
+	ctres, err := p.signer.CommittedTickets(p.ctx, tix)
+	if err != nil {
+		return fmt.Errorf("ticket pool verification: %v %v", ob.Token, err)
+	}
+	if len(ctres.TicketAddresses) == 0 {
+		return fmt.Errorf("no eligible tickets found")
+	}
+	smr, err := p.signBallot(ob.Token, ob.VoteBit, ctres, passphrase)
+	if err != nil {
+		return err
+	}
+
+	sb := offlineSignedBallot{
+		Token:   ob.Token,
+		VoteID:  ob.VoteID,
+		VoteBit: ob.VoteBit,
+		Votes:   make([]offlineVote, 0, len(ctres.TicketAddresses)),
+	}
+	for k, v := range ctres.TicketAddresses {
+		h, err := chainhash.NewHash(v.Ticket)
+		if err != nil {
+			return err
+		}
+		sb.Votes = append(sb.Votes, offlineVote{
+			Ticket:  h.String(),
+			Address: v.Address,
+			Signature: base64.StdEncoding.EncodeToString(
+				smr.Replies[k].Signature),
+		})
+	}
+	err = writeJSONFile(out, sb)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Signed %v %q votes on %v to %v\n", len(sb.Votes), sb.VoteID,
+		sb.Token, out)
+
+	return nil
+}
+
+// importVotes casts the votes that were signed on an offline machine. The
+// signatures are verified and the tickets that have already voted are
+// skipped before any vote is cast.
+func (p *piv) importVotes(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("import: not enough arguments %v", args)
+	}
+
+	var sb offlineSignedBallot
+	err := readJSONFile(args[0], &sb)
+	if err != nil {
+		return err
+	}
+	token := sb.Token
+
+	// Verify vote is still active
+	sr, err := p._summary(token)
+	if err != nil {
+		return err
+	}
+	vs, ok := sr.Summaries[token]
+	if !ok {
+		return fmt.Errorf("proposal does not exist: %v", token)
+	}
+	if vs.Status != tkv1.VoteStatusStarted {
+		return fmt.Errorf("proposal vote is not active: %v", vs.Status)
+	}
+
+	// Verify the vote bit matches the vote option
+	v, err := p.getVersion()
+	if err != nil {
+		return err
+	}
+	dr, err := p.voteDetails(token, v.PubKey)
+	if err != nil {
+		return err
+	}
+	vb, err := voteBit(dr.Vote.Params, sb.VoteID)
+	if err != nil {
+		return err
+	}
+	if vb != sb.VoteBit {
+		return fmt.Errorf("vote bit %v does not match vote id %v",
+			sb.VoteBit, sb.VoteID)
+	}
+
+	// Filter out the tickets that have already voted and verify the
+	// signatures of the remaining votes.
+	rr, err := p.voteResults(token, v.PubKey)
+	if err != nil {
+		return err
+	}
+	cast := make(map[string]struct{}, len(rr.Votes))
+	for _, v := range rr.Votes {
+		cast[v.Ticket] = struct{}{}
+	}
+	var (
+		ctres = &pb.CommittedTicketsResponse{
+			TicketAddresses: make([]*pb.CommittedTicketsResponse_TicketAddress,
+				0, len(sb.Votes)),
+		}
+		smr = &pb.SignMessagesResponse{
+			Replies: make([]*pb.SignMessagesResponse_SignReply, 0,
+				len(sb.Votes)),
+		}
+	)
+	for _, v := range sb.Votes {
+		if _, ok := cast[v.Ticket]; ok {
+			continue
+		}
+		msg := token + v.Ticket + sb.VoteBit
+		ok, err := verifyMessage(activeNetParams.Params, v.Address, msg,
+			v.Signature)
+		if err != nil {
+			return fmt.Errorf("ticket %v: %v", v.Ticket, err)
+		}
+		if !ok {
+			return fmt.Errorf("ticket %v: invalid signature", v.Ticket)
+		}
+		h, err := chainhash.NewHashFromStr(v.Ticket)
+		if err != nil {
+			return err
+		}
+		sig, err := base64.StdEncoding.DecodeString(v.Signature)
+		if err != nil {
+			return err
+		}
+		ctres.TicketAddresses = append(ctres.TicketAddresses,
+			&pb.CommittedTicketsResponse_TicketAddress{
+				Ticket:  h[:],
+				Address: v.Address,
+			})
+		smr.Replies = append(smr.Replies, &pb.SignMessagesResponse_SignReply{
+			Signature: sig,
+		})
+	}
+	if len(ctres.TicketAddresses) == 0 {
+		return fmt.Errorf("no eligible tickets found")
+	}
+
+	err = p.castVotes(token, sb.VoteBit, vs, ctres, smr)
+	// we return err after printing details
+	p.printBallotResults()
+
+	return err
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+)
+
+func TestSignOffline(t *testing.T) {
+	c, cleanup := fakePiv(t, 0, 1)
+	defer cleanup()
+
+	// Setup a wallet that owns half of the exported tickets
+	s := &testSigner{
+		key:     newTestKey(t),
+		tickets: make(map[chainhash.Hash]struct{}),
+	}
+	ob := offlineBallot{
+		Token:   "token",
+		VoteID:  "yes",
+		VoteBit: "2",
+	}
+	for i := 0; i < 10; i++ {
+		var h chainhash.Hash
+		binary.LittleEndian.PutUint64(h[:], uint64(i))
+		ob.Tickets = append(ob.Tickets, h.String())
+		if i%2 == 0 {
+			s.tickets[h] = struct{}{}
+		}
+	}
+	c.signer = s
+	c.cfg.WalletPassphrase = "passphrase"
+
+	in := filepath.Join(c.cfg.HomeDir, "unsigned.json")
+	out := filepath.Join(c.cfg.HomeDir, "signed.json")
+	err := writeJSONFile(in, ob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.signOffline([]string{in, out})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the signed votes
+	var sb offlineSignedBallot
+	err = readJSONFile(out, &sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sb.Token != ob.Token || sb.VoteID != ob.VoteID ||
+		sb.VoteBit != ob.VoteBit {
+		t.Fatalf("got %v %v %v, want %v %v %v", sb.Token, sb.VoteID,
+			sb.VoteBit, ob.Token, ob.VoteID, ob.VoteBit)
+	}
+	if len(sb.Votes) != len(s.tickets) {
+		t.Fatalf("got %v votes, want %v", len(sb.Votes), len(s.tickets))
+	}
+	for _, v := range sb.Votes {
+		h, err := chainhash.NewHashFromStr(v.Ticket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := s.tickets[*h]; !ok {
+			t.Fatalf("ticket not owned by wallet: %v", v.Ticket)
+		}
+		msg := sb.Token + v.Ticket + sb.VoteBit
+		ok, err := verifyMessage(activeNetParams.Params, v.Address, msg,
+			v.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("invalid signature: %v", v.Ticket)
+		}
+	}
+
+	// Verify that a vote is rejected when its signature does not
+	// match the vote bit.
+	msg := sb.Token + sb.Votes[0].Ticket + "1"
+	ok, err := verifyMessage(activeNetParams.Params, sb.Votes[0].Address, msg,
+		sb.Votes[0].Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("expected invalid signature")
+	}
+}
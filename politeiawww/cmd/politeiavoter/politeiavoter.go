@@ -9,7 +9,6 @@ import (
 	"context"
 	crand "crypto/rand"
 	"crypto/tls"
-	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -29,7 +28,6 @@ import (
 	"time"
 
 	pb "decred.org/dcrwallet/rpc/walletrpc"
-	"github.com/decred/dcrd/blockchain/stake/v3"
 	"github.com/decred/dcrd/chaincfg/chainhash"
 	"github.com/decred/dcrd/chaincfg/v3"
 	"github.com/decred/dcrd/dcrec/secp256k1/v3/ecdsa"
@@ -45,8 +43,6 @@ import (
 	"github.com/gorilla/schema"
 	"golang.org/x/crypto/ssh/terminal"
 	"golang.org/x/net/publicsuffix"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 )
 
 const (
@@ -54,6 +50,12 @@ const (
 	cmdVote      = "vote"
 	cmdTally     = "tally"
 	cmdVerify    = "verify"
+	cmdDaemon    = "daemon"
+	cmdDryRun    = "dryrun"
+	cmdExport    = "export"
+	cmdSign      = "sign"
+	cmdImport    = "import"
+	cmdAudit     = "audit"
 	cmdHelp      = "help"
 )
 
@@ -166,12 +168,11 @@ type piv struct {
 	id        *identity.PublicIdentity
 	userAgent string
 
-	// wallet grpc
 	ctx    context.Context
 	cancel context.CancelFunc
-	creds  credentials.TransportCredentials
-	conn   *grpc.ClientConn
-	wallet pb.WalletServiceClient
+
+	// wallet
+	signer signer
 }
 
 func newPiVoter(shutdownCtx context.Context, cfg *config) (*piv, error) {
@@ -193,40 +194,11 @@ func newPiVoter(shutdownCtx context.Context, cfg *config) (*piv, error) {
 		return nil, err
 	}
 
-	// Wallet GRPC
-	serverCAs := x509.NewCertPool()
-	serverCert, err := ioutil.ReadFile(cfg.WalletCert)
-	if err != nil {
-		return nil, err
-	}
-	if !serverCAs.AppendCertsFromPEM(serverCert) {
-		return nil, fmt.Errorf("no certificates found in %s",
-			cfg.WalletCert)
-	}
-	keypair, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
-	if err != nil {
-		return nil, fmt.Errorf("read client keypair: %v", err)
-	}
-	creds := credentials.NewTLS(&tls.Config{
-		Certificates: []tls.Certificate{keypair},
-		RootCAs:      serverCAs,
-	})
-
-	conn, err := grpc.Dial(cfg.WalletHost,
-		grpc.WithTransportCredentials(creds))
-	if err != nil {
-		return nil, err
-	}
-	wallet := pb.NewWalletServiceClient(conn)
-
 	// return context
 	return &piv{
-		run:    time.Now(),
-		ctx:    shutdownCtx,
-		creds:  creds,
-		conn:   conn,
-		wallet: wallet,
-		cfg:    cfg,
+		run: time.Now(),
+		ctx: shutdownCtx,
+		cfg: cfg,
 		client: &http.Client{
 			Transport: tr,
 			Jar:       jar,
@@ -446,8 +418,8 @@ func firstContact(shutdownCtx context.Context, cfg *config) (*piv, error) {
 
 // eligibleVotes takes a vote result reply that contains the full list of the
 // votes already cast along with a committed tickets response from wallet which
-// consists of a list of tickets the wallet is aware of and returns a list of
-// tickets that the wallet is actually able to sign and vote with.
+// consists of a list of tickets the wallet is able to sign with and returns a
+// list of the tickets that have not voted yet.
 //
 // When a ticket has already voted, the signature is also checked to ensure it
 // is valid.  In the case it is invalid, and the wallet can sign it, the ticket
@@ -472,37 +444,6 @@ func (p *piv) eligibleVotes(rr *tkv1.ResultsReply, ctres *pb.CommittedTicketsRes
 			return nil, err
 		}
 
-		// Filter out tickets tracked by imported xpub accounts.
-		r, err := p.wallet.GetTransaction(context.TODO(), &pb.GetTransactionRequest{
-			TransactionHash: h[:],
-		})
-		if err != nil {
-			log.Error(err)
-			continue
-		}
-		tx := new(wire.MsgTx)
-		err = tx.Deserialize(bytes.NewReader(r.Transaction.Transaction))
-		if err != nil {
-			log.Error(err)
-			continue
-		}
-		addr, err := stake.AddrFromSStxPkScrCommitment(tx.TxOut[1].PkScript, activeNetParams.Params)
-		if err != nil {
-			log.Error(err)
-			continue
-		}
-		vr, err := p.wallet.ValidateAddress(context.TODO(), &pb.ValidateAddressRequest{
-			Address: addr.String(),
-		})
-		if err != nil {
-			log.Error(err)
-			continue
-		}
-		if vr.AccountNumber >= 1<<31-1 { // imported xpub account
-			// do not append to filtered.
-			continue
-		}
-
 		_, ok := castVotes[h.String()]
 		if !ok {
 			eligible = append(eligible, t)
@@ -558,6 +499,25 @@ func (p *piv) voteDetails(token, serverPubKey string) (*tkv1.DetailsReply, error
 }
 
 func (p *piv) voteResults(token, serverPubKey string) (*tkv1.ResultsReply, error) {
+	rr, err := p._voteResults(token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify CastVoteDetails.
+	for _, cvd := range rr.Votes {
+		err = client.CastVoteDetailsVerify(cvd, serverPubKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rr, nil
+}
+
+// _voteResults sends a ticketvote API Results request and returns the reply
+// without verifying the cast votes.
+func (p *piv) _voteResults(token string) (*tkv1.ResultsReply, error) {
 	r := tkv1.Results{
 		Token: token,
 	}
@@ -573,14 +533,6 @@ func (p *piv) voteResults(token, serverPubKey string) (*tkv1.ResultsReply, error
 		return nil, fmt.Errorf("Could not unmarshal ResultsReply: %v", err)
 	}
 
-	// Verify CastVoteDetails.
-	for _, cvd := range rr.Votes {
-		err = client.CastVoteDetailsVerify(cvd, serverPubKey)
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	return &rr, nil
 }
 
@@ -635,21 +587,16 @@ func (p *piv) votePolicy() (*tkv1.PolicyReply, error) {
 	return &pr, nil
 }
 
-func (p *piv) inventory() error {
-	// Get server public key to verify replies.
-	version, err := p.getVersion()
-	if err != nil {
-		return err
-	}
-	serverPubKey := version.PubKey
-
+// startedVotes returns the tokens of all the proposals whose vote has been
+// started.
+func (p *piv) startedVotes() ([]string, error) {
 	// Inventory route is paginated, therefore we keep fetching
 	// until we receive a patch with number of records smaller than the
 	// ticketvote's declared page size. The page size is retrieved from
 	// the ticketvote API Policy route.
 	vp, err := p.votePolicy()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	pageSize := vp.InventoryPageSize
 	page := uint32(1)
@@ -660,7 +607,7 @@ func (p *piv) inventory() error {
 			Status: tkv1.VoteStatusStarted,
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 		pageTokens := ir.Vetted[tkv1.VoteStatuses[tkv1.VoteStatusStarted]]
 		tokens = append(tokens, pageTokens...)
@@ -670,15 +617,12 @@ func (p *piv) inventory() error {
 		page++
 	}
 
-	// Print empty message in case no active votes found.
-	if len(tokens) == 0 {
-		fmt.Printf("No active votes found.\n")
-		return nil
-	}
+	return tokens, nil
+}
 
-	// Retrieve the proposals metadata and store proposal names in a
-	// map[token] => name.
-	names := make(map[string]string, len(tokens))
+// proposalMetadata returns the proposal metadata of the provided proposals.
+func (p *piv) proposalMetadata(tokens []string, serverPubKey string) (map[string]piv1.ProposalMetadata, error) {
+	mds := make(map[string]piv1.ProposalMetadata, len(tokens))
 	remainingTokens := tokens
 	// As the records API Records route is paged, we need to fetch the proposals
 	// metadata page by page.
@@ -699,19 +643,47 @@ func (p *piv) inventory() error {
 		// Fetch page of records
 		reply, err := p.records(page, serverPubKey)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// Get proposal metadata and store proposal name in map.
+		// Decode proposal metadata
 		for token, record := range reply.Records {
 			md, err := client.ProposalMetadataDecode(record.Files)
 			if err != nil {
-				return nil
+				return nil, fmt.Errorf("%v: %v", token, err)
 			}
-			names[token] = md.Name
+			mds[token] = *md
 		}
 	}
 
+	return mds, nil
+}
+
+func (p *piv) inventory() error {
+	// Get server public key to verify replies.
+	version, err := p.getVersion()
+	if err != nil {
+		return err
+	}
+	serverPubKey := version.PubKey
+
+	tokens, err := p.startedVotes()
+	if err != nil {
+		return err
+	}
+
+	// Print empty message in case no active votes found.
+	if len(tokens) == 0 {
+		fmt.Printf("No active votes found.\n")
+		return nil
+	}
+
+	// Retrieve the proposals metadata
+	mds, err := p.proposalMetadata(tokens, serverPubKey)
+	if err != nil {
+		return err
+	}
+
 	for _, t := range tokens {
 		// Get vote details.
 		dr, err := p.voteDetails(t, serverPubKey)
@@ -726,10 +698,7 @@ func (p *piv) inventory() error {
 				dr.Vote.Params.Token, err)
 			continue
 		}
-		ctres, err := p.wallet.CommittedTickets(p.ctx,
-			&pb.CommittedTicketsRequest{
-				Tickets: tix,
-			})
+		ctres, err := p.signer.CommittedTickets(p.ctx, tix)
 		if err != nil {
 			fmt.Printf("Ticket pool verification: %v %v\n",
 				dr.Vote.Params.Token, err)
@@ -763,7 +732,7 @@ func (p *piv) inventory() error {
 
 		// Display vote bits
 		fmt.Printf("Vote: %v\n", dr.Vote.Params.Token)
-		fmt.Printf("  Proposal        : %v\n", names[t])
+		fmt.Printf("  Proposal        : %v\n", mds[t].Name)
 		fmt.Printf("  Start block     : %v\n", dr.Vote.StartBlockHeight)
 		fmt.Printf("  End block       : %v\n", dr.Vote.EndBlockHeight)
 		fmt.Printf("  Mask            : %v\n", dr.Vote.Params.Mask)
@@ -855,91 +824,77 @@ func (p *piv) dumpTogo() {
 	panic("dumpTogo")
 }
 
-func (p *piv) _vote(token, voteID string) error {
-	passphrase, err := p.walletPassphrase()
-	if err != nil {
-		return err
-	}
-	// This assumes the account is an HD account.
-	_, err = p.wallet.GetAccountExtendedPrivKey(p.ctx,
-		&pb.GetAccountExtendedPrivKeyRequest{
-			AccountNumber: 0, // TODO: make a config flag
-			Passphrase:    passphrase,
-		})
-	if err != nil {
-		return err
-	}
+// signedVotes contains the signed votes of all the eligible tickets of a
+// wallet for a proposal vote. The ticket addresses and the signatures use the
+// same index.
+type signedVotes struct {
+	summary tkv1.Summary
+	voteBit string
+	ctres   *pb.CommittedTicketsResponse
+	smr     *pb.SignMessagesResponse
+}
 
+// signVotes verifies that the proposal vote is still active, looks up the
+// wallet tickets that are eligible to vote and have not voted yet, and signs
+// a vote for the provided vote option with each of them. The eligible tickets
+// are returned in random order.
+func (p *piv) signVotes(token, voteID string, passphrase []byte) (*signedVotes, error) {
 	seed, err := generateSeed()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Verify vote is still active
 	sr, err := p._summary(token)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	vs, ok := sr.Summaries[token]
 	if !ok {
-		return fmt.Errorf("proposal does not exist: %v", token)
+		return nil, fmt.Errorf("proposal does not exist: %v", token)
 	}
 	if vs.Status != tkv1.VoteStatusStarted {
-		return fmt.Errorf("proposal vote is not active: %v", vs.Status)
+		return nil, fmt.Errorf("proposal vote is not active: %v", vs.Status)
 	}
-	bestBlock := vs.BestBlock
 
 	// Get server public key by calling version request.
 	v, err := p.getVersion()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Get vote details.
 	dr, err := p.voteDetails(token, v.PubKey)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Validate voteId
-	var (
-		voteBit string
-		found   bool
-	)
-	for _, vv := range dr.Vote.Params.Options {
-		if vv.ID == voteID {
-			found = true
-			voteBit = strconv.FormatUint(vv.Bit, 16)
-			break
-		}
-	}
-	if !found {
-		return fmt.Errorf("vote id not found: %v", voteID)
+	vb, err := voteBit(dr.Vote.Params, voteID)
+	if err != nil {
+		return nil, err
 	}
 
 	// Find eligble tickets
 	tix, err := convertTicketHashes(dr.Vote.EligibleTickets)
 	if err != nil {
-		return fmt.Errorf("ticket pool corrupt: %v %v",
+		return nil, fmt.Errorf("ticket pool corrupt: %v %v",
 			token, err)
 	}
-	ctres, err := p.wallet.CommittedTickets(p.ctx,
-		&pb.CommittedTicketsRequest{
-			Tickets: tix,
-		})
+	ctres, err := p.signer.CommittedTickets(p.ctx, tix)
 	if err != nil {
-		return fmt.Errorf("ticket pool verification: %v %v",
+		return nil, fmt.Errorf("ticket pool verification: %v %v",
 			token, err)
 	}
 	if len(ctres.TicketAddresses) == 0 {
-		return fmt.Errorf("no eligible tickets found")
+		return nil, fmt.Errorf("no eligible tickets found")
 	}
 
 	// voteResults a list of the votes that have already been cast. We use these
 	// to filter out the tickets that have already voted.
 	rr, err := p.voteResults(token, v.PubKey)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Filter out tickets that have already voted or are otherwise ineligible
@@ -947,12 +902,12 @@ func (p *piv) _vote(token, voteID string) error {
 	// have an invalid signature are included so they may be resubmitted.
 	eligible, err := p.eligibleVotes(rr, ctres)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	eligibleLen := len(eligible)
 	if eligibleLen == 0 {
-		return fmt.Errorf("no eligible tickets found")
+		return nil, fmt.Errorf("no eligible tickets found")
 	}
 	r := rand.New(rand.NewSource(seed))
 	// Fisher-Yates shuffle the ticket addresses.
@@ -964,50 +919,97 @@ func (p *piv) _vote(token, voteID string) error {
 	ctres.TicketAddresses = eligible
 
 	// Sign all tickets
-	sm := &pb.SignMessagesRequest{
-		Passphrase: passphrase,
-		Messages: make([]*pb.SignMessagesRequest_Message, 0,
-			len(ctres.TicketAddresses)),
+	smr, err := p.signBallot(token, vb, ctres, passphrase)
+	if err != nil {
+		return nil, err
 	}
+
+	return &signedVotes{
+		summary: vs,
+		voteBit: vb,
+		ctres:   ctres,
+		smr:     smr,
+	}, nil
+}
+
+// signBallot signs a vote for the provided vote bit with each of the provided
+// tickets. The signatures use the same index as the ticket addresses.
+func (p *piv) signBallot(token, voteBit string, ctres *pb.CommittedTicketsResponse, passphrase []byte) (*pb.SignMessagesResponse, error) {
+	msgs := make([]*pb.SignMessagesRequest_Message, 0,
+		len(ctres.TicketAddresses))
 	for _, v := range ctres.TicketAddresses {
 		h, err := chainhash.NewHash(v.Ticket)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		msg := token + h.String() + voteBit
-		sm.Messages = append(sm.Messages, &pb.SignMessagesRequest_Message{
+		msgs = append(msgs, &pb.SignMessagesRequest_Message{
 			Address: v.Address,
 			Message: msg,
 		})
 	}
-	smr, err := p.wallet.SignMessages(p.ctx, sm)
+	smr, err := p.signer.SignMessages(p.ctx, passphrase, msgs)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Make sure all signatures worked
+	if len(smr.Replies) != len(msgs) {
+		return nil, fmt.Errorf("invalid number of signatures: got %v, "+
+			"want %v", len(smr.Replies), len(msgs))
+	}
 	for k, v := range smr.Replies {
 		if v.Error == "" {
 			continue
 		}
-		return fmt.Errorf("signature failed index %v: %v", k, v.Error)
+		return nil, fmt.Errorf("signature failed index %v: %v", k, v.Error)
 	}
 
+	return smr, nil
+}
+
+// unlockWallet verifies that the provided passphrase unlocks the wallet.
+func (p *piv) unlockWallet(passphrase []byte) error {
+	return p.signer.Unlock(p.ctx, passphrase)
+}
+
+func (p *piv) _vote(token, voteID string) error {
+	passphrase, err := p.walletPassphrase()
+	if err != nil {
+		return err
+	}
+	err = p.unlockWallet(passphrase)
+	if err != nil {
+		return err
+	}
+
+	sv, err := p.signVotes(token, voteID, passphrase)
+	if err != nil {
+		return err
+	}
+
+	return p.castVotes(token, sv.voteBit, sv.summary, sv.ctres, sv.smr)
+}
+
+// castVotes casts the provided signed votes on a proposal. The votes are
+// trickled in if --trickle is set. Otherwise all votes are cast at once.
+func (p *piv) castVotes(token, voteBit string, vs tkv1.Summary, ctres *pb.CommittedTicketsResponse, smr *pb.SignMessagesResponse) error {
 	// Trickle in the votes if specified
 	if p.cfg.Trickle {
 		// Setup the trickler vote duration
 		var (
-			blocksLeft     = int64(vs.EndBlockHeight) - int64(bestBlock)
+			blocksLeft     = int64(vs.EndBlockHeight) - int64(vs.BestBlock)
 			blockTime      = activeNetParams.TargetTimePerBlock
 			timeLeftInVote = time.Duration(blocksLeft) * blockTime
 		)
-		err = p.setupVoteDuration(timeLeftInVote)
+		err := p.setupVoteDuration(timeLeftInVote)
 		if err != nil {
 			return err
 		}
 
 		// Trickle votes
-		return p.alarmTrickler(token, voteBit, ctres, smr)
+		return p.alarmTrickler(token, voteBit, p.cfg.voteDuration,
+			ctres, smr)
 	}
 
 	// Vote everything at once.
@@ -1092,7 +1094,13 @@ func (p *piv) vote(args []string) error {
 
 	err := p._vote(args[0], args[1])
 	// we return err after printing details
+	p.printBallotResults()
+
+	return err
+}
 
+// printBallotResults prints the results of the votes that were cast.
+func (p *piv) printBallotResults() {
 	// Verify vote replies. Already voted errors are not
 	// considered to be failures because they occur when
 	// a network error or dropped client connection causes
@@ -1129,8 +1137,6 @@ func (p *piv) vote(args []string) error {
 		fmt.Printf("Failed vote    : %v %v\n",
 			v.Ticket, v.ErrorContext)
 	}
-
-	return err
 }
 
 func (p *piv) _summary(token string) (*tkv1.SummariesReply, error) {
@@ -1520,6 +1526,9 @@ func (p *piv) verifyVote(vote string) error {
 		case name == ".voteresults":
 			// Cache file, skip
 
+		case isDaemonStateFile(name):
+			// Daemon state, skip
+
 		default:
 			fmt.Printf("unknown journal: %v\n", name)
 		}
@@ -1718,6 +1727,18 @@ func (p *piv) help(command string) {
 		fmt.Fprintf(os.Stdout, "%s\n", tallyHelpMsg)
 	case cmdVerify:
 		fmt.Fprintf(os.Stdout, "%s\n", verifyHelpMsg)
+	case cmdDaemon:
+		fmt.Fprintf(os.Stdout, "%s\n", daemonHelpMsg)
+	case cmdDryRun:
+		fmt.Fprintf(os.Stdout, "%s\n", dryRunHelpMsg)
+	case cmdExport:
+		fmt.Fprintf(os.Stdout, "%s\n", exportHelpMsg)
+	case cmdSign:
+		fmt.Fprintf(os.Stdout, "%s\n", signHelpMsg)
+	case cmdImport:
+		fmt.Fprintf(os.Stdout, "%s\n", importHelpMsg)
+	case cmdAudit:
+		fmt.Fprintf(os.Stdout, "%s\n", auditHelpMsg)
 	}
 }
 
@@ -1738,26 +1759,23 @@ func _main() error {
 	// another subsystem such as the RPC server.
 	shutdownCtx := shutdownListener()
 
-	// Contact WWW
-	c, err := firstContact(shutdownCtx, cfg)
-	if err != nil {
-		return err
-	}
-	// Close GRPC
-	defer c.conn.Close()
-
 	// Validate command
+	var (
+		walletRequired bool
+		serverRequired = true
+	)
 	switch action {
-	case cmdInventory, cmdTally, cmdVote:
-		// These commands require a connection to a dcrwallet instance. Get
-		// block height to validate GPRC creds.
-		ar, err := c.wallet.Accounts(c.ctx, &pb.AccountsRequest{})
-		if err != nil {
-			return err
-		}
-		log.Debugf("Current wallet height: %v", ar.CurrentBlockHeight)
+	case cmdInventory, cmdTally, cmdVote, cmdDaemon, cmdAudit:
+		// These commands require a connection to a dcrwallet instance.
+		walletRequired = true
+
+	case cmdSign:
+		// Offline signing only requires a connection to a dcrwallet
+		// instance so that it can be run on an air-gapped machine.
+		walletRequired = true
+		serverRequired = false
 
-	case cmdVerify, cmdHelp:
+	case cmdVerify, cmdDryRun, cmdExport, cmdImport, cmdHelp:
 		// valid command, continue
 
 	default:
@@ -1766,6 +1784,32 @@ func _main() error {
 		os.Exit(1)
 	}
 
+	// Contact WWW
+	var c *piv
+	if serverRequired {
+		c, err = firstContact(shutdownCtx, cfg)
+	} else {
+		c, err = newPiVoter(shutdownCtx, cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if walletRequired {
+		c.signer, err = newSigner(cfg)
+		if err != nil {
+			return err
+		}
+		defer c.signer.Close()
+
+		// Get block height to validate the wallet credentials.
+		height, err := c.signer.BlockHeight(c.ctx)
+		if err != nil {
+			return err
+		}
+		log.Debugf("Current wallet height: %v", height)
+	}
+
 	// Run command
 	switch action {
 	case cmdInventory:
@@ -1776,6 +1820,18 @@ func _main() error {
 		err = c.tally(args[1:])
 	case cmdVerify:
 		err = c.verify(args[1:])
+	case cmdDaemon:
+		err = c.daemon(args[1:])
+	case cmdDryRun:
+		err = c.dryRun(args[1:])
+	case cmdExport:
+		err = c.exportVotes(args[1:])
+	case cmdSign:
+		err = c.signOffline(args[1:])
+	case cmdImport:
+		err = c.importVotes(args[1:])
+	case cmdAudit:
+		err = c.audit(args[1:])
 	case cmdHelp:
 		c.help(args[1])
 	}
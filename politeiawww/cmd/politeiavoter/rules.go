@@ -0,0 +1,253 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+// voteAbstain is the rule vote that is used to not vote on a proposal.
+const voteAbstain = "abstain"
+
+// voteRules contains the votes that are automatically cast by the daemon.
+// The rules are loaded from a JSON file. For example:
+//
+//	{
+//	  "votes": {
+//	    "<token>": "yes"
+//	  },
+//	  "rules": [
+//	    {"domain": "marketing", "vote": "abstain"},
+//	    {"amountover": 100000, "vote": "no"}
+//	  ]
+//	}
+//
+// The votes take precedence over the rules. The rules are evaluated in
+// order and the first rule that matches a proposal decides the vote.
+type voteRules struct {
+	// Votes contains the vote option ID that is voted for on a proposal.
+	Votes map[string]string `json:"votes"` // [token]voteID
+
+	// Rules contains the policy rules that are evaluated against the
+	// proposals that are not listed in Votes.
+	Rules []voteRule `json:"rules"`
+}
+
+// voteRule is a policy rule that decides the vote on the proposals that it
+// matches. A rule matches a proposal when all of the criteria that are set
+// match. A rule without any criteria matches all proposals.
+type voteRule struct {
+	Token        string `json:"token,omitempty"`        // Proposal token
+	Domain       string `json:"domain,omitempty"`       // Proposal domain
+	NameContains string `json:"namecontains,omitempty"` // Case insensitive
+	AmountOver   uint64 `json:"amountover,omitempty"`   // Funding in USD
+	AmountUnder  uint64 `json:"amountunder,omitempty"`  // Funding in USD
+	VoteType     string `json:"votetype,omitempty"`     // standard or runoff
+
+	// Vote is the vote option ID that is voted for, or abstain to not
+	// vote on the proposal.
+	Vote string `json:"vote"`
+}
+
+// matches returns whether the rule matches the provided proposal.
+func (r *voteRule) matches(rp ruleProposal) bool {
+	switch {
+	case r.Token != "" && r.Token != rp.Token:
+		return false
+	case r.Domain != "" && !strings.EqualFold(r.Domain, rp.Metadata.Domain):
+		return false
+	case r.NameContains != "" &&
+		!strings.Contains(strings.ToLower(rp.Metadata.Name),
+			strings.ToLower(r.NameContains)):
+		return false
+	case r.AmountOver != 0 && rp.Metadata.Amount <= r.AmountOver*100:
+		return false
+	case r.AmountUnder != 0 && rp.Metadata.Amount >= r.AmountUnder*100:
+		return false
+	case r.VoteType != "" && r.VoteType != tkv1.VoteTypes[rp.Vote.Type]:
+		return false
+	}
+	return true
+}
+
+// ruleProposal contains the proposal data that the vote rules are evaluated
+// against.
+type ruleProposal struct {
+	Token    string
+	Metadata piv1.ProposalMetadata
+	Vote     tkv1.VoteParams
+}
+
+// voteDecision is the outcome of evaluating the vote rules against a
+// proposal. An empty VoteID means that the proposal is not voted on.
+type voteDecision struct {
+	VoteID string
+	Reason string // Vote or rule that decided the vote
+}
+
+// decide evaluates the vote rules against the provided proposal and returns
+// the vote that is cast on it. An error is returned if the matching vote or
+// rule votes for an option that the proposal vote does not have.
+func (vr *voteRules) decide(rp ruleProposal) (*voteDecision, error) {
+	var d voteDecision
+	if voteID, ok := vr.Votes[rp.Token]; ok {
+		d = voteDecision{
+			VoteID: voteID,
+			Reason: "votes",
+		}
+	} else {
+		for k, v := range vr.Rules {
+			if !v.matches(rp) {
+				continue
+			}
+			d = voteDecision{
+				VoteID: v.Vote,
+				Reason: fmt.Sprintf("rule %v", k+1),
+			}
+			break
+		}
+	}
+	switch d.VoteID {
+	case "":
+		d.Reason = "no matching rule"
+		return &d, nil
+	case voteAbstain:
+		d.VoteID = ""
+		return &d, nil
+	}
+
+	for _, v := range rp.Vote.Options {
+		if v.ID == d.VoteID {
+			return &d, nil
+		}
+	}
+	return nil, fmt.Errorf("%v: invalid vote option %q", d.Reason, d.VoteID)
+}
+
+// validate verifies that the vote rules are well formed.
+func (vr *voteRules) validate() error {
+	for token, voteID := range vr.Votes {
+		if voteID == "" {
+			return fmt.Errorf("votes: %v: vote option not set", token)
+		}
+	}
+	for k, v := range vr.Rules {
+		if v.Vote == "" {
+			return fmt.Errorf("rule %v: vote not set", k+1)
+		}
+		switch v.VoteType {
+		case "", tkv1.VoteTypes[tkv1.VoteTypeStandard],
+			tkv1.VoteTypes[tkv1.VoteTypeRunoff]:
+		default:
+			return fmt.Errorf("rule %v: invalid vote type %q", k+1,
+				v.VoteType)
+		}
+		if v.AmountOver != 0 && v.AmountUnder != 0 &&
+			v.AmountOver >= v.AmountUnder {
+			return fmt.Errorf("rule %v: amountover must be less than "+
+				"amountunder", k+1)
+		}
+	}
+	return nil
+}
+
+// loadVoteRules loads and validates the vote rules from the provided file.
+func loadVoteRules(filename string) (*voteRules, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var vr voteRules
+	err = json.Unmarshal(b, &vr)
+	if err != nil {
+		return nil, fmt.Errorf("decode %v: %v", filename, err)
+	}
+	err = vr.validate()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	return &vr, nil
+}
+
+// ruleProposals returns the rule proposals of the provided proposal votes.
+func (p *piv) ruleProposals(tokens []string) ([]ruleProposal, error) {
+	v, err := p.getVersion()
+	if err != nil {
+		return nil, err
+	}
+	mds, err := p.proposalMetadata(tokens, v.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	rps := make([]ruleProposal, 0, len(tokens))
+	for _, t := range tokens {
+		dr, err := p.voteDetails(t, v.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		if dr.Vote == nil {
+			return nil, fmt.Errorf("vote not started: %v", t)
+		}
+		rps = append(rps, ruleProposal{
+			Token:    t,
+			Metadata: mds[t],
+			Vote:     dr.Vote.Params,
+		})
+	}
+	return rps, nil
+}
+
+// dryRun prints the vote that the daemon casts on each of the proposal votes
+// that have been started according to the vote rules.
+func (p *piv) dryRun(args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("dryrun: unexpected arguments %v", args)
+	}
+
+	vr, err := loadVoteRules(p.cfg.RulesFile)
+	if err != nil {
+		return err
+	}
+	tokens, err := p.startedVotes()
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		fmt.Printf("No active votes found.\n")
+		return nil
+	}
+	rps, err := p.ruleProposals(tokens)
+	if err != nil {
+		return err
+	}
+
+	for _, rp := range rps {
+		var vote string
+		d, err := vr.decide(rp)
+		switch {
+		case err != nil:
+			vote = fmt.Sprintf("error: %v", err)
+		case d.VoteID == "":
+			vote = fmt.Sprintf("none (%v)", d.Reason)
+		default:
+			vote = fmt.Sprintf("%v (%v)", d.VoteID, d.Reason)
+		}
+
+		fmt.Printf("Vote: %v\n", rp.Token)
+		fmt.Printf("  Proposal : %v\n", rp.Metadata.Name)
+		fmt.Printf("  Domain   : %v\n", rp.Metadata.Domain)
+		fmt.Printf("  Amount   : $%v\n", rp.Metadata.Amount/100)
+		fmt.Printf("  Vote type: %v\n", tkv1.VoteTypes[rp.Vote.Type])
+		fmt.Printf("  Vote     : %v\n", vote)
+	}
+
+	return nil
+}
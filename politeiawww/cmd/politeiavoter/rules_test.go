@@ -0,0 +1,162 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	piv1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	tkv1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+func TestLoadVoteRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "politeiavoter.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var tests = []struct {
+		name    string
+		rules   string
+		wantErr bool
+	}{
+		{
+			"valid",
+			`{"votes":{"token":"yes"},"rules":[{"domain":"marketing",` +
+				`"vote":"abstain"},{"amountover":1000,"vote":"no"}]}`,
+			false,
+		},
+		{
+			"invalid json",
+			`{"votes":`,
+			true,
+		},
+		{
+			"vote not set",
+			`{"rules":[{"domain":"marketing"}]}`,
+			true,
+		},
+		{
+			"invalid vote type",
+			`{"rules":[{"votetype":"other","vote":"yes"}]}`,
+			true,
+		},
+		{
+			"invalid amount range",
+			`{"rules":[{"amountover":1000,"amountunder":10,"vote":"yes"}]}`,
+			true,
+		},
+	}
+
+	fp := filepath.Join(dir, defaultRulesFilename)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ioutil.WriteFile(fp, []byte(test.rules), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = loadVoteRules(fp)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestVoteRulesDecide(t *testing.T) {
+	vr := voteRules{
+		Votes: map[string]string{
+			"explicit": "yes",
+			"invalid":  "maybe",
+		},
+		Rules: []voteRule{
+			{Domain: "marketing", Vote: voteAbstain},
+			{AmountOver: 100000, Vote: "no"},
+			{NameContains: "decred", AmountUnder: 1000, Vote: "yes"},
+			{VoteType: "runoff", Vote: "yes"},
+		},
+	}
+	options := []tkv1.VoteOption{
+		{ID: "yes", Bit: 2},
+		{ID: "no", Bit: 1},
+	}
+	proposal := func(token, name, domain string, amount uint64, vt tkv1.VoteT) ruleProposal {
+		return ruleProposal{
+			Token: token,
+			Metadata: piv1.ProposalMetadata{
+				Name:   name,
+				Domain: domain,
+				Amount: amount * 100,
+			},
+			Vote: tkv1.VoteParams{
+				Type:    vt,
+				Options: options,
+			},
+		}
+	}
+
+	var tests = []struct {
+		name     string
+		proposal ruleProposal
+		voteID   string
+		reason   string
+		wantErr  bool
+	}{
+		{
+			"explicit vote",
+			proposal("explicit", "", "marketing", 0,
+				tkv1.VoteTypeStandard),
+			"yes", "votes", false,
+		},
+		{
+			"invalid explicit vote",
+			proposal("invalid", "", "", 0, tkv1.VoteTypeStandard),
+			"", "", true,
+		},
+		{
+			"abstain",
+			proposal("a", "", "Marketing", 500000, tkv1.VoteTypeStandard),
+			"", "rule 1", false,
+		},
+		{
+			"amount over",
+			proposal("b", "", "development", 100001,
+				tkv1.VoteTypeStandard),
+			"no", "rule 2", false,
+		},
+		{
+			"amount not over",
+			proposal("c", "", "development", 100000,
+				tkv1.VoteTypeStandard),
+			"", "no matching rule", false,
+		},
+		{
+			"name and amount under",
+			proposal("d", "Decred Meetup", "development", 999,
+				tkv1.VoteTypeStandard),
+			"yes", "rule 3", false,
+		},
+		{
+			"vote type",
+			proposal("e", "", "development", 5000, tkv1.VoteTypeRunoff),
+			"yes", "rule 4", false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d, err := vr.decide(test.proposal)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if d.VoteID != test.voteID || d.Reason != test.reason {
+				t.Fatalf("got %v (%v), want %v (%v)", d.VoteID, d.Reason,
+					test.voteID, test.reason)
+			}
+		})
+	}
+}
@@ -46,6 +46,15 @@ const FileContents = `[Application Options]
 ; walletgrpccert=~/.dcrwallet/rpc.cert
 ; walletpassphrase=
 
+; The wallet API that is used to look up the eligible tickets and to sign the
+; votes. Valid options are grpc and jsonrpc. The default is grpc. The wallethost
+; defaults to the dcrwallet JSON-RPC port when jsonrpc is used. The JSON-RPC API
+; uses the walletgrpccert certificate and requires the dcrwallet RPC username
+; and password instead of client certificates.
+; signer=grpc
+; walletrpcuser=
+; walletrpcpass=
+
 ; Client certificates are required to communicate with dcrwallet. Generate a
 ; client certificate key using the gencerts utility that is provided by dcrd.
 ; For example, on a machine with a local wallet, client certificates can be
@@ -62,6 +71,17 @@ const FileContents = `[Application Options]
 ; clientcert=~/.politeiavoter/client.pem
 ; clientkey=~/.politeiavoter/client-key.pem
 
+; ------------------------------------------------------------------------------
+; Daemon
+; ------------------------------------------------------------------------------
+
+; The rules file contains the proposal votes that are automatically cast by the
+; daemon command. The rules file is reloaded every time the daemon polls for new
+; proposal votes.
+; rulesfile=~/.politeiavoter/rules.json
+
+; How often the daemon checks for proposal votes that have been started.
+; pollinterval=10m
 
 ; ------------------------------------------------------------------------------
 ; Debug
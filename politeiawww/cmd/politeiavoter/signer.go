@@ -0,0 +1,504 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	wtypes "decred.org/dcrwallet/rpc/jsonrpc/types"
+	pb "decred.org/dcrwallet/rpc/walletrpc"
+	"github.com/decred/dcrd/blockchain/stake/v3"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	// The following are the supported signer backends.
+	signerGRPC    = "grpc"
+	signerJSONRPC = "jsonrpc"
+
+	// walletUnlockTimeout is the number of seconds that the JSON-RPC
+	// wallet is unlocked for. The wallet locks itself once the timeout
+	// expires even if politeiavoter exits before locking it. The unlock
+	// is renewed while a long running signing is in progress.
+	walletUnlockTimeout = 60
+)
+
+// signer is the wallet that looks up the tickets that are able to vote and
+// signs the votes.
+type signer interface {
+	// BlockHeight returns the block height of the wallet. It is used to
+	// verify the connection to the wallet.
+	BlockHeight(ctx context.Context) (int64, error)
+
+	// CommittedTickets returns the tickets, out of the provided tickets,
+	// that the wallet is able to sign votes for along with their
+	// commitment addresses. Tickets that are tracked by imported xpub
+	// accounts are not returned since the wallet cannot sign with them.
+	CommittedTickets(ctx context.Context, tickets [][]byte) (*pb.CommittedTicketsResponse, error)
+
+	// Unlock verifies that the passphrase unlocks the wallet.
+	Unlock(ctx context.Context, passphrase []byte) error
+
+	// SignMessages signs the provided messages. The replies are returned
+	// in the same order as the messages.
+	SignMessages(ctx context.Context, passphrase []byte, msgs []*pb.SignMessagesRequest_Message) (*pb.SignMessagesResponse, error)
+
+	// Close closes the connection to the wallet.
+	Close() error
+}
+
+// newSigner returns the signer backend that is configured.
+func newSigner(cfg *config) (signer, error) {
+	switch cfg.Signer {
+	case signerGRPC:
+		return newGRPCSigner(cfg)
+	case signerJSONRPC:
+		return newJSONRPCSigner(cfg)
+	}
+	return nil, fmt.Errorf("invalid signer %q", cfg.Signer)
+}
+
+// grpcSigner is a signer that uses the dcrwallet gRPC API.
+type grpcSigner struct {
+	conn   *grpc.ClientConn
+	wallet pb.WalletServiceClient
+}
+
+// newGRPCSigner returns a new grpcSigner. The dcrwallet gRPC API requires
+// client certificate authentication.
+func newGRPCSigner(cfg *config) (*grpcSigner, error) {
+	serverCAs := x509.NewCertPool()
+	serverCert, err := ioutil.ReadFile(cfg.WalletCert)
+	if err != nil {
+		return nil, err
+	}
+	if !serverCAs.AppendCertsFromPEM(serverCert) {
+		return nil, fmt.Errorf("no certificates found in %s",
+			cfg.WalletCert)
+	}
+	keypair, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+	if err != nil {
+		return nil, fmt.Errorf("read client keypair: %v", err)
+	}
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{keypair},
+		RootCAs:      serverCAs,
+	})
+
+	conn, err := grpc.Dial(cfg.WalletHost,
+		grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcSigner{
+		conn:   conn,
+		wallet: pb.NewWalletServiceClient(conn),
+	}, nil
+}
+
+// BlockHeight returns the block height of the wallet.
+//
+// This function satisfies the signer interface.
+func (s *grpcSigner) BlockHeight(ctx context.Context) (int64, error) {
+	ar, err := s.wallet.Accounts(ctx, &pb.AccountsRequest{})
+	if err != nil {
+		return 0, err
+	}
+	return int64(ar.CurrentBlockHeight), nil
+}
+
+// CommittedTickets returns the tickets that the wallet is able to sign votes
+// for.
+//
+// This function satisfies the signer interface.
+func (s *grpcSigner) CommittedTickets(ctx context.Context, tickets [][]byte) (*pb.CommittedTicketsResponse, error) {
+	ctres, err := s.wallet.CommittedTickets(ctx,
+		&pb.CommittedTicketsRequest{
+			Tickets: tickets,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter out tickets tracked by imported xpub accounts.
+	filtered := make([]*pb.CommittedTicketsResponse_TicketAddress, 0,
+		len(ctres.TicketAddresses))
+	for _, t := range ctres.TicketAddresses {
+		r, err := s.wallet.GetTransaction(ctx, &pb.GetTransactionRequest{
+			TransactionHash: t.Ticket,
+		})
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		tx := new(wire.MsgTx)
+		err = tx.Deserialize(bytes.NewReader(r.Transaction.Transaction))
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		addr, err := stake.AddrFromSStxPkScrCommitment(tx.TxOut[1].PkScript, activeNetParams.Params)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		vr, err := s.wallet.ValidateAddress(ctx, &pb.ValidateAddressRequest{
+			Address: addr.String(),
+		})
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		if vr.AccountNumber >= 1<<31-1 { // imported xpub account
+			// do not append to filtered.
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	ctres.TicketAddresses = filtered
+
+	return ctres, nil
+}
+
+// Unlock verifies that the passphrase unlocks the wallet.
+//
+// This function satisfies the signer interface.
+func (s *grpcSigner) Unlock(ctx context.Context, passphrase []byte) error {
+	// This assumes the account is an HD account.
+	_, err := s.wallet.GetAccountExtendedPrivKey(ctx,
+		&pb.GetAccountExtendedPrivKeyRequest{
+			AccountNumber: 0, // TODO: make a config flag
+			Passphrase:    passphrase,
+		})
+	return err
+}
+
+// SignMessages signs the provided messages.
+//
+// This function satisfies the signer interface.
+func (s *grpcSigner) SignMessages(ctx context.Context, passphrase []byte, msgs []*pb.SignMessagesRequest_Message) (*pb.SignMessagesResponse, error) {
+	return s.wallet.SignMessages(ctx, &pb.SignMessagesRequest{
+		Passphrase: passphrase,
+		Messages:   msgs,
+	})
+}
+
+// Close closes the gRPC connection.
+//
+// This function satisfies the signer interface.
+func (s *grpcSigner) Close() error {
+	return s.conn.Close()
+}
+
+// jsonrpcSigner is a signer that uses the dcrwallet JSON-RPC API.
+type jsonrpcSigner struct {
+	id     uint64 // Request ID, atomic. Must be 64-bit aligned.
+	url    string
+	user   string
+	pass   string
+	client *http.Client
+}
+
+// newJSONRPCSigner returns a new jsonrpcSigner. The dcrwallet JSON-RPC API
+// uses basic authentication.
+func newJSONRPCSigner(cfg *config) (*jsonrpcSigner, error) {
+	serverCAs := x509.NewCertPool()
+	serverCert, err := ioutil.ReadFile(cfg.WalletCert)
+	if err != nil {
+		return nil, err
+	}
+	if !serverCAs.AppendCertsFromPEM(serverCert) {
+		return nil, fmt.Errorf("no certificates found in %s",
+			cfg.WalletCert)
+	}
+	return &jsonrpcSigner{
+		url:  "https://" + cfg.WalletHost,
+		user: cfg.WalletRPCUser,
+		pass: cfg.WalletRPCPass,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs: serverCAs,
+				},
+			},
+		},
+	}, nil
+}
+
+// rpcRequest is a JSON-RPC request.
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcError is a JSON-RPC error.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error satisfies the error interface.
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Code, e.Message)
+}
+
+// rpcResponse is a JSON-RPC response.
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+	ID     uint64          `json:"id"`
+}
+
+// call sends a JSON-RPC request to the wallet and decodes the result into
+// the provided result.
+func (s *jsonrpcSigner) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	b, err := json.Marshal(rpcRequest{
+		JSONRPC: "1.0",
+		ID:      atomic.AddUint64(&s.id, 1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url,
+		bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.user, s.pass)
+	req.Header.Set("Content-Type", "application/json")
+	r, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%v: invalid wallet rpc credentials", method)
+	}
+	var rr rpcResponse
+	err = json.NewDecoder(r.Body).Decode(&rr)
+	if err != nil {
+		return fmt.Errorf("%v: %v %v", method, r.StatusCode, err)
+	}
+	if rr.Error != nil {
+		return fmt.Errorf("%v: %v", method, rr.Error)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rr.Result, result)
+}
+
+// BlockHeight returns the block height of the wallet.
+//
+// This function satisfies the signer interface.
+func (s *jsonrpcSigner) BlockHeight(ctx context.Context) (int64, error) {
+	var height int64
+	err := s.call(ctx, "getblockcount", &height)
+	if err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+// CommittedTickets returns the tickets that the wallet is able to sign votes
+// for. The JSON-RPC API does not have a committed tickets command so the
+// commitment addresses are looked up using the ticket transactions of the
+// wallet.
+//
+// This function satisfies the signer interface.
+func (s *jsonrpcSigner) CommittedTickets(ctx context.Context, tickets [][]byte) (*pb.CommittedTicketsResponse, error) {
+	var gtr wtypes.GetTicketsResult
+	err := s.call(ctx, "gettickets", &gtr, true)
+	if err != nil {
+		return nil, err
+	}
+	owned := make(map[string]struct{}, len(gtr.Hashes))
+	for _, v := range gtr.Hashes {
+		owned[v] = struct{}{}
+	}
+
+	ctres := &pb.CommittedTicketsResponse{
+		TicketAddresses: make([]*pb.CommittedTicketsResponse_TicketAddress,
+			0, len(owned)),
+	}
+	for _, t := range tickets {
+		h, err := chainhash.NewHash(t)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := owned[h.String()]; !ok {
+			continue
+		}
+
+		var tr wtypes.GetTransactionResult
+		err = s.call(ctx, "gettransaction", &tr, h.String())
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		b, err := hex.DecodeString(tr.Hex)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		tx := new(wire.MsgTx)
+		err = tx.Deserialize(bytes.NewReader(b))
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		addr, err := stake.AddrFromSStxPkScrCommitment(tx.TxOut[1].PkScript, activeNetParams.Params)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		var vr wtypes.ValidateAddressWalletResult
+		err = s.call(ctx, "validateaddress", &vr, addr.String())
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		if !vr.IsMine || vr.IsWatchOnly {
+			// The wallet cannot sign with this address
+			continue
+		}
+		ctres.TicketAddresses = append(ctres.TicketAddresses,
+			&pb.CommittedTicketsResponse_TicketAddress{
+				Ticket:  t,
+				Address: addr.String(),
+			})
+	}
+
+	return ctres, nil
+}
+
+// walletUnlocked returns whether the wallet is currently unlocked.
+func (s *jsonrpcSigner) walletUnlocked(ctx context.Context) (bool, error) {
+	var wir wtypes.WalletInfoResult
+	err := s.call(ctx, "walletinfo", &wir)
+	if err != nil {
+		return false, err
+	}
+	return wir.Unlocked, nil
+}
+
+// Unlock verifies that the passphrase unlocks the wallet. The wallet is
+// locked again right away. A wallet that is already unlocked was unlocked by
+// someone other than politeiavoter and is left untouched.
+//
+// This function satisfies the signer interface.
+func (s *jsonrpcSigner) Unlock(ctx context.Context, passphrase []byte) error {
+	unlocked, err := s.walletUnlocked(ctx)
+	if err != nil {
+		return err
+	}
+	if unlocked {
+		return nil
+	}
+	err = s.call(ctx, "walletpassphrase", nil, string(passphrase),
+		walletUnlockTimeout)
+	if err != nil {
+		return err
+	}
+	return s.call(ctx, "walletlock", nil)
+}
+
+// SignMessages signs the provided messages. The wallet is unlocked for the
+// duration of the signing. The unlock has a bounded timeout so that the wallet
+// does not remain unlocked if politeiavoter is killed. It is renewed once half
+// of the timeout has passed. A wallet that is already unlocked was unlocked by
+// someone other than politeiavoter and is neither unlocked nor locked again.
+//
+// This function satisfies the signer interface.
+func (s *jsonrpcSigner) SignMessages(ctx context.Context, passphrase []byte, msgs []*pb.SignMessagesRequest_Message) (*pb.SignMessagesResponse, error) {
+	alreadyUnlocked, err := s.walletUnlocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var unlockedAt time.Time
+	unlock := func() error {
+		err := s.call(ctx, "walletpassphrase", nil, string(passphrase),
+			walletUnlockTimeout)
+		if err != nil {
+			return err
+		}
+		unlockedAt = time.Now()
+		return nil
+	}
+	if !alreadyUnlocked {
+		err := unlock()
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			err := s.call(context.Background(), "walletlock", nil)
+			if err != nil {
+				log.Errorf("walletlock: %v", err)
+			}
+		}()
+	}
+
+	smr := &pb.SignMessagesResponse{
+		Replies: make([]*pb.SignMessagesResponse_SignReply, 0, len(msgs)),
+	}
+	for _, v := range msgs {
+		if !alreadyUnlocked &&
+			time.Since(unlockedAt) > walletUnlockTimeout*time.Second/2 {
+			err := unlock()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var (
+			reply pb.SignMessagesResponse_SignReply
+			sig   string
+		)
+		err := s.call(ctx, "signmessage", &sig, v.Address, v.Message)
+		if err != nil {
+			reply.Error = err.Error()
+		} else {
+			reply.Signature, err = base64.StdEncoding.DecodeString(sig)
+			if err != nil {
+				reply.Error = err.Error()
+			}
+		}
+		smr.Replies = append(smr.Replies, &reply)
+	}
+
+	return smr, nil
+}
+
+// Close closes the idle wallet connections.
+//
+// This function satisfies the signer interface.
+func (s *jsonrpcSigner) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	wtypes "decred.org/dcrwallet/rpc/jsonrpc/types"
+	pb "decred.org/dcrwallet/rpc/walletrpc"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrec"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3"
+	"github.com/decred/dcrd/dcrec/secp256k1/v3/ecdsa"
+	"github.com/decred/dcrd/dcrutil/v3"
+	"github.com/decred/dcrd/wire"
+)
+
+// testKey is a wallet key that is used to sign messages in tests.
+type testKey struct {
+	key     *secp256k1.PrivateKey
+	address string
+}
+
+func newTestKey(t *testing.T) *testKey {
+	t.Helper()
+
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkh := dcrutil.Hash160(key.PubKey().SerializeCompressed())
+	addr, err := dcrutil.NewAddressPubKeyHash(pkh, activeNetParams.Params,
+		dcrec.STEcdsaSecp256k1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKey{
+		key:     key,
+		address: addr.String(),
+	}
+}
+
+// sign returns the compact signature of a Decred signed message.
+func (k *testKey) sign(msg string) []byte {
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, "Decred Signed Message:\n")
+	wire.WriteVarString(&buf, 0, msg)
+	return ecdsa.SignCompact(k.key, chainhash.HashB(buf.Bytes()), true)
+}
+
+// testSigner is a signer that signs the messages of the provided tickets
+// with the test key.
+type testSigner struct {
+	key     *testKey
+	tickets map[chainhash.Hash]struct{}
+}
+
+func (s *testSigner) BlockHeight(ctx context.Context) (int64, error) {
+	return 1, nil
+}
+
+func (s *testSigner) CommittedTickets(ctx context.Context, tickets [][]byte) (*pb.CommittedTicketsResponse, error) {
+	var ctres pb.CommittedTicketsResponse
+	for _, v := range tickets {
+		h, err := chainhash.NewHash(v)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.tickets[*h]; !ok {
+			continue
+		}
+		ctres.TicketAddresses = append(ctres.TicketAddresses,
+			&pb.CommittedTicketsResponse_TicketAddress{
+				Ticket:  v,
+				Address: s.key.address,
+			})
+	}
+	return &ctres, nil
+}
+
+func (s *testSigner) Unlock(ctx context.Context, passphrase []byte) error {
+	return nil
+}
+
+func (s *testSigner) SignMessages(ctx context.Context, passphrase []byte, msgs []*pb.SignMessagesRequest_Message) (*pb.SignMessagesResponse, error) {
+	var smr pb.SignMessagesResponse
+	for _, v := range msgs {
+		smr.Replies = append(smr.Replies, &pb.SignMessagesResponse_SignReply{
+			Signature: s.key.sign(v.Message),
+		})
+	}
+	return &smr, nil
+}
+
+func (s *testSigner) Close() error {
+	return nil
+}
+
+func TestJSONRPCSigner(t *testing.T) {
+	c, cleanup := fakePiv(t, 0, 1)
+	defer cleanup()
+
+	key := newTestKey(t)
+	var unlocked bool
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		reply := rpcResponse{ID: req.ID}
+		var result interface{}
+		switch req.Method {
+		case "getblockcount":
+			result = 100
+		case "walletinfo":
+			result = wtypes.WalletInfoResult{Unlocked: unlocked}
+		case "walletpassphrase":
+			// The wallet must not be unlocked indefinitely
+			var timeout int64
+			err := json.Unmarshal(req.Params[1], &timeout)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if timeout <= 0 || timeout > walletUnlockTimeout {
+				t.Errorf("got unlock timeout %v, want 1-%v", timeout,
+					walletUnlockTimeout)
+			}
+			unlocked = true
+		case "walletlock":
+			unlocked = false
+		case "signmessage":
+			if !unlocked {
+				reply.Error = &rpcError{Code: -13, Message: "locked"}
+				break
+			}
+			var msg string
+			err := json.Unmarshal(req.Params[1], &msg)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			result = base64.StdEncoding.EncodeToString(key.sign(msg))
+		default:
+			reply.Error = &rpcError{Code: -32601, Message: "not found"}
+		}
+		reply.Result, err = json.Marshal(result)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		err = json.NewEncoder(w).Encode(reply)
+		if err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	// Write the server certificate
+	certFile := filepath.Join(c.cfg.HomeDir, "rpc.cert")
+	cert := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: ts.Certificate().Raw,
+	})
+	err := ioutil.WriteFile(certFile, cert, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.cfg.Signer = signerJSONRPC
+	c.cfg.WalletCert = certFile
+	c.cfg.WalletHost = ts.Listener.Addr().String()
+	c.cfg.WalletRPCUser = "user"
+	c.cfg.WalletRPCPass = "pass"
+	s, err := newSigner(c.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+
+	height, err := s.BlockHeight(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 100 {
+		t.Fatalf("got height %v, want 100", height)
+	}
+
+	// Verify the signatures and that the wallet is locked again
+	msgs := []*pb.SignMessagesRequest_Message{
+		{Address: key.address, Message: "message 1"},
+		{Address: key.address, Message: "message 2"},
+	}
+	smr, err := s.SignMessages(ctx, []byte("passphrase"), msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(smr.Replies) != len(msgs) {
+		t.Fatalf("got %v replies, want %v", len(smr.Replies), len(msgs))
+	}
+	for k, v := range smr.Replies {
+		if v.Error != "" {
+			t.Fatalf("reply %v: %v", k, v.Error)
+		}
+		sig := base64.StdEncoding.EncodeToString(v.Signature)
+		ok, err := verifyMessage(activeNetParams.Params, key.address,
+			msgs[k].Message, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("reply %v: invalid signature", k)
+		}
+	}
+	if unlocked {
+		t.Fatal("wallet not locked after signing")
+	}
+
+	// Verify that a wallet that was already unlocked is not locked
+	unlocked = true
+	err = s.Unlock(ctx, []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.SignMessages(ctx, []byte("passphrase"), msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !unlocked {
+		t.Fatal("wallet locked after signing")
+	}
+
+	// Verify invalid credentials are reported
+	c.cfg.WalletRPCPass = "wrong"
+	s, err = newSigner(c.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.BlockHeight(ctx)
+	if err == nil {
+		t.Fatal("expected invalid credentials error")
+	}
+}
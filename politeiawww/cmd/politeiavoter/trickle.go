@@ -51,7 +51,7 @@ type voteAlarm struct {
 	At   time.Time     `json:"at"`   // When initial vote will be submitted
 }
 
-func (p *piv) generateVoteAlarm(token, voteBit string, ctres *pb.CommittedTicketsResponse, smr *pb.SignMessagesResponse) ([]*voteAlarm, error) {
+func (p *piv) generateVoteAlarm(token, voteBit string, voteDuration time.Duration, ctres *pb.CommittedTicketsResponse, smr *pb.SignMessagesResponse) ([]*voteAlarm, error) {
 	// Assert arrays are same length.
 	if len(ctres.TicketAddresses) != len(smr.Replies) {
 		return nil, fmt.Errorf("assert len(TicketAddresses) != "+
@@ -60,7 +60,6 @@ func (p *piv) generateVoteAlarm(token, voteBit string, ctres *pb.CommittedTicket
 	}
 
 	bunches := int(p.cfg.Bunches)
-	voteDuration := p.cfg.voteDuration
 	fmt.Printf("Total number of votes  : %v\n", len(ctres.TicketAddresses))
 	fmt.Printf("Total number of bunches: %v\n", bunches)
 	fmt.Printf("Vote duration          : %v\n", voteDuration)
@@ -299,9 +298,10 @@ func randomTime(d time.Duration) (time.Time, time.Time, error) {
 	return time.Unix(startTime, 0), time.Unix(endTime, 0), nil
 }
 
-func (p *piv) alarmTrickler(token, voteBit string, ctres *pb.CommittedTicketsResponse, smr *pb.SignMessagesResponse) error {
+func (p *piv) alarmTrickler(token, voteBit string, voteDuration time.Duration, ctres *pb.CommittedTicketsResponse, smr *pb.SignMessagesResponse) error {
 	// Generate work queue
-	votes, err := p.generateVoteAlarm(token, voteBit, ctres, smr)
+	votes, err := p.generateVoteAlarm(token, voteBit, voteDuration,
+		ctres, smr)
 	if err != nil {
 		return err
 	}
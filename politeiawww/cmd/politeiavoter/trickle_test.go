@@ -74,7 +74,8 @@ func TestTrickleWorkers(t *testing.T) {
 
 	nrVotes := uint(20)
 	ctres, smr := fakeTickets(nrVotes)
-	err := c.alarmTrickler("token", "voteBit", ctres, smr)
+	err := c.alarmTrickler("token", "voteBit", c.cfg.voteDuration,
+		ctres, smr)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -87,7 +88,8 @@ func TestUnrecoverableTrickleWorkers(t *testing.T) {
 	c.cfg.testingMode = testFailUnrecoverable
 
 	ctres, smr := fakeTickets(1)
-	err := c.alarmTrickler("token", "voteBit", ctres, smr)
+	err := c.alarmTrickler("token", "voteBit", c.cfg.voteDuration,
+		ctres, smr)
 	if err == nil {
 		t.Fatal("expected unrecoverable error")
 	}
@@ -104,7 +106,8 @@ func TestManyTrickleWorkers(t *testing.T) {
 
 	nrVotes := uint(20000)
 	ctres, smr := fakeTickets(nrVotes)
-	err := c.alarmTrickler("token", "voteBit", ctres, smr)
+	err := c.alarmTrickler("token", "voteBit", c.cfg.voteDuration,
+		ctres, smr)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -93,6 +93,7 @@ var (
 	createKey        = flag.Bool("createkey", false, "")
 	verifyIdentities = flag.Bool("verifyidentities", false, "")
 	resetTotp        = flag.Bool("resettotp", false, "")
+	resetWebAuthn    = flag.Bool("resetwebauthn", false, "")
 
 	network string // Mainnet or testnet3
 	userDB  user.Database
@@ -177,7 +178,12 @@ const usageMsg = `politeiawww_dbutil usage:
           confirm identity. 
           Required DB flag : -leveldb, -cockroachdb or -mysql
           LevelDB args     : <email>
-          CockroachDB args : <username>`
+          CockroachDB args : <username>
+    -resetwebauthn
+          Remove a user's webauthn credentials and backup codes in case they
+          are locked out and confirm identity.
+          Required DB flag : -leveldb, -cockroachdb or -mysql
+          Args             : <username>`
 
 func cmdDump() error {
 	// If email is provided, only dump that user.
@@ -820,6 +826,33 @@ func cmdResetTOTP() error {
 	return nil
 }
 
+func cmdResetWebAuthn() error {
+	args := flag.Args()
+	if len(args) != 1 {
+		return fmt.Errorf("invalid number of arguments; want <username>, got %v",
+			args)
+	}
+
+	username := args[0]
+	u, err := userDB.UserGetByUsername(username)
+	if err != nil {
+		return err
+	}
+
+	u.WebAuthnCredentials = nil
+	u.WebAuthnBackupCodes = nil
+	u.WebAuthnChallenge = nil
+
+	err = userDB.UserUpdate(*u)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("User with username '%v' reset webauthn\n", username)
+
+	return nil
+}
+
 func _main() error {
 	flag.Parse()
 
@@ -845,7 +878,8 @@ func _main() error {
 	}
 
 	switch {
-	case *addCredits || *setAdmin || *stubUsers || *resetTotp:
+	case *addCredits || *setAdmin || *stubUsers || *resetTotp ||
+		*resetWebAuthn:
 		// These commands must be run with -cockroachdb, -mysql or -leveldb.
 		if !*level && !*cockroach && !*mysql {
 			return fmt.Errorf("missing database flag; must use " +
@@ -911,6 +945,8 @@ func _main() error {
 		return cmdVerifyIdentities()
 	case *resetTotp:
 		return cmdResetTOTP()
+	case *resetWebAuthn:
+		return cmdResetWebAuthn()
 	default:
 		fmt.Printf("invalid command\n")
 		flag.Usage()
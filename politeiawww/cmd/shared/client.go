@@ -1631,6 +1631,35 @@ func (c *Client) UserPaymentsRescan(upr *www.UserPaymentsRescan) (*www.UserPayme
 	return &uprr, nil
 }
 
+// MailQueue retrieves the emails in the outbound mail queue that have the
+// provided status. This call requires admin privileges.
+func (c *Client) MailQueue(mq *www.MailQueue) (*www.MailQueueReply, error) {
+	statusCode, respBody, err := c.makeRequest(http.MethodGet,
+		www.PoliteiaWWWAPIRoute, www.RouteMailQueue, mq)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, wwwError(respBody, statusCode)
+	}
+
+	var mqr www.MailQueueReply
+	err = json.Unmarshal(respBody, &mqr)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal MailQueueReply: %v", err)
+	}
+
+	if c.cfg.Verbose {
+		err := prettyPrintJSON(mqr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &mqr, nil
+}
+
 // UserProposalCredits retrieves the proposal credit history for the logged
 // in user.
 func (c *Client) UserProposalCredits() (*www.UserProposalCreditsReply, error) {
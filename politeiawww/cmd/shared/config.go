@@ -51,7 +51,7 @@ type Config struct {
 	Host        string `long:"host" description:"politeiawww host"`
 	HTTPSCert   string `long:"httpscert" description:"politeiawww https cert"`
 	SkipVerify  bool   `long:"skipverify" description:"Skip verifying the server's certifcate chain and host name"`
-	RawJSON     bool   `short:"j" long:"json" description:"Print raw JSON output"`
+	RawJSON     bool   `short:"j" long:"json" description:"Print the raw JSON replies"`
 	Verbose     bool   `short:"v" long:"verbose" description:"Print verbose output"`
 	Silent      bool   `long:"silent" description:"Suppress all output"`
 	Timer       bool   `long:"timer" description:"Print command execution time stats"`
@@ -68,8 +68,9 @@ func (p *politeiawww) newUserCmd(ctx context.Context, session *plugin.Session, c
 	pluginUser := convertUser(usr, cmd.PluginID)
 	reply, err = p.userManager.NewUser(tx,
 		plugin.WriteArgs{
-			Cmd:  cmd,
-			User: pluginUser,
+			Cmd:     cmd,
+			User:    pluginUser,
+			Session: session,
 		})
 	if err != nil {
 		return nil, err
@@ -178,8 +179,9 @@ func (p *politeiawww) writeCmd(ctx context.Context, session *plugin.Session, cmd
 	pluginUser := convertUser(usr, cmd.PluginID)
 	reply, err = plug.WriteTx(tx,
 		plugin.WriteArgs{
-			Cmd:  cmd,
-			User: pluginUser,
+			Cmd:     cmd,
+			User:    pluginUser,
+			Session: p.pluginSession(cmd.PluginID, session),
 		})
 	if err != nil {
 		return nil, err
@@ -268,8 +270,9 @@ func (p *politeiawww) readCmd(ctx context.Context, session *plugin.Session, cmd
 		return nil, errors.Errorf("plugin not found: %v", cmd.PluginID)
 	}
 	reply, err = plug.Read(plugin.ReadArgs{
-		Cmd:  cmd,
-		User: convertUser(usr, cmd.PluginID),
+		Cmd:     cmd,
+		User:    convertUser(usr, cmd.PluginID),
+		Session: p.pluginSession(cmd.PluginID, session),
 	})
 	if err != nil {
 		return nil, err
@@ -324,7 +327,7 @@ func (p *politeiawww) hook(tx *sql.Tx, h plugin.HookArgs, usr *user.User) error
 		}
 
 		// Add the plugin user to the hook payload
-		h.User = convertUser(usr, h.Cmd.PluginID)
+		h.User = convertUser(usr, pluginID)
 
 		// Execute the hook. Some commands will execute
 		// the hook using a database transaction (write
@@ -343,7 +346,7 @@ func (p *politeiawww) hook(tx *sql.Tx, h plugin.HookArgs, usr *user.User) error
 
 		// Update the global user object with any changes
 		// that the plugin made to the plugin user data.
-		updateUser(usr, h.User, h.Cmd.PluginID)
+		updateUser(usr, h.User, pluginID)
 	}
 
 	return nil
@@ -374,15 +377,35 @@ func (p *politeiawww) authorize(s *plugin.Session, usr *user.User, cmd plugin.Cm
 
 	// Update the global user object with any changes
 	// that the plugin made to the plugin user data.
-	updateUser(usr, pluginUser, cmd.PluginID)
+	updateUser(usr, pluginUser, p.authManager.ID())
+
+	// Populate the permissions that the user has been
+	// granted. These are provided to the plugins.
+	if usr != nil {
+		perms, err := p.authManager.UserPermissions(pluginUser)
+		if err != nil {
+			return nil, err
+		}
+		usr.Permissions = perms
+	}
 
 	return nil, nil
 }
 
+// pluginSession returns the session that is provided to a plugin during the
+// execution of a plugin command. The session is only provided to the user
+// manager plugin. All other plugins receive a nil session.
+func (p *politeiawww) pluginSession(pluginID string, s *plugin.Session) *plugin.Session {
+	if p.userManager == nil || p.userManager.ID() != pluginID {
+		return nil
+	}
+	return s
+}
+
 // updateUser updates the global user object with any changes that were made
 // to the plugin user object during plugin command execution.
 func updateUser(u *user.User, p *plugin.User, pluginID string) {
-	if !p.PluginData.Updated() {
+	if u == nil || p == nil || !p.PluginData.Updated() {
 		return
 	}
 
@@ -396,13 +419,19 @@ func updateUser(u *user.User, p *plugin.User, pluginID string) {
 
 // convertUser converts a global user to a plugin user. Only the plugin data
 // for the provided plugin ID is included in the plugin user object. This
-// prevents plugins from accessing data that they do not own.
+// prevents plugins from accessing data that they do not own. A nil plugin
+// user is returned if the provided user is nil, i.e. there is no logged in
+// user.
 func convertUser(u *user.User, pluginID string) *plugin.User {
+	if u == nil {
+		return nil
+	}
 	pluginData := u.Plugins[pluginID]
 	return &plugin.User{
 		ID: u.ID,
 		PluginData: plugin.NewPluginData(pluginData.ClearText,
 			pluginData.Encrypted),
+		Permissions: u.Permissions,
 	}
 }
 
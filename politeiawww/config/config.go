@@ -93,8 +93,9 @@ const (
 	CockroachDB = "cockroachdb"
 	MySQL       = "mysql"
 
-	defaultMySQLDBHost     = "localhost:3306"
-	defaultCockroachDBHost = "localhost:26257"
+	defaultMySQLDBHost       = "localhost:3306"
+	defaultCockroachDBHost   = "localhost:26257"
+	defaultUserDBKeyFilename = "userdb.key"
 
 	// SMTP settings
 	defaultMailAddress = "Politeia <noreply@example.org>"
@@ -134,6 +135,8 @@ type Config struct {
 	ReqBodySizeLimit   int64    `long:"reqbodysizelimit" description:"Maximum number of bytes allowed in a request body submitted by a client"`
 	WebsocketReadLimit int64    `long:"websocketreadlimit" description:"Maximum number of bytes allowed for a message read from a websocket client"`
 	PluginBatchLimit   uint32   `long:"pluginbatchlimit" description:"Maximum number of plugins command allowed in a batch request."`
+	RateLimits         []string `long:"ratelimit" description:"Rate limit policy in the format target,key,rate/interval,burst -- The target is a route or a plugin command in the format pluginID.cmd, the key is ip or user, and the interval is s, m, or h"`
+	TrustedProxies     []string `long:"trustedproxy" description:"IP address or CIDR of a trusted reverse proxy -- The X-Forwarded-For header is only used to identify clients for requests that were sent by a trusted proxy"`
 
 	// politeiad RPC settings
 	RPCHost         string `long:"rpchost" description:"politeiad host <host>:<port>"`
@@ -145,9 +148,10 @@ type Config struct {
 	Interactive     string `long:"interactive" description:"Set to i-know-this-is-a-bad-idea to turn off interactive mode during --fetchidentity"`
 
 	// User database settings
-	UserDB string `long:"userdb" description:"Database choice for the user database"`
-	DBHost string `long:"dbhost" description:"Database ip:port"`
-	DBPass string // Provided in env variable "DBPASS"
+	UserDB    string `long:"userdb" description:"Database choice for the user database"`
+	DBHost    string `long:"dbhost" description:"Database ip:port"`
+	DBPass    string // Provided in env variable "DBPASS"
+	UserDBKey string `long:"userdbkey" description:"File containing the key used to encrypt user plugin data at rest"`
 
 	// SMTP settings
 	MailHost       string `long:"mailhost" description:"Email server address <host>:<port>"`
@@ -555,6 +559,13 @@ func setupUserDBSettings(cfg *Config) error {
 				cfg.DBHost, err)
 		}
 
+		// Setup the encryption key file path. The key
+		// is created on startup if it does not exist.
+		if cfg.UserDBKey == "" {
+			cfg.UserDBKey = filepath.Join(cfg.HomeDir, defaultUserDBKeyFilename)
+		}
+		cfg.UserDBKey = util.CleanAndExpandPath(cfg.UserDBKey)
+
 		// Pull password from env variable
 		cfg.DBPass = os.Getenv(envDBPass)
 		if cfg.DBPass == "" {
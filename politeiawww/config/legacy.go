@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"net/url"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/decred/dcrd/hdkeychain/v3"
@@ -74,6 +75,11 @@ type LegacyConfig struct {
 	// Settings the need to be turned into plugin settings.
 	MailRateLimit    int    `long:"mailratelimit" description:"Limits the amount of emails a user can receive in 24h"`
 	WebServerAddress string `long:"webserveraddress" description:"Web server address used to create email links (format: <scheme>://<host>[:<port>])"`
+	MailTemplatesDir string `long:"mailtemplatesdir" description:"Directory containing the localized notification email templates (default: built-in templates)"`
+
+	// Legacy WebAuthn settings
+	WebAuthnRPID   string `long:"webauthnrpid" description:"WebAuthn relying party ID (default: webserveraddress host)"`
+	WebAuthnOrigin string `long:"webauthnorigin" description:"WebAuthn origin (default: webserveraddress)"`
 
 	// Legacy API settings
 	Mode        string `long:"mode" description:"Mode www runs as. Supported values: piwww, cmswww"`
@@ -141,6 +147,15 @@ func setupLegacyConfig(cfg *Config) error {
 			"webserveraddress")
 	}
 
+	// Verify the mail templates directory
+	if cfg.MailTemplatesDir != "" {
+		cfg.MailTemplatesDir = util.CleanAndExpandPath(cfg.MailTemplatesDir)
+		if !util.FileExists(cfg.MailTemplatesDir) {
+			return fmt.Errorf("mail templates dir '%v' not found",
+				cfg.MailTemplatesDir)
+		}
+	}
+
 	// Verify the webserver address
 	_, err = url.Parse(cfg.WebServerAddress)
 	if err != nil {
@@ -148,6 +163,21 @@ func setupLegacyConfig(cfg *Config) error {
 			cfg.WebServerAddress, err)
 	}
 
+	// Setup the WebAuthn relying party settings. These default to
+	// the webserver address. WebAuthn is disabled when neither the
+	// WebAuthn settings nor the webserver address have been set.
+	if cfg.WebAuthnOrigin == "" {
+		cfg.WebAuthnOrigin = strings.TrimSuffix(cfg.WebServerAddress, "/")
+	}
+	if cfg.WebAuthnRPID == "" && cfg.WebAuthnOrigin != "" {
+		u, err := url.Parse(cfg.WebAuthnOrigin)
+		if err != nil {
+			return fmt.Errorf("invalid webauthnorigin setting '%v': %v",
+				cfg.WebAuthnOrigin, err)
+		}
+		cfg.WebAuthnRPID = u.Hostname()
+	}
+
 	// Verify the dcrdata host
 	if cfg.DcrdataHost == "" {
 		if cfg.TestNet {
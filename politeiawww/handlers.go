@@ -71,6 +71,16 @@ func (p *politeiawww) handlePolicy(w http.ResponseWriter, r *http.Request) {
 		})
 }
 
+// handleRateLimits is the request handler for the http v3 RateLimitsRoute.
+func (p *politeiawww) handleRateLimits(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleRateLimits")
+
+	util.RespondWithJSON(w, http.StatusOK,
+		v3.RateLimitsReply{
+			RateLimits: convertRateLimitsToHTTP(p.rateLimiter.Stats()),
+		})
+}
+
 // handleNewUser is the request handler for the http v3 NewUserRoute.
 func (p *politeiawww) handleNewUser(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleNewUser")
@@ -88,6 +98,18 @@ func (p *politeiawww) handleNewUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// API keys cannot be used to create users
+	if r.Header.Get(v3.APIKeyHeader) != "" {
+		util.RespondWithJSON(w, http.StatusOK,
+			v3.CmdReply{
+				Error: v3.UserError{
+					ErrorCode:    v3.ErrorCodeInvalidInput,
+					ErrorContext: "api keys cannot be used to create users",
+				},
+			})
+		return
+	}
+
 	// Verify the plugin is the user plugin
 	if p.userManager.ID() != cmd.PluginID {
 		util.RespondWithJSON(w, http.StatusOK,
@@ -99,6 +121,11 @@ func (p *politeiawww) handleNewUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Apply the plugin command rate limits
+	if !p.allowCmds(w, r, []v3.Cmd{cmd}) {
+		return
+	}
+
 	// Extract the session data from the request cookies
 	s, err := p.extractSession(r)
 	if err != nil {
@@ -163,17 +190,36 @@ func (p *politeiawww) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract the session data from the request cookies
-	s, err := p.extractSession(r)
+	// Apply the plugin command rate limits
+	if !p.allowCmds(w, r, []v3.Cmd{cmd}) {
+		return
+	}
+
+	// Extract the session data from the request. Requests that
+	// contain an API key are authenticated using the API key
+	// instead of the session cookie.
+	s, err := p.extractReqSession(r)
 	if err != nil {
 		respondWithError(w, r,
-			"handleWrite: extractSession: %v", err)
+			"handleWrite: extractReqSession: %v", err)
+		return
+	}
+
+	// Verify that the API key, if one was provided, is allowed
+	// to execute the plugin command.
+	if !apiKeyAllowed(s.apiKey, cmd, true) {
+		util.RespondWithJSON(w, http.StatusOK,
+			v3.CmdReply{
+				Error: v3.UserError{
+					ErrorCode: v3.ErrorCodeAPIKeyScope,
+				},
+			})
 		return
 	}
 
 	// Execute the plugin command
 	var (
-		pluginSession = convertSession(s)
+		pluginSession = s.plugin
 		pluginCmd     = convertCmdFromHTTP(cmd)
 	)
 	pluginReply, err := p.writeCmd(r.Context(), pluginSession, pluginCmd)
@@ -186,7 +232,7 @@ func (p *politeiawww) handleWrite(w http.ResponseWriter, r *http.Request) {
 	reply := convertReplyToHTTP(pluginCmd, *pluginReply)
 
 	// Save any updates that were made to the user session
-	err = p.saveUserSession(r, w, s, pluginSession)
+	err = p.saveReqSession(r, w, s)
 	if err != nil {
 		// The plugin command has already been executed.
 		// Handled the error gracefully.
@@ -226,17 +272,36 @@ func (p *politeiawww) handleRead(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract the session data from the request cookies
-	s, err := p.extractSession(r)
+	// Apply the plugin command rate limits
+	if !p.allowCmds(w, r, []v3.Cmd{cmd}) {
+		return
+	}
+
+	// Extract the session data from the request. Requests that
+	// contain an API key are authenticated using the API key
+	// instead of the session cookie.
+	s, err := p.extractReqSession(r)
 	if err != nil {
 		respondWithError(w, r,
-			"handleRead: extractSession: %v", err)
+			"handleRead: extractReqSession: %v", err)
+		return
+	}
+
+	// Verify that the API key, if one was provided, is allowed
+	// to execute the plugin command.
+	if !apiKeyAllowed(s.apiKey, cmd, false) {
+		util.RespondWithJSON(w, http.StatusOK,
+			v3.CmdReply{
+				Error: v3.UserError{
+					ErrorCode: v3.ErrorCodeAPIKeyScope,
+				},
+			})
 		return
 	}
 
 	// Execute the plugin command
 	var (
-		pluginSession = convertSession(s)
+		pluginSession = s.plugin
 		pluginCmd     = convertCmdFromHTTP(cmd)
 	)
 	pluginReply, err := p.readCmd(r.Context(), pluginSession, pluginCmd)
@@ -249,7 +314,7 @@ func (p *politeiawww) handleRead(w http.ResponseWriter, r *http.Request) {
 	reply := convertReplyToHTTP(pluginCmd, *pluginReply)
 
 	// Save any updates that were made to the user session
-	err = p.saveUserSession(r, w, s, pluginSession)
+	err = p.saveReqSession(r, w, s)
 	if err != nil {
 		// The plugin command has already been executed.
 		// Handle the error gracefully.
@@ -288,16 +353,25 @@ func (p *politeiawww) handleReadBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract the session data from the request cookies
-	s, err := p.extractSession(r)
+	// Apply the plugin command rate limits. All commands are checked
+	// before any of them are executed.
+	if !p.allowCmds(w, r, batch.Cmds) {
+		return
+	}
+
+	// Extract the session data from the request. Requests that
+	// contain an API key are authenticated using the API key
+	// instead of the session cookie. All API keys are allowed to
+	// execute read-only commands.
+	s, err := p.extractReqSession(r)
 	if err != nil {
 		respondWithError(w, r,
-			"handleReadBatch: extractSession: %v", err)
+			"handleReadBatch: extractReqSession: %v", err)
 		return
 	}
 
 	var (
-		pluginSession = convertSession(s)
+		pluginSession = s.plugin
 		replies       = make([]v3.CmdReply, len(batch.Cmds))
 	)
 	for i, cmd := range batch.Cmds {
@@ -325,7 +399,7 @@ func (p *politeiawww) handleReadBatch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save any updates that were made to the user session
-	err = p.saveUserSession(r, w, s, pluginSession)
+	err = p.saveReqSession(r, w, s)
 	if err != nil {
 		// The plugin command has already been executed. Handle
 		// the error gracefully.
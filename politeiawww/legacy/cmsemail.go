@@ -7,8 +7,9 @@ package legacy
 import (
 	"net/url"
 	"strings"
-	"text/template"
 	"time"
+
+	"github.com/decred/politeia/politeiawww/mail"
 )
 
 const (
@@ -17,6 +18,21 @@ const (
 	guiRouteDCCDetails = "/dcc/{token}"
 )
 
+// The following are the names of the CMS notification email templates. The
+// built-in templates are defined in this file and can be overridden using the
+// mail templates directory.
+const (
+	mailTmplUserCMSInvite             = "cms-user-invite"
+	mailTmplUserDCCApproved           = "cms-user-dcc-approved"
+	mailTmplDCCSubmitted              = "cms-dcc-submitted"
+	mailTmplDCCSupportOppose          = "cms-dcc-support-oppose"
+	mailTmplInvoiceStatusUpdate       = "cms-invoice-status-update"
+	mailTmplInvoiceFirstNotification  = "cms-invoice-first-notification"
+	mailTmplInvoiceSecondNotification = "cms-invoice-second-notification"
+	mailTmplInvoiceFinalNotification  = "cms-invoice-final-notification"
+	mailTmplInvoiceNewComment         = "cms-invoice-new-comment"
+)
+
 // emailUserCMSInvite emails the invitation link for the Contractor Management
 // System to the provided user email address.
 func (p *Politeiawww) emailUserCMSInvite(email, token string) error {
@@ -30,14 +46,9 @@ func (p *Politeiawww) emailUserCMSInvite(email, token string) error {
 		Link:  link,
 	}
 
-	subject := "Welcome to the Contractor Management System"
-	body, err := createBody(userCMSInviteTmpl, tplData)
-	if err != nil {
-		return err
-	}
 	recipients := []string{email}
 
-	return p.mail.SendTo(subject, body, recipients)
+	return p.mail.SendTo("", mailTmplUserCMSInvite, tplData, recipients)
 }
 
 // emailUserDCCApproved emails the link to invite a user that has been approved
@@ -47,14 +58,9 @@ func (p *Politeiawww) emailUserDCCApproved(email string) error {
 		Email: email,
 	}
 
-	subject := "Congratulations, You've been approved!"
-	body, err := createBody(userDCCApprovedTmpl, tplData)
-	if err != nil {
-		return err
-	}
 	recipients := []string{email}
 
-	return p.mail.SendTo(subject, body, recipients)
+	return p.mail.SendTo("", mailTmplUserDCCApproved, tplData, recipients)
 }
 
 // emailDCCSubmitted sends email regarding the DCC New event. Sends email
@@ -70,13 +76,7 @@ func (p *Politeiawww) emailDCCSubmitted(token string, emails []string) error {
 		Link: l.String(),
 	}
 
-	subject := "New DCC Submitted"
-	body, err := createBody(dccSubmittedTmpl, tplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendTo(subject, body, emails)
+	return p.mail.SendTo("", mailTmplDCCSubmitted, tplData, emails)
 }
 
 // emailDCCSupportOppose sends emails regarding dcc support/oppose event.
@@ -92,13 +92,7 @@ func (p *Politeiawww) emailDCCSupportOppose(token string, emails []string) error
 		Link: l.String(),
 	}
 
-	subject := "New DCC Support/Opposition Submitted"
-	body, err := createBody(dccSupportOpposeTmpl, tplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendTo(subject, body, emails)
+	return p.mail.SendTo("", mailTmplDCCSupportOppose, tplData, emails)
 }
 
 // emailInvoiceStatusUpdate sends email for the invoice status update event.
@@ -108,19 +102,14 @@ func (p *Politeiawww) emailInvoiceStatusUpdate(invoiceToken, userEmail string) e
 		Token: invoiceToken,
 	}
 
-	subject := "Invoice status has been updated"
-	body, err := createBody(invoiceStatusUpdateTmpl, tplData)
-	if err != nil {
-		return err
-	}
 	recipients := []string{userEmail}
 
-	return p.mail.SendTo(subject, body, recipients)
+	return p.mail.SendTo("", mailTmplInvoiceStatusUpdate, tplData, recipients)
 }
 
 // emailInvoiceNotifications emails users that have not yet submitted an
 // invoice for the given month/year
-func (p *Politeiawww) emailInvoiceNotifications(email, username, subject string, tmpl *template.Template) error {
+func (p *Politeiawww) emailInvoiceNotifications(email, username, tmpl string) error {
 	// Set the date to the first day of the previous month.
 	newDate := time.Date(time.Now().Year(), time.Now().Month()-1, 1, 0, 0, 0, 0, time.UTC)
 	tplData := invoiceNotification{
@@ -128,28 +117,18 @@ func (p *Politeiawww) emailInvoiceNotifications(email, username, subject string,
 		Month:    newDate.Month().String(),
 		Year:     newDate.Year(),
 	}
-	body, err := createBody(tmpl, &tplData)
-	if err != nil {
-		return err
-	}
+
 	recipients := []string{email}
 
-	return p.mail.SendTo(subject, body, recipients)
+	return p.mail.SendTo("", tmpl, &tplData, recipients)
 }
 
 // emailInvoiceNewComment sends email for the invoice new comment event. Send
 // email to the provided user email address.
 func (p *Politeiawww) emailInvoiceNewComment(userEmail string) error {
-	var tplData interface{}
-	subject := "New Invoice Comment"
-
-	body, err := createBody(invoiceNewCommentTmpl, tplData)
-	if err != nil {
-		return err
-	}
 	recipients := []string{userEmail}
 
-	return p.mail.SendTo(subject, body, recipients)
+	return p.mail.SendTo("", mailTmplInvoiceNewComment, nil, recipients)
 }
 
 // User CMS invite - Send to user being invited
@@ -167,9 +146,6 @@ You are receiving this email because {{.Email}} was used to be invited to Decred
 If you do not recognize this, please ignore this email.
 `
 
-var userCMSInviteTmpl = template.Must(
-	template.New("userCMSInvite").Parse(userCMSInviteText))
-
 // User DCC approved - Send to approved user
 type userDCCApproved struct {
 	Email string // User email
@@ -185,9 +161,6 @@ You are receiving this email because {{.Email}} was used to be invited to Decred
 If you do not recognize this, please ignore this email.
 `
 
-var userDCCApprovedTmpl = template.Must(
-	template.New("userDCCApproved").Parse(userDCCApprovedText))
-
 // DCC submitted - Send to admins
 type dccSubmitted struct {
 	Link string // DCC gui link
@@ -202,9 +175,6 @@ Regards,
 Contractor Management System
 `
 
-var dccSubmittedTmpl = template.Must(
-	template.New("dccSubmitted").Parse(dccSubmittedText))
-
 // DCC support/oppose - Send to admins
 type dccSupportOppose struct {
 	Link string // DCC gui link
@@ -219,9 +189,6 @@ Regards,
 Contractor Management System
 `
 
-var dccSupportOpposeTmpl = template.Must(
-	template.New("dccSupportOppose").Parse(dccSupportOpposeText))
-
 // Invoice status update - Send to invoice owner
 type invoiceStatusUpdate struct {
 	Token string // Invoice token
@@ -236,17 +203,7 @@ Regards,
 Contractor Management System
 `
 
-var invoiceStatusUpdateTmpl = template.Must(
-	template.New("invoiceStatusUpdate").Parse(invoiceStatusUpdateText))
-
-// Invoice notifications
-var invoiceFirstNotificationTmpl = template.Must(
-	template.New("first_invoice_notification").Parse(invoiceFirstText))
-var invoiceSecondNotificationTmpl = template.Must(
-	template.New("second_invoice_notification").Parse(invoiceSecondText))
-var invoiceFinalNotificationTmpl = template.Must(
-	template.New("final_invoice_notification").Parse(invoiceFinalText))
-
+// Invoice notifications - Send to users that have not submitted an invoice
 type invoiceNotification struct {
 	Username string
 	Month    string
@@ -284,5 +241,42 @@ const invoiceNewCommentText = `
 An administrator has submitted a new comment to your invoice, please login to cms.decred.org to view the message.
 `
 
-var invoiceNewCommentTmpl = template.Must(
-	template.New("invoiceNewComment").Parse(invoiceNewCommentText))
+// init registers the built-in CMS notification email templates.
+func init() {
+	mail.RegisterTemplate(mailTmplUserCMSInvite, mail.Template{
+		Subject: "Welcome to the Contractor Management System",
+		Text:    userCMSInviteText,
+	})
+	mail.RegisterTemplate(mailTmplUserDCCApproved, mail.Template{
+		Subject: "Congratulations, You've been approved!",
+		Text:    userDCCApprovedText,
+	})
+	mail.RegisterTemplate(mailTmplDCCSubmitted, mail.Template{
+		Subject: "New DCC Submitted",
+		Text:    dccSubmittedText,
+	})
+	mail.RegisterTemplate(mailTmplDCCSupportOppose, mail.Template{
+		Subject: "New DCC Support/Opposition Submitted",
+		Text:    dccSupportOpposeText,
+	})
+	mail.RegisterTemplate(mailTmplInvoiceStatusUpdate, mail.Template{
+		Subject: "Invoice status has been updated",
+		Text:    invoiceStatusUpdateText,
+	})
+	mail.RegisterTemplate(mailTmplInvoiceFirstNotification, mail.Template{
+		Subject: "Monthly Invoice Reminder",
+		Text:    invoiceFirstText,
+	})
+	mail.RegisterTemplate(mailTmplInvoiceSecondNotification, mail.Template{
+		Subject: "Awaiting Monthly Invoice",
+		Text:    invoiceSecondText,
+	})
+	mail.RegisterTemplate(mailTmplInvoiceFinalNotification, mail.Template{
+		Subject: "Final Invoice Notice",
+		Text:    invoiceFinalText,
+	})
+	mail.RegisterTemplate(mailTmplInvoiceNewComment, mail.Template{
+		Subject: "New Invoice Comment",
+		Text:    invoiceNewCommentText,
+	})
+}
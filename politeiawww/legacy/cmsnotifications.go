@@ -101,23 +101,20 @@ func (p *Politeiawww) invoiceNotification(emailCheckVersion int) {
 			switch emailCheckVersion {
 			case firstEmailCheck:
 				err = p.emailInvoiceNotifications(user.Email, user.Username,
-					"Monthly Invoice Reminder",
-					invoiceFirstNotificationTmpl)
+					mailTmplInvoiceFirstNotification)
 				if err != nil {
 					log.Errorf("Error sending first email: %v %v", err, user.Email)
 				}
 			case secondEmailCheck:
 				err = p.emailInvoiceNotifications(user.Email, user.Username,
-					"Awaiting Monthly Invoice",
-					invoiceSecondNotificationTmpl)
+					mailTmplInvoiceSecondNotification)
 				if err != nil {
 					log.Errorf("Error sending second email: %v %v", err, user.Email)
 				}
 
 			case thirdEmailCheck:
 				err = p.emailInvoiceNotifications(user.Email, user.Username,
-					"Final Invoice Notice",
-					invoiceFinalNotificationTmpl)
+					mailTmplInvoiceFinalNotification)
 				if err != nil {
 					log.Errorf("Error sending second email: %v %v", err, user.Email)
 				}
@@ -46,15 +46,15 @@ func (c *Comments) processNew(ctx context.Context, n v1.New, u user.User) (*v1.N
 		}
 	}
 
-	// Only admins and the record author are allowed to comment on
+	// Only admins and the record authors are allowed to comment on
 	// unvetted records.
 	if n.State == v1.RecordStateUnvetted && !u.Admin {
-		// User is not an admin. Check if the user is the author.
-		authorID, err := c.politeiad.Author(ctx, n.Token)
+		// User is not an admin. Check if the user is an author.
+		isAuthor, err := c.isRecordAuthor(ctx, n.Token, u.ID.String())
 		if err != nil {
 			return nil, err
 		}
-		if u.ID.String() != authorID {
+		if !isAuthor {
 			return nil, v1.UserErrorReply{
 				ErrorCode:    v1.ErrorCodeUnauthorized,
 				ErrorContext: "user is not author or admin",
@@ -318,13 +318,13 @@ func (c *Comments) processComments(ctx context.Context, cs v1.Comments, u *user.
 			// User is an admin. Allowed.
 			isAllowed = true
 		default:
-			// User is not an admin. Get the record author.
-			authorID, err := c.politeiad.Author(ctx, cs.Token)
+			// User is not an admin. Check if the user is an author.
+			isAuthor, err := c.isRecordAuthor(ctx, cs.Token, u.ID.String())
 			if err != nil {
 				return nil, err
 			}
-			if u.ID.String() == authorID {
-				// User is the author. Allowed.
+			if isAuthor {
+				// User is an author. Allowed.
 				isAllowed = true
 			}
 		}
@@ -573,6 +573,21 @@ func (c *Comments) recordNoFiles(ctx context.Context, token string) (*pdv2.Recor
 	return &r, nil
 }
 
+// isRecordAuthor returns whether the provided user ID is one of the authors of
+// the record.
+func (c *Comments) isRecordAuthor(ctx context.Context, token, userID string) (bool, error) {
+	authorIDs, err := c.politeiad.Authors(ctx, token)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range authorIDs {
+		if v == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // commentPopulateUserData populates the comment with user data that is not
 // stored in politeiad.
 func commentPopulateUserData(c *v1.Comment, u user.User) {
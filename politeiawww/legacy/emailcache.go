@@ -9,16 +9,20 @@ import (
 	"github.com/google/uuid"
 )
 
-// initUserEmailsCache initializes the userEmails cache by iterating through
-// all the users in the database and adding a email-userID mapping for them.
+// initUserCaches initializes the userEmails and userLanguages caches by
+// iterating through all the users in the database and adding a email-userID
+// mapping and their language preference for them.
 //
 // This function must be called WITHOUT the lock held.
-func (p *Politeiawww) initUserEmailsCache() error {
+func (p *Politeiawww) initUserCaches() error {
 	p.Lock()
 	defer p.Unlock()
 
 	return p.db.AllUsers(func(u *user.User) {
 		p.userEmails[u.Email] = u.ID
+		if u.Language != "" {
+			p.userLanguages[u.ID] = u.Language
+		}
 	})
 }
 
@@ -52,3 +56,38 @@ func (p *Politeiawww) userByEmail(email string) (*user.User, error) {
 	}
 	return p.db.UserGetById(id)
 }
+
+// setUserLanguageCache sets the language preference of a user in the user
+// languages cache. An empty language removes the language preference.
+//
+// This function must be called WITHOUT the lock held.
+func (p *Politeiawww) setUserLanguageCache(id uuid.UUID, language string) {
+	p.Lock()
+	defer p.Unlock()
+
+	if language == "" {
+		delete(p.userLanguages, id)
+		return
+	}
+	p.userLanguages[id] = language
+}
+
+// userLanguagesByID returns the language preferences of the provided users.
+// Users that have not set a language preference are not included in the
+// returned map.
+//
+// This function satisfies the mail UserLanguagesFunc type.
+//
+// This function must be called WITHOUT the lock held.
+func (p *Politeiawww) userLanguagesByID(ids []uuid.UUID) map[uuid.UUID]string {
+	p.RLock()
+	defer p.RUnlock()
+
+	langs := make(map[uuid.UUID]string, len(ids))
+	for _, id := range ids {
+		if l, ok := p.userLanguages[id]; ok {
+			langs[id] = l
+		}
+	}
+	return langs
+}
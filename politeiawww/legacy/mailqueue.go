@@ -0,0 +1,55 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package legacy
+
+import (
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/legacy/user"
+)
+
+// processMailQueue returns the emails in the outbound mail queue that have
+// the requested status. The email bodies are not returned since they can
+// contain user secrets, such as verification tokens.
+func (p *Politeiawww) processMailQueue(mq www.MailQueue) (*www.MailQueueReply, error) {
+	log.Tracef("processMailQueue: %v", mq.Status)
+
+	switch mq.Status {
+	case www.MailStatusQueued, www.MailStatusSent, www.MailStatusFailed:
+		// These are allowed
+	default:
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid mail status"},
+		}
+	}
+
+	queued, err := p.mailerDB.MailQueueGetByStatus(
+		user.MailStatusT(mq.Status), www.MailQueuePageSize)
+	if err != nil {
+		return nil, err
+	}
+	mail := make([]www.QueuedMail, 0, len(queued))
+	for _, v := range queued {
+		mail = append(mail, convertQueuedMailToWWW(v))
+	}
+
+	return &www.MailQueueReply{
+		Mail: mail,
+	}, nil
+}
+
+func convertQueuedMailToWWW(m user.QueuedMail) www.QueuedMail {
+	return www.QueuedMail{
+		ID:            m.ID,
+		Subject:       m.Subject,
+		Recipients:    m.Recipients,
+		Status:        www.MailStatusT(m.Status),
+		Attempts:      m.Attempts,
+		LastError:     m.LastError,
+		CreatedAt:     m.CreatedAt,
+		NextAttemptAt: m.NextAttemptAt,
+		SentAt:        m.SentAt,
+	}
+}
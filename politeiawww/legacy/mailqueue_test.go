@@ -0,0 +1,108 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package legacy
+
+import (
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/legacy/user"
+)
+
+func TestProcessMailQueue(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	// Seed the mail queue with a queued and a failed email
+	queued := user.QueuedMail{
+		ID:            "proposal-new:token",
+		Subject:       "New proposal",
+		Body:          "body",
+		Recipients:    []string{"user@example.com"},
+		Status:        user.MailStatusQueued,
+		CreatedAt:     1,
+		NextAttemptAt: 1,
+	}
+	failed := user.QueuedMail{
+		ID:         "comment-reply:token:1",
+		Subject:    "New reply",
+		Body:       "body",
+		Recipients: []string{"user@example.com"},
+		Status:     user.MailStatusFailed,
+		Attempts:   8,
+		LastError:  "smtp server unavailable",
+		CreatedAt:  2,
+	}
+	for _, m := range []user.QueuedMail{queued, failed} {
+		err := p.mailerDB.MailQueueNew(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name    string
+		status  www.MailStatusT
+		wantIDs []string
+		wantErr error
+	}{
+		{
+			"invalid status",
+			www.MailStatusInvalid,
+			nil,
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			},
+		},
+		{
+			"queued mail",
+			www.MailStatusQueued,
+			[]string{queued.ID},
+			nil,
+		},
+		{
+			"failed mail",
+			www.MailStatusFailed,
+			[]string{failed.ID},
+			nil,
+		},
+		{
+			"sent mail",
+			www.MailStatusSent,
+			[]string{},
+			nil,
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			mqr, err := p.processMailQueue(www.MailQueue{
+				Status: v.status,
+			})
+			got := errToStr(err)
+			want := errToStr(v.wantErr)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+
+			if len(mqr.Mail) != len(v.wantIDs) {
+				t.Fatalf("got %v emails, want %v",
+					len(mqr.Mail), len(v.wantIDs))
+			}
+			for i, m := range mqr.Mail {
+				if m.ID != v.wantIDs[i] || m.Status != v.status {
+					t.Errorf("got email %v with status %v, want %v "+
+						"with status %v", m.ID, m.Status, v.wantIDs[i],
+						v.status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/legacy/user"
+	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/google/uuid"
+)
+
+// The following are the notification events that can be added to a user's
+// email digest. The event types are saved to the database as part of the
+// digest entries and must not be changed.
+const (
+	digestEventProposalNew       = "proposal-new"
+	digestEventProposalEdit      = "proposal-edit"
+	digestEventProposalPublished = "proposal-published"
+	digestEventProposalCensored  = "proposal-censored"
+	digestEventCommentNew        = "comment-new"
+	digestEventCommentReply      = "comment-reply"
+	digestEventVoteAuthorized    = "vote-authorized"
+	digestEventVoteStarted       = "vote-started"
+)
+
+const (
+	// digestInterval is how often the email digests are checked for
+	// digests that are due to be sent.
+	digestInterval = time.Hour
+
+	// mailTmplDigest is the name of the email digest template.
+	mailTmplDigest = "digest"
+)
+
+// digestPeriod returns the amount of time that notification events are
+// accumulated for before the email digest is sent. A zero duration is
+// returned for users that do not have an email digest enabled.
+func digestPeriod(d www.EmailDigestT) time.Duration {
+	switch d {
+	case www.EmailDigestDaily:
+		return 24 * time.Hour
+	case www.EmailDigestWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
+
+// ntfnRecipients contains the users that a notification is sent to. Users
+// that have an email digest enabled have the notification added to their
+// digest. All other users are sent the notification email right away.
+type ntfnRecipients struct {
+	emails map[uuid.UUID]string // [userID]email
+	digest []uuid.UUID
+}
+
+// newNtfnRecipients returns a new ntfnRecipients.
+func newNtfnRecipients() *ntfnRecipients {
+	return &ntfnRecipients{
+		emails: make(map[uuid.UUID]string, 1024),
+		digest: make([]uuid.UUID, 0, 1024),
+	}
+}
+
+// add adds the user to the notification recipients.
+func (r *ntfnRecipients) add(u *user.User) {
+	if www.EmailDigestT(u.EmailDigest) != www.EmailDigestNone {
+		r.digest = append(r.digest, u.ID)
+		return
+	}
+	r.emails[u.ID] = u.Email
+}
+
+// digestAdd adds the provided entry to the email digests of the provided
+// users.
+func (p *Pi) digestAdd(userIDs []uuid.UUID, e user.DigestEntry) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	p.digestMtx.Lock()
+	defer p.digestMtx.Unlock()
+
+	digests, err := p.mailerdb.EmailDigestsGet(userIDs)
+	if err != nil {
+		return fmt.Errorf("EmailDigestsGet: %v", err)
+	}
+	e.Timestamp = time.Now().Unix()
+	for _, id := range userIDs {
+		d := digests[id]
+		if d.LastSent == 0 {
+			// This is the first entry of a digest that has never been
+			// sent. The digest period starts now.
+			d.LastSent = e.Timestamp
+		}
+		d.Entries = append(d.Entries, e)
+		digests[id] = d
+	}
+	err = p.mailerdb.EmailDigestsSave(digests)
+	if err != nil {
+		return fmt.Errorf("EmailDigestsSave: %v", err)
+	}
+
+	log.Debugf("Digest %v entry added for %v users", e.Event, len(userIDs))
+
+	return nil
+}
+
+// digestScheduler sends the email digests that are due every digestInterval.
+// It runs for the lifetime of the process.
+func (p *Pi) digestScheduler() {
+	ticker := time.NewTicker(digestInterval)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		err := p.digestsSend(now)
+		if err != nil {
+			log.Errorf("digestsSend: %v", err)
+		}
+	}
+}
+
+// digestUser contains the user fields that are needed to send an email
+// digest.
+type digestUser struct {
+	email  string
+	digest www.EmailDigestT
+}
+
+// digestsSend sends the email digests that are due. A digest is due once
+// the digest period of the user has passed since the last time that the
+// digest was sent. The pending entries of users that have turned off their
+// email digest are sent right away.
+func (p *Pi) digestsSend(now time.Time) error {
+	// Compile the users that may have a pending email digest
+	users := make(map[uuid.UUID]digestUser, 1024)
+	err := p.userdb.AllUsers(func(u *user.User) {
+		if u.EmailNotifications == 0 &&
+			www.EmailDigestT(u.EmailDigest) == www.EmailDigestNone {
+			// User does not receive any notifications
+			return
+		}
+		users[u.ID] = digestUser{
+			email:  u.Email,
+			digest: www.EmailDigestT(u.EmailDigest),
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("AllUsers: %v", err)
+	}
+	userIDs := make([]uuid.UUID, 0, len(users))
+	for id := range users {
+		userIDs = append(userIDs, id)
+	}
+
+	p.digestMtx.Lock()
+	defer p.digestMtx.Unlock()
+
+	digests, err := p.mailerdb.EmailDigestsGet(userIDs)
+	if err != nil {
+		return fmt.Errorf("EmailDigestsGet: %v", err)
+	}
+
+	sent := make(map[uuid.UUID]user.EmailDigest, len(digests))
+	for id, d := range digests {
+		if len(d.Entries) == 0 {
+			continue
+		}
+		u := users[id]
+		period := digestPeriod(u.digest)
+		if period != 0 && now.Sub(time.Unix(d.LastSent, 0)) < period {
+			// Digest is not due yet
+			continue
+		}
+
+		tmplData, err := p.digestData(d.Entries)
+		if err != nil {
+			log.Errorf("digestData %v: %v", id, err)
+			continue
+		}
+
+		// The idempotency key is derived from the start of the digest
+		// period so that a digest is not sent twice if the digest fails
+		// to be saved after it was sent.
+		key := fmt.Sprintf("digest:%v:%v", id, d.LastSent)
+		err = p.mail.SendToUsers(key, mailTmplDigest, tmplData,
+			map[uuid.UUID]string{id: u.email})
+		if err != nil {
+			log.Errorf("SendToUsers %v: %v", id, err)
+			continue
+		}
+
+		// Reset the digest. The digest period of users that have turned
+		// off their email digest is restarted by the next entry that is
+		// added to the digest if they turn it back on.
+		var lastSent int64
+		if period != 0 {
+			lastSent = now.Unix()
+		}
+		sent[id] = user.EmailDigest{
+			LastSent: lastSent,
+		}
+	}
+	if len(sent) == 0 {
+		return nil
+	}
+
+	err = p.mailerdb.EmailDigestsSave(sent)
+	if err != nil {
+		return fmt.Errorf("EmailDigestsSave: %v", err)
+	}
+
+	log.Infof("Email digests sent: %v", len(sent))
+
+	return nil
+}
+
+type digest struct {
+	Count     int              // Number of events
+	Proposals []digestProposal // Events grouped by proposal
+}
+
+type digestProposal struct {
+	Name   string // Proposal name
+	Link   string // GUI proposal details URL
+	Events []digestEvent
+}
+
+type digestEvent struct {
+	Event    string // Notification event type
+	Username string // User that caused the event
+	Version  uint32 // Proposal version
+	Reason   string // Status change reason
+	Link     string // GUI comment URL
+}
+
+var digestText = `
+Here is what happened on Politeia since your last email digest.
+{{range .Proposals}}
+{{.Name}}
+{{.Link}}
+{{range .Events}}{{if eq .Event "proposal-new"}}- Submitted by {{.Username}}
+{{else if eq .Event "proposal-edit"}}- Edited by {{.Username}} (Version {{.Version}})
+{{else if eq .Event "proposal-published"}}- Published
+{{else if eq .Event "proposal-censored"}}- Censored. Reason: {{.Reason}}
+{{else if eq .Event "comment-new"}}- {{.Username}} commented on your proposal: {{.Link}}
+{{else if eq .Event "comment-reply"}}- {{.Username}} replied to your comment: {{.Link}}
+{{else if eq .Event "vote-authorized"}}- Vote authorized
+{{else if eq .Event "vote-started"}}- Voting started
+{{end}}{{end}}{{end}}
+You are receiving this digest because you have enabled email digests in your
+Politeia account settings.
+`
+
+// digestData returns the digest template data for the provided digest
+// entries. The entries are grouped by proposal in the order that the
+// proposals first appear in the digest.
+func (p *Pi) digestData(entries []user.DigestEntry) (*digest, error) {
+	var (
+		proposals = make([]digestProposal, 0, len(entries))
+		indexes   = make(map[string]int, len(entries)) // [token]index
+	)
+	for _, e := range entries {
+		i, ok := indexes[e.Token]
+		if !ok {
+			link, err := p.digestLink(e.Token, 0)
+			if err != nil {
+				return nil, err
+			}
+			i = len(proposals)
+			indexes[e.Token] = i
+			proposals = append(proposals, digestProposal{
+				Link: link,
+			})
+		}
+
+		// Use the most recent proposal name
+		if e.Name != "" {
+			proposals[i].Name = e.Name
+		}
+
+		var link string
+		if e.CommentID != 0 {
+			var err error
+			link, err = p.digestLink(e.Token, e.CommentID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		proposals[i].Events = append(proposals[i].Events, digestEvent{
+			Event:    e.Event,
+			Username: e.Username,
+			Version:  e.Version,
+			Reason:   e.Reason,
+			Link:     link,
+		})
+	}
+
+	return &digest{
+		Count:     len(entries),
+		Proposals: proposals,
+	}, nil
+}
+
+// digestLink returns the GUI URL of a proposal or, if a comment ID is
+// provided, of a proposal comment.
+func (p *Pi) digestLink(token string, commentID uint32) (string, error) {
+	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
+	if commentID != 0 {
+		cid := strconv.FormatUint(uint64(commentID), 10)
+		route = strings.Replace(guiRouteRecordComment, "{token}", token, 1)
+		route = strings.Replace(route, "{id}", cid, 1)
+	}
+	u, err := url.Parse(p.cfg.WebServerAddress + route)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// init registers the built-in email digest template.
+func init() {
+	mail.RegisterTemplate(mailTmplDigest, mail.Template{
+		Subject: `Politeia Digest: {{.Count}} New Notifications`,
+		Text:    digestText,
+	})
+}
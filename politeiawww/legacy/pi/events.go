@@ -75,7 +75,7 @@ func (p *Pi) handleEventRecordNew(ch chan interface{}) {
 
 		// Compile notification email list
 		var (
-			recipients = make(map[uuid.UUID]string, 1024)
+			recipients = newNtfnRecipients()
 			ntfnBit    = uint64(www.NotificationEmailAdminProposalNew)
 		)
 		err := p.userdb.AllUsers(func(u *user.User) {
@@ -89,7 +89,7 @@ func (p *Pi) handleEventRecordNew(ch chan interface{}) {
 			default:
 				// User is an admin and has the notification bit set. Add
 				// them to the email list.
-				recipients[u.ID] = u.Email
+				recipients.add(u)
 			}
 		})
 		if err != nil {
@@ -102,10 +102,20 @@ func (p *Pi) handleEventRecordNew(ch chan interface{}) {
 			token = e.Record.CensorshipRecord.Token
 			name  = proposalNameFromFiles(e.Record.Files)
 		)
-		err = p.mailNtfnProposalNew(token, name, e.User.Username, recipients)
+		err = p.mailNtfnProposalNew(token, name, e.User.Username,
+			recipients.emails)
 		if err != nil {
 			log.Errorf("mailNtfnProposalNew: %v", err)
 		}
+		err = p.digestAdd(recipients.digest, user.DigestEntry{
+			Event:    digestEventProposalNew,
+			Token:    token,
+			Name:     name,
+			Username: e.User.Username,
+		})
+		if err != nil {
+			log.Errorf("digestAdd: %v", err)
+		}
 
 		log.Debugf("Proposal new ntfn sent %v", token)
 	}
@@ -128,7 +138,7 @@ func (p *Pi) handleEventRecordEdit(ch chan interface{}) {
 
 		// Compile notification email list
 		var (
-			recipients = make(map[uuid.UUID]string, 1024)
+			recipients = newNtfnRecipients()
 			authorID   = e.User.ID.String()
 			ntfnBit    = uint64(www.NotificationEmailRegularProposalEdited)
 		)
@@ -144,7 +154,7 @@ func (p *Pi) handleEventRecordEdit(ch chan interface{}) {
 			default:
 				// User has the notification bit set. Add them to the email
 				// list.
-				recipients[u.ID] = u.Email
+				recipients.add(u)
 			}
 		})
 		if err != nil {
@@ -159,9 +169,20 @@ func (p *Pi) handleEventRecordEdit(ch chan interface{}) {
 			name     = proposalNameFromFiles(e.Record.Files)
 			username = e.User.Username
 		)
-		err = p.mailNtfnProposalEdit(token, version, name, username, recipients)
+		err = p.mailNtfnProposalEdit(token, version, name, username,
+			recipients.emails)
 		if err != nil {
 			log.Errorf("mailNtfnProposaledit: %v", err)
+		}
+		err = p.digestAdd(recipients.digest, user.DigestEntry{
+			Event:    digestEventProposalEdit,
+			Token:    token,
+			Name:     name,
+			Username: username,
+			Version:  version,
+		})
+		if err != nil {
+			log.Errorf("digestAdd: %v", err)
 			continue
 		}
 
@@ -207,14 +228,26 @@ func (p *Pi) ntfnRecordSetStatusToAuthor(r rcv1.Record) error {
 	}
 
 	// Author has notification enabled
-	recipient := map[uuid.UUID]string{
-		uid: author.Email,
-	}
+	recipient := newNtfnRecipients()
+	recipient.add(author)
 	err = p.mailNtfnProposalSetStatusToAuthor(token, name,
-		status, reason, recipient)
+		status, reason, recipient.emails)
 	if err != nil {
 		return fmt.Errorf("mailNtfnProposalSetStatusToAuthor: %v", err)
 	}
+	event := digestEventProposalPublished
+	if status == rcv1.RecordStatusCensored {
+		event = digestEventProposalCensored
+	}
+	err = p.digestAdd(recipient.digest, user.DigestEntry{
+		Event:  event,
+		Token:  token,
+		Name:   name,
+		Reason: reason,
+	})
+	if err != nil {
+		return err
+	}
 
 	log.Debugf("Record set status ntfn to author sent %v", token)
 
@@ -232,7 +265,7 @@ func (p *Pi) ntfnRecordSetStatus(r rcv1.Record) error {
 
 	// Compile user notification email list
 	var (
-		recipients = make(map[uuid.UUID]string, 1024)
+		recipients = newNtfnRecipients()
 		ntfnBit    = uint64(www.NotificationEmailRegularProposalVetted)
 	)
 	err := p.userdb.AllUsers(func(u *user.User) {
@@ -246,7 +279,7 @@ func (p *Pi) ntfnRecordSetStatus(r rcv1.Record) error {
 			return
 		default:
 			// Add user to notification list
-			recipients[u.ID] = u.Email
+			recipients.add(u)
 		}
 	})
 	if err != nil {
@@ -254,10 +287,18 @@ func (p *Pi) ntfnRecordSetStatus(r rcv1.Record) error {
 	}
 
 	// Send user notifications
-	err = p.mailNtfnProposalSetStatus(token, name, status, recipients)
+	err = p.mailNtfnProposalSetStatus(token, name, status, recipients.emails)
 	if err != nil {
 		return fmt.Errorf("mailNtfnProposalSetStatus: %v", err)
 	}
+	err = p.digestAdd(recipients.digest, user.DigestEntry{
+		Event: digestEventProposalPublished,
+		Token: token,
+		Name:  name,
+	})
+	if err != nil {
+		return err
+	}
 
 	log.Debugf("Record set status ntfn to users sent %v", token)
 
@@ -342,11 +383,20 @@ func (p *Pi) ntfnCommentNewProposalAuthor(c cmv1.Comment, proposalAuthorID, prop
 	}
 
 	// Send notification email
-	recipient := map[uuid.UUID]string{
-		pauthor.ID: pauthor.Email,
-	}
+	recipient := newNtfnRecipients()
+	recipient.add(pauthor)
 	err = p.mailNtfnCommentNewToProposalAuthor(c.Token, c.CommentID,
-		c.Username, proposalName, recipient)
+		c.Username, proposalName, recipient.emails)
+	if err != nil {
+		return err
+	}
+	err = p.digestAdd(recipient.digest, user.DigestEntry{
+		Event:     digestEventCommentNew,
+		Token:     c.Token,
+		Name:      proposalName,
+		Username:  c.Username,
+		CommentID: c.CommentID,
+	})
 	if err != nil {
 		return err
 	}
@@ -399,11 +449,20 @@ func (p *Pi) ntfnCommentReply(c cmv1.Comment, proposalName string) error {
 	}
 
 	// Send notification email
-	recipient := map[uuid.UUID]string{
-		pauthor.ID: pauthor.Email,
-	}
+	recipient := newNtfnRecipients()
+	recipient.add(pauthor)
 	err = p.mailNtfnCommentReply(c.Token, c.CommentID,
-		c.Username, proposalName, recipient)
+		c.Username, proposalName, recipient.emails)
+	if err != nil {
+		return err
+	}
+	err = p.digestAdd(recipient.digest, user.DigestEntry{
+		Event:     digestEventCommentReply,
+		Token:     c.Token,
+		Name:      proposalName,
+		Username:  c.Username,
+		CommentID: c.CommentID,
+	})
 	if err != nil {
 		return err
 	}
@@ -482,7 +541,7 @@ func (p *Pi) handleEventVoteAuthorized(ch chan interface{}) {
 			token        = e.Auth.Token
 			proposalName string
 			r            rcv1.Record
-			recipients   = make(map[uuid.UUID]string, 1024)
+			recipients   = newNtfnRecipients()
 			ntfnBit      = uint64(www.NotificationEmailAdminProposalVoteAuthorized)
 			err          error
 		)
@@ -506,7 +565,7 @@ func (p *Pi) handleEventVoteAuthorized(ch chan interface{}) {
 				return
 			default:
 				// Admin has notification enabled
-				recipients[u.ID] = u.Email
+				recipients.add(u)
 			}
 		})
 		if err != nil {
@@ -515,11 +574,20 @@ func (p *Pi) handleEventVoteAuthorized(ch chan interface{}) {
 		}
 
 		// Send notification email
-		err = p.mailNtfnVoteAuthorized(token, proposalName, recipients)
+		err = p.mailNtfnVoteAuthorized(token, proposalName,
+			e.Auth.Signature, recipients.emails)
 		if err != nil {
 			err = fmt.Errorf("mailNtfnVoteAuthorized: %v", err)
 			goto failed
 		}
+		err = p.digestAdd(recipients.digest, user.DigestEntry{
+			Event: digestEventVoteAuthorized,
+			Token: token,
+			Name:  proposalName,
+		})
+		if err != nil {
+			goto failed
+		}
 
 		log.Debugf("Vote authorized ntfn to admin sent %v", e.Auth.Token)
 		continue
@@ -553,10 +621,18 @@ func (p *Pi) ntfnVoteStartedToAuthor(sd tkv1.StartDetails, authorID, proposalNam
 	}
 
 	// Send notification to author
-	recipient := map[uuid.UUID]string{
-		author.ID: author.Email,
+	recipient := newNtfnRecipients()
+	recipient.add(author)
+	err = p.mailNtfnVoteStartedToAuthor(token, proposalName,
+		recipient.emails)
+	if err != nil {
+		return err
 	}
-	err = p.mailNtfnVoteStartedToAuthor(token, proposalName, recipient)
+	err = p.digestAdd(recipient.digest, user.DigestEntry{
+		Event: digestEventVoteStarted,
+		Token: token,
+		Name:  proposalName,
+	})
 	if err != nil {
 		return err
 	}
@@ -573,7 +649,7 @@ func (p *Pi) ntfnVoteStarted(sd tkv1.StartDetails, eventUser user.User, authorID
 	)
 
 	// Compile user notification list
-	recipients := make(map[uuid.UUID]string, 1024)
+	recipients := newNtfnRecipients()
 	err := p.userdb.AllUsers(func(u *user.User) {
 		switch {
 		case u.ID.String() == eventUser.ID.String():
@@ -589,7 +665,7 @@ func (p *Pi) ntfnVoteStarted(sd tkv1.StartDetails, eventUser user.User, authorID
 			return
 		default:
 			// User has notification bit set
-			recipients[u.ID] = u.Email
+			recipients.add(u)
 		}
 	})
 	if err != nil {
@@ -597,10 +673,18 @@ func (p *Pi) ntfnVoteStarted(sd tkv1.StartDetails, eventUser user.User, authorID
 	}
 
 	// Email users
-	err = p.mailNtfnVoteStarted(token, proposalName, recipients)
+	err = p.mailNtfnVoteStarted(token, proposalName, recipients.emails)
 	if err != nil {
 		return fmt.Errorf("mailNtfnVoteStarted: %v", err)
 	}
+	err = p.digestAdd(recipients.digest, user.DigestEntry{
+		Event: digestEventVoteStarted,
+		Token: token,
+		Name:  proposalName,
+	})
+	if err != nil {
+		return err
+	}
 
 	log.Debugf("Vote started ntfn to users sent %v", token)
 
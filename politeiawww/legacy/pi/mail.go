@@ -5,14 +5,13 @@
 package pi
 
 import (
-	"bytes"
 	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
-	"text/template"
 
 	rcv1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	"github.com/decred/politeia/politeiawww/mail"
 	"github.com/google/uuid"
 )
 
@@ -23,6 +22,27 @@ const (
 	guiRouteRecordComment = "/record/{token}/comments/{id}"
 )
 
+// The following are the names of the pi notification email templates. The
+// built-in templates are defined in this file and can be overridden using the
+// mail templates directory.
+const (
+	mailTmplProposalNew                = "proposal-new"
+	mailTmplProposalEdit               = "proposal-edit"
+	mailTmplProposalPublished          = "proposal-published"
+	mailTmplProposalPublishedToAuthor  = "proposal-published-author"
+	mailTmplProposalCensoredToAuthor   = "proposal-censored-author"
+	mailTmplCommentNewToProposalAuthor = "comment-new-author"
+	mailTmplCommentReply               = "comment-reply"
+	mailTmplVoteAuthorized             = "vote-authorized"
+	mailTmplVoteStarted                = "vote-started"
+	mailTmplVoteStartedToAuthor        = "vote-started-author"
+)
+
+// The notification emails are queued using an idempotency key that is
+// derived from the event that triggered the notification. This prevents a
+// notification from being sent more than once if an event is handled more
+// than once.
+
 type proposalNew struct {
 	Username string // Author username
 	Name     string // Proposal name
@@ -36,9 +56,6 @@ A new proposal has been submitted on Politeia by {{.Username}}:
 {{.Link}}
 `
 
-var proposalNewTmpl = template.Must(
-	template.New("proposalNew").Parse(proposalNewText))
-
 func (p *Pi) mailNtfnProposalNew(token, name, username string, recipients map[uuid.UUID]string) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
@@ -52,13 +69,8 @@ func (p *Pi) mailNtfnProposalNew(token, name, username string, recipients map[uu
 		Link:     u.String(),
 	}
 
-	subject := fmt.Sprintf(`New Proposal Submitted "%v"`, name)
-	body, err := populateTemplate(proposalNewTmpl, tmplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendToUsers(subject, body, recipients)
+	key := "proposal-new:" + token
+	return p.mail.SendToUsers(key, mailTmplProposalNew, tmplData, recipients)
 }
 
 type proposalEdit struct {
@@ -75,9 +87,6 @@ A proposal by {{.Username}} has just been edited:
 {{.Link}}
 `
 
-var proposalEditTmpl = template.Must(
-	template.New("proposalEdit").Parse(proposalEditText))
-
 func (p *Pi) mailNtfnProposalEdit(token string, version uint32, name, username string, recipients map[uuid.UUID]string) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
@@ -92,13 +101,8 @@ func (p *Pi) mailNtfnProposalEdit(token string, version uint32, name, username s
 		Link:     u.String(),
 	}
 
-	subject := fmt.Sprintf(`Proposal Edited "%v"`, name)
-	body, err := populateTemplate(proposalEditTmpl, tmplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendToUsers(subject, body, recipients)
+	key := fmt.Sprintf("proposal-edit:%v:%v", token, version)
+	return p.mail.SendToUsers(key, mailTmplProposalEdit, tmplData, recipients)
 }
 
 type proposalPublished struct {
@@ -106,9 +110,6 @@ type proposalPublished struct {
 	Link string // GUI proposal details URL
 }
 
-var proposalPublishedTmpl = template.Must(
-	template.New("proposalPublished").Parse(proposalPublishedText))
-
 var proposalPublishedText = `
 A new proposal has just been published on Politeia.
 
@@ -124,31 +125,29 @@ func (p *Pi) mailNtfnProposalSetStatus(token, name string, status rcv1.RecordSta
 	}
 
 	var (
-		subject string
-		body    string
+		tmpl     string
+		tmplData interface{}
 	)
 	switch status {
 	case rcv1.RecordStatusPublic:
-		subject = fmt.Sprintf(`New Proposal Published "%v"`, name)
-		tmplData := proposalPublished{
+		tmpl = mailTmplProposalPublished
+		tmplData = proposalPublished{
 			Name: name,
 			Link: u.String(),
 		}
-		body, err = populateTemplate(proposalPublishedTmpl, tmplData)
-		if err != nil {
-			return err
-		}
 
 	default:
 		return fmt.Errorf("no mail ntfn for status %v", status)
 	}
 
-	return p.mail.SendToUsers(subject, body, recipients)
+	key := fmt.Sprintf("proposal-status:%v:%v", token, status)
+	return p.mail.SendToUsers(key, tmpl, tmplData, recipients)
 }
 
 type proposalPublishedToAuthor struct {
-	Name string // Proposal name
-	Link string // GUI proposal details URL
+	Token string // Proposal token
+	Name  string // Proposal name
+	Link  string // GUI proposal details URL
 }
 
 var proposalPublishedToAuthorText = `
@@ -164,9 +163,6 @@ Once you feel that enough time has been given for discussion you may authorize t
 If you have any questions, drop by the proposals channel on matrix.
 https://chat.decred.org/#/room/#proposals:decred.org
 `
-var proposalPublishedToAuthorTmpl = template.Must(
-	template.New("proposalPublishedToAuthor").
-		Parse(proposalPublishedToAuthorText))
 
 type proposalCensoredToAuthor struct {
 	Name   string // Proposal name
@@ -180,10 +176,6 @@ Your proposal on Politeia has been censored.
 Reason: {{.Reason}}
 `
 
-var proposalCensoredToAuthorTmpl = template.Must(
-	template.New("proposalCensoredToAuthor").
-		Parse(proposalCensoredToAuthorText))
-
 func (p *Pi) mailNtfnProposalSetStatusToAuthor(token, name string, status rcv1.RecordStatusT, reason string, recipient map[uuid.UUID]string) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
@@ -192,37 +184,31 @@ func (p *Pi) mailNtfnProposalSetStatusToAuthor(token, name string, status rcv1.R
 	}
 
 	var (
-		subject string
-		body    string
+		tmpl     string
+		tmplData interface{}
 	)
 	switch status {
 	case rcv1.RecordStatusPublic:
-		subject = "Your Proposal Has Been Published " + token
-		tmplData := proposalPublishedToAuthor{
-			Name: name,
-			Link: u.String(),
-		}
-		body, err = populateTemplate(proposalPublishedToAuthorTmpl, tmplData)
-		if err != nil {
-			return err
+		tmpl = mailTmplProposalPublishedToAuthor
+		tmplData = proposalPublishedToAuthor{
+			Token: token,
+			Name:  name,
+			Link:  u.String(),
 		}
 
 	case rcv1.RecordStatusCensored:
-		subject = fmt.Sprintf(`Your Proposal Has Been Censored "%v"`, name)
-		tmplData := proposalCensoredToAuthor{
+		tmpl = mailTmplProposalCensoredToAuthor
+		tmplData = proposalCensoredToAuthor{
 			Name:   name,
 			Reason: reason,
 		}
-		body, err = populateTemplate(proposalCensoredToAuthorTmpl, tmplData)
-		if err != nil {
-			return err
-		}
 
 	default:
 		return fmt.Errorf("no author notification for prop status %v", status)
 	}
 
-	return p.mail.SendToUsers(subject, body, recipient)
+	key := fmt.Sprintf("proposal-status-author:%v:%v", token, status)
+	return p.mail.SendToUsers(key, tmpl, tmplData, recipient)
 }
 
 type commentNewToProposalAuthor struct {
@@ -237,10 +223,6 @@ var commentNewToProposalAuthorText = `
 {{.Link}}
 `
 
-var commentNewToProposalAuthorTmpl = template.Must(
-	template.New("commentNewToProposalAuthor").
-		Parse(commentNewToProposalAuthorText))
-
 func (p *Pi) mailNtfnCommentNewToProposalAuthor(token string, commentID uint32, commentUsername, proposalName string, recipient map[uuid.UUID]string) error {
 	cid := strconv.FormatUint(uint64(commentID), 10)
 	route := strings.Replace(guiRouteRecordComment, "{token}", token, 1)
@@ -251,18 +233,14 @@ func (p *Pi) mailNtfnCommentNewToProposalAuthor(token string, commentID uint32,
 		return err
 	}
 
-	subject := fmt.Sprintf(`New Comment on Your Proposal "%v"`, proposalName)
 	tmplData := commentNewToProposalAuthor{
 		Username: commentUsername,
 		Name:     proposalName,
 		Link:     u.String(),
 	}
-	body, err := populateTemplate(commentNewToProposalAuthorTmpl, tmplData)
-	if err != nil {
-		return err
-	}
 
-	return p.mail.SendToUsers(subject, body, recipient)
+	key := fmt.Sprintf("comment-new-author:%v:%v", token, cid)
+	return p.mail.SendToUsers(key, mailTmplCommentNewToProposalAuthor, tmplData, recipient)
 }
 
 type commentReply struct {
@@ -277,9 +255,6 @@ var commentReplyText = `
 {{.Link}}
 `
 
-var commentReplyTmpl = template.Must(
-	template.New("commentReply").Parse(commentReplyText))
-
 func (p *Pi) mailNtfnCommentReply(token string, commentID uint32, commentUsername, proposalName string, recipient map[uuid.UUID]string) error {
 	cid := strconv.FormatUint(uint64(commentID), 10)
 	route := strings.Replace(guiRouteRecordComment, "{token}", token, 1)
@@ -290,18 +265,14 @@ func (p *Pi) mailNtfnCommentReply(token string, commentID uint32, commentUsernam
 		return err
 	}
 
-	subject := fmt.Sprintf(`New Reply to Your Comment on "%v"`, proposalName)
 	tmplData := commentReply{
 		Username: commentUsername,
 		Name:     proposalName,
 		Link:     u.String(),
 	}
-	body, err := populateTemplate(commentReplyTmpl, tmplData)
-	if err != nil {
-		return err
-	}
 
-	return p.mail.SendToUsers(subject, body, recipient)
+	key := fmt.Sprintf("comment-reply:%v:%v", token, cid)
+	return p.mail.SendToUsers(key, mailTmplCommentReply, tmplData, recipient)
 }
 
 type voteAuthorized struct {
@@ -316,27 +287,20 @@ A proposal vote has been authorized.
 {{.Link}}
 `
 
-var voteAuthorizedTmpl = template.Must(
-	template.New("voteAuthorized").Parse(voteAuthorizedText))
-
-func (p *Pi) mailNtfnVoteAuthorized(token, name string, recipients map[uuid.UUID]string) error {
+func (p *Pi) mailNtfnVoteAuthorized(token, name, signature string, recipients map[uuid.UUID]string) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
 	if err != nil {
 		return err
 	}
 
-	subject := fmt.Sprintf(`Voting Authorized for "%v"`, name)
 	tmplData := voteAuthorized{
 		Name: name,
 		Link: u.String(),
 	}
-	body, err := populateTemplate(voteAuthorizedTmpl, tmplData)
-	if err != nil {
-		return err
-	}
 
-	return p.mail.SendToUsers(subject, body, recipients)
+	key := fmt.Sprintf("vote-authorized:%v:%v", token, signature)
+	return p.mail.SendToUsers(key, mailTmplVoteAuthorized, tmplData, recipients)
 }
 
 type voteStarted struct {
@@ -351,9 +315,6 @@ Voting has started on a Politeia proposal.
 {{.Link}}
 `
 
-var voteStartedTmpl = template.Must(
-	template.New("voteStarted").Parse(voteStartedText))
-
 func (p *Pi) mailNtfnVoteStarted(token, name string, recipients map[uuid.UUID]string) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
@@ -361,17 +322,13 @@ func (p *Pi) mailNtfnVoteStarted(token, name string, recipients map[uuid.UUID]st
 		return err
 	}
 
-	subject := fmt.Sprintf(`Voting Started for "%v"`, name)
 	tmplData := voteStarted{
 		Name: name,
 		Link: u.String(),
 	}
-	body, err := populateTemplate(voteStartedTmpl, tmplData)
-	if err != nil {
-		return err
-	}
 
-	return p.mail.SendToUsers(subject, body, recipients)
+	key := "vote-started:" + token
+	return p.mail.SendToUsers(key, mailTmplVoteStarted, tmplData, recipients)
 }
 
 type voteStartedToAuthor struct {
@@ -386,9 +343,6 @@ Voting has just started on your Politeia proposal.
 {{.Link}}
 `
 
-var voteStartedToAuthorTmpl = template.Must(
-	template.New("voteStartedToAuthor").Parse(voteStartedToAuthorText))
-
 func (p *Pi) mailNtfnVoteStartedToAuthor(token, name string, recipient map[uuid.UUID]string) error {
 	route := strings.Replace(guiRouteRecordDetails, "{token}", token, 1)
 	u, err := url.Parse(p.cfg.WebServerAddress + route)
@@ -396,24 +350,55 @@ func (p *Pi) mailNtfnVoteStartedToAuthor(token, name string, recipient map[uuid.
 		return err
 	}
 
-	subject := fmt.Sprintf(`Voting Started on Your Proposal "%v"`, name)
 	tmplData := voteStartedToAuthor{
 		Name: name,
 		Link: u.String(),
 	}
-	body, err := populateTemplate(voteStartedToAuthorTmpl, tmplData)
-	if err != nil {
-		return err
-	}
 
-	return p.mail.SendToUsers(subject, body, recipient)
+	key := "vote-started-author:" + token
+	return p.mail.SendToUsers(key, mailTmplVoteStartedToAuthor, tmplData, recipient)
 }
 
-func populateTemplate(tmpl *template.Template, tmplData interface{}) (string, error) {
-	var b bytes.Buffer
-	err := tmpl.Execute(&b, tmplData)
-	if err != nil {
-		return "", err
-	}
-	return b.String(), nil
+// init registers the built-in pi notification email templates.
+func init() {
+	mail.RegisterTemplate(mailTmplProposalNew, mail.Template{
+		Subject: `New Proposal Submitted "{{.Name}}"`,
+		Text:    proposalNewText,
+	})
+	mail.RegisterTemplate(mailTmplProposalEdit, mail.Template{
+		Subject: `Proposal Edited "{{.Name}}"`,
+		Text:    proposalEditText,
+	})
+	mail.RegisterTemplate(mailTmplProposalPublished, mail.Template{
+		Subject: `New Proposal Published "{{.Name}}"`,
+		Text:    proposalPublishedText,
+	})
+	mail.RegisterTemplate(mailTmplProposalPublishedToAuthor, mail.Template{
+		Subject: `Your Proposal Has Been Published {{.Token}}`,
+		Text:    proposalPublishedToAuthorText,
+	})
+	mail.RegisterTemplate(mailTmplProposalCensoredToAuthor, mail.Template{
+		Subject: `Your Proposal Has Been Censored "{{.Name}}"`,
+		Text:    proposalCensoredToAuthorText,
+	})
+	mail.RegisterTemplate(mailTmplCommentNewToProposalAuthor, mail.Template{
+		Subject: `New Comment on Your Proposal "{{.Name}}"`,
+		Text:    commentNewToProposalAuthorText,
+	})
+	mail.RegisterTemplate(mailTmplCommentReply, mail.Template{
+		Subject: `New Reply to Your Comment on "{{.Name}}"`,
+		Text:    commentReplyText,
+	})
+	mail.RegisterTemplate(mailTmplVoteAuthorized, mail.Template{
+		Subject: `Voting Authorized for "{{.Name}}"`,
+		Text:    voteAuthorizedText,
+	})
+	mail.RegisterTemplate(mailTmplVoteStarted, mail.Template{
+		Subject: `Voting Started for "{{.Name}}"`,
+		Text:    voteStartedText,
+	})
+	mail.RegisterTemplate(mailTmplVoteStartedToAuthor, mail.Template{
+		Subject: `Voting Started on Your Proposal "{{.Name}}"`,
+		Text:    voteStartedToAuthorText,
+	})
 }
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"sync"
 
 	pdv2 "github.com/decred/politeia/politeiad/api/v2"
 	pdclient "github.com/decred/politeia/politeiad/client"
@@ -27,10 +28,15 @@ type Pi struct {
 	cfg       *config.Config
 	politeiad *pdclient.Client
 	userdb    user.Database
+	mailerdb  user.MailerDB
 	mail      mail.Mailer
 	sessions  *sessions.Sessions
 	events    *events.Manager
 	policy    *v1.PolicyReply
+	templates *v1.TemplatesReply
+
+	// digestMtx serializes the updates to the user email digests.
+	digestMtx sync.Mutex
 }
 
 // HandlePolicy is the request handler for the pi v1 Policy route.
@@ -40,6 +46,13 @@ func (p *Pi) HandlePolicy(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, p.policy)
 }
 
+// HandleTemplates is the request handler for the pi v1 Templates route.
+func (p *Pi) HandleTemplates(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleTemplates")
+
+	util.RespondWithJSON(w, http.StatusOK, p.templates)
+}
+
 // HandleSetBillingStatus is the request handler for the pi v1 BillingStatus
 // route.
 func (p *Pi) HandleSetBillingStatus(w http.ResponseWriter, r *http.Request) {
@@ -98,6 +111,31 @@ func (p *Pi) HandleBillingStatusChanges(w http.ResponseWriter, r *http.Request)
 
 }
 
+// HandleBillingStatusTimestamps is the request handler for the pi v1
+// BillingStatusTimestamps route.
+func (p *Pi) HandleBillingStatusTimestamps(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleBillingStatusTimestamps")
+
+	var bst v1.BillingStatusTimestamps
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&bst); err != nil {
+		respondWithError(w, r, "HandleBillingStatusTimestamps: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	bstr, err := p.processBillingStatusTimestamps(r.Context(), bst)
+	if err != nil {
+		respondWithError(w, r, "HandleBillingStatusTimestamps: "+
+			"processBillingStatusTimestamps: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, bstr)
+}
+
 // HandleSummaries is the request handler for the pi v1 Summaries route.
 func (p *Pi) HandleSummaries(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("HandleSummaries")
@@ -123,7 +161,7 @@ func (p *Pi) HandleSummaries(w http.ResponseWriter, r *http.Request) {
 }
 
 // New returns a new Pi context.
-func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, m mail.Mailer, s *sessions.Sessions, e *events.Manager, plugins []pdv2.Plugin) (*Pi, error) {
+func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, mdb user.MailerDB, m mail.Mailer, s *sessions.Sessions, e *events.Manager, plugins []pdv2.Plugin) (*Pi, error) {
 	// Parse plugin settings
 	var (
 		textFileSizeMax              uint32
@@ -140,6 +178,8 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, m mail.Mai
 		billingStatusChangesMax      uint32
 		summariesPageSize            uint32
 		billingStatusChangesPageSize uint32
+		sections                     = []string{}
+		templates                    = []pi.ProposalTemplate{}
 	)
 	for _, p := range plugins {
 		if p.ID != pi.PluginID {
@@ -251,6 +291,18 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, m mail.Mai
 				}
 				billingStatusChangesPageSize = uint32(u)
 
+			case pi.SettingKeyProposalSections:
+				err := json.Unmarshal([]byte(v.Value), &sections)
+				if err != nil {
+					return nil, err
+				}
+
+			case pi.SettingKeyProposalTemplates:
+				err := json.Unmarshal([]byte(v.Value), &templates)
+				if err != nil {
+					return nil, err
+				}
+
 			default:
 				// Skip unknown settings
 				log.Warnf("Unknown plugin setting %v; Skipping...", v.Key)
@@ -298,11 +350,22 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, m mail.Mai
 			pi.SettingKeyBillingStatusChangesPageSize)
 	}
 
+	// Convert the proposal templates
+	ts := make([]v1.ProposalTemplate, 0, len(templates))
+	for _, v := range templates {
+		ts = append(ts, v1.ProposalTemplate{
+			Domain:   v.Domain,
+			Sections: v.Sections,
+			Template: v.Template,
+		})
+	}
+
 	// Setup pi context
 	p := Pi{
 		cfg:       cfg,
 		politeiad: pdc,
 		userdb:    udb,
+		mailerdb:  mdb,
 		sessions:  s,
 		events:    e,
 		mail:      m,
@@ -321,11 +384,19 @@ func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, m mail.Mai
 			SummariesPageSize:            summariesPageSize,
 			BillingStatusChangesPageSize: billingStatusChangesPageSize,
 			BillingStatusChangesMax:      billingStatusChangesMax,
+			Sections:                     sections,
+		},
+		templates: &v1.TemplatesReply{
+			Sections:  sections,
+			Templates: ts,
 		},
 	}
 
 	// Setup event listeners
 	p.setupEventListeners()
 
+	// Start the email digest scheduler
+	go p.digestScheduler()
+
 	return &p, nil
 }
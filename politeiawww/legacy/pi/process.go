@@ -79,6 +79,28 @@ func (p *Pi) processBillingStatusChanges(ctx context.Context, bscs v1.BillingSta
 	}, nil
 }
 
+// processBillingStatusTimestamps processes a pi v1 billingstatustimestamps
+// request.
+func (p *Pi) processBillingStatusTimestamps(ctx context.Context, bst v1.BillingStatusTimestamps) (*v1.BillingStatusTimestampsReply, error) {
+	log.Tracef("processBillingStatusTimestamps: %v", bst.Token)
+
+	// Send plugin command
+	bstr, err := p.politeiad.PiBillingStatusTimestamps(ctx, bst.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare reply
+	timestamps := make([]v1.Timestamp, 0, len(bstr.Timestamps))
+	for _, v := range bstr.Timestamps {
+		timestamps = append(timestamps, convertTimestampToAPI(v))
+	}
+
+	return &v1.BillingStatusTimestampsReply{
+		Timestamps: timestamps,
+	}, nil
+}
+
 // processSummaries processes a pi v1 summaries request.
 func (p *Pi) processSummaries(ctx context.Context, s v1.Summaries) (*v1.SummariesReply, error) {
 	log.Tracef("processSummaries: %v", s.Tokens)
@@ -122,6 +144,30 @@ func convertBillingStatusChangeToAPI(bsc pi.BillingStatusChange) v1.BillingStatu
 	}
 }
 
+func convertProofToAPI(p pi.Proof) v1.Proof {
+	return v1.Proof{
+		Type:       p.Type,
+		Digest:     p.Digest,
+		MerkleRoot: p.MerkleRoot,
+		MerklePath: p.MerklePath,
+		ExtraData:  p.ExtraData,
+	}
+}
+
+func convertTimestampToAPI(t pi.Timestamp) v1.Timestamp {
+	proofs := make([]v1.Proof, 0, len(t.Proofs))
+	for _, v := range t.Proofs {
+		proofs = append(proofs, convertProofToAPI(v))
+	}
+	return v1.Timestamp{
+		Data:       t.Data,
+		Digest:     t.Digest,
+		TxID:       t.TxID,
+		MerkleRoot: t.MerkleRoot,
+		Proofs:     proofs,
+	}
+}
+
 func convertBillingStatusToAPI(bs pi.BillingStatusT) v1.BillingStatusT {
 	switch bs {
 	case pi.BillingStatusActive:
@@ -60,6 +60,7 @@ type Politeiawww struct {
 	db        user.Database
 	sessions  *sessions.Sessions
 	mail      mail.Mailer
+	mailerDB  user.MailerDB
 	events    *events.Manager
 	http      *http.Client // Deprecated politeiad client
 	politeiad *pdclient.Client
@@ -73,6 +74,12 @@ type Politeiawww struct {
 	// removed once all user by email lookups have been taken out.
 	userEmails map[string]uuid.UUID // [email]userID
 
+	// userLanguages contains the language preferences of the users
+	// that have set one. The mail client uses it to render the
+	// notification emails in the language of each recipient without
+	// having to look up every recipient in the user database.
+	userLanguages map[uuid.UUID]string // [userID]language
+
 	// The following fields are only used during piwww mode.
 	userPaywallPool map[uuid.UUID]paywallPoolMember // [userid][paywallPoolMember]
 
@@ -106,6 +113,7 @@ func NewPoliteiawww(cfg *config.Config, router, auth *mux.Router, params *chainc
 			return nil, err
 		}
 		userDB = db
+		mailerDB = db
 
 	case config.MySQL, config.CockroachDB:
 		// If old encryption key is set it means that we need
@@ -167,12 +175,10 @@ func NewPoliteiawww(cfg *config.Config, router, auth *mux.Router, params *chainc
 		log.Infof("Cookie key generated")
 	}
 
-	// Setup mailer smtp client
-	mailer, err := mail.NewClient(cfg.MailHost, cfg.MailUser,
-		cfg.MailPass, cfg.MailAddress, cfg.MailCert,
-		cfg.MailSkipVerify, cfg.MailRateLimit, mailerDB)
+	// Setup the notification email templates
+	mailTemplates, err := mail.NewTemplates(cfg.MailTemplatesDir)
 	if err != nil {
-		return nil, fmt.Errorf("new mail client: %v", err)
+		return nil, fmt.Errorf("mail templates: %v", err)
 	}
 
 	// Setup legacy politeiawww context
@@ -184,14 +190,24 @@ func NewPoliteiawww(cfg *config.Config, router, auth *mux.Router, params *chainc
 		politeiad:       pdclient,
 		http:            httpClient,
 		db:              userDB,
-		mail:            mailer,
+		mailerDB:        mailerDB,
 		sessions:        sessions.New(userDB, cookieKey),
 		events:          events.NewManager(),
 		ws:              websockets.NewManager(cfg.WebsocketReadLimit),
 		userEmails:      make(map[string]uuid.UUID, 1024),
+		userLanguages:   make(map[uuid.UUID]string),
 		userPaywallPool: make(map[uuid.UUID]paywallPoolMember, 1024),
 	}
 
+	// Setup mailer smtp client
+	p.mail, err = mail.NewClient(cfg.MailHost, cfg.MailUser,
+		cfg.MailPass, cfg.MailAddress, cfg.MailCert,
+		cfg.MailSkipVerify, cfg.MailRateLimit, mailerDB, mailTemplates,
+		p.userLanguagesByID)
+	if err != nil {
+		return nil, fmt.Errorf("new mail client: %v", err)
+	}
+
 	err = p.setup()
 	if err != nil {
 		return nil, err
@@ -202,6 +218,9 @@ func NewPoliteiawww(cfg *config.Config, router, auth *mux.Router, params *chainc
 
 // Close performs any required shutdown and cleanup for Politeiawww.
 func (p *Politeiawww) Close() {
+	// Stop sending queued emails
+	p.mail.Close()
+
 	// Close user db connection
 	p.db.Close()
 
@@ -216,8 +235,8 @@ func (p *Politeiawww) Close() {
 
 // Setup performs any required setup for Politeiawww.
 func (p *Politeiawww) setup() error {
-	// Setup email-userID cache
-	err := p.initUserEmailsCache()
+	// Setup email-userID and user language caches
+	err := p.initUserCaches()
 	if err != nil {
 		return err
 	}
@@ -277,7 +296,7 @@ func (p *Politeiawww) setupPi() error {
 	if err != nil {
 		return fmt.Errorf("new ticketvote api: %v", err)
 	}
-	piCtx, err := pi.New(p.cfg, p.politeiad, p.db, p.mail,
+	piCtx, err := pi.New(p.cfg, p.politeiad, p.db, p.mailerDB, p.mail,
 		p.sessions, p.events, plugins)
 	if err != nil {
 		return fmt.Errorf("new pi api: %v", err)
@@ -757,6 +757,10 @@ func convertRecordToProposal(r pdv2.Record) (*www.ProposalRecord, error) {
 		changeMsgTimestamp                   int64
 	)
 	for _, v := range statuses {
+		if v.OwnershipTransfer != nil {
+			// Ownership transfers do not change the record status
+			continue
+		}
 		if v.Timestamp > changeMsgTimestamp {
 			changeMsg = v.Reason
 			changeMsgTimestamp = v.Timestamp
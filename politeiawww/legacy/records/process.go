@@ -41,11 +41,18 @@ func (r *Records) processNew(ctx context.Context, n v1.New, u user.User) (*v1.Ne
 		}
 	}
 
+	// Verify co-authors
+	coAuthors, err := r.coAuthorsVerify(u, n.CoAuthors)
+	if err != nil {
+		return nil, err
+	}
+
 	// Setup metadata stream
 	um := usermd.UserMetadata{
 		UserID:    u.ID.String(),
 		PublicKey: n.PublicKey,
 		Signature: n.Signature,
+		CoAuthors: coAuthors,
 	}
 	b, err := json.Marshal(um)
 	if err != nil {
@@ -143,11 +150,20 @@ func (r *Records) processEdit(ctx context.Context, e v1.Edit, u user.User) (*v1.
 	filesAdd := convertFilesToPD(e.Files)
 	filesDel := filesToDel(curr.Files, e.Files)
 
+	// Verify co-authors. The usermd plugin verifies that the user
+	// is one of the record authors and that the set of record authors
+	// has not changed.
+	coAuthors, err := r.coAuthorsVerify(u, e.CoAuthors)
+	if err != nil {
+		return nil, err
+	}
+
 	// Setup metadata
 	um := usermd.UserMetadata{
 		UserID:    u.ID.String(),
 		PublicKey: e.PublicKey,
 		Signature: e.Signature,
+		CoAuthors: coAuthors,
 	}
 	b, err := json.Marshal(um)
 	if err != nil {
@@ -259,18 +275,11 @@ func (r *Records) processDetails(ctx context.Context, d v1.Details, u *user.User
 		return nil, err
 	}
 
-	// Only admins and the record author are allowed to retrieve
+	// Only admins and the record authors are allowed to retrieve
 	// unvetted record files. Remove files if the user is not an admin
-	// or the author. This is a public route so a user may not exist.
-	if rc.State != v1.RecordStateVetted {
-		var (
-			authorID = userIDFromMetadataStreams(rc.Metadata)
-			isAuthor = u != nil && u.ID.String() == authorID
-			isAdmin  = u != nil && u.Admin
-		)
-		if !isAuthor && !isAdmin {
-			rc.Files = []v1.File{}
-		}
+	// or an author. This is a public route so a user may not exist.
+	if rc.State != v1.RecordStateVetted && !canViewFiles(u, *rc) {
+		rc.Files = []v1.File{}
 	}
 
 	return &v1.DetailsReply{
@@ -354,20 +363,13 @@ func (r *Records) processRecords(ctx context.Context, rs v1.Records, u *user.Use
 		return nil, err
 	}
 
-	// Only admins and the record author are allowed to retrieve
+	// Only admins and the record authors are allowed to retrieve
 	// unvetted record files. Remove files if the user is not an admin
-	// or the author. This is a public route so a user may not exist.
+	// or an author. This is a public route so a user may not exist.
 	for k, v := range records {
-		if v.State != v1.RecordStateVetted {
-			var (
-				authorID = userIDFromMetadataStreams(v.Metadata)
-				isAuthor = u != nil && u.ID.String() == authorID
-				isAdmin  = u != nil && u.Admin
-			)
-			if !isAuthor && !isAdmin {
-				v.Files = []v1.File{}
-				records[k] = v
-			}
+		if v.State != v1.RecordStateVetted && !canViewFiles(u, v) {
+			v.Files = []v1.File{}
+			records[k] = v
 		}
 	}
 
@@ -480,6 +482,109 @@ func (r *Records) processUserRecords(ctx context.Context, ur v1.UserRecords, u *
 	}, nil
 }
 
+func (r *Records) processTransferOwnership(ctx context.Context, to v1.TransferOwnership, u user.User) (*v1.TransferOwnershipReply, error) {
+	log.Tracef("processTransferOwnership: %v %v %v",
+		to.Token, to.FromUserID, to.ToUserID)
+
+	// Verify admin signed using active identity
+	if u.PublicKey() != to.PublicKey {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodePublicKeyInvalid,
+			ErrorContext: "not active identity",
+		}
+	}
+
+	// Verify that the author signed using their active identity
+	from, err := r.userByID(to.FromUserID)
+	if err != nil {
+		return nil, err
+	}
+	if from.PublicKey() != to.AuthorPublicKey {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodePublicKeyInvalid,
+			ErrorContext: "author did not sign with their active identity",
+		}
+	}
+
+	// Verify that the new author exists. The usermd plugin verifies
+	// the signatures and the record authors.
+	_, err = r.userByID(to.ToUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the plugin command to politeiad. The ownership transfer
+	// is added to the status change history of the record and does
+	// not change the record status.
+	_, err = r.politeiad.TransferOwnership(ctx, usermd.TransferOwnership{
+		Token:           to.Token,
+		Version:         to.Version,
+		FromUserID:      to.FromUserID,
+		ToUserID:        to.ToUserID,
+		Reason:          to.Reason,
+		AuthorPublicKey: to.AuthorPublicKey,
+		AuthorSignature: to.AuthorSignature,
+		PublicKey:       to.PublicKey,
+		Signature:       to.Signature,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the updated record
+	rc, err := r.record(ctx, to.Token, 0)
+	if err != nil {
+		if err == errRecordNotFound {
+			return nil, v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeRecordNotFound,
+			}
+		}
+		return nil, err
+	}
+
+	log.Infof("Record ownership transferred: %v %v to %v",
+		to.Token, to.FromUserID, to.ToUserID)
+
+	return &v1.TransferOwnershipReply{
+		Record: *rc,
+	}, nil
+}
+
+func (r *Records) processUserActivity(ctx context.Context, ua v1.UserActivity, u *user.User) (*v1.UserActivityReply, error) {
+	log.Tracef("processUserActivity: %v %v", ua.UserID, ua.Page)
+
+	// Verify user ID
+	_, err := uuid.Parse(ua.UserID)
+	if err != nil {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("invalid user id %v", ua.UserID),
+		}
+	}
+
+	// Only admins and the user are allowed to retrieve the activity
+	// that occurred on unvetted records. This is a public route so a
+	// user may not exist.
+	var (
+		isAdmin  = u != nil && u.Admin
+		isUser   = u != nil && u.ID.String() == ua.UserID
+		unvetted = isAdmin || isUser
+	)
+
+	activity, err := r.politeiad.UserActivity(ctx, usermd.UserActivity{
+		UserID:   ua.UserID,
+		Page:     ua.Page,
+		Unvetted: unvetted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.UserActivityReply{
+		Activity: convertActivityToV1(activity),
+	}, nil
+}
+
 func (r *Records) records(ctx context.Context, reqs []pdv2.RecordRequest) (map[string]v1.Record, error) {
 	// Get records
 	pdr, err := r.politeiad.Records(ctx, reqs)
@@ -524,6 +629,77 @@ func (r *Records) record(ctx context.Context, token string, version uint32) (*v1
 	return &rc, nil
 }
 
+// coAuthorsVerify verifies that each of the provided co-authors corresponds to
+// an existing user, that the co-author signed using their active identity, and
+// that the submitting user has not listed themselves as a co-author. The
+// co-authors are returned as usermd plugin co-authors. The signatures are
+// verified by the usermd plugin.
+func (r *Records) coAuthorsVerify(u user.User, cas []v1.CoAuthor) ([]usermd.CoAuthor, error) {
+	coAuthors := make([]usermd.CoAuthor, 0, len(cas))
+	for _, v := range cas {
+		uid, err := uuid.Parse(v.UserID)
+		if err != nil {
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeInputInvalid,
+				ErrorContext: fmt.Sprintf("invalid co-author %v", v.UserID),
+			}
+		}
+		if uid == u.ID {
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeInputInvalid,
+				ErrorContext: "user cannot be their own co-author",
+			}
+		}
+		ca, err := r.userdb.UserGetById(uid)
+		if err != nil {
+			if errors.Is(err, user.ErrUserNotFound) {
+				return nil, v1.UserErrorReply{
+					ErrorCode: v1.ErrorCodeInputInvalid,
+					ErrorContext: fmt.Sprintf("co-author %v not found",
+						v.UserID),
+				}
+			}
+			return nil, err
+		}
+		if ca.PublicKey() != v.PublicKey {
+			return nil, v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodePublicKeyInvalid,
+				ErrorContext: fmt.Sprintf("co-author %v did not sign "+
+					"with their active identity", v.UserID),
+			}
+		}
+		coAuthors = append(coAuthors, usermd.CoAuthor{
+			UserID:    v.UserID,
+			PublicKey: v.PublicKey,
+			Signature: v.Signature,
+		})
+	}
+	return coAuthors, nil
+}
+
+// userByID returns the user with the provided user ID. A user error is
+// returned if the user ID is invalid or if the user does not exist.
+func (r *Records) userByID(userID string) (*user.User, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("invalid user id %v", userID),
+		}
+	}
+	u, err := r.userdb.UserGetById(uid)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeInputInvalid,
+				ErrorContext: fmt.Sprintf("user %v not found", userID),
+			}
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
 // convertRecordToV1 converts a politeiad's Record to a records API Record,
 // then it populates the user data.
 func (r *Records) convertRecordToV1(pdr pdv2.Record) (*v1.Record, error) {
@@ -550,6 +726,30 @@ func recordPopulateUserData(r *v1.Record, u user.User) {
 	r.Username = u.Username
 }
 
+// canViewFiles returns whether the provided user is allowed to view the files
+// of an unvetted record. Admins and all of the current record authors,
+// including the co-authors and the users that the ownership of the record was
+// transferred to, are allowed to view unvetted files.
+func canViewFiles(u *user.User, r v1.Record) bool {
+	if u == nil {
+		return false
+	}
+	if u.Admin {
+		return true
+	}
+	authors, err := client.RecordAuthors(r.Version, r.Metadata)
+	if err != nil {
+		return false
+	}
+	userID := u.ID.String()
+	for _, v := range authors {
+		if v == userID {
+			return true
+		}
+	}
+	return false
+}
+
 // userIDFromMetadataStreams searches for a UserMetadata and parses the user ID
 // from it if found. An empty string is returned if no UserMetadata is found.
 func userIDFromMetadataStreams(ms []v1.MetadataStream) string {
@@ -656,6 +856,25 @@ func convertTimestampToV1(t pdv2.Timestamp) v1.Timestamp {
 	}
 }
 
+func convertActivityToV1(activity []usermd.Activity) []v1.Activity {
+	a := make([]v1.Activity, 0, len(activity))
+	for _, v := range activity {
+		a = append(a, v1.Activity{
+			Type:       v1.ActivityT(v.Type),
+			Token:      v.Token,
+			State:      convertStateToV1(pdv2.RecordStateT(v.State)),
+			Version:    v.Version,
+			Status:     convertStatusToV1(pdv2.RecordStatusT(v.Status)),
+			CommentID:  v.CommentID,
+			Vote:       v.Vote,
+			FromUserID: v.FromUserID,
+			ToUserID:   v.ToUserID,
+			Timestamp:  v.Timestamp,
+		})
+	}
+	return a
+}
+
 func convertFilesToPD(f []v1.File) []pdv2.File {
 	files := make([]pdv2.File, 0, len(f))
 	for _, v := range f {
@@ -328,6 +328,72 @@ func (c *Records) HandleUserRecords(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, urr)
 }
 
+// HandleTransferOwnership is the request handler for the records v1
+// TransferOwnership route.
+func (c *Records) HandleTransferOwnership(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleTransferOwnership")
+
+	var to v1.TransferOwnership
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&to); err != nil {
+		respondWithError(w, r, "HandleTransferOwnership: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	u, err := c.sessions.GetSessionUser(w, r)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleTransferOwnership: GetSessionUser: %v", err)
+		return
+	}
+
+	tor, err := c.processTransferOwnership(r.Context(), to, *u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleTransferOwnership: processTransferOwnership: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, tor)
+}
+
+// HandleUserActivity is the request handler for the records v1 UserActivity
+// route.
+func (c *Records) HandleUserActivity(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("HandleUserActivity")
+
+	var ua v1.UserActivity
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&ua); err != nil {
+		respondWithError(w, r, "HandleUserActivity: unmarshal",
+			v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeInputInvalid,
+			})
+		return
+	}
+
+	// Lookup session user. This is a public route so a session may not
+	// exist. Ignore any session not found errors.
+	u, err := c.sessions.GetSessionUser(w, r)
+	if err != nil && err != sessions.ErrSessionNotFound {
+		respondWithError(w, r,
+			"HandleUserActivity: GetSessionUser: %v", err)
+		return
+	}
+
+	uar, err := c.processUserActivity(r.Context(), ua, u)
+	if err != nil {
+		respondWithError(w, r,
+			"HandleUserActivity: processUserActivity: %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, uar)
+}
+
 // New returns a new Records context.
 func New(cfg *config.Config, pdc *pdclient.Client, udb user.Database, s *sessions.Sessions, e *events.Manager) *Records {
 	return &Records{
@@ -101,6 +101,21 @@ func (p *Politeiawww) setUserWWWRoutes() {
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteVerifyTOTP, p.handleVerifyTOTP,
 		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteSetWebAuthn, p.handleSetWebAuthn,
+		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteVerifyWebAuthn, p.handleVerifyWebAuthn,
+		permissionLogin)
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteWebAuthnCredentials, p.handleWebAuthnCredentials,
+		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteRemoveWebAuthn, p.handleRemoveWebAuthn,
+		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteWebAuthnBackupCodes, p.handleWebAuthnBackupCodes,
+		permissionLogin)
 
 	// Routes that require being logged in as an admin user.
 	p.addRoute(http.MethodPut, www.PoliteiaWWWAPIRoute,
@@ -109,6 +124,9 @@ func (p *Politeiawww) setUserWWWRoutes() {
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteManageUser, p.handleManageUser,
 		permissionAdmin)
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteMailQueue, p.handleMailQueue,
+		permissionAdmin)
 }
 
 // setCMSUserWWWRoutes setsup the user routes for cms mode
@@ -168,6 +186,21 @@ func (p *Politeiawww) setCMSUserWWWRoutes() {
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteVerifyTOTP, p.handleVerifyTOTP,
 		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteSetWebAuthn, p.handleSetWebAuthn,
+		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteVerifyWebAuthn, p.handleVerifyWebAuthn,
+		permissionLogin)
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteWebAuthnCredentials, p.handleWebAuthnCredentials,
+		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteRemoveWebAuthn, p.handleRemoveWebAuthn,
+		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteWebAuthnBackupCodes, p.handleWebAuthnBackupCodes,
+		permissionLogin)
 
 	// Routes that require being logged in as an admin user.
 	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
@@ -179,6 +212,9 @@ func (p *Politeiawww) setCMSUserWWWRoutes() {
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteManageUser, p.handleManageUser,
 		permissionAdmin)
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteMailQueue, p.handleMailQueue,
+		permissionAdmin)
 }
 
 func (p *Politeiawww) setCMSWWWRoutes() {
@@ -267,6 +303,21 @@ func (p *Politeiawww) setCMSWWWRoutes() {
 	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
 		www.RouteVerifyTOTP, p.handleVerifyTOTP,
 		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteSetWebAuthn, p.handleSetWebAuthn,
+		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteVerifyWebAuthn, p.handleVerifyWebAuthn,
+		permissionLogin)
+	p.addRoute(http.MethodGet, www.PoliteiaWWWAPIRoute,
+		www.RouteWebAuthnCredentials, p.handleWebAuthnCredentials,
+		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteRemoveWebAuthn, p.handleRemoveWebAuthn,
+		permissionLogin)
+	p.addRoute(http.MethodPost, www.PoliteiaWWWAPIRoute,
+		www.RouteWebAuthnBackupCodes, p.handleWebAuthnBackupCodes,
+		permissionLogin)
 	p.addRoute(http.MethodPost, cms.APIRoute,
 		cms.RouteUserCodeStats, p.handleUserCodeStats,
 		permissionLogin)
@@ -389,6 +440,12 @@ func (p *Politeiawww) setPiRoutes(r *records.Records, c *comments.Comments, t *t
 	p.addRoute(http.MethodPost, rcv1.APIRoute,
 		rcv1.RouteUserRecords, r.HandleUserRecords,
 		permissionPublic)
+	p.addRoute(http.MethodPost, rcv1.APIRoute,
+		rcv1.RouteTransferOwnership, r.HandleTransferOwnership,
+		permissionAdmin)
+	p.addRoute(http.MethodPost, rcv1.APIRoute,
+		rcv1.RouteUserActivity, r.HandleUserActivity,
+		permissionPublic)
 
 	// Comment routes
 	p.addRoute(http.MethodPost, cmv1.APIRoute,
@@ -455,12 +512,18 @@ func (p *Politeiawww) setPiRoutes(r *records.Records, c *comments.Comments, t *t
 	p.addRoute(http.MethodPost, piv1.APIRoute,
 		piv1.RoutePolicy, pic.HandlePolicy,
 		permissionPublic)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteTemplates, pic.HandleTemplates,
+		permissionPublic)
 	p.addRoute(http.MethodPost, piv1.APIRoute,
 		piv1.RouteSetBillingStatus, pic.HandleSetBillingStatus,
 		permissionAdmin)
 	p.addRoute(http.MethodPost, piv1.APIRoute,
 		piv1.RouteBillingStatusChanges, pic.HandleBillingStatusChanges,
 		permissionPublic)
+	p.addRoute(http.MethodPost, piv1.APIRoute,
+		piv1.RouteBillingStatusTimestamps, pic.HandleBillingStatusTimestamps,
+		permissionPublic)
 	p.addRoute(http.MethodPost, piv1.APIRoute,
 		piv1.RouteSummaries, pic.HandleSummaries,
 		permissionPublic)
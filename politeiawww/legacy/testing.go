@@ -295,6 +295,8 @@ func newTestPoliteiawww(t *testing.T) (*Politeiawww, func()) {
 			PaywallXpub:     xpub,
 			VoteDurationMin: 2016,
 			VoteDurationMax: 4032,
+			WebAuthnRPID:    "localhost",
+			WebAuthnOrigin:  "https://localhost:3000",
 		},
 		Identity: &fid.Public,
 	}
@@ -306,7 +308,12 @@ func newTestPoliteiawww(t *testing.T) (*Politeiawww, func()) {
 	}
 
 	// Setup mail client
-	mailClient, err := mail.NewClient("", "", "", "", "", false, 0, db)
+	mailTemplates, err := mail.NewTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mailClient, err := mail.NewClient("", "", "", "", "", false, 0, db,
+		mailTemplates, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -325,9 +332,11 @@ func newTestPoliteiawww(t *testing.T) (*Politeiawww, func()) {
 		auth:            mux.NewRouter(),
 		sessions:        sessions.New(db, cookieKey),
 		mail:            mailClient,
+		mailerDB:        db,
 		db:              db,
 		test:            true,
 		userEmails:      make(map[string]uuid.UUID),
+		userLanguages:   make(map[uuid.UUID]string),
 		userPaywallPool: make(map[uuid.UUID]paywallPoolMember),
 	}
 
@@ -408,7 +417,12 @@ func newTestCMSwww(t *testing.T) (*Politeiawww, func()) {
 	}
 
 	// Setup smtp
-	mailClient, err := mail.NewClient("", "", "", "", "", false, 0, db)
+	mailTemplates, err := mail.NewTemplates("")
+	if err != nil {
+		t.Fatalf("setup mail templates: %v", err)
+	}
+	mailClient, err := mail.NewClient("", "", "", "", "", false, 0, db,
+		mailTemplates, nil)
 	if err != nil {
 		t.Fatalf("setup SMTP: %v", err)
 	}
@@ -428,8 +442,10 @@ func newTestCMSwww(t *testing.T) (*Politeiawww, func()) {
 		auth:            mux.NewRouter(),
 		sessions:        sessions.New(db, cookieKey),
 		mail:            mailClient,
+		mailerDB:        db,
 		test:            true,
 		userEmails:      make(map[string]uuid.UUID),
+		userLanguages:   make(map[uuid.UUID]string),
 		userPaywallPool: make(map[uuid.UUID]paywallPoolMember),
 	}
 
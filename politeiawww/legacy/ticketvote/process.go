@@ -24,12 +24,19 @@ func (t *TicketVote) processAuthorize(ctx context.Context, a v1.Authorize, u use
 		}
 	}
 
-	// Verify user is the record author
-	authorID, err := t.politeiad.Author(ctx, a.Token)
+	// Verify user is one of the record authors
+	authorIDs, err := t.politeiad.Authors(ctx, a.Token)
 	if err != nil {
 		return nil, err
 	}
-	if u.ID.String() != authorID {
+	var isAuthor bool
+	for _, v := range authorIDs {
+		if u.ID.String() == v {
+			isAuthor = true
+			break
+		}
+	}
+	if !isAuthor {
 		return nil, v1.UserErrorReply{
 			ErrorCode:    v1.ErrorCodeUnauthorized,
 			ErrorContext: "user is not record author",
@@ -402,8 +409,14 @@ func convertVoteErrorToV1(e *ticketvote.VoteErrorT) *v1.VoteErrorT {
 		ve = v1.VoteErrorInvalid
 	case ticketvote.VoteErrorInternalError:
 		ve = v1.VoteErrorInternalError
+	case ticketvote.VoteErrorTokenInvalid:
+		ve = v1.VoteErrorTokenInvalid
 	case ticketvote.VoteErrorRecordNotFound:
 		ve = v1.VoteErrorRecordNotFound
+	case ticketvote.VoteErrorMultipleRecordVotes:
+		ve = v1.VoteErrorMultipleRecordVotes
+	case ticketvote.VoteErrorSignatureInvalid:
+		ve = v1.VoteErrorSignatureInvalid
 	case ticketvote.VoteErrorVoteBitInvalid:
 		ve = v1.VoteErrorVoteBitInvalid
 	case ticketvote.VoteErrorVoteStatusInvalid:
@@ -658,6 +658,28 @@ func (p *Politeiawww) processEditUser(eu *www.EditUser, user *user.User) (*www.E
 	if eu.EmailNotifications != nil {
 		user.EmailNotifications = *eu.EmailNotifications
 	}
+	if eu.EmailDigest != nil {
+		if _, ok := www.EmailDigest[*eu.EmailDigest]; !ok {
+			return nil, www.UserError{
+				ErrorCode: www.ErrorStatusInvalidEmailDigest,
+				ErrorContext: []string{
+					strconv.Itoa(int(*eu.EmailDigest)),
+				},
+			}
+		}
+		user.EmailDigest = uint32(*eu.EmailDigest)
+	}
+	if eu.Language != nil {
+		// An empty language resets the language preference to the
+		// default language.
+		if *eu.Language != "" && !p.isSupportedLanguage(*eu.Language) {
+			return nil, www.UserError{
+				ErrorCode:    www.ErrorStatusInvalidLanguage,
+				ErrorContext: []string{*eu.Language},
+			}
+		}
+		user.Language = *eu.Language
+	}
 
 	// Update the user in the database.
 	err := p.db.UserUpdate(*user)
@@ -665,9 +687,25 @@ func (p *Politeiawww) processEditUser(eu *www.EditUser, user *user.User) (*www.E
 		return nil, err
 	}
 
+	// Update the user languages cache
+	if eu.Language != nil {
+		p.setUserLanguageCache(user.ID, user.Language)
+	}
+
 	return &www.EditUserReply{}, nil
 }
 
+// isSupportedLanguage returns whether notification emails can be rendered in
+// the provided language.
+func (p *Politeiawww) isSupportedLanguage(language string) bool {
+	for _, v := range p.mail.Languages() {
+		if v == language {
+			return true
+		}
+	}
+	return false
+}
+
 // processUpdateUserKey sets a verification token and expiry to allow the user
 // to update his key pair; the token must be verified before it expires. If the
 // token is already set and is expired, it generates a new one.
@@ -1239,8 +1277,13 @@ func (p *Politeiawww) login(l www.Login) loginResult {
 		}
 	}
 
-	// First check if TOTP is enabled and verified.
-	if u.TOTPVerified {
+	// First check if TOTP is enabled and verified. Users that have
+	// registered a WebAuthn credential can use either a TOTP code or
+	// the WebAuthn second factor. The WebAuthn second factor is used
+	// when a TOTP code is not provided.
+	webAuthnRequired := len(u.WebAuthnCredentials) > 0
+	if u.TOTPVerified && (!webAuthnRequired || l.Code != "") {
+		webAuthnRequired = false
 		err := p.totpCheck(l.Code, u)
 		if err != nil {
 			return loginResult{
@@ -1256,30 +1299,11 @@ func (p *Politeiawww) login(l www.Login) loginResult {
 	if err != nil {
 		// Wrong password. Update user record with failed attempt.
 		log.Debugf("login: wrong password")
-		if !userIsLocked(u.FailedLoginAttempts) {
-			u.FailedLoginAttempts++
-			u.TOTPLastFailedCodeTime = make([]int64, 0, 2)
-			err := p.db.UserUpdate(*u)
-			if err != nil {
-				return loginResult{
-					reply: nil,
-					err:   err,
-				}
-			}
-			// If the failed attempt puts the user over the limit,
-			// send them an email informing them their account is
-			// now locked.
-			if userIsLocked(u.FailedLoginAttempts) {
-				recipient := map[uuid.UUID]string{
-					u.ID: u.Email,
-				}
-				err := p.emailUserAccountLocked(u.Username, recipient)
-				if err != nil {
-					return loginResult{
-						reply: nil,
-						err:   err,
-					}
-				}
+		err := p.loginFailed(u)
+		if err != nil {
+			return loginResult{
+				reply: nil,
+				err:   err,
 			}
 		}
 		return loginResult{
@@ -1316,6 +1340,19 @@ func (p *Politeiawww) login(l www.Login) loginResult {
 		}
 	}
 
+	// Verify the WebAuthn second factor. This is done once the
+	// password has been verified so that the login challenge is
+	// only returned to users that know the password.
+	if webAuthnRequired {
+		err := p.webAuthnCheck(l, u)
+		if err != nil {
+			return loginResult{
+				reply: nil,
+				err:   err,
+			}
+		}
+	}
+
 	// Update user record with successful login
 	lastLoginTime := u.LastLoginTime
 	u.FailedLoginAttempts = 0
@@ -1336,6 +1373,35 @@ func (p *Politeiawww) login(l www.Login) loginResult {
 	}
 }
 
+// loginFailed updates the user record with a failed login attempt. The user
+// is sent an email if the failed attempt locks their account.
+func (p *Politeiawww) loginFailed(u *user.User) error {
+	if userIsLocked(u.FailedLoginAttempts) {
+		return nil
+	}
+	u.FailedLoginAttempts++
+	u.TOTPLastFailedCodeTime = make([]int64, 0, 2)
+	err := p.db.UserUpdate(*u)
+	if err != nil {
+		return err
+	}
+
+	// If the failed attempt puts the user over the limit,
+	// send them an email informing them their account is
+	// now locked.
+	if userIsLocked(u.FailedLoginAttempts) {
+		recipient := map[uuid.UUID]string{
+			u.ID: u.Email,
+		}
+		err := p.emailUserAccountLocked(u.Username, recipient)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // createLoginReply creates a login reply.
 func (p *Politeiawww) createLoginReply(u *user.User, lastLoginTime int64) (*www.LoginReply, error) {
 	reply := www.LoginReply{
@@ -1351,6 +1417,7 @@ func (p *Politeiawww) createLoginReply(u *user.User, lastLoginTime int64) (*www.
 		ProposalCredits:    uint64(len(u.UnspentProposalCredits)),
 		LastLoginTime:      lastLoginTime,
 		TOTPVerified:       u.TOTPVerified,
+		WebAuthnEnabled:    len(u.WebAuthnCredentials) > 0,
 	}
 
 	if !p.userHasPaid(*u) {
@@ -1667,6 +1734,8 @@ func convertWWWUserFromDatabaseUser(user *user.User) www.User {
 		Identities:                      convertWWWIdentitiesFromDatabaseIdentities(user.Identities),
 		ProposalCredits:                 uint64(len(user.UnspentProposalCredits)),
 		EmailNotifications:              user.EmailNotifications,
+		EmailDigest:                     www.EmailDigestT(user.EmailDigest),
+		Language:                        user.Language,
 	}
 }
 
@@ -32,6 +32,8 @@ const (
 	tableIdentities     = "identities"
 	tableSessions       = "sessions"
 	tableEmailHistories = "email_histories"
+	tableEmailDigests   = "email_digests"
+	tableMailQueue      = "mail_queue"
 
 	// Database user (read/write access)
 	userPoliteiawww = "politeiawww"
@@ -887,6 +889,305 @@ func (c *cockroachdb) convertEmailHistoryToUser(eh EmailHistory) (*user.EmailHis
 	return &h, nil
 }
 
+// EmailDigestsSave creates or updates the email digests. The digests map
+// contains map[userid]EmailDigest.
+//
+// EmailDigestsSave satisfies the user MailerDB interface.
+func (c *cockroachdb) EmailDigestsSave(digests map[uuid.UUID]user.EmailDigest) error {
+	log.Tracef("EmailDigestsSave: %v", len(digests))
+
+	if len(digests) == 0 {
+		return nil
+	}
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	for userID, digest := range digests {
+		d := EmailDigest{
+			UserID: userID,
+		}
+
+		var update bool
+		err := c.userDB.Find(&d).Error
+		switch err {
+		case nil:
+			// DB entry already exists, update it.
+			update = true
+		case gorm.ErrRecordNotFound:
+			// DB entry doesn't exist, create new one.
+		default:
+			// All other errors
+			return fmt.Errorf("find email digest: %v", err)
+		}
+
+		digestDB, err := c.convertEmailDigestFromUser(userID, digest)
+		if err != nil {
+			return err
+		}
+
+		if update {
+			err := c.userDB.Save(&digestDB).Error
+			if err != nil {
+				return fmt.Errorf("save: %v", err)
+			}
+		} else {
+			err := c.userDB.Create(&digestDB).Error
+			if err != nil {
+				return fmt.Errorf("create: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// EmailDigestsGet retrieves the email digests for the provided user IDs. If a
+// provided user ID does not have an email digest, then the entry will be
+// skipped in the returned map. An error is not returned.
+//
+// EmailDigestsGet satisfies the user MailerDB interface.
+func (c *cockroachdb) EmailDigestsGet(users []uuid.UUID) (map[uuid.UUID]user.EmailDigest, error) {
+	log.Tracef("EmailDigestsGet: %v", users)
+
+	if c.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	var result []EmailDigest
+	err := c.userDB.
+		Where("user_id IN (?)", users).
+		Find(&result).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	digests := make(map[uuid.UUID]user.EmailDigest, len(result))
+	for _, row := range result {
+		d, err := c.convertEmailDigestToUser(row)
+		if err != nil {
+			return nil, err
+		}
+		digests[row.UserID] = *d
+	}
+
+	return digests, nil
+}
+
+func (c *cockroachdb) convertEmailDigestFromUser(userID uuid.UUID, d user.EmailDigest) (*EmailDigest, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	eb, err := c.encrypt(user.VersionEmailDigest, b)
+	if err != nil {
+		return nil, err
+	}
+	return &EmailDigest{
+		UserID: userID,
+		Blob:   eb,
+	}, nil
+}
+
+func (c *cockroachdb) convertEmailDigestToUser(ed EmailDigest) (*user.EmailDigest, error) {
+	b, _, err := c.decrypt(ed.Blob)
+	if err != nil {
+		return nil, err
+	}
+	var d user.EmailDigest
+	err = json.Unmarshal(b, &d)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// MailQueueNew inserts a new email into the mail queue. An ErrMailExists
+// error is returned if an email with the same ID has already been queued.
+//
+// MailQueueNew satisfies the user MailerDB interface.
+func (c *cockroachdb) MailQueueNew(qm user.QueuedMail) error {
+	log.Tracef("MailQueueNew: %v", qm.ID)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	mq, err := c.convertQueuedMailFromUser(qm)
+	if err != nil {
+		return err
+	}
+
+	tx := c.userDB.Begin()
+	err = tx.Find(&MailQueue{ID: qm.ID}).Error
+	switch err {
+	case nil:
+		tx.Rollback()
+		return user.ErrMailExists
+	case gorm.ErrRecordNotFound:
+		// Email has not been queued yet; continue
+	default:
+		tx.Rollback()
+		return fmt.Errorf("find mail: %v", err)
+	}
+	err = tx.Create(mq).Error
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("create: %v", err)
+	}
+
+	return tx.Commit().Error
+}
+
+// MailQueueUpdate updates an existing email in the mail queue. An
+// ErrMailNotFound error is returned if the email does not exist.
+//
+// MailQueueUpdate satisfies the user MailerDB interface.
+func (c *cockroachdb) MailQueueUpdate(qm user.QueuedMail) error {
+	log.Tracef("MailQueueUpdate: %v", qm.ID)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	mq, err := c.convertQueuedMailFromUser(qm)
+	if err != nil {
+		return err
+	}
+
+	r := c.userDB.Model(&MailQueue{ID: qm.ID}).
+		Updates(map[string]interface{}{
+			"status":          mq.Status,
+			"next_attempt_at": mq.NextAttemptAt,
+			"sent_at":         mq.SentAt,
+			"blob":            mq.Blob,
+		})
+	if r.Error != nil {
+		return fmt.Errorf("update: %v", r.Error)
+	}
+	if r.RowsAffected == 0 {
+		return user.ErrMailNotFound
+	}
+
+	return nil
+}
+
+// mailQueueDecode decodes the provided mail queue rows.
+func (c *cockroachdb) mailQueueDecode(rows []MailQueue) ([]user.QueuedMail, error) {
+	mail := make([]user.QueuedMail, 0, len(rows))
+	for _, v := range rows {
+		qm, err := c.convertQueuedMailToUser(v)
+		if err != nil {
+			return nil, err
+		}
+		mail = append(mail, *qm)
+	}
+	return mail, nil
+}
+
+// MailQueueDue returns up to limit queued emails whose next attempt time is
+// at or before the provided UNIX timestamp, ordered by next attempt time.
+//
+// MailQueueDue satisfies the user MailerDB interface.
+func (c *cockroachdb) MailQueueDue(now int64, limit int) ([]user.QueuedMail, error) {
+	log.Tracef("MailQueueDue: %v %v", now, limit)
+
+	if c.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	var rows []MailQueue
+	err := c.userDB.
+		Where("status = ? AND next_attempt_at <= ?",
+			int(user.MailStatusQueued), now).
+		Order("next_attempt_at asc").
+		Limit(limit).
+		Find(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return c.mailQueueDecode(rows)
+}
+
+// MailQueueGetByStatus returns up to limit emails with the provided status,
+// ordered by the time that they were created.
+//
+// MailQueueGetByStatus satisfies the user MailerDB interface.
+func (c *cockroachdb) MailQueueGetByStatus(s user.MailStatusT, limit int) ([]user.QueuedMail, error) {
+	log.Tracef("MailQueueGetByStatus: %v %v", s, limit)
+
+	if c.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	var rows []MailQueue
+	err := c.userDB.
+		Where("status = ?", int(s)).
+		Order("created_at asc").
+		Limit(limit).
+		Find(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	return c.mailQueueDecode(rows)
+}
+
+// MailQueuePrune deletes the sent emails that were sent before the provided
+// UNIX timestamp.
+//
+// MailQueuePrune satisfies the user MailerDB interface.
+func (c *cockroachdb) MailQueuePrune(sentBefore int64) error {
+	log.Tracef("MailQueuePrune: %v", sentBefore)
+
+	if c.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	return c.userDB.
+		Where("status = ? AND sent_at < ?",
+			int(user.MailStatusSent), sentBefore).
+		Delete(MailQueue{}).
+		Error
+}
+
+func (c *cockroachdb) convertQueuedMailFromUser(qm user.QueuedMail) (*MailQueue, error) {
+	b, err := json.Marshal(qm)
+	if err != nil {
+		return nil, err
+	}
+	eb, err := c.encrypt(user.VersionQueuedMail, b)
+	if err != nil {
+		return nil, err
+	}
+	return &MailQueue{
+		ID:            qm.ID,
+		Status:        int(qm.Status),
+		CreatedAt:     qm.CreatedAt,
+		NextAttemptAt: qm.NextAttemptAt,
+		SentAt:        qm.SentAt,
+		Blob:          eb,
+	}, nil
+}
+
+func (c *cockroachdb) convertQueuedMailToUser(mq MailQueue) (*user.QueuedMail, error) {
+	b, _, err := c.decrypt(mq.Blob)
+	if err != nil {
+		return nil, err
+	}
+	var qm user.QueuedMail
+	err = json.Unmarshal(b, &qm)
+	if err != nil {
+		return nil, err
+	}
+	return &qm, nil
+}
+
 // Close shuts down the database. All interface functions must return with
 // errShutdown if the backend is shutting down.
 //
@@ -936,6 +1237,18 @@ func (c *cockroachdb) createTables(tx *gorm.DB) error {
 			return err
 		}
 	}
+	if !tx.HasTable(tableEmailDigests) {
+		err := tx.CreateTable(&EmailDigest{}).Error
+		if err != nil {
+			return err
+		}
+	}
+	if !tx.HasTable(tableMailQueue) {
+		err := tx.CreateTable(&MailQueue{}).Error
+		if err != nil {
+			return err
+		}
+	}
 
 	// Insert version record
 	kv := KeyValue{
@@ -768,6 +768,62 @@ func TestEmailHistoriesGet(t *testing.T) {
 	}
 }
 
+func TestEmailDigestsGet(t *testing.T) {
+	cdb, mock, close := setupTestDB(t)
+	defer close()
+
+	// Arguments
+	userID := uuid.New()
+	digest := user.EmailDigest{
+		LastSent: time.Now().Unix(),
+		Entries: []user.DigestEntry{
+			{
+				Event: "proposal-new",
+				Token: "token",
+			},
+		},
+	}
+	db, err := json.Marshal(digest)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	eb, err := cdb.encrypt(user.VersionEmailDigest, db)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	// Mock data
+	rows := sqlmock.NewRows([]string{"user_id", "blob"}).
+		AddRow(userID, eb)
+
+	// Query
+	sql := `SELECT * FROM "email_digests" WHERE (user_id IN ($1))`
+
+	// Success expectations
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).
+		WithArgs(userID).
+		WillReturnRows(rows)
+
+	// Execute method
+	d, err := cdb.EmailDigestsGet([]uuid.UUID{userID})
+	if err != nil {
+		t.Errorf("EmailDigestsGet unwanted error: %s", err)
+	}
+
+	// Make sure correct digest was returned
+	if d[userID].LastSent != digest.LastSent ||
+		len(d[userID].Entries) != 1 ||
+		d[userID].Entries[0] != digest.Entries[0] {
+		t.Errorf("got digest %+v, want %+v", d[userID], digest)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
 func TestSessionSave(t *testing.T) {
 	cdb, mock, close := setupTestDB(t)
 	defer close()
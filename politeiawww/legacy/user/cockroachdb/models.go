@@ -57,6 +57,36 @@ func (EmailHistory) TableName() string {
 	return tableEmailHistories
 }
 
+// EmailDigest represents the notification events that have been accumulated
+// for a user that receives their email notifications as a digest.
+type EmailDigest struct {
+	UserID uuid.UUID `gorm:"primary_key"` // User UUID
+	Blob   []byte    `gorm:"not null"`    // Encrypted email digest
+}
+
+// TableName returns the table name of the EmailDigest table.
+func (EmailDigest) TableName() string {
+	return tableEmailDigests
+}
+
+// MailQueue represents an outbound email in the mail queue.
+//
+// Blob represents an encrypted user.QueuedMail. The fields that have been
+// broken out of the encrypted blob are the fields that need to be queryable.
+type MailQueue struct {
+	ID            string `gorm:"primary_key"`                       // Mail ID
+	Status        int    `gorm:"not null;index:idx_mail_queue_due"` // Mail status
+	CreatedAt     int64  `gorm:"not null"`                          // UNIX timestamp
+	NextAttemptAt int64  `gorm:"not null;index:idx_mail_queue_due"` // UNIX timestamp
+	SentAt        int64  `gorm:"not null"`                          // UNIX timestamp
+	Blob          []byte `gorm:"not null"`                          // Encrypted queued mail
+}
+
+// TableName returns the table name of the MailQueue table.
+func (MailQueue) TableName() string {
+	return tableMailQueue
+}
+
 // Session represents a user session.
 //
 // Key is a SHA256 hash of the decoded session ID. The session Store handles
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 
@@ -27,10 +28,17 @@ const (
 
 	// The key for a user email history is emailHistoryPrefix+userID
 	emailHistoryPrefix = "emailhistory:"
+
+	// The key for a user email digest is emailDigestPrefix+userID
+	emailDigestPrefix = "emaildigest:"
+
+	// The key for a queued email is mailQueuePrefix+mailID
+	mailQueuePrefix = "mailqueue:"
 )
 
 var (
 	_ user.Database = (*localdb)(nil)
+	_ user.MailerDB = (*localdb)(nil)
 )
 
 // localdb implements the Database interface.
@@ -58,7 +66,9 @@ func isUserRecord(key string) bool {
 		!strings.HasPrefix(key, sessionPrefix) &&
 		!strings.HasPrefix(key, cmsUserPrefix) &&
 		!strings.HasPrefix(key, cmsCodeStatsPrefix) &&
-		!strings.HasPrefix(key, emailHistoryPrefix)
+		!strings.HasPrefix(key, emailHistoryPrefix) &&
+		!strings.HasPrefix(key, emailDigestPrefix) &&
+		!strings.HasPrefix(key, mailQueuePrefix)
 }
 
 // Store new user.
@@ -542,6 +552,251 @@ func (l *localdb) EmailHistoriesGet(users []uuid.UUID) (map[uuid.UUID]user.Email
 	return histories, nil
 }
 
+// EmailDigestsSave saves an email digest for each user passed in the map.
+// The digests map contains map[userid]EmailDigest.
+//
+// EmailDigestsSave satisfies the user MailerDB interface.
+func (l *localdb) EmailDigestsSave(digests map[uuid.UUID]user.EmailDigest) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	if len(digests) == 0 {
+		return nil
+	}
+
+	log.Debugf("EmailDigestsSave: %v", len(digests))
+
+	for id, digest := range digests {
+		payload, err := json.Marshal(digest)
+		if err != nil {
+			return err
+		}
+		key := []byte(emailDigestPrefix + id.String())
+		err = l.userdb.Put(key, payload, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EmailDigestsGet retrieves the email digests for the provided user IDs. If a
+// provided user ID does not have an email digest, then the entry will be
+// skipped in the returned map. An error is not returned.
+//
+// EmailDigestsGet satisfies the user MailerDB interface.
+func (l *localdb) EmailDigestsGet(users []uuid.UUID) (map[uuid.UUID]user.EmailDigest, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return nil, user.ErrShutdown
+	}
+
+	log.Debugf("EmailDigestsGet: %v", users)
+
+	digests := make(map[uuid.UUID]user.EmailDigest, len(users))
+	for _, id := range users {
+		key := []byte(emailDigestPrefix + id.String())
+		payload, err := l.userdb.Get(key, nil)
+		if errors.Is(err, leveldb.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var d user.EmailDigest
+		err = json.Unmarshal(payload, &d)
+		if err != nil {
+			return nil, err
+		}
+
+		digests[id] = d
+	}
+
+	return digests, nil
+}
+
+// MailQueueNew inserts a new email into the mail queue. An ErrMailExists
+// error is returned if an email with the same ID has already been queued.
+//
+// MailQueueNew satisfies the user MailerDB interface.
+func (l *localdb) MailQueueNew(m user.QueuedMail) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	log.Debugf("MailQueueNew: %v", m.ID)
+
+	key := []byte(mailQueuePrefix + m.ID)
+	exists, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return user.ErrMailExists
+	}
+
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put(key, payload, nil)
+}
+
+// MailQueueUpdate updates an existing email in the mail queue. An
+// ErrMailNotFound error is returned if the email does not exist.
+//
+// MailQueueUpdate satisfies the user MailerDB interface.
+func (l *localdb) MailQueueUpdate(m user.QueuedMail) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	log.Debugf("MailQueueUpdate: %v", m.ID)
+
+	key := []byte(mailQueuePrefix + m.ID)
+	exists, err := l.userdb.Has(key, nil)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return user.ErrMailNotFound
+	}
+
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return l.userdb.Put(key, payload, nil)
+}
+
+// mailQueueFilter iterates the mail queue and returns the emails for which
+// the provided filter function returns true.
+//
+// This function must be called with the read lock held.
+func (l *localdb) mailQueueFilter(filter func(user.QueuedMail) bool) ([]user.QueuedMail, error) {
+	mail := make([]user.QueuedMail, 0, 64)
+	iter := l.userdb.NewIterator(util.BytesPrefix([]byte(mailQueuePrefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		var m user.QueuedMail
+		err := json.Unmarshal(iter.Value(), &m)
+		if err != nil {
+			return nil, err
+		}
+		if filter(m) {
+			mail = append(mail, m)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	return mail, nil
+}
+
+// MailQueueDue returns up to limit queued emails whose next attempt time is
+// at or before the provided UNIX timestamp, ordered by next attempt time.
+//
+// MailQueueDue satisfies the user MailerDB interface.
+func (l *localdb) MailQueueDue(now int64, limit int) ([]user.QueuedMail, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return nil, user.ErrShutdown
+	}
+
+	log.Debugf("MailQueueDue: %v %v", now, limit)
+
+	due, err := l.mailQueueFilter(func(m user.QueuedMail) bool {
+		return m.Status == user.MailStatusQueued && m.NextAttemptAt <= now
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt < due[j].NextAttemptAt
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+// MailQueueGetByStatus returns up to limit emails with the provided status,
+// ordered by the time that they were created.
+//
+// MailQueueGetByStatus satisfies the user MailerDB interface.
+func (l *localdb) MailQueueGetByStatus(s user.MailStatusT, limit int) ([]user.QueuedMail, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	if l.shutdown {
+		return nil, user.ErrShutdown
+	}
+
+	log.Debugf("MailQueueGetByStatus: %v %v", s, limit)
+
+	mail, err := l.mailQueueFilter(func(m user.QueuedMail) bool {
+		return m.Status == s
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(mail, func(i, j int) bool {
+		return mail[i].CreatedAt < mail[j].CreatedAt
+	})
+	if len(mail) > limit {
+		mail = mail[:limit]
+	}
+
+	return mail, nil
+}
+
+// MailQueuePrune deletes the sent emails that were sent before the provided
+// UNIX timestamp.
+//
+// MailQueuePrune satisfies the user MailerDB interface.
+func (l *localdb) MailQueuePrune(sentBefore int64) error {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.shutdown {
+		return user.ErrShutdown
+	}
+
+	log.Debugf("MailQueuePrune: %v", sentBefore)
+
+	mail, err := l.mailQueueFilter(func(m user.QueuedMail) bool {
+		return m.Status == user.MailStatusSent && m.SentAt < sentBefore
+	})
+	if err != nil {
+		return err
+	}
+	batch := new(leveldb.Batch)
+	for _, m := range mail {
+		batch.Delete([]byte(mailQueuePrefix + m.ID))
+	}
+
+	return l.userdb.Write(batch, nil)
+}
+
 // Close shuts down the database.  All interface functions MUST return with
 // errShutdown if the backend is shutting down.
 //
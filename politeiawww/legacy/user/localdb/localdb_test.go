@@ -181,6 +181,134 @@ func TestSessionDeleteByID(t *testing.T) {
 	}
 }
 
+func TestMailQueue(t *testing.T) {
+	db, dataDir := setupTestData(t)
+	defer teardownTestData(t, db, dataDir)
+
+	// Queue an email
+	m := user.QueuedMail{
+		ID:            "proposal-new:token",
+		Subject:       "subject",
+		Body:          "body",
+		Recipients:    []string{"user@example.com"},
+		Status:        user.MailStatusQueued,
+		CreatedAt:     1,
+		NextAttemptAt: 10,
+	}
+	err := db.MailQueueNew(m)
+	if err != nil {
+		t.Fatalf("MailQueueNew: %v", err)
+	}
+
+	// Queuing an email with the same ID must fail
+	err = db.MailQueueNew(m)
+	if !errors.Is(err, user.ErrMailExists) {
+		t.Fatalf("got error %v, want %v", err, user.ErrMailExists)
+	}
+
+	// The email must only be due once its next attempt time has passed
+	due, err := db.MailQueueDue(9, 10)
+	if err != nil {
+		t.Fatalf("MailQueueDue: %v", err)
+	}
+	if len(due) != 0 {
+		t.Fatalf("got %v due emails, want 0", len(due))
+	}
+	due, err = db.MailQueueDue(10, 10)
+	if err != nil {
+		t.Fatalf("MailQueueDue: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != m.ID {
+		t.Fatalf("got due emails %v, want %v", due, m.ID)
+	}
+
+	// Mark the email as sent
+	m.Status = user.MailStatusSent
+	m.SentAt = 20
+	err = db.MailQueueUpdate(m)
+	if err != nil {
+		t.Fatalf("MailQueueUpdate: %v", err)
+	}
+	sent, err := db.MailQueueGetByStatus(user.MailStatusSent, 10)
+	if err != nil {
+		t.Fatalf("MailQueueGetByStatus: %v", err)
+	}
+	if len(sent) != 1 || sent[0].SentAt != m.SentAt {
+		t.Fatalf("got sent emails %v, want %v", sent, m.ID)
+	}
+
+	// Updating an email that does not exist must fail
+	err = db.MailQueueUpdate(user.QueuedMail{ID: "missing"})
+	if !errors.Is(err, user.ErrMailNotFound) {
+		t.Fatalf("got error %v, want %v", err, user.ErrMailNotFound)
+	}
+
+	// Sent emails are only pruned once they are older than the cutoff
+	err = db.MailQueuePrune(20)
+	if err != nil {
+		t.Fatalf("MailQueuePrune: %v", err)
+	}
+	sent, err = db.MailQueueGetByStatus(user.MailStatusSent, 10)
+	if err != nil {
+		t.Fatalf("MailQueueGetByStatus: %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("got %v sent emails, want 1", len(sent))
+	}
+	err = db.MailQueuePrune(21)
+	if err != nil {
+		t.Fatalf("MailQueuePrune: %v", err)
+	}
+	sent, err = db.MailQueueGetByStatus(user.MailStatusSent, 10)
+	if err != nil {
+		t.Fatalf("MailQueueGetByStatus: %v", err)
+	}
+	if len(sent) != 0 {
+		t.Errorf("got %v sent emails, want 0", len(sent))
+	}
+}
+
+func TestEmailDigests(t *testing.T) {
+	db, dataDir := setupTestData(t)
+	defer teardownTestData(t, db, dataDir)
+
+	var (
+		userID  = uuid.New()
+		missing = uuid.New()
+		digest  = user.EmailDigest{
+			LastSent: 1,
+			Entries: []user.DigestEntry{
+				{
+					Event:     "proposal-new",
+					Token:     "token",
+					Name:      "name",
+					Timestamp: 2,
+				},
+			},
+		}
+	)
+	err := db.EmailDigestsSave(map[uuid.UUID]user.EmailDigest{
+		userID: digest,
+	})
+	if err != nil {
+		t.Fatalf("EmailDigestsSave: %v", err)
+	}
+
+	// Users without a digest are skipped
+	digests, err := db.EmailDigestsGet([]uuid.UUID{userID, missing})
+	if err != nil {
+		t.Fatalf("EmailDigestsGet: %v", err)
+	}
+	if len(digests) != 1 {
+		t.Fatalf("got %v digests, want 1", len(digests))
+	}
+	d := digests[userID]
+	if d.LastSent != digest.LastSent || len(d.Entries) != 1 ||
+		d.Entries[0] != digest.Entries[0] {
+		t.Errorf("got digest %+v, want %+v", d, digest)
+	}
+}
+
 func TestIsUserRecord(t *testing.T) {
 	tests := []struct {
 		input string
@@ -198,6 +326,14 @@ func TestIsUserRecord(t *testing.T) {
 			input: sessionPrefix + uuid.New().String(),
 			want:  false,
 		},
+		{
+			input: mailQueuePrefix + uuid.New().String(),
+			want:  false,
+		},
+		{
+			input: emailDigestPrefix + uuid.New().String(),
+			want:  false,
+		},
 	}
 
 	for _, test := range tests {
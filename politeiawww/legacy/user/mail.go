@@ -4,10 +4,14 @@
 
 package user
 
-import "github.com/google/uuid"
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
 
 // MailerDB describes the interface used to interact with the email histories
-// table from the user database, used by the mail client.
+// the email digests and the mail queue tables from the user database, used by the mail client.
 type MailerDB interface {
 	// EmailHistoriesSave saves the provided email histories to the
 	// database. The histories map contains map[userid]EmailHistory.
@@ -19,6 +23,38 @@ type MailerDB interface {
 	// does not correspond to a user in the database then the entry will
 	// be skipped in the returned map. An error is not returned.
 	EmailHistoriesGet(users []uuid.UUID) (map[uuid.UUID]EmailHistory, error)
+
+	// EmailDigestsSave saves the provided email digests to the database.
+	// The digests map contains map[userid]EmailDigest.
+	EmailDigestsSave(digests map[uuid.UUID]EmailDigest) error
+
+	// EmailDigestsGet retrieves the email digests for the provided user
+	// IDs. If a provided user ID does not have an email digest then the
+	// entry will be skipped in the returned map. An error is not
+	// returned.
+	EmailDigestsGet(users []uuid.UUID) (map[uuid.UUID]EmailDigest, error)
+
+	// MailQueueNew inserts a new email into the mail queue. An
+	// ErrMailExists error is returned if an email with the same ID has
+	// already been queued.
+	MailQueueNew(m QueuedMail) error
+
+	// MailQueueUpdate updates an existing email in the mail queue. An
+	// ErrMailNotFound error is returned if the email does not exist.
+	MailQueueUpdate(m QueuedMail) error
+
+	// MailQueueDue returns up to limit queued emails whose next attempt
+	// time is at or before the provided UNIX timestamp, ordered by next
+	// attempt time.
+	MailQueueDue(now int64, limit int) ([]QueuedMail, error)
+
+	// MailQueueGetByStatus returns up to limit emails with the provided
+	// status, ordered by the time that they were created.
+	MailQueueGetByStatus(s MailStatusT, limit int) ([]QueuedMail, error)
+
+	// MailQueuePrune deletes the sent emails that were sent before the
+	// provided UNIX timestamp.
+	MailQueuePrune(sentBefore int64) error
 }
 
 // EmailHistory keeps track of the received emails by each user. This is
@@ -38,3 +74,85 @@ type EmailHistory struct {
 
 // VersionEmailHistory is the version of the EmailHistory struct.
 const VersionEmailHistory uint32 = 1
+
+// EmailDigest contains the notification events that have been accumulated
+// for a user that receives their email notifications as a periodic digest
+// instead of as individual emails. Like the EmailHistory, this is not stored
+// in the user object since the digests are updated by the email notification
+// goroutines.
+type EmailDigest struct {
+	// LastSent is the UNIX timestamp of the last time that the digest was
+	// sent. It is set when the first entry is added to a digest that has
+	// never been sent so that the digest period starts with the first
+	// event.
+	LastSent int64         `json:"lastsent"`
+	Entries  []DigestEntry `json:"entries"`
+}
+
+// VersionEmailDigest is the version of the EmailDigest struct.
+const VersionEmailDigest uint32 = 1
+
+// DigestEntry is a notification event that has been added to an email
+// digest. The fields that are populated depend on the event type.
+type DigestEntry struct {
+	Event     string `json:"event"`               // Notification event type
+	Token     string `json:"token"`               // Record token
+	Name      string `json:"name"`                // Record name
+	Username  string `json:"username,omitempty"`  // User that caused the event
+	Version   uint32 `json:"version,omitempty"`   // Record version
+	CommentID uint32 `json:"commentid,omitempty"` // Comment ID
+	Reason    string `json:"reason,omitempty"`    // Status change reason
+	Timestamp int64  `json:"timestamp"`           // UNIX timestamp
+}
+
+// MailStatusT represents the status of a queued email.
+type MailStatusT int
+
+const (
+	// MailStatusInvalid is an invalid mail status.
+	MailStatusInvalid MailStatusT = 0
+
+	// MailStatusQueued indicates that the email has not been sent yet.
+	// The email will be sent, or retried, once its next attempt time
+	// has passed.
+	MailStatusQueued MailStatusT = 1
+
+	// MailStatusSent indicates that the email was sent successfully.
+	MailStatusSent MailStatusT = 2
+
+	// MailStatusFailed indicates that the email could not be sent after
+	// the maximum number of attempts. Failed emails are not retried and
+	// remain in the queue as dead letters until they are inspected by an
+	// admin.
+	MailStatusFailed MailStatusT = 3
+)
+
+var (
+	// ErrMailExists is returned when a queued email is inserted using an
+	// ID that already exists in the mail queue.
+	ErrMailExists = errors.New("mail already exists")
+
+	// ErrMailNotFound is returned when a queued email does not exist.
+	ErrMailNotFound = errors.New("mail not found")
+)
+
+// QueuedMail is an outbound email that has been persisted to the mail
+// queue. The ID doubles as an idempotency key. An email that is queued using
+// the ID of an existing queued email will be rejected, which prevents the
+// same notification from being sent twice.
+type QueuedMail struct {
+	ID            string      `json:"id"`
+	Subject       string      `json:"subject"`
+	Body          string      `json:"body"`
+	HTML          string      `json:"html,omitempty"` // Optional HTML body
+	Recipients    []string    `json:"recipients"`
+	Status        MailStatusT `json:"status"`
+	Attempts      int         `json:"attempts"`      // Send attempts
+	LastError     string      `json:"lasterror"`     // Last send error
+	CreatedAt     int64       `json:"createdat"`     // UNIX timestamp
+	NextAttemptAt int64       `json:"nextattemptat"` // UNIX timestamp
+	SentAt        int64       `json:"sentat"`        // UNIX timestamp
+}
+
+// VersionQueuedMail is the version of the QueuedMail struct.
+const VersionQueuedMail uint32 = 1
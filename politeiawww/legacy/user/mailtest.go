@@ -5,6 +5,7 @@
 package user
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/google/uuid"
@@ -17,6 +18,8 @@ type testMailerDB struct {
 	sync.RWMutex
 
 	Histories map[uuid.UUID]EmailHistory
+	Digests   map[uuid.UUID]EmailDigest
+	Queue     map[string]QueuedMail // [id]QueuedMail
 }
 
 // NewTestMailerDB returns a new testMailerDB. The caller can optionally
@@ -28,6 +31,8 @@ func NewTestMailerDB(histories map[uuid.UUID]EmailHistory) *testMailerDB {
 	}
 	return &testMailerDB{
 		Histories: histories,
+		Digests:   make(map[uuid.UUID]EmailDigest, 1024),
+		Queue:     make(map[string]QueuedMail, 1024),
 	}
 }
 
@@ -66,3 +71,130 @@ func (m *testMailerDB) EmailHistoriesGet(users []uuid.UUID) (map[uuid.UUID]Email
 	}
 	return histories, nil
 }
+
+// EmailDigestsSave saves the email digests to the in memory cache.
+//
+// This function satisfies the MailerDB interface.
+func (m *testMailerDB) EmailDigestsSave(digests map[uuid.UUID]EmailDigest) error {
+	m.Lock()
+	defer m.Unlock()
+
+	for userID, digest := range digests {
+		m.Digests[userID] = digest
+	}
+
+	return nil
+}
+
+// EmailDigestsGet returns the email digests from the in memory cache.
+//
+// This function satisfies the MailerDB interface.
+func (m *testMailerDB) EmailDigestsGet(users []uuid.UUID) (map[uuid.UUID]EmailDigest, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	digests := make(map[uuid.UUID]EmailDigest, len(users))
+	for _, userID := range users {
+		d, ok := m.Digests[userID]
+		if !ok {
+			continue
+		}
+		digests[userID] = d
+	}
+	return digests, nil
+}
+
+// MailQueueNew inserts a new email into the in memory mail queue.
+//
+// This function satisfies the MailerDB interface.
+func (m *testMailerDB) MailQueueNew(qm QueuedMail) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.Queue[qm.ID]; ok {
+		return ErrMailExists
+	}
+	m.Queue[qm.ID] = qm
+
+	return nil
+}
+
+// MailQueueUpdate updates an email in the in memory mail queue.
+//
+// This function satisfies the MailerDB interface.
+func (m *testMailerDB) MailQueueUpdate(qm QueuedMail) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if _, ok := m.Queue[qm.ID]; !ok {
+		return ErrMailNotFound
+	}
+	m.Queue[qm.ID] = qm
+
+	return nil
+}
+
+// MailQueueDue returns the queued emails that are due to be sent from the in
+// memory mail queue.
+//
+// This function satisfies the MailerDB interface.
+func (m *testMailerDB) MailQueueDue(now int64, limit int) ([]QueuedMail, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	due := make([]QueuedMail, 0, len(m.Queue))
+	for _, qm := range m.Queue {
+		if qm.Status == MailStatusQueued && qm.NextAttemptAt <= now {
+			due = append(due, qm)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].NextAttemptAt < due[j].NextAttemptAt
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	return due, nil
+}
+
+// MailQueueGetByStatus returns the emails with the provided status from the
+// in memory mail queue.
+//
+// This function satisfies the MailerDB interface.
+func (m *testMailerDB) MailQueueGetByStatus(s MailStatusT, limit int) ([]QueuedMail, error) {
+	m.RLock()
+	defer m.RUnlock()
+
+	mail := make([]QueuedMail, 0, len(m.Queue))
+	for _, qm := range m.Queue {
+		if qm.Status == s {
+			mail = append(mail, qm)
+		}
+	}
+	sort.SliceStable(mail, func(i, j int) bool {
+		return mail[i].CreatedAt < mail[j].CreatedAt
+	})
+	if len(mail) > limit {
+		mail = mail[:limit]
+	}
+
+	return mail, nil
+}
+
+// MailQueuePrune deletes the sent emails that were sent before the provided
+// timestamp from the in memory mail queue.
+//
+// This function satisfies the MailerDB interface.
+func (m *testMailerDB) MailQueuePrune(sentBefore int64) error {
+	m.Lock()
+	defer m.Unlock()
+
+	for id, qm := range m.Queue {
+		if qm.Status == MailStatusSent && qm.SentAt < sentBefore {
+			delete(m.Queue, id)
+		}
+	}
+
+	return nil
+}
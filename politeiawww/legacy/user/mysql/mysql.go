@@ -44,6 +44,8 @@ const (
 	tableNameIdentities     = "identities"
 	tableNameSessions       = "sessions"
 	tableNameEmailHistories = "email_histories"
+	tableNameEmailDigests   = "email_digests"
+	tableNameMailQueue      = "mail_queue"
 
 	// Key-value store keys.
 	keyVersion             = "version"
@@ -89,6 +91,25 @@ const tableEmailHistories = `
   h_blob  BLOB NOT NULL
 `
 
+// tableEmailDigests defines the email_digests table.
+const tableEmailDigests = `
+  user_id VARCHAR(36) NOT NULL PRIMARY KEY,
+  d_blob  LONGBLOB NOT NULL
+`
+
+// tableMailQueue defines the mail_queue table. The m_blob column contains
+// the encrypted user.QueuedMail. The remaining columns are broken out of the
+// blob so that they can be queried.
+const tableMailQueue = `
+  id              VARCHAR(255) NOT NULL PRIMARY KEY,
+  status          INT(11) NOT NULL,
+  created_at      INT(11) NOT NULL,
+  next_attempt_at INT(11) NOT NULL,
+  sent_at         INT(11) NOT NULL,
+  m_blob          LONGBLOB NOT NULL,
+  INDEX (status, next_attempt_at)
+`
+
 var (
 	_ user.Database = (*mysql)(nil)
 	_ user.MailerDB = (*mysql)(nil)
@@ -1316,6 +1337,365 @@ func (m *mysql) EmailHistoriesGet(users []uuid.UUID) (map[uuid.UUID]user.EmailHi
 	return histories, nil
 }
 
+// EmailDigestsSave creates or updates the email digests in the database. The
+// digests map contains map[userid]EmailDigest.
+//
+// EmailDigestsSave satisfies the user MailerDB interface.
+func (m *mysql) EmailDigestsSave(digests map[uuid.UUID]user.EmailDigest) error {
+	log.Tracef("EmailDigestsSave: %v", len(digests))
+
+	if len(digests) == 0 {
+		return nil
+	}
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	// Start transaction.
+	opts := &sql.TxOptions{
+		Isolation: sql.LevelDefault,
+	}
+	tx, err := m.userDB.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin tx: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Execute statements
+	err = m.emailDigestsSave(ctx, tx, digests)
+	if err != nil {
+		return err
+	}
+
+	// Commit transaction.
+	if err := tx.Commit(); err != nil {
+		if err2 := tx.Rollback(); err2 != nil {
+			// We're in trouble!
+			panic(fmt.Errorf("rollback tx failed: commit:'%v' rollback:'%v'",
+				err, err2))
+		}
+		return fmt.Errorf("commit tx: %v", err)
+	}
+
+	return nil
+}
+
+// emailDigestsSave creates or updates the email digests for the given users
+// in the digests map[userid]EmailDigest.
+//
+// This function must be called using a sql transaction.
+func (m *mysql) emailDigestsSave(ctx context.Context, tx *sql.Tx, digests map[uuid.UUID]user.EmailDigest) error {
+	for userID, digest := range digests {
+		var (
+			update bool
+			id     string
+		)
+		err := tx.QueryRowContext(ctx,
+			"SELECT user_id FROM email_digests WHERE user_id = ?", userID).
+			Scan(&id)
+		switch err {
+		case nil:
+			// Email digest already exists for this user, update it.
+			update = true
+		case sql.ErrNoRows:
+			// Email digest doesn't exist for this user, create new one.
+		default:
+			// All other errors
+			return fmt.Errorf("lookup: %v", err)
+		}
+
+		// Make email digest blob
+		db, err := json.Marshal(digest)
+		if err != nil {
+			return fmt.Errorf("convert email digest to DB: %w", err)
+		}
+		eb, err := m.encrypt(user.VersionEmailDigest, db)
+		if err != nil {
+			return err
+		}
+
+		// Save email digest
+		if update {
+			_, err := tx.ExecContext(ctx,
+				`UPDATE email_digests SET d_blob = ? WHERE user_id = ?`,
+				eb, userID)
+			if err != nil {
+				return fmt.Errorf("update: %v", err)
+			}
+		} else {
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO email_digests (user_id, d_blob) VALUES (?, ?)`,
+				userID, eb)
+			if err != nil {
+				return fmt.Errorf("create: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// EmailDigestsGet retrieves the email digests for the provided user IDs. If a
+// provided user ID does not have an email digest, then the entry will be
+// skipped in the returned map. An error is not returned.
+//
+// EmailDigestsGet satisfies the user MailerDB interface.
+func (m *mysql) EmailDigestsGet(users []uuid.UUID) (map[uuid.UUID]user.EmailDigest, error) {
+	log.Tracef("EmailDigestsGet: %v", users)
+
+	if m.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	digests := make(map[uuid.UUID]user.EmailDigest, len(users))
+	if len(users) == 0 {
+		return digests, nil
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	// Lookup email digests by user ids.
+	q := `SELECT user_id, d_blob FROM email_digests WHERE user_id IN (?` +
+		strings.Repeat(",?", len(users)-1) + `)`
+
+	args := make([]interface{}, len(users))
+	for i, userID := range users {
+		args[i] = userID.String()
+	}
+	rows, err := m.userDB.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			userID string
+			blob   []byte
+		)
+		if err := rows.Scan(&userID, &blob); err != nil {
+			return nil, err
+		}
+
+		b, _, err := m.decrypt(blob)
+		if err != nil {
+			return nil, err
+		}
+
+		var d user.EmailDigest
+		err = json.Unmarshal(b, &d)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, err
+		}
+
+		digests[id] = d
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return digests, nil
+}
+
+// encodeQueuedMail returns the encrypted blob of the provided queued email.
+func (m *mysql) encodeQueuedMail(qm user.QueuedMail) ([]byte, error) {
+	b, err := json.Marshal(qm)
+	if err != nil {
+		return nil, err
+	}
+	return m.encrypt(user.VersionQueuedMail, b)
+}
+
+// decodeQueuedMail decrypts and decodes the provided queued email blob.
+func (m *mysql) decodeQueuedMail(eb []byte) (*user.QueuedMail, error) {
+	b, _, err := m.decrypt(eb)
+	if err != nil {
+		return nil, err
+	}
+	var qm user.QueuedMail
+	err = json.Unmarshal(b, &qm)
+	if err != nil {
+		return nil, err
+	}
+	return &qm, nil
+}
+
+// MailQueueNew inserts a new email into the mail queue. An ErrMailExists
+// error is returned if an email with the same ID has already been queued.
+//
+// MailQueueNew satisfies the user MailerDB interface.
+func (m *mysql) MailQueueNew(qm user.QueuedMail) error {
+	log.Tracef("MailQueueNew: %v", qm.ID)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	eb, err := m.encodeQueuedMail(qm)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	// The no-op update on a duplicate key results in zero affected rows,
+	// which means that the email has already been queued.
+	r, err := m.userDB.ExecContext(ctx,
+		`INSERT INTO mail_queue
+    (id, status, created_at, next_attempt_at, sent_at, m_blob)
+    VALUES (?, ?, ?, ?, ?, ?)
+    ON DUPLICATE KEY UPDATE id = id`,
+		qm.ID, qm.Status, qm.CreatedAt, qm.NextAttemptAt, qm.SentAt, eb)
+	if err != nil {
+		return fmt.Errorf("insert: %v", err)
+	}
+	rows, err := r.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %v", err)
+	}
+	if rows == 0 {
+		return user.ErrMailExists
+	}
+
+	return nil
+}
+
+// MailQueueUpdate updates an existing email in the mail queue. An
+// ErrMailNotFound error is returned if the email does not exist.
+//
+// MailQueueUpdate satisfies the user MailerDB interface.
+func (m *mysql) MailQueueUpdate(qm user.QueuedMail) error {
+	log.Tracef("MailQueueUpdate: %v", qm.ID)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	eb, err := m.encodeQueuedMail(qm)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	r, err := m.userDB.ExecContext(ctx,
+		`UPDATE mail_queue
+    SET status = ?, next_attempt_at = ?, sent_at = ?, m_blob = ?
+    WHERE id = ?`,
+		qm.Status, qm.NextAttemptAt, qm.SentAt, eb, qm.ID)
+	if err != nil {
+		return fmt.Errorf("update: %v", err)
+	}
+	rows, err := r.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %v", err)
+	}
+	if rows == 0 {
+		return user.ErrMailNotFound
+	}
+
+	return nil
+}
+
+// mailQueueQuery runs the provided query against the mail queue and returns
+// the decoded emails. The query must select the m_blob column.
+func (m *mysql) mailQueueQuery(q string, args ...interface{}) ([]user.QueuedMail, error) {
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	rows, err := m.userDB.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mail := make([]user.QueuedMail, 0, 64)
+	for rows.Next() {
+		var eb []byte
+		if err := rows.Scan(&eb); err != nil {
+			return nil, err
+		}
+		qm, err := m.decodeQueuedMail(eb)
+		if err != nil {
+			return nil, err
+		}
+		mail = append(mail, *qm)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mail, nil
+}
+
+// MailQueueDue returns up to limit queued emails whose next attempt time is
+// at or before the provided UNIX timestamp, ordered by next attempt time.
+//
+// MailQueueDue satisfies the user MailerDB interface.
+func (m *mysql) MailQueueDue(now int64, limit int) ([]user.QueuedMail, error) {
+	log.Tracef("MailQueueDue: %v %v", now, limit)
+
+	if m.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	return m.mailQueueQuery(`SELECT m_blob FROM mail_queue
+    WHERE status = ? AND next_attempt_at <= ?
+    ORDER BY next_attempt_at ASC LIMIT ?`,
+		user.MailStatusQueued, now, limit)
+}
+
+// MailQueueGetByStatus returns up to limit emails with the provided status,
+// ordered by the time that they were created.
+//
+// MailQueueGetByStatus satisfies the user MailerDB interface.
+func (m *mysql) MailQueueGetByStatus(s user.MailStatusT, limit int) ([]user.QueuedMail, error) {
+	log.Tracef("MailQueueGetByStatus: %v %v", s, limit)
+
+	if m.isShutdown() {
+		return nil, user.ErrShutdown
+	}
+
+	return m.mailQueueQuery(`SELECT m_blob FROM mail_queue
+    WHERE status = ? ORDER BY created_at ASC LIMIT ?`, s, limit)
+}
+
+// MailQueuePrune deletes the sent emails that were sent before the provided
+// UNIX timestamp.
+//
+// MailQueuePrune satisfies the user MailerDB interface.
+func (m *mysql) MailQueuePrune(sentBefore int64) error {
+	log.Tracef("MailQueuePrune: %v", sentBefore)
+
+	if m.isShutdown() {
+		return user.ErrShutdown
+	}
+
+	ctx, cancel := ctxWithTimeout()
+	defer cancel()
+
+	_, err := m.userDB.ExecContext(ctx,
+		`DELETE FROM mail_queue WHERE status = ? AND sent_at < ?`,
+		user.MailStatusSent, sentBefore)
+	if err != nil {
+		return fmt.Errorf("delete: %v", err)
+	}
+
+	return nil
+}
+
 // Close shuts down the database.  All interface functions must return with
 // errShutdown if the backend is shutting down.
 //
@@ -1403,6 +1783,24 @@ func New(host, password, network, encryptionKey string) (*mysql, error) {
 			tableNameEmailHistories, err)
 	}
 
+	// Setup email_digests table.
+	q = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (%v)`,
+		tableNameEmailDigests, tableEmailDigests)
+	_, err = db.Exec(q)
+	if err != nil {
+		return nil, fmt.Errorf("create %v table: %v",
+			tableNameEmailDigests, err)
+	}
+
+	// Setup mail_queue table.
+	q = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (%v)`,
+		tableNameMailQueue, tableMailQueue)
+	_, err = db.Exec(q)
+	if err != nil {
+		return nil, fmt.Errorf("create %v table: %v",
+			tableNameMailQueue, err)
+	}
+
 	// Load encryption key.
 	key, err := util.LoadEncryptionKey(log, encryptionKey)
 	if err != nil {
@@ -710,6 +710,239 @@ func TestEmailHistoriesGet(t *testing.T) {
 	}
 }
 
+func TestEmailDigestsSave(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	// Arguments
+	userID := uuid.New()
+	digests := map[uuid.UUID]user.EmailDigest{
+		userID: {
+			LastSent: time.Now().Unix(),
+			Entries: []user.DigestEntry{
+				{
+					Event:     "proposal-new",
+					Token:     "token",
+					Timestamp: time.Now().Unix(),
+				},
+			},
+		},
+	}
+
+	// Queries
+	sqlSelect := `SELECT user_id FROM email_digests WHERE user_id = ?`
+	sqlInsert := `INSERT INTO email_digests (user_id, d_blob) VALUES (?, ?)`
+	sqlUpdate := `UPDATE email_digests SET d_blob = ? WHERE user_id = ?`
+
+	// Success create expectations
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).
+		WithArgs(userID).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec(regexp.QuoteMeta(sqlInsert)).
+		WithArgs(userID, AnyBlob{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Execute method
+	err := mdb.EmailDigestsSave(digests)
+	if err != nil {
+		t.Errorf("EmailDigestsSave unwanted error: %s", err)
+	}
+
+	// Success update expectations
+	rows := sqlmock.NewRows([]string{"user_id"}).AddRow(userID.String())
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelect)).
+		WithArgs(userID).
+		WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta(sqlUpdate)).
+		WithArgs(AnyBlob{}, userID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// Execute method
+	err = mdb.EmailDigestsSave(digests)
+	if err != nil {
+		t.Errorf("EmailDigestsSave unwanted error: %s", err)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestEmailDigestsGet(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	// Arguments
+	userID := uuid.New()
+	digest := user.EmailDigest{
+		LastSent: time.Now().Unix(),
+		Entries: []user.DigestEntry{
+			{
+				Event:     "comment-new",
+				Token:     "token",
+				CommentID: 1,
+			},
+		},
+	}
+	db, err := json.Marshal(digest)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	eb, err := mdb.encrypt(user.VersionEmailDigest, db)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	// Mock data
+	rows := sqlmock.NewRows([]string{"user_id", "d_blob"}).
+		AddRow(userID, eb)
+
+	// Query
+	sql := `SELECT user_id, d_blob FROM email_digests WHERE user_id IN (?)`
+
+	// Success expectations
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).
+		WithArgs(userID).
+		WillReturnRows(rows)
+
+	// Execute method
+	d, err := mdb.EmailDigestsGet([]uuid.UUID{userID})
+	if err != nil {
+		t.Errorf("EmailDigestsGet unwanted error: %s", err)
+	}
+
+	// Make sure correct digest was returned
+	if len(d[userID].Entries) != 1 ||
+		d[userID].Entries[0] != digest.Entries[0] {
+		t.Errorf("got digest %+v, want %+v", d[userID], digest)
+	}
+
+	// An empty list of users does not hit the database
+	d, err = mdb.EmailDigestsGet([]uuid.UUID{})
+	if err != nil {
+		t.Errorf("EmailDigestsGet unwanted error: %s", err)
+	}
+	if len(d) != 0 {
+		t.Errorf("expected no email digests but got %v", d)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMailQueueNew(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	// Arguments
+	m := user.QueuedMail{
+		ID:            "proposal-new:token",
+		Subject:       "subject",
+		Body:          "body",
+		Recipients:    []string{"user@example.com"},
+		Status:        user.MailStatusQueued,
+		CreatedAt:     time.Now().Unix(),
+		NextAttemptAt: time.Now().Unix(),
+	}
+
+	// Query
+	sqlInsert := `INSERT INTO mail_queue
+    (id, status, created_at, next_attempt_at, sent_at, m_blob)
+    VALUES (?, ?, ?, ?, ?, ?)
+    ON DUPLICATE KEY UPDATE id = id`
+
+	// Success expectations
+	mock.ExpectExec(regexp.QuoteMeta(sqlInsert)).
+		WithArgs(m.ID, m.Status, m.CreatedAt, m.NextAttemptAt, m.SentAt,
+			AnyBlob{}).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Execute method
+	err := mdb.MailQueueNew(m)
+	if err != nil {
+		t.Errorf("MailQueueNew unwanted error: %s", err)
+	}
+
+	// Duplicate ID expectations
+	mock.ExpectExec(regexp.QuoteMeta(sqlInsert)).
+		WithArgs(m.ID, m.Status, m.CreatedAt, m.NextAttemptAt, m.SentAt,
+			AnyBlob{}).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute method
+	err = mdb.MailQueueNew(m)
+	if !errors.Is(err, user.ErrMailExists) {
+		t.Errorf("expecting error %s but got %s", user.ErrMailExists, err)
+	}
+
+	// Make sure expectations were met for both success and failure
+	// conditions
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMailQueueDue(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	// Arguments
+	now := time.Now().Unix()
+	m := user.QueuedMail{
+		ID:            "proposal-new:token",
+		Subject:       "subject",
+		Body:          "body",
+		Recipients:    []string{"user@example.com"},
+		Status:        user.MailStatusQueued,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}
+	eb, err := mdb.encodeQueuedMail(m)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+
+	// Mock data
+	rows := sqlmock.NewRows([]string{"m_blob"}).AddRow(eb)
+
+	// Query
+	sql := `SELECT m_blob FROM mail_queue
+    WHERE status = ? AND next_attempt_at <= ?
+    ORDER BY next_attempt_at ASC LIMIT ?`
+
+	// Success expectations
+	mock.ExpectQuery(regexp.QuoteMeta(sql)).
+		WithArgs(user.MailStatusQueued, now, 10).
+		WillReturnRows(rows)
+
+	// Execute method
+	due, err := mdb.MailQueueDue(now, 10)
+	if err != nil {
+		t.Errorf("MailQueueDue unwanted error: %s", err)
+	}
+
+	// Make sure the email was decrypted
+	if len(due) != 1 || due[0].Body != m.Body {
+		t.Errorf("expecting email %v but got %v", m, due)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
 func TestSessionSave(t *testing.T) {
 	mdb, mock, close := setupTestDB(t)
 	defer close()
@@ -137,6 +137,32 @@ type ProposalCredit struct {
 	CensorshipToken string `json:"censorshiptoken"` // Token of proposal that spent this credit
 }
 
+// WebAuthnCredential is a WebAuthn public key credential that has been
+// registered by the user as a second factor.
+type WebAuthnCredential struct {
+	ID         []byte `json:"id"`         // Credential ID
+	Name       string `json:"name"`       // User chosen name
+	PublicKey  []byte `json:"publickey"`  // COSE encoded public key
+	SignCount  uint32 `json:"signcount"`  // Last seen signature counter
+	CreatedAt  int64  `json:"createdat"`  // Unix timestamp of registration
+	LastUsedAt int64  `json:"lastusedat"` // Unix timestamp of last login
+}
+
+// WebAuthnChallenge is a pending WebAuthn ceremony challenge. A challenge can
+// only be used once.
+type WebAuthnChallenge struct {
+	Challenge []byte `json:"challenge"`
+	Type      string `json:"type"`   // Ceremony type, register, login, or confirm
+	Expiry    int64  `json:"expiry"` // Unix timestamp of expiration
+}
+
+// WebAuthn challenge types.
+const (
+	WebAuthnChallengeRegister = "register"
+	WebAuthnChallengeLogin    = "login"
+	WebAuthnChallengeConfirm  = "confirm"
+)
+
 // VersionUser is the version of the User struct.
 const VersionUser uint32 = 1
 
@@ -148,9 +174,11 @@ type User struct {
 	HashedPassword      []byte    `json:"hashedpassword"`      // Blowfish hash
 	Admin               bool      `json:"admin"`               // Is user an admin
 	EmailNotifications  uint64    `json:"emailnotifications"`  // Email notification setting
+	EmailDigest         uint32    `json:"emaildigest"`         // Email digest frequency
 	LastLoginTime       int64     `json:"lastlogintime"`       // Unix timestamp of last login
 	FailedLoginAttempts uint64    `json:"failedloginattempts"` // Sequential failed login attempts
 	Deactivated         bool      `json:"deactivated"`         // Is account deactivated
+	Language            string    `json:"language,omitempty"`  // Notification email language
 
 	// Verification tokens and their expirations
 	NewUserVerificationToken        []byte `json:"newuserverificationtoken"`
@@ -234,6 +262,14 @@ type User struct {
 	TOTPVerified           bool    `json:"totpverified"` // whether current totp secret has been verified with
 	TOTPLastUpdated        []int64 `json:"totplastupdated"`
 	TOTPLastFailedCodeTime []int64 `json:"totplastfailedcodetime"`
+
+	// WebAuthn credentials that have been registered by the user. A
+	// WebAuthn assertion or a backup code is required during login
+	// once a credential has been registered. The backup codes are
+	// saved as SHA256 digests and are removed once used.
+	WebAuthnCredentials []WebAuthnCredential `json:"webauthncredentials,omitempty"`
+	WebAuthnBackupCodes []string             `json:"webauthnbackupcodes,omitempty"`
+	WebAuthnChallenge   *WebAuthnChallenge   `json:"webauthnchallenge,omitempty"`
 }
 
 // ActiveIdentity returns the active identity for the user if one exists.
@@ -15,8 +15,10 @@ import (
 	"github.com/decred/politeia/politeiad/api/v1/identity"
 	www "github.com/decred/politeia/politeiawww/api/www/v1"
 	"github.com/decred/politeia/politeiawww/legacy/user"
+	"github.com/decred/politeia/politeiawww/mail"
 	"github.com/decred/politeia/util"
 	"github.com/go-test/deep"
+	"github.com/google/uuid"
 	"github.com/pquerna/otp/totp"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -1979,6 +1981,137 @@ func TestProcessEditUser(t *testing.T) {
 	}
 }
 
+func TestProcessEditUserLanguage(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	user, _ := newUser(t, p, true, false)
+
+	// The test politeiawww only uses the built-in email templates
+	var (
+		supported   = mail.DefaultLanguage
+		unsupported = "xx"
+		reset       = ""
+	)
+
+	// Setup test cases
+	tests := []struct {
+		name     string
+		language string
+		want     error
+	}{
+		{
+			"unsupported language",
+			unsupported,
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidLanguage,
+			},
+		},
+		{
+			"supported language",
+			supported,
+			nil,
+		},
+		{
+			"reset language",
+			reset,
+			nil,
+		},
+	}
+
+	// Run test cases
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := p.processEditUser(&www.EditUser{
+				Language: &test.language,
+			}, user)
+			got := errToStr(err)
+			want := errToStr(test.want)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+
+			// Ensure the database and the user languages
+			// cache were updated.
+			u, err := p.db.UserGetById(user.ID)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			if u.Language != test.language {
+				t.Errorf("got language %q, want %q",
+					u.Language, test.language)
+			}
+			langs := p.userLanguagesByID([]uuid.UUID{user.ID})
+			if langs[user.ID] != test.language {
+				t.Errorf("got cached language %q, want %q",
+					langs[user.ID], test.language)
+			}
+		})
+	}
+}
+
+func TestProcessEditUserEmailDigest(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	user, _ := newUser(t, p, true, false)
+
+	// Setup test cases
+	tests := []struct {
+		name   string
+		digest www.EmailDigestT
+		want   error
+	}{
+		{
+			"invalid digest",
+			www.EmailDigestT(99),
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidEmailDigest,
+			},
+		},
+		{
+			"weekly digest",
+			www.EmailDigestWeekly,
+			nil,
+		},
+		{
+			"no digest",
+			www.EmailDigestNone,
+			nil,
+		},
+	}
+
+	// Run test cases
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := p.processEditUser(&www.EditUser{
+				EmailDigest: &test.digest,
+			}, user)
+			got := errToStr(err)
+			want := errToStr(test.want)
+			if got != want {
+				t.Fatalf("got error %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+
+			// Ensure the database was updated
+			u, err := p.db.UserGetById(user.ID)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			if www.EmailDigestT(u.EmailDigest) != test.digest {
+				t.Errorf("got digest %v, want %v",
+					u.EmailDigest, test.digest)
+			}
+		})
+	}
+}
+
 func TestProcessManageUser(t *testing.T) {
 	p, cleanup := newTestPoliteiawww(t)
 	defer cleanup()
@@ -5,11 +5,10 @@
 package legacy
 
 import (
-	"bytes"
 	"net/url"
-	"text/template"
 
 	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/mail"
 	"github.com/google/uuid"
 )
 
@@ -19,6 +18,17 @@ const (
 	guiRouteRegisterNewUser = "/register"
 )
 
+// The following are the names of the user notification email templates. The
+// built-in templates are defined in this file and can be overridden using the
+// mail templates directory.
+const (
+	mailTmplUserEmailVerify     = "user-email-verify"
+	mailTmplUserKeyUpdate       = "user-key-update"
+	mailTmplUserPasswordReset   = "user-password-reset"
+	mailTmplUserAccountLocked   = "user-account-locked"
+	mailTmplUserPasswordChanged = "user-password-changed"
+)
+
 // emailUserEmailVerify sends a new user verification email to the provided
 // email address. This function is not rate limited by the smtp client because
 // the user is only created/updated when this function is successfully executed
@@ -36,13 +46,8 @@ func (p *Politeiawww) emailUserEmailVerify(email, token, username string) error
 		Link:     link,
 	}
 
-	subject := "Verify Your Email"
-	body, err := createBody(userEmailVerifyTmpl, tplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendTo(subject, body, []string{email})
+	return p.mail.SendTo("", mailTmplUserEmailVerify, tplData,
+		[]string{email})
 }
 
 // emailUserKeyUpdate emails the link with the verification token used for
@@ -59,13 +64,7 @@ func (p *Politeiawww) emailUserKeyUpdate(username, publicKey, token string, reci
 		Link:      link,
 	}
 
-	subject := "Verify Your New Identity"
-	body, err := createBody(userKeyUpdateTmpl, tplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendToUsers(subject, body, recipient)
+	return p.mail.SendToUsers("", mailTmplUserKeyUpdate, tplData, recipient)
 }
 
 // emailUserPasswordReset emails the link with the reset password verification
@@ -82,17 +81,12 @@ func (p *Politeiawww) emailUserPasswordReset(username, token string, recipient m
 	u.RawQuery = q.Encode()
 
 	// Setup email
-	subject := "Reset Your Password"
 	tplData := userPasswordReset{
 		Link: u.String(),
 	}
-	body, err := createBody(userPasswordResetTmpl, tplData)
-	if err != nil {
-		return err
-	}
 
 	// Send email
-	return p.mail.SendToUsers(subject, body, recipient)
+	return p.mail.SendToUsers("", mailTmplUserPasswordReset, tplData, recipient)
 }
 
 // emailUserAccountLocked notifies the user its account has been locked and
@@ -114,13 +108,7 @@ func (p *Politeiawww) emailUserAccountLocked(username string, recipient map[uuid
 		Username: username,
 	}
 
-	subject := "Locked Account - Reset Your Password"
-	body, err := createBody(userAccountLockedTmpl, tplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendToUsers(subject, body, recipient)
+	return p.mail.SendToUsers("", mailTmplUserAccountLocked, tplData, recipient)
 }
 
 // emailUserPasswordChanged notifies the user that his password was changed,
@@ -130,13 +118,7 @@ func (p *Politeiawww) emailUserPasswordChanged(username string, recipient map[uu
 		Username: username,
 	}
 
-	subject := "Password Changed - Security Notification"
-	body, err := createBody(userPasswordChangedTmpl, tplData)
-	if err != nil {
-		return err
-	}
-
-	return p.mail.SendToUsers(subject, body, recipient)
+	return p.mail.SendToUsers("", mailTmplUserPasswordChanged, tplData, recipient)
 }
 
 func (p *Politeiawww) createEmailLink(path, email, token, username string) (string, error) {
@@ -160,16 +142,6 @@ func (p *Politeiawww) createEmailLink(path, email, token, username string) (stri
 	return l.String(), nil
 }
 
-func createBody(tpl *template.Template, tplData interface{}) (string, error) {
-	var buf bytes.Buffer
-	err := tpl.Execute(&buf, tplData)
-	if err != nil {
-		return "", err
-	}
-
-	return buf.String(), nil
-}
-
 // User email verify - Send verification link to new user
 type userEmailVerify struct {
 	Username string // User username
@@ -188,9 +160,6 @@ register a Politeia account.  If you did not perform this action, please ignore
 this email.
 `
 
-var userEmailVerifyTmpl = template.Must(
-	template.New("userEmailVerify").Parse(userEmailVerifyText))
-
 // User key update - Send key verification link to user
 type userKeyUpdate struct {
 	PublicKey string // User new public key
@@ -214,9 +183,6 @@ the Politeia channel on Matrix.
 https://chat.decred.org/#/room/#politeia:decred.org
 `
 
-var userKeyUpdateTmpl = template.Must(
-	template.New("userKeyUpdate").Parse(userKeyUpdateText))
-
 // User password reset - Send password reset link to user
 type userPasswordReset struct {
 	Link string // Password reset link
@@ -234,9 +200,6 @@ Please contact a Politeia administrator in the Politeia channel on Matrix.
 https://chat.decred.org/#/room/#politeia:decred.org
 `
 
-var userPasswordResetTmpl = template.Must(
-	template.New("userPasswordReset").Parse(userPasswordResetText))
-
 // User account locked - Send reset password link to user
 type userAccountLocked struct {
 	Link     string // Reset password link
@@ -255,9 +218,6 @@ administrators in the Politeia channel on Matrix.
 https://chat.decred.org/#/room/#politeia:decred.org
 `
 
-var userAccountLockedTmpl = template.Must(
-	template.New("userAccountLocked").Parse(userAccountLockedText))
-
 // User password changed - Send to user
 type userPasswordChanged struct {
 	Username string
@@ -274,5 +234,26 @@ on Matrix.
 https://chat.decred.org/#/room/#politeia:decred.org
 `
 
-var userPasswordChangedTmpl = template.Must(
-	template.New("userPasswordChanged").Parse(userPasswordChangedText))
+// init registers the built-in user notification email templates.
+func init() {
+	mail.RegisterTemplate(mailTmplUserEmailVerify, mail.Template{
+		Subject: "Verify Your Email",
+		Text:    userEmailVerifyText,
+	})
+	mail.RegisterTemplate(mailTmplUserKeyUpdate, mail.Template{
+		Subject: "Verify Your New Identity",
+		Text:    userKeyUpdateText,
+	})
+	mail.RegisterTemplate(mailTmplUserPasswordReset, mail.Template{
+		Subject: "Reset Your Password",
+		Text:    userPasswordResetText,
+	})
+	mail.RegisterTemplate(mailTmplUserAccountLocked, mail.Template{
+		Subject: "Locked Account - Reset Your Password",
+		Text:    userAccountLockedText,
+	})
+	mail.RegisterTemplate(mailTmplUserPasswordChanged, mail.Template{
+		Subject: "Password Changed - Security Notification",
+		Text:    userPasswordChangedText,
+	})
+}
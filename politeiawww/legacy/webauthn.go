@@ -0,0 +1,528 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package legacy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/config"
+	"github.com/decred/politeia/politeiawww/legacy/user"
+	"github.com/decred/politeia/politeiawww/legacy/webauthn"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// webAuthnTimeout is the amount of time that a user has to complete
+	// a WebAuthn ceremony.
+	webAuthnTimeout = 5 * time.Minute
+
+	// webAuthnCredentialsMax is the maximum number of WebAuthn
+	// credentials that a user can register.
+	webAuthnCredentialsMax = 10
+
+	// webAuthnNameMaxLength is the maximum length of a WebAuthn
+	// credential name.
+	webAuthnNameMaxLength = 64
+
+	// webAuthnBackupCodesCount is the number of backup codes that are
+	// generated for a user.
+	webAuthnBackupCodesCount = 10
+
+	// webAuthnBackupCodeSize is the number of random bytes that a backup
+	// code contains.
+	webAuthnBackupCodeSize = 8
+)
+
+// webAuthnRelyingParty returns the WebAuthn relying party settings.
+func (p *Politeiawww) webAuthnRelyingParty() webauthn.RelyingParty {
+	return webauthn.RelyingParty{
+		ID:     p.cfg.WebAuthnRPID,
+		Origin: p.cfg.WebAuthnOrigin,
+	}
+}
+
+// webAuthnEnabled returns whether the WebAuthn relying party settings have
+// been configured.
+func (p *Politeiawww) webAuthnEnabled() bool {
+	return p.cfg.WebAuthnRPID != "" && p.cfg.WebAuthnOrigin != ""
+}
+
+// processSetWebAuthn begins the registration of a new WebAuthn credential.
+func (p *Politeiawww) processSetWebAuthn(u *user.User) (*www.SetWebAuthnReply, error) {
+	log.Tracef("processSetWebAuthn: %v", u.ID.String())
+
+	if !p.webAuthnEnabled() {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"webauthn is not enabled"},
+		}
+	}
+	if len(u.WebAuthnCredentials) >= webAuthnCredentialsMax {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"max webauthn credentials registered"},
+		}
+	}
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+	u.WebAuthnChallenge = &user.WebAuthnChallenge{
+		Challenge: challenge,
+		Type:      user.WebAuthnChallengeRegister,
+		Expiry:    time.Now().Add(webAuthnTimeout).Unix(),
+	}
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return nil, err
+	}
+
+	rpName := defaultPoliteiaIssuer
+	if p.cfg.Mode == config.CMSWWWMode {
+		rpName = defaultCMSIssuer
+	}
+	exclude := make([]string, 0, len(u.WebAuthnCredentials))
+	for _, v := range u.WebAuthnCredentials {
+		exclude = append(exclude, base64.RawURLEncoding.EncodeToString(v.ID))
+	}
+
+	return &www.SetWebAuthnReply{
+		Challenge:          base64.RawURLEncoding.EncodeToString(challenge),
+		RPID:               p.cfg.WebAuthnRPID,
+		RPName:             rpName,
+		UserID:             base64.RawURLEncoding.EncodeToString(u.ID[:]),
+		Username:           u.Username,
+		Algorithms:         webauthn.SupportedAlgorithms,
+		ExcludeCredentials: exclude,
+		Timeout:            webAuthnTimeout.Milliseconds(),
+	}, nil
+}
+
+// processVerifyWebAuthn completes the registration of a new WebAuthn
+// credential. Backup codes are generated and returned when the first
+// credential is registered.
+func (p *Politeiawww) processVerifyWebAuthn(vw www.VerifyWebAuthn, u *user.User) (*www.VerifyWebAuthnReply, error) {
+	log.Tracef("processVerifyWebAuthn: %v", u.ID.String())
+
+	// Validate the input
+	name := strings.TrimSpace(vw.Name)
+	if name == "" || utf8.RuneCountInString(name) > webAuthnNameMaxLength {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid credential name"},
+		}
+	}
+	clientDataJSON, err := base64.RawURLEncoding.DecodeString(vw.ClientDataJSON)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid client data json"},
+		}
+	}
+	attestationObject, err := base64.RawURLEncoding.
+		DecodeString(vw.AttestationObject)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode:    www.ErrorStatusInvalidInput,
+			ErrorContext: []string{"invalid attestation object"},
+		}
+	}
+
+	// Verify the registration. The challenge is consumed whether
+	// or not the verification succeeds.
+	challenge := takeWebAuthnChallenge(u, user.WebAuthnChallengeRegister)
+	c, err := p.webAuthnRelyingParty().VerifyRegistration(challenge,
+		clientDataJSON, attestationObject)
+	if err != nil {
+		var ve webauthn.VerificationError
+		if !errors.As(err, &ve) {
+			return nil, err
+		}
+		log.Debugf("processVerifyWebAuthn: %v %v", u.Username, err)
+		err = p.db.UserUpdate(*u)
+		if err != nil {
+			return nil, err
+		}
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWebAuthnFailedValidation,
+		}
+	}
+	for _, v := range u.WebAuthnCredentials {
+		if bytes.Equal(v.ID, c.ID) {
+			err = p.db.UserUpdate(*u)
+			if err != nil {
+				return nil, err
+			}
+			return nil, www.UserError{
+				ErrorCode:    www.ErrorStatusWebAuthnFailedValidation,
+				ErrorContext: []string{"credential already registered"},
+			}
+		}
+	}
+
+	// Save the credential. Backup codes are created along with
+	// the first credential.
+	var reply www.VerifyWebAuthnReply
+	if len(u.WebAuthnCredentials) == 0 {
+		codes, digests, err := newWebAuthnBackupCodes()
+		if err != nil {
+			return nil, err
+		}
+		u.WebAuthnBackupCodes = digests
+		reply.BackupCodes = codes
+	}
+	u.WebAuthnCredentials = append(u.WebAuthnCredentials,
+		user.WebAuthnCredential{
+			ID:        c.ID,
+			Name:      name,
+			PublicKey: c.PublicKey,
+			SignCount: c.SignCount,
+			CreatedAt: time.Now().Unix(),
+		})
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("WebAuthn credential registered: %v %v", u.Username, name)
+
+	reply.CredentialID = base64.RawURLEncoding.EncodeToString(c.ID)
+	return &reply, nil
+}
+
+// processWebAuthnCredentials returns the WebAuthn credentials of a user.
+func (p *Politeiawww) processWebAuthnCredentials(u *user.User) (*www.WebAuthnCredentialsReply, error) {
+	log.Tracef("processWebAuthnCredentials: %v", u.ID.String())
+
+	creds := make([]www.WebAuthnCredential, 0, len(u.WebAuthnCredentials))
+	for _, v := range u.WebAuthnCredentials {
+		creds = append(creds, www.WebAuthnCredential{
+			ID:         base64.RawURLEncoding.EncodeToString(v.ID),
+			Name:       v.Name,
+			CreatedAt:  v.CreatedAt,
+			LastUsedAt: v.LastUsedAt,
+		})
+	}
+
+	return &www.WebAuthnCredentialsReply{
+		Credentials: creds,
+		BackupCodes: len(u.WebAuthnBackupCodes),
+	}, nil
+}
+
+// processRemoveWebAuthn removes a WebAuthn credential from a user. The backup
+// codes are removed along with the last credential. The user must confirm the
+// request using a WebAuthn assertion or an unused backup code.
+func (p *Politeiawww) processRemoveWebAuthn(rw www.RemoveWebAuthn, u *user.User) (*www.RemoveWebAuthnReply, error) {
+	log.Tracef("processRemoveWebAuthn: %v %v", u.ID.String(), rw.CredentialID)
+
+	id, err := base64.RawURLEncoding.DecodeString(rw.CredentialID)
+	if err != nil {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWebAuthnCredentialNotFound,
+		}
+	}
+	creds := make([]user.WebAuthnCredential, 0, len(u.WebAuthnCredentials))
+	for _, v := range u.WebAuthnCredentials {
+		if !bytes.Equal(v.ID, id) {
+			creds = append(creds, v)
+		}
+	}
+	if len(creds) == len(u.WebAuthnCredentials) {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWebAuthnCredentialNotFound,
+		}
+	}
+
+	// Verify the second factor
+	err = p.webAuthnConfirm(rw.WebAuthn, rw.BackupCode, u)
+	if err != nil {
+		return nil, err
+	}
+
+	u.WebAuthnCredentials = creds
+	if len(creds) == 0 {
+		u.WebAuthnBackupCodes = nil
+		u.WebAuthnChallenge = nil
+	}
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("WebAuthn credential removed: %v %v",
+		u.Username, rw.CredentialID)
+
+	return &www.RemoveWebAuthnReply{}, nil
+}
+
+// processWebAuthnBackupCodes replaces the backup codes of a user. The user
+// must confirm the request using a WebAuthn assertion or an unused backup
+// code.
+func (p *Politeiawww) processWebAuthnBackupCodes(bc www.WebAuthnBackupCodes, u *user.User) (*www.WebAuthnBackupCodesReply, error) {
+	log.Tracef("processWebAuthnBackupCodes: %v", u.ID.String())
+
+	if len(u.WebAuthnCredentials) == 0 {
+		return nil, www.UserError{
+			ErrorCode: www.ErrorStatusWebAuthnCredentialNotFound,
+		}
+	}
+
+	// Verify the second factor
+	err := p.webAuthnConfirm(bc.WebAuthn, bc.BackupCode, u)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, digests, err := newWebAuthnBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	u.WebAuthnBackupCodes = digests
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("WebAuthn backup codes replaced: %v", u.Username)
+
+	return &www.WebAuthnBackupCodesReply{
+		BackupCodes: codes,
+	}, nil
+}
+
+// webAuthnCheck verifies the WebAuthn second factor of a login. The login
+// must contain either a WebAuthn assertion or an unused backup code. A new
+// login challenge is created and returned in the error context of an
+// ErrorStatusRequiresWebAuthn error when neither have been provided.
+//
+// The user is updated in the database. A failed attempt counts towards the
+// failed login attempts of the user.
+func (p *Politeiawww) webAuthnCheck(l www.Login, u *user.User) error {
+	ok, err := p.webAuthnVerify(l.WebAuthn, l.BackupCode,
+		user.WebAuthnChallengeLogin, u)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		err := p.loginFailed(u)
+		if err != nil {
+			return err
+		}
+		return www.UserError{
+			ErrorCode: www.ErrorStatusWebAuthnFailedValidation,
+		}
+	}
+
+	return nil
+}
+
+// webAuthnConfirm verifies the WebAuthn second factor of a request that
+// changes the WebAuthn settings of a user. This prevents a stolen session
+// from being used to remove the second factor of the account. The request
+// must contain either a WebAuthn assertion or an unused backup code. A new
+// challenge is created and returned in the error context of an
+// ErrorStatusRequiresWebAuthn error when neither have been provided.
+//
+// The user is saved to the database when the verification fails. The caller
+// must save the user when the verification succeeds.
+func (p *Politeiawww) webAuthnConfirm(a *www.WebAuthnAssertion, backupCode string, u *user.User) error {
+	ok, err := p.webAuthnVerify(a, backupCode,
+		user.WebAuthnChallengeConfirm, u)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// Save the consumed challenge
+		err := p.db.UserUpdate(*u)
+		if err != nil {
+			return err
+		}
+		return www.UserError{
+			ErrorCode: www.ErrorStatusWebAuthnFailedValidation,
+		}
+	}
+
+	return nil
+}
+
+// webAuthnVerify verifies either the provided WebAuthn assertion against a
+// pending challenge of the provided type or the provided backup code, and
+// returns whether the verification succeeded. A used backup code is removed
+// from the user. The user is not saved to the database.
+//
+// When neither an assertion nor a backup code has been provided, a new
+// challenge of the provided type is created and saved to the database and an
+// ErrorStatusRequiresWebAuthn error that contains the challenge is returned.
+func (p *Politeiawww) webAuthnVerify(a *www.WebAuthnAssertion, backupCode, challengeType string, u *user.User) (bool, error) {
+	switch {
+	case a != nil:
+		return p.webAuthnVerifyAssertion(*a, challengeType, u)
+
+	case backupCode != "":
+		ok := useWebAuthnBackupCode(u, backupCode)
+		if ok {
+			log.Infof("WebAuthn backup code used: %v, %v remaining",
+				u.Username, len(u.WebAuthnBackupCodes))
+		}
+		return ok, nil
+	}
+
+	// Create a challenge
+	log.Debugf("webauthn: %v assertion required %v", challengeType, u.Email)
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return false, err
+	}
+	u.WebAuthnChallenge = &user.WebAuthnChallenge{
+		Challenge: challenge,
+		Type:      challengeType,
+		Expiry:    time.Now().Add(webAuthnTimeout).Unix(),
+	}
+	err = p.db.UserUpdate(*u)
+	if err != nil {
+		return false, err
+	}
+	allow := make([]string, 0, len(u.WebAuthnCredentials))
+	for _, v := range u.WebAuthnCredentials {
+		allow = append(allow, base64.RawURLEncoding.EncodeToString(v.ID))
+	}
+	b, err := json.Marshal(www.WebAuthnLoginChallenge{
+		Challenge:        base64.RawURLEncoding.EncodeToString(challenge),
+		RPID:             p.cfg.WebAuthnRPID,
+		AllowCredentials: allow,
+		Timeout:          webAuthnTimeout.Milliseconds(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return false, www.UserError{
+		ErrorCode:    www.ErrorStatusRequiresWebAuthn,
+		ErrorContext: []string{string(b)},
+	}
+}
+
+// webAuthnVerifyAssertion verifies a WebAuthn assertion against the pending
+// challenge of the provided type and updates the signature counter of the
+// credential that was used. The challenge is consumed whether or not the
+// verification succeeds. The user is not saved to the database.
+func (p *Politeiawww) webAuthnVerifyAssertion(a www.WebAuthnAssertion, challengeType string, u *user.User) (bool, error) {
+	challenge := takeWebAuthnChallenge(u, challengeType)
+
+	// Decode the assertion
+	var (
+		fields = []string{a.CredentialID, a.ClientDataJSON,
+			a.AuthenticatorData, a.Signature}
+		decoded = make([][]byte, 0, len(fields))
+	)
+	for _, v := range fields {
+		b, err := base64.RawURLEncoding.DecodeString(v)
+		if err != nil {
+			log.Debugf("webauthn: invalid assertion encoding %v",
+				u.Email)
+			return false, nil
+		}
+		decoded = append(decoded, b)
+	}
+	id, clientDataJSON, authData, sig := decoded[0], decoded[1],
+		decoded[2], decoded[3]
+
+	// Find the credential
+	var cred *user.WebAuthnCredential
+	for k, v := range u.WebAuthnCredentials {
+		if bytes.Equal(v.ID, id) {
+			cred = &u.WebAuthnCredentials[k]
+			break
+		}
+	}
+	if cred == nil {
+		log.Debugf("webauthn: credential not found %v", u.Email)
+		return false, nil
+	}
+
+	// Verify the assertion
+	signCount, err := p.webAuthnRelyingParty().VerifyAssertion(
+		webauthn.Credential{
+			ID:        cred.ID,
+			PublicKey: cred.PublicKey,
+			SignCount: cred.SignCount,
+		}, challenge, clientDataJSON, authData, sig)
+	if err != nil {
+		var ve webauthn.VerificationError
+		if !errors.As(err, &ve) {
+			return false, err
+		}
+		log.Debugf("webauthn: %v %v", u.Email, err)
+		return false, nil
+	}
+
+	cred.SignCount = signCount
+	cred.LastUsedAt = time.Now().Unix()
+
+	return true, nil
+}
+
+// takeWebAuthnChallenge removes the pending WebAuthn challenge from the user
+// and returns it. A nil challenge is returned if the pending challenge is not
+// of the provided type or has expired. The user is not saved to the
+// database.
+func takeWebAuthnChallenge(u *user.User, challengeType string) []byte {
+	c := u.WebAuthnChallenge
+	u.WebAuthnChallenge = nil
+	if c == nil || c.Type != challengeType ||
+		time.Now().Unix() > c.Expiry {
+		return nil
+	}
+	return c.Challenge
+}
+
+// newWebAuthnBackupCodes returns a new set of backup codes along with the
+// digests of the codes that are saved to the user database.
+func newWebAuthnBackupCodes() ([]string, []string, error) {
+	codes := make([]string, 0, webAuthnBackupCodesCount)
+	digests := make([]string, 0, webAuthnBackupCodesCount)
+	for i := 0; i < webAuthnBackupCodesCount; i++ {
+		b, err := util.Random(webAuthnBackupCodeSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := hex.EncodeToString(b)
+		codes = append(codes, code)
+		digests = append(digests, webAuthnBackupCodeDigest(code))
+	}
+	return codes, digests, nil
+}
+
+// useWebAuthnBackupCode removes the provided backup code from the user and
+// returns whether it was found. The user is not saved to the database.
+func useWebAuthnBackupCode(u *user.User, code string) bool {
+	d := webAuthnBackupCodeDigest(code)
+	for k, v := range u.WebAuthnBackupCodes {
+		if subtle.ConstantTimeCompare([]byte(v), []byte(d)) == 1 {
+			u.WebAuthnBackupCodes = append(u.WebAuthnBackupCodes[:k],
+				u.WebAuthnBackupCodes[k+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// webAuthnBackupCodeDigest returns the digest of a backup code. Backup codes
+// are case insensitive.
+func webAuthnBackupCodeDigest(code string) string {
+	h := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(h[:])
+}
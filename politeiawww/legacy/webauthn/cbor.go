@@ -0,0 +1,204 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// CBOR major types.
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+// CBOR simple values.
+const (
+	cborFalse = 20
+	cborTrue  = 21
+	cborNull  = 22
+)
+
+// cborMaxDepth is the maximum nesting depth of CBOR arrays and maps that will
+// be decoded.
+const cborMaxDepth = 16
+
+// decodeCBOR decodes the CBOR data item at the start of b and returns the
+// decoded item along with the number of bytes that were consumed.
+//
+// Only the subset of CBOR that is used by WebAuthn is supported: integers,
+// byte strings, text strings, arrays, maps, and the simple values false, true,
+// and null. Integers are decoded into int64, arrays into []interface{}, and
+// maps into map[interface{}]interface{}. Map keys must be integers or text
+// strings. Indefinite length items, tags, and floats are not supported.
+func decodeCBOR(b []byte) (interface{}, int, error) {
+	d := cborDecoder{
+		b: b,
+	}
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v, d.off, nil
+}
+
+// cborDecoder decodes CBOR data items from a byte slice.
+type cborDecoder struct {
+	b   []byte
+	off int
+}
+
+// decode decodes the next data item.
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, errors.New("cbor: max depth exceeded")
+	}
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return int64(arg), nil
+
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(arg), nil
+
+	case cborBytes:
+		b, err := d.next(arg)
+		if err != nil {
+			return nil, err
+		}
+		c := make([]byte, len(b))
+		copy(c, b)
+		return c, nil
+
+	case cborText:
+		b, err := d.next(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+
+	case cborArray:
+		// Every item is at least one byte long
+		if arg > uint64(len(d.b)-d.off) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		a := make([]interface{}, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		return a, nil
+
+	case cborMap:
+		// Every key and value is at least one byte long
+		if arg > uint64(len(d.b)-d.off)/2 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		m := make(map[interface{}]interface{}, arg)
+		for i := uint64(0); i < arg; i++ {
+			k, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, fmt.Errorf("cbor: invalid map key type %T", k)
+			}
+			if _, ok := m[k]; ok {
+				return nil, fmt.Errorf("cbor: duplicate map key %v", k)
+			}
+			v, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+
+	case cborSimple:
+		if info >= 24 {
+			return nil, errors.New("cbor: floats are not supported")
+		}
+		switch arg {
+		case cborFalse:
+			return false, nil
+		case cborTrue:
+			return true, nil
+		case cborNull:
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cbor: unsupported simple value %v", arg)
+	}
+
+	return nil, fmt.Errorf("cbor: unsupported major type %v", major)
+}
+
+// head decodes the initial byte and argument of the next data item. It
+// returns the major type, the additional information bits, and the argument.
+func (d *cborDecoder) head() (byte, byte, uint64, error) {
+	if d.off >= len(d.b) {
+		return 0, 0, 0, io.ErrUnexpectedEOF
+	}
+	ib := d.b[d.off]
+	d.off++
+	major, info := ib>>5, ib&0x1f
+
+	var n uint64
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		n = 1
+	case info == 25:
+		n = 2
+	case info == 26:
+		n = 4
+	case info == 27:
+		n = 8
+	default:
+		return 0, 0, 0, errors.New("cbor: indefinite length items are " +
+			"not supported")
+	}
+	b, err := d.next(n)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var buf [8]byte
+	copy(buf[8-n:], b)
+	return major, info, binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// next returns the next n bytes.
+func (d *cborDecoder) next(n uint64) ([]byte, error) {
+	if n > uint64(len(d.b)-d.off) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := d.b[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// COSE key parameters. See RFC 8152.
+const (
+	coseKeyKty = 1
+	coseKeyAlg = 3
+
+	// EC2 and OKP key parameters
+	coseKeyCrv = -1
+	coseKeyX   = -2
+	coseKeyY   = -3
+
+	// RSA key parameters
+	coseKeyN = -1
+	coseKeyE = -2
+)
+
+// COSE key types.
+const (
+	coseKtyOKP = 1
+	coseKtyEC2 = 2
+	coseKtyRSA = 3
+)
+
+// COSE curves.
+const (
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+// COSE algorithms that are supported for credential public keys.
+const (
+	// COSEAlgES256 is ECDSA using the P-256 curve and SHA-256.
+	COSEAlgES256 = -7
+
+	// COSEAlgEdDSA is EdDSA using the Ed25519 curve.
+	COSEAlgEdDSA = -8
+
+	// COSEAlgRS256 is RSASSA-PKCS1-v1_5 using SHA-256.
+	COSEAlgRS256 = -257
+)
+
+// SupportedAlgorithms contains the COSE algorithms that are supported for
+// credential public keys in order of preference. These are the algorithms
+// that clients should request when creating a credential.
+var SupportedAlgorithms = []int64{
+	COSEAlgES256,
+	COSEAlgEdDSA,
+	COSEAlgRS256,
+}
+
+// coseKey is a decoded COSE public key.
+type coseKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+// parseCOSEKey parses a CBOR encoded COSE public key.
+func parseCOSEKey(b []byte) (*coseKey, int, error) {
+	v, n, err := decodeCBOR(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	m, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, 0, fmt.Errorf("cose key is not a map")
+	}
+	kty, _ := m[int64(coseKeyKty)].(int64)
+	alg, _ := m[int64(coseKeyAlg)].(int64)
+
+	switch alg {
+	case COSEAlgES256:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		y, _ := m[int64(coseKeyY)].([]byte)
+		if kty != coseKtyEC2 || crv != coseCrvP256 ||
+			len(x) != 32 || len(y) != 32 {
+			return nil, 0, fmt.Errorf("invalid ES256 key")
+		}
+		pk := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !pk.Curve.IsOnCurve(pk.X, pk.Y) {
+			return nil, 0, fmt.Errorf("ES256 key is not on the curve")
+		}
+		return &coseKey{alg: alg, key: pk}, n, nil
+
+	case COSEAlgEdDSA:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		if kty != coseKtyOKP || crv != coseCrvEd25519 ||
+			len(x) != ed25519.PublicKeySize {
+			return nil, 0, fmt.Errorf("invalid EdDSA key")
+		}
+		return &coseKey{alg: alg, key: ed25519.PublicKey(x)}, n, nil
+
+	case COSEAlgRS256:
+		nb, _ := m[int64(coseKeyN)].([]byte)
+		eb, _ := m[int64(coseKeyE)].([]byte)
+		if kty != coseKtyRSA || len(nb) < 256 || len(eb) == 0 ||
+			len(eb) > 4 {
+			return nil, 0, fmt.Errorf("invalid RS256 key")
+		}
+		e := new(big.Int).SetBytes(eb)
+		return &coseKey{
+			alg: alg,
+			key: &rsa.PublicKey{
+				N: new(big.Int).SetBytes(nb),
+				E: int(e.Int64()),
+			},
+		}, n, nil
+	}
+
+	return nil, 0, fmt.Errorf("unsupported algorithm %v", alg)
+}
+
+// verify verifies a signature of the provided data.
+func (k *coseKey) verify(data, sig []byte) bool {
+	switch pk := k.key.(type) {
+	case *ecdsa.PublicKey:
+		h := sha256.Sum256(data)
+		return ecdsa.VerifyASN1(pk, h[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(pk, data, sig)
+	case *rsa.PublicKey:
+		h := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(pk, crypto.SHA256, h[:], sig) == nil
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/decred/politeia/util"
+)
+
+// TestAuthenticator is a software authenticator that can be used to execute
+// the WebAuthn ceremonies in tests. It returns none attestations.
+type TestAuthenticator struct {
+	CredentialID []byte
+	SignCount    uint32
+
+	rp         RelyingParty
+	alg        int64
+	ecdsaKey   *ecdsa.PrivateKey
+	ed25519Key ed25519.PrivateKey
+}
+
+// NewTestAuthenticator returns a new TestAuthenticator for the provided
+// relying party. The algorithm must be either COSEAlgES256 or COSEAlgEdDSA.
+func NewTestAuthenticator(rp RelyingParty, alg int64) (*TestAuthenticator, error) {
+	id, err := util.Random(16)
+	if err != nil {
+		return nil, err
+	}
+	a := TestAuthenticator{
+		CredentialID: id,
+		rp:           rp,
+		alg:          alg,
+	}
+	switch alg {
+	case COSEAlgES256:
+		a.ecdsaKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case COSEAlgEdDSA:
+		_, a.ed25519Key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		err = fmt.Errorf("unsupported algorithm %v", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Register executes the authenticator side of a registration ceremony and
+// returns the clientDataJSON and attestationObject.
+func (a *TestAuthenticator) Register(challenge []byte) ([]byte, []byte, error) {
+	clientDataJSON, err := a.clientData(clientDataTypeCreate, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Build the attested credential data
+	var l [2]byte
+	binary.BigEndian.PutUint16(l[:], uint16(len(a.CredentialID)))
+	acd := make([]byte, 16) // AAGUID
+	acd = append(acd, l[:]...)
+	acd = append(acd, a.CredentialID...)
+	acd = append(acd, a.publicKey()...)
+
+	authData := a.authenticatorData(flagUserPresent|
+		flagAttestedCredentialData, acd)
+	attestationObject := encodeCBOR(cborMapPairs{
+		{"fmt", "none"},
+		{"attStmt", cborMapPairs{}},
+		{"authData", authData},
+	})
+
+	return clientDataJSON, attestationObject, nil
+}
+
+// Assert executes the authenticator side of an authentication ceremony and
+// returns the clientDataJSON, authenticatorData, and signature. The signature
+// counter is incremented.
+func (a *TestAuthenticator) Assert(challenge []byte) ([]byte, []byte, []byte, error) {
+	clientDataJSON, err := a.clientData(clientDataTypeGet, challenge)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	a.SignCount++
+	authData := a.authenticatorData(flagUserPresent, nil)
+
+	cdh := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte{}, authData...), cdh[:]...)
+	var sig []byte
+	switch a.alg {
+	case COSEAlgES256:
+		h := sha256.Sum256(signed)
+		sig, err = ecdsa.SignASN1(rand.Reader, a.ecdsaKey, h[:])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	case COSEAlgEdDSA:
+		sig = ed25519.Sign(a.ed25519Key, signed)
+	}
+
+	return clientDataJSON, authData, sig, nil
+}
+
+// clientData returns the collected client data for a ceremony.
+func (a *TestAuthenticator) clientData(typ string, challenge []byte) ([]byte, error) {
+	return json.Marshal(clientData{
+		Type:      typ,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    a.rp.Origin,
+	})
+}
+
+// authenticatorData returns the authenticator data with the provided flags
+// and attested credential data.
+func (a *TestAuthenticator) authenticatorData(flags byte, acd []byte) []byte {
+	h := sha256.Sum256([]byte(a.rp.ID))
+	var c [4]byte
+	binary.BigEndian.PutUint32(c[:], a.SignCount)
+	b := append(h[:], flags)
+	b = append(b, c[:]...)
+	return append(b, acd...)
+}
+
+// publicKey returns the COSE encoded public key of the authenticator.
+func (a *TestAuthenticator) publicKey() []byte {
+	switch a.alg {
+	case COSEAlgES256:
+		x := make([]byte, 32)
+		y := make([]byte, 32)
+		a.ecdsaKey.X.FillBytes(x)
+		a.ecdsaKey.Y.FillBytes(y)
+		return encodeCBOR(cborMapPairs{
+			{int64(coseKeyKty), int64(coseKtyEC2)},
+			{int64(coseKeyAlg), int64(COSEAlgES256)},
+			{int64(coseKeyCrv), int64(coseCrvP256)},
+			{int64(coseKeyX), x},
+			{int64(coseKeyY), y},
+		})
+	case COSEAlgEdDSA:
+		return encodeCBOR(cborMapPairs{
+			{int64(coseKeyKty), int64(coseKtyOKP)},
+			{int64(coseKeyAlg), int64(COSEAlgEdDSA)},
+			{int64(coseKeyCrv), int64(coseCrvEd25519)},
+			{int64(coseKeyX), []byte(a.ed25519Key.Public().(ed25519.PublicKey))},
+		})
+	}
+	return nil
+}
+
+// cborMapPairs is a CBOR map that is encoded in the order of its pairs.
+type cborMapPairs [][2]interface{}
+
+// encodeCBOR encodes the provided value as CBOR. Only the types that are
+// required by the TestAuthenticator are supported.
+func encodeCBOR(v interface{}) []byte {
+	switch t := v.(type) {
+	case int64:
+		if t < 0 {
+			return cborHead(cborNegInt, uint64(-1-t))
+		}
+		return cborHead(cborUint, uint64(t))
+	case []byte:
+		return append(cborHead(cborBytes, uint64(len(t))), t...)
+	case string:
+		return append(cborHead(cborText, uint64(len(t))), t...)
+	case cborMapPairs:
+		b := cborHead(cborMap, uint64(len(t)))
+		for _, p := range t {
+			b = append(b, encodeCBOR(p[0])...)
+			b = append(b, encodeCBOR(p[1])...)
+		}
+		return b
+	}
+	panic(fmt.Sprintf("cbor: unsupported type %T", v))
+}
+
+// cborHead encodes the initial byte and argument of a CBOR data item.
+func cborHead(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major<<5 | 24, byte(arg)}
+	case arg <= 0xffff:
+		b := []byte{major<<5 | 25, 0, 0}
+		binary.BigEndian.PutUint16(b[1:], uint16(arg))
+		return b
+	case arg <= 0xffffffff:
+		b := []byte{major<<5 | 26, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(b[1:], uint32(arg))
+		return b
+	}
+	b := []byte{major<<5 | 27, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint64(b[1:], arg)
+	return b
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package webauthn implements the relying party side of the WebAuthn
+// registration and authentication ceremonies.
+//
+// Attestation statements are not verified since politeia does not restrict
+// the authenticator models that can be used. Clients should request the none
+// attestation conveyance preference.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// ChallengeSize is the size in bytes of a ceremony challenge.
+	ChallengeSize = 32
+
+	// Client data types
+	clientDataTypeCreate = "webauthn.create"
+	clientDataTypeGet    = "webauthn.get"
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent            = 0x01
+	flagAttestedCredentialData = 0x40
+	flagExtensionData          = 0x80
+)
+
+// VerificationError is returned when a WebAuthn ceremony fails verification.
+type VerificationError struct {
+	Reason string
+}
+
+// Error satisfies the error interface.
+func (e VerificationError) Error() string {
+	return "webauthn: " + e.Reason
+}
+
+// newVerificationError returns a new VerificationError.
+func newVerificationError(format string, args ...interface{}) VerificationError {
+	return VerificationError{
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// RelyingParty contains the relying party settings that the ceremonies are
+// verified against.
+type RelyingParty struct {
+	// ID is the relying party ID. This is the domain of the web server.
+	ID string
+
+	// Origin is the origin of the web server, including the scheme and
+	// the port if a non-default port is used.
+	Origin string
+}
+
+// Credential is a public key credential that has been registered by a user.
+type Credential struct {
+	ID        []byte
+	PublicKey []byte // COSE encoded
+	SignCount uint32
+}
+
+// NewChallenge returns a new random ceremony challenge.
+func NewChallenge() ([]byte, error) {
+	return util.Random(ChallengeSize)
+}
+
+// VerifyRegistration verifies the response of a registration ceremony and
+// returns the new credential. The clientDataJSON and attestationObject are
+// the raw values of the AuthenticatorAttestationResponse. A
+// VerificationError is returned if the response fails verification.
+func (rp RelyingParty) VerifyRegistration(challenge, clientDataJSON, attestationObject []byte) (*Credential, error) {
+	err := rp.verifyClientData(clientDataJSON, clientDataTypeCreate, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the attestation object
+	v, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, newVerificationError("invalid attestation object: %v",
+			err)
+	}
+	ao, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, newVerificationError("invalid attestation object")
+	}
+	rawAuthData, ok := ao["authData"].([]byte)
+	if !ok {
+		return nil, newVerificationError("authenticator data not found")
+	}
+	if _, ok := ao["fmt"].(string); !ok {
+		return nil, newVerificationError("attestation format not found")
+	}
+
+	// Verify the authenticator data
+	ad, err := rp.parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if ad.credentialID == nil {
+		return nil, newVerificationError("attested credential data not found")
+	}
+
+	return &Credential{
+		ID:        ad.credentialID,
+		PublicKey: ad.publicKey,
+		SignCount: ad.signCount,
+	}, nil
+}
+
+// VerifyAssertion verifies the response of an authentication ceremony for the
+// provided credential and returns the updated signature counter. The
+// clientDataJSON, authenticatorData, and signature are the raw values of the
+// AuthenticatorAssertionResponse. A VerificationError is returned if the
+// response fails verification.
+func (rp RelyingParty) VerifyAssertion(c Credential, challenge, clientDataJSON, authenticatorData, signature []byte) (uint32, error) {
+	err := rp.verifyClientData(clientDataJSON, clientDataTypeGet, challenge)
+	if err != nil {
+		return 0, err
+	}
+	ad, err := rp.parseAuthenticatorData(authenticatorData)
+	if err != nil {
+		return 0, err
+	}
+
+	// Verify the signature. The signature is over the authenticator
+	// data concatenated with the hash of the client data.
+	key, _, err := parseCOSEKey(c.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+	cdh := sha256.Sum256(clientDataJSON)
+	signed := make([]byte, 0, len(authenticatorData)+len(cdh))
+	signed = append(signed, authenticatorData...)
+	signed = append(signed, cdh[:]...)
+	if !key.verify(signed, signature) {
+		return 0, newVerificationError("invalid signature")
+	}
+
+	// Verify the signature counter. Authenticators that do not
+	// support a signature counter always return zero. A counter
+	// that has not increased indicates that the authenticator may
+	// have been cloned.
+	if (ad.signCount != 0 || c.SignCount != 0) &&
+		ad.signCount <= c.SignCount {
+		return 0, newVerificationError("signature counter did not "+
+			"increase: got %v, stored %v", ad.signCount, c.SignCount)
+	}
+
+	return ad.signCount, nil
+}
+
+// clientData contains the fields of the collected client data that are
+// verified.
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// verifyClientData verifies the collected client data of a ceremony.
+func (rp RelyingParty) verifyClientData(clientDataJSON []byte, typ string, challenge []byte) error {
+	var cd clientData
+	err := json.Unmarshal(clientDataJSON, &cd)
+	if err != nil {
+		return newVerificationError("invalid client data: %v", err)
+	}
+	c, err := base64.RawURLEncoding.DecodeString(cd.Challenge)
+	if err != nil {
+		return newVerificationError("invalid client data challenge")
+	}
+	switch {
+	case cd.Type != typ:
+		return newVerificationError("invalid client data type: got %v, "+
+			"want %v", cd.Type, typ)
+	case len(challenge) == 0 ||
+		subtle.ConstantTimeCompare(c, challenge) != 1:
+		return newVerificationError("challenge mismatch")
+	case cd.Origin != rp.Origin:
+		return newVerificationError("origin mismatch: got %v, want %v",
+			cd.Origin, rp.Origin)
+	case cd.CrossOrigin:
+		return newVerificationError("cross origin requests are not allowed")
+	}
+	return nil
+}
+
+// authenticatorData contains the parsed authenticator data.
+type authenticatorData struct {
+	flags     byte
+	signCount uint32
+
+	// The following fields are only populated when attested
+	// credential data is included.
+	credentialID []byte
+	publicKey    []byte
+}
+
+// parseAuthenticatorData parses the authenticator data and verifies the
+// relying party ID hash and the user present flag.
+func (rp RelyingParty) parseAuthenticatorData(b []byte) (*authenticatorData, error) {
+	// The authenticator data begins with the relying party ID hash
+	// (32 bytes), the flags (1 byte), and the signature counter
+	// (4 bytes).
+	if len(b) < 37 {
+		return nil, newVerificationError("authenticator data too short")
+	}
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(b[:32], rpIDHash[:]) {
+		return nil, newVerificationError("relying party id hash mismatch")
+	}
+	ad := authenticatorData{
+		flags:     b[32],
+		signCount: binary.BigEndian.Uint32(b[33:37]),
+	}
+	if ad.flags&flagUserPresent == 0 {
+		return nil, newVerificationError("user not present")
+	}
+	rest := b[37:]
+
+	// Parse the attested credential data. This consists of the
+	// AAGUID (16 bytes), the credential ID length (2 bytes), the
+	// credential ID, and the COSE encoded credential public key.
+	if ad.flags&flagAttestedCredentialData != 0 {
+		if len(rest) < 18 {
+			return nil, newVerificationError("attested credential data " +
+				"too short")
+		}
+		l := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if l == 0 || len(rest) < l {
+			return nil, newVerificationError("invalid credential id")
+		}
+		ad.credentialID = append([]byte{}, rest[:l]...)
+		rest = rest[l:]
+
+		_, n, err := parseCOSEKey(rest)
+		if err != nil {
+			return nil, newVerificationError("invalid credential public "+
+				"key: %v", err)
+		}
+		ad.publicKey = append([]byte{}, rest[:n]...)
+		rest = rest[n:]
+	}
+
+	// Extensions are not used, but they must be well formed
+	if ad.flags&flagExtensionData != 0 {
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, newVerificationError("invalid extensions: %v", err)
+		}
+		rest = rest[n:]
+	}
+	if len(rest) != 0 {
+		return nil, newVerificationError("unexpected trailing authenticator " +
+			"data")
+	}
+
+	return &ad, nil
+}
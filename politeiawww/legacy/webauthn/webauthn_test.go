@@ -0,0 +1,234 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package webauthn
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var testRP = RelyingParty{
+	ID:     "localhost",
+	Origin: "https://localhost:3000",
+}
+
+func TestCeremonies(t *testing.T) {
+	for _, alg := range []int64{COSEAlgES256, COSEAlgEdDSA} {
+		a, err := NewTestAuthenticator(testRP, alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Registration
+		challenge, err := NewChallenge()
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientDataJSON, attestationObject, err := a.Register(challenge)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := testRP.VerifyRegistration(challenge, clientDataJSON,
+			attestationObject)
+		if err != nil {
+			t.Fatalf("alg %v: VerifyRegistration: %v", alg, err)
+		}
+		if !bytes.Equal(c.ID, a.CredentialID) {
+			t.Fatalf("alg %v: got credential id %x, want %x",
+				alg, c.ID, a.CredentialID)
+		}
+
+		// Authentication
+		challenge, err = NewChallenge()
+		if err != nil {
+			t.Fatal(err)
+		}
+		clientDataJSON, authData, sig, err := a.Assert(challenge)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signCount, err := testRP.VerifyAssertion(*c, challenge,
+			clientDataJSON, authData, sig)
+		if err != nil {
+			t.Fatalf("alg %v: VerifyAssertion: %v", alg, err)
+		}
+		if signCount != a.SignCount {
+			t.Fatalf("alg %v: got sign count %v, want %v",
+				alg, signCount, a.SignCount)
+		}
+	}
+}
+
+func TestVerifyRegistrationErrors(t *testing.T) {
+	a, err := NewTestAuthenticator(testRP, COSEAlgES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherChallenge, err := NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientDataJSON, attestationObject, err := a.Register(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name              string
+		rp                RelyingParty
+		challenge         []byte
+		clientDataJSON    []byte
+		attestationObject []byte
+	}{
+		{
+			"wrong challenge",
+			testRP,
+			otherChallenge,
+			clientDataJSON,
+			attestationObject,
+		},
+		{
+			"wrong origin",
+			RelyingParty{ID: testRP.ID, Origin: "https://evil.com"},
+			challenge,
+			clientDataJSON,
+			attestationObject,
+		},
+		{
+			"wrong rp id",
+			RelyingParty{ID: "evil.com", Origin: testRP.Origin},
+			challenge,
+			clientDataJSON,
+			attestationObject,
+		},
+		{
+			"truncated attestation object",
+			testRP,
+			challenge,
+			clientDataJSON,
+			attestationObject[:len(attestationObject)-1],
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.rp.VerifyRegistration(test.challenge,
+				test.clientDataJSON, test.attestationObject)
+			var ve VerificationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("got err %v, want VerificationError", err)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertionErrors(t *testing.T) {
+	a, err := NewTestAuthenticator(testRP, COSEAlgES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := NewChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientDataJSON, attestationObject, err := a.Register(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := testRP.VerifyRegistration(challenge, clientDataJSON,
+		attestationObject)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The registration client data is not valid for an assertion
+	// since the client data type is different.
+	clientDataJSON, authData, sig, err := a.Assert(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	regClientDataJSON, _, err := a.Register(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	badSig := append([]byte{}, sig...)
+	badSig[len(badSig)-1] ^= 0xff
+
+	// Credential with a signature counter that is already at
+	// the value that is returned by the authenticator.
+	replayed := *c
+	replayed.SignCount = a.SignCount
+
+	var tests = []struct {
+		name           string
+		cred           Credential
+		clientDataJSON []byte
+		sig            []byte
+	}{
+		{"wrong type", *c, regClientDataJSON, sig},
+		{"bad signature", *c, clientDataJSON, badSig},
+		{"counter not increased", replayed, clientDataJSON, sig},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := testRP.VerifyAssertion(test.cred, challenge,
+				test.clientDataJSON, authData, test.sig)
+			var ve VerificationError
+			if !errors.As(err, &ve) {
+				t.Fatalf("got err %v, want VerificationError", err)
+			}
+		})
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	var tests = []struct {
+		name    string
+		b       []byte
+		want    interface{}
+		wantErr bool
+	}{
+		{"uint", []byte{0x18, 0x64}, int64(100), false},
+		{"negative int", []byte{0x38, 0x63}, int64(-100), false},
+		{"bytes", []byte{0x42, 0x01, 0x02}, []byte{0x01, 0x02}, false},
+		{"text", []byte{0x62, 'h', 'i'}, "hi", false},
+		{"true", []byte{0xf5}, true, false},
+		{"truncated", []byte{0x42, 0x01}, nil, true},
+		{"indefinite length", []byte{0x5f}, nil, true},
+		{"float", []byte{0xf9, 0x3c, 0x00}, nil, true},
+		{"duplicate key", []byte{0xa2, 0x01, 0x01, 0x01, 0x02}, nil, true},
+		{"huge array", []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0xff}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, n, err := decodeCBOR(test.b)
+			switch {
+			case test.wantErr && err == nil:
+				t.Fatalf("got nil error, want error")
+			case test.wantErr:
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if n != len(test.b) {
+				t.Fatalf("got %v bytes consumed, want %v", n, len(test.b))
+			}
+			if b, ok := test.want.([]byte); ok {
+				if !bytes.Equal(v.([]byte), b) {
+					t.Fatalf("got %x, want %x", v, b)
+				}
+				return
+			}
+			if v != test.want {
+				t.Fatalf("got %v, want %v", v, test.want)
+			}
+		})
+	}
+}
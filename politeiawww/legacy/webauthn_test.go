@@ -0,0 +1,386 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package legacy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	www "github.com/decred/politeia/politeiawww/api/www/v1"
+	"github.com/decred/politeia/politeiawww/legacy/user"
+	"github.com/decred/politeia/politeiawww/legacy/webauthn"
+)
+
+// registerWebAuthn registers a new credential for the provided user using a
+// test authenticator and returns the authenticator and the verify reply.
+func registerWebAuthn(t *testing.T, p *Politeiawww, u *user.User) (*webauthn.TestAuthenticator, *www.VerifyWebAuthnReply) {
+	t.Helper()
+
+	a, err := webauthn.NewTestAuthenticator(p.webAuthnRelyingParty(),
+		webauthn.COSEAlgES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swr, err := p.processSetWebAuthn(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := base64.RawURLEncoding.DecodeString(swr.Challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientDataJSON, attestationObject, err := a.Register(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vwr, err := p.processVerifyWebAuthn(www.VerifyWebAuthn{
+		Name:              "key",
+		ClientDataJSON:    base64.RawURLEncoding.EncodeToString(clientDataJSON),
+		AttestationObject: base64.RawURLEncoding.EncodeToString(attestationObject),
+	}, u)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a, vwr
+}
+
+// webAuthnAssertion returns a login assertion for the provided login
+// challenge.
+func webAuthnAssertion(t *testing.T, a *webauthn.TestAuthenticator, lc www.WebAuthnLoginChallenge) *www.WebAuthnAssertion {
+	t.Helper()
+
+	challenge, err := base64.RawURLEncoding.DecodeString(lc.Challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientDataJSON, authData, sig, err := a.Assert(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &www.WebAuthnAssertion{
+		CredentialID:      base64.RawURLEncoding.EncodeToString(a.CredentialID),
+		ClientDataJSON:    base64.RawURLEncoding.EncodeToString(clientDataJSON),
+		AuthenticatorData: base64.RawURLEncoding.EncodeToString(authData),
+		Signature:         base64.RawURLEncoding.EncodeToString(sig),
+	}
+}
+
+func TestProcessVerifyWebAuthn(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	usr, _ := newUser(t, p, true, false)
+
+	a, err := webauthn.NewTestAuthenticator(p.webAuthnRelyingParty(),
+		webauthn.COSEAlgES256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swr, err := p.processSetWebAuthn(usr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	challenge, err := base64.RawURLEncoding.DecodeString(swr.Challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientDataJSON, attestationObject, err := a.Register(challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vw := www.VerifyWebAuthn{
+		Name:              "key",
+		ClientDataJSON:    base64.RawURLEncoding.EncodeToString(clientDataJSON),
+		AttestationObject: base64.RawURLEncoding.EncodeToString(attestationObject),
+	}
+	noName := vw
+	noName.Name = ""
+
+	// The tests are run in order. The challenge is consumed by the
+	// success case so it cannot be reused.
+	var tests = []struct {
+		name      string
+		params    www.VerifyWebAuthn
+		wantError error
+	}{
+		{
+			"error no name",
+			noName,
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			},
+		},
+		{
+			"success",
+			vw,
+			nil,
+		},
+		{
+			"error challenge reused",
+			vw,
+			www.UserError{
+				ErrorCode: www.ErrorStatusWebAuthnFailedValidation,
+			},
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			reply, err := p.processVerifyWebAuthn(v.params, usr)
+			got := errToStr(err)
+			want := errToStr(v.wantError)
+			if got != want {
+				t.Fatalf("got %v, want %v", got, want)
+			}
+			if err != nil {
+				return
+			}
+			if len(reply.BackupCodes) != webAuthnBackupCodesCount {
+				t.Errorf("got %v backup codes, want %v",
+					len(reply.BackupCodes), webAuthnBackupCodesCount)
+			}
+		})
+	}
+}
+
+func TestLoginWebAuthn(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	// newUser() sets the password to be the username
+	usr, _ := newUser(t, p, true, false)
+	a, vwr := registerWebAuthn(t, p, usr)
+	backupCode := vwr.BackupCodes[0]
+
+	login := www.Login{
+		Email:    usr.Email,
+		Password: usr.Username,
+	}
+
+	// loginChallenge attempts a login without a second factor and
+	// returns the login challenge from the error context.
+	loginChallenge := func(t *testing.T) www.WebAuthnLoginChallenge {
+		t.Helper()
+
+		_, err := p.processLogin(login)
+		ue, ok := err.(www.UserError)
+		if !ok || ue.ErrorCode != www.ErrorStatusRequiresWebAuthn {
+			t.Fatalf("got %v, want %v", errToStr(err),
+				www.ErrorStatus[www.ErrorStatusRequiresWebAuthn])
+		}
+		if len(ue.ErrorContext) != 1 {
+			t.Fatalf("login challenge not found")
+		}
+		var lc www.WebAuthnLoginChallenge
+		err = json.Unmarshal([]byte(ue.ErrorContext[0]), &lc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lc
+	}
+
+	t.Run("success assertion", func(t *testing.T) {
+		l := login
+		l.WebAuthn = webAuthnAssertion(t, a, loginChallenge(t))
+		reply, err := p.processLogin(l)
+		if err != nil {
+			t.Fatalf("got %v, want nil", errToStr(err))
+		}
+		if !reply.WebAuthnEnabled {
+			t.Errorf("got WebAuthnEnabled false, want true")
+		}
+	})
+
+	t.Run("error assertion replayed", func(t *testing.T) {
+		lc := loginChallenge(t)
+		l := login
+		l.WebAuthn = webAuthnAssertion(t, a, lc)
+		_, err := p.processLogin(l)
+		if err != nil {
+			t.Fatalf("got %v, want nil", errToStr(err))
+		}
+		_, err = p.processLogin(l)
+		got := errToStr(err)
+		want := www.ErrorStatus[www.ErrorStatusWebAuthnFailedValidation]
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("backup code", func(t *testing.T) {
+		l := login
+		l.BackupCode = backupCode
+		_, err := p.processLogin(l)
+		if err != nil {
+			t.Fatalf("got %v, want nil", errToStr(err))
+		}
+
+		// Backup codes can only be used once
+		_, err = p.processLogin(l)
+		got := errToStr(err)
+		want := www.ErrorStatus[www.ErrorStatusWebAuthnFailedValidation]
+		if got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+// webAuthnChallenge returns the challenge from the error context of an
+// ErrorStatusRequiresWebAuthn error.
+func webAuthnChallenge(t *testing.T, err error) www.WebAuthnLoginChallenge {
+	t.Helper()
+
+	ue, ok := err.(www.UserError)
+	if !ok || ue.ErrorCode != www.ErrorStatusRequiresWebAuthn {
+		t.Fatalf("got %v, want %v", errToStr(err),
+			www.ErrorStatus[www.ErrorStatusRequiresWebAuthn])
+	}
+	if len(ue.ErrorContext) != 1 {
+		t.Fatalf("webauthn challenge not found")
+	}
+	var lc www.WebAuthnLoginChallenge
+	err = json.Unmarshal([]byte(ue.ErrorContext[0]), &lc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lc
+}
+
+func TestProcessRemoveWebAuthn(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	usr, _ := newUser(t, p, true, false)
+	a, vwr := registerWebAuthn(t, p, usr)
+
+	// A challenge is returned when the request is not confirmed
+	rw := www.RemoveWebAuthn{
+		CredentialID: vwr.CredentialID,
+	}
+	_, err := p.processRemoveWebAuthn(rw, usr)
+	webAuthnChallenge(t, err)
+
+	// A login challenge cannot be used to confirm the request
+	login := www.Login{
+		Email:    usr.Email,
+		Password: usr.Username,
+	}
+	_, err = p.processLogin(login)
+	loginAssertion := webAuthnAssertion(t, a, webAuthnChallenge(t, err))
+
+	var tests = []struct {
+		name      string
+		params    www.RemoveWebAuthn
+		wantError error
+	}{
+		{
+			"error credential not found",
+			www.RemoveWebAuthn{
+				CredentialID: "AAAA",
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusWebAuthnCredentialNotFound,
+			},
+		},
+		{
+			"error invalid backup code",
+			www.RemoveWebAuthn{
+				CredentialID: vwr.CredentialID,
+				BackupCode:   "invalid",
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusWebAuthnFailedValidation,
+			},
+		},
+		{
+			"error login assertion",
+			www.RemoveWebAuthn{
+				CredentialID: vwr.CredentialID,
+				WebAuthn:     loginAssertion,
+			},
+			www.UserError{
+				ErrorCode: www.ErrorStatusWebAuthnFailedValidation,
+			},
+		},
+		{
+			"success",
+			rw,
+			nil,
+		},
+	}
+
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			if v.wantError == nil {
+				// Sign a new challenge. The previous challenges
+				// have been consumed.
+				_, err := p.processRemoveWebAuthn(rw, usr)
+				v.params.WebAuthn = webAuthnAssertion(t, a,
+					webAuthnChallenge(t, err))
+			}
+			_, err := p.processRemoveWebAuthn(v.params, usr)
+			got := errToStr(err)
+			want := errToStr(v.wantError)
+			if got != want {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+
+	// The backup codes are removed along with the last credential
+	u, err := p.db.UserGetById(usr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(u.WebAuthnCredentials) != 0 || len(u.WebAuthnBackupCodes) != 0 {
+		t.Errorf("got %v credentials and %v backup codes, want 0",
+			len(u.WebAuthnCredentials), len(u.WebAuthnBackupCodes))
+	}
+}
+
+func TestProcessWebAuthnBackupCodes(t *testing.T) {
+	p, cleanup := newTestPoliteiawww(t)
+	defer cleanup()
+
+	usr, _ := newUser(t, p, true, false)
+	_, vwr := registerWebAuthn(t, p, usr)
+
+	// A challenge is returned when the request is not confirmed
+	_, err := p.processWebAuthnBackupCodes(www.WebAuthnBackupCodes{}, usr)
+	webAuthnChallenge(t, err)
+
+	// An invalid backup code is rejected
+	_, err = p.processWebAuthnBackupCodes(www.WebAuthnBackupCodes{
+		BackupCode: "invalid",
+	}, usr)
+	got := errToStr(err)
+	want := www.ErrorStatus[www.ErrorStatusWebAuthnFailedValidation]
+	if got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// A backup code confirms the request
+	bcr, err := p.processWebAuthnBackupCodes(www.WebAuthnBackupCodes{
+		BackupCode: vwr.BackupCodes[0],
+	}, usr)
+	if err != nil {
+		t.Fatalf("got %v, want nil", errToStr(err))
+	}
+	if len(bcr.BackupCodes) != webAuthnBackupCodesCount {
+		t.Errorf("got %v backup codes, want %v",
+			len(bcr.BackupCodes), webAuthnBackupCodesCount)
+	}
+
+	// The previous backup codes have been replaced
+	_, err = p.processWebAuthnBackupCodes(www.WebAuthnBackupCodes{
+		BackupCode: vwr.BackupCodes[1],
+	}, usr)
+	got = errToStr(err)
+	if got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -85,6 +85,7 @@ func (p *Politeiawww) handlePolicy(w http.ResponseWriter, r *http.Request) {
 		MinVoteDuration:            0,
 		MaxVoteDuration:            0,
 		PaywallConfirmations:       p.cfg.MinConfirmationsRequired,
+		EmailLanguages:             p.mail.Languages(),
 	}
 
 	util.RespondWithJSON(w, http.StatusOK, reply)
@@ -557,6 +557,30 @@ func (p *Politeiawww) handleManageUser(w http.ResponseWriter, r *http.Request) {
 	util.RespondWithJSON(w, http.StatusOK, mur)
 }
 
+// handleMailQueue handles the request to inspect the outbound mail queue.
+func (p *Politeiawww) handleMailQueue(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleMailQueue")
+
+	var mq www.MailQueue
+	err := util.ParseGetParams(r, &mq)
+	if err != nil {
+		RespondWithError(w, r, 0, "handleMailQueue: ParseGetParams",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	mqr, err := p.processMailQueue(mq)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleMailQueue: processMailQueue %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, mqr)
+}
+
 // handleSetTOTP handles the setting of TOTP Key
 func (p *Politeiawww) handleSetTOTP(w http.ResponseWriter, r *http.Request) {
 	log.Tracef("handleSetTOTP")
@@ -618,3 +642,152 @@ func (p *Politeiawww) handleVerifyTOTP(w http.ResponseWriter, r *http.Request) {
 
 	util.RespondWithJSON(w, http.StatusOK, vtr)
 }
+
+// handleSetWebAuthn handles the request to begin the registration of a
+// WebAuthn credential.
+func (p *Politeiawww) handleSetWebAuthn(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleSetWebAuthn")
+
+	var sw www.SetWebAuthn
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&sw); err != nil {
+		RespondWithError(w, r, 0, "handleSetWebAuthn: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleSetWebAuthn: getSessionUser %v", err)
+		return
+	}
+
+	swr, err := p.processSetWebAuthn(u)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleSetWebAuthn: processSetWebAuthn %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, swr)
+}
+
+// handleVerifyWebAuthn handles the request to complete the registration of a
+// WebAuthn credential.
+func (p *Politeiawww) handleVerifyWebAuthn(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleVerifyWebAuthn")
+
+	var vw www.VerifyWebAuthn
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&vw); err != nil {
+		RespondWithError(w, r, 0, "handleVerifyWebAuthn: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVerifyWebAuthn: getSessionUser %v", err)
+		return
+	}
+
+	vwr, err := p.processVerifyWebAuthn(vw, u)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleVerifyWebAuthn: processVerifyWebAuthn %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, vwr)
+}
+
+// handleWebAuthnCredentials handles the request to list the WebAuthn
+// credentials of the logged in user.
+func (p *Politeiawww) handleWebAuthnCredentials(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleWebAuthnCredentials")
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleWebAuthnCredentials: getSessionUser %v", err)
+		return
+	}
+
+	wcr, err := p.processWebAuthnCredentials(u)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleWebAuthnCredentials: processWebAuthnCredentials %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, wcr)
+}
+
+// handleRemoveWebAuthn handles the request to remove a WebAuthn credential.
+func (p *Politeiawww) handleRemoveWebAuthn(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleRemoveWebAuthn")
+
+	var rw www.RemoveWebAuthn
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&rw); err != nil {
+		RespondWithError(w, r, 0, "handleRemoveWebAuthn: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleRemoveWebAuthn: getSessionUser %v", err)
+		return
+	}
+
+	rwr, err := p.processRemoveWebAuthn(rw, u)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleRemoveWebAuthn: processRemoveWebAuthn %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, rwr)
+}
+
+// handleWebAuthnBackupCodes handles the request to replace the WebAuthn
+// backup codes of the logged in user.
+func (p *Politeiawww) handleWebAuthnBackupCodes(w http.ResponseWriter, r *http.Request) {
+	log.Tracef("handleWebAuthnBackupCodes")
+
+	var bc www.WebAuthnBackupCodes
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&bc); err != nil {
+		RespondWithError(w, r, 0, "handleWebAuthnBackupCodes: unmarshal",
+			www.UserError{
+				ErrorCode: www.ErrorStatusInvalidInput,
+			})
+		return
+	}
+
+	u, err := p.sessions.GetSessionUser(w, r)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleWebAuthnBackupCodes: getSessionUser %v", err)
+		return
+	}
+
+	bcr, err := p.processWebAuthnBackupCodes(bc, u)
+	if err != nil {
+		RespondWithError(w, r, 0,
+			"handleWebAuthnBackupCodes: processWebAuthnBackupCodes %v", err)
+		return
+	}
+
+	util.RespondWithJSON(w, http.StatusOK, bcr)
+}
@@ -5,12 +5,18 @@
 package mail
 
 import (
+	"bytes"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime/multipart"
 	"net/mail"
+	"net/textproto"
 	"net/url"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/dajohi/goemail"
@@ -23,19 +29,64 @@ const (
 	// used when initializing a new client. This value is configurable
 	// so that it can be updated during tests.
 	defaultRateLimitPeriod = 24 * time.Hour
+
+	// defaultPollInterval is the default interval at which the mail
+	// sender checks the mail queue for emails that are due to be sent.
+	// The sender is also woken up whenever a new email is queued.
+	defaultPollInterval = 30 * time.Second
+
+	// defaultRetryBackoff is the default delay before the first retry of
+	// an email that failed to send. The delay is doubled on every failed
+	// attempt, up to defaultRetryBackoffMax.
+	defaultRetryBackoff    = 1 * time.Minute
+	defaultRetryBackoffMax = 6 * time.Hour
+
+	// defaultMaxAttempts is the default number of times that the sender
+	// attempts to send an email before giving up and marking it as
+	// failed. Failed emails remain in the mail queue as dead letters.
+	defaultMaxAttempts = 8
+
+	// sendBatchSize is the maximum number of queued emails that are sent
+	// during a single pass over the mail queue.
+	sendBatchSize = 50
+
+	// sentMailRetention is the amount of time that sent emails are kept
+	// in the mail queue. Sent emails are kept for a while so that their
+	// idempotency keys continue to prevent duplicate notifications.
+	sentMailRetention = 7 * 24 * time.Hour
+
+	// pruneInterval is the interval at which sent emails that are older
+	// than the retention period are pruned from the mail queue.
+	pruneInterval = 1 * time.Hour
 )
 
+// sender sends an email message. It is satisfied by the goemail SMTP client
+// and allows the tests to use an SMTP stand-in.
+type sender interface {
+	Send(msg *goemail.Message) error
+}
+
 // client provides an SMTP client for sending emails from a preset email
 // address.
 //
+// Emails are not sent synchronously. They are persisted to the mail queue
+// in the MailerDB and are sent by a background sender, which retries failed
+// emails using an exponential backoff.
+//
 // client implements the Mailer interface.
 type client struct {
-	smtp        *goemail.SMTP // SMTP server
+	smtp        sender        // SMTP server
 	mailName    string        // From name
 	mailAddress string        // From email address
 	mailerDB    user.MailerDB // User mailer database in www
 	disabled    bool          // Has email been disabled
 
+	// templates is used to render the notification emails. The emails
+	// that are sent to politeiawww users are rendered in the language
+	// that is returned by userLanguages. userLanguages is optional.
+	templates     *Templates
+	userLanguages UserLanguagesFunc
+
 	// rateLimit is the maximum number of emails that can be sent to
 	// any individual user during a single rateLimitPeriod. Once the
 	// rate limit is hit the user must wait one rateLimitPeriod before
@@ -43,6 +94,18 @@ type client struct {
 	// applied to certain client methods.
 	rateLimit       int
 	rateLimitPeriod time.Duration
+
+	// The following fields configure the background sender. They are
+	// configurable so that they can be updated during tests.
+	pollInterval    time.Duration
+	retryBackoff    time.Duration
+	retryBackoffMax time.Duration
+	maxAttempts     int
+
+	lastPrune time.Time     // Last time sent emails were pruned
+	notify    chan struct{} // Wakes up the sender
+	quit      chan struct{} // Stops the sender
+	wg        sync.WaitGroup
 }
 
 // IsEnabled returns whether the mail server is enabled.
@@ -52,30 +115,45 @@ func (c *client) IsEnabled() bool {
 	return !c.disabled
 }
 
-// SendTo sends an email to a list of recipient email addresses.
+// Languages returns the languages that notification emails can be rendered
+// in.
+//
+// This function satisfies the Mailer interface.
+func (c *client) Languages() []string {
+	return c.templates.Languages()
+}
+
+// SendTo queues an email to a list of recipient email addresses.
 // This function does not rate limit emails and a recipient does
 // does not need to correspond to a politeiawww user. This function
 // can be used to send emails to sysadmins or similar cases.
 //
+// The email is rendered in the default language using the provided template
+// name and template data.
+//
+// The key is an optional idempotency key. An email that is sent using the
+// key of an email that has already been queued is skipped.
+//
 // This function satisfies the Mailer interface.
-func (c *client) SendTo(subject, body string, recipients []string) error {
+func (c *client) SendTo(key, tmpl string, data interface{}, recipients []string) error {
 	if c.disabled || len(recipients) == 0 {
 		return nil
 	}
 
-	// Setup email
-	msg := goemail.NewMessage(c.mailAddress, subject, body)
-	msg.SetName(c.mailName)
-
-	// Add all recipients to BCC
-	for _, v := range recipients {
-		msg.AddBCC(v)
+	e, err := c.templates.Render(tmpl, DefaultLanguage, data)
+	if err != nil {
+		return err
+	}
+	err = c.enqueue(key, *e, recipients)
+	if errors.Is(err, user.ErrMailExists) {
+		log.Debugf("Mail already queued: %v", key)
+		return nil
 	}
 
-	return c.smtp.Send(msg)
+	return err
 }
 
-// SendToUsers sends an email to a list of recipient email
+// SendToUsers queues an email to a list of recipient email
 // addresses. The recipient MUST correspond to a politeiawww user
 // in the database for the email to be sent. This function rate
 // limits the number of emails that can be sent to any individual
@@ -83,8 +161,15 @@ func (c *client) SendTo(subject, body string, recipients []string) error {
 // not correspond to a politeiawww user, the email is simply
 // skipped. An error is not returned.
 //
+// The email is rendered in the language preference of each recipient using
+// the provided template name and template data.
+//
+// The key is an optional idempotency key. An email that is sent using the
+// key of an email that has already been queued is skipped and does not
+// count towards the rate limit of the recipients.
+//
 // This function satisfies the Mailer interface.
-func (c *client) SendToUsers(subjects, body string, recipients map[uuid.UUID]string) error {
+func (c *client) SendToUsers(key, tmpl string, data interface{}, recipients map[uuid.UUID]string) error {
 	if c.disabled || len(recipients) == 0 {
 		return nil
 	}
@@ -95,13 +180,17 @@ func (c *client) SendToUsers(subjects, body string, recipients map[uuid.UUID]str
 	}
 
 	// Handle valid recipients.
-	err = c.SendTo(subjects, body, filtered.valid)
+	queued, err := c.enqueueLocalized(key, tmpl, data, filtered.valid)
 	if err != nil {
 		return err
 	}
+	if !queued {
+		log.Debugf("Mail already queued: %v", key)
+		return nil
+	}
 
 	// Handle warning email recipients.
-	err = c.SendTo(limitEmailSubject, limitEmailBody, filtered.warning)
+	_, err = c.enqueueLocalized("", TemplateRateLimit, nil, filtered.warning)
 	if err != nil {
 		return err
 	}
@@ -115,6 +204,267 @@ func (c *client) SendToUsers(subjects, body string, recipients map[uuid.UUID]str
 	return nil
 }
 
+// Close stops the background mail sender. Emails that have not been sent yet
+// remain in the mail queue and are sent once the client is restarted.
+//
+// This function satisfies the Mailer interface.
+func (c *client) Close() {
+	if c.disabled {
+		return
+	}
+	close(c.quit)
+	c.wg.Wait()
+}
+
+// enqueueLocalized renders the email in the language preference of each
+// recipient and queues one email per language. The idempotency key of the
+// emails that are not in the default language is suffixed with the language.
+// The returned bool is false if all of the emails had already been queued.
+func (c *client) enqueueLocalized(key, tmpl string, data interface{}, recipients map[uuid.UUID]string) (bool, error) {
+	if len(recipients) == 0 {
+		return true, nil
+	}
+
+	// Group the recipients by language
+	var langs map[uuid.UUID]string
+	if c.userLanguages != nil {
+		ids := make([]uuid.UUID, 0, len(recipients))
+		for id := range recipients {
+			ids = append(ids, id)
+		}
+		langs = c.userLanguages(ids)
+	}
+	groups := make(map[string][]string, 1) // [language][]email
+	for id, email := range recipients {
+		lang := langs[id]
+		if !c.templates.IsSupported(lang) {
+			lang = DefaultLanguage
+		}
+		groups[lang] = append(groups[lang], email)
+	}
+	order := make([]string, 0, len(groups))
+	for lang := range groups {
+		order = append(order, lang)
+	}
+	sort.Strings(order)
+
+	// Render and queue the email for every language
+	var queued bool
+	for _, lang := range order {
+		e, err := c.templates.Render(tmpl, lang, data)
+		if err != nil {
+			return false, err
+		}
+		k := key
+		if k != "" && lang != DefaultLanguage {
+			k += ":" + lang
+		}
+		err = c.enqueue(k, *e, groups[lang])
+		switch {
+		case errors.Is(err, user.ErrMailExists):
+			// Already queued; continue
+		case err != nil:
+			return false, err
+		default:
+			queued = true
+		}
+	}
+
+	return queued, nil
+}
+
+// enqueue inserts an email into the mail queue and wakes up the background
+// sender. A random ID is used when an idempotency key is not provided. A
+// user.ErrMailExists error is returned if the key has already been used.
+func (c *client) enqueue(key string, e Email, recipients []string) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	if key == "" {
+		key = uuid.New().String()
+	}
+
+	now := time.Now().Unix()
+	err := c.mailerDB.MailQueueNew(user.QueuedMail{
+		ID:            key,
+		Subject:       e.Subject,
+		Body:          e.Text,
+		HTML:          e.HTML,
+		Recipients:    recipients,
+		Status:        user.MailStatusQueued,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Wake up the sender. This is a noop if the sender has already been
+	// notified.
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// run is the background mail sender. It sends the queued emails that are
+// due whenever it is notified of a new email or the poll interval elapses.
+//
+// This function must be run as a goroutine.
+func (c *client) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		c.sendQueued(time.Now())
+
+		select {
+		case <-c.quit:
+			return
+		case <-ticker.C:
+		case <-c.notify:
+		}
+	}
+}
+
+// sendQueued sends the queued emails that are due at the provided time and
+// prunes the old sent emails from the mail queue.
+func (c *client) sendQueued(now time.Time) {
+	for {
+		due, err := c.mailerDB.MailQueueDue(now.Unix(), sendBatchSize)
+		if err != nil {
+			log.Errorf("Mail queue due: %v", err)
+			return
+		}
+		for _, m := range due {
+			c.send(m, now)
+		}
+		if len(due) < sendBatchSize {
+			break
+		}
+	}
+
+	if now.Sub(c.lastPrune) < pruneInterval {
+		return
+	}
+	err := c.mailerDB.MailQueuePrune(now.Add(-sentMailRetention).Unix())
+	if err != nil {
+		log.Errorf("Mail queue prune: %v", err)
+		return
+	}
+	c.lastPrune = now
+}
+
+// send attempts to send a queued email and updates the email in the mail
+// queue with the result. An email that fails to send is retried after an
+// exponential backoff. An email that has failed to send the maximum number
+// of times is marked as failed and is not retried.
+func (c *client) send(m user.QueuedMail, now time.Time) {
+	m.Attempts++
+	msg, err := c.newMessage(m)
+	if err == nil {
+		err = c.smtp.Send(msg)
+	}
+	switch {
+	case err == nil:
+		m.Status = user.MailStatusSent
+		m.SentAt = now.Unix()
+		m.LastError = ""
+
+	case m.Attempts >= c.maxAttempts:
+		log.Errorf("Mail %v failed after %v attempts: %v",
+			m.ID, m.Attempts, err)
+		m.Status = user.MailStatusFailed
+		m.LastError = err.Error()
+
+	default:
+		backoff := c.backoff(m.Attempts)
+		log.Warnf("Mail %v attempt %v failed, retrying in %v: %v",
+			m.ID, m.Attempts, backoff, err)
+		m.NextAttemptAt = now.Add(backoff).Unix()
+		m.LastError = err.Error()
+	}
+
+	err = c.mailerDB.MailQueueUpdate(m)
+	if err != nil {
+		log.Errorf("Mail queue update %v: %v", m.ID, err)
+	}
+}
+
+// newMessage returns the email message for a queued email. Emails that have
+// an HTML body are sent as multipart/alternative messages that contain both
+// the plain text and the HTML body.
+func (c *client) newMessage(m user.QueuedMail) (*goemail.Message, error) {
+	var msg *goemail.Message
+	if m.HTML == "" {
+		msg = goemail.NewMessage(c.mailAddress, m.Subject, m.Body)
+	} else {
+		body, contentType, err := multipartBody(m.Body, m.HTML)
+		if err != nil {
+			return nil, err
+		}
+		msg = goemail.NewMessageType(c.mailAddress, m.Subject, body,
+			contentType)
+	}
+	msg.SetName(c.mailName)
+
+	// Add all recipients to BCC
+	for _, v := range m.Recipients {
+		msg.AddBCC(v)
+	}
+
+	return msg, nil
+}
+
+// multipartBody returns the multipart/alternative body and the content type
+// of an email that contains a plain text and an HTML body. The plain text
+// part comes first so that email clients prefer the HTML part.
+func multipartBody(text, html string) (string, string, error) {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	}
+	for _, p := range parts {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Type", p.contentType)
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			return "", "", err
+		}
+		_, err = pw.Write([]byte(p.body))
+		if err != nil {
+			return "", "", err
+		}
+	}
+	err := w.Close()
+	if err != nil {
+		return "", "", err
+	}
+	return b.String(), "multipart/alternative; boundary=" + w.Boundary(), nil
+}
+
+// backoff returns the delay before the next attempt of an email that has
+// failed to send the provided number of times.
+func (c *client) backoff(attempts int) time.Duration {
+	d := c.retryBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= c.retryBackoffMax {
+			return c.retryBackoffMax
+		}
+	}
+	return d
+}
+
 // filteredRecipients is returned by the filteredRecipients function and
 // contains the recipients that should receive some sort of email notification.
 //
@@ -127,12 +477,12 @@ func (c *client) SendToUsers(subjects, body string, recipients map[uuid.UUID]str
 type filteredRecipients struct {
 	// valid contains the email addresses of the users that have not
 	// hit the email rate limit and are eligible to receive an email.
-	valid []string
+	valid map[uuid.UUID]string
 
 	// warning contains the email addresses of the users that have hit
 	// the email rate limit during this invocation and should be sent
 	// the rate limit warning email.
-	warning []string
+	warning map[uuid.UUID]string
 
 	// histories contains the updated email histories of the users in
 	// the valid and warning lists.
@@ -155,8 +505,8 @@ func (c *client) filterRecipients(users map[uuid.UUID]string) (*filteredRecipien
 	// Divide recipients into valid and warning recipients, and parse their
 	// new email history.
 	var (
-		valid     = make([]string, 0, len(users))
-		warning   = make([]string, 0, len(users))
+		valid     = make(map[uuid.UUID]string, len(users))
+		warning   = make(map[uuid.UUID]string, len(users))
 		histories = make(map[uuid.UUID]user.EmailHistory, len(users))
 	)
 	for userID, email := range users {
@@ -168,7 +518,7 @@ func (c *client) filterRecipients(users map[uuid.UUID]string) (*filteredRecipien
 				Timestamps:       []int64{time.Now().Unix()},
 				LimitWarningSent: false,
 			}
-			valid = append(valid, email)
+			valid[userID] = email
 			continue
 		}
 
@@ -180,7 +530,7 @@ func (c *client) filterRecipients(users map[uuid.UUID]string) (*filteredRecipien
 		if len(history.Timestamps) < c.rateLimit {
 			// Rate limit has not been hit, add user to valid recipients and
 			// update email history.
-			valid = append(valid, email)
+			valid[userID] = email
 			history.Timestamps = append(history.Timestamps, time.Now().Unix())
 			history.LimitWarningSent = false
 			histories[userID] = history
@@ -191,7 +541,7 @@ func (c *client) filterRecipients(users map[uuid.UUID]string) (*filteredRecipien
 			// Rate limit has been hit with the last email notification above.
 			// If limit warning email has not yet been sent, add user to
 			// warning recipients and update email history.
-			warning = append(warning, email)
+			warning[userID] = email
 			history.LimitWarningSent = true
 			histories[userID] = history
 		}
@@ -221,23 +571,38 @@ func filterTimestamps(in []int64, delta time.Duration) []int64 {
 	return out
 }
 
-// The limit email is sent to users as a warning when they hit the email rate
-// limit.
-const limitEmailSubject = "Email Rate Limit Hit"
-const limitEmailBody = `
+// TemplateRateLimit is the name of the email that is sent to users as a
+// warning when they hit the email rate limit.
+const TemplateRateLimit = "mail-rate-limit"
+
+const rateLimitText = `
 Your email rate limit for the past 24 hours has been hit. This measure is used to avoid malicious users from spamming Politeia's email server. You will not receive any notification emails for 24 hours.
 
 We apologize for any inconvenience.
 `
 
-// NewClient returns a new client.
-func NewClient(host, user, password, emailAddress, certPath string, skipVerify bool, rateLimit int, db user.MailerDB) (*client, error) {
+func init() {
+	RegisterTemplate(TemplateRateLimit, Template{
+		Subject: "Email Rate Limit Hit",
+		Text:    rateLimitText,
+	})
+}
+
+// UserLanguagesFunc returns the language preferences of the provided users.
+// Users that have not set a language preference can be omitted from the
+// returned map.
+type UserLanguagesFunc func(userIDs []uuid.UUID) map[uuid.UUID]string
+
+// NewClient returns a new client. The notification emails are rendered using
+// the provided templates. The user languages function is optional.
+func NewClient(host, user, password, emailAddress, certPath string, skipVerify bool, rateLimit int, db user.MailerDB, t *Templates, langs UserLanguagesFunc) (*client, error) {
 	// Email is considered disabled if any of the required user
 	// credentials are missing.
 	if host == "" || user == "" || password == "" {
 		log.Infof("Mail: DISABLED")
 		return &client{
-			disabled: true,
+			disabled:  true,
+			templates: t,
 		}, nil
 	}
 
@@ -281,13 +646,31 @@ func NewClient(host, user, password, emailAddress, certPath string, skipVerify b
 		return nil, err
 	}
 
+	c := newClient(smtp, a.Name, a.Address, rateLimit, db, t)
+	c.userLanguages = langs
+	c.wg.Add(1)
+	go c.run()
+
+	return c, nil
+}
+
+// newClient returns a new client that uses the default sender settings. The
+// background sender is not started.
+func newClient(s sender, mailName, mailAddress string, rateLimit int, db user.MailerDB, t *Templates) *client {
 	return &client{
-		smtp:            smtp,
-		mailName:        a.Name,
-		mailAddress:     a.Address,
+		templates:       t,
+		smtp:            s,
+		mailName:        mailName,
+		mailAddress:     mailAddress,
 		mailerDB:        db,
 		disabled:        false,
 		rateLimit:       rateLimit,
 		rateLimitPeriod: defaultRateLimitPeriod,
-	}, nil
+		pollInterval:    defaultPollInterval,
+		retryBackoff:    defaultRetryBackoff,
+		retryBackoffMax: defaultRetryBackoffMax,
+		maxAttempts:     defaultMaxAttempts,
+		notify:          make(chan struct{}, 1),
+		quit:            make(chan struct{}),
+	}
 }
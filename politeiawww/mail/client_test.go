@@ -5,9 +5,11 @@
 package mail
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/dajohi/goemail"
 	"github.com/decred/politeia/politeiawww/legacy/user"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
@@ -275,17 +277,243 @@ func TestFilterTimestamps(t *testing.T) {
 	}
 }
 
+func TestSendToUsersIdempotency(t *testing.T) {
+	var (
+		c       = newTestClient(10, defaultRateLimitPeriod, nil)
+		db      = c.mailerDB
+		userID  = uuid.New()
+		emails  = map[uuid.UUID]string{userID: "user@email.com"}
+		key     = "proposal-new:token"
+		subject = "New proposal"
+	)
+
+	// Queue the same notification twice
+	for i := 0; i < 2; i++ {
+		err := c.SendToUsers(key, testTemplate,
+			testEmail{Subject: subject}, emails)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Verify that the email was only queued once
+	queued, err := db.MailQueueGetByStatus(user.MailStatusQueued, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queued) != 1 {
+		t.Fatalf("got %v queued emails, want 1", len(queued))
+	}
+	if queued[0].ID != key || queued[0].Subject != subject {
+		t.Errorf("got queued email %v %q, want %v %q",
+			queued[0].ID, queued[0].Subject, key, subject)
+	}
+
+	// Verify that the duplicate did not count towards the rate limit
+	hs, err := db.EmailHistoriesGet([]uuid.UUID{userID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hs[userID].Timestamps) != 1 {
+		t.Errorf("got %v email history timestamps, want 1",
+			len(hs[userID].Timestamps))
+	}
+
+	// Emails without an idempotency key are always queued
+	for i := 0; i < 2; i++ {
+		err := c.SendTo("", testTemplate, testEmail{Subject: subject},
+			[]string{"admin@email.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	queued, err = db.MailQueueGetByStatus(user.MailStatusQueued, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queued) != 3 {
+		t.Errorf("got %v queued emails, want 3", len(queued))
+	}
+}
+
+func TestSendQueued(t *testing.T) {
+	var (
+		c    = newTestClient(10, defaultRateLimitPeriod, nil)
+		db   = c.mailerDB
+		smtp = c.smtp.(*testSMTP)
+		now  = time.Now()
+		to   = []string{"a@email.com", "b@email.com"}
+	)
+	c.maxAttempts = 3
+
+	// mailStatus returns the queued email with the provided ID.
+	mailStatus := func(id string) user.QueuedMail {
+		t.Helper()
+		for _, s := range []user.MailStatusT{user.MailStatusQueued,
+			user.MailStatusSent, user.MailStatusFailed} {
+			mail, err := db.MailQueueGetByStatus(s, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range mail {
+				if m.ID == id {
+					return m
+				}
+			}
+		}
+		t.Fatalf("mail %v not found", id)
+		return user.QueuedMail{}
+	}
+
+	// The first attempt fails and the email is retried after the
+	// backoff.
+	smtp.fail = 1
+	err := c.SendTo("retry", testTemplate, testEmail{}, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.sendQueued(now)
+	m := mailStatus("retry")
+	wantNext := now.Add(c.retryBackoff).Unix()
+	if m.Status != user.MailStatusQueued || m.Attempts != 1 ||
+		m.NextAttemptAt != wantNext || m.LastError == "" {
+		t.Fatalf("got status %v attempts %v next %v error %q, want "+
+			"status %v attempts 1 next %v and an error", m.Status,
+			m.Attempts, m.NextAttemptAt, m.LastError,
+			user.MailStatusQueued, wantNext)
+	}
+
+	// The email is not sent again until the backoff has elapsed
+	c.sendQueued(now.Add(c.retryBackoff - time.Second))
+	if len(smtp.sent) != 0 {
+		t.Fatalf("email was sent before the backoff elapsed")
+	}
+
+	// The second attempt succeeds
+	later := now.Add(c.retryBackoff)
+	c.sendQueued(later)
+	m = mailStatus("retry")
+	if m.Status != user.MailStatusSent || m.Attempts != 2 ||
+		m.SentAt != later.Unix() || m.LastError != "" {
+		t.Fatalf("got status %v attempts %v sent %v error %q, want "+
+			"status %v attempts 2 sent %v and no error", m.Status,
+			m.Attempts, m.SentAt, m.LastError, user.MailStatusSent,
+			later.Unix())
+	}
+	if len(smtp.sent) != 1 {
+		t.Fatalf("got %v sent emails, want 1", len(smtp.sent))
+	}
+	diff := cmp.Diff(smtp.sent[0].Recipients(), to)
+	if diff != "" {
+		t.Errorf("recipients got/want diff: \n%v", diff)
+	}
+
+	// An email that fails the maximum number of times is dead lettered
+	smtp.fail = c.maxAttempts
+	err = c.SendTo("dead", testTemplate, testEmail{}, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := now
+	for i := 0; i < c.maxAttempts; i++ {
+		c.sendQueued(ts)
+		ts = ts.Add(c.retryBackoffMax)
+	}
+	m = mailStatus("dead")
+	if m.Status != user.MailStatusFailed || m.Attempts != c.maxAttempts {
+		t.Fatalf("got status %v attempts %v, want status %v attempts %v",
+			m.Status, m.Attempts, user.MailStatusFailed, c.maxAttempts)
+	}
+
+	// Failed emails are not retried
+	c.sendQueued(ts.Add(c.retryBackoffMax))
+	if len(smtp.sent) != 1 {
+		t.Errorf("got %v sent emails, want 1", len(smtp.sent))
+	}
+
+	// Sent emails are pruned once the retention period has elapsed.
+	// Failed emails are kept.
+	c.sendQueued(later.Add(sentMailRetention + time.Second))
+	sent, err := db.MailQueueGetByStatus(user.MailStatusSent, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 0 {
+		t.Errorf("got %v sent emails after pruning, want 0", len(sent))
+	}
+	mailStatus("dead")
+}
+
+func TestBackoff(t *testing.T) {
+	c := newTestClient(10, defaultRateLimitPeriod, nil)
+	c.retryBackoff = time.Minute
+	c.retryBackoffMax = 5 * time.Minute
+
+	var tests = []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 5 * time.Minute},
+		{100, 5 * time.Minute},
+	}
+	for _, test := range tests {
+		got := c.backoff(test.attempts)
+		if got != test.want {
+			t.Errorf("backoff(%v): got %v, want %v",
+				test.attempts, got, test.want)
+		}
+	}
+}
+
+// testSMTP is an SMTP stand-in that records the emails that it sends instead
+// of sending them. The next fail sends return an error.
+type testSMTP struct {
+	fail int
+	sent []*goemail.Message
+}
+
+// Send records the provided email.
+//
+// This function satisfies the sender interface.
+func (s *testSMTP) Send(msg *goemail.Message) error {
+	if s.fail > 0 {
+		s.fail--
+		return errors.New("smtp server unavailable")
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+// testTemplate is the name of the email template that is used by the tests.
+const testTemplate = "test"
+
+// testEmail is the template data of the test email template.
+type testEmail struct {
+	Subject string
+	Body    string
+}
+
+func init() {
+	RegisterTemplate(testTemplate, Template{
+		Subject: "{{.Subject}}",
+		Text:    "{{.Body}}",
+	})
+}
+
 // newTestClient returns a new client that is setup for testing. The caller can
 // optionally provide a list of email histories to seed the testMailerDB with
-// on intialization.
+// on intialization. The client uses a testSMTP stand-in, the built-in email
+// templates, and the background sender is not started.
 func newTestClient(rateLimit int, rateLimitPeriod time.Duration, histories map[uuid.UUID]user.EmailHistory) *client {
-	return &client{
-		smtp:            nil,
-		mailName:        "test",
-		mailAddress:     "test@email.com",
-		mailerDB:        user.NewTestMailerDB(histories),
-		disabled:        false,
-		rateLimit:       rateLimit,
-		rateLimitPeriod: rateLimitPeriod,
+	t, err := NewTemplates("")
+	if err != nil {
+		panic(err)
 	}
+	c := newClient(&testSMTP{}, "test", "test@email.com", rateLimit,
+		user.NewTestMailerDB(histories), t)
+	c.rateLimitPeriod = rateLimitPeriod
+	return c
 }
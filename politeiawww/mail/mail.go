@@ -11,18 +11,38 @@ type Mailer interface {
 	// IsEnabled determines if the smtp server is enabled or not.
 	IsEnabled() bool
 
-	// SendTo sends an email to a list of recipient email addresses.
+	// Languages returns the languages that notification emails can be
+	// rendered in.
+	Languages() []string
+
+	// SendTo queues an email to a list of recipient email addresses.
 	// This function does not rate limit emails and a recipient does
 	// does not need to correspond to a politeiawww user. This function
-	// can be used to send emails to sysadmins or similar cases.
-	SendTo(subject, body string, recipients []string) error
+	// can be used to send emails to sysadmins or similar cases. The
+	// email is rendered in the default language using the provided
+	// template name and template data.
+	//
+	// The key is an optional idempotency key. An email that is sent
+	// using the key of an email that has already been queued is
+	// skipped. An empty key disables the idempotency check.
+	SendTo(key, tmpl string, data interface{}, recipients []string) error
 
-	// SendToUsers sends an email to a list of recipient email
+	// SendToUsers queues an email to a list of recipient email
 	// addresses. The recipient MUST correspond to a politeiawww user
 	// in the database for the email to be sent. This function rate
 	// limits the number of emails that can be sent to any individual
 	// user over a 24 hour period. If a recipient is provided that does
 	// not correspond to a politeiawww user, the email is simply
-	// skipped. An error is not returned.
-	SendToUsers(subject, body string, recipients map[uuid.UUID]string) error
+	// skipped. An error is not returned. The email is rendered in the
+	// language preference of each recipient using the provided template
+	// name and template data.
+	//
+	// The key is an optional idempotency key. An email that is sent
+	// using the key of an email that has already been queued is
+	// skipped. An empty key disables the idempotency check.
+	SendToUsers(key, tmpl string, data interface{}, recipients map[uuid.UUID]string) error
+
+	// Close stops sending the queued emails. Emails that have not been
+	// sent yet remain queued.
+	Close()
 }
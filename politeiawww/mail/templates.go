@@ -0,0 +1,301 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+const (
+	// DefaultLanguage is the language that notification emails are
+	// rendered in when a user has not set a language preference or when
+	// a template has not been translated into the user's language. The
+	// built-in templates are written in the default language.
+	DefaultLanguage = "en"
+
+	// The following file extensions are used for the template files
+	// that are loaded from the templates directory. The templates of a
+	// notification email are saved to <dir>/<language>/<name><ext>. The
+	// subject and plain text templates are required. The HTML template
+	// is optional.
+	extSubject = ".subject.tmpl"
+	extText    = ".txt.tmpl"
+	extHTML    = ".html.tmpl"
+)
+
+// Template contains the templates that are used to render a notification
+// email. The templates use the text/template syntax. The HTML template is
+// parsed using the html/template package so that the template data is
+// escaped. The subject and plain text templates are required. The HTML
+// template is optional. Emails that have an HTML template are sent as
+// multipart emails that contain both the plain text and the HTML body.
+type Template struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Email is a rendered notification email.
+type Email struct {
+	Subject string
+	Text    string
+	HTML    string // Optional
+}
+
+// parsedTemplate is a Template that has been parsed.
+type parsedTemplate struct {
+	subject *template.Template
+	text    *template.Template
+	html    *htmltemplate.Template // Optional
+}
+
+// render renders the template using the provided template data.
+func (t *parsedTemplate) render(data interface{}) (*Email, error) {
+	var b bytes.Buffer
+	err := t.subject.Execute(&b, data)
+	if err != nil {
+		return nil, fmt.Errorf("subject: %v", err)
+	}
+	// The subject is a single line
+	subject := strings.Join(strings.Fields(b.String()), " ")
+
+	b.Reset()
+	err = t.text.Execute(&b, data)
+	if err != nil {
+		return nil, fmt.Errorf("text: %v", err)
+	}
+	text := b.String()
+
+	var html string
+	if t.html != nil {
+		b.Reset()
+		err = t.html.Execute(&b, data)
+		if err != nil {
+			return nil, fmt.Errorf("html: %v", err)
+		}
+		html = b.String()
+	}
+
+	return &Email{
+		Subject: subject,
+		Text:    text,
+		HTML:    html,
+	}, nil
+}
+
+// parseTemplate parses the provided Template.
+func parseTemplate(name string, t Template) (*parsedTemplate, error) {
+	if t.Subject == "" || t.Text == "" {
+		return nil, fmt.Errorf("template %v: subject and text are required",
+			name)
+	}
+	subject, err := template.New(name + extSubject).Parse(t.Subject)
+	if err != nil {
+		return nil, err
+	}
+	text, err := template.New(name + extText).Parse(t.Text)
+	if err != nil {
+		return nil, err
+	}
+	var html *htmltemplate.Template
+	if t.HTML != "" {
+		html, err = htmltemplate.New(name + extHTML).Parse(t.HTML)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &parsedTemplate{
+		subject: subject,
+		text:    text,
+		html:    html,
+	}, nil
+}
+
+var (
+	builtinMtx sync.RWMutex
+	builtin    = make(map[string]*parsedTemplate) // [name]template
+)
+
+// RegisterTemplate registers the built-in template of a notification email.
+// The built-in template is used when the email template has not been
+// overridden in the templates directory. It panics if the template is
+// registered twice or if it cannot be parsed.
+//
+// RegisterTemplate is intended to be called from the init function of the
+// packages that send notification emails.
+func RegisterTemplate(name string, t Template) {
+	builtinMtx.Lock()
+	defer builtinMtx.Unlock()
+
+	if _, ok := builtin[name]; ok {
+		panic(fmt.Sprintf("mail template %v registered twice", name))
+	}
+	pt, err := parseTemplate(name, t)
+	if err != nil {
+		panic(fmt.Sprintf("mail template %v: %v", name, err))
+	}
+	builtin[name] = pt
+}
+
+// builtinTemplate returns the built-in template for the provided name.
+func builtinTemplate(name string) (*parsedTemplate, bool) {
+	builtinMtx.RLock()
+	defer builtinMtx.RUnlock()
+
+	t, ok := builtin[name]
+	return t, ok
+}
+
+// Templates renders notification emails using the templates that were loaded
+// from the templates directory, falling back to the built-in templates.
+//
+// Templates are looked up in the following order:
+//  1. The template in the requested language.
+//  2. The template in the default language.
+//  3. The built-in template.
+type Templates struct {
+	languages []string
+	templates map[string]map[string]*parsedTemplate // [language][name]
+}
+
+// Languages returns the languages that notification emails can be rendered
+// in. The default language is always included.
+func (t *Templates) Languages() []string {
+	return t.languages
+}
+
+// IsSupported returns whether notification emails can be rendered in the
+// provided language.
+func (t *Templates) IsSupported(language string) bool {
+	for _, v := range t.languages {
+		if v == language {
+			return true
+		}
+	}
+	return false
+}
+
+// Render renders the notification email with the provided template name in
+// the provided language.
+func (t *Templates) Render(name, language string, data interface{}) (*Email, error) {
+	pt, ok := t.templates[language][name]
+	if !ok {
+		pt, ok = t.templates[DefaultLanguage][name]
+	}
+	if !ok {
+		pt, ok = builtinTemplate(name)
+	}
+	if !ok {
+		return nil, fmt.Errorf("mail template not found: %v", name)
+	}
+	e, err := pt.render(data)
+	if err != nil {
+		return nil, fmt.Errorf("render %v %v: %v", name, language, err)
+	}
+	return e, nil
+}
+
+// NewTemplates returns a new Templates that loads the email templates from
+// the provided directory. The directory contains a subdirectory for every
+// supported language. The built-in templates are used for all emails when
+// the directory is not provided.
+//
+// Only templates that have been registered using RegisterTemplate can be
+// overridden. An error is returned if the directory contains an unknown
+// template.
+func NewTemplates(dir string) (*Templates, error) {
+	t := Templates{
+		languages: []string{DefaultLanguage},
+		templates: make(map[string]map[string]*parsedTemplate),
+	}
+	if dir == "" {
+		return &t, nil
+	}
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		language := e.Name()
+		templates, err := loadTemplates(filepath.Join(dir, language))
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", language, err)
+		}
+		t.templates[language] = templates
+		if language != DefaultLanguage {
+			t.languages = append(t.languages, language)
+		}
+
+		log.Infof("Mail templates loaded: %v %v", language, len(templates))
+	}
+	sort.Strings(t.languages[1:])
+
+	return &t, nil
+}
+
+// loadTemplates loads the email templates of a single language from the
+// provided directory.
+func loadTemplates(dir string) (map[string]*parsedTemplate, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+extText))
+	if err != nil {
+		return nil, err
+	}
+	templates := make(map[string]*parsedTemplate, len(files))
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), extText)
+		if _, ok := builtinTemplate(name); !ok {
+			return nil, fmt.Errorf("unknown mail template %v", name)
+		}
+
+		var tmpl Template
+		tmpl.Text, err = readTemplate(f, true)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.Subject, err = readTemplate(filepath.Join(dir, name+extSubject),
+			true)
+		if err != nil {
+			return nil, err
+		}
+		tmpl.HTML, err = readTemplate(filepath.Join(dir, name+extHTML),
+			false)
+		if err != nil {
+			return nil, err
+		}
+
+		pt, err := parseTemplate(name, tmpl)
+		if err != nil {
+			return nil, err
+		}
+		templates[name] = pt
+	}
+	return templates, nil
+}
+
+// readTemplate reads a template file. An empty string is returned if the
+// file does not exist and the file is not required.
+func readTemplate(path string, required bool) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err) && !required:
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,237 @@
+// Copyright (c) 2021 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mail
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/legacy/user"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+// writeTemplateFiles writes the provided template files to the templates
+// directory. The files map is keyed by the file path relative to dir.
+func writeTemplateFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		fp := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(fp), 0700)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(fp, []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTemplatesRender(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mailtemplates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The rate limit email is overridden in the default language and
+	// translated into Spanish. The test email is only translated into
+	// German.
+	writeTemplateFiles(t, dir, map[string]string{
+		"en/mail-rate-limit.subject.tmpl": "Rate limit",
+		"en/mail-rate-limit.txt.tmpl":     "Overridden",
+		"es/mail-rate-limit.subject.tmpl": "Límite",
+		"es/mail-rate-limit.txt.tmpl":     "Texto",
+		"es/mail-rate-limit.html.tmpl":    "<p>Texto</p>",
+		"de/test.subject.tmpl":            "Betreff {{.Subject}}",
+		"de/test.txt.tmpl":                "{{.Body}}",
+		"de/test.html.tmpl":               "<p>{{.Body}}</p>",
+	})
+
+	tmpls, err := NewTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(tmpls.Languages(), []string{"en", "de", "es"})
+	if diff != "" {
+		t.Errorf("languages got/want diff: \n%v", diff)
+	}
+
+	data := testEmail{
+		Subject: "subject\nwith newline",
+		Body:    "<b>body</b>",
+	}
+	var tests = []struct {
+		name     string
+		template string
+		language string
+		want     Email
+	}{
+		{
+			"translated template",
+			TemplateRateLimit,
+			"es",
+			Email{"Límite", "Texto", "<p>Texto</p>"},
+		},
+		{
+			"default language override",
+			TemplateRateLimit,
+			"de",
+			Email{"Rate limit", "Overridden", ""},
+		},
+		{
+			"built-in template",
+			testTemplate,
+			"es",
+			Email{"subject with newline", "<b>body</b>", ""},
+		},
+		{
+			"html escaped",
+			testTemplate,
+			"de",
+			Email{"Betreff subject with newline", "<b>body</b>",
+				"<p>&lt;b&gt;body&lt;/b&gt;</p>"},
+		},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			e, err := tmpls.Render(v.template, v.language, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff := cmp.Diff(*e, v.want)
+			if diff != "" {
+				t.Errorf("got/want diff: \n%v", diff)
+			}
+		})
+	}
+
+	// Unknown templates cannot be rendered
+	_, err = tmpls.Render("unknown", DefaultLanguage, nil)
+	if err == nil {
+		t.Errorf("rendered an unknown template")
+	}
+
+	// A templates directory that contains an unknown template or a
+	// template without a subject is rejected.
+	writeTemplateFiles(t, dir, map[string]string{
+		"fr/unknown.subject.tmpl": "Sujet",
+		"fr/unknown.txt.tmpl":     "Texte",
+	})
+	_, err = NewTemplates(dir)
+	if err == nil {
+		t.Errorf("loaded a directory with an unknown template")
+	}
+	os.RemoveAll(filepath.Join(dir, "fr"))
+	writeTemplateFiles(t, dir, map[string]string{
+		"fr/test.txt.tmpl": "Texte",
+	})
+	_, err = NewTemplates(dir)
+	if err == nil {
+		t.Errorf("loaded a template without a subject")
+	}
+}
+
+func TestSendToUsersLocalized(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mailtemplates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTemplateFiles(t, dir, map[string]string{
+		"es/test.subject.tmpl": "Asunto",
+		"es/test.txt.tmpl":     "Texto",
+		"es/test.html.tmpl":    "<p>Texto</p>",
+	})
+	tmpls, err := NewTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		c    = newTestClient(10, defaultRateLimitPeriod, nil)
+		db   = c.mailerDB
+		smtp = c.smtp.(*testSMTP)
+
+		userEN = uuid.New() // No language preference
+		userES = uuid.New() // Spanish
+		userXX = uuid.New() // Unsupported language
+
+		emails = map[uuid.UUID]string{
+			userEN: "en@email.com",
+			userES: "es@email.com",
+			userXX: "xx@email.com",
+		}
+		key = "proposal-new:token"
+	)
+	c.templates = tmpls
+	c.userLanguages = func(ids []uuid.UUID) map[uuid.UUID]string {
+		return map[uuid.UUID]string{
+			userES: "es",
+			userXX: "xx",
+		}
+	}
+
+	err = c.SendToUsers(key, testTemplate,
+		testEmail{Subject: "Subject", Body: "Text"}, emails)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify that one email was queued per language
+	queued, err := db.MailQueueGetByStatus(user.MailStatusQueued, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mail := make(map[string]user.QueuedMail, len(queued)) // [id]QueuedMail
+	for _, v := range queued {
+		mail[v.ID] = v
+	}
+	if len(mail) != 2 {
+		t.Fatalf("got %v queued emails, want 2", len(mail))
+	}
+	en, ok := mail[key]
+	if !ok {
+		t.Fatalf("default language email %v not queued", key)
+	}
+	es, ok := mail[key+":es"]
+	if !ok {
+		t.Fatalf("spanish email %v not queued", key+":es")
+	}
+	if en.Subject != "Subject" || en.HTML != "" ||
+		len(en.Recipients) != 2 {
+		t.Errorf("unexpected default language email: %+v", en)
+	}
+	if es.Subject != "Asunto" || es.HTML != "<p>Texto</p>" ||
+		len(es.Recipients) != 1 || es.Recipients[0] != emails[userES] {
+		t.Errorf("unexpected spanish email: %+v", es)
+	}
+
+	// Emails with an HTML body are sent as multipart emails
+	c.send(es, time.Now())
+	if len(smtp.sent) != 1 {
+		t.Fatalf("got %v sent emails, want 1", len(smtp.sent))
+	}
+	body := string(smtp.sent[0].Body())
+	for _, want := range []string{
+		"Content-Type: multipart/alternative; boundary=",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+		"Texto",
+		"<p>Texto</p>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("multipart body does not contain %q:\n%v",
+				want, body)
+		}
+	}
+}
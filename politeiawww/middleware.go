@@ -93,3 +93,20 @@ func (m *middleware) reqBodySizeLimitMiddleware(next http.Handler) http.Handler
 		next.ServeHTTP(w, r)
 	})
 }
+
+// rateLimitMiddleware applies the route rate limit policies to requests. A 429
+// is returned to the client if a rate limit has been exceeded.
+func (p *politeiawww) rateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if p.rateLimiter.HasPolicy(route) {
+			ok, wait := p.rateLimiter.Allow(route, p.rateLimitClient(r))
+			if !ok {
+				respondWithRateLimit(w, r, route, wait)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,16 +1,20 @@
-// Copyright (c) 2021 The Decred developers
+// Copyright (c) 2021-2022 The Decred developers
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
 package main
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	v3 "github.com/decred/politeia/politeiawww/api/http/v3"
+	"github.com/decred/politeia/politeiawww/ratelimit"
 	"github.com/gorilla/mux"
 )
 
@@ -92,3 +96,100 @@ func TestReqBodySizeMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestRateLimitMiddleware(t *testing.T) {
+	// Setup the test router with a rate limit policy
+	// that allows a single request per minute per IP.
+	const (
+		limitedRoute   = "/limited"
+		unlimitedRoute = "/unlimited"
+	)
+	p := &politeiawww{
+		rateLimiter: ratelimit.New([]ratelimit.Policy{
+			{
+				Target:   limitedRoute,
+				Key:      ratelimit.KeyIP,
+				Rate:     1,
+				Interval: time.Minute,
+				Burst:    1,
+			},
+		}),
+	}
+	router := mux.NewRouter()
+	router.Use(p.rateLimitMiddleware)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	router.HandleFunc(limitedRoute, handler)
+	router.HandleFunc(unlimitedRoute, handler)
+
+	// Setup tests
+	var tests = []struct {
+		name     string // Test name
+		route    string // Request route
+		ip       string // Client IP
+		wantCode int    // Expected http status code
+	}{
+		{
+			"first request allowed",
+			limitedRoute,
+			"10.0.0.1:1234",
+			http.StatusOK,
+		},
+		{
+			"second request limited",
+			limitedRoute,
+			"10.0.0.1:5678",
+			http.StatusTooManyRequests,
+		},
+		{
+			"different ip allowed",
+			limitedRoute,
+			"10.0.0.2:1234",
+			http.StatusOK,
+		},
+		{
+			"route without policy allowed",
+			unlimitedRoute,
+			"10.0.0.1:1234",
+			http.StatusOK,
+		},
+	}
+
+	// Run tests. The tests are dependent on
+	// each other and must be run in order.
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup and send the test request
+			req, err := http.NewRequest(http.MethodGet, tc.route, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.RemoteAddr = tc.ip
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			// Verify the response
+			if rr.Code != tc.wantCode {
+				t.Fatalf("wrong http response code: got %v, want %v",
+					rr.Code, tc.wantCode)
+			}
+			if rr.Code != http.StatusTooManyRequests {
+				return
+			}
+			if rr.Header().Get(retryAfterHeader) != "60" {
+				t.Errorf("wrong retry after header: got %v, want 60",
+					rr.Header().Get(retryAfterHeader))
+			}
+			var ue v3.UserError
+			err = json.Unmarshal(rr.Body.Bytes(), &ue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ue.ErrorCode != v3.ErrorCodeRateLimitExceeded {
+				t.Errorf("wrong error code: got %v, want %v",
+					ue.ErrorCode, v3.ErrorCodeRateLimitExceeded)
+			}
+		})
+	}
+}
@@ -19,6 +19,11 @@ type AuthManager interface {
 	//
 	// A UserError is returned if the user is not authorized.
 	Authorize(AuthorizeArgs) error
+
+	// UserPermissions returns the permission levels that the user has been
+	// granted. The returned permission levels are provided to the plugins
+	// on the User object.
+	UserPermissions(*User) ([]string, error)
 }
 
 // AuthorizeArgs contains the arguments for the Authorize method.
@@ -33,13 +38,28 @@ type AuthorizeArgs struct {
 // Session contains the data that is saved as part of a user session.
 //
 // Plugins do not have direct access to the sessions database, but the
-// AuthManager plugin is able to update fields on this session struct. Updates
-// are saved to the sessions database by the backend.
+// AuthManager and UserManager plugins are able to update fields on this
+// session struct, e.g. the UserManager sets the UserID when a user logs in.
+// Updates are saved to the sessions database by the backend.
+//
+// The backend replaces the session ID when the UserID of the session changes
+// in order to prevent session fixation attacks.
 type Session struct {
+	// ID is the session ID. It is set by the backend and changes made to
+	// it by the plugins are ignored. The ID is empty for requests that are
+	// not authenticated using a session cookie, e.g. API key requests.
+	ID string
+
 	UserID    string
 	CreatedAt int64
 
-	// Delete can be set by the AuthManager plugin to instruct the backend to
-	// delete the session.
+	// Save can be set by the AuthManager or UserManager plugin to instruct
+	// the backend to save the session even if none of the session values
+	// were updated. This allows a plugin to bind data to the ID of a new
+	// session, e.g. a pending login request.
+	Save bool
+
+	// Delete can be set by the AuthManager or UserManager plugin to instruct
+	// the backend to delete the session.
 	Delete bool
 }
@@ -27,6 +27,10 @@ type Plugin interface {
 	// Permissions returns the user permissions for each plugin commands. These
 	// are provided to the AuthPlugin on startup. The AuthPlugin handles user
 	// authorization at runtime.
+	//
+	// The permission level is the name of the role that is required to
+	// execute the command. See the Permission constants for the standard
+	// permission levels.
 	Permissions() map[string]string // [cmd]permissionLevel
 
 	// Hook executes a plugin hook.
@@ -45,6 +49,22 @@ type Plugin interface {
 	ReadTx(*sql.Tx, ReadArgs) (*Reply, error)
 }
 
+// The following permission levels are understood by all AuthManager plugins.
+// An AuthManager may support additional permission levels.
+const (
+	// PermissionPublic is the permission level for commands that can be
+	// executed by anyone, including users that are not logged in.
+	PermissionPublic = "public"
+
+	// PermissionUser is the permission level for commands that can only be
+	// executed by a logged in user.
+	PermissionUser = "user"
+
+	// PermissionAdmin is the permission level for commands that can only be
+	// executed by an admin.
+	PermissionAdmin = "admin"
+)
+
 // HookArgs contains the arguments for the plugin hook methods.
 type HookArgs struct {
 	Type  HookT
@@ -54,15 +74,24 @@ type HookArgs struct {
 }
 
 // WriteArgs contain the arguments for the plugin write methods.
+//
+// The Session is only provided to the UserManager plugin. It is nil for all
+// other plugins. Updates made to the session by the UserManager plugin are
+// saved by the backend.
 type WriteArgs struct {
-	Cmd  Cmd
-	User *User
+	Cmd     Cmd
+	User    *User
+	Session *Session
 }
 
 // ReadArgs contain the arguments for the plugin read methods.
+//
+// The Session is only provided to the UserManager plugin. It is nil for all
+// other plugins.
 type ReadArgs struct {
-	Cmd  Cmd
-	User *User
+	Cmd     Cmd
+	User    *User
+	Session *Session
 }
 
 // Cmd represents a plugin command.
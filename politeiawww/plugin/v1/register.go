@@ -4,7 +4,15 @@
 
 package v1
 
-import "github.com/pkg/errors"
+import (
+	"database/sql"
+
+	pdclient "github.com/decred/politeia/politeiad/client"
+	"github.com/decred/politeia/politeiawww/events"
+	"github.com/decred/politeia/politeiawww/mail"
+
+	"github.com/pkg/errors"
+)
 
 var (
 	// The following maps store the various initialization functions that have
@@ -18,6 +26,37 @@ var (
 // types.
 type InitArgs struct {
 	Settings []Setting
+
+	// DB is the politeiawww database. Plugins can use it to create and manage
+	// their own database tables.
+	DB *sql.DB
+
+	// UserDB provides the plugin with access to the plugin data of any user.
+	UserDB UserDB
+
+	// Politeiad is the politeiad client. Plugins that proxy commands to
+	// politeiad use it to send the politeiad requests.
+	Politeiad *pdclient.Client
+
+	// Permissions contains the permission levels of the commands of all
+	// registered plugins. It is only provided to the AuthManager.
+	Permissions map[string]map[string]string // [pluginID][cmd]permissionLevel
+
+	// Identities provides the active identities of the users. It is
+	// implemented by the UserManager and is nil when the user layer has
+	// been disabled. It is not provided to the UserManager.
+	Identities Identities
+
+	// Mail queues notification emails. The emails are sent in the
+	// background. Mail.IsEnabled returns false when email has not been
+	// configured for politeiawww.
+	Mail mail.Mailer
+
+	// Events is the politeiawww event manager. The plugins that replace the
+	// legacy routes emit the legacy events so that the event listeners, such
+	// as the notification emails, continue to work when the legacy routes
+	// have been disabled.
+	Events *events.Manager
 }
 
 // Setting represents a configurable plugin setting.
@@ -4,13 +4,63 @@
 
 package v1
 
-import "github.com/google/uuid"
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrUserNotFound is returned by the UserDB when a user is not found.
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// UserDB provides plugins with access to the user database. Plugins are only
+// able to access and update the plugin data that they own.
+//
+// The user that is executing a plugin command is provided to the plugin in
+// the command arguments. Updates to that user must be made using the
+// provided user object, not the UserDB, otherwise the updates will be
+// overwritten by the backend.
+type UserDB interface {
+	// TxGet returns the user for the provided user ID. The returned user only
+	// contains the plugin data that is owned by the plugin. The user is read
+	// without a database transaction if the provided transaction is nil.
+	//
+	// An ErrUserNotFound error is returned if a user is not found for the
+	// provided user ID.
+	TxGet(tx *sql.Tx, userID string) (*User, error)
+
+	// TxUpdate saves any updates that were made to the plugin data of the
+	// provided user.
+	TxUpdate(tx *sql.Tx, u User) error
+}
 
 // User represents a politeia user. The user will contain the PluginData for
 // the plugin that is executing the command or hook.
 type User struct {
 	ID         uuid.UUID // Unique ID
 	PluginData *PluginData
+
+	// Permissions contains the permission levels that the AuthManager has
+	// granted the user. Plugins can use it to vary the behavior of a command
+	// for privileged users, e.g. returning unvetted data to admins.
+	Permissions []string
+}
+
+// HasPermission returns whether the user has been granted the provided
+// permission level.
+func (u *User) HasPermission(permissionLevel string) bool {
+	if permissionLevel == PermissionPublic {
+		return true
+	}
+	for _, v := range u.Permissions {
+		if v == permissionLevel {
+			return true
+		}
+	}
+	return false
 }
 
 // PluginData contains the user data that is owned by the plugin.
@@ -4,7 +4,16 @@
 
 package v1
 
-import "database/sql"
+import (
+	"database/sql"
+	"errors"
+)
+
+var (
+	// ErrIdentityNotFound is returned by the Identities interface when a
+	// user does not have an active identity.
+	ErrIdentityNotFound = errors.New("identity not found")
+)
 
 // UserManager provides methods that result in state changes to the user
 // database that cannot be done inside of plugins.
@@ -29,4 +38,32 @@ type UserManager interface {
 	// inserted if this command executes successfully without any user errors
 	// or unexpected errors.
 	NewUser(*sql.Tx, WriteArgs) (*Reply, error)
+
+	// The UserManager manages the identities of the users. The identities
+	// are provided to the other plugins so that they are able to verify
+	// that a user signed a payload using their active identity.
+	Identities
+}
+
+// Identities provides plugins with read access to the identities of the
+// users. An identity is an ed25519 key pair that a user signs payloads with.
+// The identities and the usernames are managed by the UserManager.
+//
+// A nil database transaction can be provided by the read commands, which are
+// not executed using a database transaction.
+type Identities interface {
+	// ActiveIdentity returns the hex encoded public key of the active
+	// identity of the provided user.
+	//
+	// An ErrUserNotFound error is returned if a user is not found for the
+	// provided user ID. An ErrIdentityNotFound error is returned if the user
+	// does not have an active identity.
+	ActiveIdentity(tx *sql.Tx, userID string) (string, error)
+
+	// Username returns the username of the provided user. An empty string
+	// is returned if the UserManager does not assign usernames.
+	//
+	// An ErrUserNotFound error is returned if a user is not found for the
+	// provided user ID.
+	Username(tx *sql.Tx, userID string) (string, error)
 }
@@ -5,11 +5,23 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"strings"
 
 	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/decred/politeia/politeiawww/user"
 	"github.com/pkg/errors"
+
+	// Plugins register their initialization functions
+	// with the plugin package on package init.
+	_ "github.com/decred/politeia/politeiawww/plugins/comments"
+	_ "github.com/decred/politeia/politeiawww/plugins/oidc"
+	_ "github.com/decred/politeia/politeiawww/plugins/pi"
+	_ "github.com/decred/politeia/politeiawww/plugins/rbac"
+	_ "github.com/decred/politeia/politeiawww/plugins/records"
+	_ "github.com/decred/politeia/politeiawww/plugins/ticketvote"
+	_ "github.com/decred/politeia/politeiawww/plugins/userpass"
 )
 
 // setupPlugins initializes the plugins that have been specified in the
@@ -31,27 +43,13 @@ func (p *politeiawww) setupPlugins() error {
 		settings[pluginID] = ss
 	}
 
-	// Initialize the plugins
-	plugins := make(map[string]plugin.Plugin, len(p.cfg.Plugins))
-	for _, pluginID := range p.cfg.Plugins {
-		s, ok := settings[pluginID]
-		if !ok {
-			s = []plugin.Setting{}
-		}
-		args := plugin.InitArgs{
-			Settings: s,
-		}
-		pp, err := plugin.NewPlugin(pluginID, args)
-		if err != nil {
-			return errors.Errorf("failed to initialize %v", pluginID)
-		}
-		plugins[pluginID] = pp
-	}
-
-	// Initialize the user plugin interfaces
+	// Initialize the user manager. This is done prior to initializing
+	// the plugins since the user manager provides the plugins with the
+	// identities of the users.
 	var (
 		um  plugin.UserManager
 		am  plugin.AuthManager
+		ids plugin.Identities
 		err error
 	)
 	if !p.cfg.DisableUsers {
@@ -64,32 +62,70 @@ func (p *politeiawww) setupPlugins() error {
 				"plugin must be provided when the user layer is enabled")
 		}
 
-		// Initialize the user manager
 		s, ok := settings[p.cfg.UserPlugin]
 		if !ok {
 			s = []plugin.Setting{}
 		}
 		args := plugin.InitArgs{
-			Settings: s,
+			Settings:  s,
+			DB:        p.db,
+			UserDB:    newPluginUserDB(p.userDB, p.cfg.UserPlugin),
+			Politeiad: p.politeiad,
+			Mail:      p.mail,
 		}
 		um, err = plugin.NewUserManager(p.cfg.UserPlugin, args)
 		if err != nil {
-			return errors.Errorf("failed to initialize the user manager plugin %v",
-				p.cfg.UserPlugin)
+			return errors.Errorf("failed to initialize the user manager "+
+				"plugin %v: %v", p.cfg.UserPlugin, err)
 		}
+		ids = um
+	}
 
-		// Initialize the authorizer
-		s, ok = settings[p.cfg.AuthPlugin]
+	// Initialize the plugins
+	plugins := make(map[string]plugin.Plugin, len(p.cfg.Plugins))
+	for _, pluginID := range p.cfg.Plugins {
+		s, ok := settings[pluginID]
 		if !ok {
 			s = []plugin.Setting{}
 		}
-		args = plugin.InitArgs{
-			Settings: s,
+		args := plugin.InitArgs{
+			Settings:   s,
+			DB:         p.db,
+			UserDB:     newPluginUserDB(p.userDB, pluginID),
+			Politeiad:  p.politeiad,
+			Identities: ids,
+			Mail:       p.mail,
+			Events:     p.events,
+		}
+		pp, err := plugin.NewPlugin(pluginID, args)
+		if err != nil {
+			return errors.Errorf("failed to initialize %v: %v", pluginID, err)
+		}
+		plugins[pluginID] = pp
+	}
+
+	// Initialize the authorizer
+	if !p.cfg.DisableUsers {
+		s, ok := settings[p.cfg.AuthPlugin]
+		if !ok {
+			s = []plugin.Setting{}
+		}
+		perms := make(map[string]map[string]string, len(plugins))
+		for pluginID, pp := range plugins {
+			perms[pluginID] = pp.Permissions()
+		}
+		args := plugin.InitArgs{
+			Settings:    s,
+			DB:          p.db,
+			UserDB:      newPluginUserDB(p.userDB, p.cfg.AuthPlugin),
+			Politeiad:   p.politeiad,
+			Permissions: perms,
+			Identities:  ids,
 		}
 		am, err = plugin.NewAuthManager(p.cfg.AuthPlugin, args)
 		if err != nil {
-			return errors.Errorf("failed to initialize the auth manager plugin %v",
-				p.cfg.AuthPlugin)
+			return errors.Errorf("failed to initialize the auth manager "+
+				"plugin %v: %v", p.cfg.AuthPlugin, err)
 		}
 	}
 
@@ -102,6 +138,70 @@ func (p *politeiawww) setupPlugins() error {
 	return nil
 }
 
+// pluginUserDB implements the plugin UserDB interface. It provides a plugin
+// with access to the plugin data that it owns.
+type pluginUserDB struct {
+	userDB   user.DB
+	pluginID string
+}
+
+var (
+	_ plugin.UserDB = (*pluginUserDB)(nil)
+)
+
+// newPluginUserDB returns a new pluginUserDB for the provided plugin.
+func newPluginUserDB(userDB user.DB, pluginID string) *pluginUserDB {
+	return &pluginUserDB{
+		userDB:   userDB,
+		pluginID: pluginID,
+	}
+}
+
+// TxGet returns the user for the provided user ID. The returned user only
+// contains the plugin data that is owned by the plugin.
+//
+// This function satisfies the plugin UserDB interface.
+func (d *pluginUserDB) TxGet(tx *sql.Tx, userID string) (*plugin.User, error) {
+	var (
+		u   *user.User
+		err error
+	)
+	if tx == nil {
+		// The read commands are not executed
+		// using a database transaction.
+		u, err = d.userDB.Get(userID)
+	} else {
+		u, err = d.userDB.TxGet(tx, userID)
+	}
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return nil, plugin.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return convertUser(u, d.pluginID), nil
+}
+
+// TxUpdate saves any updates that were made to the plugin data of the
+// provided user.
+//
+// This function satisfies the plugin UserDB interface.
+func (d *pluginUserDB) TxUpdate(tx *sql.Tx, pu plugin.User) error {
+	u, err := d.userDB.TxGet(tx, pu.ID.String())
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return plugin.ErrUserNotFound
+		}
+		return err
+	}
+	updateUser(u, &pu, d.pluginID)
+	if !u.Updated {
+		// Nothing to update
+		return nil
+	}
+	return d.userDB.TxUpdate(tx, *u)
+}
+
 // parsePluginSetting parses a plugin setting. Plugin settings will be in
 // following format. The value may be a single value or an array of values.
 //
@@ -0,0 +1,588 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/plugins/comments"
+	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	lcomments "github.com/decred/politeia/politeiawww/legacy/comments"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+)
+
+// cmdPolicy returns the comments policy.
+func (p *commentsPlugin) cmdPolicy(ctx context.Context) (*plugin.Reply, error) {
+	pol, err := p.policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newReply(pol)
+}
+
+// cmdNew adds a new comment to a record.
+func (p *commentsPlugin) cmdNew(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var n v1.New
+	err := decodePayload(args.Cmd.Payload, &n)
+	if err != nil {
+		return nil, err
+	}
+	u := args.User
+
+	// Verify that the user signed using their active identity
+	err = p.identityVerify(tx, u.ID.String(), n.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify state
+	state := convertStateToPlugin(n.State)
+	if state == comments.RecordStateInvalid {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordStateInvalid,
+		}
+	}
+
+	// Only admins and the record authors are allowed to comment on
+	// unvetted records.
+	if n.State == v1.RecordStateUnvetted && !isAdmin(u) {
+		// User is not an admin. Check if the user is an author.
+		isAuthor, err := p.isRecordAuthor(ctx, n.Token, u.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		if !isAuthor {
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeUnauthorized,
+				ErrorContext: "user is not author or admin",
+			}
+		}
+	}
+
+	// Send plugin command. The signature is verified by
+	// the politeiad comments plugin.
+	cn := comments.New{
+		UserID:        u.ID.String(),
+		State:         state,
+		Token:         n.Token,
+		ParentID:      n.ParentID,
+		Comment:       n.Comment,
+		PublicKey:     n.PublicKey,
+		Signature:     n.Signature,
+		ExtraData:     n.ExtraData,
+		ExtraDataHint: n.ExtraDataHint,
+	}
+	pdc, err := p.politeiad.CommentNew(ctx, cn)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Comment submitted: %v %v", pdc.Token, pdc.CommentID)
+
+	// Prepare reply
+	username, err := p.username(tx, pdc.UserID)
+	if err != nil {
+		return nil, err
+	}
+	cm := convertComment(*pdc, username)
+
+	// Emit the legacy event
+	p.events.Emit(lcomments.EventTypeNew,
+		lcomments.EventNew{
+			State:   n.State,
+			Comment: cm,
+		})
+
+	return newReply(v1.NewReply{
+		Comment: cm,
+	})
+}
+
+// cmdEdit edits a comment.
+func (p *commentsPlugin) cmdEdit(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var e v1.Edit
+	err := decodePayload(args.Cmd.Payload, &e)
+	if err != nil {
+		return nil, err
+	}
+	u := args.User
+
+	// Verify that the user signed using their active identity
+	err = p.identityVerify(tx, u.ID.String(), e.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify state
+	state := convertStateToPlugin(e.State)
+	if state == comments.RecordStateInvalid {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordStateInvalid,
+		}
+	}
+
+	// Ensure that the user ID of the logged in user is identical
+	// to the user ID included in the edit payload.
+	if u.ID.String() != e.UserID {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeUnauthorized,
+			ErrorContext: "user is not comment author",
+		}
+	}
+
+	// Send plugin command
+	ce := comments.Edit{
+		UserID:        u.ID.String(),
+		State:         state,
+		Token:         e.Token,
+		ParentID:      e.ParentID,
+		CommentID:     e.CommentID,
+		Comment:       e.Comment,
+		PublicKey:     e.PublicKey,
+		Signature:     e.Signature,
+		ExtraData:     e.ExtraData,
+		ExtraDataHint: e.ExtraDataHint,
+	}
+	pdc, err := p.politeiad.CommentEdit(ctx, ce)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Comment edited: %v %v", pdc.Token, pdc.CommentID)
+
+	username, err := p.username(tx, pdc.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.EditReply{
+		Comment: convertComment(*pdc, username),
+	})
+}
+
+// cmdVote casts a comment vote on a comment.
+func (p *commentsPlugin) cmdVote(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var v v1.Vote
+	err := decodePayload(args.Cmd.Payload, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify that the user signed using their active identity
+	err = p.identityVerify(tx, args.User.ID.String(), v.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify state
+	state := convertStateToPlugin(v.State)
+	if state == comments.RecordStateInvalid {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordStateInvalid,
+		}
+	}
+
+	// Votes are only allowed on vetted records
+	if v.State != v1.RecordStateVetted {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeRecordStateInvalid,
+			ErrorContext: "comment voting is only allowed on vetted records",
+		}
+	}
+
+	// Send plugin command
+	cv := comments.Vote{
+		UserID:    args.User.ID.String(),
+		State:     state,
+		Token:     v.Token,
+		CommentID: v.CommentID,
+		Vote:      comments.VoteT(v.Vote),
+		PublicKey: v.PublicKey,
+		Signature: v.Signature,
+	}
+	vr, err := p.politeiad.CommentVote(ctx, cv)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.VoteReply{
+		Downvotes: vr.Downvotes,
+		Upvotes:   vr.Upvotes,
+		Timestamp: vr.Timestamp,
+		Receipt:   vr.Receipt,
+	})
+}
+
+// cmdDel permanently deletes the provided comment.
+func (p *commentsPlugin) cmdDel(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var d v1.Del
+	err := decodePayload(args.Cmd.Payload, &d)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify that the admin signed using their active identity
+	err = p.identityVerify(tx, args.User.ID.String(), d.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify state
+	state := convertStateToPlugin(d.State)
+	if state == comments.RecordStateInvalid {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordStateInvalid,
+		}
+	}
+
+	// Send plugin command
+	cd := comments.Del{
+		State:     state,
+		Token:     d.Token,
+		CommentID: d.CommentID,
+		Reason:    d.Reason,
+		PublicKey: d.PublicKey,
+		Signature: d.Signature,
+	}
+	cdr, err := p.politeiad.CommentDel(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Comment deleted: %v %v", d.Token, d.CommentID)
+
+	username, err := p.username(tx, cdr.Comment.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.DelReply{
+		Comment: convertComment(cdr.Comment, username),
+	})
+}
+
+// cmdCount returns the number of comments on each of the provided records.
+func (p *commentsPlugin) cmdCount(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var ct v1.Count
+	err := decodePayload(args.Cmd.Payload, &ct)
+	if err != nil {
+		return nil, err
+	}
+	pol, err := p.policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify size of request
+	switch {
+	case len(ct.Tokens) == 0:
+		// Nothing to do
+		return newReply(v1.CountReply{
+			Counts: map[string]uint32{},
+		})
+
+	case len(ct.Tokens) > int(pol.CountPageSize):
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePageSizeExceeded,
+			ErrorContext: fmt.Sprintf("max page size is %v",
+				pol.CountPageSize),
+		}
+	}
+
+	// Get comment counts
+	counts, err := p.politeiad.CommentCount(ctx, ct.Tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.CountReply{
+		Counts: counts,
+	})
+}
+
+// cmdComments returns all comments on a record.
+func (p *commentsPlugin) cmdComments(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var cs v1.Comments
+	err := decodePayload(args.Cmd.Payload, &cs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send plugin command
+	pcomments, err := p.politeiad.CommentsGetAll(ctx, cs.Token)
+	if err != nil {
+		return nil, err
+	}
+	if len(pcomments) == 0 {
+		return newReply(v1.CommentsReply{
+			Comments: []v1.Comment{},
+		})
+	}
+
+	// Only admins and the record authors are allowed to retrieve
+	// unvetted comments. This is a public command so a user may
+	// not exist.
+	if pcomments[0].State == comments.RecordStateUnvetted {
+		u := args.User
+		isAllowed := isAdmin(u)
+		if !isAllowed && u != nil {
+			isAllowed, err = p.isRecordAuthor(ctx, cs.Token, u.ID.String())
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !isAllowed {
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeUnauthorized,
+				ErrorContext: "user is not author or admin",
+			}
+		}
+	}
+
+	// Populate the usernames. The read commands are not executed
+	// using a database transaction.
+	userIDs := make([]string, 0, len(pcomments))
+	for _, v := range pcomments {
+		userIDs = append(userIDs, v.UserID)
+	}
+	usernames, err := p.usernames(nil, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	cms := make([]v1.Comment, 0, len(pcomments))
+	for _, v := range pcomments {
+		cms = append(cms, convertComment(v, usernames[v.UserID]))
+	}
+
+	return newReply(v1.CommentsReply{
+		Comments: cms,
+	})
+}
+
+// cmdVotes returns the comment votes that meet the provided filtering
+// criteria.
+func (p *commentsPlugin) cmdVotes(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var v v1.Votes
+	err := decodePayload(args.Cmd.Payload, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get comment votes. Votes are only allowed on vetted comments so
+	// there is no need to check the user permissions since all vetted
+	// comments are public.
+	cm := comments.Votes{
+		UserID: v.UserID,
+		Page:   v.Page,
+	}
+	votes, err := p.politeiad.CommentVotes(ctx, v.Token, cm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Populate the usernames. The read commands are not executed
+	// using a database transaction.
+	userIDs := make([]string, 0, len(votes))
+	for _, v := range votes {
+		userIDs = append(userIDs, v.UserID)
+	}
+	usernames, err := p.usernames(nil, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.VotesReply{
+		Votes: convertCommentVotes(votes, usernames),
+	})
+}
+
+// cmdTimestamps returns the timestamps for the provided comments.
+func (p *commentsPlugin) cmdTimestamps(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var t v1.Timestamps
+	err := decodePayload(args.Cmd.Payload, &t)
+	if err != nil {
+		return nil, err
+	}
+	pol, err := p.policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify size of request
+	switch {
+	case len(t.CommentIDs) == 0:
+		// Nothing to do
+		return newReply(v1.TimestampsReply{
+			Comments: map[uint32]v1.CommentTimestamp{},
+		})
+
+	case len(t.CommentIDs) > int(pol.TimestampsPageSize):
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePageSizeExceeded,
+			ErrorContext: fmt.Sprintf("max page size is %v",
+				pol.TimestampsPageSize),
+		}
+	}
+
+	// Get record state
+	r, err := p.recordNoFiles(ctx, t.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get timestamps
+	ct := comments.Timestamps{
+		CommentIDs: t.CommentIDs,
+	}
+	ctr, err := p.politeiad.CommentTimestamps(ctx, t.Token, ct)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare reply
+	var (
+		cts = make(map[uint32]v1.CommentTimestamp, len(ctr.Comments))
+
+		// Unvetted data payloads are removed from the timestamp if the
+		// user is not an admin.
+		rmPayloads = (r.State == pdv2.RecordStateUnvetted) &&
+			!isAdmin(args.User)
+	)
+	for commentID, ct := range ctr.Comments {
+		adds := make([]v1.Timestamp, 0, len(ct.Adds))
+		for _, ts := range ct.Adds {
+			if rmPayloads {
+				ts.Data = ""
+			}
+			adds = append(adds, convertTimestamp(ts))
+		}
+
+		var del *v1.Timestamp
+		if ct.Del != nil {
+			if rmPayloads {
+				ct.Del.Data = ""
+			}
+			d := convertTimestamp(*ct.Del)
+			del = &d
+		}
+
+		cts[commentID] = v1.CommentTimestamp{
+			Adds: adds,
+			Del:  del,
+		}
+	}
+
+	return newReply(v1.TimestampsReply{
+		Comments: cts,
+	})
+}
+
+// recordNoFiles returns a politeiad record without any of its files. This
+// allows the call to be light weight but still return metadata about the
+// record such as state and status. A user error is returned if the record is
+// not found.
+func (p *commentsPlugin) recordNoFiles(ctx context.Context, token string) (*pdv2.Record, error) {
+	req := []pdv2.RecordRequest{
+		{
+			Token:        token,
+			OmitAllFiles: true,
+		},
+	}
+	records, err := p.politeiad.Records(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := records[token]
+	if !ok {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordNotFound,
+		}
+	}
+
+	return &r, nil
+}
+
+// isRecordAuthor returns whether the provided user ID is one of the authors of
+// the record.
+func (p *commentsPlugin) isRecordAuthor(ctx context.Context, token, userID string) (bool, error) {
+	authorIDs, err := p.politeiad.Authors(ctx, token)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range authorIDs {
+		if v == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// identityVerify verifies that the provided public key is the active identity
+// of the provided user. A user error is returned if it is not.
+func (p *commentsPlugin) identityVerify(tx *sql.Tx, userID, publicKey string) error {
+	if p.identities == nil {
+		return errors.New("user identities are not available")
+	}
+	pk, err := p.identities.ActiveIdentity(tx, userID)
+	if err != nil {
+		if errors.Is(err, plugin.ErrIdentityNotFound) {
+			return v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodePublicKeyInvalid,
+				ErrorContext: fmt.Sprintf("user %v does not have an "+
+					"active identity", userID),
+			}
+		}
+		return err
+	}
+	if pk != publicKey {
+		return v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePublicKeyInvalid,
+			ErrorContext: fmt.Sprintf("user %v did not sign with "+
+				"their active identity", userID),
+		}
+	}
+	return nil
+}
+
+// username returns the username of the provided user.
+func (p *commentsPlugin) username(tx *sql.Tx, userID string) (string, error) {
+	usernames, err := p.usernames(tx, []string{userID})
+	if err != nil {
+		return "", err
+	}
+	return usernames[userID], nil
+}
+
+// usernames returns the usernames of the provided users. Usernames are owned
+// by the user manager and are omitted if the user identities have not been
+// provided to the plugin. Users that are not found in the user database, such
+// as the authors of comments that were imported from a legacy database, are
+// also omitted.
+func (p *commentsPlugin) usernames(tx *sql.Tx, userIDs []string) (map[string]string, error) {
+	usernames := make(map[string]string, len(userIDs)) // [userID]username
+	if p.identities == nil {
+		return usernames, nil
+	}
+	for _, userID := range userIDs {
+		if _, ok := usernames[userID]; ok {
+			// Already retrieved
+			continue
+		}
+		username, err := p.identities.Username(tx, userID)
+		if err != nil {
+			if !errors.Is(err, plugin.ErrUserNotFound) {
+				return nil, err
+			}
+			username = ""
+		}
+		usernames[userID] = username
+	}
+	return usernames, nil
+}
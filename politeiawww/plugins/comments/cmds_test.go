@@ -0,0 +1,105 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+)
+
+// testIdentities implements the plugin Identities interface using an in
+// memory map of user IDs to their usernames. The active public key of each
+// user is their username.
+type testIdentities map[string]string
+
+func (t testIdentities) ActiveIdentity(tx *sql.Tx, userID string) (string, error) {
+	username, ok := t[userID]
+	if !ok {
+		return "", plugin.ErrIdentityNotFound
+	}
+	return username, nil
+}
+
+func (t testIdentities) Username(tx *sql.Tx, userID string) (string, error) {
+	username, ok := t[userID]
+	if !ok {
+		return "", plugin.ErrUserNotFound
+	}
+	return username, nil
+}
+
+func TestIdentityVerify(t *testing.T) {
+	p := &commentsPlugin{
+		identities: testIdentities{
+			"user": "active",
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		userID    string
+		publicKey string
+		wantErr   bool
+	}{
+		{"active identity", "user", "active", false},
+		{"inactive identity", "user", "other", true},
+		{"another user's identity", "user2", "active", true},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := p.identityVerify(nil, v.userID, v.publicKey)
+			if !v.wantErr {
+				if err != nil {
+					t.Errorf("got error %v, want nil", err)
+				}
+				return
+			}
+			var ue v1.UserErrorReply
+			if !errors.As(err, &ue) ||
+				ue.ErrorCode != v1.ErrorCodePublicKeyInvalid {
+				t.Errorf("got error %v, want public key invalid", err)
+			}
+		})
+	}
+}
+
+func TestUsernames(t *testing.T) {
+	p := &commentsPlugin{
+		identities: testIdentities{
+			"user1": "alice",
+			"user2": "bob",
+		},
+	}
+
+	// Duplicate and unknown users are allowed. The usernames of
+	// unknown users are left blank.
+	got, err := p.usernames(nil, []string{"user1", "user2", "user1", "user3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"user1": "alice",
+		"user2": "bob",
+		"user3": "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The usernames are omitted without the identities
+	p.identities = nil
+	got, err = p.usernames(nil, []string{"user1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %v, want no usernames", got)
+	}
+}
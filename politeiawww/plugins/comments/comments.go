@@ -0,0 +1,270 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package comments provides a politeiawww plugin that implements the comments
+// API. The plugin commands are proxied to the politeiad comments plugin.
+package comments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"time"
+
+	pdclient "github.com/decred/politeia/politeiad/client"
+	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	"github.com/decred/politeia/politeiawww/events"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/decred/politeia/politeiawww/plugins/pdutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// pdTimeout is the timeout for the politeiad requests that are made
+	// during the execution of a single plugin command.
+	pdTimeout = 1 * time.Minute
+)
+
+var (
+	_ plugin.Plugin = (*commentsPlugin)(nil)
+)
+
+func init() {
+	plugin.RegisterPluginInitFn(v1.PluginID,
+		func(args plugin.InitArgs) (plugin.Plugin, error) {
+			return New(args)
+		})
+}
+
+// commentsPlugin implements the politeiawww plugin interface.
+type commentsPlugin struct {
+	sync.RWMutex
+	politeiad  *pdclient.Client
+	identities plugin.Identities
+	events     *events.Manager
+
+	// permissions contains the user permission level for each of the
+	// plugin commands.
+	permissions map[string]string // [cmd]permissionLevel
+
+	// pol is the comments policy. It is derived from the politeiad
+	// comments plugin settings and is lazy loaded by policy().
+	pol *v1.PolicyReply
+}
+
+// ID returns the plugin ID.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *commentsPlugin) ID() string {
+	return v1.PluginID
+}
+
+// Version returns the lowest supported plugin API version.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *commentsPlugin) Version() uint32 {
+	return v1.Version
+}
+
+// SetPermission sets the user permission level for a command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *commentsPlugin) SetPermission(cmd, permissionLevel string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.permissions[cmd] = permissionLevel
+}
+
+// Permissions returns the user permission level for each of the plugin
+// commands.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *commentsPlugin) Permissions() map[string]string {
+	p.RLock()
+	defer p.RUnlock()
+
+	perms := make(map[string]string, len(p.permissions))
+	for k, v := range p.permissions {
+		perms[k] = v
+	}
+	return perms
+}
+
+// Hook executes a plugin hook.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *commentsPlugin) Hook(h plugin.HookArgs) error {
+	log.Tracef("Hook: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// HookTx executes a plugin hook using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *commentsPlugin) HookTx(tx *sql.Tx, h plugin.HookArgs) error {
+	log.Tracef("HookTx: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// WriteTx executes a write plugin command using a database transaction.
+//
+// The comments are saved to politeiad. The database transaction is only used
+// to read the identities of the users.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *commentsPlugin) WriteTx(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	log.Tracef("WriteTx: %v", args.Cmd.Cmd)
+
+	if args.User == nil {
+		// Should not happen. The permission level of all
+		// write commands requires a logged in user.
+		return nil, errors.Errorf("user not provided")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pdTimeout)
+	defer cancel()
+
+	var (
+		reply *plugin.Reply
+		err   error
+	)
+	switch args.Cmd.Cmd {
+	case v1.CmdNew:
+		reply, err = p.cmdNew(ctx, tx, args)
+	case v1.CmdEdit:
+		reply, err = p.cmdEdit(ctx, tx, args)
+	case v1.CmdVote:
+		reply, err = p.cmdVote(ctx, tx, args)
+	case v1.CmdDel:
+		reply, err = p.cmdDel(ctx, tx, args)
+	default:
+		err = v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "invalid write cmd " + args.Cmd.Cmd,
+		}
+	}
+	if err != nil {
+		return pdutil.ReplyFromError(convertError(err))
+	}
+
+	return reply, nil
+}
+
+// Read executes a read plugin command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *commentsPlugin) Read(args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("Read: %v", args.Cmd.Cmd)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pdTimeout)
+	defer cancel()
+
+	var (
+		reply *plugin.Reply
+		err   error
+	)
+	switch args.Cmd.Cmd {
+	case v1.CmdPolicy:
+		reply, err = p.cmdPolicy(ctx)
+	case v1.CmdCount:
+		reply, err = p.cmdCount(ctx, args)
+	case v1.CmdComments:
+		reply, err = p.cmdComments(ctx, args)
+	case v1.CmdVotes:
+		reply, err = p.cmdVotes(ctx, args)
+	case v1.CmdTimestamps:
+		reply, err = p.cmdTimestamps(ctx, args)
+	default:
+		err = v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "invalid read cmd " + args.Cmd.Cmd,
+		}
+	}
+	if err != nil {
+		return pdutil.ReplyFromError(convertError(err))
+	}
+
+	return reply, nil
+}
+
+// ReadTx executes a read plugin command using a database transaction.
+//
+// The comments are read from politeiad. The database transaction is not used.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *commentsPlugin) ReadTx(tx *sql.Tx, args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("ReadTx: %v", args.Cmd.Cmd)
+
+	return p.Read(args)
+}
+
+// New returns a new commentsPlugin.
+func New(args plugin.InitArgs) (*commentsPlugin, error) {
+	if args.Politeiad == nil {
+		return nil, errors.Errorf("politeiad client not provided")
+	}
+	for _, v := range args.Settings {
+		return nil, errors.Errorf("invalid plugin setting: %v", v.Key)
+	}
+	if args.Events == nil {
+		return nil, errors.Errorf("events manager not provided")
+	}
+	if args.Identities == nil {
+		// The write commands verify that the user signed the
+		// payload using their active identity. They will error
+		// since the identities are not available.
+		log.Warnf("User identities not provided; the write " +
+			"commands are disabled")
+	}
+
+	return &commentsPlugin{
+		politeiad:  args.Politeiad,
+		identities: args.Identities,
+		events:     args.Events,
+		permissions: map[string]string{
+			v1.CmdPolicy:     plugin.PermissionPublic,
+			v1.CmdNew:        plugin.PermissionUser,
+			v1.CmdEdit:       plugin.PermissionUser,
+			v1.CmdVote:       plugin.PermissionUser,
+			v1.CmdDel:        plugin.PermissionAdmin,
+			v1.CmdCount:      plugin.PermissionPublic,
+			v1.CmdComments:   plugin.PermissionPublic,
+			v1.CmdVotes:      plugin.PermissionPublic,
+			v1.CmdTimestamps: plugin.PermissionPublic,
+		},
+	}, nil
+}
+
+// isAdmin returns whether the user has been granted admin permissions. This
+// is used by public commands where a user may not exist.
+func isAdmin(u *plugin.User) bool {
+	return u != nil && u.HasPermission(plugin.PermissionAdmin)
+}
+
+// decodePayload decodes the JSON encoded command payload into the provided
+// interface. A user error is returned if the payload is invalid.
+func decodePayload(payload string, v interface{}) error {
+	err := json.Unmarshal([]byte(payload), v)
+	if err != nil {
+		return v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+		}
+	}
+	return nil
+}
+
+// newReply returns a plugin reply that contains the JSON encoded payload.
+func newReply(payload interface{}) (*plugin.Reply, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.Reply{
+		Payload: string(b),
+	}, nil
+}
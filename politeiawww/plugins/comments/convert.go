@@ -0,0 +1,97 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"github.com/decred/politeia/politeiad/plugins/comments"
+	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+)
+
+func convertStateToPlugin(s v1.RecordStateT) comments.RecordStateT {
+	switch s {
+	case v1.RecordStateUnvetted:
+		return comments.RecordStateUnvetted
+	case v1.RecordStateVetted:
+		return comments.RecordStateVetted
+	}
+	return comments.RecordStateInvalid
+}
+
+func convertStateToV1(s comments.RecordStateT) v1.RecordStateT {
+	switch s {
+	case comments.RecordStateUnvetted:
+		return v1.RecordStateUnvetted
+	case comments.RecordStateVetted:
+		return v1.RecordStateVetted
+	}
+	return v1.RecordStateInvalid
+}
+
+func convertComment(c comments.Comment, username string) v1.Comment {
+	return v1.Comment{
+		UserID:        c.UserID,
+		Username:      username,
+		State:         convertStateToV1(c.State),
+		Token:         c.Token,
+		ParentID:      c.ParentID,
+		Comment:       c.Comment,
+		PublicKey:     c.PublicKey,
+		Signature:     c.Signature,
+		CommentID:     c.CommentID,
+		Version:       c.Version,
+		CreatedAt:     c.CreatedAt,
+		Timestamp:     c.Timestamp,
+		Receipt:       c.Receipt,
+		Downvotes:     c.Downvotes,
+		Upvotes:       c.Upvotes,
+		Deleted:       c.Deleted,
+		Reason:        c.Reason,
+		ExtraData:     c.ExtraData,
+		ExtraDataHint: c.ExtraDataHint,
+	}
+}
+
+func convertCommentVotes(cv []comments.CommentVote, usernames map[string]string) []v1.CommentVote {
+	c := make([]v1.CommentVote, 0, len(cv))
+	for _, v := range cv {
+		c = append(c, v1.CommentVote{
+			UserID:    v.UserID,
+			Username:  usernames[v.UserID],
+			Token:     v.Token,
+			State:     convertStateToV1(v.State),
+			CommentID: v.CommentID,
+			Vote:      v1.VoteT(v.Vote),
+			PublicKey: v.PublicKey,
+			Signature: v.Signature,
+			Timestamp: v.Timestamp,
+			Receipt:   v.Receipt,
+		})
+	}
+	return c
+}
+
+func convertProof(p comments.Proof) v1.Proof {
+	return v1.Proof{
+		Type:       p.Type,
+		Digest:     p.Digest,
+		MerkleRoot: p.MerkleRoot,
+		MerklePath: p.MerklePath,
+		ExtraData:  p.ExtraData,
+	}
+}
+
+func convertTimestamp(t comments.Timestamp) v1.Timestamp {
+	proofs := make([]v1.Proof, 0, len(t.Proofs))
+	for _, v := range t.Proofs {
+		proofs = append(proofs, convertProof(v))
+	}
+	return v1.Timestamp{
+		Data:       t.Data,
+		Digest:     t.Digest,
+		TxID:       t.TxID,
+		MerkleRoot: t.MerkleRoot,
+		Proofs:     proofs,
+	}
+}
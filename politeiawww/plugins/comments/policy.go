@@ -0,0 +1,124 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"context"
+	"strconv"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/plugins/comments"
+	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+	"github.com/decred/politeia/politeiawww/plugins/pdutil"
+	"github.com/pkg/errors"
+)
+
+// policy returns the comments policy. The policy is derived from the settings
+// of the politeiad comments plugin, which are retrieved from politeiad the
+// first time that this function is called.
+func (p *commentsPlugin) policy(ctx context.Context) (*v1.PolicyReply, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.pol != nil {
+		return p.pol, nil
+	}
+	settings, err := pdutil.PluginSettings(ctx, p.politeiad, comments.PluginID)
+	if err != nil {
+		return nil, err
+	}
+	pol, err := parsePolicy(settings)
+	if err != nil {
+		return nil, err
+	}
+	p.pol = pol
+
+	return pol, nil
+}
+
+// parsePolicy parses the comments policy from the politeiad comments plugin
+// settings.
+func parsePolicy(settings []pdv2.PluginSetting) (*v1.PolicyReply, error) {
+	var (
+		lengthMax          uint32
+		voteChangesMax     uint32
+		allowExtraData     bool
+		votesPageSize      uint32
+		countPageSize      uint32
+		timestampsPageSize uint32
+		allowEdits         bool
+		editPeriod         uint32
+	)
+	for _, v := range settings {
+		var err error
+		switch v.Key {
+		case comments.SettingKeyCommentLengthMax:
+			lengthMax, err = parseUint32(v.Value)
+		case comments.SettingKeyVoteChangesMax:
+			voteChangesMax, err = parseUint32(v.Value)
+		case comments.SettingKeyAllowExtraData:
+			allowExtraData, err = strconv.ParseBool(v.Value)
+		case comments.SettingKeyVotesPageSize:
+			votesPageSize, err = parseUint32(v.Value)
+		case comments.SettingKeyCountPageSize:
+			countPageSize, err = parseUint32(v.Value)
+		case comments.SettingKeyTimestampsPageSize:
+			timestampsPageSize, err = parseUint32(v.Value)
+		case comments.SettingKeyAllowEdits:
+			allowEdits, err = strconv.ParseBool(v.Value)
+		case comments.SettingKeyEditPeriod:
+			editPeriod, err = parseUint32(v.Value)
+		default:
+			// Skip unknown settings
+			log.Warnf("Unknown politeiad plugin setting %v; Skipping...", v.Key)
+		}
+		if err != nil {
+			return nil, errors.Errorf("invalid politeiad plugin setting "+
+				"%v '%v': %v", v.Key, v.Value, err)
+		}
+	}
+
+	// Verify all plugin settings have been provided
+	switch {
+	case lengthMax == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			comments.SettingKeyCommentLengthMax)
+	case voteChangesMax == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			comments.SettingKeyVoteChangesMax)
+	case votesPageSize == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			comments.SettingKeyVotesPageSize)
+	case countPageSize == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			comments.SettingKeyCountPageSize)
+	case timestampsPageSize == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			comments.SettingKeyTimestampsPageSize)
+	case editPeriod == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			comments.SettingKeyEditPeriod)
+	}
+
+	return &v1.PolicyReply{
+		LengthMax:          lengthMax,
+		VoteChangesMax:     voteChangesMax,
+		AllowExtraData:     allowExtraData,
+		VotesPageSize:      votesPageSize,
+		CountPageSize:      countPageSize,
+		TimestampsPageSize: timestampsPageSize,
+		AllowEdits:         allowEdits,
+		EditPeriod:         editPeriod,
+	}, nil
+}
+
+// parseUint32 parses a uint32 from the provided string.
+func parseUint32(s string) (uint32, error) {
+	u, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(u), nil
+}
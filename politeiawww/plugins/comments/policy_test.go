@@ -0,0 +1,59 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package comments
+
+import (
+	"testing"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/plugins/comments"
+	v1 "github.com/decred/politeia/politeiawww/api/comments/v1"
+)
+
+func TestParsePolicy(t *testing.T) {
+	settings := []pdv2.PluginSetting{
+		{Key: comments.SettingKeyCommentLengthMax, Value: "8000"},
+		{Key: comments.SettingKeyVoteChangesMax, Value: "5"},
+		{Key: comments.SettingKeyAllowExtraData, Value: "true"},
+		{Key: comments.SettingKeyVotesPageSize, Value: "2500"},
+		{Key: comments.SettingKeyCountPageSize, Value: "10"},
+		{Key: comments.SettingKeyTimestampsPageSize, Value: "100"},
+		{Key: comments.SettingKeyAllowEdits, Value: "false"},
+		{Key: comments.SettingKeyEditPeriod, Value: "300"},
+	}
+
+	// Valid settings
+	pol, err := parsePolicy(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := v1.PolicyReply{
+		LengthMax:          8000,
+		VoteChangesMax:     5,
+		AllowExtraData:     true,
+		VotesPageSize:      2500,
+		CountPageSize:      10,
+		TimestampsPageSize: 100,
+		AllowEdits:         false,
+		EditPeriod:         300,
+	}
+	if *pol != want {
+		t.Errorf("got %+v, want %+v", *pol, want)
+	}
+
+	// Missing setting
+	_, err = parsePolicy(settings[1:])
+	if err == nil {
+		t.Errorf("got nil error for missing setting")
+	}
+
+	// Invalid setting value
+	invalid := append([]pdv2.PluginSetting{}, settings...)
+	invalid[0].Value = "-1"
+	_, err = parsePolicy(invalid)
+	if err == nil {
+		t.Errorf("got nil error for invalid setting")
+	}
+}
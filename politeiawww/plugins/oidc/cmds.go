@@ -0,0 +1,385 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/oidc/v1"
+	"github.com/decred/politeia/util"
+	"github.com/pkg/errors"
+)
+
+// cmdAuthURL creates a new authorization request and returns the provider
+// authorization URL that the user must be redirected to. The authorization
+// request is bound to the session of the caller and can only be completed
+// using the same session.
+func (p *oidcPlugin) cmdAuthURL(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var au v1.AuthURL
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &au)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.Session == nil {
+		return nil, errors.Errorf("session not provided")
+	}
+	if args.Session.ID == "" {
+		return userErrorReply(v1.ErrorCodeInvalidInput,
+			"a session cookie is required"), nil
+	}
+
+	// Create the authorization request. The state is used to look
+	// up the request once the user is redirected back, the nonce
+	// binds the ID token to the request, and the verifier is the
+	// PKCE secret that the authorization code is exchanged with.
+	state, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	authURL, err := p.provider.authCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	err = insertAuthRequest(tx, authRequest{
+		State:     state,
+		SessionID: args.Session.ID,
+		Verifier:  verifier,
+		Nonce:     nonce,
+		ExpiresAt: now + p.authRequestExpiry,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the session so that the session ID is still
+	// valid when the user is redirected back.
+	args.Session.Save = true
+
+	return newReply(v1.AuthURLReply{
+		URL:   authURL,
+		State: state,
+	})
+}
+
+// cmdNewUser creates a new user that is linked to the external identity that
+// authenticated with the provider and logs the user in.
+func (p *oidcPlugin) cmdNewUser(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var nu v1.NewUser
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &nu)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.User == nil {
+		return nil, errors.Errorf("user not provided")
+	}
+	if args.Session == nil {
+		return nil, errors.Errorf("session not provided")
+	}
+
+	// Authenticate the external identity
+	c, e, err := p.authenticate(tx, args.Session.ID, nu.Code, nu.State)
+	if err != nil {
+		return nil, err
+	}
+	if e != nil {
+		return e, nil
+	}
+
+	// Verify that the identity has not already been linked
+	userID, err := userIDByIdentity(tx, c.Issuer, c.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" {
+		return userErrorReply(v1.ErrorCodeIdentityLinked, ""), nil
+	}
+
+	// Setup the user data
+	now := time.Now().Unix()
+	ud := userData{
+		Issuer:      c.Issuer,
+		Subject:     c.Subject,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}
+	us := userSecrets{
+		Email: verifiedEmail(c),
+		Name:  c.Name,
+	}
+	err = encodeUser(args.User.PluginData, ud, us)
+	if err != nil {
+		return nil, err
+	}
+
+	// Link the identity to the user
+	err = insertIdentity(tx, c.Issuer, c.Subject, args.User.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the session
+	args.Session.UserID = args.User.ID.String()
+	args.Session.CreatedAt = now
+
+	log.Infof("New user created %v %v %v", c.Issuer, c.Subject, args.User.ID)
+
+	return newReply(v1.NewUserReply{
+		UserID: args.User.ID.String(),
+	})
+}
+
+// cmdLogin logs a user in by setting the user ID on the session.
+func (p *oidcPlugin) cmdLogin(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var l v1.Login
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &l)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.Session == nil {
+		return nil, errors.Errorf("session not provided")
+	}
+
+	// Authenticate the external identity
+	c, e, err := p.authenticate(tx, args.Session.ID, l.Code, l.State)
+	if err != nil {
+		return nil, err
+	}
+	if e != nil {
+		return e, nil
+	}
+
+	// Get the user that the identity is linked to
+	userID, err := userIDByIdentity(tx, c.Issuer, c.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return userErrorReply(v1.ErrorCodeIdentityNotLinked, ""), nil
+	}
+	u, err := p.userByID(tx, args.User, userID)
+	if err != nil {
+		return nil, err
+	}
+	ud, us, err := decodeUser(u.PluginData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the user. The email and name are refreshed on every
+	// login since they can change at the provider.
+	now := time.Now().Unix()
+	ud.LastLoginAt = now
+	us.Email = verifiedEmail(c)
+	us.Name = c.Name
+	err = p.saveUser(tx, args.User, u, *ud, *us)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the session
+	args.Session.UserID = u.ID.String()
+	args.Session.CreatedAt = now
+
+	log.Infof("User logged in %v %v %v", c.Issuer, c.Subject, u.ID)
+
+	return newReply(v1.LoginReply{
+		UserID: u.ID.String(),
+	})
+}
+
+// cmdLogout logs a user out by instructing the backend to delete the session.
+func (p *oidcPlugin) cmdLogout(args plugin.WriteArgs) (*plugin.Reply, error) {
+	if args.Session == nil {
+		return nil, errors.Errorf("session not provided")
+	}
+	if args.Session.UserID == "" {
+		return userErrorReply(v1.ErrorCodeNotLoggedIn, ""), nil
+	}
+
+	args.Session.Delete = true
+
+	log.Debugf("User logged out %v", args.Session.UserID)
+
+	return newReply(v1.LogoutReply{})
+}
+
+// cmdSetIdentity sets the active identity of the logged in user.
+func (p *oidcPlugin) cmdSetIdentity(args plugin.WriteArgs) (*plugin.Reply, error) {
+	var si v1.SetIdentity
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &si)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.User == nil {
+		return userErrorReply(v1.ErrorCodeNotLoggedIn, ""), nil
+	}
+	ud, us, err := decodeUser(args.User.PluginData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify that the user controls the identity
+	err = util.VerifySignature(si.Signature, si.PublicKey,
+		args.User.ID.String())
+	if err != nil {
+		var se util.SignatureError
+		if errors.As(err, &se) &&
+			se.ErrorCode == util.ErrorStatusPublicKeyInvalid {
+			return userErrorReply(v1.ErrorCodePublicKeyInvalid,
+				se.ErrorContext), nil
+		}
+		return userErrorReply(v1.ErrorCodeSignatureInvalid, ""), nil
+	}
+	if ud.activeIdentity() == si.PublicKey {
+		return userErrorReply(v1.ErrorCodePublicKeyInvalid,
+			"identity is already active"), nil
+	}
+
+	// Update the user. The changes to the plugin data of the
+	// user executing the command are saved by the backend.
+	ud.setIdentity(si.PublicKey, time.Now().Unix())
+	err = encodeUser(args.User.PluginData, *ud, *us)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Identity set %v %v", args.User.ID, si.PublicKey)
+
+	return newReply(v1.SetIdentityReply{})
+}
+
+// cmdMe returns the account details of the logged in user.
+func (p *oidcPlugin) cmdMe(args plugin.ReadArgs) (*plugin.Reply, error) {
+	if args.User == nil {
+		return userErrorReply(v1.ErrorCodeNotLoggedIn, ""), nil
+	}
+	ud, us, err := decodeUser(args.User.PluginData)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.MeReply{
+		UserID:      args.User.ID.String(),
+		Issuer:      ud.Issuer,
+		Subject:     ud.Subject,
+		Email:       us.Email,
+		Name:        us.Name,
+		CreatedAt:   ud.CreatedAt,
+		LastLoginAt: ud.LastLoginAt,
+		PublicKey:   ud.activeIdentity(),
+	})
+}
+
+// authenticate consumes the authorization request for the provided state and
+// exchanges the authorization code with the provider. The verified ID token
+// claims are returned on success. A user error reply is returned if the state,
+// the code, or the ID token is not valid, or if the authorization request was
+// created by a different session.
+func (p *oidcPlugin) authenticate(tx *sql.Tx, sessionID, code, state string) (*claims, *plugin.Reply, error) {
+	if code == "" || state == "" {
+		return nil, userErrorReply(v1.ErrorCodeInvalidInput,
+			"code and state are required"), nil
+	}
+
+	// Get the authorization request
+	r, err := takeAuthRequest(tx, state)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r == nil || r.ExpiresAt <= time.Now().Unix() {
+		return nil, userErrorReply(v1.ErrorCodeStateInvalid, ""), nil
+	}
+	if sessionID == "" || r.SessionID != sessionID {
+		// The authorization request was started by a different
+		// session. Completing it would log this session in to
+		// the account of whoever started the request.
+		return nil, userErrorReply(v1.ErrorCodeStateInvalid,
+			"state was not issued to this session"), nil
+	}
+
+	// Exchange the code
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	c, err := p.provider.exchange(ctx, code, r.Verifier, r.Nonce)
+	if err != nil {
+		var ite idTokenError
+		switch {
+		case errors.Is(err, errCodeRejected):
+			return nil, userErrorReply(v1.ErrorCodeCodeInvalid, ""), nil
+		case errors.As(err, &ite):
+			log.Debugf("ID token verification failed: %v", err)
+			return nil, userErrorReply(v1.ErrorCodeIDTokenInvalid,
+				"%v", ite), nil
+		}
+		return nil, nil, err
+	}
+
+	return c, nil, nil
+}
+
+// userByID returns the user for the provided user ID.
+//
+// The user that is executing the command is returned if the user ID belongs
+// to them. Updates to this user must be made to the provided object, not
+// through the UserDB, otherwise they are overwritten by the backend.
+func (p *oidcPlugin) userByID(tx *sql.Tx, current *plugin.User, userID string) (*plugin.User, error) {
+	if current != nil && current.ID.String() == userID {
+		return current, nil
+	}
+	return p.userDB.TxGet(tx, userID)
+}
+
+// saveUser encodes the user data into the plugin data of the provided user
+// and saves it. The backend saves the changes that are made to the user that
+// is executing the command, so the UserDB is only used for other users.
+func (p *oidcPlugin) saveUser(tx *sql.Tx, current, u *plugin.User, ud userData, us userSecrets) error {
+	err := encodeUser(u.PluginData, ud, us)
+	if err != nil {
+		return err
+	}
+	if u == current {
+		return nil
+	}
+	return p.userDB.TxUpdate(tx, *u)
+}
+
+// verifiedEmail returns the email address from the ID token claims if the
+// provider has verified it. An empty string is returned otherwise.
+func verifiedEmail(c *claims) string {
+	if !c.EmailVerified {
+		return ""
+	}
+	return c.Email
+}
+
+// randomString returns a random base64url encoded string that is suitable for
+// use as the state, the nonce, or the PKCE code verifier.
+func randomString() (string, error) {
+	b, err := util.Random(32)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
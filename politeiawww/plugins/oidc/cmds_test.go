@@ -0,0 +1,70 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/oidc/v1"
+)
+
+func TestAuthenticateSession(t *testing.T) {
+	// The provider is not setup. The tests fail with a panic if the
+	// authorization code is exchanged.
+	p := &oidcPlugin{}
+
+	var tests = []struct {
+		name      string
+		sessionID string // Session that completes the request
+	}{
+		{"different session", "attacker"},
+		{"no session", ""},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			// The authorization request was created by the victim
+			// session.
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT state, session_id`)).
+				WithArgs("state").
+				WillReturnRows(sqlmock.NewRows([]string{"state",
+					"session_id", "verifier", "nonce", "expires_at"}).
+					AddRow("state", "victim", "verifier", "nonce",
+						time.Now().Unix()+60))
+			mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM`)).
+				WithArgs("state").
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, r, err := p.authenticate(tx, v.sessionID, "code", "state")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ue plugin.UserError
+			if r == nil || !errors.As(r.Error, &ue) ||
+				ue.ErrorCode != uint32(v1.ErrorCodeStateInvalid) {
+				t.Errorf("got reply %+v, want state invalid error", r)
+			}
+			err = mock.ExpectationsWereMet()
+			if err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// tableNameAuthRequests is the table name for the auth requests table.
+	tableNameAuthRequests = "oidc_auth_requests"
+
+	// tableNameIdentities is the table name for the identities table.
+	tableNameIdentities = "oidc_identities"
+
+	// opTimeout is the timeout for a single database operation.
+	opTimeout = 1 * time.Minute
+)
+
+// tableAuthRequests defines the auth requests table. It contains the pending
+// authorization requests. An authorization request is bound to the session
+// that created it and is deleted once it has been used or has expired.
+const tableAuthRequests = `
+  state      VARCHAR(64) NOT NULL PRIMARY KEY,
+  session_id VARCHAR(128) NOT NULL,
+  verifier   VARCHAR(128) NOT NULL,
+  nonce      VARCHAR(64) NOT NULL,
+  expires_at BIGINT NOT NULL
+`
+
+// tableIdentities defines the identities table. It links an external
+// identity, which is identified by the issuer and subject of the provider ID
+// tokens, to a politeia user.
+const tableIdentities = `
+  issuer  VARCHAR(255) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  user_id CHAR(36) NOT NULL UNIQUE,
+  PRIMARY KEY (issuer, subject)
+`
+
+// setupTables creates the plugin database tables if they do not already
+// exist.
+func setupTables(db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	tables := []struct {
+		name   string
+		schema string
+	}{
+		{tableNameAuthRequests, tableAuthRequests},
+		{tableNameIdentities, tableIdentities},
+	}
+	for _, t := range tables {
+		q := `CREATE TABLE IF NOT EXISTS ` + t.name + ` (` + t.schema + `)`
+		_, err := db.ExecContext(ctx, q)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		log.Debugf("Created %v database table", t.name)
+	}
+
+	return nil
+}
+
+// authRequest is a pending authorization request.
+type authRequest struct {
+	State     string
+	SessionID string
+	Verifier  string
+	Nonce     string
+	ExpiresAt int64
+}
+
+// insertAuthRequest inserts an authorization request into the auth requests
+// table. Expired authorization requests are deleted at the same time so that
+// the table does not grow unbounded.
+func insertAuthRequest(tx *sql.Tx, r authRequest, now int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	q := `DELETE FROM ` + tableNameAuthRequests + ` WHERE expires_at <= ?`
+	_, err := tx.ExecContext(ctx, q, now)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	q = `INSERT INTO ` + tableNameAuthRequests +
+		` (state, session_id, verifier, nonce, expires_at)` +
+		` VALUES (?, ?, ?, ?, ?)`
+	_, err = tx.ExecContext(ctx, q, r.State, r.SessionID, r.Verifier,
+		r.Nonce, r.ExpiresAt)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// takeAuthRequest returns the authorization request for the provided state
+// and deletes it so that it cannot be used again. A nil authorization request
+// is returned if one is not found.
+func takeAuthRequest(tx *sql.Tx, state string) (*authRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	q := `SELECT state, session_id, verifier, nonce, expires_at FROM ` +
+		tableNameAuthRequests + ` WHERE state = ?`
+	var r authRequest
+	err := tx.QueryRowContext(ctx, q, state).
+		Scan(&r.State, &r.SessionID, &r.Verifier, &r.Nonce, &r.ExpiresAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, nil
+	case err != nil:
+		return nil, errors.WithStack(err)
+	}
+
+	q = `DELETE FROM ` + tableNameAuthRequests + ` WHERE state = ?`
+	_, err = tx.ExecContext(ctx, q, state)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &r, nil
+}
+
+// insertIdentity links an external identity to a politeia user.
+func insertIdentity(tx *sql.Tx, issuer, subject, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	q := `INSERT INTO ` + tableNameIdentities +
+		` (issuer, subject, user_id) VALUES (?, ?, ?)`
+	_, err := tx.ExecContext(ctx, q, issuer, subject, userID)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// userIDByIdentity returns the user ID that an external identity has been
+// linked to. An empty string is returned if the identity has not been linked
+// to a user.
+func userIDByIdentity(tx *sql.Tx, issuer, subject string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	q := `SELECT user_id FROM ` + tableNameIdentities +
+		` WHERE issuer = ? AND subject = ?`
+	var userID string
+	err := tx.QueryRowContext(ctx, q, issuer, subject).Scan(&userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", nil
+	case err != nil:
+		return "", errors.WithStack(err)
+	}
+
+	return userID, nil
+}
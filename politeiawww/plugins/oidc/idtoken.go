@@ -0,0 +1,184 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// clockSkew is the amount of clock skew that is allowed between politeiawww
+// and the provider when verifying the ID token expiration.
+const clockSkew = 1 * time.Minute
+
+// idTokenError is returned when an ID token fails verification.
+type idTokenError struct {
+	reason string
+}
+
+// Error satisfies the error interface.
+func (e idTokenError) Error() string {
+	return e.reason
+}
+
+// newIDTokenError returns a new idTokenError.
+func newIDTokenError(format string, args ...interface{}) idTokenError {
+	return idTokenError{
+		reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// audience is the aud claim of an ID token. The aud claim can be either a
+// single string or an array of strings.
+type audience []string
+
+// UnmarshalJSON satisfies the json.Unmarshaler interface.
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+	var ss []string
+	if err := json.Unmarshal(b, &ss); err != nil {
+		return err
+	}
+	*a = ss
+	return nil
+}
+
+// contains returns whether the audience contains the provided client ID.
+func (a audience) contains(clientID string) bool {
+	for _, v := range a {
+		if v == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// claims contains the ID token claims that are used by the plugin.
+type claims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	AuthorizedBy  string   `json:"azp"`
+	Expiry        int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Name          string   `json:"name"`
+}
+
+// header contains the ID token JOSE header fields that are used by the
+// plugin.
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// verifyIDToken verifies the signature and the claims of a compact serialized
+// ID token and returns the claims. An idTokenError is returned if the ID
+// token fails verification.
+//
+// Only the RS256 and ES256 signing algorithms are supported. The none and
+// HMAC algorithms are never accepted.
+func (p *provider) verifyIDToken(ctx context.Context, rawIDToken, nonce string, now time.Time) (*claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, newIDTokenError("malformed id token")
+	}
+
+	// Decode the header and look up the signing key
+	var h header
+	err := decodeSegment(parts[0], &h)
+	if err != nil {
+		return nil, newIDTokenError("invalid header: %v", err)
+	}
+	pk, err := p.publicKey(ctx, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if pk == nil {
+		return nil, newIDTokenError("signing key not found: %v", h.Kid)
+	}
+
+	// Verify the signature
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, newIDTokenError("invalid signature encoding")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !verifySignature(h.Alg, pk, digest[:], sig) {
+		return nil, newIDTokenError("invalid signature")
+	}
+
+	// Verify the claims
+	var c claims
+	err = decodeSegment(parts[1], &c)
+	if err != nil {
+		return nil, newIDTokenError("invalid claims: %v", err)
+	}
+	switch {
+	case strings.TrimSuffix(c.Issuer, "/") != p.issuer:
+		return nil, newIDTokenError("issuer mismatch: %v", c.Issuer)
+	case !c.Audience.contains(p.clientID):
+		return nil, newIDTokenError("audience mismatch: %v", c.Audience)
+	case len(c.Audience) > 1 && c.AuthorizedBy != p.clientID:
+		return nil, newIDTokenError("authorized party mismatch: %v",
+			c.AuthorizedBy)
+	case now.Add(-clockSkew).Unix() >= c.Expiry:
+		return nil, newIDTokenError("id token expired")
+	case c.Nonce != nonce:
+		return nil, newIDTokenError("nonce mismatch")
+	case c.Subject == "":
+		return nil, newIDTokenError("subject missing")
+	}
+
+	return &c, nil
+}
+
+// verifySignature verifies a JWS signature of the provided digest using the
+// provided algorithm and public key.
+func verifySignature(alg string, pk crypto.PublicKey, digest, sig []byte) bool {
+	switch alg {
+	case "RS256":
+		k, ok := pk.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, sig) == nil
+
+	case "ES256":
+		k, ok := pk.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		return ecdsa.Verify(k, digest, r, s)
+	}
+
+	return false
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a JWT.
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
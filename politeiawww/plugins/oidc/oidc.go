@@ -0,0 +1,333 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package oidc provides a politeiawww user manager plugin that allows users
+// to login using an external OpenID Connect provider.
+//
+// The plugin is a user manager, not an auth manager, since the user manager
+// is the only plugin that is able to create users and update the session of
+// the client.
+package oidc
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/oidc/v1"
+	"github.com/pkg/errors"
+)
+
+var (
+	_ plugin.Plugin      = (*oidcPlugin)(nil)
+	_ plugin.UserManager = (*oidcPlugin)(nil)
+)
+
+func init() {
+	plugin.RegisterPluginInitFn(v1.PluginID,
+		func(args plugin.InitArgs) (plugin.Plugin, error) {
+			return New(args)
+		})
+	plugin.RegisterUserManagerInitFn(v1.PluginID,
+		func(args plugin.InitArgs) (plugin.UserManager, error) {
+			return New(args)
+		})
+}
+
+// oidcPlugin implements the politeiawww plugin and user manager interfaces.
+type oidcPlugin struct {
+	sync.RWMutex
+	db       *sql.DB
+	userDB   plugin.UserDB
+	provider *provider
+
+	// permissions contains the user permission level for each of the
+	// plugin commands.
+	permissions map[string]string // [cmd]permissionLevel
+
+	// Plugin settings
+	authRequestExpiry int64 // In seconds
+}
+
+// ID returns the plugin ID.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *oidcPlugin) ID() string {
+	return v1.PluginID
+}
+
+// Version returns the lowest supported plugin API version.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *oidcPlugin) Version() uint32 {
+	return v1.Version
+}
+
+// SetPermission sets the user permission level for a command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *oidcPlugin) SetPermission(cmd, permissionLevel string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.permissions[cmd] = permissionLevel
+}
+
+// Permissions returns the user permission level for each of the plugin
+// commands.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *oidcPlugin) Permissions() map[string]string {
+	p.RLock()
+	defer p.RUnlock()
+
+	perms := make(map[string]string, len(p.permissions))
+	for k, v := range p.permissions {
+		perms[k] = v
+	}
+	return perms
+}
+
+// Hook executes a plugin hook.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *oidcPlugin) Hook(h plugin.HookArgs) error {
+	log.Tracef("Hook: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// HookTx executes a plugin hook using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *oidcPlugin) HookTx(tx *sql.Tx, h plugin.HookArgs) error {
+	log.Tracef("HookTx: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// NewUser executes a command that results in a new user being added to the
+// database.
+//
+// This function satisfies the plugin.UserManager interface.
+func (p *oidcPlugin) NewUser(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	log.Tracef("NewUser: %v", args.Cmd.Cmd)
+
+	if args.Cmd.Cmd != v1.CmdNewUser {
+		return userErrorReply(v1.ErrorCodeInvalidInput,
+			"invalid new user cmd '%v'", args.Cmd.Cmd), nil
+	}
+
+	return p.cmdNewUser(tx, args)
+}
+
+// ActiveIdentity returns the public key of the active identity of the
+// provided user.
+//
+// This function satisfies the plugin.Identities interface.
+func (p *oidcPlugin) ActiveIdentity(tx *sql.Tx, userID string) (string, error) {
+	u, err := p.userDB.TxGet(tx, userID)
+	if err != nil {
+		return "", err
+	}
+	ud, _, err := decodeUser(u.PluginData)
+	if err != nil {
+		return "", err
+	}
+	pk := ud.activeIdentity()
+	if pk == "" {
+		return "", plugin.ErrIdentityNotFound
+	}
+	return pk, nil
+}
+
+// Username returns the username of the provided user. The oidc users are
+// identified by their external identity and are not assigned usernames, so
+// an empty string is returned for existing users.
+//
+// This function satisfies the plugin.Identities interface.
+func (p *oidcPlugin) Username(tx *sql.Tx, userID string) (string, error) {
+	_, err := p.userDB.TxGet(tx, userID)
+	if err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// WriteTx executes a write plugin command using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *oidcPlugin) WriteTx(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	log.Tracef("WriteTx: %v", args.Cmd.Cmd)
+
+	switch args.Cmd.Cmd {
+	case v1.CmdAuthURL:
+		return p.cmdAuthURL(tx, args)
+	case v1.CmdLogin:
+		return p.cmdLogin(tx, args)
+	case v1.CmdLogout:
+		return p.cmdLogout(args)
+	case v1.CmdSetIdentity:
+		return p.cmdSetIdentity(args)
+	}
+
+	return userErrorReply(v1.ErrorCodeInvalidInput,
+		"invalid write cmd '%v'", args.Cmd.Cmd), nil
+}
+
+// Read executes a read plugin command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *oidcPlugin) Read(args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("Read: %v", args.Cmd.Cmd)
+
+	switch args.Cmd.Cmd {
+	case v1.CmdMe:
+		return p.cmdMe(args)
+	}
+
+	return userErrorReply(v1.ErrorCodeInvalidInput,
+		"invalid read cmd '%v'", args.Cmd.Cmd), nil
+}
+
+// ReadTx executes a read plugin command using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *oidcPlugin) ReadTx(tx *sql.Tx, args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("ReadTx: %v", args.Cmd.Cmd)
+
+	return p.Read(args)
+}
+
+// New returns a new oidcPlugin.
+func New(args plugin.InitArgs) (*oidcPlugin, error) {
+	// Default plugin settings
+	var (
+		issuer            string
+		clientID          string
+		clientSecret      string
+		redirectURL       string
+		scopes            = v1.SettingScopes
+		authRequestExpiry = v1.SettingAuthRequestExpiry
+	)
+
+	// Override defaults with any passed in settings
+	for _, v := range args.Settings {
+		switch v.Key {
+		case v1.SettingKeyIssuer:
+			_, err := url.ParseRequestURI(v.Value)
+			if err != nil {
+				return nil, errors.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+			issuer = v.Value
+			log.Infof("Plugin setting updated: %v %v", v.Key, issuer)
+
+		case v1.SettingKeyClientID:
+			clientID = v.Value
+			log.Infof("Plugin setting updated: %v %v", v.Key, clientID)
+
+		case v1.SettingKeyClientSecret:
+			clientSecret = v.Value
+			log.Infof("Plugin setting updated: %v (redacted)", v.Key)
+
+		case v1.SettingKeyRedirectURL:
+			_, err := url.ParseRequestURI(v.Value)
+			if err != nil {
+				return nil, errors.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+			redirectURL = v.Value
+			log.Infof("Plugin setting updated: %v %v", v.Key, redirectURL)
+
+		case v1.SettingKeyScopes:
+			var s []string
+			err := json.Unmarshal([]byte(v.Value), &s)
+			if err != nil {
+				// Allow a single scope to be provided without
+				// the JSON array formatting.
+				s = []string{v.Value}
+			}
+			scopes = s
+			log.Infof("Plugin setting updated: %v %v", v.Key, scopes)
+
+		case v1.SettingKeyAuthRequestExpiry:
+			i, err := strconv.ParseInt(v.Value, 10, 64)
+			if err != nil || i <= 0 {
+				return nil, errors.Errorf("invalid plugin setting %v '%v'",
+					v.Key, v.Value)
+			}
+			authRequestExpiry = i
+			log.Infof("Plugin setting updated: %v %v",
+				v.Key, authRequestExpiry)
+
+		default:
+			return nil, errors.Errorf("invalid plugin setting: %v", v.Key)
+		}
+	}
+
+	// Verify that the required settings were provided
+	switch {
+	case issuer == "":
+		return nil, errors.Errorf("plugin setting not provided: %v",
+			v1.SettingKeyIssuer)
+	case clientID == "":
+		return nil, errors.Errorf("plugin setting not provided: %v",
+			v1.SettingKeyClientID)
+	case redirectURL == "":
+		return nil, errors.Errorf("plugin setting not provided: %v",
+			v1.SettingKeyRedirectURL)
+	}
+
+	// Setup the database tables
+	if args.DB == nil {
+		return nil, errors.Errorf("database not provided")
+	}
+	err := setupTables(args.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oidcPlugin{
+		db:     args.DB,
+		userDB: args.UserDB,
+		provider: newProvider(issuer, clientID, clientSecret,
+			redirectURL, scopes),
+		permissions: map[string]string{
+			v1.CmdAuthURL:     plugin.PermissionPublic,
+			v1.CmdNewUser:     plugin.PermissionPublic,
+			v1.CmdLogin:       plugin.PermissionPublic,
+			v1.CmdLogout:      plugin.PermissionPublic,
+			v1.CmdSetIdentity: plugin.PermissionUser,
+			v1.CmdMe:          plugin.PermissionUser,
+		},
+		authRequestExpiry: authRequestExpiry,
+	}, nil
+}
+
+// userErrorReply returns a plugin reply that contains a user error.
+func userErrorReply(e v1.ErrorCodeT, format string, args ...interface{}) *plugin.Reply {
+	return &plugin.Reply{
+		Error: plugin.UserError{
+			PluginID:     v1.PluginID,
+			ErrorCode:    uint32(e),
+			ErrorContext: fmt.Sprintf(format, args...),
+		},
+	}
+}
+
+// newReply returns a plugin reply that contains the JSON encoded payload.
+func newReply(payload interface{}) (*plugin.Reply, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.Reply{
+		Payload: string(b),
+	}, nil
+}
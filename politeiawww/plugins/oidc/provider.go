@@ -0,0 +1,313 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// providerTimeout is the timeout for a single request to the provider.
+	providerTimeout = 30 * time.Second
+
+	// discoveryPath is the path of the provider configuration document
+	// relative to the issuer URL.
+	discoveryPath = "/.well-known/openid-configuration"
+)
+
+// errCodeRejected is returned when the provider rejects an authorization
+// code.
+var errCodeRejected = errors.New("authorization code rejected")
+
+// discoveryDoc contains the fields of the provider configuration document
+// that are used by the plugin.
+type discoveryDoc struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// provider is an OpenID Connect provider. The provider configuration and
+// signing keys are fetched lazily and cached.
+type provider struct {
+	sync.Mutex
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	client       *http.Client
+
+	doc  *discoveryDoc
+	keys map[string]crypto.PublicKey // [kid]publicKey
+}
+
+// newProvider returns a new provider. The openid scope is added to the scopes
+// if it was not provided.
+func newProvider(issuer, clientID, clientSecret, redirectURL string, scopes []string) *provider {
+	s := []string{"openid"}
+	for _, v := range scopes {
+		if v != "openid" {
+			s = append(s, v)
+		}
+	}
+	return &provider{
+		issuer:       strings.TrimSuffix(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       s,
+		client: &http.Client{
+			Timeout: providerTimeout,
+		},
+	}
+}
+
+// authCodeURL returns the URL that the user must be redirected to in order to
+// authenticate with the provider. The PKCE code challenge is derived from the
+// provided verifier.
+func (p *provider) authCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	c, err := p.config(ctx)
+	if err != nil {
+		return "", err
+	}
+	return c.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("code_challenge", codeChallenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256")), nil
+}
+
+// exchange exchanges an authorization code for an ID token and returns the
+// verified ID token claims. errCodeRejected is returned if the provider
+// rejects the code and an idTokenError is returned if the ID token fails
+// verification.
+func (p *provider) exchange(ctx context.Context, code, verifier, nonce string) (*claims, error) {
+	c, err := p.config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	t, err := c.Exchange(ctx, code,
+		oauth2.SetAuthURLParam("code_verifier", verifier))
+	if err != nil {
+		var re *oauth2.RetrieveError
+		if errors.As(err, &re) {
+			log.Debugf("Token exchange rejected: %v", err)
+			return nil, errCodeRejected
+		}
+		return nil, errors.WithStack(err)
+	}
+	rawIDToken, ok := t.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, newIDTokenError("id token not returned")
+	}
+
+	return p.verifyIDToken(ctx, rawIDToken, nonce, time.Now())
+}
+
+// config returns the oauth2 config for the provider.
+func (p *provider) config(ctx context.Context) (*oauth2.Config, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  doc.AuthorizationEndpoint,
+			TokenURL: doc.TokenEndpoint,
+		},
+		RedirectURL: p.redirectURL,
+		Scopes:      p.scopes,
+	}, nil
+}
+
+// discover returns the provider configuration document. The document is
+// fetched on first use and cached.
+func (p *provider) discover(ctx context.Context) (*discoveryDoc, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.doc != nil {
+		return p.doc, nil
+	}
+
+	var doc discoveryDoc
+	err := p.getJSON(ctx, p.issuer+discoveryPath, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != p.issuer {
+		return nil, errors.Errorf("provider issuer mismatch: got %v, want %v",
+			doc.Issuer, p.issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" ||
+		doc.JWKSURI == "" {
+		return nil, errors.Errorf("provider configuration incomplete: %+v",
+			doc)
+	}
+	p.doc = &doc
+
+	log.Debugf("Provider configuration discovered: %v", p.issuer)
+
+	return p.doc, nil
+}
+
+// publicKey returns the provider signing key for the provided key ID. The
+// provider keys are fetched again if the key ID is not found in the cache so
+// that key rotations are picked up. A nil key is returned if the key ID is
+// not found.
+func (p *provider) publicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	doc, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	keys, err := p.fetchKeys(ctx, doc.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+
+	return p.keys[kid], nil
+}
+
+// jwk is a JSON web key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchKeys fetches the provider signing keys. Keys that are not signing keys
+// or that use an unsupported key type are skipped.
+func (p *provider) fetchKeys(ctx context.Context, jwksURI string) (map[string]crypto.PublicKey, error) {
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	err := p.getJSON(ctx, jwksURI, &jwks)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pk, err := k.publicKey()
+		if err != nil {
+			log.Debugf("Skipping provider key %v: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = pk
+	}
+	return keys, nil
+}
+
+// publicKey returns the public key that the JSON web key represents. Only RSA
+// keys and P-256 EC keys are supported.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+			return nil, errors.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(exp.Int64()),
+		}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, errors.Errorf("unsupported curve %v", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		pk := &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}
+		if !pk.Curve.IsOnCurve(pk.X, pk.Y) {
+			return nil, errors.Errorf("point not on curve")
+		}
+		return pk, nil
+	}
+
+	return nil, errors.Errorf("unsupported key type %v", k.Kty)
+}
+
+// getJSON performs a GET request to the provided URL and decodes the JSON
+// response body into v.
+func (p *provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	r, err := p.client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return errors.Errorf("%v: unexpected status %v", url, r.Status)
+	}
+	err = json.NewDecoder(r.Body).Decode(v)
+	if err != nil {
+		return errors.Errorf("%v: %v", url, err)
+	}
+
+	return nil
+}
+
+// codeChallenge returns the S256 PKCE code challenge for a code verifier.
+func codeChallenge(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
@@ -0,0 +1,336 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID    = "politeia"
+	testRedirectURL = "https://localhost:3000/oidc/callback"
+	testKeyID       = "key-1"
+)
+
+// testIssuer is a local stand-in OpenID Connect provider. It serves the
+// discovery document, the signing keys, and a token endpoint that issues RS256
+// signed ID tokens for the authorization codes that have been registered with
+// it.
+type testIssuer struct {
+	sync.Mutex
+	*httptest.Server
+	key   *rsa.PrivateKey
+	codes map[string]testAuthCode // [code]authCode
+}
+
+// testAuthCode is an authorization code that has been issued by the test
+// issuer. The claims are the ID token claims that are returned when the code
+// is exchanged.
+type testAuthCode struct {
+	challenge string
+	claims    map[string]interface{}
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ti := &testIssuer{
+		key:   key,
+		codes: make(map[string]testAuthCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(discoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, discoveryDoc{
+			Issuer:                ti.URL,
+			AuthorizationEndpoint: ti.URL + "/authorize",
+			TokenEndpoint:         ti.URL + "/token",
+			JWKSURI:               ti.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		e := big.NewInt(int64(key.PublicKey.E)).Bytes()
+		writeJSON(w, http.StatusOK, map[string][]jwk{
+			"keys": {{
+				Kty: "RSA",
+				Kid: testKeyID,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(e),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", ti.handleToken)
+	ti.Server = httptest.NewServer(mux)
+	t.Cleanup(ti.Close)
+
+	return ti
+}
+
+// handleToken exchanges an authorization code for an ID token. The PKCE code
+// verifier must match the challenge that the code was issued for.
+func (ti *testIssuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest,
+			map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, _, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+	}
+
+	ti.Lock()
+	ac, ok := ti.codes[r.PostForm.Get("code")]
+	delete(ti.codes, r.PostForm.Get("code"))
+	ti.Unlock()
+
+	if !ok || clientID != testClientID ||
+		codeChallenge(r.PostForm.Get("code_verifier")) != ac.challenge {
+		writeJSON(w, http.StatusBadRequest,
+			map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     ti.sign(testKeyID, ac.claims),
+	})
+}
+
+// authorize simulates a user authenticating at the provider using the
+// provided authorization URL. It returns the authorization code and the state
+// that the provider would send to the redirect URL.
+func (ti *testIssuer) authorize(t *testing.T, authURL string, claims map[string]interface{}) (string, string) {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("got code challenge method %v, want S256",
+			q.Get("code_challenge_method"))
+	}
+	if q.Get("client_id") != testClientID {
+		t.Fatalf("got client id %v, want %v", q.Get("client_id"), testClientID)
+	}
+
+	// Use the request nonce unless the test overrides it
+	c := map[string]interface{}{
+		"iss":   ti.URL,
+		"sub":   "subject-1",
+		"aud":   testClientID,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": q.Get("nonce"),
+	}
+	for k, v := range claims {
+		c[k] = v
+	}
+
+	code := base64.RawURLEncoding.EncodeToString([]byte(q.Get("state")))
+	ti.Lock()
+	ti.codes[code] = testAuthCode{
+		challenge: q.Get("code_challenge"),
+		claims:    c,
+	}
+	ti.Unlock()
+
+	return code, q.Get("state")
+}
+
+// sign returns a compact serialized RS256 JWT.
+func (ti *testIssuer) sign(kid string, claims map[string]interface{}) string {
+	h, _ := json.Marshal(header{Alg: "RS256", Kid: kid})
+	c, _ := json.Marshal(claims)
+	s := base64.RawURLEncoding.EncodeToString(h) + "." +
+		base64.RawURLEncoding.EncodeToString(c)
+	digest := sha256.Sum256([]byte(s))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, ti.key, crypto.SHA256, digest[:])
+	return s + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestProviderExchange(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := newProvider(ti.URL, testClientID, "", testRedirectURL,
+		[]string{"email"})
+
+	var tests = []struct {
+		name     string
+		claims   map[string]interface{}
+		verifier string // Overrides the request verifier
+		wantErr  error
+		wantIDT  bool // Want an idTokenError
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:     "wrong verifier",
+			verifier: "wrong-verifier",
+			wantErr:  errCodeRejected,
+		},
+		{
+			name:    "nonce mismatch",
+			claims:  map[string]interface{}{"nonce": "other-nonce"},
+			wantIDT: true,
+		},
+		{
+			name:    "audience mismatch",
+			claims:  map[string]interface{}{"aud": "other-client"},
+			wantIDT: true,
+		},
+		{
+			name: "audience array",
+			claims: map[string]interface{}{
+				"aud": []string{"other-client", testClientID},
+				"azp": testClientID,
+			},
+		},
+		{
+			name: "authorized party mismatch",
+			claims: map[string]interface{}{
+				"aud": []string{"other-client", testClientID},
+				"azp": "other-client",
+			},
+			wantIDT: true,
+		},
+		{
+			name:    "issuer mismatch",
+			claims:  map[string]interface{}{"iss": "https://other.example.com"},
+			wantIDT: true,
+		},
+		{
+			name: "expired",
+			claims: map[string]interface{}{
+				"exp": time.Now().Add(-time.Hour).Unix(),
+			},
+			wantIDT: true,
+		},
+		{
+			name:    "subject missing",
+			claims:  map[string]interface{}{"sub": ""},
+			wantIDT: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			state, _ := randomString()
+			nonce, _ := randomString()
+			verifier, _ := randomString()
+
+			authURL, err := p.authCodeURL(ctx, state, nonce, verifier)
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, gotState := ti.authorize(t, authURL, test.claims)
+			if gotState != state {
+				t.Fatalf("got state %v, want %v", gotState, state)
+			}
+			if test.verifier != "" {
+				verifier = test.verifier
+			}
+
+			c, err := p.exchange(ctx, code, verifier, nonce)
+			var ite idTokenError
+			switch {
+			case test.wantIDT:
+				if !errors.As(err, &ite) {
+					t.Fatalf("got err %v, want idTokenError", err)
+				}
+				return
+			case test.wantErr != nil:
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("got err %v, want %v", err, test.wantErr)
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+			if c.Subject != "subject-1" || c.Issuer != ti.URL {
+				t.Fatalf("unexpected claims %+v", c)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenSignature(t *testing.T) {
+	ti := newTestIssuer(t)
+	p := newProvider(ti.URL, testClientID, "", testRedirectURL, nil)
+	claims := map[string]interface{}{
+		"iss":   ti.URL,
+		"sub":   "subject-1",
+		"aud":   testClientID,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": "nonce",
+	}
+	ctx := context.Background()
+
+	// Valid token
+	tok := ti.sign(testKeyID, claims)
+	_, err := p.verifyIDToken(ctx, tok, "nonce", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tampered claims
+	claims["sub"] = "subject-2"
+	forged := strings.Split(ti.sign(testKeyID, claims), ".")
+	tampered := forged[0] + "." + forged[1] + "." + strings.Split(tok, ".")[2]
+	_, err = p.verifyIDToken(ctx, tampered, "nonce", time.Now())
+	var ite idTokenError
+	if !errors.As(err, &ite) {
+		t.Fatalf("tampered: got err %v, want idTokenError", err)
+	}
+
+	// Unknown key ID
+	_, err = p.verifyIDToken(ctx, ti.sign("unknown", claims), "nonce",
+		time.Now())
+	if !errors.As(err, &ite) {
+		t.Fatalf("unknown kid: got err %v, want idTokenError", err)
+	}
+
+	// Unsigned token
+	h, _ := json.Marshal(header{Alg: "none", Kid: testKeyID})
+	c, _ := json.Marshal(claims)
+	unsigned := base64.RawURLEncoding.EncodeToString(h) + "." +
+		base64.RawURLEncoding.EncodeToString(c) + "."
+	_, err = p.verifyIDToken(ctx, unsigned, "nonce", time.Now())
+	if !errors.As(err, &ite) {
+		t.Fatalf("unsigned: got err %v, want idTokenError", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"encoding/json"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+)
+
+// userData contains the user data that is saved to the clear text plugin
+// data of the user.
+type userData struct {
+	Issuer      string `json:"issuer"`
+	Subject     string `json:"subject"`
+	CreatedAt   int64  `json:"createdat"`
+	LastLoginAt int64  `json:"lastloginat"`
+
+	// Identities contains the identities that the user signs payloads
+	// with. These are not related to the external identity. The last
+	// identity is the active identity if it has not been deactivated.
+	Identities []identity `json:"identities,omitempty"`
+}
+
+// identity contains the public key of a user identity.
+type identity struct {
+	PublicKey   string `json:"publickey"`
+	Activated   int64  `json:"activated"`
+	Deactivated int64  `json:"deactivated,omitempty"`
+}
+
+// activeIdentity returns the public key of the active identity. An empty
+// string is returned if the user does not have an active identity.
+func (u *userData) activeIdentity() string {
+	if len(u.Identities) == 0 {
+		return ""
+	}
+	id := u.Identities[len(u.Identities)-1]
+	if id.Deactivated != 0 {
+		return ""
+	}
+	return id.PublicKey
+}
+
+// setIdentity deactivates the active identity and adds the provided public
+// key as the new active identity.
+func (u *userData) setIdentity(publicKey string, now int64) {
+	if len(u.Identities) > 0 {
+		last := &u.Identities[len(u.Identities)-1]
+		if last.Deactivated == 0 {
+			last.Deactivated = now
+		}
+	}
+	u.Identities = append(u.Identities, identity{
+		PublicKey: publicKey,
+		Activated: now,
+	})
+}
+
+// userSecrets contains the user data that is saved to the encrypted plugin
+// data of the user. This data is encrypted at rest by the backend.
+type userSecrets struct {
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// decodeUser decodes the oidc user data from the provided plugin data.
+func decodeUser(pd *plugin.PluginData) (*userData, *userSecrets, error) {
+	var ud userData
+	err := json.Unmarshal(pd.ClearText(), &ud)
+	if err != nil {
+		return nil, nil, err
+	}
+	var us userSecrets
+	err = json.Unmarshal(pd.Encrypted(), &us)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ud, &us, nil
+}
+
+// encodeUser encodes the oidc user data and saves it to the provided plugin
+// data.
+func encodeUser(pd *plugin.PluginData, ud userData, us userSecrets) error {
+	clearText, err := json.Marshal(ud)
+	if err != nil {
+		return err
+	}
+	encrypted, err := json.Marshal(us)
+	if err != nil {
+		return err
+	}
+	pd.SetClearText(clearText)
+	pd.SetEncrypted(encrypted)
+	return nil
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package v1 contains the API for the oidc plugin. The oidc plugin is a
+// politeiawww user manager plugin that allows users to login using an
+// external OpenID Connect provider.
+//
+// The authorization code flow with PKCE is used. A client begins the flow by
+// requesting an authorization URL using the AuthURL command and redirecting
+// the user to it. The provider redirects the user back to the client with an
+// authorization code and the state that was returned in the AuthURLReply. The
+// code and state are then submitted using either the NewUser command, which
+// creates a new politeia user that is linked to the external identity, or the
+// Login command, which logs in the politeia user that the external identity
+// has already been linked to. An authorization code can only be used once.
+//
+// The authorization request is bound to the session cookie of the client that
+// requested the authorization URL. The NewUser and Login commands must be sent
+// using the same session. The session ID is replaced once the user has logged
+// in.
+package v1
+
+const (
+	// PluginID is the unique identifier for this plugin.
+	PluginID = "oidc"
+
+	// Version is the plugin API version.
+	Version uint32 = 1
+)
+
+// Plugin commands. The NewUser command must be executed using the politeiawww
+// NewUser route. The Me command is a read-only command. All other commands
+// are write commands.
+const (
+	// CmdAuthURL command returns the provider authorization URL that the user
+	// must be redirected to.
+	CmdAuthURL = "authurl"
+
+	// CmdNewUser command creates a new user that is linked to an external
+	// identity.
+	CmdNewUser = "newuser"
+
+	// CmdLogin command logs a user in using an external identity.
+	CmdLogin = "login"
+
+	// CmdLogout command logs a user out.
+	CmdLogout = "logout"
+
+	// CmdSetIdentity command sets the active identity of the logged in user.
+	CmdSetIdentity = "setidentity"
+
+	// CmdMe command returns the account details of the logged in user.
+	CmdMe = "me"
+)
+
+// Plugin setting keys can be used to specify custom plugin settings. Default
+// plugin setting values can be overridden by providing a plugin setting key
+// and value to the plugin on startup.
+//
+// The issuer, client ID, and redirect URL settings do not have default values
+// and must be provided.
+const (
+	// SettingKeyIssuer is the plugin setting key for the issuer URL of the
+	// OpenID Connect provider. The provider configuration is discovered using
+	// the issuer URL.
+	SettingKeyIssuer = "issuer"
+
+	// SettingKeyClientID is the plugin setting key for the OAuth2 client ID
+	// that politeia has been registered with at the provider.
+	SettingKeyClientID = "clientid"
+
+	// SettingKeyClientSecret is the plugin setting key for the OAuth2 client
+	// secret. The client secret is optional for public clients since PKCE is
+	// always used.
+	SettingKeyClientSecret = "clientsecret"
+
+	// SettingKeyRedirectURL is the plugin setting key for the URL that the
+	// provider redirects the user to once they have authenticated.
+	SettingKeyRedirectURL = "redirecturl"
+
+	// SettingKeyScopes is the plugin setting key for the SettingScopes plugin
+	// setting.
+	SettingKeyScopes = "scopes"
+
+	// SettingKeyAuthRequestExpiry is the plugin setting key for the
+	// SettingAuthRequestExpiry plugin setting.
+	SettingKeyAuthRequestExpiry = "authrequestexpiry"
+)
+
+// Plugin setting default values. These can be overridden by providing a plugin
+// setting key and value to the plugin on startup.
+var (
+	// SettingScopes contains the default scopes that are requested from the
+	// provider. The openid scope is always requested.
+	SettingScopes = []string{"openid", "email", "profile"}
+)
+
+const (
+	// SettingAuthRequestExpiry is the default number of seconds that a user
+	// has to complete the authorization at the provider.
+	SettingAuthRequestExpiry int64 = 600 // 10 minutes
+)
+
+// ErrorCodeT represents a plugin error that was caused by the user.
+type ErrorCodeT uint32
+
+const (
+	// ErrorCodeInvalid is an invalid error code.
+	ErrorCodeInvalid ErrorCodeT = 0
+
+	// ErrorCodeInvalidInput is returned when the command payload could not be
+	// decoded or the command is not a valid command.
+	ErrorCodeInvalidInput ErrorCodeT = 1
+
+	// ErrorCodeNotLoggedIn is returned when a command that requires a logged
+	// in user is executed without one.
+	ErrorCodeNotLoggedIn ErrorCodeT = 2
+
+	// ErrorCodeStateInvalid is returned when the state does not correspond to
+	// a pending authorization request of the session. The authorization
+	// request may have expired, already been used, or been requested by a
+	// different session.
+	ErrorCodeStateInvalid ErrorCodeT = 3
+
+	// ErrorCodeCodeInvalid is returned when the provider rejects the
+	// authorization code.
+	ErrorCodeCodeInvalid ErrorCodeT = 4
+
+	// ErrorCodeIDTokenInvalid is returned when the ID token that was issued
+	// by the provider fails verification.
+	ErrorCodeIDTokenInvalid ErrorCodeT = 5
+
+	// ErrorCodeIdentityNotLinked is returned when a user attempts to login
+	// with an external identity that has not been linked to a politeia user.
+	ErrorCodeIdentityNotLinked ErrorCodeT = 6
+
+	// ErrorCodeIdentityLinked is returned when a user attempts to create a
+	// new user with an external identity that has already been linked to a
+	// politeia user.
+	ErrorCodeIdentityLinked ErrorCodeT = 7
+
+	// ErrorCodePublicKeyInvalid is returned when a public key is not a valid
+	// hex encoded ed25519 public key.
+	ErrorCodePublicKeyInvalid ErrorCodeT = 8
+
+	// ErrorCodeSignatureInvalid is returned when a signature is not valid.
+	ErrorCodeSignatureInvalid ErrorCodeT = 9
+
+	// ErrorCodeLast unit test only.
+	ErrorCodeLast ErrorCodeT = 10
+)
+
+var (
+	// ErrorCodes contains the human readable errors.
+	ErrorCodes = map[ErrorCodeT]string{
+		ErrorCodeInvalid:           "error code invalid",
+		ErrorCodeInvalidInput:      "invalid input",
+		ErrorCodeNotLoggedIn:       "not logged in",
+		ErrorCodeStateInvalid:      "state invalid",
+		ErrorCodeCodeInvalid:       "authorization code invalid",
+		ErrorCodeIDTokenInvalid:    "id token invalid",
+		ErrorCodeIdentityNotLinked: "identity not linked",
+		ErrorCodeIdentityLinked:    "identity already linked",
+		ErrorCodePublicKeyInvalid:  "public key invalid",
+		ErrorCodeSignatureInvalid:  "signature invalid",
+	}
+)
+
+// AuthURL requests a new authorization URL. The user must be redirected to
+// the returned URL to authenticate with the provider.
+type AuthURL struct{}
+
+// AuthURLReply is the reply to the AuthURL command.
+//
+// The State is also included in the URL. The provider returns it to the
+// redirect URL along with the authorization code.
+type AuthURLReply struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+// NewUser creates a new user that is linked to the external identity that
+// authenticated with the provider. The user is logged in on success.
+type NewUser struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+// NewUserReply is the reply to the NewUser command.
+type NewUserReply struct {
+	UserID string `json:"userid"`
+}
+
+// Login logs a user in using the external identity that authenticated with
+// the provider. The session of the client is updated to contain the user ID
+// on success.
+type Login struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+// LoginReply is the reply to the Login command.
+type LoginReply struct {
+	UserID string `json:"userid"`
+}
+
+// Logout logs the user out by deleting the session of the client.
+type Logout struct{}
+
+// LogoutReply is the reply to the Logout command.
+type LogoutReply struct{}
+
+// SetIdentity sets the active identity of the logged in user. The previous
+// identity is deactivated. The identity is an ed25519 key pair that is used to
+// verify the signatures of the payloads that the user submits to other
+// plugins, e.g. records. It is not related to the external identity that the
+// user authenticates with.
+//
+// Signature is the signature of the user ID using the new identity. It proves
+// that the user controls the private key of the identity.
+type SetIdentity struct {
+	PublicKey string `json:"publickey"`
+	Signature string `json:"signature"`
+}
+
+// SetIdentityReply is the reply to the SetIdentity command.
+type SetIdentityReply struct{}
+
+// Me returns the account details of the logged in user.
+type Me struct{}
+
+// MeReply is the reply to the Me command.
+//
+// The Email and Name are the values that were provided by the provider the
+// last time that the user logged in. They are empty if the provider did not
+// return them.
+type MeReply struct {
+	UserID      string `json:"userid"`
+	Issuer      string `json:"issuer"`
+	Subject     string `json:"subject"`
+	Email       string `json:"email,omitempty"`
+	Name        string `json:"name,omitempty"`
+	CreatedAt   int64  `json:"createdat"`
+	LastLoginAt int64  `json:"lastloginat"`
+	PublicKey   string `json:"publickey,omitempty"` // Active identity
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package pdutil provides helper functions for the politeiawww plugins that
+// proxy their commands to politeiad.
+package pdutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	pdclient "github.com/decred/politeia/politeiad/client"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+)
+
+// PluginIDPrefix is prefixed onto the plugin ID of the user errors that are
+// returned by politeiad plugins. This prevents the error codes of a politeiad
+// plugin from colliding with the error codes of a politeiawww plugin that
+// uses the same plugin ID, e.g. the comments plugin.
+const PluginIDPrefix = "politeiad-"
+
+// ConvertError converts a politeiad response error into a plugin user error.
+//
+// politeiad plugin errors are returned using the politeiad plugin ID prefixed
+// with the PluginIDPrefix. politeiad backend errors are converted into user
+// errors for the provided plugin ID using the convertCode function. A zero
+// error code returned by convertCode indicates that the politeiad error does
+// not correspond to a user error. The original error is returned in this
+// case, as well as for all other errors.
+func ConvertError(err error, pluginID string, convertCode func(pdv2.ErrorCodeT) uint32) error {
+	var pde pdclient.RespError
+	if !errors.As(err, &pde) {
+		return err
+	}
+	var (
+		pdPluginID = pde.ErrorReply.PluginID
+		errCode    = pde.ErrorReply.ErrorCode
+		errContext = pde.ErrorReply.ErrorContext
+	)
+	if pdPluginID != "" {
+		return plugin.UserError{
+			PluginID:     PluginIDPrefix + pdPluginID,
+			ErrorCode:    errCode,
+			ErrorContext: errContext,
+		}
+	}
+	e := convertCode(pdv2.ErrorCodeT(errCode))
+	if e == 0 {
+		return err
+	}
+	return plugin.UserError{
+		PluginID:     pluginID,
+		ErrorCode:    e,
+		ErrorContext: errContext,
+	}
+}
+
+// ReplyFromError returns a plugin reply for the provided error. A reply that
+// contains the error is returned if the error is a plugin user error. All
+// other errors are returned as is.
+func ReplyFromError(err error) (*plugin.Reply, error) {
+	var ue plugin.UserError
+	if errors.As(err, &ue) {
+		return &plugin.Reply{
+			Error: ue,
+		}, nil
+	}
+	return nil, err
+}
+
+// PluginSettings returns the settings of a politeiad plugin. An error is
+// returned if the plugin is not registered with politeiad.
+func PluginSettings(ctx context.Context, c *pdclient.Client, pluginID string) ([]pdv2.PluginSetting, error) {
+	plugins, err := c.PluginInventory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range plugins {
+		if v.ID == pluginID {
+			return v.Settings, nil
+		}
+	}
+	return nil, fmt.Errorf("politeiad plugin not found: %v", pluginID)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pdutil
+
+import (
+	"errors"
+	"testing"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	pdclient "github.com/decred/politeia/politeiad/client"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+)
+
+func TestConvertError(t *testing.T) {
+	const pluginID = "records"
+	convertCode := func(e pdv2.ErrorCodeT) uint32 {
+		if e == pdv2.ErrorCodeRecordNotFound {
+			return 7
+		}
+		return 0
+	}
+	var (
+		errInternal  = errors.New("internal error")
+		errPDPlugin  = pdclient.RespError{ErrorReply: pdclient.ErrorReply{PluginID: "usermd", ErrorCode: 3}}
+		errPDUser    = pdclient.RespError{ErrorReply: pdclient.ErrorReply{ErrorCode: uint32(pdv2.ErrorCodeRecordNotFound)}}
+		errPDUnknown = pdclient.RespError{ErrorReply: pdclient.ErrorReply{ErrorCode: uint32(pdv2.ErrorCodeChallengeInvalid)}}
+	)
+
+	var tests = []struct {
+		name         string
+		err          error
+		wantUser     bool
+		wantPluginID string
+		wantCode     uint32
+	}{
+		{"internal error", errInternal, false, "", 0},
+		{"politeiad plugin error", errPDPlugin, true,
+			PluginIDPrefix + "usermd", 3},
+		{"politeiad user error", errPDUser, true, pluginID, 7},
+		{"politeiad internal error", errPDUnknown, false, "", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ConvertError(test.err, pluginID, convertCode)
+			var ue plugin.UserError
+			isUser := errors.As(err, &ue)
+			if isUser != test.wantUser {
+				t.Fatalf("got user error %v, want %v", isUser, test.wantUser)
+			}
+			if !isUser {
+				if err != test.err {
+					t.Errorf("got error %v, want %v", err, test.err)
+				}
+				return
+			}
+			if ue.PluginID != test.wantPluginID ||
+				ue.ErrorCode != test.wantCode {
+				t.Errorf("got %v %v, want %v %v", ue.PluginID,
+					ue.ErrorCode, test.wantPluginID, test.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+)
+
+// cmdPolicy returns the pi policy.
+func (p *piPlugin) cmdPolicy(ctx context.Context) (*plugin.Reply, error) {
+	pol, _, err := p.policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newReply(pol)
+}
+
+// cmdTemplates returns the proposal templates.
+func (p *piPlugin) cmdTemplates(ctx context.Context) (*plugin.Reply, error) {
+	_, templates, err := p.policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newReply(templates)
+}
+
+// cmdSetBillingStatus sets the billing status of a proposal.
+func (p *piPlugin) cmdSetBillingStatus(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var sbs v1.SetBillingStatus
+	err := decodePayload(args.Cmd.Payload, &sbs)
+	if err != nil {
+		return nil, err
+	}
+	if args.User == nil {
+		// Should not happen. The permission level of this
+		// command requires a logged in user.
+		return nil, errors.New("user not provided")
+	}
+
+	// Verify that the admin signed using their active identity
+	err = p.identityVerify(tx, args.User.ID.String(), sbs.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send plugin command. The signature is verified by
+	// the politeiad pi plugin.
+	psbs := convertSetBillingStatusToPlugin(sbs)
+	psbsr, err := p.politeiad.PiSetBillingStatus(ctx, psbs)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Billing status set: %v %v", sbs.Token,
+		v1.BillingStatuses[sbs.Status])
+
+	return newReply(v1.SetBillingStatusReply{
+		Timestamp: psbsr.Timestamp,
+		Receipt:   psbsr.Receipt,
+	})
+}
+
+// cmdBillingStatusChanges returns the billing status changes of the provided
+// proposals.
+func (p *piPlugin) cmdBillingStatusChanges(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var bscs v1.BillingStatusChanges
+	err := decodePayload(args.Cmd.Payload, &bscs)
+	if err != nil {
+		return nil, err
+	}
+	pol, _, err := p.policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify request size
+	if len(bscs.Tokens) > int(pol.BillingStatusChangesPageSize) {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePageSizeExceeded,
+			ErrorContext: fmt.Sprintf("max page size is %v",
+				pol.BillingStatusChangesPageSize),
+		}
+	}
+
+	reply, err := p.politeiad.PiBillingStatusChanges(ctx, bscs.Tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert reply to API
+	r := make(map[string][]v1.BillingStatusChange, len(reply))
+	for t, bscs := range reply {
+		statusChanges := make([]v1.BillingStatusChange, 0,
+			len(bscs.BillingStatusChanges))
+		for _, bsc := range bscs.BillingStatusChanges {
+			statusChanges = append(statusChanges,
+				convertBillingStatusChangeToAPI(bsc))
+		}
+		r[t] = statusChanges
+	}
+
+	return newReply(v1.BillingStatusChangesReply{
+		BillingStatusChanges: r,
+	})
+}
+
+// cmdBillingStatusTimestamps returns the timestamps of the billing status
+// changes of a proposal.
+func (p *piPlugin) cmdBillingStatusTimestamps(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var bst v1.BillingStatusTimestamps
+	err := decodePayload(args.Cmd.Payload, &bst)
+	if err != nil {
+		return nil, err
+	}
+
+	bstr, err := p.politeiad.PiBillingStatusTimestamps(ctx, bst.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert reply to API
+	timestamps := make([]v1.Timestamp, 0, len(bstr.Timestamps))
+	for _, v := range bstr.Timestamps {
+		timestamps = append(timestamps, convertTimestampToAPI(v))
+	}
+
+	return newReply(v1.BillingStatusTimestampsReply{
+		Timestamps: timestamps,
+	})
+}
+
+// cmdSummaries returns the proposal summaries of the provided proposals.
+func (p *piPlugin) cmdSummaries(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var s v1.Summaries
+	err := decodePayload(args.Cmd.Payload, &s)
+	if err != nil {
+		return nil, err
+	}
+	pol, _, err := p.policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify request size
+	if len(s.Tokens) > int(pol.SummariesPageSize) {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePageSizeExceeded,
+			ErrorContext: fmt.Sprintf("max page size is %v",
+				pol.SummariesPageSize),
+		}
+	}
+
+	psr, err := p.politeiad.PiSummaries(ctx, s.Tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert reply to API
+	ss := make(map[string]v1.Summary, len(psr))
+	for token, s := range psr {
+		ss[token] = v1.Summary{
+			Status: string(s.Summary.Status),
+		}
+	}
+
+	return newReply(v1.SummariesReply{
+		Summaries: ss,
+	})
+}
+
+// identityVerify verifies that the provided public key is the active identity
+// of the provided user. A user error is returned if it is not.
+func (p *piPlugin) identityVerify(tx *sql.Tx, userID, publicKey string) error {
+	if p.identities == nil {
+		return errors.New("user identities are not available")
+	}
+	pk, err := p.identities.ActiveIdentity(tx, userID)
+	if err != nil {
+		if errors.Is(err, plugin.ErrIdentityNotFound) {
+			return v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodePublicKeyInvalid,
+				ErrorContext: fmt.Sprintf("user %v does not have an "+
+					"active identity", userID),
+			}
+		}
+		return err
+	}
+	if pk != publicKey {
+		return v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePublicKeyInvalid,
+			ErrorContext: fmt.Sprintf("user %v did not sign with "+
+				"their active identity", userID),
+		}
+	}
+	return nil
+}
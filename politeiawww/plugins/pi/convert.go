@@ -0,0 +1,80 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"github.com/decred/politeia/politeiad/plugins/pi"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+)
+
+func convertBillingStatusChangeToAPI(bsc pi.BillingStatusChange) v1.BillingStatusChange {
+	return v1.BillingStatusChange{
+		Token:     bsc.Token,
+		Status:    convertBillingStatusToAPI(bsc.Status),
+		Reason:    bsc.Reason,
+		PublicKey: bsc.PublicKey,
+		Signature: bsc.Signature,
+		Receipt:   bsc.Receipt,
+		Timestamp: bsc.Timestamp,
+	}
+}
+
+func convertBillingStatusToAPI(bs pi.BillingStatusT) v1.BillingStatusT {
+	switch bs {
+	case pi.BillingStatusActive:
+		return v1.BillingStatusActive
+	case pi.BillingStatusClosed:
+		return v1.BillingStatusClosed
+	case pi.BillingStatusCompleted:
+		return v1.BillingStatusCompleted
+	}
+	return v1.BillingStatusInvalid
+}
+
+func convertProofToAPI(p pi.Proof) v1.Proof {
+	return v1.Proof{
+		Type:       p.Type,
+		Digest:     p.Digest,
+		MerkleRoot: p.MerkleRoot,
+		MerklePath: p.MerklePath,
+		ExtraData:  p.ExtraData,
+	}
+}
+
+func convertTimestampToAPI(t pi.Timestamp) v1.Timestamp {
+	proofs := make([]v1.Proof, 0, len(t.Proofs))
+	for _, v := range t.Proofs {
+		proofs = append(proofs, convertProofToAPI(v))
+	}
+	return v1.Timestamp{
+		Data:       t.Data,
+		Digest:     t.Digest,
+		TxID:       t.TxID,
+		MerkleRoot: t.MerkleRoot,
+		Proofs:     proofs,
+	}
+}
+
+func convertSetBillingStatusToPlugin(sbs v1.SetBillingStatus) pi.SetBillingStatus {
+	return pi.SetBillingStatus{
+		Token:     sbs.Token,
+		Status:    convertBillingStatusToPlugin(sbs.Status),
+		Reason:    sbs.Reason,
+		PublicKey: sbs.PublicKey,
+		Signature: sbs.Signature,
+	}
+}
+
+func convertBillingStatusToPlugin(bs v1.BillingStatusT) pi.BillingStatusT {
+	switch bs {
+	case v1.BillingStatusActive:
+		return pi.BillingStatusActive
+	case v1.BillingStatusClosed:
+		return pi.BillingStatusClosed
+	case v1.BillingStatusCompleted:
+		return pi.BillingStatusCompleted
+	}
+	return pi.BillingStatusInvalid
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"errors"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/decred/politeia/politeiawww/plugins/pdutil"
+)
+
+// convertError converts the errors that are returned by the command
+// implementations into plugin user errors. Errors that were not caused by the
+// user are returned as is.
+func convertError(err error) error {
+	var ue v1.UserErrorReply
+	if errors.As(err, &ue) {
+		return plugin.UserError{
+			PluginID:     v1.PluginID,
+			ErrorCode:    uint32(ue.ErrorCode),
+			ErrorContext: ue.ErrorContext,
+		}
+	}
+	return pdutil.ConvertError(err, v1.PluginID,
+		func(e pdv2.ErrorCodeT) uint32 {
+			return uint32(convertPDErrorCode(e))
+		})
+}
+
+func convertPDErrorCode(errCode pdv2.ErrorCodeT) v1.ErrorCodeT {
+	// Any error statuses that are omitted means that politeiawww
+	// should 500.
+	switch errCode {
+	case pdv2.ErrorCodeTokenInvalid:
+		return v1.ErrorCodeRecordTokenInvalid
+	case pdv2.ErrorCodeRecordNotFound:
+		return v1.ErrorCodeRecordNotFound
+	}
+	return v1.ErrorCodeInvalid
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pi
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/plugins/pi"
+	v1 "github.com/decred/politeia/politeiawww/api/pi/v1"
+	"github.com/decred/politeia/politeiawww/plugins/pdutil"
+	"github.com/pkg/errors"
+)
+
+// policy returns the pi policy and the proposal templates. These are derived
+// from the settings of the politeiad pi plugin, which are retrieved from
+// politeiad the first time that this function is called.
+func (p *piPlugin) policy(ctx context.Context) (*v1.PolicyReply, *v1.TemplatesReply, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.pol != nil {
+		return p.pol, p.templates, nil
+	}
+	settings, err := pdutil.PluginSettings(ctx, p.politeiad, pi.PluginID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pol, templates, err := parsePolicy(settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.pol = pol
+	p.templates = templates
+
+	return pol, templates, nil
+}
+
+// parsePolicy parses the pi policy and the proposal templates from the
+// politeiad pi plugin settings.
+func parsePolicy(settings []pdv2.PluginSetting) (*v1.PolicyReply, *v1.TemplatesReply, error) {
+	var (
+		textFileSizeMax              uint32
+		imageFileCountMax            uint32
+		imageFileSizeMax             uint32
+		titleLengthMin               uint32
+		titleLengthMax               uint32
+		titleSupportedChars          []string
+		amountMin                    uint64
+		amountMax                    uint64
+		startDateMin                 int64
+		endDateMax                   int64
+		domains                      []string
+		billingStatusChangesMax      uint32
+		summariesPageSize            uint32
+		billingStatusChangesPageSize uint32
+		sections                     = []string{}
+		templates                    = []pi.ProposalTemplate{}
+	)
+	for _, v := range settings {
+		var err error
+		switch v.Key {
+		case pi.SettingKeyTextFileSizeMax:
+			textFileSizeMax, err = parseUint32(v.Value)
+		case pi.SettingKeyImageFileCountMax:
+			imageFileCountMax, err = parseUint32(v.Value)
+		case pi.SettingKeyImageFileSizeMax:
+			imageFileSizeMax, err = parseUint32(v.Value)
+		case pi.SettingKeyTitleLengthMin:
+			titleLengthMin, err = parseUint32(v.Value)
+		case pi.SettingKeyTitleLengthMax:
+			titleLengthMax, err = parseUint32(v.Value)
+		case pi.SettingKeyTitleSupportedChars:
+			err = json.Unmarshal([]byte(v.Value), &titleSupportedChars)
+		case pi.SettingKeyProposalAmountMin:
+			amountMin, err = strconv.ParseUint(v.Value, 10, 64)
+		case pi.SettingKeyProposalAmountMax:
+			amountMax, err = strconv.ParseUint(v.Value, 10, 64)
+		case pi.SettingKeyProposalStartDateMin:
+			startDateMin, err = strconv.ParseInt(v.Value, 10, 64)
+		case pi.SettingKeyProposalEndDateMax:
+			endDateMax, err = strconv.ParseInt(v.Value, 10, 64)
+		case pi.SettingKeyProposalDomains:
+			err = json.Unmarshal([]byte(v.Value), &domains)
+			for _, d := range domains {
+				if d == "" {
+					err = errors.New("proposal domain can not be an " +
+						"empty string")
+				}
+			}
+		case pi.SettingKeyBillingStatusChangesMax:
+			billingStatusChangesMax, err = parseUint32(v.Value)
+		case pi.SettingKeySummariesPageSize:
+			summariesPageSize, err = parseUint32(v.Value)
+		case pi.SettingKeyBillingStatusChangesPageSize:
+			billingStatusChangesPageSize, err = parseUint32(v.Value)
+		case pi.SettingKeyProposalSections:
+			err = json.Unmarshal([]byte(v.Value), &sections)
+		case pi.SettingKeyProposalTemplates:
+			err = json.Unmarshal([]byte(v.Value), &templates)
+		default:
+			// Skip unknown settings
+			log.Warnf("Unknown politeiad plugin setting %v; Skipping...", v.Key)
+		}
+		if err != nil {
+			return nil, nil, errors.Errorf("invalid politeiad plugin "+
+				"setting %v '%v': %v", v.Key, v.Value, err)
+		}
+	}
+
+	// Verify all plugin settings have been provided
+	var missing string
+	switch {
+	case textFileSizeMax == 0:
+		missing = pi.SettingKeyTextFileSizeMax
+	case imageFileCountMax == 0:
+		missing = pi.SettingKeyImageFileCountMax
+	case imageFileSizeMax == 0:
+		missing = pi.SettingKeyImageFileSizeMax
+	case titleLengthMin == 0:
+		missing = pi.SettingKeyTitleLengthMin
+	case titleLengthMax == 0:
+		missing = pi.SettingKeyTitleLengthMax
+	case len(titleSupportedChars) == 0:
+		missing = pi.SettingKeyTitleSupportedChars
+	case amountMin == 0:
+		missing = pi.SettingKeyProposalAmountMin
+	case amountMax == 0:
+		missing = pi.SettingKeyProposalAmountMax
+	case endDateMax == 0:
+		missing = pi.SettingKeyProposalEndDateMax
+	case len(domains) == 0:
+		missing = pi.SettingKeyProposalDomains
+	case summariesPageSize == 0:
+		missing = pi.SettingKeySummariesPageSize
+	case billingStatusChangesPageSize == 0:
+		missing = pi.SettingKeyBillingStatusChangesPageSize
+	}
+	if missing != "" {
+		return nil, nil, errors.Errorf("plugin setting not found: %v",
+			missing)
+	}
+
+	// Convert the proposal templates
+	ts := make([]v1.ProposalTemplate, 0, len(templates))
+	for _, v := range templates {
+		ts = append(ts, v1.ProposalTemplate{
+			Domain:   v.Domain,
+			Sections: v.Sections,
+			Template: v.Template,
+		})
+	}
+
+	pol := &v1.PolicyReply{
+		TextFileSizeMax:              textFileSizeMax,
+		ImageFileCountMax:            imageFileCountMax,
+		ImageFileSizeMax:             imageFileSizeMax,
+		NameLengthMin:                titleLengthMin,
+		NameLengthMax:                titleLengthMax,
+		NameSupportedChars:           titleSupportedChars,
+		AmountMin:                    amountMin,
+		AmountMax:                    amountMax,
+		StartDateMin:                 startDateMin,
+		EndDateMax:                   endDateMax,
+		Domains:                      domains,
+		SummariesPageSize:            summariesPageSize,
+		BillingStatusChangesPageSize: billingStatusChangesPageSize,
+		BillingStatusChangesMax:      billingStatusChangesMax,
+		Sections:                     sections,
+	}
+	tr := &v1.TemplatesReply{
+		Sections:  sections,
+		Templates: ts,
+	}
+
+	return pol, tr, nil
+}
+
+// parseUint32 parses a uint32 from the provided string.
+func parseUint32(s string) (uint32, error) {
+	u, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(u), nil
+}
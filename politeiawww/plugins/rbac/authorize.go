@@ -0,0 +1,131 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rbac
+
+import (
+	"sort"
+	"time"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/rbac/v1"
+)
+
+// Authorize checks if the user is authorized to execute a plugin command.
+//
+// Every authorization decision is logged. A UserError is returned if the
+// user is not authorized. The session is marked for deletion if it has
+// expired.
+//
+// This function satisfies the plugin.AuthManager interface.
+func (p *rbacPlugin) Authorize(a plugin.AuthorizeArgs) error {
+	log.Tracef("Authorize: %v %v", a.PluginID, a.Cmd)
+
+	userID := "public"
+	if a.Session != nil && a.Session.UserID != "" {
+		userID = a.Session.UserID
+	}
+	level := p.permissionLevel(a.PluginID, a.Cmd)
+
+	err := p.authorize(a, level, time.Now().Unix())
+	if err != nil {
+		log.Infof("Authorize denied: %v %v.%v (%v): %v",
+			userID, a.PluginID, a.Cmd, level, err)
+		return err
+	}
+
+	log.Infof("Authorize allowed: %v %v.%v (%v)",
+		userID, a.PluginID, a.Cmd, level)
+
+	return nil
+}
+
+// authorize checks if the user has the role that is required by the provided
+// permission level.
+func (p *rbacPlugin) authorize(a plugin.AuthorizeArgs, level string, now int64) error {
+	// Check for an expired session
+	s := a.Session
+	if s != nil && s.UserID != "" && now > s.CreatedAt+p.sessionMaxAge {
+		s.Delete = true
+		return userError(v1.ErrorCodeSessionExpired, "")
+	}
+
+	// Check the permission level
+	switch {
+	case level == "":
+		// Commands without a permission level are denied
+		// by default.
+		return userError(v1.ErrorCodeNotAuthorized,
+			"command does not have a permission level")
+	case !p.isRole(level):
+		log.Warnf("Unknown permission level '%v' for %v.%v",
+			level, a.PluginID, a.Cmd)
+		return userError(v1.ErrorCodeNotAuthorized, "")
+	case level == v1.RolePublic:
+		return nil
+	}
+
+	// All other permission levels require a logged in user
+	if s == nil || s.UserID == "" || a.User == nil {
+		return userError(v1.ErrorCodeNotLoggedIn, "")
+	}
+	if level == v1.RoleUser {
+		return nil
+	}
+
+	// Verify that the user has the required role. Admins are
+	// able to execute all commands.
+	roles, err := p.userRoles(a.User)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if r == level || r == v1.RoleAdmin {
+			return nil
+		}
+	}
+
+	return userError(v1.ErrorCodeNotAuthorized, "")
+}
+
+// permissionLevel returns the permission level of a plugin command. An empty
+// string is returned if the command does not have a permission level.
+func (p *rbacPlugin) permissionLevel(pluginID, cmd string) string {
+	if pluginID == v1.PluginID {
+		return p.Permissions()[cmd]
+	}
+	return p.pluginPerms[pluginID][cmd]
+}
+
+// isRole returns whether the provided role is a valid role.
+func (p *rbacPlugin) isRole(role string) bool {
+	switch role {
+	case v1.RolePublic, v1.RoleUser:
+		return true
+	}
+	_, ok := p.roles[role]
+	return ok
+}
+
+// UserPermissions returns the permission levels that the user has been
+// granted. Admins are granted every role.
+//
+// This function satisfies the plugin.AuthManager interface.
+func (p *rbacPlugin) UserPermissions(u *plugin.User) ([]string, error) {
+	log.Tracef("UserPermissions: %v", u.ID)
+
+	roles, err := p.userRoles(u)
+	if err != nil {
+		return nil, err
+	}
+	if hasRole(roles, v1.RoleAdmin) {
+		roles = make([]string, 0, len(p.roles))
+		for r := range p.roles {
+			roles = append(roles, r)
+		}
+		sort.Strings(roles)
+	}
+
+	return append([]string{v1.RolePublic, v1.RoleUser}, roles...), nil
+}
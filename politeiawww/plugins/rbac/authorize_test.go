@@ -0,0 +1,207 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rbac
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/rbac/v1"
+	"github.com/google/uuid"
+)
+
+func TestAuthorize(t *testing.T) {
+	const (
+		pluginID     = "testplugin"
+		cmdPublic    = "public"
+		cmdUser      = "user"
+		cmdAdmin     = "admin"
+		cmdModerator = "moderator"
+		cmdNoLevel   = "nolevel"
+	)
+	p, err := New(plugin.InitArgs{
+		Settings: []plugin.Setting{
+			{Key: v1.SettingKeyRoles, Value: `["moderator"]`},
+			{Key: v1.SettingKeySessionMaxAge, Value: "60"},
+		},
+		Permissions: map[string]map[string]string{
+			pluginID: {
+				cmdPublic:    plugin.PermissionPublic,
+				cmdUser:      plugin.PermissionUser,
+				cmdAdmin:     plugin.PermissionAdmin,
+				cmdModerator: "moderator",
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Setup users
+	var (
+		now       int64 = 1000
+		user            = newUser(t, nil)
+		moderator       = newUser(t, []string{"moderator"})
+		admin           = newUser(t, []string{v1.RoleAdmin})
+	)
+
+	var tests = []struct {
+		name        string
+		user        *plugin.User
+		createdAt   int64
+		cmd         string
+		wantErr     v1.ErrorCodeT // ErrorCodeInvalid means no error
+		wantDeleted bool
+	}{
+		{"public no user", nil, 0, cmdPublic, v1.ErrorCodeInvalid, false},
+		{"user no user", nil, 0, cmdUser, v1.ErrorCodeNotLoggedIn, false},
+		{"user", user, now, cmdUser, v1.ErrorCodeInvalid, false},
+		{"user not admin", user, now, cmdAdmin,
+			v1.ErrorCodeNotAuthorized, false},
+		{"user not moderator", user, now, cmdModerator,
+			v1.ErrorCodeNotAuthorized, false},
+		{"moderator", moderator, now, cmdModerator,
+			v1.ErrorCodeInvalid, false},
+		{"moderator not admin", moderator, now, cmdAdmin,
+			v1.ErrorCodeNotAuthorized, false},
+		{"admin", admin, now, cmdAdmin, v1.ErrorCodeInvalid, false},
+		{"admin has all roles", admin, now, cmdModerator,
+			v1.ErrorCodeInvalid, false},
+		{"no permission level", admin, now, cmdNoLevel,
+			v1.ErrorCodeNotAuthorized, false},
+		{"session expired", admin, now - 61, cmdPublic,
+			v1.ErrorCodeSessionExpired, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := &plugin.Session{}
+			if test.user != nil {
+				s.UserID = test.user.ID.String()
+				s.CreatedAt = test.createdAt
+			}
+			a := plugin.AuthorizeArgs{
+				Session:  s,
+				User:     test.user,
+				PluginID: pluginID,
+				Cmd:      test.cmd,
+			}
+			err := p.authorize(a, p.permissionLevel(pluginID, test.cmd), now)
+			assertErrorCode(t, err, test.wantErr)
+			if s.Delete != test.wantDeleted {
+				t.Errorf("got session delete %v, want %v",
+					s.Delete, test.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestNewInvalidPermission(t *testing.T) {
+	_, err := New(plugin.InitArgs{
+		Permissions: map[string]map[string]string{
+			"testplugin": {
+				"cmd": "moderator",
+			},
+		},
+	})
+	if err == nil {
+		t.Errorf("unknown permission level was not rejected")
+	}
+}
+
+func TestUserRolesAdminSetting(t *testing.T) {
+	u := newUser(t, nil)
+	p, err := New(plugin.InitArgs{
+		Settings: []plugin.Setting{
+			{Key: v1.SettingKeyAdmins, Value: u.ID.String()},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles, err := p.userRoles(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasRole(roles, v1.RoleAdmin) {
+		t.Errorf("admin setting was not applied; got roles %v", roles)
+	}
+}
+
+func newUser(t *testing.T, roles []string) *plugin.User {
+	t.Helper()
+
+	var clearText []byte
+	if roles != nil {
+		b, err := json.Marshal(userData{Roles: roles})
+		if err != nil {
+			t.Fatal(err)
+		}
+		clearText = b
+	}
+	return &plugin.User{
+		ID:         uuid.New(),
+		PluginData: plugin.NewPluginData(clearText, nil),
+	}
+}
+
+func assertErrorCode(t *testing.T, err error, want v1.ErrorCodeT) {
+	t.Helper()
+
+	if want == v1.ErrorCodeInvalid {
+		if err != nil {
+			t.Errorf("got error %v, want nil", err)
+		}
+		return
+	}
+	var ue plugin.UserError
+	if !errors.As(err, &ue) {
+		t.Fatalf("got error %v, want user error %v", err, v1.ErrorCodes[want])
+	}
+	if ue.ErrorCode != uint32(want) {
+		t.Errorf("got error code %v, want %v", ue.ErrorCode, want)
+	}
+}
+
+func TestUserPermissions(t *testing.T) {
+	p, err := New(plugin.InitArgs{
+		Settings: []plugin.Setting{
+			{Key: v1.SettingKeyRoles, Value: `["moderator","reviewer"]`},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name  string
+		roles []string
+		want  []string
+	}{
+		{"user", nil, []string{"public", "user"}},
+		{"moderator", []string{"moderator"},
+			[]string{"public", "user", "moderator"}},
+		{"admin", []string{"admin"},
+			[]string{"public", "user", "admin", "moderator", "reviewer"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u := newUser(t, test.roles)
+			perms, err := p.UserPermissions(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(perms) != len(test.want) {
+				t.Fatalf("got %v, want %v", perms, test.want)
+			}
+			for i := range perms {
+				if perms[i] != test.want[i] {
+					t.Fatalf("got %v, want %v", perms, test.want)
+				}
+			}
+		})
+	}
+}
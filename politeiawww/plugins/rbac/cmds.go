@@ -0,0 +1,183 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rbac
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/rbac/v1"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	// opTimeout is the timeout for a single database operation.
+	opTimeout = 1 * time.Minute
+)
+
+// userData contains the user data that is saved to the clear text plugin
+// data of the user.
+type userData struct {
+	Roles []string `json:"roles"`
+}
+
+// cmdSetRoles sets the roles of a user.
+func (p *rbacPlugin) cmdSetRoles(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var sr v1.SetRoles
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &sr)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.User == nil {
+		return userErrorReply(v1.ErrorCodeNotLoggedIn, ""), nil
+	}
+
+	// Verify the roles
+	roles := make([]string, 0, len(sr.Roles))
+	dups := make(map[string]struct{}, len(sr.Roles))
+	for _, r := range sr.Roles {
+		if _, ok := p.roles[r]; !ok {
+			return userErrorReply(v1.ErrorCodeRoleInvalid,
+				"'%v' cannot be assigned", r), nil
+		}
+		if _, ok := dups[r]; ok {
+			continue
+		}
+		dups[r] = struct{}{}
+		roles = append(roles, r)
+	}
+	sort.Strings(roles)
+
+	// Get the user
+	u, err := p.user(tx, args.User, sr.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return userErrorReply(v1.ErrorCodeUserNotFound, ""), nil
+	}
+
+	// Save the roles. The backend saves the changes that are
+	// made to the user that is executing the command, so the
+	// UserDB is only used for other users.
+	b, err := json.Marshal(userData{Roles: roles})
+	if err != nil {
+		return nil, err
+	}
+	u.PluginData.SetClearText(b)
+	if u != args.User {
+		err = p.userDB.TxUpdate(tx, *u)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Infof("Roles updated for %v by %v: %v", u.ID, args.User.ID, roles)
+
+	return newReply(v1.SetRolesReply{
+		Roles: roles,
+	})
+}
+
+// cmdRoles returns the roles of a user.
+func (p *rbacPlugin) cmdRoles(tx *sql.Tx, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var r v1.Roles
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &r)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.User == nil {
+		return userErrorReply(v1.ErrorCodeNotLoggedIn, ""), nil
+	}
+
+	// Only admins are allowed to request the roles of
+	// other users.
+	if r.UserID == "" {
+		r.UserID = args.User.ID.String()
+	}
+	if r.UserID != args.User.ID.String() {
+		roles, err := p.userRoles(args.User)
+		if err != nil {
+			return nil, err
+		}
+		if !hasRole(roles, v1.RoleAdmin) {
+			return userErrorReply(v1.ErrorCodeNotAuthorized, ""), nil
+		}
+	}
+
+	// Get the user
+	u, err := p.user(tx, args.User, r.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return userErrorReply(v1.ErrorCodeUserNotFound, ""), nil
+	}
+	roles, err := p.userRoles(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.RolesReply{
+		UserID: u.ID.String(),
+		Roles:  append([]string{v1.RoleUser}, roles...),
+	})
+}
+
+// user returns the user for the provided user ID. A nil user is returned if
+// a user is not found.
+//
+// The user that is executing the command is returned if the user ID belongs
+// to them. Updates to this user must be made to the provided object, not
+// through the UserDB, otherwise they are overwritten by the backend.
+func (p *rbacPlugin) user(tx *sql.Tx, current *plugin.User, userID string) (*plugin.User, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, nil
+	}
+	if current != nil && current.ID.String() == userID {
+		return current, nil
+	}
+	u, err := p.userDB.TxGet(tx, userID)
+	if err != nil {
+		if errors.Is(err, plugin.ErrUserNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// userRoles returns the roles that have been assigned to a user. The admin
+// role is included for the users that are admins by the plugin settings. The
+// implicit public and user roles are not included.
+func (p *rbacPlugin) userRoles(u *plugin.User) ([]string, error) {
+	var roles []string
+	if b := u.PluginData.ClearText(); len(b) > 0 {
+		var ud userData
+		err := json.Unmarshal(b, &ud)
+		if err != nil {
+			return nil, err
+		}
+		roles = ud.Roles
+	}
+	if _, ok := p.admins[u.ID.String()]; ok && !hasRole(roles, v1.RoleAdmin) {
+		roles = append(roles, v1.RoleAdmin)
+	}
+	return roles, nil
+}
+
+// hasRole returns whether the provided roles contain the role.
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
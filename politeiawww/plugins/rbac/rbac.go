@@ -0,0 +1,293 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package rbac provides a politeiawww auth manager plugin that authorizes the
+// plugin commands using role based access control.
+package rbac
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/rbac/v1"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+var (
+	_ plugin.Plugin      = (*rbacPlugin)(nil)
+	_ plugin.AuthManager = (*rbacPlugin)(nil)
+)
+
+func init() {
+	plugin.RegisterPluginInitFn(v1.PluginID,
+		func(args plugin.InitArgs) (plugin.Plugin, error) {
+			return New(args)
+		})
+	plugin.RegisterAuthManagerInitFn(v1.PluginID,
+		func(args plugin.InitArgs) (plugin.AuthManager, error) {
+			return New(args)
+		})
+}
+
+// rbacPlugin implements the politeiawww plugin and auth manager interfaces.
+type rbacPlugin struct {
+	sync.RWMutex
+	db     *sql.DB
+	userDB plugin.UserDB
+
+	// permissions contains the user permission level for each of the
+	// plugin commands.
+	permissions map[string]string // [cmd]permissionLevel
+
+	// pluginPerms contains the permission levels of the commands of all
+	// registered plugins. It is only populated when the plugin is being
+	// used as the auth manager.
+	pluginPerms map[string]map[string]string // [pluginID][cmd]permissionLevel
+
+	// roles contains the roles that can be assigned to a user. This
+	// includes the admin role and any custom roles.
+	roles map[string]struct{}
+
+	// admins contains the IDs of the users that are always admins.
+	admins map[string]struct{}
+
+	// Plugin settings
+	sessionMaxAge int64 // In seconds
+}
+
+// ID returns the plugin ID.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *rbacPlugin) ID() string {
+	return v1.PluginID
+}
+
+// Version returns the lowest supported plugin API version.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *rbacPlugin) Version() uint32 {
+	return v1.Version
+}
+
+// SetPermission sets the user permission level for a command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *rbacPlugin) SetPermission(cmd, permissionLevel string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.permissions[cmd] = permissionLevel
+}
+
+// Permissions returns the user permission level for each of the plugin
+// commands.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *rbacPlugin) Permissions() map[string]string {
+	p.RLock()
+	defer p.RUnlock()
+
+	perms := make(map[string]string, len(p.permissions))
+	for k, v := range p.permissions {
+		perms[k] = v
+	}
+	return perms
+}
+
+// Hook executes a plugin hook.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *rbacPlugin) Hook(h plugin.HookArgs) error {
+	log.Tracef("Hook: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// HookTx executes a plugin hook using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *rbacPlugin) HookTx(tx *sql.Tx, h plugin.HookArgs) error {
+	log.Tracef("HookTx: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// WriteTx executes a write plugin command using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *rbacPlugin) WriteTx(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	log.Tracef("WriteTx: %v", args.Cmd.Cmd)
+
+	switch args.Cmd.Cmd {
+	case v1.CmdSetRoles:
+		return p.cmdSetRoles(tx, args)
+	}
+
+	return userErrorReply(v1.ErrorCodeInvalidInput,
+		"invalid write cmd '%v'", args.Cmd.Cmd), nil
+}
+
+// Read executes a read plugin command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *rbacPlugin) Read(args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("Read: %v", args.Cmd.Cmd)
+
+	if p.db == nil {
+		return nil, errors.Errorf("database not provided")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	return p.ReadTx(tx, args)
+}
+
+// ReadTx executes a read plugin command using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *rbacPlugin) ReadTx(tx *sql.Tx, args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("ReadTx: %v", args.Cmd.Cmd)
+
+	switch args.Cmd.Cmd {
+	case v1.CmdRoles:
+		return p.cmdRoles(tx, args)
+	}
+
+	return userErrorReply(v1.ErrorCodeInvalidInput,
+		"invalid read cmd '%v'", args.Cmd.Cmd), nil
+}
+
+// New returns a new rbacPlugin.
+func New(args plugin.InitArgs) (*rbacPlugin, error) {
+	// Default plugin settings
+	var (
+		roles         = v1.SettingRoles
+		admins        = v1.SettingAdmins
+		sessionMaxAge = v1.SettingSessionMaxAge
+	)
+
+	// Override defaults with any passed in settings
+	for _, v := range args.Settings {
+		switch v.Key {
+		case v1.SettingKeyRoles:
+			var r []string
+			err := json.Unmarshal([]byte(v.Value), &r)
+			if err != nil {
+				// Allow a single role to be provided without
+				// the JSON array formatting.
+				r = []string{v.Value}
+			}
+			roles = r
+			log.Infof("Plugin setting updated: %v %v", v.Key, roles)
+
+		case v1.SettingKeyAdmins:
+			var a []string
+			err := json.Unmarshal([]byte(v.Value), &a)
+			if err != nil {
+				a = []string{v.Value}
+			}
+			for _, userID := range a {
+				if _, err := uuid.Parse(userID); err != nil {
+					return nil, errors.Errorf("invalid plugin setting %v: "+
+						"invalid user ID '%v'", v.Key, userID)
+				}
+			}
+			admins = a
+			log.Infof("Plugin setting updated: %v %v", v.Key, admins)
+
+		case v1.SettingKeySessionMaxAge:
+			i, err := strconv.ParseInt(v.Value, 10, 64)
+			if err != nil || i <= 0 {
+				return nil, errors.Errorf("invalid plugin setting %v '%v'",
+					v.Key, v.Value)
+			}
+			sessionMaxAge = i
+			log.Infof("Plugin setting updated: %v %v", v.Key, sessionMaxAge)
+
+		default:
+			return nil, errors.Errorf("invalid plugin setting: %v", v.Key)
+		}
+	}
+
+	// Setup the assignable roles
+	rolesM := map[string]struct{}{
+		v1.RoleAdmin: {},
+	}
+	for _, r := range roles {
+		switch r {
+		case "", v1.RolePublic, v1.RoleUser, v1.RoleAdmin:
+			return nil, errors.Errorf("invalid plugin setting %v: "+
+				"'%v' is not a valid custom role", v1.SettingKeyRoles, r)
+		}
+		rolesM[r] = struct{}{}
+	}
+	adminsM := make(map[string]struct{}, len(admins))
+	for _, userID := range admins {
+		adminsM[userID] = struct{}{}
+	}
+
+	p := &rbacPlugin{
+		db:     args.DB,
+		userDB: args.UserDB,
+		permissions: map[string]string{
+			v1.CmdSetRoles: plugin.PermissionAdmin,
+			v1.CmdRoles:    plugin.PermissionUser,
+		},
+		pluginPerms:   args.Permissions,
+		roles:         rolesM,
+		admins:        adminsM,
+		sessionMaxAge: sessionMaxAge,
+	}
+
+	// Verify that the permission level of every plugin
+	// command is a role that this plugin understands.
+	for pluginID, perms := range args.Permissions {
+		for cmd, level := range perms {
+			if !p.isRole(level) {
+				return nil, errors.Errorf("%v %v permission level '%v' "+
+					"is not a valid role", pluginID, cmd, level)
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// userErrorReply returns a plugin reply that contains a user error.
+func userErrorReply(e v1.ErrorCodeT, format string, args ...interface{}) *plugin.Reply {
+	return &plugin.Reply{
+		Error: userError(e, format, args...),
+	}
+}
+
+// userError returns a plugin user error.
+func userError(e v1.ErrorCodeT, format string, args ...interface{}) plugin.UserError {
+	return plugin.UserError{
+		PluginID:     v1.PluginID,
+		ErrorCode:    uint32(e),
+		ErrorContext: fmt.Sprintf(format, args...),
+	}
+}
+
+// newReply returns a plugin reply that contains the JSON encoded payload.
+func newReply(payload interface{}) (*plugin.Reply, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.Reply{
+		Payload: string(b),
+	}, nil
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package v1 contains the API for the rbac plugin. The rbac plugin is a
+// politeiawww auth manager plugin that provides role based access control for
+// the plugin commands.
+//
+// The permission level of a plugin command is the name of the role that is
+// required to execute it. The following roles are always defined:
+//
+// public - Anyone can execute the command, including users that are not
+// logged in.
+//
+// user - Any logged in user can execute the command.
+//
+// admin - Only admins can execute the command. Admins are able to execute all
+// commands, regardless of the command permission level.
+//
+// Custom roles can be defined using the SettingKeyRoles plugin setting.
+// Custom roles and the admin role are assigned to users using the SetRoles
+// command.
+package v1
+
+const (
+	// PluginID is the unique identifier for this plugin.
+	PluginID = "rbac"
+
+	// Version is the plugin API version.
+	Version uint32 = 1
+)
+
+// Plugin commands. The SetRoles command is a write command. The Roles command
+// is a read-only command.
+const (
+	// CmdSetRoles command sets the roles of a user.
+	CmdSetRoles = "setroles"
+
+	// CmdRoles command returns the roles of a user.
+	CmdRoles = "roles"
+)
+
+// Built in roles. These are the permission levels that are defined by the
+// plugin API.
+const (
+	// RolePublic is the role of all users, including users that are not
+	// logged in.
+	RolePublic = "public"
+
+	// RoleUser is the role of all logged in users.
+	RoleUser = "user"
+
+	// RoleAdmin is the role of admin users.
+	RoleAdmin = "admin"
+)
+
+// Plugin setting keys can be used to specify custom plugin settings. Default
+// plugin setting values can be overridden by providing a plugin setting key
+// and value to the plugin on startup.
+const (
+	// SettingKeyRoles is the plugin setting key for the SettingRoles plugin
+	// setting.
+	SettingKeyRoles = "roles"
+
+	// SettingKeyAdmins is the plugin setting key for the SettingAdmins plugin
+	// setting.
+	SettingKeyAdmins = "admins"
+
+	// SettingKeySessionMaxAge is the plugin setting key for the
+	// SettingSessionMaxAge plugin setting.
+	SettingKeySessionMaxAge = "sessionmaxage"
+)
+
+// Plugin setting default values. These can be overridden by providing a plugin
+// setting key and value to the plugin on startup.
+var (
+	// SettingRoles contains the custom roles that can be assigned to users
+	// in addition to the built in roles. This setting can be used to create
+	// roles like "moderator" that are then used as the permission level of
+	// plugin commands.
+	SettingRoles = []string{}
+
+	// SettingAdmins contains the user IDs of the users that are always given
+	// the admin role, regardless of the roles that have been assigned to
+	// them. This is used to bootstrap the initial admin users.
+	SettingAdmins = []string{}
+)
+
+const (
+	// SettingSessionMaxAge is the default number of seconds that a logged in
+	// session is valid for. The session is deleted once it has expired and
+	// the user must login again.
+	SettingSessionMaxAge int64 = 86400 // 1 day
+)
+
+// ErrorCodeT represents a plugin error that was caused by the user.
+type ErrorCodeT uint32
+
+const (
+	// ErrorCodeInvalid is an invalid error code.
+	ErrorCodeInvalid ErrorCodeT = 0
+
+	// ErrorCodeInvalidInput is returned when the command payload could not be
+	// decoded or the command is not a valid command.
+	ErrorCodeInvalidInput ErrorCodeT = 1
+
+	// ErrorCodeNotLoggedIn is returned when a command that requires a logged
+	// in user is executed without one.
+	ErrorCodeNotLoggedIn ErrorCodeT = 2
+
+	// ErrorCodeNotAuthorized is returned when the user does not have the
+	// role that is required to execute a command.
+	ErrorCodeNotAuthorized ErrorCodeT = 3
+
+	// ErrorCodeSessionExpired is returned when the session of the user has
+	// expired. The session is deleted and the user must login again.
+	ErrorCodeSessionExpired ErrorCodeT = 4
+
+	// ErrorCodeRoleInvalid is returned when a role is not a role that can be
+	// assigned to a user.
+	ErrorCodeRoleInvalid ErrorCodeT = 5
+
+	// ErrorCodeUserNotFound is returned when a user is not found.
+	ErrorCodeUserNotFound ErrorCodeT = 6
+
+	// ErrorCodeLast unit test only.
+	ErrorCodeLast ErrorCodeT = 7
+)
+
+var (
+	// ErrorCodes contains the human readable errors.
+	ErrorCodes = map[ErrorCodeT]string{
+		ErrorCodeInvalid:        "error code invalid",
+		ErrorCodeInvalidInput:   "invalid input",
+		ErrorCodeNotLoggedIn:    "not logged in",
+		ErrorCodeNotAuthorized:  "not authorized",
+		ErrorCodeSessionExpired: "session expired",
+		ErrorCodeRoleInvalid:    "role invalid",
+		ErrorCodeUserNotFound:   "user not found",
+	}
+)
+
+// SetRoles sets the roles of a user. The provided roles replace any roles
+// that were previously assigned to the user. Only the admin role and the
+// custom roles can be assigned. The public and user roles are implicit.
+type SetRoles struct {
+	UserID string   `json:"userid"`
+	Roles  []string `json:"roles"`
+}
+
+// SetRolesReply is the reply to the SetRoles command.
+type SetRolesReply struct {
+	Roles []string `json:"roles"`
+}
+
+// Roles returns the roles of a user. The roles of the logged in user are
+// returned if a user ID is not provided. Only admins can request the roles of
+// other users.
+type Roles struct {
+	UserID string `json:"userid,omitempty"`
+}
+
+// RolesReply is the reply to the Roles command. The reply includes the
+// implicit user role.
+type RolesReply struct {
+	UserID string   `json:"userid"`
+	Roles  []string `json:"roles"`
+}
@@ -0,0 +1,749 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package records
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+	v1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	"github.com/decred/politeia/politeiawww/client"
+	lrecords "github.com/decred/politeia/politeiawww/legacy/records"
+	luser "github.com/decred/politeia/politeiawww/legacy/user"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/google/uuid"
+)
+
+// cmdNew submits a new record to politeiad.
+func (p *recordsPlugin) cmdNew(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var n v1.New
+	err := decodePayload(args.Cmd.Payload, &n)
+	if err != nil {
+		return nil, err
+	}
+	u := args.User
+
+	// Verify that the user signed using their active identity
+	err = p.identityVerify(tx, u.ID.String(), n.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify co-authors
+	coAuthors, err := p.coAuthorsVerify(tx, u, n.CoAuthors)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup metadata stream. The signature is verified
+	// by the usermd plugin.
+	um := usermd.UserMetadata{
+		UserID:    u.ID.String(),
+		PublicKey: n.PublicKey,
+		Signature: n.Signature,
+		CoAuthors: coAuthors,
+	}
+	b, err := json.Marshal(um)
+	if err != nil {
+		return nil, err
+	}
+	metadata := []pdv2.MetadataStream{
+		{
+			PluginID: usermd.PluginID,
+			StreamID: usermd.StreamIDUserMetadata,
+			Payload:  string(b),
+		},
+	}
+
+	// Save record to politeiad
+	f := convertFilesToPD(n.Files)
+	pdr, err := p.politeiad.RecordNew(ctx, metadata, f)
+	if err != nil {
+		return nil, err
+	}
+	rc := convertRecordToV1(*pdr)
+
+	log.Infof("Record submitted: %v", rc.CensorshipRecord.Token)
+	for k, f := range rc.Files {
+		log.Debugf("%02v: %v", k, f.Name)
+	}
+
+	// Emit the legacy event
+	lu, err := p.legacyUser(tx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	rc.Username = lu.Username
+	p.events.Emit(lrecords.EventTypeNew,
+		lrecords.EventNew{
+			User:   *lu,
+			Record: rc,
+		})
+
+	return newReply(v1.NewReply{
+		Record: rc,
+	})
+}
+
+// cmdEdit edits a record in politeiad.
+func (p *recordsPlugin) cmdEdit(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var e v1.Edit
+	err := decodePayload(args.Cmd.Payload, &e)
+	if err != nil {
+		return nil, err
+	}
+	u := args.User
+
+	// Verify that the user signed using their active identity
+	err = p.identityVerify(tx, u.ID.String(), e.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get current record
+	curr, err := p.record(ctx, e.Token, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup files
+	filesAdd := convertFilesToPD(e.Files)
+	filesDel := filesToDel(curr.Files, e.Files)
+
+	// Verify co-authors. The usermd plugin verifies that the user
+	// is one of the record authors and that the set of record authors
+	// has not changed.
+	coAuthors, err := p.coAuthorsVerify(tx, u, e.CoAuthors)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup metadata
+	um := usermd.UserMetadata{
+		UserID:    u.ID.String(),
+		PublicKey: e.PublicKey,
+		Signature: e.Signature,
+		CoAuthors: coAuthors,
+	}
+	b, err := json.Marshal(um)
+	if err != nil {
+		return nil, err
+	}
+	mdOverwrite := []pdv2.MetadataStream{
+		{
+			PluginID: usermd.PluginID,
+			StreamID: usermd.StreamIDUserMetadata,
+			Payload:  string(b),
+		},
+	}
+	mdAppend := []pdv2.MetadataStream{}
+
+	// Save update to politeiad
+	pdr, err := p.politeiad.RecordEdit(ctx, e.Token, mdAppend,
+		mdOverwrite, filesAdd, filesDel)
+	if err != nil {
+		return nil, err
+	}
+	rc := convertRecordToV1(*pdr)
+
+	log.Infof("Record edited: %v", rc.CensorshipRecord.Token)
+	for k, f := range rc.Files {
+		log.Debugf("%02v: %v", k, f.Name)
+	}
+
+	// Emit the legacy event
+	lu, err := p.legacyUser(tx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	rc.Username = lu.Username
+	p.events.Emit(lrecords.EventTypeEdit,
+		lrecords.EventEdit{
+			User:   *lu,
+			Record: rc,
+		})
+
+	return newReply(v1.EditReply{
+		Record: rc,
+	})
+}
+
+// cmdSetStatus sets the status of a record in politeiad.
+func (p *recordsPlugin) cmdSetStatus(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var ss v1.SetStatus
+	err := decodePayload(args.Cmd.Payload, &ss)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify that the admin signed using their active identity
+	err = p.identityVerify(tx, args.User.ID.String(), ss.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup status change metadata
+	scm := usermd.StatusChangeMetadata{
+		Token:     ss.Token,
+		Version:   ss.Version,
+		Status:    uint32(ss.Status),
+		Reason:    ss.Reason,
+		PublicKey: ss.PublicKey,
+		Signature: ss.Signature,
+		Timestamp: time.Now().Unix(),
+	}
+	b, err := json.Marshal(scm)
+	if err != nil {
+		return nil, err
+	}
+	mdAppend := []pdv2.MetadataStream{
+		{
+			PluginID: usermd.PluginID,
+			StreamID: usermd.StreamIDStatusChanges,
+			Payload:  string(b),
+		},
+	}
+	mdOverwrite := []pdv2.MetadataStream{}
+
+	// Send politeiad request
+	s := convertStatusToPD(ss.Status)
+	pdr, err := p.politeiad.RecordSetStatus(ctx, ss.Token, s,
+		mdAppend, mdOverwrite)
+	if err != nil {
+		return nil, err
+	}
+	rc := convertRecordToV1(*pdr)
+
+	log.Infof("Record status set: %v %v", rc.CensorshipRecord.Token,
+		v1.RecordStatuses[rc.Status])
+
+	// Emit the legacy event
+	p.events.Emit(lrecords.EventTypeSetStatus,
+		lrecords.EventSetStatus{
+			Record: rc,
+		})
+
+	return newReply(v1.SetStatusReply{
+		Record: rc,
+	})
+}
+
+// cmdTransferOwnership transfers the ownership of a record to a new user.
+func (p *recordsPlugin) cmdTransferOwnership(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var to v1.TransferOwnership
+	err := decodePayload(args.Cmd.Payload, &to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify that the admin and the author signed using their
+	// active identities and that the new author exists. The
+	// usermd plugin verifies the signatures and the record
+	// authors.
+	err = p.identityVerify(tx, args.User.ID.String(), to.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	err = p.userVerify(tx, to.FromUserID)
+	if err != nil {
+		return nil, err
+	}
+	err = p.identityVerify(tx, to.FromUserID, to.AuthorPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	err = p.userVerify(tx, to.ToUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the plugin command to politeiad. The ownership transfer
+	// is added to the status change history of the record and does
+	// not change the record status.
+	_, err = p.politeiad.TransferOwnership(ctx, usermd.TransferOwnership{
+		Token:           to.Token,
+		Version:         to.Version,
+		FromUserID:      to.FromUserID,
+		ToUserID:        to.ToUserID,
+		Reason:          to.Reason,
+		AuthorPublicKey: to.AuthorPublicKey,
+		AuthorSignature: to.AuthorSignature,
+		PublicKey:       to.PublicKey,
+		Signature:       to.Signature,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the updated record
+	rc, err := p.record(ctx, to.Token, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Record ownership transferred: %v %v to %v",
+		to.Token, to.FromUserID, to.ToUserID)
+
+	return newReply(v1.TransferOwnershipReply{
+		Record: *rc,
+	})
+}
+
+// cmdDetails returns the details of a record.
+func (p *recordsPlugin) cmdDetails(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var d v1.Details
+	err := decodePayload(args.Cmd.Payload, &d)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get record
+	rc, err := p.record(ctx, d.Token, d.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only admins and the record author are allowed to retrieve
+	// unvetted record files. This is a public command so a user
+	// may not exist.
+	if !canViewFiles(args.User, *rc) {
+		rc.Files = []v1.File{}
+	}
+
+	return newReply(v1.DetailsReply{
+		Record: *rc,
+	})
+}
+
+// cmdTimestamps returns the timestamps of a record.
+func (p *recordsPlugin) cmdTimestamps(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var t v1.Timestamps
+	err := decodePayload(args.Cmd.Payload, &t)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get record timestamps
+	rt, err := p.politeiad.RecordTimestamps(ctx, t.Token, t.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		recordMD = convertTimestampToV1(rt.RecordMetadata)
+		metadata = make(map[string]map[uint32]v1.Timestamp, len(rt.Files))
+		files    = make(map[string]v1.Timestamp, len(rt.Files))
+	)
+	for pluginID, v := range rt.Metadata {
+		streams, ok := metadata[pluginID]
+		if !ok {
+			streams = make(map[uint32]v1.Timestamp, 16)
+		}
+		for streamID, ts := range v {
+			streams[streamID] = convertTimestampToV1(ts)
+		}
+		metadata[pluginID] = streams
+	}
+	for k, v := range rt.Files {
+		files[k] = convertTimestampToV1(v)
+	}
+
+	// Get the record. We need to know the record state.
+	rc, err := p.record(ctx, t.Token, t.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unvetted data blobs are stripped if the user is not an admin.
+	// The rest of the timestamp is still returned.
+	if rc.State != v1.RecordStateVetted && !isAdmin(args.User) {
+		recordMD.Data = ""
+		for k, v := range files {
+			v.Data = ""
+			files[k] = v
+		}
+		for _, streams := range metadata {
+			for streamID, ts := range streams {
+				ts.Data = ""
+				streams[streamID] = ts
+			}
+		}
+	}
+
+	return newReply(v1.TimestampsReply{
+		RecordMetadata: recordMD,
+		Files:          files,
+		Metadata:       metadata,
+	})
+}
+
+// cmdRecords returns a batch of records.
+func (p *recordsPlugin) cmdRecords(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var rs v1.Records
+	err := decodePayload(args.Cmd.Payload, &rs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify page size
+	if len(rs.Requests) > v1.RecordsPageSize {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePageSizeExceeded,
+			ErrorContext: fmt.Sprintf("max page size is %v",
+				v1.RecordsPageSize),
+		}
+	}
+
+	// Get records
+	reqs := convertRequestsToPD(rs.Requests)
+	records, err := p.records(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only admins and the record author are allowed to retrieve
+	// unvetted record files. This is a public command so a user
+	// may not exist.
+	for k, v := range records {
+		if !canViewFiles(args.User, v) {
+			v.Files = []v1.File{}
+			records[k] = v
+		}
+	}
+
+	return newReply(v1.RecordsReply{
+		Records: records,
+	})
+}
+
+// cmdInventory returns the tokens of the records in the inventory,
+// categorized by record state and record status.
+func (p *recordsPlugin) cmdInventory(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var i v1.Inventory
+	err := decodePayload(args.Cmd.Payload, &i)
+	if err != nil {
+		return nil, err
+	}
+
+	// The inventory arguments are optional. If a status is provided
+	// then they all arguments must be provided.
+	var (
+		state  pdv2.RecordStateT
+		status pdv2.RecordStatusT
+	)
+	if i.Status != v1.RecordStatusInvalid {
+		// Verify state
+		state = convertStateToPD(i.State)
+		if state == pdv2.RecordStateInvalid {
+			return nil, v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeRecordStateInvalid,
+			}
+		}
+
+		// Verify status
+		status = convertStatusToPD(i.Status)
+		if status == pdv2.RecordStatusInvalid {
+			return nil, v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodeRecordStatusInvalid,
+			}
+		}
+	}
+
+	// Get inventory
+	ir, err := p.politeiad.Inventory(ctx, state, status, i.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only admins are allowed to retrieve unvetted tokens
+	if !isAdmin(args.User) {
+		ir.Unvetted = map[string][]string{}
+	}
+
+	return newReply(v1.InventoryReply{
+		Unvetted: ir.Unvetted,
+		Vetted:   ir.Vetted,
+	})
+}
+
+// cmdInventoryOrdered returns a page of record tokens ordered by the timestamp
+// of their most recent status change.
+func (p *recordsPlugin) cmdInventoryOrdered(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var i v1.InventoryOrdered
+	err := decodePayload(args.Cmd.Payload, &i)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify state
+	state := convertStateToPD(i.State)
+	if state == pdv2.RecordStateInvalid {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordStateInvalid,
+		}
+	}
+
+	// Only admins are allowed to retrieve unvetted tokens
+	if state == pdv2.RecordStateUnvetted && !isAdmin(args.User) {
+		return newReply(v1.InventoryOrderedReply{
+			Tokens: []string{},
+		})
+	}
+
+	// Get inventory
+	tokens, err := p.politeiad.InventoryOrdered(ctx, state, i.Page)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.InventoryOrderedReply{
+		Tokens: tokens,
+	})
+}
+
+// cmdUserRecords returns the tokens of all records submitted by a user.
+func (p *recordsPlugin) cmdUserRecords(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var ur v1.UserRecords
+	err := decodePayload(args.Cmd.Payload, &ur)
+	if err != nil {
+		return nil, err
+	}
+
+	urr, err := p.politeiad.UserRecords(ctx, ur.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only admins and the user are allowed to retrieve
+	// unvetted tokens.
+	u := args.User
+	isUser := u != nil && u.ID.String() == ur.UserID
+	if !isUser && !isAdmin(u) {
+		urr.Unvetted = []string{}
+	}
+
+	return newReply(v1.UserRecordsReply{
+		Unvetted: urr.Unvetted,
+		Vetted:   urr.Vetted,
+	})
+}
+
+// cmdUserActivity returns a page of the activity timeline of a user.
+func (p *recordsPlugin) cmdUserActivity(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var ua v1.UserActivity
+	err := decodePayload(args.Cmd.Payload, &ua)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify user ID
+	_, err = uuid.Parse(ua.UserID)
+	if err != nil {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("invalid user id %v", ua.UserID),
+		}
+	}
+
+	// Only admins and the user are allowed to retrieve the activity
+	// that occurred on unvetted records.
+	var (
+		u        = args.User
+		isUser   = u != nil && u.ID.String() == ua.UserID
+		unvetted = isUser || isAdmin(u)
+	)
+	activity, err := p.politeiad.UserActivity(ctx, usermd.UserActivity{
+		UserID:   ua.UserID,
+		Page:     ua.Page,
+		Unvetted: unvetted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.UserActivityReply{
+		Activity: convertActivityToV1(activity),
+	})
+}
+
+// records returns a batch of records from politeiad.
+func (p *recordsPlugin) records(ctx context.Context, reqs []pdv2.RecordRequest) (map[string]v1.Record, error) {
+	pdr, err := p.politeiad.Records(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	records := make(map[string]v1.Record, len(pdr))
+	for k, v := range pdr {
+		records[k] = convertRecordToV1(v)
+	}
+	return records, nil
+}
+
+// record returns a version of a record from politeiad. The most recent
+// version is returned if the version is 0. A user error is returned if the
+// record is not found.
+func (p *recordsPlugin) record(ctx context.Context, token string, version uint32) (*v1.Record, error) {
+	reqs := []pdv2.RecordRequest{
+		{
+			Token:   token,
+			Version: version,
+		},
+	}
+	rcs, err := p.records(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+	rc, ok := rcs[token]
+	if !ok {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeRecordNotFound,
+		}
+	}
+	return &rc, nil
+}
+
+// coAuthorsVerify verifies that each of the provided co-authors corresponds to
+// an existing user and that the submitting user has not listed themselves as
+// a co-author. The co-authors are returned as usermd plugin co-authors. The
+// signatures are verified by the usermd plugin.
+func (p *recordsPlugin) coAuthorsVerify(tx *sql.Tx, u *plugin.User, cas []v1.CoAuthor) ([]usermd.CoAuthor, error) {
+	coAuthors := make([]usermd.CoAuthor, 0, len(cas))
+	for _, v := range cas {
+		if v.UserID == u.ID.String() {
+			return nil, v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeInputInvalid,
+				ErrorContext: "user cannot be their own co-author",
+			}
+		}
+		err := p.userVerify(tx, v.UserID)
+		if err != nil {
+			return nil, err
+		}
+		err = p.identityVerify(tx, v.UserID, v.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		coAuthors = append(coAuthors, usermd.CoAuthor{
+			UserID:    v.UserID,
+			PublicKey: v.PublicKey,
+			Signature: v.Signature,
+		})
+	}
+	return coAuthors, nil
+}
+
+// userVerify verifies that a user exists for the provided user ID. A user
+// error is returned if the user ID is invalid or if the user does not exist.
+func (p *recordsPlugin) userVerify(tx *sql.Tx, userID string) error {
+	_, err := uuid.Parse(userID)
+	if err != nil {
+		return v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: fmt.Sprintf("invalid user id %v", userID),
+		}
+	}
+	_, err = p.userDB.TxGet(tx, userID)
+	if err != nil {
+		if errors.Is(err, plugin.ErrUserNotFound) {
+			return v1.UserErrorReply{
+				ErrorCode:    v1.ErrorCodeInputInvalid,
+				ErrorContext: fmt.Sprintf("user %v not found", userID),
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// identityVerify verifies that the provided public key is the active identity
+// of the provided user. A user error is returned if it is not.
+func (p *recordsPlugin) identityVerify(tx *sql.Tx, userID, publicKey string) error {
+	if p.identities == nil {
+		return errors.New("user identities are not available")
+	}
+	pk, err := p.identities.ActiveIdentity(tx, userID)
+	if err != nil {
+		if errors.Is(err, plugin.ErrIdentityNotFound) {
+			return v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodePublicKeyInvalid,
+				ErrorContext: fmt.Sprintf("user %v does not have an "+
+					"active identity", userID),
+			}
+		}
+		return err
+	}
+	if pk != publicKey {
+		return v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePublicKeyInvalid,
+			ErrorContext: fmt.Sprintf("user %v did not sign with "+
+				"their active identity", userID),
+		}
+	}
+	return nil
+}
+
+// legacyUser returns the legacy user that is included in the legacy events.
+// Only the user ID and the username are populated. The legacy event listeners
+// do not require any other user fields.
+func (p *recordsPlugin) legacyUser(tx *sql.Tx, userID uuid.UUID) (*luser.User, error) {
+	username, err := p.identities.Username(tx, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &luser.User{
+		ID:       userID,
+		Username: username,
+	}, nil
+}
+
+// canViewFiles returns whether the user is allowed to view the files of the
+// provided record. Only admins and the current record authors, including the
+// co-authors and the users that the ownership of the record was transferred
+// to, are allowed to view the files of unvetted records.
+func canViewFiles(u *plugin.User, r v1.Record) bool {
+	if r.State == v1.RecordStateVetted || isAdmin(u) {
+		return true
+	}
+	if u == nil {
+		return false
+	}
+	authors, err := client.RecordAuthors(r.Version, r.Metadata)
+	if err != nil {
+		return false
+	}
+	userID := u.ID.String()
+	for _, v := range authors {
+		if v == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// filesToDel returns the names of the files that are included in the current
+// files but are not included in updated files. These are the files that need
+// to be deleted from a record on update.
+func filesToDel(current []v1.File, updated []v1.File) []string {
+	curr := make(map[string]struct{}, len(current)) // [name]struct
+	for _, v := range updated {
+		curr[v.Name] = struct{}{}
+	}
+
+	del := make([]string, 0, len(current))
+	for _, v := range current {
+		_, ok := curr[v.Name]
+		if !ok {
+			del = append(del, v.Name)
+		}
+	}
+
+	return del
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package records
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+	v1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/google/uuid"
+)
+
+func TestFilesToDel(t *testing.T) {
+	var (
+		a = v1.File{Name: "a"}
+		b = v1.File{Name: "b"}
+		c = v1.File{Name: "c"}
+	)
+	var tests = []struct {
+		name    string
+		current []v1.File
+		updated []v1.File
+		want    []string
+	}{
+		{"no changes", []v1.File{a, b}, []v1.File{a, b}, []string{}},
+		{"file added", []v1.File{a}, []v1.File{a, b}, []string{}},
+		{"file removed", []v1.File{a, b, c}, []v1.File{a, c}, []string{"b"}},
+		{"all replaced", []v1.File{a, b}, []v1.File{c}, []string{"a", "b"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := filesToDel(test.current, test.updated)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCanViewFiles(t *testing.T) {
+	var (
+		author = &plugin.User{ID: uuid.New()}
+		admin  = &plugin.User{
+			ID: uuid.New(),
+			Permissions: []string{plugin.PermissionPublic,
+				plugin.PermissionUser, plugin.PermissionAdmin},
+		}
+		coAuthor = &plugin.User{ID: uuid.New()}
+		other    = &plugin.User{ID: uuid.New()}
+	)
+	b, err := json.Marshal(usermd.UserMetadata{
+		UserID: author.ID.String(),
+		CoAuthors: []usermd.CoAuthor{
+			{
+				UserID: coAuthor.ID.String(),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := []v1.MetadataStream{
+		{
+			PluginID: usermd.PluginID,
+			StreamID: usermd.StreamIDUserMetadata,
+			Payload:  string(b),
+		},
+	}
+	unvetted := v1.Record{State: v1.RecordStateUnvetted, Metadata: md}
+	vetted := v1.Record{State: v1.RecordStateVetted, Metadata: md}
+
+	// Setup an unvetted record where the ownership was transferred
+	// from the author to a new owner.
+	newOwner := &plugin.User{ID: uuid.New()}
+	b, err = json.Marshal(usermd.StatusChangeMetadata{
+		Version: 1,
+		Status:  uint32(v1.RecordStatusUnreviewed),
+		OwnershipTransfer: &usermd.OwnershipTransfer{
+			FromUserID: author.ID.String(),
+			ToUserID:   newOwner.ID.String(),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transferred := v1.Record{
+		State:   v1.RecordStateUnvetted,
+		Version: 1,
+		Metadata: append(md, v1.MetadataStream{
+			PluginID: usermd.PluginID,
+			StreamID: usermd.StreamIDStatusChanges,
+			Payload:  string(b),
+		}),
+	}
+
+	var tests = []struct {
+		name   string
+		user   *plugin.User
+		record v1.Record
+		want   bool
+	}{
+		{"vetted public", nil, vetted, true},
+		{"vetted user", other, vetted, true},
+		{"unvetted public", nil, unvetted, false},
+		{"unvetted user", other, unvetted, false},
+		{"unvetted author", author, unvetted, true},
+		{"unvetted co-author", coAuthor, unvetted, true},
+		{"unvetted admin", admin, unvetted, true},
+		{"unvetted new owner", newOwner, transferred, true},
+		{"unvetted previous owner", author, transferred, false},
+		{"unvetted co-author after transfer", coAuthor, transferred, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := canViewFiles(test.user, test.record)
+			if got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// testIdentities implements the plugin Identities interface using an in
+// memory map of user IDs to their active public key.
+type testIdentities map[string]string
+
+func (t testIdentities) ActiveIdentity(tx *sql.Tx, userID string) (string, error) {
+	pk, ok := t[userID]
+	if !ok {
+		return "", plugin.ErrIdentityNotFound
+	}
+	return pk, nil
+}
+
+func (t testIdentities) Username(tx *sql.Tx, userID string) (string, error) {
+	_, ok := t[userID]
+	if !ok {
+		return "", plugin.ErrUserNotFound
+	}
+	return userID, nil
+}
+
+func TestIdentityVerify(t *testing.T) {
+	p := &recordsPlugin{
+		identities: testIdentities{
+			"user": "active",
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		userID    string
+		publicKey string
+		wantErr   bool
+	}{
+		{"active identity", "user", "active", false},
+		{"inactive identity", "user", "other", true},
+		{"another user's identity", "user2", "active", true},
+	}
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			err := p.identityVerify(nil, v.userID, v.publicKey)
+			if !v.wantErr {
+				if err != nil {
+					t.Errorf("got error %v, want nil", err)
+				}
+				return
+			}
+			var ue v1.UserErrorReply
+			if !errors.As(err, &ue) ||
+				ue.ErrorCode != v1.ErrorCodePublicKeyInvalid {
+				t.Errorf("got error %v, want public key invalid", err)
+			}
+		})
+	}
+
+	// The identity cannot be verified without the identities
+	p.identities = nil
+	err := p.identityVerify(nil, "user", "active")
+	if err == nil {
+		t.Errorf("got nil error, want error")
+	}
+}
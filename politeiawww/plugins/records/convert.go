@@ -0,0 +1,181 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package records
+
+import (
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/plugins/usermd"
+	v1 "github.com/decred/politeia/politeiawww/api/records/v1"
+)
+
+func convertStateToV1(s pdv2.RecordStateT) v1.RecordStateT {
+	switch s {
+	case pdv2.RecordStateUnvetted:
+		return v1.RecordStateUnvetted
+	case pdv2.RecordStateVetted:
+		return v1.RecordStateVetted
+	}
+	return v1.RecordStateInvalid
+}
+
+func convertStatusToV1(s pdv2.RecordStatusT) v1.RecordStatusT {
+	switch s {
+	case pdv2.RecordStatusUnreviewed:
+		return v1.RecordStatusUnreviewed
+	case pdv2.RecordStatusPublic:
+		return v1.RecordStatusPublic
+	case pdv2.RecordStatusCensored:
+		return v1.RecordStatusCensored
+	case pdv2.RecordStatusArchived:
+		return v1.RecordStatusArchived
+	}
+	return v1.RecordStatusInvalid
+}
+
+func convertFilesToV1(f []pdv2.File) []v1.File {
+	files := make([]v1.File, 0, len(f))
+	for _, v := range f {
+		files = append(files, v1.File{
+			Name:    v.Name,
+			MIME:    v.MIME,
+			Digest:  v.Digest,
+			Payload: v.Payload,
+		})
+	}
+	return files
+}
+
+func convertMetadataStreamsToV1(ms []pdv2.MetadataStream) []v1.MetadataStream {
+	metadata := make([]v1.MetadataStream, 0, len(ms))
+	for _, v := range ms {
+		metadata = append(metadata, v1.MetadataStream{
+			PluginID: v.PluginID,
+			StreamID: v.StreamID,
+			Payload:  v.Payload,
+		})
+	}
+	return metadata
+}
+
+func convertRecordToV1(r pdv2.Record) v1.Record {
+	// The username has been intentionally left blank. Usernames
+	// are owned by the user manager plugin and are not accessible
+	// to this plugin. Clients can use the user ID that is saved
+	// in the usermd metadata stream instead.
+	return v1.Record{
+		State:     convertStateToV1(r.State),
+		Status:    convertStatusToV1(r.Status),
+		Version:   r.Version,
+		Timestamp: r.Timestamp,
+		Username:  "", // Intentionally left blank
+		Metadata:  convertMetadataStreamsToV1(r.Metadata),
+		Files:     convertFilesToV1(r.Files),
+		CensorshipRecord: v1.CensorshipRecord{
+			Token:     r.CensorshipRecord.Token,
+			Merkle:    r.CensorshipRecord.Merkle,
+			Signature: r.CensorshipRecord.Signature,
+		},
+	}
+}
+
+func convertProofToV1(p pdv2.Proof) v1.Proof {
+	return v1.Proof{
+		Type:       p.Type,
+		Digest:     p.Digest,
+		MerkleRoot: p.MerkleRoot,
+		MerklePath: p.MerklePath,
+		ExtraData:  p.ExtraData,
+	}
+}
+
+func convertTimestampToV1(t pdv2.Timestamp) v1.Timestamp {
+	proofs := make([]v1.Proof, 0, len(t.Proofs))
+	for _, v := range t.Proofs {
+		proofs = append(proofs, convertProofToV1(v))
+	}
+	return v1.Timestamp{
+		Data:       t.Data,
+		Digest:     t.Digest,
+		TxID:       t.TxID,
+		MerkleRoot: t.MerkleRoot,
+		Proofs:     proofs,
+	}
+}
+
+func convertActivityToV1(activity []usermd.Activity) []v1.Activity {
+	a := make([]v1.Activity, 0, len(activity))
+	for _, v := range activity {
+		a = append(a, v1.Activity{
+			Type:       v1.ActivityT(v.Type),
+			Token:      v.Token,
+			State:      convertStateToV1(pdv2.RecordStateT(v.State)),
+			Version:    v.Version,
+			Status:     convertStatusToV1(pdv2.RecordStatusT(v.Status)),
+			CommentID:  v.CommentID,
+			Vote:       v.Vote,
+			FromUserID: v.FromUserID,
+			ToUserID:   v.ToUserID,
+			Timestamp:  v.Timestamp,
+		})
+	}
+	return a
+}
+
+func convertFilesToPD(f []v1.File) []pdv2.File {
+	files := make([]pdv2.File, 0, len(f))
+	for _, v := range f {
+		files = append(files, pdv2.File{
+			Name:    v.Name,
+			MIME:    v.MIME,
+			Digest:  v.Digest,
+			Payload: v.Payload,
+		})
+	}
+	return files
+}
+
+func convertStateToPD(s v1.RecordStateT) pdv2.RecordStateT {
+	switch s {
+	case v1.RecordStateUnvetted:
+		return pdv2.RecordStateUnvetted
+	case v1.RecordStateVetted:
+		return pdv2.RecordStateVetted
+	}
+	return pdv2.RecordStateInvalid
+}
+
+func convertStatusToPD(s v1.RecordStatusT) pdv2.RecordStatusT {
+	switch s {
+	case v1.RecordStatusUnreviewed:
+		return pdv2.RecordStatusUnreviewed
+	case v1.RecordStatusPublic:
+		return pdv2.RecordStatusPublic
+	case v1.RecordStatusCensored:
+		return pdv2.RecordStatusCensored
+	case v1.RecordStatusArchived:
+		return pdv2.RecordStatusArchived
+	}
+	return pdv2.RecordStatusInvalid
+}
+
+func convertRequestsToPD(reqs []v1.RecordRequest) []pdv2.RecordRequest {
+	r := make([]pdv2.RecordRequest, 0, len(reqs))
+	for _, v := range reqs {
+		// The records API returns the record without any files by
+		// default. Files are only returned if the filenames are
+		// provided. This behavior differs from the politeiad API
+		// behavior, which returns all files by default.
+		var omitAllFiles bool
+		if len(v.Filenames) == 0 {
+			omitAllFiles = true
+		}
+		r = append(r, pdv2.RecordRequest{
+			Token:        v.Token,
+			Filenames:    v.Filenames,
+			OmitAllFiles: omitAllFiles,
+		})
+	}
+	return r
+}
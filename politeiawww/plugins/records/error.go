@@ -0,0 +1,64 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package records
+
+import (
+	"errors"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	v1 "github.com/decred/politeia/politeiawww/api/records/v1"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/decred/politeia/politeiawww/plugins/pdutil"
+)
+
+// convertError converts the errors that are returned by the command
+// implementations into plugin user errors. Errors that were not caused by the
+// user are returned as is.
+func convertError(err error) error {
+	var ue v1.UserErrorReply
+	if errors.As(err, &ue) {
+		return plugin.UserError{
+			PluginID:     v1.PluginID,
+			ErrorCode:    uint32(ue.ErrorCode),
+			ErrorContext: ue.ErrorContext,
+		}
+	}
+	return pdutil.ConvertError(err, v1.PluginID,
+		func(e pdv2.ErrorCodeT) uint32 {
+			return uint32(convertPDErrorCode(e))
+		})
+}
+
+func convertPDErrorCode(errCode pdv2.ErrorCodeT) v1.ErrorCodeT {
+	// Any error statuses that are intentionally omitted means that
+	// politeiawww should 500.
+	switch errCode {
+	case pdv2.ErrorCodeFilesEmpty:
+		return v1.ErrorCodeFilesEmpty
+	case pdv2.ErrorCodeFileNameInvalid:
+		return v1.ErrorCodeFileNameInvalid
+	case pdv2.ErrorCodeFileNameDuplicate:
+		return v1.ErrorCodeFileNameDuplicate
+	case pdv2.ErrorCodeFileDigestInvalid:
+		return v1.ErrorCodeFileDigestInvalid
+	case pdv2.ErrorCodeFilePayloadInvalid:
+		return v1.ErrorCodeFilePayloadInvalid
+	case pdv2.ErrorCodeFileMIMETypeInvalid:
+		return v1.ErrorCodeFileMIMETypeInvalid
+	case pdv2.ErrorCodeFileMIMETypeUnsupported:
+		return v1.ErrorCodeFileMIMETypeUnsupported
+	case pdv2.ErrorCodeTokenInvalid:
+		return v1.ErrorCodeRecordTokenInvalid
+	case pdv2.ErrorCodeRecordNotFound:
+		return v1.ErrorCodeRecordNotFound
+	case pdv2.ErrorCodeRecordLocked:
+		return v1.ErrorCodeRecordLocked
+	case pdv2.ErrorCodeNoRecordChanges:
+		return v1.ErrorCodeNoRecordChanges
+	case pdv2.ErrorCodeStatusChangeInvalid:
+		return v1.ErrorCodeStatusChangeInvalid
+	}
+	return v1.ErrorCodeInvalid
+}
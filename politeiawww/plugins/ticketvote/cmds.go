@@ -0,0 +1,403 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticketvote
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	lticketvote "github.com/decred/politeia/politeiawww/legacy/ticketvote"
+	luser "github.com/decred/politeia/politeiawww/legacy/user"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// cmdPolicy returns the ticketvote policy.
+func (p *ticketvotePlugin) cmdPolicy(ctx context.Context) (*plugin.Reply, error) {
+	pol, err := p.policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newReply(pol)
+}
+
+// cmdAuthorize authorizes or revokes the authorization of a ticket vote.
+func (p *ticketvotePlugin) cmdAuthorize(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var a v1.Authorize
+	err := decodePayload(args.Cmd.Payload, &a)
+	if err != nil {
+		return nil, err
+	}
+	if args.User == nil {
+		// Should not happen. The permission level of this
+		// command requires a logged in user.
+		return nil, errors.Errorf("user not provided")
+	}
+
+	// Verify that the user signed using their active identity
+	err = p.identityVerify(tx, args.User.ID.String(), a.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify user is one of the record authors
+	authorIDs, err := p.politeiad.Authors(ctx, a.Token)
+	if err != nil {
+		return nil, err
+	}
+	var isAuthor bool
+	for _, v := range authorIDs {
+		if args.User.ID.String() == v {
+			isAuthor = true
+			break
+		}
+	}
+	if !isAuthor {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeUnauthorized,
+			ErrorContext: "user is not record author",
+		}
+	}
+
+	// Send plugin command. The signature is verified by
+	// the politeiad ticketvote plugin.
+	ta := ticketvote.Authorize{
+		Token:     a.Token,
+		Version:   a.Version,
+		Action:    ticketvote.AuthActionT(a.Action),
+		PublicKey: a.PublicKey,
+		Signature: a.Signature,
+	}
+	tar, err := p.politeiad.TicketVoteAuthorize(ctx, ta)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Vote authorization: %v %v", a.Token, a.Action)
+
+	// Emit the legacy event
+	lu, err := p.legacyUser(tx, args.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	p.events.Emit(lticketvote.EventTypeAuthorize,
+		lticketvote.EventAuthorize{
+			Auth: a,
+			User: *lu,
+		})
+
+	return newReply(v1.AuthorizeReply{
+		Timestamp: tar.Timestamp,
+		Receipt:   tar.Receipt,
+	})
+}
+
+// cmdStart starts a ticket vote.
+func (p *ticketvotePlugin) cmdStart(ctx context.Context, tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var s v1.Start
+	err := decodePayload(args.Cmd.Payload, &s)
+	if err != nil {
+		return nil, err
+	}
+	if args.User == nil {
+		// Should not happen. The permission level of this
+		// command requires a logged in user.
+		return nil, errors.Errorf("user not provided")
+	}
+
+	// Verify there is work to be done
+	if len(s.Starts) == 0 {
+		return nil, v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "no start details found",
+		}
+	}
+
+	// Verify that the admin signed using their active identity
+	for _, v := range s.Starts {
+		err = p.identityVerify(tx, args.User.ID.String(), v.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Get token from start details
+	var token string
+	for _, v := range s.Starts {
+		switch v.Params.Type {
+		case v1.VoteTypeRunoff:
+			// This is a runoff vote. Execute the plugin command on the
+			// parent record.
+			token = v.Params.Parent
+		case v1.VoteTypeStandard:
+			// This is a standard vote. Execute the plugin command on the
+			// record specified in the vote params.
+			token = v.Params.Token
+		}
+	}
+
+	// Send plugin command
+	ts := convertStartToPlugin(s)
+	tsr, err := p.politeiad.TicketVoteStart(ctx, token, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range s.Starts {
+		log.Infof("Vote started: %v", v.Params.Token)
+	}
+
+	// Emit the legacy event
+	lu, err := p.legacyUser(tx, args.User.ID)
+	if err != nil {
+		return nil, err
+	}
+	p.events.Emit(lticketvote.EventTypeStart,
+		lticketvote.EventStart{
+			Starts: s.Starts,
+			User:   *lu,
+		})
+
+	return newReply(v1.StartReply{
+		Receipt:          tsr.Receipt,
+		StartBlockHeight: tsr.StartBlockHeight,
+		StartBlockHash:   tsr.StartBlockHash,
+		EndBlockHeight:   tsr.EndBlockHeight,
+		EligibleTickets:  tsr.EligibleTickets,
+	})
+}
+
+// cmdCastBallot casts a ballot of votes.
+func (p *ticketvotePlugin) cmdCastBallot(ctx context.Context, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var cb v1.CastBallot
+	err := decodePayload(args.Cmd.Payload, &cb)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get token from one of the votes
+	var token string
+	for _, v := range cb.Votes {
+		token = v.Token
+		break
+	}
+
+	// Send plugin command
+	tcb := ticketvote.CastBallot{
+		Ballot: convertCastVotesToPlugin(cb.Votes),
+	}
+	tcbr, err := p.politeiad.TicketVoteCastBallot(ctx, token, tcb)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.CastBallotReply{
+		Receipts: convertCastVoteRepliesToV1(tcbr.Receipts),
+	})
+}
+
+// cmdDetails returns the vote details of a record.
+func (p *ticketvotePlugin) cmdDetails(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var d v1.Details
+	err := decodePayload(args.Cmd.Payload, &d)
+	if err != nil {
+		return nil, err
+	}
+
+	tdr, err := p.politeiad.TicketVoteDetails(ctx, d.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	var vote *v1.VoteDetails
+	if tdr.Vote != nil {
+		vd := convertVoteDetailsToV1(*tdr.Vote)
+		vote = &vd
+	}
+
+	return newReply(v1.DetailsReply{
+		Auths: convertAuthDetailsToV1(tdr.Auths),
+		Vote:  vote,
+	})
+}
+
+// cmdResults returns the votes that were cast on a record.
+func (p *ticketvotePlugin) cmdResults(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var r v1.Results
+	err := decodePayload(args.Cmd.Payload, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	rr, err := p.politeiad.TicketVoteResults(ctx, r.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.ResultsReply{
+		Votes: convertCastVoteDetailsToV1(rr.Votes),
+	})
+}
+
+// cmdSummaries returns the vote summaries of the provided records.
+func (p *ticketvotePlugin) cmdSummaries(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var s v1.Summaries
+	err := decodePayload(args.Cmd.Payload, &s)
+	if err != nil {
+		return nil, err
+	}
+	pol, err := p.policy(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify request size
+	if len(s.Tokens) > int(pol.SummariesPageSize) {
+		return nil, v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePageSizeExceeded,
+			ErrorContext: fmt.Sprintf("max page size is %v",
+				pol.SummariesPageSize),
+		}
+	}
+
+	// Get vote summaries
+	ts, err := p.politeiad.TicketVoteSummaries(ctx, s.Tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.SummariesReply{
+		Summaries: convertSummariesToV1(ts),
+	})
+}
+
+// cmdSubmissions returns the runoff vote submissions of a record.
+func (p *ticketvotePlugin) cmdSubmissions(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var s v1.Submissions
+	err := decodePayload(args.Cmd.Payload, &s)
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := p.politeiad.TicketVoteSubmissions(ctx, s.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.SubmissionsReply{
+		Submissions: subs,
+	})
+}
+
+// cmdInventory returns the tokens of the public records, categorized by vote
+// status.
+func (p *ticketvotePlugin) cmdInventory(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var i v1.Inventory
+	err := decodePayload(args.Cmd.Payload, &i)
+	if err != nil {
+		return nil, err
+	}
+
+	ti := ticketvote.Inventory{
+		Status: convertVoteStatusToPlugin(i.Status),
+		Page:   i.Page,
+	}
+	ir, err := p.politeiad.TicketVoteInventory(ctx, ti)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.InventoryReply{
+		Vetted:    ir.Tokens,
+		BestBlock: ir.BestBlock,
+	})
+}
+
+// cmdTimestamps returns the timestamps of the ticket vote data of a record.
+func (p *ticketvotePlugin) cmdTimestamps(ctx context.Context, args plugin.ReadArgs) (*plugin.Reply, error) {
+	var ts v1.Timestamps
+	err := decodePayload(args.Cmd.Payload, &ts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send plugin command
+	tt := ticketvote.Timestamps{
+		VotesPage: ts.VotesPage,
+	}
+	tsr, err := p.politeiad.TicketVoteTimestamps(ctx, ts.Token, tt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare reply
+	var (
+		auths = make([]v1.Timestamp, 0, len(tsr.Auths))
+		votes = make([]v1.Timestamp, 0, len(tsr.Votes))
+
+		details *v1.Timestamp
+	)
+	if tsr.Details != nil {
+		dt := convertTimestampToV1(*tsr.Details)
+		details = &dt
+	}
+	for _, v := range tsr.Auths {
+		auths = append(auths, convertTimestampToV1(v))
+	}
+	for _, v := range tsr.Votes {
+		votes = append(votes, convertTimestampToV1(v))
+	}
+
+	return newReply(v1.TimestampsReply{
+		Auths:   auths,
+		Details: details,
+		Votes:   votes,
+	})
+}
+
+// identityVerify verifies that the provided public key is the active identity
+// of the provided user. A user error is returned if it is not.
+func (p *ticketvotePlugin) identityVerify(tx *sql.Tx, userID, publicKey string) error {
+	if p.identities == nil {
+		return errors.New("user identities are not available")
+	}
+	pk, err := p.identities.ActiveIdentity(tx, userID)
+	if err != nil {
+		if errors.Is(err, plugin.ErrIdentityNotFound) {
+			return v1.UserErrorReply{
+				ErrorCode: v1.ErrorCodePublicKeyInvalid,
+				ErrorContext: fmt.Sprintf("user %v does not have an "+
+					"active identity", userID),
+			}
+		}
+		return err
+	}
+	if pk != publicKey {
+		return v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodePublicKeyInvalid,
+			ErrorContext: fmt.Sprintf("user %v did not sign with "+
+				"their active identity", userID),
+		}
+	}
+	return nil
+}
+
+// legacyUser returns the legacy user that is included in the legacy events.
+// Only the user ID and the username are populated. The legacy event listeners
+// do not require any other user fields.
+func (p *ticketvotePlugin) legacyUser(tx *sql.Tx, userID uuid.UUID) (*luser.User, error) {
+	username, err := p.identities.Username(tx, userID.String())
+	if err != nil {
+		return nil, err
+	}
+	return &luser.User{
+		ID:       userID,
+		Username: username,
+	}, nil
+}
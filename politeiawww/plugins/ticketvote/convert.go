@@ -0,0 +1,297 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticketvote
+
+import (
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+)
+
+func convertVoteStatusToPlugin(s v1.VoteStatusT) ticketvote.VoteStatusT {
+	switch s {
+	case v1.VoteStatusUnauthorized:
+		return ticketvote.VoteStatusUnauthorized
+	case v1.VoteStatusAuthorized:
+		return ticketvote.VoteStatusAuthorized
+	case v1.VoteStatusStarted:
+		return ticketvote.VoteStatusStarted
+	case v1.VoteStatusFinished:
+		return ticketvote.VoteStatusFinished
+	case v1.VoteStatusApproved:
+		return ticketvote.VoteStatusApproved
+	case v1.VoteStatusRejected:
+		return ticketvote.VoteStatusRejected
+	case v1.VoteStatusIneligible:
+		return ticketvote.VoteStatusIneligible
+	default:
+		return ticketvote.VoteStatusInvalid
+	}
+}
+
+func convertVoteTypeToPlugin(t v1.VoteT) ticketvote.VoteT {
+	switch t {
+	case v1.VoteTypeStandard:
+		return ticketvote.VoteTypeStandard
+	case v1.VoteTypeRunoff:
+		return ticketvote.VoteTypeRunoff
+	}
+	return ticketvote.VoteTypeInvalid
+}
+
+func convertVoteParamsToPlugin(v v1.VoteParams) ticketvote.VoteParams {
+	tv := ticketvote.VoteParams{
+		Token:            v.Token,
+		Version:          v.Version,
+		Type:             convertVoteTypeToPlugin(v.Type),
+		Mask:             v.Mask,
+		Duration:         v.Duration,
+		QuorumPercentage: v.QuorumPercentage,
+		PassPercentage:   v.PassPercentage,
+		Parent:           v.Parent,
+	}
+	// Convert vote options
+	vo := make([]ticketvote.VoteOption, 0, len(v.Options))
+	for _, vi := range v.Options {
+		vo = append(vo, ticketvote.VoteOption{
+			ID:          vi.ID,
+			Description: vi.Description,
+			Bit:         vi.Bit,
+		})
+	}
+	tv.Options = vo
+
+	return tv
+}
+
+func convertStartDetailsToPlugin(sd v1.StartDetails) ticketvote.StartDetails {
+	return ticketvote.StartDetails{
+		Params:    convertVoteParamsToPlugin(sd.Params),
+		PublicKey: sd.PublicKey,
+		Signature: sd.Signature,
+	}
+}
+
+func convertStartToPlugin(vs v1.Start) ticketvote.Start {
+	starts := make([]ticketvote.StartDetails, 0, len(vs.Starts))
+	for _, v := range vs.Starts {
+		starts = append(starts, convertStartDetailsToPlugin(v))
+	}
+	return ticketvote.Start{
+		Starts: starts,
+	}
+}
+
+func convertCastVotesToPlugin(votes []v1.CastVote) []ticketvote.CastVote {
+	cv := make([]ticketvote.CastVote, 0, len(votes))
+	for _, v := range votes {
+		cv = append(cv, ticketvote.CastVote{
+			Token:     v.Token,
+			Ticket:    v.Ticket,
+			VoteBit:   v.VoteBit,
+			Signature: v.Signature,
+		})
+	}
+	return cv
+}
+
+func convertVoteTypeToV1(t ticketvote.VoteT) v1.VoteT {
+	switch t {
+	case ticketvote.VoteTypeStandard:
+		return v1.VoteTypeStandard
+	case ticketvote.VoteTypeRunoff:
+		return v1.VoteTypeRunoff
+	}
+	return v1.VoteTypeInvalid
+
+}
+
+func convertVoteParamsToV1(v ticketvote.VoteParams) v1.VoteParams {
+	vp := v1.VoteParams{
+		Token:            v.Token,
+		Version:          v.Version,
+		Type:             convertVoteTypeToV1(v.Type),
+		Mask:             v.Mask,
+		Duration:         v.Duration,
+		QuorumPercentage: v.QuorumPercentage,
+		PassPercentage:   v.PassPercentage,
+	}
+	vo := make([]v1.VoteOption, 0, len(v.Options))
+	for _, o := range v.Options {
+		vo = append(vo, v1.VoteOption{
+			ID:          o.ID,
+			Description: o.Description,
+			Bit:         o.Bit,
+		})
+	}
+	vp.Options = vo
+
+	return vp
+}
+
+func convertVoteErrorToV1(e *ticketvote.VoteErrorT) *v1.VoteErrorT {
+	if e == nil {
+		return nil
+	}
+
+	var ve v1.VoteErrorT
+	switch *e {
+	case ticketvote.VoteErrorInvalid:
+		ve = v1.VoteErrorInvalid
+	case ticketvote.VoteErrorInternalError:
+		ve = v1.VoteErrorInternalError
+	case ticketvote.VoteErrorRecordNotFound:
+		ve = v1.VoteErrorRecordNotFound
+	case ticketvote.VoteErrorVoteBitInvalid:
+		ve = v1.VoteErrorVoteBitInvalid
+	case ticketvote.VoteErrorVoteStatusInvalid:
+		ve = v1.VoteErrorVoteStatusInvalid
+	case ticketvote.VoteErrorTicketAlreadyVoted:
+		ve = v1.VoteErrorTicketAlreadyVoted
+	case ticketvote.VoteErrorTicketNotEligible:
+		ve = v1.VoteErrorTicketNotEligible
+	default:
+		ve = v1.VoteErrorInternalError
+	}
+
+	return &ve
+}
+
+func convertCastVoteRepliesToV1(replies []ticketvote.CastVoteReply) []v1.CastVoteReply {
+	r := make([]v1.CastVoteReply, 0, len(replies))
+	for _, v := range replies {
+		r = append(r, v1.CastVoteReply{
+			Ticket:       v.Ticket,
+			Receipt:      v.Receipt,
+			ErrorCode:    convertVoteErrorToV1(v.ErrorCode),
+			ErrorContext: v.ErrorContext,
+		})
+	}
+	return r
+}
+
+func convertVoteDetailsToV1(vd ticketvote.VoteDetails) v1.VoteDetails {
+	return v1.VoteDetails{
+		Params:           convertVoteParamsToV1(vd.Params),
+		PublicKey:        vd.PublicKey,
+		Signature:        vd.Signature,
+		Receipt:          vd.Receipt,
+		StartBlockHeight: vd.StartBlockHeight,
+		StartBlockHash:   vd.StartBlockHash,
+		EndBlockHeight:   vd.EndBlockHeight,
+		EligibleTickets:  vd.EligibleTickets,
+	}
+}
+
+func convertAuthDetailsToV1(auths []ticketvote.AuthDetails) []v1.AuthDetails {
+	a := make([]v1.AuthDetails, 0, len(auths))
+	for _, v := range auths {
+		a = append(a, v1.AuthDetails{
+			Token:     v.Token,
+			Version:   v.Version,
+			Action:    v.Action,
+			PublicKey: v.PublicKey,
+			Signature: v.Signature,
+			Timestamp: v.Timestamp,
+			Receipt:   v.Receipt,
+		})
+	}
+	return a
+}
+
+func convertCastVoteDetailsToV1(votes []ticketvote.CastVoteDetails) []v1.CastVoteDetails {
+	vs := make([]v1.CastVoteDetails, 0, len(votes))
+	for _, v := range votes {
+		vs = append(vs, v1.CastVoteDetails{
+			Token:     v.Token,
+			Ticket:    v.Ticket,
+			VoteBit:   v.VoteBit,
+			Address:   v.Address,
+			Signature: v.Signature,
+			Receipt:   v.Receipt,
+			Timestamp: v.Timestamp,
+		})
+	}
+	return vs
+}
+
+func convertVoteStatusToV1(s ticketvote.VoteStatusT) v1.VoteStatusT {
+	switch s {
+	case ticketvote.VoteStatusInvalid:
+		return v1.VoteStatusInvalid
+	case ticketvote.VoteStatusUnauthorized:
+		return v1.VoteStatusUnauthorized
+	case ticketvote.VoteStatusAuthorized:
+		return v1.VoteStatusAuthorized
+	case ticketvote.VoteStatusStarted:
+		return v1.VoteStatusStarted
+	case ticketvote.VoteStatusFinished:
+		return v1.VoteStatusFinished
+	case ticketvote.VoteStatusApproved:
+		return v1.VoteStatusApproved
+	case ticketvote.VoteStatusRejected:
+		return v1.VoteStatusRejected
+	case ticketvote.VoteStatusIneligible:
+		return v1.VoteStatusIneligible
+	default:
+		return v1.VoteStatusInvalid
+	}
+}
+
+func convertSummaryToV1(s ticketvote.SummaryReply) v1.Summary {
+	results := make([]v1.VoteResult, 0, len(s.Results))
+	for _, v := range s.Results {
+		results = append(results, v1.VoteResult{
+			ID:          v.ID,
+			Description: v.Description,
+			VoteBit:     v.VoteBit,
+			Votes:       v.Votes,
+		})
+	}
+	return v1.Summary{
+		Type:             convertVoteTypeToV1(s.Type),
+		Status:           convertVoteStatusToV1(s.Status),
+		Duration:         s.Duration,
+		StartBlockHeight: s.StartBlockHeight,
+		StartBlockHash:   s.StartBlockHash,
+		EndBlockHeight:   s.EndBlockHeight,
+		EligibleTickets:  s.EligibleTickets,
+		QuorumPercentage: s.QuorumPercentage,
+		PassPercentage:   s.PassPercentage,
+		Results:          results,
+		BestBlock:        s.BestBlock,
+	}
+}
+
+func convertSummariesToV1(s map[string]ticketvote.SummaryReply) map[string]v1.Summary {
+	ts := make(map[string]v1.Summary, len(s))
+	for k, v := range s {
+		ts[k] = convertSummaryToV1(v)
+	}
+	return ts
+}
+
+func convertProofToV1(p ticketvote.Proof) v1.Proof {
+	return v1.Proof{
+		Type:       p.Type,
+		Digest:     p.Digest,
+		MerkleRoot: p.MerkleRoot,
+		MerklePath: p.MerklePath,
+		ExtraData:  p.ExtraData,
+	}
+}
+
+func convertTimestampToV1(t ticketvote.Timestamp) v1.Timestamp {
+	proofs := make([]v1.Proof, 0, len(t.Proofs))
+	for _, v := range t.Proofs {
+		proofs = append(proofs, convertProofToV1(v))
+	}
+	return v1.Timestamp{
+		Data:       t.Data,
+		Digest:     t.Digest,
+		TxID:       t.TxID,
+		MerkleRoot: t.MerkleRoot,
+		Proofs:     proofs,
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ticketvote
+
+import (
+	"context"
+	"strconv"
+
+	pdv2 "github.com/decred/politeia/politeiad/api/v2"
+	"github.com/decred/politeia/politeiad/plugins/ticketvote"
+	v1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/plugins/pdutil"
+	"github.com/pkg/errors"
+)
+
+// policy returns the ticketvote policy. The policy is derived from the
+// settings of the politeiad ticketvote plugin, which are retrieved from
+// politeiad the first time that this function is called.
+func (p *ticketvotePlugin) policy(ctx context.Context) (*v1.PolicyReply, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.pol != nil {
+		return p.pol, nil
+	}
+	settings, err := pdutil.PluginSettings(ctx, p.politeiad,
+		ticketvote.PluginID)
+	if err != nil {
+		return nil, err
+	}
+	pol, err := parsePolicy(settings)
+	if err != nil {
+		return nil, err
+	}
+	p.pol = pol
+
+	return pol, nil
+}
+
+// parsePolicy parses the ticketvote policy from the politeiad ticketvote
+// plugin settings.
+func parsePolicy(settings []pdv2.PluginSetting) (*v1.PolicyReply, error) {
+	var (
+		linkByPeriodMin    int64
+		linkByPeriodMax    int64
+		voteDurationMin    uint32
+		voteDurationMax    uint32
+		summariesPageSize  uint32
+		inventoryPageSize  uint32
+		timestampsPageSize uint32
+	)
+	for _, v := range settings {
+		var err error
+		switch v.Key {
+		case ticketvote.SettingKeyLinkByPeriodMin:
+			linkByPeriodMin, err = strconv.ParseInt(v.Value, 10, 64)
+		case ticketvote.SettingKeyLinkByPeriodMax:
+			linkByPeriodMax, err = strconv.ParseInt(v.Value, 10, 64)
+		case ticketvote.SettingKeyVoteDurationMin:
+			voteDurationMin, err = parseUint32(v.Value)
+		case ticketvote.SettingKeyVoteDurationMax:
+			voteDurationMax, err = parseUint32(v.Value)
+		case ticketvote.SettingKeySummariesPageSize:
+			summariesPageSize, err = parseUint32(v.Value)
+		case ticketvote.SettingKeyInventoryPageSize:
+			inventoryPageSize, err = parseUint32(v.Value)
+		case ticketvote.SettingKeyTimestampsPageSize:
+			timestampsPageSize, err = parseUint32(v.Value)
+		default:
+			// Skip unknown settings
+			log.Warnf("Unknown politeiad plugin setting %v; Skipping...", v.Key)
+		}
+		if err != nil {
+			return nil, errors.Errorf("invalid politeiad plugin setting "+
+				"%v '%v': %v", v.Key, v.Value, err)
+		}
+	}
+
+	// Verify all plugin settings have been provided
+	switch {
+	case linkByPeriodMin == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			ticketvote.SettingKeyLinkByPeriodMin)
+	case linkByPeriodMax == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			ticketvote.SettingKeyLinkByPeriodMax)
+	case voteDurationMin == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			ticketvote.SettingKeyVoteDurationMin)
+	case voteDurationMax == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			ticketvote.SettingKeyVoteDurationMax)
+	case summariesPageSize == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			ticketvote.SettingKeySummariesPageSize)
+	case inventoryPageSize == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			ticketvote.SettingKeyInventoryPageSize)
+	case timestampsPageSize == 0:
+		return nil, errors.Errorf("plugin setting not found: %v",
+			ticketvote.SettingKeyTimestampsPageSize)
+	}
+
+	return &v1.PolicyReply{
+		LinkByPeriodMin:    linkByPeriodMin,
+		LinkByPeriodMax:    linkByPeriodMax,
+		VoteDurationMin:    voteDurationMin,
+		VoteDurationMax:    voteDurationMax,
+		SummariesPageSize:  summariesPageSize,
+		InventoryPageSize:  inventoryPageSize,
+		TimestampsPageSize: timestampsPageSize,
+	}, nil
+}
+
+// parseUint32 parses a uint32 from the provided string.
+func parseUint32(s string) (uint32, error) {
+	u, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(u), nil
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package ticketvote provides a politeiawww plugin that implements the
+// ticketvote API. The plugin commands are proxied to the politeiad ticketvote
+// plugin.
+package ticketvote
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"time"
+
+	pdclient "github.com/decred/politeia/politeiad/client"
+	v1 "github.com/decred/politeia/politeiawww/api/ticketvote/v1"
+	"github.com/decred/politeia/politeiawww/events"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/decred/politeia/politeiawww/plugins/pdutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	// pdTimeout is the timeout for the politeiad requests that are made
+	// during the execution of a single plugin command.
+	pdTimeout = 1 * time.Minute
+)
+
+var (
+	_ plugin.Plugin = (*ticketvotePlugin)(nil)
+)
+
+func init() {
+	plugin.RegisterPluginInitFn(v1.PluginID,
+		func(args plugin.InitArgs) (plugin.Plugin, error) {
+			return New(args)
+		})
+}
+
+// ticketvotePlugin implements the politeiawww plugin interface.
+type ticketvotePlugin struct {
+	sync.RWMutex
+	politeiad  *pdclient.Client
+	identities plugin.Identities
+	events     *events.Manager
+
+	// permissions contains the user permission level for each of the
+	// plugin commands.
+	permissions map[string]string // [cmd]permissionLevel
+
+	// pol is the ticketvote policy. It is derived from the politeiad
+	// ticketvote plugin settings and is lazy loaded by policy().
+	pol *v1.PolicyReply
+}
+
+// ID returns the plugin ID.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *ticketvotePlugin) ID() string {
+	return v1.PluginID
+}
+
+// Version returns the lowest supported plugin API version.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *ticketvotePlugin) Version() uint32 {
+	return v1.Version
+}
+
+// SetPermission sets the user permission level for a command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *ticketvotePlugin) SetPermission(cmd, permissionLevel string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.permissions[cmd] = permissionLevel
+}
+
+// Permissions returns the user permission level for each of the plugin
+// commands.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *ticketvotePlugin) Permissions() map[string]string {
+	p.RLock()
+	defer p.RUnlock()
+
+	perms := make(map[string]string, len(p.permissions))
+	for k, v := range p.permissions {
+		perms[k] = v
+	}
+	return perms
+}
+
+// Hook executes a plugin hook.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *ticketvotePlugin) Hook(h plugin.HookArgs) error {
+	log.Tracef("Hook: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// HookTx executes a plugin hook using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *ticketvotePlugin) HookTx(tx *sql.Tx, h plugin.HookArgs) error {
+	log.Tracef("HookTx: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// WriteTx executes a write plugin command using a database transaction.
+//
+// The commands are executed by politeiad. The database transaction is only
+// used to read the identities of the users.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *ticketvotePlugin) WriteTx(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	log.Tracef("WriteTx: %v", args.Cmd.Cmd)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pdTimeout)
+	defer cancel()
+
+	var (
+		reply *plugin.Reply
+		err   error
+	)
+	switch args.Cmd.Cmd {
+	case v1.CmdAuthorize:
+		reply, err = p.cmdAuthorize(ctx, tx, args)
+	case v1.CmdStart:
+		reply, err = p.cmdStart(ctx, tx, args)
+	case v1.CmdCastBallot:
+		reply, err = p.cmdCastBallot(ctx, args)
+	default:
+		err = v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "invalid write cmd " + args.Cmd.Cmd,
+		}
+	}
+	if err != nil {
+		return pdutil.ReplyFromError(convertError(err))
+	}
+
+	return reply, nil
+}
+
+// Read executes a read plugin command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *ticketvotePlugin) Read(args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("Read: %v", args.Cmd.Cmd)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pdTimeout)
+	defer cancel()
+
+	var (
+		reply *plugin.Reply
+		err   error
+	)
+	switch args.Cmd.Cmd {
+	case v1.CmdPolicy:
+		reply, err = p.cmdPolicy(ctx)
+	case v1.CmdDetails:
+		reply, err = p.cmdDetails(ctx, args)
+	case v1.CmdResults:
+		reply, err = p.cmdResults(ctx, args)
+	case v1.CmdSummaries:
+		reply, err = p.cmdSummaries(ctx, args)
+	case v1.CmdSubmissions:
+		reply, err = p.cmdSubmissions(ctx, args)
+	case v1.CmdInventory:
+		reply, err = p.cmdInventory(ctx, args)
+	case v1.CmdTimestamps:
+		reply, err = p.cmdTimestamps(ctx, args)
+	default:
+		err = v1.UserErrorReply{
+			ErrorCode:    v1.ErrorCodeInputInvalid,
+			ErrorContext: "invalid read cmd " + args.Cmd.Cmd,
+		}
+	}
+	if err != nil {
+		return pdutil.ReplyFromError(convertError(err))
+	}
+
+	return reply, nil
+}
+
+// ReadTx executes a read plugin command using a database transaction.
+//
+// The commands are executed by politeiad. The database transaction is not
+// used.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *ticketvotePlugin) ReadTx(tx *sql.Tx, args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("ReadTx: %v", args.Cmd.Cmd)
+
+	return p.Read(args)
+}
+
+// New returns a new ticketvotePlugin.
+func New(args plugin.InitArgs) (*ticketvotePlugin, error) {
+	if args.Politeiad == nil {
+		return nil, errors.Errorf("politeiad client not provided")
+	}
+	for _, v := range args.Settings {
+		return nil, errors.Errorf("invalid plugin setting: %v", v.Key)
+	}
+	if args.Events == nil {
+		return nil, errors.Errorf("events manager not provided")
+	}
+	if args.Identities == nil {
+		// The write commands verify that the user signed the
+		// payload using their active identity. They will error
+		// since the identities are not available.
+		log.Warnf("User identities not provided; the write " +
+			"commands are disabled")
+	}
+
+	return &ticketvotePlugin{
+		politeiad:  args.Politeiad,
+		identities: args.Identities,
+		events:     args.Events,
+		permissions: map[string]string{
+			v1.CmdPolicy:      plugin.PermissionPublic,
+			v1.CmdAuthorize:   plugin.PermissionUser,
+			v1.CmdStart:       plugin.PermissionAdmin,
+			v1.CmdCastBallot:  plugin.PermissionPublic,
+			v1.CmdDetails:     plugin.PermissionPublic,
+			v1.CmdResults:     plugin.PermissionPublic,
+			v1.CmdSummaries:   plugin.PermissionPublic,
+			v1.CmdSubmissions: plugin.PermissionPublic,
+			v1.CmdInventory:   plugin.PermissionPublic,
+			v1.CmdTimestamps:  plugin.PermissionPublic,
+		},
+	}, nil
+}
+
+// decodePayload decodes the JSON encoded command payload into the provided
+// interface. A user error is returned if the payload is invalid.
+func decodePayload(payload string, v interface{}) error {
+	err := json.Unmarshal([]byte(payload), v)
+	if err != nil {
+		return v1.UserErrorReply{
+			ErrorCode: v1.ErrorCodeInputInvalid,
+		}
+	}
+	return nil
+}
+
+// newReply returns a plugin reply that contains the JSON encoded payload.
+func newReply(payload interface{}) (*plugin.Reply, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.Reply{
+		Payload: string(b),
+	}, nil
+}
@@ -0,0 +1,513 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package userpass
+
+import (
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/userpass/v1"
+	"github.com/decred/politeia/util"
+	"github.com/pkg/errors"
+)
+
+var (
+	// validUsername contains the characters that a username is allowed to
+	// contain.
+	validUsername = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+	// validEmail matches valid email addresses.
+	validEmail = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_` +
+		"`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?" +
+		"(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+)
+
+// cmdNewUser creates a new user.
+func (p *userpassPlugin) cmdNewUser(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var nu v1.NewUser
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &nu)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.User == nil {
+		return nil, errors.Errorf("user not provided")
+	}
+
+	// Validate the user input
+	username := formatUsername(nu.Username)
+	if e, ok := validateUsername(username); !ok {
+		return e, nil
+	}
+	if e, ok := validateEmail(nu.Email); !ok {
+		return e, nil
+	}
+	if e, ok := p.validatePassword(nu.Password); !ok {
+		return e, nil
+	}
+
+	// Verify that the username and email are not already taken
+	userID, err := userIDByUsername(tx, username)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" {
+		return userErrorReply(v1.ErrorCodeUsernameTaken, ""), nil
+	}
+	ed := emailDigest(nu.Email)
+	userID, err = userIDByEmail(tx, ed)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" {
+		return userErrorReply(v1.ErrorCodeEmailTaken, ""), nil
+	}
+
+	// Setup the user data
+	now := time.Now().Unix()
+	verifyToken, t, err := newToken(now, p.tokenExpiry)
+	if err != nil {
+		return nil, err
+	}
+	ud := userData{
+		Username:      username,
+		EmailVerified: false,
+		CreatedAt:     now,
+	}
+	us := userSecrets{
+		Email:       nu.Email,
+		Password:    newPasswordHash(nu.Password),
+		VerifyToken: t,
+	}
+	err = encodeUser(args.User.PluginData, ud, us)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the user to the lookup index
+	err = insertUser(tx, args.User.ID.String(), username, ed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Email the verification token to the user
+	err = p.emailUserEmailVerify(nu.Email, username, verifyToken)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("New user created %v %v", username, args.User.ID)
+
+	nur := v1.NewUserReply{
+		UserID: args.User.ID.String(),
+	}
+	if p.returnTokens {
+		nur.VerificationToken = verifyToken
+	}
+
+	return newReply(nur)
+}
+
+// cmdVerifyEmail verifies the email address of a user.
+func (p *userpassPlugin) cmdVerifyEmail(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var ve v1.VerifyEmail
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &ve)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+
+	// Get the user
+	u, err := p.userByUsername(tx, args.User, formatUsername(ve.Username))
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return userErrorReply(v1.ErrorCodeTokenInvalid, ""), nil
+	}
+	ud, us, err := decodeUser(u.PluginData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the token
+	if e, ok := us.VerifyToken.verify(ve.Token, time.Now().Unix()); !ok {
+		return userErrorReply(e, ""), nil
+	}
+
+	// Update the user
+	ud.EmailVerified = true
+	us.VerifyToken = nil
+	err = p.saveUser(tx, args.User, u, *ud, *us)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Email verified %v %v", ud.Username, u.ID)
+
+	return newReply(v1.VerifyEmailReply{})
+}
+
+// cmdLogin logs a user in by setting the user ID on the session.
+func (p *userpassPlugin) cmdLogin(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var l v1.Login
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &l)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.Session == nil {
+		return nil, errors.Errorf("session not provided")
+	}
+
+	// Get the user
+	u, err := p.userByUsername(tx, args.User, formatUsername(l.Username))
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		// Hash the password anyway so that the response time does not
+		// reveal whether the username exists.
+		newPasswordHash(l.Password)
+		return userErrorReply(v1.ErrorCodeLoginFailed, ""), nil
+	}
+	ud, us, err := decodeUser(u.PluginData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the password
+	if !us.Password.verify(l.Password) {
+		return userErrorReply(v1.ErrorCodeLoginFailed, ""), nil
+	}
+	if !ud.EmailVerified {
+		return userErrorReply(v1.ErrorCodeEmailNotVerified, ""), nil
+	}
+
+	// Update the user
+	now := time.Now().Unix()
+	ud.LastLoginAt = now
+	err = p.saveUser(tx, args.User, u, *ud, *us)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update the session
+	args.Session.UserID = u.ID.String()
+	args.Session.CreatedAt = now
+
+	log.Infof("User logged in %v %v", ud.Username, u.ID)
+
+	return newReply(v1.LoginReply{
+		UserID: u.ID.String(),
+	})
+}
+
+// cmdLogout logs a user out by instructing the backend to delete the session.
+func (p *userpassPlugin) cmdLogout(args plugin.WriteArgs) (*plugin.Reply, error) {
+	if args.Session == nil {
+		return nil, errors.Errorf("session not provided")
+	}
+	if args.Session.UserID == "" {
+		return userErrorReply(v1.ErrorCodeNotLoggedIn, ""), nil
+	}
+
+	args.Session.Delete = true
+
+	log.Debugf("User logged out %v", args.Session.UserID)
+
+	return newReply(v1.LogoutReply{})
+}
+
+// cmdChangePassword changes the password of the logged in user.
+func (p *userpassPlugin) cmdChangePassword(args plugin.WriteArgs) (*plugin.Reply, error) {
+	var cp v1.ChangePassword
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &cp)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.User == nil {
+		return userErrorReply(v1.ErrorCodeNotLoggedIn, ""), nil
+	}
+	ud, us, err := decodeUser(args.User.PluginData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the current password
+	if !us.Password.verify(cp.CurrentPassword) {
+		return userErrorReply(v1.ErrorCodePasswordInvalid,
+			"current password is incorrect"), nil
+	}
+	if e, ok := p.validatePassword(cp.NewPassword); !ok {
+		return e, nil
+	}
+
+	// Update the user. The changes to the plugin data of the
+	// user executing the command are saved by the backend.
+	us.Password = newPasswordHash(cp.NewPassword)
+	err = encodeUser(args.User.PluginData, *ud, *us)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Password changed %v %v", ud.Username, args.User.ID)
+
+	return newReply(v1.ChangePasswordReply{})
+}
+
+// cmdResetPassword starts the password reset process for a user. A reset
+// token is only created when the username and email address match, but the
+// same reply is returned either way so that users cannot be enumerated.
+func (p *userpassPlugin) cmdResetPassword(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var rp v1.ResetPassword
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &rp)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+
+	// Get the user
+	u, err := p.userByUsername(tx, args.User, formatUsername(rp.Username))
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		log.Debugf("Reset password user not found %v", rp.Username)
+		return newReply(v1.ResetPasswordReply{})
+	}
+	ud, us, err := decodeUser(u.PluginData)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(us.Email, rp.Email) {
+		log.Debugf("Reset password email mismatch %v", ud.Username)
+		return newReply(v1.ResetPasswordReply{})
+	}
+
+	// Create a reset token
+	resetToken, t, err := newToken(time.Now().Unix(), p.tokenExpiry)
+	if err != nil {
+		return nil, err
+	}
+	us.ResetToken = t
+	err = p.saveUser(tx, args.User, u, *ud, *us)
+	if err != nil {
+		return nil, err
+	}
+
+	// Email the reset token to the user
+	err = p.emailUserPasswordReset(us.Email, ud.Username, resetToken)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Reset password token created %v %v", ud.Username, u.ID)
+
+	var rpr v1.ResetPasswordReply
+	if p.returnTokens {
+		rpr.ResetToken = resetToken
+	}
+
+	return newReply(rpr)
+}
+
+// cmdVerifyResetPassword completes the password reset process by setting a
+// new password for the user.
+func (p *userpassPlugin) cmdVerifyResetPassword(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	var vrp v1.VerifyResetPassword
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &vrp)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+
+	// Get the user
+	u, err := p.userByUsername(tx, args.User, formatUsername(vrp.Username))
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return userErrorReply(v1.ErrorCodeTokenInvalid, ""), nil
+	}
+	ud, us, err := decodeUser(u.PluginData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the token and the new password
+	if e, ok := us.ResetToken.verify(vrp.Token, time.Now().Unix()); !ok {
+		return userErrorReply(e, ""), nil
+	}
+	if e, ok := p.validatePassword(vrp.NewPassword); !ok {
+		return e, nil
+	}
+
+	// Update the user. The reset token was sent to the email
+	// address of the user, so the email address is verified
+	// as well.
+	ud.EmailVerified = true
+	us.Password = newPasswordHash(vrp.NewPassword)
+	us.ResetToken = nil
+	err = p.saveUser(tx, args.User, u, *ud, *us)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Password reset %v %v", ud.Username, u.ID)
+
+	return newReply(v1.VerifyResetPasswordReply{})
+}
+
+// cmdSetIdentity sets the active identity of the logged in user.
+func (p *userpassPlugin) cmdSetIdentity(args plugin.WriteArgs) (*plugin.Reply, error) {
+	var si v1.SetIdentity
+	err := json.Unmarshal([]byte(args.Cmd.Payload), &si)
+	if err != nil {
+		return userErrorReply(v1.ErrorCodeInvalidInput, ""), nil
+	}
+	if args.User == nil {
+		return userErrorReply(v1.ErrorCodeNotLoggedIn, ""), nil
+	}
+	ud, us, err := decodeUser(args.User.PluginData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify that the user controls the identity
+	err = util.VerifySignature(si.Signature, si.PublicKey,
+		args.User.ID.String())
+	if err != nil {
+		var se util.SignatureError
+		if errors.As(err, &se) &&
+			se.ErrorCode == util.ErrorStatusPublicKeyInvalid {
+			return userErrorReply(v1.ErrorCodePublicKeyInvalid,
+				se.ErrorContext), nil
+		}
+		return userErrorReply(v1.ErrorCodeSignatureInvalid, ""), nil
+	}
+	if ud.activeIdentity() == si.PublicKey {
+		return userErrorReply(v1.ErrorCodePublicKeyInvalid,
+			"identity is already active"), nil
+	}
+
+	// Update the user. The changes to the plugin data of the
+	// user executing the command are saved by the backend.
+	ud.setIdentity(si.PublicKey, time.Now().Unix())
+	err = encodeUser(args.User.PluginData, *ud, *us)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Identity set %v %v %v", ud.Username, args.User.ID,
+		si.PublicKey)
+
+	return newReply(v1.SetIdentityReply{})
+}
+
+// cmdMe returns the account details of the logged in user.
+func (p *userpassPlugin) cmdMe(args plugin.ReadArgs) (*plugin.Reply, error) {
+	if args.User == nil {
+		return userErrorReply(v1.ErrorCodeNotLoggedIn, ""), nil
+	}
+	ud, us, err := decodeUser(args.User.PluginData)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReply(v1.MeReply{
+		UserID:        args.User.ID.String(),
+		Username:      ud.Username,
+		Email:         us.Email,
+		EmailVerified: ud.EmailVerified,
+		CreatedAt:     ud.CreatedAt,
+		LastLoginAt:   ud.LastLoginAt,
+		PublicKey:     ud.activeIdentity(),
+	})
+}
+
+// userByUsername returns the user for the provided username. A nil user is
+// returned if a user is not found.
+//
+// The user that is executing the command is returned if the username belongs
+// to them. Updates to this user must be made to the provided object, not
+// through the UserDB, otherwise they are overwritten by the backend.
+func (p *userpassPlugin) userByUsername(tx *sql.Tx, current *plugin.User, username string) (*plugin.User, error) {
+	userID, err := userIDByUsername(tx, username)
+	if err != nil {
+		return nil, err
+	}
+	if userID == "" {
+		return nil, nil
+	}
+	if current != nil && current.ID.String() == userID {
+		return current, nil
+	}
+	u, err := p.userDB.TxGet(tx, userID)
+	if err != nil {
+		if errors.Is(err, plugin.ErrUserNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// saveUser encodes the user data into the plugin data of the provided user
+// and saves it. The backend saves the changes that are made to the user that
+// is executing the command, so the UserDB is only used for other users.
+func (p *userpassPlugin) saveUser(tx *sql.Tx, current, u *plugin.User, ud userData, us userSecrets) error {
+	err := encodeUser(u.PluginData, ud, us)
+	if err != nil {
+		return err
+	}
+	if u == current {
+		return nil
+	}
+	return p.userDB.TxUpdate(tx, *u)
+}
+
+// formatUsername normalizes a username. Usernames are case insensitive.
+func formatUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// validateUsername verifies that a username meets the username requirements.
+// A user error reply is returned if it does not.
+func validateUsername(username string) (*plugin.Reply, bool) {
+	if len(username) < v1.UsernameMinLength ||
+		len(username) > v1.UsernameMaxLength {
+		return userErrorReply(v1.ErrorCodeUsernameInvalid,
+			"must be between %v and %v characters",
+			v1.UsernameMinLength, v1.UsernameMaxLength), false
+	}
+	if !validUsername.MatchString(username) {
+		return userErrorReply(v1.ErrorCodeUsernameInvalid,
+			"can only contain lowercase letters, numbers, and "+
+				"underscores"), false
+	}
+	return nil, true
+}
+
+// validateEmail verifies that an email address is valid. A user error reply
+// is returned if it is not.
+func validateEmail(email string) (*plugin.Reply, bool) {
+	if !validEmail.MatchString(email) {
+		return userErrorReply(v1.ErrorCodeEmailInvalid, ""), false
+	}
+	return nil, true
+}
+
+// validatePassword verifies that a password meets the password requirements.
+// A user error reply is returned if it does not.
+func (p *userpassPlugin) validatePassword(password string) (*plugin.Reply, bool) {
+	l := utf8.RuneCountInString(password)
+	if l < int(p.passwordMinLength) || l > v1.PasswordMaxLength {
+		return userErrorReply(v1.ErrorCodePasswordInvalid,
+			"must be between %v and %v characters",
+			p.passwordMinLength, v1.PasswordMaxLength), false
+	}
+	return nil, true
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package userpass
+
+import (
+	"reflect"
+	"testing"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/userpass/v1"
+)
+
+func TestValidateUsername(t *testing.T) {
+	var tests = []struct {
+		username string
+		valid    bool
+	}{
+		{"abc", true},
+		{"user_name_123", true},
+		{"ab", false},
+		{"abcdefghijklmnopqrstuvwxyz0123456", false},
+		{"user name", false},
+		{"User", false},
+		{"user-name", false},
+	}
+	for _, test := range tests {
+		t.Run(test.username, func(t *testing.T) {
+			r, valid := validateUsername(test.username)
+			if valid != test.valid {
+				t.Fatalf("got valid %v, want %v", valid, test.valid)
+			}
+			if !valid {
+				assertErrorCode(t, r, v1.ErrorCodeUsernameInvalid)
+			}
+		})
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	p := &userpassPlugin{
+		passwordMinLength: 8,
+	}
+	var tests = []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"min length", "12345678", true},
+		{"too short", "1234567", false},
+		{"multibyte", "пароль12", true},
+		{"too long", string(make([]byte, v1.PasswordMaxLength+1)), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, valid := p.validatePassword(test.password)
+			if valid != test.valid {
+				t.Fatalf("got valid %v, want %v", valid, test.valid)
+			}
+			if !valid {
+				assertErrorCode(t, r, v1.ErrorCodePasswordInvalid)
+			}
+		})
+	}
+}
+
+func TestUserEncoding(t *testing.T) {
+	pd := plugin.NewPluginData(nil, nil)
+	ud := userData{
+		Username:      "user",
+		EmailVerified: true,
+		CreatedAt:     1,
+	}
+	ud.setIdentity("key", 1)
+	us := userSecrets{
+		Email:    "user@example.com",
+		Password: newPasswordHash("password"),
+	}
+	err := encodeUser(pd, ud, us)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pd.Updated() {
+		t.Errorf("plugin data was not marked as updated")
+	}
+
+	ud2, us2, err := decodeUser(pd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*ud2, ud) {
+		t.Errorf("got user data %+v, want %+v", *ud2, ud)
+	}
+	if us2.Email != us.Email || !us2.Password.verify("password") {
+		t.Errorf("user secrets were not decoded correctly")
+	}
+}
+
+func TestSetIdentity(t *testing.T) {
+	var ud userData
+	if ud.activeIdentity() != "" {
+		t.Fatalf("got active identity %v, want none", ud.activeIdentity())
+	}
+
+	ud.setIdentity("key1", 1)
+	if ud.activeIdentity() != "key1" {
+		t.Errorf("got active identity %v, want key1", ud.activeIdentity())
+	}
+
+	// Setting a new identity deactivates the previous identity
+	ud.setIdentity("key2", 2)
+	if ud.activeIdentity() != "key2" {
+		t.Errorf("got active identity %v, want key2", ud.activeIdentity())
+	}
+	if len(ud.Identities) != 2 || ud.Identities[0].Deactivated != 2 {
+		t.Errorf("previous identity was not deactivated: %+v", ud.Identities)
+	}
+}
+
+func assertErrorCode(t *testing.T, r *plugin.Reply, want v1.ErrorCodeT) {
+	t.Helper()
+
+	if r == nil || r.Error == nil {
+		t.Fatalf("user error reply not returned")
+	}
+	ue, ok := r.Error.(plugin.UserError)
+	if !ok {
+		t.Fatalf("reply error is not a user error: %T", r.Error)
+	}
+	if ue.ErrorCode != uint32(want) {
+		t.Errorf("got error code %v, want %v", ue.ErrorCode, want)
+	}
+}
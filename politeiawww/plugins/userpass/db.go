@@ -0,0 +1,103 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package userpass
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// tableNameUsers is the table name for the users table.
+	tableNameUsers = "userpass_users"
+
+	// opTimeout is the timeout for a single database operation.
+	opTimeout = 1 * time.Minute
+)
+
+// tableUsers defines the users table. It provides a username and email
+// lookup index for the userpass users. The email address is stored as a
+// digest so that the clear text email address is only ever saved to the
+// encrypted user plugin data.
+const tableUsers = `
+  user_id      CHAR(36) NOT NULL PRIMARY KEY,
+  username     VARCHAR(64) NOT NULL UNIQUE,
+  email_digest CHAR(64) NOT NULL UNIQUE
+`
+
+// setupTables creates the plugin database tables if they do not already
+// exist.
+func setupTables(db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	q := `CREATE TABLE IF NOT EXISTS ` + tableNameUsers +
+		` (` + tableUsers + `)`
+	_, err := db.ExecContext(ctx, q)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Debugf("Created %v database table", tableNameUsers)
+
+	return nil
+}
+
+// insertUser inserts a user into the users table.
+func insertUser(tx *sql.Tx, userID, username, emailDigest string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	q := `INSERT INTO ` + tableNameUsers +
+		` (user_id, username, email_digest) VALUES (?, ?, ?)`
+	_, err := tx.ExecContext(ctx, q, userID, username, emailDigest)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// userIDByUsername returns the user ID for the provided username. An empty
+// string is returned if a user is not found.
+func userIDByUsername(tx *sql.Tx, username string) (string, error) {
+	return selectUserID(tx, "username", username)
+}
+
+// userIDByEmail returns the user ID for the provided email digest. An empty
+// string is returned if a user is not found.
+func userIDByEmail(tx *sql.Tx, emailDigest string) (string, error) {
+	return selectUserID(tx, "email_digest", emailDigest)
+}
+
+// selectUserID returns the user ID of the row where the provided column
+// matches the provided value. An empty string is returned if a row is not
+// found.
+func selectUserID(tx *sql.Tx, column, value string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	q := `SELECT user_id FROM ` + tableNameUsers + ` WHERE ` + column + ` = ?`
+	var userID string
+	err := tx.QueryRowContext(ctx, q, value).Scan(&userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", nil
+	case err != nil:
+		return "", errors.WithStack(err)
+	}
+
+	return userID, nil
+}
+
+// emailDigest returns the digest of an email address that is saved to the
+// users table. Email addresses are case insensitive.
+func emailDigest(email string) string {
+	return digest(strings.ToLower(email))
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package userpass
+
+import (
+	"github.com/decred/politeia/politeiawww/mail"
+)
+
+// The following are the names of the userpass notification email templates.
+// The built-in templates are defined in this file and can be overridden using
+// the mail templates directory.
+const (
+	mailTmplEmailVerify   = "userpass-email-verify"
+	mailTmplPasswordReset = "userpass-password-reset"
+)
+
+// emailUserEmailVerify emails the email verification token to a new user.
+func (p *userpassPlugin) emailUserEmailVerify(email, username, token string) error {
+	return p.sendTo(mailTmplEmailVerify, userEmailVerify{
+		Username: username,
+		Token:    token,
+	}, email)
+}
+
+// emailUserPasswordReset emails the password reset token to a user.
+func (p *userpassPlugin) emailUserPasswordReset(email, username, token string) error {
+	return p.sendTo(mailTmplPasswordReset, userPasswordReset{
+		Username: username,
+		Token:    token,
+	}, email)
+}
+
+// sendTo queues an email to the provided email address. The email is not
+// sent if a mailer has not been provided to the plugin, which is only allowed
+// when the tokens are returned in the command replies.
+func (p *userpassPlugin) sendTo(tmpl string, data interface{}, email string) error {
+	if p.mail == nil {
+		return nil
+	}
+	return p.mail.SendTo("", tmpl, data, []string{email})
+}
+
+// User email verify - Send the verification token to a new user
+type userEmailVerify struct {
+	Username string // User username
+	Token    string // Email verification token
+}
+
+const userEmailVerifyText = `
+Thanks for joining Politeia, {{.Username}}!
+
+Use the token below to verify your email and complete your registration.
+
+{{.Token}}
+
+You are receiving this notification because this email address was used to
+register a Politeia account.  If you did not perform this action, please ignore
+this email.
+`
+
+// User password reset - Send the password reset token to a user
+type userPasswordReset struct {
+	Username string // User username
+	Token    string // Password reset token
+}
+
+const userPasswordResetText = `
+Use the token below to continue resetting the password of {{.Username}}:
+
+{{.Token}}
+
+A password reset was initiated for this Politeia account.  If you did not
+perform this action, it's possible that your account has been compromised.
+Please contact a Politeia administrator in the Politeia channel on Matrix.
+
+https://chat.decred.org/#/room/#politeia:decred.org
+`
+
+// init registers the built-in userpass notification email templates.
+func init() {
+	mail.RegisterTemplate(mailTmplEmailVerify, mail.Template{
+		Subject: "Verify Your Email",
+		Text:    userEmailVerifyText,
+	})
+	mail.RegisterTemplate(mailTmplPasswordReset, mail.Template{
+		Subject: "Reset Your Password",
+		Text:    userPasswordResetText,
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package userpass
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+
+	v1 "github.com/decred/politeia/politeiawww/plugins/userpass/v1"
+	"github.com/decred/politeia/util"
+	"golang.org/x/crypto/argon2"
+)
+
+// passwordHash contains the argon2id hash of a user password and the
+// parameters that were used to derive it.
+type passwordHash struct {
+	Hash   []byte            `json:"hash"`
+	Params util.Argon2Params `json:"params"`
+}
+
+// newPasswordHash returns the password hash for the provided password using
+// a new random salt.
+func newPasswordHash(password string) passwordHash {
+	params := util.NewArgon2Params()
+	return passwordHash{
+		Hash:   hashPassword(password, params),
+		Params: params,
+	}
+}
+
+// verify returns whether the provided password matches the password hash.
+// The comparison is done in constant time.
+func (h passwordHash) verify(password string) bool {
+	hash := hashPassword(password, h.Params)
+	return subtle.ConstantTimeCompare(hash, h.Hash) == 1
+}
+
+// hashPassword derives the argon2id hash of a password.
+func hashPassword(password string, p util.Argon2Params) []byte {
+	return argon2.IDKey([]byte(password), p.Salt, p.Time, p.Memory,
+		p.Threads, p.KeyLen)
+}
+
+// token represents an email verification token or a password reset token.
+// Only the digest of the token is saved so that a leaked database cannot be
+// used to verify emails or reset passwords.
+type token struct {
+	Digest string `json:"digest"` // SHA256 digest of the token
+	Expiry int64  `json:"expiry"` // Unix timestamp
+}
+
+// newToken returns a new random token that expires after the provided number
+// of seconds. The token string is returned along with the token digest that
+// should be saved.
+func newToken(now, expiry int64) (string, *token, error) {
+	b, err := util.Random(32)
+	if err != nil {
+		return "", nil, err
+	}
+	t := hex.EncodeToString(b)
+	return t, &token{
+		Digest: digest(t),
+		Expiry: now + expiry,
+	}, nil
+}
+
+// verify verifies the provided token string against the token. An error code
+// is returned if the token is not valid.
+func (t *token) verify(tokenStr string, now int64) (v1.ErrorCodeT, bool) {
+	if t == nil {
+		return v1.ErrorCodeTokenInvalid, false
+	}
+	d := digest(tokenStr)
+	if subtle.ConstantTimeCompare([]byte(d), []byte(t.Digest)) != 1 {
+		return v1.ErrorCodeTokenInvalid, false
+	}
+	if now > t.Expiry {
+		return v1.ErrorCodeTokenExpired, false
+	}
+	return v1.ErrorCodeInvalid, true
+}
+
+// digest returns the hex encoded SHA256 digest of the provided string.
+func digest(s string) string {
+	d := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(d[:])
+}
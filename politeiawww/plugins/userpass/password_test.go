@@ -0,0 +1,67 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package userpass
+
+import (
+	"testing"
+
+	v1 "github.com/decred/politeia/politeiawww/plugins/userpass/v1"
+)
+
+func TestPasswordHash(t *testing.T) {
+	h := newPasswordHash("password")
+	if !h.verify("password") {
+		t.Errorf("correct password was not verified")
+	}
+	if h.verify("wrongpassword") {
+		t.Errorf("incorrect password was verified")
+	}
+
+	// The same password must not produce the same hash
+	h2 := newPasswordHash("password")
+	if string(h.Hash) == string(h2.Hash) {
+		t.Errorf("password hashes are not salted")
+	}
+}
+
+func TestTokenVerify(t *testing.T) {
+	var (
+		now    int64 = 1000
+		expiry int64 = 60
+	)
+	tokenStr, tk, err := newToken(now, expiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tk.Digest == tokenStr {
+		t.Fatalf("token digest is the clear text token")
+	}
+
+	var tests = []struct {
+		name      string
+		token     *token
+		tokenStr  string
+		now       int64
+		wantValid bool
+		wantCode  v1.ErrorCodeT
+	}{
+		{"valid token", tk, tokenStr, now + expiry, true, v1.ErrorCodeInvalid},
+		{"wrong token", tk, "deadbeef", now, false, v1.ErrorCodeTokenInvalid},
+		{"expired token", tk, tokenStr, now + expiry + 1, false,
+			v1.ErrorCodeTokenExpired},
+		{"no token", nil, tokenStr, now, false, v1.ErrorCodeTokenInvalid},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, valid := test.token.verify(test.tokenStr, test.now)
+			if valid != test.wantValid {
+				t.Errorf("got valid %v, want %v", valid, test.wantValid)
+			}
+			if code != test.wantCode {
+				t.Errorf("got error code %v, want %v", code, test.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package userpass provides a politeiawww user manager plugin that manages
+// password based user accounts.
+package userpass
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/decred/politeia/politeiawww/mail"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/userpass/v1"
+	"github.com/pkg/errors"
+)
+
+var (
+	_ plugin.Plugin      = (*userpassPlugin)(nil)
+	_ plugin.UserManager = (*userpassPlugin)(nil)
+)
+
+func init() {
+	plugin.RegisterPluginInitFn(v1.PluginID,
+		func(args plugin.InitArgs) (plugin.Plugin, error) {
+			return New(args)
+		})
+	plugin.RegisterUserManagerInitFn(v1.PluginID,
+		func(args plugin.InitArgs) (plugin.UserManager, error) {
+			return New(args)
+		})
+}
+
+// userpassPlugin implements the politeiawww plugin and user manager
+// interfaces.
+type userpassPlugin struct {
+	sync.RWMutex
+	db     *sql.DB
+	userDB plugin.UserDB
+
+	// mail is used to email the verification and reset tokens to the
+	// users. It is only nil when the tokens are returned in the command
+	// replies, which is only allowed for testing.
+	mail mail.Mailer
+
+	// permissions contains the user permission level for each of the
+	// plugin commands.
+	permissions map[string]string // [cmd]permissionLevel
+
+	// Plugin settings
+	passwordMinLength uint32
+	tokenExpiry       int64 // In seconds
+	returnTokens      bool
+}
+
+// ID returns the plugin ID.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *userpassPlugin) ID() string {
+	return v1.PluginID
+}
+
+// Version returns the lowest supported plugin API version.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *userpassPlugin) Version() uint32 {
+	return v1.Version
+}
+
+// SetPermission sets the user permission level for a command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *userpassPlugin) SetPermission(cmd, permissionLevel string) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.permissions[cmd] = permissionLevel
+}
+
+// Permissions returns the user permission level for each of the plugin
+// commands.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *userpassPlugin) Permissions() map[string]string {
+	p.RLock()
+	defer p.RUnlock()
+
+	perms := make(map[string]string, len(p.permissions))
+	for k, v := range p.permissions {
+		perms[k] = v
+	}
+	return perms
+}
+
+// Hook executes a plugin hook.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *userpassPlugin) Hook(h plugin.HookArgs) error {
+	log.Tracef("Hook: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// HookTx executes a plugin hook using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *userpassPlugin) HookTx(tx *sql.Tx, h plugin.HookArgs) error {
+	log.Tracef("HookTx: %v %v", h.Type, h.Cmd.Cmd)
+
+	return nil
+}
+
+// NewUser executes a command that results in a new user being added to the
+// database.
+//
+// This function satisfies the plugin.UserManager interface.
+func (p *userpassPlugin) NewUser(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	log.Tracef("NewUser: %v", args.Cmd.Cmd)
+
+	if args.Cmd.Cmd != v1.CmdNewUser {
+		return userErrorReply(v1.ErrorCodeInvalidInput,
+			"invalid new user cmd '%v'", args.Cmd.Cmd), nil
+	}
+
+	return p.cmdNewUser(tx, args)
+}
+
+// ActiveIdentity returns the public key of the active identity of the
+// provided user.
+//
+// This function satisfies the plugin.Identities interface.
+func (p *userpassPlugin) ActiveIdentity(tx *sql.Tx, userID string) (string, error) {
+	u, err := p.userDB.TxGet(tx, userID)
+	if err != nil {
+		return "", err
+	}
+	ud, _, err := decodeUser(u.PluginData)
+	if err != nil {
+		return "", err
+	}
+	pk := ud.activeIdentity()
+	if pk == "" {
+		return "", plugin.ErrIdentityNotFound
+	}
+	return pk, nil
+}
+
+// Username returns the username of the provided user.
+//
+// This function satisfies the plugin.Identities interface.
+func (p *userpassPlugin) Username(tx *sql.Tx, userID string) (string, error) {
+	u, err := p.userDB.TxGet(tx, userID)
+	if err != nil {
+		return "", err
+	}
+	ud, _, err := decodeUser(u.PluginData)
+	if err != nil {
+		return "", err
+	}
+	return ud.Username, nil
+}
+
+// WriteTx executes a write plugin command using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *userpassPlugin) WriteTx(tx *sql.Tx, args plugin.WriteArgs) (*plugin.Reply, error) {
+	log.Tracef("WriteTx: %v", args.Cmd.Cmd)
+
+	switch args.Cmd.Cmd {
+	case v1.CmdVerifyEmail:
+		return p.cmdVerifyEmail(tx, args)
+	case v1.CmdLogin:
+		return p.cmdLogin(tx, args)
+	case v1.CmdLogout:
+		return p.cmdLogout(args)
+	case v1.CmdChangePassword:
+		return p.cmdChangePassword(args)
+	case v1.CmdResetPassword:
+		return p.cmdResetPassword(tx, args)
+	case v1.CmdVerifyResetPassword:
+		return p.cmdVerifyResetPassword(tx, args)
+	case v1.CmdSetIdentity:
+		return p.cmdSetIdentity(args)
+	}
+
+	return userErrorReply(v1.ErrorCodeInvalidInput,
+		"invalid write cmd '%v'", args.Cmd.Cmd), nil
+}
+
+// Read executes a read plugin command.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *userpassPlugin) Read(args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("Read: %v", args.Cmd.Cmd)
+
+	switch args.Cmd.Cmd {
+	case v1.CmdMe:
+		return p.cmdMe(args)
+	}
+
+	return userErrorReply(v1.ErrorCodeInvalidInput,
+		"invalid read cmd '%v'", args.Cmd.Cmd), nil
+}
+
+// ReadTx executes a read plugin command using a database transaction.
+//
+// This function satisfies the plugin.Plugin interface.
+func (p *userpassPlugin) ReadTx(tx *sql.Tx, args plugin.ReadArgs) (*plugin.Reply, error) {
+	log.Tracef("ReadTx: %v", args.Cmd.Cmd)
+
+	return p.Read(args)
+}
+
+// New returns a new userpassPlugin.
+func New(args plugin.InitArgs) (*userpassPlugin, error) {
+	// Default plugin settings
+	var (
+		passwordMinLength = v1.SettingPasswordMinLength
+		tokenExpiry       = v1.SettingTokenExpiry
+		returnTokens      = v1.SettingReturnTokens
+	)
+
+	// Override defaults with any passed in settings
+	for _, v := range args.Settings {
+		switch v.Key {
+		case v1.SettingKeyPasswordMinLength:
+			u, err := strconv.ParseUint(v.Value, 10, 32)
+			if err != nil {
+				return nil, errors.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+			passwordMinLength = uint32(u)
+			log.Infof("Plugin setting updated: %v %v",
+				v.Key, passwordMinLength)
+
+		case v1.SettingKeyTokenExpiry:
+			i, err := strconv.ParseInt(v.Value, 10, 64)
+			if err != nil || i <= 0 {
+				return nil, errors.Errorf("invalid plugin setting %v '%v'",
+					v.Key, v.Value)
+			}
+			tokenExpiry = i
+			log.Infof("Plugin setting updated: %v %v",
+				v.Key, tokenExpiry)
+
+		case v1.SettingKeyReturnTokens:
+			b, err := strconv.ParseBool(v.Value)
+			if err != nil {
+				return nil, errors.Errorf("invalid plugin setting %v '%v': %v",
+					v.Key, v.Value, err)
+			}
+			returnTokens = b
+			log.Infof("Plugin setting updated: %v %v",
+				v.Key, returnTokens)
+			if returnTokens {
+				log.Warnf("**********************************************")
+				log.Warnf("The %v setting is enabled. The email ",
+					v1.SettingKeyReturnTokens)
+				log.Warnf("verification and password reset tokens are")
+				log.Warnf("returned in the command replies. Anyone can")
+				log.Warnf("verify any email address and reset the")
+				log.Warnf("password of any user. This setting must ONLY")
+				log.Warnf("be used for testing.")
+				log.Warnf("**********************************************")
+			}
+
+		default:
+			return nil, errors.Errorf("invalid plugin setting: %v", v.Key)
+		}
+	}
+
+	// The verification and reset tokens are emailed to the users. Users
+	// would not be able to verify their email address or reset their
+	// password without email, so the plugin refuses to start unless
+	// email has been configured. The test only setting that returns the
+	// tokens in the command replies is the only exception.
+	mailer := args.Mail
+	if mailer != nil && !mailer.IsEnabled() {
+		mailer = nil
+	}
+	if mailer == nil && !returnTokens {
+		return nil, errors.Errorf("a mailer has not been configured for " +
+			"the userpass plugin; the verification and reset tokens are " +
+			"emailed to the users")
+	}
+
+	// Setup the database tables
+	if args.DB == nil {
+		return nil, errors.Errorf("database not provided")
+	}
+	err := setupTables(args.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userpassPlugin{
+		db:     args.DB,
+		userDB: args.UserDB,
+		mail:   mailer,
+		permissions: map[string]string{
+			v1.CmdNewUser:             plugin.PermissionPublic,
+			v1.CmdVerifyEmail:         plugin.PermissionPublic,
+			v1.CmdLogin:               plugin.PermissionPublic,
+			v1.CmdLogout:              plugin.PermissionPublic,
+			v1.CmdChangePassword:      plugin.PermissionUser,
+			v1.CmdResetPassword:       plugin.PermissionPublic,
+			v1.CmdVerifyResetPassword: plugin.PermissionPublic,
+			v1.CmdSetIdentity:         plugin.PermissionUser,
+			v1.CmdMe:                  plugin.PermissionUser,
+		},
+		passwordMinLength: passwordMinLength,
+		tokenExpiry:       tokenExpiry,
+		returnTokens:      returnTokens,
+	}, nil
+}
+
+// userErrorReply returns a plugin reply that contains a user error.
+func userErrorReply(e v1.ErrorCodeT, format string, args ...interface{}) *plugin.Reply {
+	return &plugin.Reply{
+		Error: plugin.UserError{
+			PluginID:     v1.PluginID,
+			ErrorCode:    uint32(e),
+			ErrorContext: fmt.Sprintf(format, args...),
+		},
+	}
+}
+
+// newReply returns a plugin reply that contains the JSON encoded payload.
+func newReply(payload interface{}) (*plugin.Reply, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.Reply{
+		Payload: string(b),
+	}, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package userpass
+
+import (
+	"strings"
+	"testing"
+
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	v1 "github.com/decred/politeia/politeiawww/plugins/userpass/v1"
+	"github.com/google/uuid"
+)
+
+// testMailer implements the mail Mailer interface. It records the emails that
+// are sent using SendTo.
+type testMailer struct {
+	enabled bool
+	sent    map[string][]string // [template]recipients
+}
+
+func (m *testMailer) IsEnabled() bool     { return m.enabled }
+func (m *testMailer) Languages() []string { return nil }
+func (m *testMailer) Close()              {}
+
+func (m *testMailer) SendTo(key, tmpl string, data interface{}, recipients []string) error {
+	m.sent[tmpl] = append(m.sent[tmpl], recipients...)
+	return nil
+}
+
+func (m *testMailer) SendToUsers(key, tmpl string, data interface{}, recipients map[uuid.UUID]string) error {
+	return nil
+}
+
+func TestNewRequiresTokenDelivery(t *testing.T) {
+	// The plugin must refuse to start when the tokens cannot be
+	// delivered to the users.
+	_, err := New(plugin.InitArgs{})
+	if err == nil || !strings.Contains(err.Error(), "mailer") {
+		t.Fatalf("got error '%v', want mailer error", err)
+	}
+	_, err = New(plugin.InitArgs{
+		Mail: &testMailer{enabled: false},
+	})
+	if err == nil || !strings.Contains(err.Error(), "mailer") {
+		t.Fatalf("got error '%v', want mailer error for disabled mail", err)
+	}
+
+	// The token delivery check passes when email is enabled or when
+	// the tokens are returned in the replies. The plugin then fails
+	// on the missing database.
+	_, err = New(plugin.InitArgs{
+		Mail: &testMailer{enabled: true},
+	})
+	if err == nil || strings.Contains(err.Error(), "mailer") {
+		t.Fatalf("got error '%v', want database error", err)
+	}
+	_, err = New(plugin.InitArgs{
+		Settings: []plugin.Setting{
+			{
+				Key:   v1.SettingKeyReturnTokens,
+				Value: "true",
+			},
+		},
+	})
+	if err == nil || strings.Contains(err.Error(), "mailer") {
+		t.Fatalf("got error '%v', want database error", err)
+	}
+}
+
+func TestEmailTokens(t *testing.T) {
+	m := &testMailer{
+		enabled: true,
+		sent:    make(map[string][]string),
+	}
+	p := &userpassPlugin{
+		mail: m,
+	}
+	err := p.emailUserEmailVerify("user@example.org", "user", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.emailUserPasswordReset("user@example.org", "user", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tmpl := range []string{mailTmplEmailVerify, mailTmplPasswordReset} {
+		r := m.sent[tmpl]
+		if len(r) != 1 || r[0] != "user@example.org" {
+			t.Errorf("%v: got recipients %v", tmpl, r)
+		}
+	}
+
+	// Emails are skipped when a mailer has not been provided
+	p.mail = nil
+	err = p.emailUserEmailVerify("user@example.org", "user", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
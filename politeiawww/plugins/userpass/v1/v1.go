@@ -0,0 +1,293 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package v1 contains the API for the userpass plugin. The userpass plugin is
+// a politeiawww user manager plugin that provides password based user
+// accounts.
+package v1
+
+const (
+	// PluginID is the unique identifier for this plugin.
+	PluginID = "userpass"
+
+	// Version is the plugin API version.
+	Version uint32 = 1
+)
+
+// Plugin commands. The NewUser command must be executed using the politeiawww
+// NewUser route. The Me command is a read-only command. All other commands
+// are write commands.
+const (
+	// CmdNewUser command creates a new user.
+	CmdNewUser = "newuser"
+
+	// CmdVerifyEmail command verifies the email address of a user.
+	CmdVerifyEmail = "verifyemail"
+
+	// CmdLogin command logs a user in.
+	CmdLogin = "login"
+
+	// CmdLogout command logs a user out.
+	CmdLogout = "logout"
+
+	// CmdChangePassword command changes the password of the logged in user.
+	CmdChangePassword = "changepassword"
+
+	// CmdResetPassword command starts the password reset process for a user
+	// that has forgotten their password.
+	CmdResetPassword = "resetpassword"
+
+	// CmdVerifyResetPassword command completes the password reset process.
+	CmdVerifyResetPassword = "verifyresetpassword"
+
+	// CmdSetIdentity command sets the active identity of the logged in user.
+	CmdSetIdentity = "setidentity"
+
+	// CmdMe command returns the account details of the logged in user.
+	CmdMe = "me"
+)
+
+// Plugin setting keys can be used to specify custom plugin settings. Default
+// plugin setting values can be overridden by providing a plugin setting key
+// and value to the plugin on startup.
+const (
+	// SettingKeyPasswordMinLength is the plugin setting key for the
+	// SettingPasswordMinLength plugin setting.
+	SettingKeyPasswordMinLength = "passwordminlength"
+
+	// SettingKeyTokenExpiry is the plugin setting key for the
+	// SettingTokenExpiry plugin setting.
+	SettingKeyTokenExpiry = "tokenexpiry"
+
+	// SettingKeyReturnTokens is the plugin setting key for the
+	// SettingReturnTokens plugin setting.
+	SettingKeyReturnTokens = "returntokens"
+)
+
+// Plugin setting default values. These can be overridden by providing a plugin
+// setting key and value to the plugin on startup.
+const (
+	// SettingPasswordMinLength is the default minimum number of characters
+	// that a password must contain.
+	SettingPasswordMinLength uint32 = 8
+
+	// SettingTokenExpiry is the default number of seconds that an email
+	// verification token or a password reset token is valid for.
+	SettingTokenExpiry int64 = 86400 // 1 day
+
+	// SettingReturnTokens is the default setting for whether the email
+	// verification tokens and the password reset tokens are returned in the
+	// command replies. The tokens are always emailed to the users. Returning
+	// them in the command replies allows anyone to verify an email address
+	// that they do not own or to reset the password of any user whose email
+	// address they know. This setting must only be enabled for testing.
+	SettingReturnTokens = false
+)
+
+const (
+	// UsernameMinLength is the minimum number of characters that a username
+	// must contain.
+	UsernameMinLength = 3
+
+	// UsernameMaxLength is the maximum number of characters that a username
+	// can contain.
+	UsernameMaxLength = 32
+
+	// PasswordMaxLength is the maximum number of characters that a password
+	// can contain.
+	PasswordMaxLength = 128
+)
+
+// ErrorCodeT represents a plugin error that was caused by the user.
+type ErrorCodeT uint32
+
+const (
+	// ErrorCodeInvalid is an invalid error code.
+	ErrorCodeInvalid ErrorCodeT = 0
+
+	// ErrorCodeInvalidInput is returned when the command payload could not be
+	// decoded or the command is not a valid command.
+	ErrorCodeInvalidInput ErrorCodeT = 1
+
+	// ErrorCodeUsernameInvalid is returned when a username does not meet the
+	// username requirements. Usernames can only contain lowercase letters,
+	// numbers, and underscores.
+	ErrorCodeUsernameInvalid ErrorCodeT = 2
+
+	// ErrorCodeEmailInvalid is returned when an email address is not valid.
+	ErrorCodeEmailInvalid ErrorCodeT = 3
+
+	// ErrorCodePasswordInvalid is returned when a password does not meet the
+	// password requirements.
+	ErrorCodePasswordInvalid ErrorCodeT = 4
+
+	// ErrorCodeUsernameTaken is returned when a username is already in use.
+	ErrorCodeUsernameTaken ErrorCodeT = 5
+
+	// ErrorCodeEmailTaken is returned when an email address is already in
+	// use.
+	ErrorCodeEmailTaken ErrorCodeT = 6
+
+	// ErrorCodeLoginFailed is returned when the username or password provided
+	// during login is incorrect. The same error is returned for both cases so
+	// that usernames cannot be enumerated.
+	ErrorCodeLoginFailed ErrorCodeT = 7
+
+	// ErrorCodeEmailNotVerified is returned when a user attempts to login
+	// before verifying their email address.
+	ErrorCodeEmailNotVerified ErrorCodeT = 8
+
+	// ErrorCodeTokenInvalid is returned when an email verification token or a
+	// password reset token is not valid.
+	ErrorCodeTokenInvalid ErrorCodeT = 9
+
+	// ErrorCodeTokenExpired is returned when an email verification token or a
+	// password reset token has expired.
+	ErrorCodeTokenExpired ErrorCodeT = 10
+
+	// ErrorCodeNotLoggedIn is returned when a command that requires a logged
+	// in user is executed without one.
+	ErrorCodeNotLoggedIn ErrorCodeT = 11
+
+	// ErrorCodePublicKeyInvalid is returned when a public key is not a valid
+	// hex encoded ed25519 public key.
+	ErrorCodePublicKeyInvalid ErrorCodeT = 12
+
+	// ErrorCodeSignatureInvalid is returned when a signature is not valid.
+	ErrorCodeSignatureInvalid ErrorCodeT = 13
+
+	// ErrorCodeLast unit test only.
+	ErrorCodeLast ErrorCodeT = 14
+)
+
+var (
+	// ErrorCodes contains the human readable errors.
+	ErrorCodes = map[ErrorCodeT]string{
+		ErrorCodeInvalid:          "error code invalid",
+		ErrorCodeInvalidInput:     "invalid input",
+		ErrorCodeUsernameInvalid:  "username invalid",
+		ErrorCodeEmailInvalid:     "email invalid",
+		ErrorCodePasswordInvalid:  "password invalid",
+		ErrorCodeUsernameTaken:    "username taken",
+		ErrorCodeEmailTaken:       "email taken",
+		ErrorCodeLoginFailed:      "login failed",
+		ErrorCodeEmailNotVerified: "email not verified",
+		ErrorCodeTokenInvalid:     "token invalid",
+		ErrorCodeTokenExpired:     "token expired",
+		ErrorCodeNotLoggedIn:      "not logged in",
+		ErrorCodePublicKeyInvalid: "public key invalid",
+		ErrorCodeSignatureInvalid: "signature invalid",
+	}
+)
+
+// NewUser creates a new user. The email address must be verified using the
+// VerifyEmail command before the user is able to login. The verification token
+// is emailed to the user.
+type NewUser struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// NewUserReply is the reply to the NewUser command.
+//
+// The VerificationToken is only returned when the ReturnTokens plugin setting
+// has been enabled. This setting is only used for testing.
+type NewUserReply struct {
+	UserID            string `json:"userid"`
+	VerificationToken string `json:"verificationtoken,omitempty"`
+}
+
+// VerifyEmail verifies the email address of a user using the verification
+// token that was created when the user was created.
+type VerifyEmail struct {
+	Username string `json:"username"`
+	Token    string `json:"token"`
+}
+
+// VerifyEmailReply is the reply to the VerifyEmail command.
+type VerifyEmailReply struct{}
+
+// Login logs a user in. The session of the client is updated to contain the
+// user ID on success.
+type Login struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// LoginReply is the reply to the Login command.
+type LoginReply struct {
+	UserID string `json:"userid"`
+}
+
+// Logout logs the user out by deleting the session of the client.
+type Logout struct{}
+
+// LogoutReply is the reply to the Logout command.
+type LogoutReply struct{}
+
+// ChangePassword changes the password of the logged in user.
+type ChangePassword struct {
+	CurrentPassword string `json:"currentpassword"`
+	NewPassword     string `json:"newpassword"`
+}
+
+// ChangePasswordReply is the reply to the ChangePassword command.
+type ChangePasswordReply struct{}
+
+// ResetPassword starts the password reset process for a user. A password
+// reset token is created if the username and email address match an
+// existing user and is emailed to the user. The reply is the same whether or
+// not a match was found so that users cannot be enumerated.
+type ResetPassword struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// ResetPasswordReply is the reply to the ResetPassword command.
+//
+// The ResetToken is only returned when the ReturnTokens plugin setting has
+// been enabled. This setting is only used for testing.
+type ResetPasswordReply struct {
+	ResetToken string `json:"resettoken,omitempty"`
+}
+
+// VerifyResetPassword completes the password reset process by setting a new
+// password for the user.
+type VerifyResetPassword struct {
+	Username    string `json:"username"`
+	Token       string `json:"token"`
+	NewPassword string `json:"newpassword"`
+}
+
+// VerifyResetPasswordReply is the reply to the VerifyResetPassword command.
+type VerifyResetPasswordReply struct{}
+
+// SetIdentity sets the active identity of the logged in user. The previous
+// identity is deactivated. The identity is used to verify the signatures of
+// the payloads that the user submits to other plugins, e.g. records.
+//
+// Signature is the signature of the user ID using the new identity. It proves
+// that the user controls the private key of the identity.
+type SetIdentity struct {
+	PublicKey string `json:"publickey"`
+	Signature string `json:"signature"`
+}
+
+// SetIdentityReply is the reply to the SetIdentity command.
+type SetIdentityReply struct{}
+
+// Me returns the account details of the logged in user.
+type Me struct{}
+
+// MeReply is the reply to the Me command.
+type MeReply struct {
+	UserID        string `json:"userid"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailverified"`
+	CreatedAt     int64  `json:"createdat"`
+	LastLoginAt   int64  `json:"lastloginat"`
+	PublicKey     string `json:"publickey,omitempty"` // Active identity
+}
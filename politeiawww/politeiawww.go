@@ -9,6 +9,7 @@ import (
 	"crypto/tls"
 	"database/sql"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -16,16 +17,18 @@ import (
 	"time"
 
 	pdclient "github.com/decred/politeia/politeiad/client"
+	"github.com/decred/politeia/politeiawww/apikeys"
 	"github.com/decred/politeia/politeiawww/config"
 	"github.com/decred/politeia/politeiawww/events"
 	"github.com/decred/politeia/politeiawww/legacy"
 	"github.com/decred/politeia/politeiawww/logger"
+	"github.com/decred/politeia/politeiawww/mail"
 	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/decred/politeia/politeiawww/ratelimit"
 	"github.com/decred/politeia/politeiawww/user"
 	"github.com/decred/politeia/util"
 	"github.com/decred/politeia/util/version"
 	"github.com/gorilla/mux"
-	"github.com/gorilla/sessions"
 )
 
 // politeiawww represents the politeiawww server.
@@ -34,11 +37,21 @@ type politeiawww struct {
 	router    *mux.Router // Unprotected router
 	protected *mux.Router // CSRF protected subrouter
 
+	// rateLimiter applies the rate limit policies from the config to the
+	// client requests.
+	rateLimiter *ratelimit.Limiter
+
+	// trustedProxies contains the reverse proxies that are allowed to set
+	// the X-Forwarded-For header. The header is ignored for requests that
+	// were not sent by a trusted proxy.
+	trustedProxies []*net.IPNet
+
 	// Database layer. The sql DB is used as the backing database for the
 	// following interfaces.
 	db       *sql.DB
-	sessions sessions.Store
+	sessions sessionStore
 	userDB   user.DB
+	apiKeys  apikeys.DB
 
 	// pluginIDs contains the plugin IDs of all registered plugins, ordered in
 	// the same order that they were provided to the config in. This is the order
@@ -60,6 +73,9 @@ type politeiawww struct {
 	// must be specified if the user layer is enabled.
 	authManager plugin.AuthManager
 
+	// mail queues the notification emails that are sent by the plugins.
+	mail mail.Mailer
+
 	// Legacy fields
 	politeiad *pdclient.Client
 	events    *events.Manager
@@ -122,16 +138,20 @@ func _main() error {
 		router:    nil, // Set in setupRouter()
 		protected: nil, // Set in setupRouter()
 
-		// Not implemented yet
+		rateLimiter: nil, // Set in setupRouter()
+
+		// The database fields are setup by setupDB()
 		db:       nil,
 		sessions: nil,
 		userDB:   nil,
+		apiKeys:  nil,
 
 		// The plugin fields are setup by setupPlugins()
 		pluginIDs:   cfg.Plugins,
 		plugins:     nil,
 		userManager: nil,
 		authManager: nil,
+		mail:        nil, // Set in setupMail()
 
 		// Legacy fields
 		politeiad: pdc,
@@ -150,7 +170,15 @@ func _main() error {
 	// disabled then the plugin routes will be setup.
 	if cfg.DisableLegacy {
 		// Legacy routes have been disabled
+		err = p.setupDB()
+		if err != nil {
+			return err
+		}
 		p.setupPluginRoutes()
+		err = p.setupMail()
+		if err != nil {
+			return err
+		}
 		err = p.setupPlugins()
 		if err != nil {
 			return err
@@ -225,6 +253,12 @@ done:
 	if p.legacy != nil {
 		p.legacy.Close()
 	}
+	if p.mail != nil {
+		p.mail.Close()
+	}
+	if p.db != nil {
+		p.db.Close()
+	}
 
 	return nil
 }
@@ -0,0 +1,231 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pdv1 "github.com/decred/politeia/politeiad/api/v1"
+	v3 "github.com/decred/politeia/politeiawww/api/http/v3"
+	"github.com/decred/politeia/politeiawww/ratelimit"
+	"github.com/decred/politeia/util"
+)
+
+const (
+	// retryAfterHeader is the header that contains the number of seconds
+	// that a rate limited client must wait before retrying the request.
+	retryAfterHeader = "Retry-After"
+)
+
+// setupRateLimiter parses the rate limit policies and the trusted proxies
+// from the config and sets up the rate limiter.
+func (p *politeiawww) setupRateLimiter() error {
+	proxies, err := parseTrustedProxies(p.cfg.TrustedProxies)
+	if err != nil {
+		return err
+	}
+	for _, v := range proxies {
+		log.Infof("Trusted proxy: %v", v)
+	}
+	p.trustedProxies = proxies
+
+	policies := make([]ratelimit.Policy, 0, len(p.cfg.RateLimits))
+	for _, v := range p.cfg.RateLimits {
+		policy, err := ratelimit.ParsePolicy(v)
+		if err != nil {
+			return err
+		}
+		policies = append(policies, *policy)
+
+		log.Infof("Rate limit: %v", policy)
+	}
+	p.rateLimiter = ratelimit.New(policies)
+	return nil
+}
+
+// allowCmds applies the plugin command rate limit policies to the provided
+// plugin commands. A 429 is sent to the client and false is returned if any
+// of the commands exceed a rate limit.
+func (p *politeiawww) allowCmds(w http.ResponseWriter, r *http.Request, cmds []v3.Cmd) bool {
+	var (
+		c        ratelimit.Client
+		clientOK bool
+	)
+	for _, cmd := range cmds {
+		target := ratelimit.PluginCmdTarget(cmd.PluginID, cmd.Cmd)
+		if !p.rateLimiter.HasPolicy(target) {
+			continue
+		}
+		if !clientOK {
+			c = p.rateLimitClient(r)
+			clientOK = true
+		}
+		if ok, wait := p.rateLimiter.Allow(target, c); !ok {
+			respondWithRateLimit(w, r, target, wait)
+			return false
+		}
+	}
+	return true
+}
+
+// rateLimitClient returns the fields that are used to identify the client
+// that sent the request.
+func (p *politeiawww) rateLimitClient(r *http.Request) ratelimit.Client {
+	c := ratelimit.Client{
+		IP: clientIP(r, p.trustedProxies),
+	}
+	if p.sessions == nil {
+		return c
+	}
+
+	// Requests that contain an API key are identified by their IP. The
+	// API key has not been verified yet, so neither the key ID nor the
+	// user ID of the key can be trusted. Using the key ID would allow a
+	// client to get a new bucket for every request by sending random
+	// key IDs.
+	if r.Header.Get(v3.APIKeyHeader) != "" {
+		return c
+	}
+
+	s, err := p.extractSession(r)
+	if err != nil {
+		// The client will be identified by their IP
+		log.Debugf("rateLimitClient: extractSession: %v", err)
+		return c
+	}
+	if s.IsNew {
+		// A new session is created for every request that does not
+		// contain a session cookie. The client will be identified by
+		// their IP.
+		return c
+	}
+	c.SessionID = s.ID
+	if userID, ok := s.Values[sessionValueUserID].(string); ok {
+		c.UserID = userID
+	}
+	return c
+}
+
+// parseTrustedProxies parses the provided trusted proxies. A trusted proxy
+// can be provided as an IP address or as a CIDR.
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, v := range proxies {
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %v", v)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{
+				IP:   ip,
+				Mask: net.CIDRMask(bits, bits),
+			})
+			continue
+		}
+		_, n, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %v: %v", v, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// isTrustedProxy returns whether the provided IP address belongs to one of
+// the trusted proxies.
+func isTrustedProxy(ip string, proxies []*net.IPNet) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, v := range proxies {
+		if v.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the IP address of the client that sent the request.
+//
+// The X-Forwarded-For header is set by the client and can contain arbitrary
+// addresses, so it is only honored when the request was sent by one of the
+// provided trusted proxies. The header is walked from right to left, skipping
+// the addresses of trusted proxies, and the first untrusted address is the
+// client address. The remote address of the connection is used for requests
+// that were not sent by a trusted proxy.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+	xff := r.Header.Values(pdv1.Forward)
+	ips := strings.Split(strings.Join(xff, ","), ",")
+	for i := len(ips) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(ips[i])
+		if addr == "" {
+			continue
+		}
+		if net.ParseIP(addr) == nil {
+			// The header is malformed. Fall back to the address of
+			// the last trusted hop.
+			return ip
+		}
+		if !isTrustedProxy(addr, trustedProxies) {
+			return addr
+		}
+		ip = addr
+	}
+	return ip
+}
+
+// respondWithRateLimit sends a 429 to the client that contains the number of
+// seconds that the client must wait before retrying the request.
+func respondWithRateLimit(w http.ResponseWriter, r *http.Request, target string, wait time.Duration) {
+	retryAfter := int64(math.Ceil(wait.Seconds()))
+
+	log.Infof("%v Rate limit exceeded: %v, retry after %vs",
+		util.RemoteAddr(r), target, retryAfter)
+
+	w.Header().Set(retryAfterHeader, strconv.FormatInt(retryAfter, 10))
+	util.RespondWithJSON(w, http.StatusTooManyRequests,
+		v3.UserError{
+			ErrorCode: v3.ErrorCodeRateLimitExceeded,
+			ErrorContext: fmt.Sprintf("%v: retry after %v seconds",
+				target, retryAfter),
+		})
+}
+
+// convertRateLimitsToHTTP converts the rate limiter stats to http v3 rate
+// limits.
+func convertRateLimitsToHTTP(stats []ratelimit.Stats) []v3.RateLimit {
+	rl := make([]v3.RateLimit, 0, len(stats))
+	for _, v := range stats {
+		rl = append(rl, v3.RateLimit{
+			Target:   v.Policy.Target,
+			Key:      string(v.Policy.Key),
+			Rate:     v.Policy.Rate,
+			Interval: uint32(v.Policy.Interval.Seconds()),
+			Burst:    v.Policy.Burst,
+			Allowed:  v.Allowed,
+			Limited:  v.Limited,
+		})
+	}
+	return rl
+}
@@ -0,0 +1,366 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package ratelimit provides a token bucket rate limiter that applies
+// configurable policies to client requests.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyT represents the key that a rate limit policy uses to identify a client.
+type KeyT string
+
+const (
+	// KeyIP identifies clients by their IP address.
+	KeyIP KeyT = "ip"
+
+	// KeyUser identifies clients by their user ID. Clients that are not
+	// logged in are identified by their session ID. Clients that do not have
+	// a session are identified by their IP address.
+	KeyUser KeyT = "user"
+)
+
+const (
+	// pruneInterval is the interval at which the buckets that have been
+	// fully refilled are removed from memory. A fully refilled bucket is
+	// indistinguishable from a new bucket so removing it has no effect on
+	// the rate limiting.
+	pruneInterval = time.Minute
+)
+
+// Policy describes a rate limit that is applied to a target. The target can
+// either be a route, e.g. "/v3/read", or a plugin command in the format
+// "pluginID.cmd", e.g. "comments.new".
+//
+// Each client is given its own token bucket. A bucket holds at most Burst
+// tokens and is refilled at a rate of Rate tokens per Interval. Every request
+// consumes a single token. Requests are rejected once the bucket is empty.
+type Policy struct {
+	Target   string
+	Key      KeyT
+	Rate     uint32
+	Interval time.Duration
+	Burst    uint32
+}
+
+// String returns the string representation of the policy. It uses the same
+// format that is accepted by ParsePolicy.
+func (p Policy) String() string {
+	return fmt.Sprintf("%v,%v,%v/%v,%v", p.Target, p.Key, p.Rate,
+		intervalSymbol(p.Interval), p.Burst)
+}
+
+// ParsePolicy parses a rate limit policy from the provided string. The policy
+// string must be in the format:
+//
+// "target,key,rate/interval,burst"
+//
+// The target is either a route or a plugin command in the format
+// "pluginID.cmd". The key is either "ip" or "user". The interval is either
+// "s" (second), "m" (minute), or "h" (hour). The burst is optional and
+// defaults to the rate.
+//
+// Examples:
+// "/v3/write,user,10/m,20"
+// "comments.new,user,5/m"
+func ParsePolicy(s string) (*Policy, error) {
+	fields := strings.Split(s, ",")
+	if len(fields) != 3 && len(fields) != 4 {
+		return nil, fmt.Errorf("invalid policy '%v': want format "+
+			"target,key,rate/interval,burst", s)
+	}
+
+	// Parse the target
+	target := strings.TrimSpace(fields[0])
+	if target == "" {
+		return nil, fmt.Errorf("invalid policy '%v': target missing", s)
+	}
+	if !strings.HasPrefix(target, "/") {
+		// The target is a plugin command. Verify
+		// that it contains both a plugin ID and a
+		// command.
+		pc := strings.Split(target, ".")
+		if len(pc) != 2 || pc[0] == "" || pc[1] == "" {
+			return nil, fmt.Errorf("invalid policy '%v': target must be "+
+				"a route or in the format pluginID.cmd", s)
+		}
+	}
+
+	// Parse the key
+	key := KeyT(strings.TrimSpace(fields[1]))
+	switch key {
+	case KeyIP, KeyUser:
+		// These are allowed; continue
+	default:
+		return nil, fmt.Errorf("invalid policy '%v': key must be "+
+			"'%v' or '%v'", s, KeyIP, KeyUser)
+	}
+
+	// Parse the rate and interval
+	ri := strings.Split(strings.TrimSpace(fields[2]), "/")
+	if len(ri) != 2 {
+		return nil, fmt.Errorf("invalid policy '%v': rate must be in "+
+			"the format rate/interval", s)
+	}
+	rate, err := strconv.ParseUint(ri[0], 10, 32)
+	if err != nil || rate == 0 {
+		return nil, fmt.Errorf("invalid policy '%v': invalid rate '%v'",
+			s, ri[0])
+	}
+	var interval time.Duration
+	switch ri[1] {
+	case "s":
+		interval = time.Second
+	case "m":
+		interval = time.Minute
+	case "h":
+		interval = time.Hour
+	default:
+		return nil, fmt.Errorf("invalid policy '%v': interval must be "+
+			"'s', 'm', or 'h'", s)
+	}
+
+	// Parse the burst
+	burst := rate
+	if len(fields) == 4 {
+		burst, err = strconv.ParseUint(strings.TrimSpace(fields[3]), 10, 32)
+		if err != nil || burst == 0 {
+			return nil, fmt.Errorf("invalid policy '%v': invalid burst '%v'",
+				s, fields[3])
+		}
+	}
+
+	return &Policy{
+		Target:   target,
+		Key:      key,
+		Rate:     uint32(rate),
+		Interval: interval,
+		Burst:    uint32(burst),
+	}, nil
+}
+
+// PluginCmdTarget returns the policy target for a plugin command.
+func PluginCmdTarget(pluginID, cmd string) string {
+	return pluginID + "." + cmd
+}
+
+// Client contains the fields that are used to identify a client.
+type Client struct {
+	IP        string
+	UserID    string
+	SessionID string
+}
+
+// key returns the bucket key for the client using the provided key type.
+func (c Client) key(k KeyT) string {
+	if k == KeyUser {
+		switch {
+		case c.UserID != "":
+			return "user:" + c.UserID
+		case c.SessionID != "":
+			return "session:" + c.SessionID
+		}
+	}
+	return "ip:" + c.IP
+}
+
+// Stats contains the counters of a rate limit policy.
+type Stats struct {
+	Policy  Policy
+	Allowed uint64 // Number of requests that were allowed
+	Limited uint64 // Number of requests that were rate limited
+	Clients uint64 // Number of clients being tracked
+}
+
+// bucket is a token bucket for an individual client.
+type bucket struct {
+	tokens float64
+	last   time.Time // Last time the tokens were refilled
+}
+
+// limit contains the state of a rate limit policy.
+type limit struct {
+	policy  Policy
+	buckets map[string]*bucket // [clientKey]bucket
+	allowed uint64
+	limited uint64
+}
+
+// refill refills the bucket with the tokens that have accumulated since the
+// last refill and returns the bucket.
+func (l *limit) refill(key string, now time.Time) *bucket {
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{
+			tokens: float64(l.policy.Burst),
+			last:   now,
+		}
+		l.buckets[key] = b
+		return b
+	}
+	elapsed := now.Sub(b.last)
+	if elapsed > 0 {
+		b.tokens += l.tokensPerNanosecond() * float64(elapsed)
+		b.tokens = math.Min(b.tokens, float64(l.policy.Burst))
+		b.last = now
+	}
+	return b
+}
+
+// wait returns the duration until the provided bucket contains a token.
+func (l *limit) wait(b *bucket) time.Duration {
+	missing := 1 - b.tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(missing / l.tokensPerNanosecond()))
+}
+
+// tokensPerNanosecond returns the rate at which the buckets are refilled.
+func (l *limit) tokensPerNanosecond() float64 {
+	return float64(l.policy.Rate) / float64(l.policy.Interval)
+}
+
+// Limiter applies rate limit policies to client requests. It is safe for
+// concurrent use.
+type Limiter struct {
+	sync.Mutex
+	limits    map[string][]*limit // [target]limits
+	lastPrune time.Time
+
+	// now is used to retrieve the current time. It is a field so that it
+	// can be overridden during testing.
+	now func() time.Time
+}
+
+// New returns a new Limiter that applies the provided policies.
+func New(policies []Policy) *Limiter {
+	limits := make(map[string][]*limit, len(policies))
+	for _, p := range policies {
+		limits[p.Target] = append(limits[p.Target], &limit{
+			policy:  p,
+			buckets: make(map[string]*bucket),
+		})
+	}
+	return &Limiter{
+		limits:    limits,
+		lastPrune: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// HasPolicy returns whether any policies apply to the provided target.
+func (l *Limiter) HasPolicy(target string) bool {
+	_, ok := l.limits[target]
+	return ok
+}
+
+// Allow consumes a token from each of the client's buckets for the provided
+// target. The request is allowed if every bucket contains a token. If the
+// request is not allowed, no tokens are consumed and the duration that the
+// client must wait before retrying is returned.
+func (l *Limiter) Allow(target string, c Client) (bool, time.Duration) {
+	limits, ok := l.limits[target]
+	if !ok {
+		// No policies apply to this target
+		return true, 0
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastPrune) > pruneInterval {
+		l.prune(now)
+	}
+
+	// Refill the buckets and check if any of them are empty
+	var (
+		buckets = make([]*bucket, len(limits))
+		wait    time.Duration
+	)
+	for i, v := range limits {
+		b := v.refill(c.key(v.policy.Key), now)
+		if w := v.wait(b); w > wait {
+			wait = w
+		}
+		buckets[i] = b
+	}
+	if wait > 0 {
+		for _, v := range limits {
+			v.limited++
+		}
+		return false, wait
+	}
+
+	// Consume a token from each bucket
+	for i, v := range limits {
+		buckets[i].tokens--
+		v.allowed++
+	}
+
+	return true, 0
+}
+
+// Stats returns the counters of all policies, sorted by policy string.
+func (l *Limiter) Stats() []Stats {
+	l.Lock()
+	defer l.Unlock()
+
+	stats := make([]Stats, 0, len(l.limits))
+	for _, limits := range l.limits {
+		for _, v := range limits {
+			stats = append(stats, Stats{
+				Policy:  v.policy,
+				Allowed: v.allowed,
+				Limited: v.limited,
+				Clients: uint64(len(v.buckets)),
+			})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Policy.String() < stats[j].Policy.String()
+	})
+
+	return stats
+}
+
+// prune removes all buckets that have been fully refilled.
+//
+// This function must be called WITH the lock held.
+func (l *Limiter) prune(now time.Time) {
+	for _, limits := range l.limits {
+		for _, v := range limits {
+			for key := range v.buckets {
+				b := v.refill(key, now)
+				if b.tokens >= float64(v.policy.Burst) {
+					delete(v.buckets, key)
+				}
+			}
+		}
+	}
+	l.lastPrune = now
+}
+
+// intervalSymbol returns the symbol that is used to represent the provided
+// interval in a policy string.
+func intervalSymbol(d time.Duration) string {
+	switch d {
+	case time.Second:
+		return "s"
+	case time.Minute:
+		return "m"
+	case time.Hour:
+		return "h"
+	}
+	return d.String()
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePolicy(t *testing.T) {
+	// Setup tests
+	var tests = []struct {
+		name    string  // Test name
+		policy  string  // Policy string
+		want    *Policy // Expected policy, nil if an error is expected
+		wantStr string  // Expected policy string
+	}{
+		{
+			"route policy",
+			"/v3/write,user,10/m,20",
+			&Policy{
+				Target:   "/v3/write",
+				Key:      KeyUser,
+				Rate:     10,
+				Interval: time.Minute,
+				Burst:    20,
+			},
+			"/v3/write,user,10/m,20",
+		},
+		{
+			"plugin cmd policy with default burst",
+			"comments.new,ip,5/s",
+			&Policy{
+				Target:   "comments.new",
+				Key:      KeyIP,
+				Rate:     5,
+				Interval: time.Second,
+				Burst:    5,
+			},
+			"comments.new,ip,5/s,5",
+		},
+		{
+			"missing fields",
+			"/v3/write,user",
+			nil,
+			"",
+		},
+		{
+			"invalid plugin cmd",
+			"comments,user,10/m",
+			nil,
+			"",
+		},
+		{
+			"invalid key",
+			"/v3/write,token,10/m",
+			nil,
+			"",
+		},
+		{
+			"zero rate",
+			"/v3/write,user,0/m",
+			nil,
+			"",
+		},
+		{
+			"invalid interval",
+			"/v3/write,user,10/d",
+			nil,
+			"",
+		},
+		{
+			"zero burst",
+			"/v3/write,user,10/m,0",
+			nil,
+			"",
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			p, err := ParsePolicy(v.policy)
+			switch {
+			case v.want == nil && err == nil:
+				t.Fatalf("want error, got nil")
+			case v.want == nil:
+				// Expected error; test passed
+				return
+			case err != nil:
+				t.Fatalf("want error nil, got '%v'", err)
+			}
+			if *p != *v.want {
+				t.Errorf("got %+v, want %+v", *p, *v.want)
+			}
+			if p.String() != v.wantStr {
+				t.Errorf("got string %v, want %v", p.String(), v.wantStr)
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	const route = "/v3/write"
+	l := New([]Policy{
+		{
+			Target:   route,
+			Key:      KeyUser,
+			Rate:     1,
+			Interval: time.Second,
+			Burst:    2,
+		},
+	})
+
+	// Override the limiter clock
+	now := time.Now()
+	l.now = func() time.Time { return now }
+
+	var (
+		user1 = Client{IP: "127.0.0.1", UserID: "user1"}
+		user2 = Client{IP: "127.0.0.1", UserID: "user2"}
+	)
+
+	// The burst allows two requests
+	for i := 0; i < 2; i++ {
+		ok, _ := l.Allow(route, user1)
+		if !ok {
+			t.Fatalf("request %v: want allowed, got limited", i)
+		}
+	}
+
+	// The third request is rate limited
+	ok, wait := l.Allow(route, user1)
+	if ok {
+		t.Fatalf("want limited, got allowed")
+	}
+	if wait != time.Second {
+		t.Errorf("got wait %v, want %v", wait, time.Second)
+	}
+
+	// A different user on the same IP is not rate limited
+	ok, _ = l.Allow(route, user2)
+	if !ok {
+		t.Fatalf("different user: want allowed, got limited")
+	}
+
+	// Targets without a policy are not rate limited
+	ok, _ = l.Allow("/v3/read", user1)
+	if !ok {
+		t.Fatalf("no policy: want allowed, got limited")
+	}
+
+	// A token is refilled after waiting
+	now = now.Add(wait)
+	ok, _ = l.Allow(route, user1)
+	if !ok {
+		t.Fatalf("after wait: want allowed, got limited")
+	}
+
+	// Verify the counters
+	stats := l.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("got %v stats, want 1", len(stats))
+	}
+	s := stats[0]
+	if s.Allowed != 4 || s.Limited != 1 || s.Clients != 2 {
+		t.Errorf("got allowed %v, limited %v, clients %v; "+
+			"want 4, 1, 2", s.Allowed, s.Limited, s.Clients)
+	}
+
+	// Fully refilled buckets are pruned
+	now = now.Add(2 * pruneInterval)
+	_, _ = l.Allow(route, user1)
+	s = l.Stats()[0]
+	if s.Clients != 1 {
+		t.Errorf("got %v clients after pruning, want 1", s.Clients)
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	c := Client{IP: "127.0.0.1"}
+	if c.key(KeyUser) != "ip:127.0.0.1" {
+		t.Errorf("no session: got key %v", c.key(KeyUser))
+	}
+	c.SessionID = "session1"
+	if c.key(KeyUser) != "session:session1" {
+		t.Errorf("session: got key %v", c.key(KeyUser))
+	}
+	c.UserID = "user1"
+	if c.key(KeyUser) != "user:user1" {
+		t.Errorf("user: got key %v", c.key(KeyUser))
+	}
+	if c.key(KeyIP) != "ip:127.0.0.1" {
+		t.Errorf("ip: got key %v", c.key(KeyIP))
+	}
+}
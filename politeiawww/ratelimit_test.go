@@ -0,0 +1,100 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	pdv1 "github.com/decred/politeia/politeiad/api/v1"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := parseTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Setup tests
+	var tests = []struct {
+		name       string // Test name
+		remoteAddr string // Request remote address
+		xff        string // X-Forwarded-For header
+		want       string // Expected client IP
+	}{
+		{
+			"no proxy",
+			"1.2.3.4:5000",
+			"",
+			"1.2.3.4",
+		},
+		{
+			"untrusted remote spoofs header",
+			"1.2.3.4:5000",
+			"5.6.7.8",
+			"1.2.3.4",
+		},
+		{
+			"trusted proxy",
+			"10.0.0.1:5000",
+			"5.6.7.8",
+			"5.6.7.8",
+		},
+		{
+			"client prepends a spoofed address",
+			"10.0.0.1:5000",
+			"9.9.9.9, 5.6.7.8",
+			"5.6.7.8",
+		},
+		{
+			"chain of trusted proxies",
+			"10.0.0.1:5000",
+			"5.6.7.8, 192.168.1.1",
+			"5.6.7.8",
+		},
+		{
+			"trusted proxy without header",
+			"10.0.0.1:5000",
+			"",
+			"10.0.0.1",
+		},
+		{
+			"malformed header",
+			"10.0.0.1:5000",
+			"5.6.7.8, notanip",
+			"10.0.0.1",
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = v.remoteAddr
+			if v.xff != "" {
+				r.Header.Set(pdv1.Forward, v.xff)
+			}
+			got := clientIP(r, proxies)
+			if got != v.want {
+				t.Errorf("got %v, want %v", got, v.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := parseTrustedProxies([]string{"10.0.0.1", "::1", "10.0.0.0/8"})
+	if err != nil {
+		t.Errorf("want error nil, got '%v'", err)
+	}
+	_, err = parseTrustedProxies([]string{"notanip"})
+	if err == nil {
+		t.Errorf("want error for invalid ip, got nil")
+	}
+	_, err = parseTrustedProxies([]string{"10.0.0.0/99"})
+	if err == nil {
+		t.Errorf("want error for invalid cidr, got nil")
+	}
+}
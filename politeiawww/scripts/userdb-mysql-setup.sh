@@ -26,9 +26,12 @@
 flags="-u "${MYSQL_ROOT_USER}" -p"${MYSQL_ROOT_PASSWORD}" --verbose \
   --host ${MYSQL_HOST} --port ${MYSQL_PORT}"
 
-# Database names.
+# Database names. The users databases are used by the legacy API. The
+# politeiawww databases are used by the plugin API.
 DB_MAINNET="users_mainnet"
 DB_TESTNET="users_testnet3"
+DB_PLUGINS_MAINNET="politeiawww_mainnet"
+DB_PLUGINS_TESTNET="politeiawww_testnet3"
 
 # Database usernames.
 USER_POLITEIAWWW="politeiawww"
@@ -45,6 +48,13 @@ mysql ${flags} -e \
 mysql ${flags} -e \
   "CREATE DATABASE IF NOT EXISTS ${DB_TESTNET};"
 
+# Create the mainnet and testnet databases for the plugin API.
+mysql ${flags} -e \
+  "CREATE DATABASE IF NOT EXISTS ${DB_PLUGINS_MAINNET};"
+
+mysql ${flags} -e \
+  "CREATE DATABASE IF NOT EXISTS ${DB_PLUGINS_TESTNET};"
+
 # Grant politeiawww user privileges.
 mysql ${flags} -e \
   "GRANT ALL PRIVILEGES ON ${DB_MAINNET}.* \
@@ -54,3 +64,10 @@ mysql ${flags} -e \
   "GRANT ALL PRIVILEGES ON ${DB_TESTNET}.* \
   TO '${USER_POLITEIAWWW}'@'${MYSQL_USER_HOST}';"
 
+mysql ${flags} -e \
+  "GRANT ALL PRIVILEGES ON ${DB_PLUGINS_MAINNET}.* \
+  TO '${USER_POLITEIAWWW}'@'${MYSQL_USER_HOST}';"
+
+mysql ${flags} -e \
+  "GRANT ALL PRIVILEGES ON ${DB_PLUGINS_TESTNET}.* \
+  TO '${USER_POLITEIAWWW}'@'${MYSQL_USER_HOST}';"
@@ -40,6 +40,15 @@ func (p *politeiawww) setupRouter() error {
 	p.router.Use(loggingMiddleware)
 	p.router.Use(recoverMiddleware)
 
+	// Setup the rate limiter. The rate limit middleware is registered
+	// after the logging middleware so that rate limited requests are
+	// still logged.
+	err := p.setupRateLimiter()
+	if err != nil {
+		return err
+	}
+	p.router.Use(p.rateLimitMiddleware)
+
 	// Setup a subrouter that is CSRF protected. Authenticated routes are
 	// required to use the protected router. The subrouter takes on the
 	// configuration of the router that it was spawned from, including all
@@ -65,6 +74,7 @@ func (p *politeiawww) setupRouter() error {
 		csrf.Path("/"),
 		csrf.MaxAge(csrfCookieMaxAge),
 	)
+	p.protected.Use(apiKeyCSRFMiddleware)
 	p.protected.Use(csrfMiddleware)
 
 	return nil
@@ -90,12 +100,20 @@ func (p *politeiawww) setupPluginRoutes() {
 		v3.ReadRoute, p.handleRead)
 	addRoute(p.router, http.MethodPost, v3.APIVersionPrefix,
 		v3.ReadBatchRoute, p.handleReadBatch)
+	addRoute(p.router, http.MethodGet, v3.APIVersionPrefix,
+		v3.RateLimitsRoute, p.handleRateLimits)
 
 	// CSRF protected routes
 	addRoute(p.protected, http.MethodPost, v3.APIVersionPrefix,
 		v3.NewUserRoute, p.handleNewUser)
 	addRoute(p.protected, http.MethodPost, v3.APIVersionPrefix,
 		v3.WriteRoute, p.handleWrite)
+	addRoute(p.protected, http.MethodPost, v3.APIVersionPrefix,
+		v3.APIKeyNewRoute, p.handleAPIKeyNew)
+	addRoute(p.protected, http.MethodPost, v3.APIVersionPrefix,
+		v3.APIKeyRevokeRoute, p.handleAPIKeyRevoke)
+	addRoute(p.protected, http.MethodPost, v3.APIVersionPrefix,
+		v3.APIKeysRoute, p.handleAPIKeys)
 }
 
 // addRoute adds a route to the provided router.
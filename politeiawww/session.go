@@ -15,6 +15,9 @@ import (
 const (
 	sessionValueUserID    = "user-id"
 	sessionValueCreatedAt = "created-at"
+
+	// sessionMaxAge is the max age for a session in seconds.
+	sessionMaxAge = 86400 // One day
 )
 
 // extractSession extracts and returns the session from the http request
@@ -23,10 +26,24 @@ func (p *politeiawww) extractSession(r *http.Request) (*sessions.Session, error)
 	return p.sessions.Get(r, v3.SessionCookieName)
 }
 
+// sessionStore is the store for the user sessions.
+type sessionStore interface {
+	sessions.Store
+
+	// Rotate replaces the ID of the session with a new ID and deletes
+	// the session that was saved under the previous ID.
+	Rotate(*sessions.Session) error
+}
+
 // saveUserSession saves the encoded session values to the database and the
 // encoded session ID to the response cookie if there were any changes to the
-// session. The session is deleted from the database if the auth plugin has
-// set the plugin session Delete field to true.
+// session or if the plugin has set the plugin session Save field to true. The
+// session is deleted from the database if the auth plugin has set the plugin
+// session Delete field to true.
+//
+// The session ID is replaced when the user ID of an existing session changes,
+// e.g. when a user logs in, so that a session ID that was planted by an
+// attacker prior to the login is not elevated to a logged in session.
 func (p *politeiawww) saveUserSession(r *http.Request, w http.ResponseWriter, s *sessions.Session, pluginSession *plugin.Session) error {
 	// Check if the session should be deleted.
 	if pluginSession.Delete {
@@ -35,17 +52,23 @@ func (p *politeiawww) saveUserSession(r *http.Request, w http.ResponseWriter, s
 	}
 
 	// Check if any values were updated.
-	var (
-		userID    = s.Values[sessionValueUserID].(string)
-		createdAt = s.Values[sessionValueUserID].(int64)
-	)
+	userID, createdAt := sessionValues(s)
 	if pluginSession.UserID == userID &&
-		pluginSession.CreatedAt == createdAt {
+		pluginSession.CreatedAt == createdAt &&
+		!pluginSession.Save {
 		// No changes were made. There is no
 		// need to update the database.
 		return nil
 	}
 
+	// Rotate the session ID if the user ID has changed.
+	if pluginSession.UserID != userID && !s.IsNew {
+		err := p.sessions.Rotate(s)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Update the orignal session object with the changes
 	// made by the plugin.
 	s.Values[sessionValueUserID] = pluginSession.UserID
@@ -55,13 +78,20 @@ func (p *politeiawww) saveUserSession(r *http.Request, w http.ResponseWriter, s
 	return p.sessions.Save(r, w, s)
 }
 
-func convertSession(s *sessions.Session) *plugin.Session {
+// sessionValues returns the user ID and created at values of the session.
+// Zero values are returned for a new session that does not have any values
+// set yet.
+func sessionValues(s *sessions.Session) (string, int64) {
 	// The interface{} values need to be type casted.
-	var (
-		userID    = s.Values[sessionValueUserID].(string)
-		createdAt = s.Values[sessionValueUserID].(int64)
-	)
+	userID, _ := s.Values[sessionValueUserID].(string)
+	createdAt, _ := s.Values[sessionValueCreatedAt].(int64)
+	return userID, createdAt
+}
+
+func convertSession(s *sessions.Session) *plugin.Session {
+	userID, createdAt := sessionValues(s)
 	return &plugin.Session{
+		ID:        s.ID,
 		UserID:    userID,
 		CreatedAt: createdAt,
 		Delete:    false,
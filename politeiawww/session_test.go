@@ -0,0 +1,125 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	v3 "github.com/decred/politeia/politeiawww/api/http/v3"
+	plugin "github.com/decred/politeia/politeiawww/plugin/v1"
+	"github.com/decred/politeia/politeiawww/sessions"
+)
+
+// testSessionsDB is an in memory sessions.DB that is used for testing.
+type testSessionsDB struct {
+	sessions map[string]sessions.EncodedSession
+}
+
+var (
+	_ sessions.DB = (*testSessionsDB)(nil)
+)
+
+func (d *testSessionsDB) Save(sessionID string, s sessions.EncodedSession) error {
+	d.sessions[sessionID] = s
+	return nil
+}
+
+func (d *testSessionsDB) Del(sessionID string) error {
+	delete(d.sessions, sessionID)
+	return nil
+}
+
+func (d *testSessionsDB) Get(sessionID string) (*sessions.EncodedSession, error) {
+	s, ok := d.sessions[sessionID]
+	if !ok {
+		return nil, sessions.ErrNotFound
+	}
+	return &s, nil
+}
+
+func TestSaveUserSession(t *testing.T) {
+	db := &testSessionsDB{
+		sessions: make(map[string]sessions.EncodedSession),
+	}
+	p := &politeiawww{
+		sessions: sessions.NewStore(db, sessions.NewOptions(sessionMaxAge),
+			[]byte("0123456789abcdef0123456789abcdef")),
+	}
+
+	// request executes a request using the provided session cookie,
+	// applies the provided plugin session changes, and returns the
+	// session ID and the response session cookie.
+	request := func(cookie string, update func(id string, s *plugin.Session)) (string, string) {
+		t.Helper()
+
+		r := httptest.NewRequest("POST", "/", nil)
+		if cookie != "" {
+			r.Header.Set("Cookie", cookie)
+		}
+		w := httptest.NewRecorder()
+		s, err := p.extractSession(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ps := convertSession(s)
+		update(s.ID, ps)
+		err = p.saveUserSession(r, w, s, ps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range w.Result().Cookies() {
+			if c.Name == v3.SessionCookieName {
+				return ps.ID, c.Name + "=" + c.Value
+			}
+		}
+		return ps.ID, cookie
+	}
+
+	// A session without any changes is not saved
+	request("", func(id string, s *plugin.Session) {})
+	if len(db.sessions) != 0 {
+		t.Fatalf("got %v saved sessions, want 0", len(db.sessions))
+	}
+
+	// A session is saved when the plugin requests it
+	anonID, cookie := request("", func(id string, s *plugin.Session) {
+		s.Save = true
+	})
+	if _, ok := db.sessions[anonID]; !ok {
+		t.Fatalf("anonymous session was not saved")
+	}
+
+	// The same session is returned for the cookie
+	request(cookie, func(id string, s *plugin.Session) {
+		if id != anonID {
+			t.Errorf("got session %v, want %v", id, anonID)
+		}
+	})
+
+	// Logging in replaces the session ID
+	var loginID string
+	_, cookie = request(cookie, func(id string, s *plugin.Session) {
+		s.UserID = "user"
+		s.CreatedAt = 1
+	})
+	if _, ok := db.sessions[anonID]; ok {
+		t.Errorf("session was not rotated on login")
+	}
+	if len(db.sessions) != 1 {
+		t.Fatalf("got %v saved sessions, want 1", len(db.sessions))
+	}
+	for id := range db.sessions {
+		loginID = id
+	}
+	request(cookie, func(id string, s *plugin.Session) {
+		if id != loginID {
+			t.Errorf("got session %v, want %v", id, loginID)
+		}
+		if s.UserID != "user" {
+			t.Errorf("got user %v, want user", s.UserID)
+		}
+	})
+}
@@ -219,6 +219,24 @@ func (s *sessionStore) Get(r *http.Request, cookieName string) (*sessions.Sessio
 	return sessions.GetRegistry(r).Get(s, cookieName)
 }
 
+// Rotate replaces the ID of the session with a new ID and deletes the session
+// that was saved under the previous ID. The session values are not changed.
+// The session must be saved in order to persist it under the new ID.
+//
+// The session ID should be rotated whenever the privilege level of a session
+// changes, e.g. when a user logs in, to prevent session fixation attacks.
+func (s *sessionStore) Rotate(session *sessions.Session) error {
+	log.Tracef("Rotate: %v", session.ID)
+
+	err := s.db.Del(session.ID)
+	if err != nil {
+		return err
+	}
+	session.ID = newSessionID()
+
+	return nil
+}
+
 // newSessionID returns a new session ID. A session ID is defined as a 32 byte
 // base32 string with padding. The session ID is set by the store and can be
 // whatever the store chooses. This ID was chosen simply because it's what the
@@ -7,9 +7,24 @@ package main
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"time"
 
+	amysql "github.com/decred/politeia/politeiawww/apikeys/mysql"
+	"github.com/decred/politeia/politeiawww/config"
+	luser "github.com/decred/politeia/politeiawww/legacy/user"
+	lmysql "github.com/decred/politeia/politeiawww/legacy/user/mysql"
+	"github.com/decred/politeia/politeiawww/mail"
+	"github.com/decred/politeia/politeiawww/sessions"
+	smysql "github.com/decred/politeia/politeiawww/sessions/mysql"
+	umysql "github.com/decred/politeia/politeiawww/user/mysql"
+	"github.com/decred/politeia/util"
 	"github.com/pkg/errors"
+
+	// MySQL driver.
+	_ "github.com/go-sql-driver/mysql"
 )
 
 const (
@@ -18,8 +33,154 @@ const (
 
 	// timeoutTx is the timeout for a database transaction.
 	timeoutTx = 3 * time.Minute
+
+	// Database connection settings
+	dbUser            = "politeiawww"
+	dbNamePrefix      = "politeiawww_" // Suffixed with the network name
+	dbConnMaxLifetime = 1 * time.Minute
+	dbMaxOpenConns    = 0 // 0 is unlimited
+	dbMaxIdleConns    = 100
+
+	// cookieKeyLength is the length of the session cookie key in bytes.
+	cookieKeyLength = 32
 )
 
+// setupDB opens the MySQL database connection and sets up the database
+// layers that use it: the sessions store, the user database, and the API
+// keys database.
+func (p *politeiawww) setupDB() error {
+	if p.cfg.UserDB != config.MySQL {
+		return errors.Errorf("the plugin API requires the %v user "+
+			"database; got %v", config.MySQL, p.cfg.UserDB)
+	}
+
+	// Open the database connection
+	db, err := openDB(p.cfg.DBHost, p.cfg.DBPass, p.cfg.ActiveNet.Name)
+	if err != nil {
+		return err
+	}
+
+	// Setup the sessions store
+	sdb, err := smysql.New(db, nil)
+	if err != nil {
+		return err
+	}
+	cookieKey, err := loadCookieKey(p.cfg.CookieKeyFile)
+	if err != nil {
+		return err
+	}
+	store := sessions.NewStore(sdb, sessions.NewOptions(sessionMaxAge),
+		cookieKey)
+
+	// Setup the user database
+	key, err := util.LoadEncryptionKey(log, p.cfg.UserDBKey)
+	if err != nil {
+		return err
+	}
+	udb, err := umysql.New(db, key, nil)
+	if err != nil {
+		return err
+	}
+
+	// Setup the API keys database. This must be done after the
+	// user database has been setup since the API keys table
+	// references the users table.
+	adb, err := amysql.New(db, nil)
+	if err != nil {
+		return err
+	}
+
+	p.db = db
+	p.sessions = store
+	p.userDB = udb
+	p.apiKeys = adb
+
+	return nil
+}
+
+// setupMail sets up the mail client that the plugins use to queue
+// notification emails. The emails are persisted to the mail queue of the
+// legacy MySQL user database and are sent by a background sender. Email is
+// disabled when the mail server credentials have not been provided, in which
+// case the mail queue is not used.
+func (p *politeiawww) setupMail() error {
+	templates, err := mail.NewTemplates(p.cfg.MailTemplatesDir)
+	if err != nil {
+		return errors.Errorf("mail templates: %v", err)
+	}
+	var mailerDB luser.MailerDB
+	if p.cfg.MailHost != "" && p.cfg.MailUser != "" && p.cfg.MailPass != "" {
+		mailerDB, err = lmysql.New(p.cfg.DBHost, p.cfg.DBPass,
+			filepath.Base(p.cfg.DataDir), p.cfg.UserDBKey)
+		if err != nil {
+			return errors.Errorf("mail queue db: %v", err)
+		}
+	}
+	p.mail, err = mail.NewClient(p.cfg.MailHost, p.cfg.MailUser,
+		p.cfg.MailPass, p.cfg.MailAddress, p.cfg.MailCert,
+		p.cfg.MailSkipVerify, p.cfg.MailRateLimit, mailerDB, templates, nil)
+	if err != nil {
+		return errors.Errorf("new mail client: %v", err)
+	}
+	return nil
+}
+
+// openDB opens and verifies a connection to the MySQL database for the
+// provided network.
+func openDB(host, password, network string) (*sql.DB, error) {
+	dbName := dbNamePrefix + network
+	log.Infof("MySQL host: %v:[password]@tcp(%v)/%v", dbUser, host, dbName)
+
+	h := fmt.Sprintf("%v:%v@tcp(%v)/%v", dbUser, password, host, dbName)
+	db, err := sql.Open("mysql", h)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Verify the database connection
+	ctx, cancel := ctxForOp()
+	defer cancel()
+	err = db.PingContext(ctx)
+	if err != nil {
+		return nil, errors.Errorf("db ping: %v", err)
+	}
+
+	// Setup the database options
+	db.SetConnMaxLifetime(dbConnMaxLifetime)
+	db.SetMaxOpenConns(dbMaxOpenConns)
+	db.SetMaxIdleConns(dbMaxIdleConns)
+
+	return db, nil
+}
+
+// loadCookieKey loads the session cookie key from disk. If a cookie key does
+// not exist then one is created and saved to disk for future use.
+func loadCookieKey(fp string) ([]byte, error) {
+	if util.FileExists(fp) {
+		key, err := ioutil.ReadFile(fp)
+		if err != nil {
+			return nil, err
+		}
+		if len(key) != cookieKeyLength {
+			return nil, errors.Errorf("cookie key corrupt")
+		}
+		return key, nil
+	}
+
+	log.Infof("Cookie key not found, generating one...")
+	key, err := util.Random(cookieKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(fp, key, 0400)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Cookie key created and saved to %v", fp)
+
+	return key, nil
+}
+
 // beginTx returns a database transactions and a cancel function for the
 // transaction.
 //
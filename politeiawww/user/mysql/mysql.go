@@ -0,0 +1,346 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+	"github.com/marcopeereboom/sbox"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultTableUsers is the default table name for the users table.
+	defaultTableUsers = "users"
+
+	// defaultTablePluginData is the default table name for the user plugin
+	// data table.
+	defaultTablePluginData = "user_plugin_data"
+
+	// defaultOpTimeout is the default timeout for a single database operation.
+	defaultOpTimeout = 1 * time.Minute
+
+	// encryptionVersion is the version that is encoded into the sbox header
+	// of the encrypted plugin data.
+	encryptionVersion uint32 = 1
+)
+
+// tableUsers defines the users table.
+const tableUsers = `
+  id         CHAR(36) NOT NULL PRIMARY KEY,
+  created_at BIGINT NOT NULL,
+  updated_at BIGINT NOT NULL
+`
+
+// tablePluginData defines the user plugin data table. Each row contains the
+// data for a single user and plugin.
+//
+// The encrypted column contains the plugin data that is encrypted at rest. It
+// is encrypted using the database encryption key.
+const tablePluginData = `
+  user_id    CHAR(36) NOT NULL,
+  plugin_id  VARCHAR(64) NOT NULL,
+  clear_text LONGBLOB NOT NULL,
+  encrypted  LONGBLOB NOT NULL,
+  PRIMARY KEY (user_id, plugin_id),
+  FOREIGN KEY (user_id) REFERENCES %v(id)
+`
+
+// Opts includes configurable options for the user database.
+type Opts struct {
+	// UsersTable is the table name for the users table. Defaults to "users".
+	UsersTable string
+
+	// PluginDataTable is the table name for the user plugin data table.
+	// Defaults to "user_plugin_data".
+	PluginDataTable string
+
+	// OpTimeout is the timeout for a single database operation. Defaults to
+	// 1 minute.
+	OpTimeout time.Duration
+}
+
+var (
+	_ user.DB = (*mysql)(nil)
+)
+
+// mysql implements the user.DB interface.
+type mysql struct {
+	// db is the mysql DB context.
+	db *sql.DB
+
+	// key is the encryption key that is used to encrypt the plugin data at
+	// rest.
+	key *[32]byte
+
+	// opts includes the user database options.
+	opts *Opts
+}
+
+// querier is satisfied by both a sql DB and a sql Tx. It allows the same
+// query code to be used inside and outside of a transaction.
+type querier interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+// ctxForOp returns a context and cancel function for a single database
+// operation. It uses the database operation timeout set on the mysql
+// context.
+func (m *mysql) ctxForOp() (context.Context, func()) {
+	return context.WithTimeout(context.Background(), m.opts.OpTimeout)
+}
+
+// TxInsert inserts a user into the database using a transaction.
+//
+// TxInsert satisfies the user.DB interface.
+func (m *mysql) TxInsert(tx *sql.Tx, u user.User) error {
+	log.Tracef("TxInsert: %v", u.ID)
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	// Insert the user
+	now := time.Now().Unix()
+	q := fmt.Sprintf(`INSERT INTO %v
+  (id, created_at, updated_at) VALUES (?, ?, ?)`, m.opts.UsersTable)
+	_, err := tx.ExecContext(ctx, q, u.ID.String(), now, now)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Insert the plugin data
+	return m.pluginDataSave(ctx, tx, u)
+}
+
+// TxUpdate updates a user in the database using a transaction.
+//
+// TxUpdate satisfies the user.DB interface.
+func (m *mysql) TxUpdate(tx *sql.Tx, u user.User) error {
+	log.Tracef("TxUpdate: %v", u.ID)
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	// Update the user
+	q := fmt.Sprintf(`UPDATE %v SET updated_at = ? WHERE id = ?`,
+		m.opts.UsersTable)
+	r, err := tx.ExecContext(ctx, q, time.Now().Unix(), u.ID.String())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	rows, err := r.RowsAffected()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if rows == 0 {
+		return user.ErrNotFound
+	}
+
+	// Update the plugin data
+	return m.pluginDataSave(ctx, tx, u)
+}
+
+// TxGet gets a user from the database using a transaction.
+//
+// An ErrNotFound error is returned if a user is not found for the provided
+// user ID.
+//
+// TxGet satisfies the user.DB interface.
+func (m *mysql) TxGet(tx *sql.Tx, userID string) (*user.User, error) {
+	log.Tracef("TxGet: %v", userID)
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	return m.get(ctx, tx, userID)
+}
+
+// Get gets a user from the database.
+//
+// An ErrNotFound error is returned if a user is not found for the provided
+// user ID.
+//
+// Get satisfies the user.DB interface.
+func (m *mysql) Get(userID string) (*user.User, error) {
+	log.Tracef("Get: %v", userID)
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	return m.get(ctx, m.db, userID)
+}
+
+// get gets a user and all of the user's plugin data from the database. The
+// encrypted plugin data is decrypted before being returned.
+func (m *mysql) get(ctx context.Context, q querier, userID string) (*user.User, error) {
+	// Verify the user exists
+	var id string
+	err := q.QueryRowContext(ctx,
+		"SELECT id FROM "+m.opts.UsersTable+" WHERE id = ?",
+		userID).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, user.ErrNotFound
+	case err != nil:
+		return nil, errors.WithStack(err)
+	}
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the plugin data
+	rows, err := q.QueryContext(ctx,
+		"SELECT plugin_id, clear_text, encrypted FROM "+
+			m.opts.PluginDataTable+" WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	plugins := make(map[string]user.PluginData, 16)
+	for rows.Next() {
+		var (
+			pluginID  string
+			clearText []byte
+			encrypted []byte
+		)
+		err = rows.Scan(&pluginID, &clearText, &encrypted)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		decrypted, err := m.decrypt(encrypted)
+		if err != nil {
+			return nil, errors.Errorf("decrypt %v plugin data: %v",
+				pluginID, err)
+		}
+		plugins[pluginID] = user.PluginData{
+			ClearText: clearText,
+			Encrypted: decrypted,
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &user.User{
+		ID:      uid,
+		Plugins: plugins,
+	}, nil
+}
+
+// pluginDataSave saves all of the plugin data for a user to the database. The
+// encrypted plugin data is encrypted prior to being saved.
+func (m *mysql) pluginDataSave(ctx context.Context, tx *sql.Tx, u user.User) error {
+	q := fmt.Sprintf(`INSERT INTO %v
+  (user_id, plugin_id, clear_text, encrypted) VALUES (?, ?, ?, ?)
+  ON DUPLICATE KEY UPDATE
+  clear_text = VALUES(clear_text), encrypted = VALUES(encrypted)`,
+		m.opts.PluginDataTable)
+	for pluginID, pd := range u.Plugins {
+		encrypted, err := m.encrypt(pd.Encrypted)
+		if err != nil {
+			return err
+		}
+		clearText := pd.ClearText
+		if clearText == nil {
+			clearText = []byte{}
+		}
+		_, err = tx.ExecContext(ctx, q, u.ID.String(), pluginID,
+			clearText, encrypted)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// encrypt encrypts the provided data using the database encryption key. Empty
+// data is not encrypted.
+func (m *mysql) encrypt(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return []byte{}, nil
+	}
+	return sbox.Encrypt(encryptionVersion, m.key, b)
+}
+
+// decrypt decrypts the provided data using the database encryption key. Empty
+// data is not decrypted.
+func (m *mysql) decrypt(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	d, _, err := sbox.Decrypt(m.key, b)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// New returns a new mysql context that implements the user DB interface. The
+// provided key is used to encrypt the plugin data at rest. The opts param can
+// be used to override the default mysql context settings.
+func New(db *sql.DB, key *[32]byte, opts *Opts) (*mysql, error) {
+	if key == nil {
+		return nil, errors.Errorf("encryption key not provided")
+	}
+
+	// Setup database options.
+	usersTable := defaultTableUsers
+	pluginDataTable := defaultTablePluginData
+	opTimeout := defaultOpTimeout
+	// Override defaults if options are provided
+	if opts != nil {
+		if opts.UsersTable != "" {
+			usersTable = opts.UsersTable
+		}
+		if opts.PluginDataTable != "" {
+			pluginDataTable = opts.PluginDataTable
+		}
+		if opts.OpTimeout != 0 {
+			opTimeout = opts.OpTimeout
+		}
+	}
+
+	// Create mysql context
+	m := mysql{
+		db:  db,
+		key: key,
+		opts: &Opts{
+			UsersTable:      usersTable,
+			PluginDataTable: pluginDataTable,
+			OpTimeout:       opTimeout,
+		},
+	}
+
+	ctx, cancel := m.ctxForOp()
+	defer cancel()
+
+	// Create the users table
+	q := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (%v)`,
+		m.opts.UsersTable, tableUsers)
+	_, err := db.ExecContext(ctx, q)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Create the plugin data table
+	q = fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (%v)`,
+		m.opts.PluginDataTable,
+		fmt.Sprintf(tablePluginData, m.opts.UsersTable))
+	_, err = db.ExecContext(ctx, q)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &m, nil
+}
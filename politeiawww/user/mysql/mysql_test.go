@@ -0,0 +1,235 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mysql
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/decred/politeia/politeiawww/user"
+	"github.com/google/uuid"
+	"github.com/marcopeereboom/sbox"
+)
+
+// Custom go-sqlmock types for type assertion
+type AnyInt64 struct{}
+
+func (a AnyInt64) Match(v driver.Value) bool {
+	_, ok := v.(int64)
+	return ok
+}
+
+// EncryptedBlob matches a blob that is not equal to the provided clear text.
+type EncryptedBlob struct {
+	clearText []byte
+}
+
+func (e EncryptedBlob) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	return ok && len(b) > 0 && !bytes.Equal(b, e.clearText)
+}
+
+func setupTestDB(t *testing.T) (*mysql, sqlmock.Sqlmock, func()) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error %s while creating stub db conn", err)
+	}
+	key, err := sbox.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mysql{
+		db:  db,
+		key: key,
+		opts: &Opts{
+			UsersTable:      defaultTableUsers,
+			PluginDataTable: defaultTablePluginData,
+			OpTimeout:       defaultOpTimeout,
+		},
+	}
+
+	return m, mock, func() {
+		db.Close()
+	}
+}
+
+func TestTxInsert(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	// Arguments
+	var (
+		pluginID  = "testplugin"
+		clearText = []byte("clear text")
+		secret    = []byte("secret")
+	)
+	u := user.User{
+		ID: uuid.New(),
+		Plugins: map[string]user.PluginData{
+			pluginID: {
+				ClearText: clearText,
+				Encrypted: secret,
+			},
+		},
+	}
+
+	// Queries
+	sqlInsertUser := fmt.Sprintf(`INSERT INTO %v
+  (id, created_at, updated_at) VALUES (?, ?, ?)`, mdb.opts.UsersTable)
+	sqlUpsertData := fmt.Sprintf(`INSERT INTO %v
+  (user_id, plugin_id, clear_text, encrypted) VALUES (?, ?, ?, ?)
+  ON DUPLICATE KEY UPDATE
+  clear_text = VALUES(clear_text), encrypted = VALUES(encrypted)`,
+		mdb.opts.PluginDataTable)
+
+	// The encrypted plugin data must not be saved as clear text
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlInsertUser)).
+		WithArgs(u.ID.String(), AnyInt64{}, AnyInt64{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(sqlUpsertData)).
+		WithArgs(u.ID.String(), pluginID, clearText,
+			EncryptedBlob{clearText: secret}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Execute method
+	tx, err := mdb.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mdb.TxInsert(tx, u)
+	if err != nil {
+		t.Errorf("TxInsert unwanted error: %s", err)
+	}
+	err = tx.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTxUpdateNotFound(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	u := user.User{
+		ID: uuid.New(),
+	}
+
+	// Query
+	sqlUpdate := fmt.Sprintf(`UPDATE %v SET updated_at = ? WHERE id = ?`,
+		mdb.opts.UsersTable)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(sqlUpdate)).
+		WithArgs(AnyInt64{}, u.ID.String()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	// Execute method
+	tx, err := mdb.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mdb.TxUpdate(tx, u)
+	if !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, user.ErrNotFound)
+	}
+	err = tx.Rollback()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	mdb, mock, close := setupTestDB(t)
+	defer close()
+
+	// Arguments
+	var (
+		userID    = uuid.New()
+		pluginID  = "testplugin"
+		clearText = []byte("clear text")
+		secret    = []byte("secret")
+	)
+	encrypted, err := mdb.encrypt(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Queries
+	sqlSelectUser := "SELECT id FROM " + mdb.opts.UsersTable +
+		" WHERE id = ?"
+	sqlSelectData := "SELECT plugin_id, clear_text, encrypted FROM " +
+		mdb.opts.PluginDataTable + " WHERE user_id = ?"
+
+	// Should return user.ErrNotFound when the user doesn't exist
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectUser)).
+		WithArgs(userID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	u, err := mdb.Get(userID.String())
+	if !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("got error %v, want %v", err, user.ErrNotFound)
+	}
+	if u != nil {
+		t.Errorf("not expecting a result but got one")
+	}
+
+	// Should return the decrypted plugin data when the user exists
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectUser)).
+		WithArgs(userID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow(userID.String()))
+	mock.ExpectQuery(regexp.QuoteMeta(sqlSelectData)).
+		WithArgs(userID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"plugin_id",
+			"clear_text", "encrypted"}).
+			AddRow(pluginID, clearText, encrypted))
+
+	u, err = mdb.Get(userID.String())
+	if err != nil {
+		t.Fatalf("Get unwanted error: %s", err)
+	}
+	if u.ID != userID {
+		t.Errorf("got user ID %v, want %v", u.ID, userID)
+	}
+	pd, ok := u.Plugins[pluginID]
+	if !ok {
+		t.Fatalf("plugin data not found")
+	}
+	if !bytes.Equal(pd.ClearText, clearText) {
+		t.Errorf("got clear text %s, want %s", pd.ClearText, clearText)
+	}
+	if !bytes.Equal(pd.Encrypted, secret) {
+		t.Errorf("got encrypted %s, want %s", pd.Encrypted, secret)
+	}
+
+	// Make sure expectations were met
+	err = mock.ExpectationsWereMet()
+	if err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
@@ -34,6 +34,11 @@ type User struct {
 	ID      uuid.UUID             // Unique ID
 	Plugins map[string]PluginData // [pluginID]PluginData
 	Updated bool
+
+	// Permissions contains the permission levels that the AuthManager has
+	// granted the user. It is populated by the backend during user
+	// authorization and is not saved to the database.
+	Permissions []string
 }
 
 // PluginData contains the user data for a specific plugin.
@@ -0,0 +1,97 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import "strings"
+
+// MarkdownHeadingNormalize normalizes a markdown heading so that headings can
+// be compared without regard to case or surrounding whitespace.
+func MarkdownHeadingNormalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// MarkdownHeadings returns the normalized text of all of the headings that
+// are contained in the provided markdown document. Both ATX headings (e.g.
+// "## Budget") and setext headings (a line of text that is underlined using
+// "=" or "-" characters) are supported. Lines that are inside of fenced code
+// blocks are ignored.
+func MarkdownHeadings(md string) map[string]struct{} {
+	var (
+		headings = make(map[string]struct{})
+
+		fence    string // Opening code fence, if inside of a code block
+		previous string // Previous paragraph line
+	)
+	for _, line := range strings.Split(md, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+
+		// Markdown allows up to three spaces of indentation for
+		// headings and code fences. Anything more than that is an
+		// indented code block.
+		indented := len(line)-len(strings.TrimLeft(line, " ")) > 3
+
+		// Handle fenced code blocks
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if !indented && (strings.HasPrefix(trimmed, "```") ||
+			strings.HasPrefix(trimmed, "~~~")) {
+			fence = trimmed[:3]
+			previous = ""
+			continue
+		}
+
+		switch {
+		case indented || trimmed == "":
+			previous = ""
+
+		case atxHeading(trimmed) != "":
+			headings[MarkdownHeadingNormalize(atxHeading(trimmed))] = struct{}{}
+			previous = ""
+
+		case previous != "" && setextUnderline(trimmed):
+			headings[MarkdownHeadingNormalize(previous)] = struct{}{}
+			previous = ""
+
+		default:
+			previous = trimmed
+		}
+	}
+
+	return headings
+}
+
+// atxHeading returns the text of the provided line if the line is an ATX
+// heading. An empty string is returned if the line is not an ATX heading.
+func atxHeading(line string) string {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	if level == 0 || level > 6 {
+		return ""
+	}
+	text := line[level:]
+	if text != "" && text[0] != ' ' && text[0] != '\t' {
+		// A space is required after the opening sequence
+		return ""
+	}
+
+	// Remove the optional closing sequence
+	text = strings.TrimSpace(text)
+	closing := strings.TrimRight(text, "#")
+	if closing == "" || strings.HasSuffix(closing, " ") {
+		text = closing
+	}
+
+	return strings.TrimSpace(text)
+}
+
+// setextUnderline returns whether the provided line is a setext heading
+// underline.
+func setextUnderline(line string) bool {
+	return strings.Trim(line, "=") == "" || strings.Trim(line, "-") == ""
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2022 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package util
+
+import "testing"
+
+func TestMarkdownHeadings(t *testing.T) {
+	// Setup tests
+	var tests = []struct {
+		name     string   // Test name
+		md       string   // Markdown document
+		headings []string // Expected headings
+	}{
+		{
+			"atx headings",
+			"# Budget\n## Timeline ##\n###### Risks",
+			[]string{"budget", "timeline", "risks"},
+		},
+		{
+			"atx heading without space",
+			"#Budget",
+			[]string{},
+		},
+		{
+			"atx heading level too deep",
+			"####### Budget",
+			[]string{},
+		},
+		{
+			"setext headings",
+			"Budget\n======\n\nTimeline\n---",
+			[]string{"budget", "timeline"},
+		},
+		{
+			"thematic break is not a heading",
+			"Budget\n\n---",
+			[]string{},
+		},
+		{
+			"fenced code block",
+			"```\n# Budget\n```\n~~~\n# Timeline\n~~~\n# Risks",
+			[]string{"risks"},
+		},
+		{
+			"indented code block",
+			"    # Budget",
+			[]string{},
+		},
+		{
+			"windows line endings",
+			"# Budget\r\n# Timeline\r\n",
+			[]string{"budget", "timeline"},
+		},
+		{
+			"whitespace and case",
+			"#   Project    TIMELINE  ",
+			[]string{"project timeline"},
+		},
+	}
+
+	// Run tests
+	for _, v := range tests {
+		t.Run(v.name, func(t *testing.T) {
+			headings := MarkdownHeadings(v.md)
+			if len(headings) != len(v.headings) {
+				t.Fatalf("got %v headings, want %v: %v",
+					len(headings), len(v.headings), headings)
+			}
+			for _, h := range v.headings {
+				if _, ok := headings[h]; !ok {
+					t.Errorf("heading '%v' not found", h)
+				}
+			}
+		})
+	}
+}